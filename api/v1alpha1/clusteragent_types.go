@@ -0,0 +1,104 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// ClusterAgentSpec defines the desired state of ClusterAgent
+type ClusterAgentSpec struct {
+	// ClusterName is the name of the member cluster the agent runs in,
+	// it is the cluster dimension of the exported data points
+	// +kubebuilder:validation:MinLength=1
+	ClusterName string `json:"clusterName"`
+}
+
+// ClusterAgentMetric is the result of the last collection of a FederatedMetric by an agent
+type ClusterAgentMetric struct {
+	// Name of the FederatedMetric
+	Name string `json:"name"`
+
+	// Namespace of the FederatedMetric
+	Namespace string `json:"namespace"`
+
+	// Ready is True if the last collection was exported successfully
+	Ready string `json:"ready"`
+
+	// Message describes the result of the last collection
+	// +optional
+	Message string `json:"message,omitempty"`
+
+	// LastExportTime is the time of the last collection
+	// +optional
+	LastExportTime *metav1.Time `json:"lastExportTime,omitempty"`
+}
+
+// ClusterAgentStatus defines the observed state of ClusterAgent
+type ClusterAgentStatus struct {
+	// LastHeartbeatTime is the time the agent last reported
+	// +optional
+	LastHeartbeatTime *metav1.Time `json:"lastHeartbeatTime,omitempty"`
+
+	// Metrics are the results of the FederatedMetrics collected by the agent
+	// +optional
+	Metrics []ClusterAgentMetric `json:"metrics,omitempty"`
+}
+
+// FindMetric returns the result of the FederatedMetric with the given namespace and name, or nil if the agent has not collected it
+func (s *ClusterAgentStatus) FindMetric(namespace, name string) *ClusterAgentMetric {
+	for i := range s.Metrics {
+		if s.Metrics[i].Namespace == namespace && s.Metrics[i].Name == name {
+			return &s.Metrics[i]
+		}
+	}
+	return nil
+}
+
+// ClusterAgent is registered on the hub by an agent that runs in a member cluster.
+// The agent collects the FederatedMetrics of the FederatedClusterAccesses selecting it and exports them directly,
+// the hub only aggregates the results reported in the status.
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:shortName=cagent
+// +kubebuilder:printcolumn:name="CLUSTER",type="string",JSONPath=".spec.clusterName"
+// +kubebuilder:printcolumn:name="HEARTBEAT",type="date",JSONPath=".status.lastHeartbeatTime"
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+type ClusterAgent struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ClusterAgentSpec   `json:"spec,omitempty"`
+	Status ClusterAgentStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ClusterAgentList contains a list of ClusterAgent
+type ClusterAgentList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ClusterAgent `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(func(s *runtime.Scheme) error {
+		s.AddKnownTypes(GroupVersion, &ClusterAgent{}, &ClusterAgentList{})
+		return nil
+	})
+}
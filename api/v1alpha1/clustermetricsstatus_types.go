@@ -0,0 +1,105 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// ClusterMetricsStatusName is the name of the ClusterMetricsStatus maintained by the operator
+const ClusterMetricsStatusName = "cluster"
+
+// MetricKindSummary counts the metrics of one kind by their health
+type MetricKindSummary struct {
+	// Kind of the metrics, e.g. Metric or ManagedMetric
+	Kind string `json:"kind"`
+	// Total is the number of metrics of the kind
+	Total int `json:"total"`
+	// Ready is the number of metrics that are ready
+	Ready int `json:"ready"`
+	// Failing is the number of metrics that are not ready
+	Failing int `json:"failing"`
+	// Stale is the number of metrics without an observation within twice their interval, they may be ready or failing
+	Stale int `json:"stale"`
+}
+
+// ClusterMetricsStatusStatus summarizes the health of all metrics of the cluster
+type ClusterMetricsStatusStatus struct {
+	// Total is the number of metrics of all kinds
+	Total int `json:"total"`
+	// Ready is the number of metrics that are ready
+	Ready int `json:"ready"`
+	// Failing is the number of metrics that are not ready
+	Failing int `json:"failing"`
+	// Stale is the number of metrics without an observation within twice their interval
+	Stale int `json:"stale"`
+
+	// Kinds break the numbers down by the kind of the metrics
+	// +optional
+	// +listType=map
+	// +listMapKey=kind
+	Kinds []MetricKindSummary `json:"kinds,omitempty"`
+
+	// LastUpdateTime is the time the summary was last computed
+	// +optional
+	LastUpdateTime *metav1.Time `json:"lastUpdateTime,omitempty"`
+
+	// Conditions represent the latest available observations of an object's state
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// ClusterMetricsStatus summarizes the health of all metrics of the cluster in a single object.
+// The operator maintains the object named "cluster".
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster,shortName=cms
+// +kubebuilder:printcolumn:name="TOTAL",type="integer",JSONPath=".status.total"
+// +kubebuilder:printcolumn:name="READY",type="integer",JSONPath=".status.ready"
+// +kubebuilder:printcolumn:name="FAILING",type="integer",JSONPath=".status.failing"
+// +kubebuilder:printcolumn:name="STALE",type="integer",JSONPath=".status.stale"
+// +kubebuilder:printcolumn:name="UPDATED",type="date",JSONPath=".status.lastUpdateTime"
+type ClusterMetricsStatus struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Status ClusterMetricsStatusStatus `json:"status,omitempty"`
+}
+
+// SetConditions sets the conditions of the ClusterMetricsStatus
+func (r *ClusterMetricsStatus) SetConditions(conditions ...metav1.Condition) {
+	for _, c := range conditions {
+		meta.SetStatusCondition(&r.Status.Conditions, c)
+	}
+}
+
+// +kubebuilder:object:root=true
+
+// ClusterMetricsStatusList contains a list of ClusterMetricsStatus
+type ClusterMetricsStatusList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ClusterMetricsStatus `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(func(s *runtime.Scheme) error {
+		s.AddKnownTypes(GroupVersion, &ClusterMetricsStatus{}, &ClusterMetricsStatusList{})
+		return nil
+	})
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 )
@@ -15,6 +16,19 @@ const (
 	StatusFalse = "False"
 )
 
+// ReplayAnnotation requests an immediate export of a metric regardless of its interval or schedule.
+// Its value is an RFC3339 timestamp, the metric is exported once if its last export is older.
+const ReplayAnnotation = "metrics.openmcp.cloud/replay"
+
+// Placeholders replaced in the string fields of the spec of a metric when it is reconciled.
+// The variables of the operator, set with its --variables flag, are referenced as $(<NAME>) as well.
+const (
+	// MetricNamePlaceholder is replaced with the name of the metric resource
+	MetricNamePlaceholder = "$(METRIC_NAME)"
+	// MetricNamespacePlaceholder is replaced with the namespace of the metric resource
+	MetricNamespacePlaceholder = "$(METRIC_NAMESPACE)"
+)
+
 // GroupVersionKind defines the group, version and kind of the object that should be instrumented
 type GroupVersionKind struct {
 	// Define the kind of the object that should be instrumented
@@ -35,6 +49,7 @@ func (gvk *GroupVersionKind) GVK() schema.GroupVersionKind {
 }
 
 // Projection defines the projection of the metric
+// +kubebuilder:validation:XValidation:rule="!(has(self.fieldPath) && has(self.source))",message="fieldPath and source cannot be used together"
 type Projection struct {
 	// Define the name of the field that should be extracted
 	Name string `json:"name,omitempty"`
@@ -42,6 +57,13 @@ type Projection struct {
 	// Define the path to the field that should be extracted
 	FieldPath string `json:"fieldPath,omitempty"`
 
+	// Source extracts a built-in value instead of the field at fieldPath.
+	// "ownerKind" and "ownerName" are the kind and name of the controller owner of the resource,
+	// the owning Deployment for Pods of a ReplicaSet created by a Deployment.
+	// +optional
+	// +kubebuilder:validation:Enum=ownerKind;ownerName
+	Source ProjectionSource `json:"source,omitempty"`
+
 	// Type specifies the type of the projections's value.
 	// It can be "primitive", "slice", "map", or "timestamp".
 	// Use "timestamp" for RFC3339 time fields — the value is converted to Unix seconds.
@@ -63,6 +85,16 @@ type Projection struct {
 	Default *ProjectionDefaultValue `json:"default,omitempty"`
 }
 
+// ProjectionSource is a built-in value a projection extracts instead of a field
+type ProjectionSource string
+
+const (
+	// ProjectionSourceOwnerKind is the kind of the controller owner of the resource
+	ProjectionSourceOwnerKind ProjectionSource = "ownerKind"
+	// ProjectionSourceOwnerName is the name of the controller owner of the resource
+	ProjectionSourceOwnerName ProjectionSource = "ownerName"
+)
+
 // ValueType represents the type of a gauge metric value extracted from a resource field.
 type ValueType string
 
@@ -71,6 +103,8 @@ const (
 	ValueTypeInteger ValueType = "integer"
 	// ValueTypeTimestamp interprets the field as an RFC3339 timestamp, converting it to Unix seconds.
 	ValueTypeTimestamp ValueType = "timestamp"
+	// ValueTypeQuantity interprets the field as a resource quantity like "10Gi", rounded up to an integer.
+	ValueTypeQuantity ValueType = "quantity"
 )
 
 // AggregationType represents the aggregation function applied to valueFrom when multiple
@@ -88,6 +122,56 @@ const (
 	AggregationMean AggregationType = "mean"
 )
 
+// Priority is the priority with which a metric is reconciled when many metrics are due at once.
+type Priority string
+
+const (
+	// PriorityHigh metrics are reconciled before all other metrics, e.g. metrics used for billing.
+	PriorityHigh Priority = "high"
+	// PriorityNormal is the default priority.
+	PriorityNormal Priority = "normal"
+	// PriorityLow metrics are reconciled after all other metrics, e.g. inventory metrics.
+	PriorityLow Priority = "low"
+)
+
+// ExportStatus shows whether the data points of a metric reach its data sink, independent of whether the collection succeeded
+type ExportStatus struct {
+	// LastExportTime is the time of the last successful export
+	// +optional
+	LastExportTime *metav1.Time `json:"lastExportTime,omitempty"`
+
+	// LastExportDuration is how long the last export took, successful or not
+	// +optional
+	LastExportDuration *metav1.Duration `json:"lastExportDuration,omitempty"`
+
+	// ExportAttempts is the number of exports attempted since the metric was created
+	// +optional
+	ExportAttempts int64 `json:"exportAttempts,omitempty"`
+
+	// DataSink is the namespace/name of the data sink the metric was last exported to
+	// +optional
+	DataSink string `json:"dataSink,omitempty"`
+}
+
+// RetryPolicy defines how the failed collections of a metric are retried
+type RetryPolicy struct {
+	// BaseDelay is the delay of the first retry after a failed collection, it is doubled with every further failure.
+	// Defaults to the --error-requeue-base-delay of the operator.
+	// +optional
+	BaseDelay *metav1.Duration `json:"baseDelay,omitempty"`
+
+	// MaxDelay is the upper bound of the delay between retries.
+	// Defaults to the --error-requeue-max-delay of the operator.
+	// +optional
+	MaxDelay *metav1.Duration `json:"maxDelay,omitempty"`
+
+	// FailureThreshold is the number of consecutive failed collections after which the metric is marked failed
+	// and no longer retried until its spec changes. The metric is retried forever if it is not set.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	FailureThreshold int32 `json:"failureThreshold,omitempty"`
+}
+
 // ValueFromProjection defines a field whose value is used as the gauge metric value.
 type ValueFromProjection struct {
 	// Define the path to the field that should be extracted
@@ -96,10 +180,11 @@ type ValueFromProjection struct {
 	// Type specifies the type of the field's value.
 	// Use "integer" for numeric fields — the value is used directly as the gauge value.
 	// Use "timestamp" for RFC3339 time fields — the value is converted to Unix seconds.
+	// Use "quantity" for resource quantities like "10Gi" — the value is rounded up to an integer.
 	// If not specified, it will default to "integer".
 	// +optional
 	// +default="integer"
-	// +kubebuilder:validation:Enum=integer;timestamp
+	// +kubebuilder:validation:Enum=integer;timestamp;quantity
 	Type ValueType `json:"type,omitempty"`
 
 	// Aggregation specifies how values are combined when multiple objects share the same
@@ -174,8 +259,19 @@ type MetricObservation struct {
 	LatestValue string `json:"latestValue,omitempty"`
 
 	Dimensions []Dimension `json:"dimensions,omitempty"`
+
+	// Series are the series exported by the last collection of a metric with projections, sorted by their dimensions,
+	// at most MaxObservedSeries of them. Their values are taken after the mode, sampling and export policy are applied.
+	// +optional
+	Series []RecordedSeriesValue `json:"series,omitempty"`
+	// OmittedSeries is the number of exported series beyond MaxObservedSeries that are not listed
+	// +optional
+	OmittedSeries int32 `json:"omittedSeries,omitempty"`
 }
 
+// MaxObservedSeries is the maximum number of series listed in the observation of a metric
+const MaxObservedSeries = 20
+
 // GetTimestamp returns the timestamp of the observation
 func (mo *MetricObservation) GetTimestamp() metav1.Time {
 	return mo.Timestamp
@@ -185,3 +281,77 @@ func (mo *MetricObservation) GetTimestamp() metav1.Time {
 func (mo *MetricObservation) GetValue() string {
 	return mo.LatestValue
 }
+
+// DebugOptions help verifying that a metric selects the intended resources
+type DebugOptions struct {
+	// EmitSamples is the maximum number of matched resource names listed in a Samples event per reconcile.
+	// No event is emitted if it is 0.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=50
+	EmitSamples int `json:"emitSamples,omitempty"`
+
+	// RecordDimensions writes the dimensions and values of the series recorded by the last collection
+	// to status.recordedSeries, at most MaxRecordedSeries of them. Only supported by Metrics.
+	// +optional
+	RecordDimensions bool `json:"recordDimensions,omitempty"`
+}
+
+// Thresholds bound the latest value of a metric, a CloudEvent is sent when the value crosses one of them.
+// Notifications are sent if the operator is started with --notification-sink.
+// +kubebuilder:validation:XValidation:rule="!has(self.above) || !has(self.below) || self.below <= self.above",message="below must not be greater than above"
+type Thresholds struct {
+	// Above is crossed when the latest value rises above it
+	// +optional
+	Above *int64 `json:"above,omitempty"`
+	// Below is crossed when the latest value falls below it
+	// +optional
+	Below *int64 `json:"below,omitempty"`
+}
+
+// ScopeAttribute is an attribute of the instrumentation scope a metric is exported with
+type ScopeAttribute struct {
+	// Name of the attribute, unique within the scope attributes of a metric
+	// +kubebuilder:validation:Pattern=`^[a-zA-Z_][a-zA-Z0-9_.]*$`
+	Name string `json:"name"`
+	// Value of the attribute
+	Value string `json:"value"`
+}
+
+// ScopeAttributesMap returns the scope attributes by name
+func ScopeAttributesMap(attributes []ScopeAttribute) map[string]string {
+	if len(attributes) == 0 {
+		return nil
+	}
+	m := make(map[string]string, len(attributes))
+	for _, a := range attributes {
+		m[a.Name] = a.Value
+	}
+	return m
+}
+
+// StaticDimension is a dimension with the same value on every data point of a metric,
+// e.g. the tenant or cost center the metric is charged to
+// +kubebuilder:validation:XValidation:rule="has(self.value) != has(self.valueFrom)",message="exactly one of value or valueFrom must be set"
+type StaticDimension struct {
+	// Name of the dimension, unique within the static dimensions of a metric
+	// +kubebuilder:validation:Pattern=`^[a-zA-Z_][a-zA-Z0-9_.]*$`
+	Name string `json:"name"`
+	// Value of the dimension
+	// +optional
+	Value string `json:"value,omitempty"`
+	// ValueFrom reads the value from a key of a ConfigMap or Secret in the namespace of the metric
+	// +optional
+	ValueFrom *StaticDimensionSource `json:"valueFrom,omitempty"`
+}
+
+// StaticDimensionSource selects the key of a ConfigMap or Secret a static dimension is read from
+// +kubebuilder:validation:XValidation:rule="has(self.configMapKeyRef) != has(self.secretKeyRef)",message="exactly one of configMapKeyRef or secretKeyRef must be set"
+type StaticDimensionSource struct {
+	// ConfigMapKeyRef selects a key of a ConfigMap
+	// +optional
+	ConfigMapKeyRef *corev1.ConfigMapKeySelector `json:"configMapKeyRef,omitempty"`
+	// SecretKeyRef selects a key of a Secret
+	// +optional
+	SecretKeyRef *corev1.SecretKeySelector `json:"secretKeyRef,omitempty"`
+}
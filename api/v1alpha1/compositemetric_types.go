@@ -0,0 +1,190 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// CompositeMetricSource references a Metric whose latest observed value is used in the expression
+type CompositeMetricSource struct {
+	// Name is the variable name used to reference the value of the source in the expression
+	// +kubebuilder:validation:Pattern=`^[a-zA-Z_][a-zA-Z0-9_]*$`
+	Name string `json:"name"`
+	// MetricRef is the name of a Metric in the same namespace whose latest observed value is used
+	MetricRef string `json:"metricRef"`
+}
+
+// CompositeMetricSpec defines the desired state of CompositeMetric
+type CompositeMetricSpec struct {
+	// Sets the name that will be used to identify the metric in Dynatrace(or other providers)
+	Name string `json:"name,omitempty"`
+	// Sets the description that will be used to identify the metric in Dynatrace(or other providers)
+	// +optional
+	Description string `json:"description,omitempty"`
+	// Sets the unit of the metric in UCUM notation, e.g. "1", "s" or "By", that will be shown in Dynatrace(or other providers)
+	// +kubebuilder:validation:MaxLength=63
+	// +optional
+	Unit string `json:"unit,omitempty"`
+
+	// Sources lists the Metrics the composite metric is derived from
+	// +kubebuilder:validation:MinItems=1
+	// +listType=map
+	// +listMapKey=name
+	Sources []CompositeMetricSource `json:"sources"`
+
+	// Expression is an arithmetic expression over the values of the sources, e.g. "100 * ready / total".
+	// It supports +, -, *, / and parentheses. The result is rounded to the nearest integer.
+	// +kubebuilder:validation:MinLength=1
+	Expression string `json:"expression"`
+
+	// Define in what interval the derived value should be recorded.
+	// The value is also recomputed whenever one of the sources is observed again.
+	// +kubebuilder:default:="10m"
+	Interval metav1.Duration `json:"interval,omitempty"`
+
+	// Schedule is a cron expression like "0 * * * *" or "@daily", evaluated in UTC, that defines when the composite metric is exported.
+	// If set, it is used instead of the interval and the first export happens at the first scheduled time.
+	// +kubebuilder:validation:MaxLength=100
+	// +optional
+	Schedule string `json:"schedule,omitempty"`
+
+	// Priority decides which metrics are reconciled first when many metrics are due at once,
+	// so important metrics are not delayed behind a large number of less important ones.
+	// +kubebuilder:validation:Enum=high;normal;low
+	// +kubebuilder:default:="normal"
+	// +optional
+	Priority Priority `json:"priority,omitempty"`
+
+	// RetryPolicy defines the delay between the retries of failed collections and after how many failures they stop
+	// +optional
+	RetryPolicy *RetryPolicy `json:"retryPolicy,omitempty"`
+
+	// DataSinkRef specifies the DataSink to be used for this composite metric.
+	// If omitted, no OTLP export is performed; metrics are only exposed via /metrics.
+	// If provided, the referenced DataSink must exist or reconciliation will fail.
+	// +optional
+	DataSinkRef *DataSinkReference `json:"dataSinkRef,omitempty"`
+
+	// MeterName is the name of the OpenTelemetry meter, i.e. the instrumentation scope the metric is exported with,
+	// so downstream pipelines can route by scope. Defaults to "composite".
+	// +optional
+	// +kubebuilder:validation:MaxLength=255
+	// +kubebuilder:validation:Pattern=`^[a-zA-Z][a-zA-Z0-9_./-]*$`
+	MeterName string `json:"meterName,omitempty"`
+
+	// ScopeAttributes are exported as attributes of the instrumentation scope
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	ScopeAttributes []ScopeAttribute `json:"scopeAttributes,omitempty"`
+
+	// StaticDimensions are added to every data point of the metric, e.g. a tenant read from a ConfigMap of the namespace.
+	// They never override the dimensions of the metric itself.
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	StaticDimensions []StaticDimension `json:"staticDimensions,omitempty"`
+
+	// Thresholds send a notification when the latest value of the metric crosses them
+	// +optional
+	Thresholds *Thresholds `json:"thresholds,omitempty"`
+}
+
+// CompositeMetricStatus defines the observed state of CompositeMetric
+type CompositeMetricStatus struct {
+
+	// Observation represent the latest available observation of an object's state
+	Observation MetricObservation `json:"observation,omitempty"`
+
+	// Ready is like a snapshot of the current state of the metric's lifecycle
+	Ready string `json:"ready,omitempty"`
+
+	// Conditions represent the latest available observations of an object's state
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// NextRunTime is the time the composite metric is exported next
+	// +optional
+	NextRunTime *metav1.Time `json:"nextRunTime,omitempty"`
+
+	// ConsecutiveFailures is the number of collections that failed in a row since the last successful one
+	// +optional
+	ConsecutiveFailures int32 `json:"consecutiveFailures,omitempty"`
+
+	ExportStatus `json:",inline"`
+
+	// StaticDimensionsHash identifies the values of the static dimensions of the last export,
+	// the metric is exported again when a value read from a ConfigMap or Secret changes
+	// +optional
+	StaticDimensionsHash string `json:"staticDimensionsHash,omitempty"`
+}
+
+// CompositeMetric is the Schema for the compositemetrics API
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:shortName=cmtr
+// +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.ready"
+// +kubebuilder:printcolumn:name="VALUE",type="string",JSONPath=".status.observation.latestValue"
+// +kubebuilder:printcolumn:name="INTERVAL",type="string",JSONPath=".spec.interval"
+// +kubebuilder:printcolumn:name="SINK",type="string",JSONPath=".status.dataSink"
+// +kubebuilder:printcolumn:name="OBSERVED",type="date",JSONPath=".status.observation.timestamp"
+// +kubebuilder:printcolumn:name="EXPORTED",type="date",JSONPath=".status.lastExportTime"
+type CompositeMetric struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   CompositeMetricSpec   `json:"spec,omitempty"`
+	Status CompositeMetricStatus `json:"status,omitempty"`
+}
+
+// SetConditions sets the conditions of the composite metric
+func (r *CompositeMetric) SetConditions(conditions ...metav1.Condition) {
+	for _, c := range conditions {
+		meta.SetStatusCondition(&r.Status.Conditions, c)
+	}
+}
+
+// GetPriority returns the priority of the CompositeMetric, metrics without a priority have the normal priority
+func (r *CompositeMetric) GetPriority() Priority {
+	if r.Spec.Priority == "" {
+		return PriorityNormal
+	}
+	return r.Spec.Priority
+}
+
+// GetStaticDimensions returns the static dimensions of the CompositeMetric
+func (r *CompositeMetric) GetStaticDimensions() []StaticDimension {
+	return r.Spec.StaticDimensions
+}
+
+// +kubebuilder:object:root=true
+
+// CompositeMetricList contains a list of CompositeMetric
+type CompositeMetricList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []CompositeMetric `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(func(s *runtime.Scheme) error {
+		s.AddKnownTypes(GroupVersion, &CompositeMetric{}, &CompositeMetricList{})
+		return nil
+	})
+}
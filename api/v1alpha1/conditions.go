@@ -37,6 +37,12 @@ const (
 	// TypeReady is a condition type that indicates the resource is ready
 	TypeReady = "Ready"
 
+	// TypeDegraded is a condition type that indicates the resource works, but not as expected, e.g. a data sink rejecting exports
+	TypeDegraded = "Degraded"
+
+	// TypeCredentialsExpiring is a condition type that indicates the credentials of a remote cluster expire soon or expired
+	TypeCredentialsExpiring = "CredentialsExpiring"
+
 	// StatusStringTrue represents the True status string.
 	StatusStringTrue string = "True"
 	// StatusStringFalse represents the False status string.
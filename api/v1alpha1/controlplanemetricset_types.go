@@ -0,0 +1,150 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// ControlPlaneMetricSetLabel is set on the Metrics and FederatedMetrics generated by a ControlPlaneMetricSet
+// to the name of the ControlPlaneMetricSet
+const ControlPlaneMetricSetLabel = "metrics.openmcp.cloud/controlplanemetricset"
+
+// Placeholders replaced in the templates of a ControlPlaneMetricSet
+const (
+	// ControlPlaneNamePlaceholder is replaced with the name of the control plane
+	ControlPlaneNamePlaceholder = "$(CONTROL_PLANE_NAME)"
+	// ControlPlaneNamespacePlaceholder is replaced with the namespace of the control plane, empty for cluster-scoped kinds
+	ControlPlaneNamespacePlaceholder = "$(CONTROL_PLANE_NAMESPACE)"
+)
+
+// ControlPlaneMetricTemplate is the template of the Metric generated for each control plane
+type ControlPlaneMetricTemplate struct {
+	// Name identifies the template. The generated Metrics are named <ControlPlaneMetricSet name>-<name>-<control plane>.
+	// +kubebuilder:validation:MaxLength=63
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	Name string `json:"name"`
+
+	// Labels are added to the generated Metrics
+	// +optional
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// Spec is the spec of the generated Metrics
+	Spec MetricSpec `json:"spec"`
+}
+
+// ControlPlaneFederatedMetricTemplate is the template of the FederatedMetric generated for each control plane
+type ControlPlaneFederatedMetricTemplate struct {
+	// Name identifies the template. The generated FederatedMetrics are named <ControlPlaneMetricSet name>-<name>-<control plane>.
+	// +kubebuilder:validation:MaxLength=63
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	Name string `json:"name"`
+
+	// Labels are added to the generated FederatedMetrics
+	// +optional
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// Spec is the spec of the generated FederatedMetrics
+	Spec FederatedMetricSpec `json:"spec"`
+}
+
+// ControlPlaneMetricSetSpec defines the desired state of ControlPlaneMetricSet
+// +kubebuilder:validation:XValidation:rule="has(self.metrics) || has(self.federatedMetrics)",message="at least one of metrics or federatedMetrics must be set"
+type ControlPlaneMetricSetSpec struct {
+	// Selector selects the control planes by their labels, all control planes are selected if it is omitted
+	// +optional
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+
+	// Dimension is the name of the static dimension holding the name of the control plane,
+	// it is added to every generated Metric and FederatedMetric
+	// +kubebuilder:validation:Pattern=`^[a-zA-Z_][a-zA-Z0-9_.]*$`
+	// +kubebuilder:default:="mcp"
+	// +optional
+	Dimension string `json:"dimension,omitempty"`
+
+	// Metrics are the templates of the Metrics generated for each control plane.
+	// The placeholders $(CONTROL_PLANE_NAME) and $(CONTROL_PLANE_NAMESPACE) in their string fields are replaced
+	// with the name and namespace of the control plane.
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	Metrics []ControlPlaneMetricTemplate `json:"metrics,omitempty"`
+
+	// FederatedMetrics are the templates of the FederatedMetrics generated for each control plane,
+	// with the same placeholders as the metrics
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	FederatedMetrics []ControlPlaneFederatedMetricTemplate `json:"federatedMetrics,omitempty"`
+}
+
+// ControlPlaneMetricSetStatus defines the observed state of ControlPlaneMetricSet
+type ControlPlaneMetricSetStatus struct {
+	// ControlPlanes is the number of selected control planes
+	ControlPlanes int `json:"controlPlanes,omitempty"`
+
+	// Generated is the number of generated Metrics and FederatedMetrics
+	Generated int `json:"generated,omitempty"`
+
+	// Ready is True if all Metrics and FederatedMetrics were generated
+	Ready string `json:"ready,omitempty"`
+
+	// Conditions represent the latest available observations of an object's state
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// ControlPlaneMetricSet generates Metrics and FederatedMetrics from templates for each control plane,
+// e.g. each ManagedControlPlane of an openmcp landscape, and deletes them with the control plane
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:shortName=cpmset
+// +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.ready"
+// +kubebuilder:printcolumn:name="CONTROL PLANES",type="integer",JSONPath=".status.controlPlanes"
+// +kubebuilder:printcolumn:name="GENERATED",type="integer",JSONPath=".status.generated"
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+type ControlPlaneMetricSet struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ControlPlaneMetricSetSpec   `json:"spec,omitempty"`
+	Status ControlPlaneMetricSetStatus `json:"status,omitempty"`
+}
+
+// SetConditions sets the conditions of the control plane metric set
+func (r *ControlPlaneMetricSet) SetConditions(conditions ...metav1.Condition) {
+	for _, c := range conditions {
+		meta.SetStatusCondition(&r.Status.Conditions, c)
+	}
+}
+
+// +kubebuilder:object:root=true
+
+// ControlPlaneMetricSetList contains a list of ControlPlaneMetricSet
+type ControlPlaneMetricSetList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ControlPlaneMetricSet `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(func(s *runtime.Scheme) error {
+		s.AddKnownTypes(GroupVersion, &ControlPlaneMetricSet{}, &ControlPlaneMetricSetList{})
+		return nil
+	})
+}
@@ -18,6 +18,7 @@ package v1alpha1
 
 import (
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 )
@@ -25,9 +26,96 @@ import (
 // Connection defines the connection details for the DataSink
 type Connection struct {
 	// Endpoint specifies the target endpoint URL
-	// Currently supported protocols are "http", "https", "grcp", and "grpcs"
-	// +kubebuilder:validation:Pattern=`^(http|https|grcp|grpcs)://.*$`
-	Endpoint string `json:"endpoint"`
+	// Currently supported protocols are "http", "https", "grpc", and "grpcs".
+	// OTLP data sinks export with OTLP/gRPC to grpc and grpcs endpoints, e.g. grpcs://collector.example.com:4317
+	// +kubebuilder:validation:Pattern=`^(http|https|grpc|grpcs)://.*$`
+	// +optional
+	Endpoint string `json:"endpoint,omitempty"`
+	// Proxy is the HTTP proxy the data sink is reached through, instead of the proxy of the operator's environment.
+	// It is used by all exporters sending over HTTP.
+	// +optional
+	Proxy *Proxy `json:"proxy,omitempty"`
+	// CABundle references a key in a Kubernetes Secret containing PEM encoded CA certificates
+	// that are trusted for the endpoint in addition to the system CAs, e.g. of a TLS intercepting proxy
+	// +optional
+	CABundle *corev1.SecretKeySelector `json:"caBundleSecretKeyRef,omitempty"`
+	// Compression of the payloads sent to the data sink: None (default) or Gzip
+	// +kubebuilder:validation:Enum=None;Gzip
+	// +optional
+	Compression Compression `json:"compression,omitempty"`
+	// MaxPayloadBytes is the maximum size of the uncompressed payload of a single request.
+	// Larger exports, e.g. of high-cardinality federated metrics, are split into multiple requests
+	// instead of being rejected by the request size limit of the data sink. The size of OTLP payloads is estimated,
+	// so the limit should leave some headroom. If not set, exports are not split by size.
+	// +kubebuilder:validation:Minimum=1024
+	// +optional
+	MaxPayloadBytes int64 `json:"maxPayloadBytes,omitempty"`
+	// Timeout of a single export request, 10s by default like the OTLP exporters.
+	// Requests that time out are retried according to retry.
+	// +optional
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+	// Retry configures how failed exports are retried with exponential backoff before the reconcile fails.
+	// Rate limited and unavailable responses are retried, honoring their Retry-After header.
+	// +optional
+	Retry *ExportRetry `json:"retry,omitempty"`
+	// GRPC configures the connection to grpc and grpcs endpoints
+	// +optional
+	GRPC *GRPCSettings `json:"grpc,omitempty"`
+}
+
+// Compression of the payloads sent to the data sink
+type Compression string
+
+const (
+	// CompressionNone sends the payloads uncompressed
+	CompressionNone Compression = "None"
+	// CompressionGzip compresses the payloads with gzip
+	CompressionGzip Compression = "Gzip"
+)
+
+// ExportRetry configures the exponential backoff of failed exports.
+// Throttling responses of the data sink, e.g. with a Retry-After header, are honored.
+// It applies to all exporters except Stdout.
+type ExportRetry struct {
+	// Enabled retries failed exports, if false a failed export fails the reconcile immediately
+	// +kubebuilder:default:=true
+	// +optional
+	Enabled *bool `json:"enabled,omitempty"`
+	// InitialInterval is the time waited after the first failure before retrying
+	// +kubebuilder:default:="5s"
+	// +optional
+	InitialInterval metav1.Duration `json:"initialInterval,omitempty"`
+	// MaxInterval is the upper bound of the time waited between retries
+	// +kubebuilder:default:="30s"
+	// +optional
+	MaxInterval metav1.Duration `json:"maxInterval,omitempty"`
+	// MaxElapsedTime is the maximum time spent on an export including its retries, after which it fails
+	// +kubebuilder:default:="1m"
+	// +optional
+	MaxElapsedTime metav1.Duration `json:"maxElapsedTime,omitempty"`
+}
+
+// GRPCSettings configures the gRPC connection of OTLP data sinks with grpc and grpcs endpoints
+type GRPCSettings struct {
+	// KeepaliveTime is the time without activity after which the client pings the collector
+	// to check that the connection is alive, e.g. to keep it open through load balancers.
+	// Values below 10s are raised to 10s. If not set, no keepalive pings are sent.
+	// +optional
+	KeepaliveTime *metav1.Duration `json:"keepaliveTime,omitempty"`
+	// KeepaliveTimeout is the time waited for the response to a keepalive ping before the connection is closed
+	// +kubebuilder:default:="20s"
+	// +optional
+	KeepaliveTimeout metav1.Duration `json:"keepaliveTimeout,omitempty"`
+}
+
+// Proxy defines the HTTP proxy used to reach the data sink
+type Proxy struct {
+	// URL of the proxy, e.g. http://proxy.example.com:3128
+	// +kubebuilder:validation:Pattern=`^(http|https|socks5)://.*$`
+	URL string `json:"url"`
+	// NoProxy is a comma separated list of hosts, domains and CIDRs reached without the proxy, like NO_PROXY
+	// +optional
+	NoProxy string `json:"noProxy,omitempty"`
 }
 
 // APIKeyAuthentication defines API key authentication configuration
@@ -58,13 +146,226 @@ type Authentication struct {
 	Certificate *CertificateAuthentication `json:"certificate,omitempty"`
 }
 
+// DataSinkType selects the exporter that sends the metrics to the data sink
+type DataSinkType string
+
+const (
+	// DataSinkTypeOTLP exports the metrics with OTLP over HTTP or gRPC, depending on the protocol of the endpoint
+	DataSinkTypeOTLP DataSinkType = "OTLP"
+	// DataSinkTypeStdout writes the metrics as JSON lines to the standard output of the operator, for debugging
+	DataSinkTypeStdout DataSinkType = "Stdout"
+	// DataSinkTypeDynatrace exports the metrics to the Dynatrace metrics ingest API v2, without an OpenTelemetry collector
+	DataSinkTypeDynatrace DataSinkType = "Dynatrace"
+	// DataSinkTypeInfluxDB writes the metrics in line protocol to the write API of InfluxDB v2
+	DataSinkTypeInfluxDB DataSinkType = "InfluxDB"
+	// DataSinkTypeWebhook posts the metrics rendered by a Go template to an HTTP endpoint
+	DataSinkTypeWebhook DataSinkType = "Webhook"
+)
+
 // DataSinkSpec defines the desired state of DataSink
+// +kubebuilder:validation:XValidation:rule="self.type == 'Stdout' || (has(self.connection) && has(self.connection.endpoint))",message="connection.endpoint is required unless type is Stdout"
+// +kubebuilder:validation:XValidation:rule="self.type != 'InfluxDB' || has(self.influxDB)",message="influxDB is required if type is InfluxDB"
+// +kubebuilder:validation:XValidation:rule="self.type != 'Webhook' || has(self.webhook)",message="webhook is required if type is Webhook"
+// +kubebuilder:validation:XValidation:rule="!has(self.otlp) || self.type == 'OTLP'",message="otlp is only supported if type is OTLP"
 type DataSinkSpec struct {
-	// Connection specifies the connection details for the data sink
-	Connection Connection `json:"connection"`
+	// Type selects the exporter of the data sink: OTLP (default), Stdout, Dynatrace, InfluxDB or Webhook
+	// +kubebuilder:validation:Enum=OTLP;Stdout;Dynatrace;InfluxDB;Webhook
+	// +kubebuilder:default:="OTLP"
+	// +optional
+	Type DataSinkType `json:"type,omitempty"`
+	// Connection specifies the connection details for the data sink, it is required unless the type is Stdout
+	// +optional
+	Connection Connection `json:"connection,omitempty"`
 	// Authentication specifies the authentication configuration
 	// +optional
 	Authentication *Authentication `json:"authentication,omitempty"`
+	// InfluxDB specifies the organization and bucket written to if the type is InfluxDB
+	// +optional
+	InfluxDB *InfluxDBSettings `json:"influxDB,omitempty"`
+	// Webhook specifies the payload and headers of the requests if the type is Webhook
+	// +optional
+	Webhook *WebhookSettings `json:"webhook,omitempty"`
+	// OTLP specifies the temporality and aggregation of the exported metrics if the type is OTLP
+	// +optional
+	OTLP *OTLPSettings `json:"otlp,omitempty"`
+	// DeletedSeries decides what is exported for the series of a metric when the metric is deleted
+	// +optional
+	DeletedSeries *DeletedSeries `json:"deletedSeries,omitempty"`
+	// DimensionPolicy restricts the dimensions exported to the data sink,
+	// e.g. to guarantee that no resource names or namespaces leave the cluster
+	// +optional
+	DimensionPolicy *DimensionPolicy `json:"dimensionPolicy,omitempty"`
+	// AllowedNamespaces restricts the namespaces whose metrics may export to the data sink,
+	// so that the metrics of one tenant cannot export into the data sink of another.
+	// Metrics of all namespaces may reference the data sink if it is empty, metrics in its own namespace always may.
+	// +listType=set
+	// +optional
+	AllowedNamespaces []string `json:"allowedNamespaces,omitempty"`
+}
+
+// InfluxDBSettings specifies where the metrics are written in InfluxDB v2.
+// The endpoint of the connection is the base URL of InfluxDB, the API key is the InfluxDB API token.
+type InfluxDBSettings struct {
+	// Org is the name of the organization the bucket belongs to
+	// +kubebuilder:validation:MinLength=1
+	Org string `json:"org"`
+	// Bucket is the name of the bucket the metrics are written to
+	// +kubebuilder:validation:MinLength=1
+	Bucket string `json:"bucket"`
+}
+
+// WebhookSettings specifies the requests posted to the endpoint of the connection.
+// Each export is a single POST request whose body is rendered by the template, an export without data points sends nothing.
+type WebhookSettings struct {
+	// Template is a Go text/template rendering the body of the request.
+	// It is executed with .DataSink, the namespace/name of the data sink, and .DataPoints,
+	// each with .Metric, .Description, .Unit, .Time, .Dimensions and .Value.
+	// The function json marshals a value to JSON. Defaults to the data points as a JSON array.
+	// +optional
+	Template string `json:"template,omitempty"`
+	// ContentType is the Content-Type header of the requests
+	// +kubebuilder:default:="application/json"
+	// +optional
+	ContentType string `json:"contentType,omitempty"`
+	// HeadersSecretRef references a Secret in the namespace of the DataSink whose keys and values are sent as headers,
+	// e.g. an Authorization header
+	// +optional
+	HeadersSecretRef *corev1.LocalObjectReference `json:"headersSecretRef,omitempty"`
+}
+
+// Temporality of the exported histograms
+type Temporality string
+
+const (
+	// TemporalityCumulative exports histograms accumulated since the start of the collection
+	TemporalityCumulative Temporality = "Cumulative"
+	// TemporalityDelta exports histograms accumulated since the last export
+	TemporalityDelta Temporality = "Delta"
+)
+
+// OTLPAggregation is the aggregation of the recorded values of a metric into the data points exported with OTLP
+type OTLPAggregation string
+
+const (
+	// OTLPAggregationLastValue exports the last recorded value as a gauge
+	OTLPAggregationLastValue OTLPAggregation = "LastValue"
+	// OTLPAggregationHistogram exports the recorded values as a histogram with explicit bucket boundaries
+	OTLPAggregationHistogram OTLPAggregation = "Histogram"
+	// OTLPAggregationExponentialHistogram exports the recorded values as a base2 exponential histogram
+	OTLPAggregationExponentialHistogram OTLPAggregation = "ExponentialHistogram"
+)
+
+// OTLPSettings specifies how the recorded values are aggregated and exported to OTLP data sinks,
+// e.g. for backends that only accept histograms with delta temporality
+// +kubebuilder:validation:XValidation:rule="!has(self.histogramBoundaries) || self.aggregation == 'Histogram'",message="histogramBoundaries requires the Histogram aggregation"
+type OTLPSettings struct {
+	// Temporality of the exported histograms: Cumulative (default) or Delta.
+	// Gauges have no temporality.
+	// +kubebuilder:validation:Enum=Cumulative;Delta
+	// +kubebuilder:default:="Cumulative"
+	// +optional
+	Temporality Temporality `json:"temporality,omitempty"`
+	// Aggregation of the recorded values: LastValue (default) exports gauges,
+	// Histogram or ExponentialHistogram export the distribution of the recorded values instead
+	// +kubebuilder:validation:Enum=LastValue;Histogram;ExponentialHistogram
+	// +kubebuilder:default:="LastValue"
+	// +optional
+	Aggregation OTLPAggregation `json:"aggregation,omitempty"`
+	// HistogramBoundaries are the ascending bucket boundaries of the Histogram aggregation,
+	// the default boundaries of OpenTelemetry are used if empty
+	// +kubebuilder:validation:MaxItems=64
+	// +kubebuilder:validation:XValidation:rule="self.isSorted()",message="histogramBoundaries must be ascending"
+	// +optional
+	HistogramBoundaries []int64 `json:"histogramBoundaries,omitempty"`
+}
+
+// DimensionPolicy drops and redacts dimensions before they are exported to a data sink.
+// Dimensions are first filtered by their keys, then the values of the remaining dimensions are redacted.
+type DimensionPolicy struct {
+	// AllowedKeys are the dimension keys that are exported, all other dimensions are dropped.
+	// If empty, all keys that are not denied are exported.
+	// +optional
+	// +listType=set
+	AllowedKeys []string `json:"allowedKeys,omitempty"`
+	// DeniedKeys are the dimension keys that are never exported, they take precedence over AllowedKeys
+	// +optional
+	// +listType=set
+	DeniedKeys []string `json:"deniedKeys,omitempty"`
+	// Redactions replace the parts of dimension values that match a regular expression
+	// +optional
+	Redactions []DimensionRedaction `json:"redactions,omitempty"`
+}
+
+// DimensionRedaction replaces the parts of dimension values that match a regular expression
+type DimensionRedaction struct {
+	// Keys are the dimension keys whose values are redacted, if empty the values of all dimensions are redacted
+	// +optional
+	// +listType=set
+	Keys []string `json:"keys,omitempty"`
+	// Pattern is the regular expression in RE2 syntax matched against the dimension values
+	// +kubebuilder:validation:MinLength=1
+	Pattern string `json:"pattern"`
+	// Replacement replaces each match of the pattern, it may refer to submatches like $1
+	// +kubebuilder:default:="REDACTED"
+	// +optional
+	Replacement string `json:"replacement,omitempty"`
+}
+
+// DeletedSeriesPolicy decides what is exported for the series of a deleted metric
+type DeletedSeriesPolicy string
+
+const (
+	// DeletedSeriesKeep exports nothing, the series keep their last value in the data sink
+	DeletedSeriesKeep DeletedSeriesPolicy = "Keep"
+	// DeletedSeriesFinalZero exports 0 for every series of the metric before the metric is deleted
+	DeletedSeriesFinalZero DeletedSeriesPolicy = "FinalZero"
+)
+
+// DeletedSeries decides what is exported for the series of a metric when the metric is deleted,
+// so dashboards do not show the last value of a deleted metric forever
+type DeletedSeries struct {
+	// Policy is Keep to leave the series of a deleted metric at their last value,
+	// or FinalZero to export 0 for every series before the metric is deleted.
+	// With FinalZero, the metrics exporting to the data sink get a finalizer.
+	// +kubebuilder:validation:Enum=Keep;FinalZero
+	// +kubebuilder:default:="Keep"
+	// +optional
+	Policy DeletedSeriesPolicy `json:"policy,omitempty"`
+	// GracePeriod is how long the final export is retried before the metric is deleted without it
+	// +kubebuilder:default:="5m"
+	// +optional
+	GracePeriod metav1.Duration `json:"gracePeriod,omitempty"`
+}
+
+// CircuitBreakerState is the state of the circuit breaker of a DataSink
+type CircuitBreakerState string
+
+const (
+	// CircuitClosed lets all exports to the data sink pass
+	CircuitClosed CircuitBreakerState = "Closed"
+	// CircuitOpen skips all exports to the data sink after consecutive failures
+	CircuitOpen CircuitBreakerState = "Open"
+	// CircuitHalfOpen lets a single trial export pass to check whether the data sink recovered
+	CircuitHalfOpen CircuitBreakerState = "HalfOpen"
+)
+
+// CircuitBreakerStatus is the state of the circuit breaker shared by all metrics exporting to a DataSink
+type CircuitBreakerStatus struct {
+	// State is the state of the circuit breaker
+	// +kubebuilder:validation:Enum=Closed;Open;HalfOpen
+	State CircuitBreakerState `json:"state"`
+
+	// ConsecutiveFailures is the number of exports that failed since the last successful export
+	// +optional
+	ConsecutiveFailures int `json:"consecutiveFailures,omitempty"`
+
+	// OpenedAt is the time the circuit breaker opened the last time
+	// +optional
+	OpenedAt *metav1.Time `json:"openedAt,omitempty"`
+
+	// LastError is the error of the last failed export
+	// +optional
+	LastError string `json:"lastError,omitempty"`
 }
 
 // DataSinkStatus defines the observed state of DataSink
@@ -72,12 +373,29 @@ type DataSinkStatus struct {
 	// Conditions represent the latest available observations of an object's state
 	// +optional
 	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// LastProbeTime is the time the connectivity of the data sink was last checked
+	// +optional
+	LastProbeTime *metav1.Time `json:"lastProbeTime,omitempty"`
+
+	// LastSuccessfulProbeTime is the time the data sink last accepted a probe export
+	// +optional
+	LastSuccessfulProbeTime *metav1.Time `json:"lastSuccessfulProbeTime,omitempty"`
+
+	// CircuitBreaker is the state of the circuit breaker that skips exports while the data sink fails
+	// +optional
+	CircuitBreaker *CircuitBreakerStatus `json:"circuitBreaker,omitempty"`
 }
 
 // DataSink is the Schema for the datasinks API
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
+// +kubebuilder:resource:shortName=sink
+// +kubebuilder:printcolumn:name="TYPE",type="string",JSONPath=".spec.type",priority=1
 // +kubebuilder:printcolumn:name="ENDPOINT",type="string",JSONPath=".spec.connection.endpoint"
+// +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status"
+// +kubebuilder:printcolumn:name="DEGRADED",type="string",JSONPath=".status.conditions[?(@.type==\"Degraded\")].status"
+// +kubebuilder:printcolumn:name="CIRCUIT",type="string",JSONPath=".status.circuitBreaker.state",priority=1
 // +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
 type DataSink struct {
 	metav1.TypeMeta   `json:",inline"`
@@ -87,6 +405,13 @@ type DataSink struct {
 	Status DataSinkStatus `json:"status,omitempty"`
 }
 
+// SetConditions sets the conditions of the DataSink
+func (r *DataSink) SetConditions(conditions ...metav1.Condition) {
+	for _, c := range conditions {
+		meta.SetStatusCondition(&r.Status.Conditions, c)
+	}
+}
+
 // DataSinkList contains a list of DataSink
 // +kubebuilder:object:root=true
 type DataSinkList struct {
@@ -17,6 +17,7 @@ limitations under the License.
 package v1alpha1
 
 import (
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 )
@@ -27,8 +28,99 @@ type FederateClusterAccessRef struct {
 	Namespace string `json:"namespace,omitempty"`
 }
 
+// DefaultKubeConfigSecretKey is the key of the kubeconfig in secrets selected by a KubeConfigSecretSelector
+const DefaultKubeConfigSecretKey = "kubeconfig"
+
+// KubeConfigSecretSelector selects the Secrets holding the kubeconfigs of member clusters, one Secret per cluster
+type KubeConfigSecretSelector struct {
+	// Selector matches the labels of the Secrets, e.g. "cluster.x-k8s.io/cluster-name" for Cluster API
+	Selector metav1.LabelSelector `json:"selector"`
+
+	// Key in the Secrets that holds the kubeconfig, e.g. "value" for Cluster API.
+	// Selected Secrets without the key are skipped.
+	// +optional
+	// +kubebuilder:default:=kubeconfig
+	Key string `json:"key,omitempty"`
+}
+
+// GetKey returns the key of the kubeconfig in the selected secrets
+func (s *KubeConfigSecretSelector) GetKey() string {
+	if s.Key == "" {
+		return DefaultKubeConfigSecretKey
+	}
+	return s.Key
+}
+
+// DefaultAdminKubeconfigExpirationSeconds is the default validity of the admin kubeconfigs requested for Gardener Shoots
+const DefaultAdminKubeconfigExpirationSeconds = 3600
+
+// GardenerShoots discovers the Shoots of a Gardener project as member clusters,
+// their kubeconfigs are requested through the adminkubeconfig subresource of the Shoots
+type GardenerShoots struct {
+	// Selector matches the labels of the Shoots, all Shoots of the namespace are selected if it is omitted.
+	// Hibernated Shoots and Shoots in deletion are skipped.
+	// +optional
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+
+	// ExpirationSeconds is the validity of the requested admin kubeconfigs,
+	// they are requested again shortly before they expire
+	// +optional
+	// +kubebuilder:validation:Minimum=600
+	// +kubebuilder:default:=3600
+	ExpirationSeconds int64 `json:"expirationSeconds,omitempty"`
+}
+
+// GetExpirationSeconds returns the validity of the requested admin kubeconfigs
+func (g *GardenerShoots) GetExpirationSeconds() int64 {
+	if g.ExpirationSeconds == 0 {
+		return DefaultAdminKubeconfigExpirationSeconds
+	}
+	return g.ExpirationSeconds
+}
+
+// ClusterAPIClusters discovers the Cluster API Clusters of a namespace as member clusters,
+// their kubeconfigs are read from the <cluster name>-kubeconfig Secrets published by Cluster API
+type ClusterAPIClusters struct {
+	// Selector matches the labels of the Clusters, all Clusters of the namespace are selected if it is omitted.
+	// Clusters in deletion and Clusters without a kubeconfig Secret yet are skipped.
+	// +optional
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+}
+
+// OCMManagedClusters discovers the ManagedClusters of an Open Cluster Management hub as member clusters.
+// They are accessed through the API server URL of their client config with the token of a ManagedServiceAccount.
+type OCMManagedClusters struct {
+	// Selector matches the labels of the ManagedClusters, all ManagedClusters are selected if it is omitted.
+	// ManagedClusters in deletion and ManagedClusters without a client config URL are skipped.
+	// +optional
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+
+	// ManagedServiceAccount is the name of the ManagedServiceAccount whose token Secret,
+	// in the namespace of each ManagedCluster on the hub, is used to access the clusters
+	// +kubebuilder:validation:MinLength=1
+	ManagedServiceAccount string `json:"managedServiceAccount"`
+}
+
+// KarmadaClusters discovers the Clusters of a Karmada control plane as member clusters.
+// They are accessed through their API endpoint with the token of the Secret referenced by the Cluster.
+type KarmadaClusters struct {
+	// Selector matches the labels of the Clusters, all Clusters are selected if it is omitted.
+	// Clusters in deletion and Clusters without API endpoint or Secret reference, e.g. in Pull mode, are skipped.
+	// +optional
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+}
+
+// ClusterAgents selects the ClusterAgents registered by agents running in the member clusters.
+// The agents collect the FederatedMetrics referencing the access themselves and export them directly,
+// so the member clusters need not accept connections from the hub.
+type ClusterAgents struct {
+	// Selector matches the labels of the ClusterAgents, all ClusterAgents of the namespace are selected if it is omitted
+	// +optional
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+}
+
 // FederatedClusterAccessSpec defines the desired state of FederatedClusterAccess
-// +kubebuilder:validation:XValidation:rule="(has(self.kubeConfigPath) && size(self.kubeConfigPath) > 0) != (has(self.secretRefPath) && size(self.secretRefPath) > 0)",message="exactly one of kubeConfigPath or secretRefPath must be set"
+// +kubebuilder:validation:XValidation:rule="[has(self.kubeConfigPath) && size(self.kubeConfigPath) > 0, has(self.secretRefPath) && size(self.secretRefPath) > 0, has(self.secretSelector), has(self.gardener), has(self.clusterAPI), has(self.ocm), has(self.karmada), has(self.agents)].filter(x, x).size() == 1",message="exactly one of kubeConfigPath, secretRefPath, secretSelector, gardener, clusterAPI, ocm, karmada or agents must be set"
 type FederatedClusterAccessSpec struct {
 	// Define the target resources that should be monitored
 	Target GroupVersionKind `json:"target,omitempty"`
@@ -42,13 +134,17 @@ type FederatedClusterAccessSpec struct {
 	FieldSelector string `json:"fieldSelector,omitempty"`
 
 	// Restricts the scope of the target resource to a specific namespace
-	// Only applicable for namespaced resources
+	// Only applicable for namespaced resources.
+	// In combination with SecretSelector, the namespace the secrets are looked up in, defaults to the namespace of the FederatedClusterAccess.
+	// In combination with Gardener, the project namespace of the Shoots, defaults to the namespace of the FederatedClusterAccess.
+	// In combination with ClusterAPI, the namespace of the Clusters, defaults to the namespace of the FederatedClusterAccess.
+	// In combination with Agents, the namespace of the ClusterAgents, defaults to the namespace of the FederatedClusterAccess.
 	// +optional
 	Namespace string `json:"namespace,omitempty"`
 
 	// Field that contains the kubeconfig to access the target cluster. Use dot notation to access nested fields.
 	// The field can be of type string or object.
-	// Either KubeConfigPath or SecretRefPath must be set.
+	// Exactly one of KubeConfigPath, SecretRefPath, SecretSelector, Gardener, ClusterAPI, OCM, Karmada or Agents must be set.
 	// +optional
 	KubeConfigPath string `json:"kubeConfigPath,omitempty"`
 
@@ -56,17 +152,115 @@ type FederatedClusterAccessSpec struct {
 	// The field needs to be of type SecretRef and contain the reference to the secret that holds the kubeconfig (name, namespace, key).
 	// If namespace is omitted, the namespace of target object will be used as default.
 	// If key is omitted, "kubeconfig" will be used as default.
-	// Either KubeConfigPath or SecretRefPath must be set.
+	// Exactly one of KubeConfigPath, SecretRefPath, SecretSelector, Gardener, ClusterAPI, OCM, Karmada or Agents must be set.
 	// +optional
 	SecretRefPath string `json:"secretRefPath,omitempty"`
+
+	// SecretSelector discovers member clusters through labeled Secrets holding their kubeconfigs,
+	// as published e.g. by Cluster API or Gardener. The target is not used in this mode.
+	// Exactly one of KubeConfigPath, SecretRefPath, SecretSelector, Gardener, ClusterAPI, OCM, Karmada or Agents must be set.
+	// +optional
+	SecretSelector *KubeConfigSecretSelector `json:"secretSelector,omitempty"`
+
+	// Gardener discovers member clusters from the Gardener Shoots of a project namespace,
+	// with short-lived admin kubeconfigs requested for each Shoot. The target is not used in this mode.
+	// Exactly one of KubeConfigPath, SecretRefPath, SecretSelector, Gardener, ClusterAPI, OCM, Karmada or Agents must be set.
+	// +optional
+	Gardener *GardenerShoots `json:"gardener,omitempty"`
+
+	// ClusterAPI discovers member clusters from the Cluster API Clusters of a namespace and their kubeconfig Secrets.
+	// The target is not used in this mode.
+	// Exactly one of KubeConfigPath, SecretRefPath, SecretSelector, Gardener, ClusterAPI, OCM, Karmada or Agents must be set.
+	// +optional
+	ClusterAPI *ClusterAPIClusters `json:"clusterAPI,omitempty"`
+
+	// OCM discovers member clusters from the ManagedClusters of an Open Cluster Management hub.
+	// The target and the namespace are not used in this mode.
+	// Exactly one of KubeConfigPath, SecretRefPath, SecretSelector, Gardener, ClusterAPI, OCM, Karmada or Agents must be set.
+	// +optional
+	OCM *OCMManagedClusters `json:"ocm,omitempty"`
+
+	// Karmada discovers member clusters from the Clusters of a Karmada control plane.
+	// The target and the namespace are not used in this mode.
+	// Exactly one of KubeConfigPath, SecretRefPath, SecretSelector, Gardener, ClusterAPI, OCM, Karmada or Agents must be set.
+	// +optional
+	Karmada *KarmadaClusters `json:"karmada,omitempty"`
+
+	// Agents discovers member clusters from the ClusterAgents registered by agents running in them.
+	// Only FederatedMetrics are collected by the agents, the hub aggregates their results.
+	// The target is not used in this mode.
+	// Exactly one of KubeConfigPath, SecretRefPath, SecretSelector, Gardener, ClusterAPI, OCM, Karmada or Agents must be set.
+	// +optional
+	Agents *ClusterAgents `json:"agents,omitempty"`
+
+	// ClusterNameFrom selects the cluster dimension of the data points of member clusters.
+	// Host uses the host name of the API server, Member the name of the resource providing access to the cluster.
+	// +optional
+	// +kubebuilder:validation:Enum=Host;Member
+	// +kubebuilder:default:=Host
+	ClusterNameFrom string `json:"clusterNameFrom,omitempty"`
+
+	// ClusterLabels are exported as additional dimensions of every data point of the member clusters.
+	// They do not override dimensions of the metric.
+	// +optional
+	ClusterLabels map[string]string `json:"clusterLabels,omitempty"`
+
+	// MemberLabels are labels of the resources providing access to the member clusters,
+	// that are exported as additional dimensions of the data points of the respective cluster.
+	// Missing labels are exported with an empty value.
+	// +optional
+	MemberLabels []string `json:"memberLabels,omitempty"`
+}
+
+const (
+	// ClusterNameFromHost uses the host name of the API server of a member cluster as cluster dimension
+	ClusterNameFromHost = "Host"
+	// ClusterNameFromMember uses the name of the resource providing access to a member cluster as cluster dimension
+	ClusterNameFromMember = "Member"
+)
+
+// FederatedCluster identifies a member cluster by the resource that provides access to it
+type FederatedCluster struct {
+	// Name of the resource providing access to the cluster
+	Name string `json:"name"`
+
+	// Namespace of the resource providing access to the cluster
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// String returns the namespaced name of the resource providing access to the cluster
+func (c FederatedCluster) String() string {
+	if c.Namespace == "" {
+		return c.Name
+	}
+	return c.Namespace + "/" + c.Name
 }
 
 // FederatedClusterAccessStatus defines the observed state of FederatedClusterAccess
 type FederatedClusterAccessStatus struct {
+	// Clusters lists the member clusters currently matching the target
+	// +optional
+	Clusters []FederatedCluster `json:"clusters,omitempty"`
+
+	// LastRefreshTime is the time the list of member clusters was last refreshed
+	// +optional
+	LastRefreshTime *metav1.Time `json:"lastRefreshTime,omitempty"`
+
+	// CredentialsExpiry is the earliest time the client certificate or token of a member cluster expires,
+	// not set if the credentials do not expire or are requested by the operator
+	// +optional
+	CredentialsExpiry *metav1.Time `json:"credentialsExpiry,omitempty"`
+
+	// Conditions represent the latest available observations of an object's state
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
 }
 
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
+// +kubebuilder:resource:shortName=fca
+// +kubebuilder:printcolumn:name="REFRESHED",type="date",JSONPath=".status.lastRefreshTime"
 
 // FederatedClusterAccess is the Schema for the federatedclusteraccesses API
 type FederatedClusterAccess struct {
@@ -77,6 +271,13 @@ type FederatedClusterAccess struct {
 	Status FederatedClusterAccessStatus `json:"status,omitempty"`
 }
 
+// SetConditions sets the conditions of the federated cluster access
+func (r *FederatedClusterAccess) SetConditions(conditions ...metav1.Condition) {
+	for _, c := range conditions {
+		meta.SetStatusCondition(&r.Status.Conditions, c)
+	}
+}
+
 // +kubebuilder:object:root=true
 
 // FederatedClusterAccessList contains a list of FederatedClusterAccess
@@ -28,6 +28,20 @@ type FederatedManagedMetricSpec struct {
 
 	// +optional
 	Description string `json:"description,omitempty"`
+	// Sets the unit of the metric in UCUM notation, e.g. "1", "s" or "By", that will be shown in Dynatrace(or other providers)
+	// +kubebuilder:validation:MaxLength=63
+	// +optional
+	Unit string `json:"unit,omitempty"`
+
+	// Defines which managed resources to observe, unset parts of the group, version and kind match any value
+	// +optional
+	Target *GroupVersionKind `json:"target,omitempty"`
+	// Defines dimensions of the metric like those of a ManagedMetric.
+	// If not specified, the kind, API version, UID and status.conditions of the resources are used as dimensions.
+	// The cluster and its labels are added to the dimensions of every data point.
+	// +kubebuilder:validation:XValidation:rule="self.all(d, has(d.name) && (has(d.fieldPath) || has(d.source)))",message="every dimension requires a name and a fieldPath or source"
+	// +optional
+	Dimensions []Projection `json:"dimensions,omitempty"`
 
 	// Define labels of your object to adapt filters of the query
 	// +optional
@@ -36,18 +50,53 @@ type FederatedManagedMetricSpec struct {
 	// +optional
 	FieldSelector string `json:"fieldSelector,omitempty"`
 
-	// Projections []Projection `json:"projections,omitempty"`
-
 	// Define in what interval the query should be recorded
 	// +kubebuilder:default:="10m"
 	Interval metav1.Duration `json:"interval,omitempty"`
 
+	// Schedule is a cron expression like "0 * * * *" or "@daily", evaluated in UTC, that defines when the federated managed metric is exported.
+	// If set, it is used instead of the interval and the first export happens at the first scheduled time.
+	// +kubebuilder:validation:MaxLength=100
+	// +optional
+	Schedule string `json:"schedule,omitempty"`
+
+	// Priority decides which metrics are reconciled first when many metrics are due at once,
+	// so important metrics are not delayed behind a large number of less important ones.
+	// +kubebuilder:validation:Enum=high;normal;low
+	// +kubebuilder:default:="normal"
+	// +optional
+	Priority Priority `json:"priority,omitempty"`
+
+	// RetryPolicy defines the delay between the retries of failed collections and after how many failures they stop
+	// +optional
+	RetryPolicy *RetryPolicy `json:"retryPolicy,omitempty"`
+
 	// DataSinkRef specifies the DataSink to be used for this federated managed metric.
 	// If omitted, no OTLP export is performed; metrics are only exposed via /metrics.
 	// If provided, the referenced DataSink must exist or reconciliation will fail.
 	// +optional
 	DataSinkRef *DataSinkReference `json:"dataSinkRef,omitempty"`
 
+	// MeterName is the name of the OpenTelemetry meter, i.e. the instrumentation scope the metric is exported with,
+	// so downstream pipelines can route by scope. Defaults to "managed".
+	// +optional
+	// +kubebuilder:validation:MaxLength=255
+	// +kubebuilder:validation:Pattern=`^[a-zA-Z][a-zA-Z0-9_./-]*$`
+	MeterName string `json:"meterName,omitempty"`
+
+	// ScopeAttributes are exported as attributes of the instrumentation scope
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	ScopeAttributes []ScopeAttribute `json:"scopeAttributes,omitempty"`
+
+	// StaticDimensions are added to every data point of the metric, e.g. a tenant read from a ConfigMap of the namespace.
+	// They never override the dimensions of the metric itself.
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	StaticDimensions []StaticDimension `json:"staticDimensions,omitempty"`
+
 	FederatedClusterAccessRef FederateClusterAccessRef `json:"federateClusterAccessRef,omitempty"`
 }
 
@@ -61,6 +110,21 @@ type FederatedManagedMetricStatus struct {
 	// Conditions represent the latest available observations of an object's state
 	Conditions        []metav1.Condition `json:"conditions,omitempty"`
 	LastReconcileTime *metav1.Time       `json:"lastReconcileTime,omitempty"`
+
+	// NextRunTime is the time the federated managed metric is exported next
+	// +optional
+	NextRunTime *metav1.Time `json:"nextRunTime,omitempty"`
+
+	// ConsecutiveFailures is the number of collections that failed in a row since the last successful one
+	// +optional
+	ConsecutiveFailures int32 `json:"consecutiveFailures,omitempty"`
+
+	ExportStatus `json:",inline"`
+
+	// StaticDimensionsHash identifies the values of the static dimensions of the last export,
+	// the metric is exported again when a value read from a ConfigMap or Secret changes
+	// +optional
+	StaticDimensionsHash string `json:"staticDimensionsHash,omitempty"`
 }
 
 // SetConditions sets the conditions of the FederatedManagedMetric
@@ -70,8 +134,27 @@ func (r *FederatedManagedMetric) SetConditions(conditions ...metav1.Condition) {
 	}
 }
 
+// GetPriority returns the priority of the FederatedManagedMetric, metrics without a priority have the normal priority
+func (r *FederatedManagedMetric) GetPriority() Priority {
+	if r.Spec.Priority == "" {
+		return PriorityNormal
+	}
+	return r.Spec.Priority
+}
+
+// GetStaticDimensions returns the static dimensions of the FederatedManagedMetric
+func (r *FederatedManagedMetric) GetStaticDimensions() []StaticDimension {
+	return r.Spec.StaticDimensions
+}
+
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
+// +kubebuilder:resource:shortName=fmmtr
+// +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.ready"
+// +kubebuilder:printcolumn:name="CLUSTERS",type="integer",JSONPath=".status.observation.activeCount"
+// +kubebuilder:printcolumn:name="INTERVAL",type="string",JSONPath=".spec.interval"
+// +kubebuilder:printcolumn:name="SINK",type="string",JSONPath=".status.dataSink"
+// +kubebuilder:printcolumn:name="EXPORTED",type="date",JSONPath=".status.lastExportTime"
 
 // FederatedManagedMetric is the Schema for the federatedmanagedmetrics API
 type FederatedManagedMetric struct {
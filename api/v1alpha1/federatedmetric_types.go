@@ -28,6 +28,10 @@ type FederatedMetricSpec struct {
 
 	// +optional
 	Description string `json:"description,omitempty"`
+	// Sets the unit of the metric in UCUM notation, e.g. "1", "s" or "By", that will be shown in Dynatrace(or other providers)
+	// +kubebuilder:validation:MaxLength=63
+	// +optional
+	Unit string `json:"unit,omitempty"`
 
 	// +kubebuilder:validation:Required
 	Target GroupVersionKind `json:"target,omitempty"`
@@ -35,7 +39,8 @@ type FederatedMetricSpec struct {
 	// Define labels of your object to adapt filters of the query
 	// +optional
 	LabelSelector string `json:"labelSelector,omitempty"`
-	// Define fields of your object to adapt filters of the query
+	// Define fields of your object to adapt filters of the query.
+	// Fields the API server doesn't support for the kind are filtered by the operator instead.
 	// +optional
 	FieldSelector string `json:"fieldSelector,omitempty"`
 
@@ -50,17 +55,55 @@ type FederatedMetricSpec struct {
 	// +kubebuilder:default:="10m"
 	Interval metav1.Duration `json:"interval,omitempty"`
 
+	// Schedule is a cron expression like "0 * * * *" or "@daily", evaluated in UTC, that defines when the federated metric is exported.
+	// If set, it is used instead of the interval and the first export happens at the first scheduled time.
+	// +kubebuilder:validation:MaxLength=100
+	// +optional
+	Schedule string `json:"schedule,omitempty"`
+
+	// Priority decides which metrics are reconciled first when many metrics are due at once,
+	// so important metrics are not delayed behind a large number of less important ones.
+	// +kubebuilder:validation:Enum=high;normal;low
+	// +kubebuilder:default:="normal"
+	// +optional
+	Priority Priority `json:"priority,omitempty"`
+
+	// RetryPolicy defines the delay between the retries of failed collections and after how many failures they stop
+	// +optional
+	RetryPolicy *RetryPolicy `json:"retryPolicy,omitempty"`
+
 	// DataSinkRef specifies the DataSink to be used for this federated metric.
 	// If omitted, no OTLP export is performed; metrics are only exposed via /metrics.
 	// If provided, the referenced DataSink must exist or reconciliation will fail.
 	// +optional
 	DataSinkRef *DataSinkReference `json:"dataSinkRef,omitempty"`
 
+	// MeterName is the name of the OpenTelemetry meter, i.e. the instrumentation scope the metric is exported with,
+	// so downstream pipelines can route by scope. Defaults to "federated".
+	// +optional
+	// +kubebuilder:validation:MaxLength=255
+	// +kubebuilder:validation:Pattern=`^[a-zA-Z][a-zA-Z0-9_./-]*$`
+	MeterName string `json:"meterName,omitempty"`
+
+	// ScopeAttributes are exported as attributes of the instrumentation scope
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	ScopeAttributes []ScopeAttribute `json:"scopeAttributes,omitempty"`
+
+	// StaticDimensions are added to every data point of the metric, e.g. a tenant read from a ConfigMap of the namespace.
+	// They never override the dimensions of the metric itself.
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	StaticDimensions []StaticDimension `json:"staticDimensions,omitempty"`
+
 	FederatedClusterAccessRef FederateClusterAccessRef `json:"federateClusterAccessRef,omitempty"`
 }
 
 // FederatedObservation represents the latest available observation of an object's state
 type FederatedObservation struct {
+	// ActiveCount is the number of member clusters the metric was collected from
 	ActiveCount  int `json:"activeCount,omitempty"`
 	FailedCount  int `json:"failedCount,omitempty"`
 	PendingCount int `json:"pendingCount,omitempty"`
@@ -76,6 +119,21 @@ type FederatedMetricStatus struct {
 	// Conditions represent the latest available observations of an object's state
 	Conditions        []metav1.Condition `json:"conditions,omitempty"`
 	LastReconcileTime *metav1.Time       `json:"lastReconcileTime,omitempty"`
+
+	// NextRunTime is the time the federated metric is exported next
+	// +optional
+	NextRunTime *metav1.Time `json:"nextRunTime,omitempty"`
+
+	// ConsecutiveFailures is the number of collections that failed in a row since the last successful one
+	// +optional
+	ConsecutiveFailures int32 `json:"consecutiveFailures,omitempty"`
+
+	ExportStatus `json:",inline"`
+
+	// StaticDimensionsHash identifies the values of the static dimensions of the last export,
+	// the metric is exported again when a value read from a ConfigMap or Secret changes
+	// +optional
+	StaticDimensionsHash string `json:"staticDimensionsHash,omitempty"`
 }
 
 // SetConditions sets the conditions of the FederatedMetric
@@ -85,8 +143,27 @@ func (r *FederatedMetric) SetConditions(conditions ...metav1.Condition) {
 	}
 }
 
+// GetPriority returns the priority of the FederatedMetric, metrics without a priority have the normal priority
+func (r *FederatedMetric) GetPriority() Priority {
+	if r.Spec.Priority == "" {
+		return PriorityNormal
+	}
+	return r.Spec.Priority
+}
+
+// GetStaticDimensions returns the static dimensions of the FederatedMetric
+func (r *FederatedMetric) GetStaticDimensions() []StaticDimension {
+	return r.Spec.StaticDimensions
+}
+
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
+// +kubebuilder:resource:shortName=fmtr
+// +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.ready"
+// +kubebuilder:printcolumn:name="CLUSTERS",type="integer",JSONPath=".status.observation.activeCount"
+// +kubebuilder:printcolumn:name="INTERVAL",type="string",JSONPath=".spec.interval"
+// +kubebuilder:printcolumn:name="SINK",type="string",JSONPath=".status.dataSink"
+// +kubebuilder:printcolumn:name="EXPORTED",type="date",JSONPath=".status.lastExportTime"
 
 // FederatedMetric is the Schema for the federatedmetrics API
 type FederatedMetric struct {
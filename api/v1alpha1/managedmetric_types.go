@@ -22,18 +22,58 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
+var (
+	// DefaultManagedCRDCategories are the CRD categories that identify Crossplane managed resources
+	DefaultManagedCRDCategories = []string{"crossplane", "managed"}
+	// DefaultManagedConditionTypes are the condition types exported as dimensions for Crossplane managed resources
+	DefaultManagedConditionTypes = []string{"Ready", "Synced"}
+)
+
+// ConditionRequirement matches resources whose status condition of the given type has the given status
+type ConditionRequirement struct {
+	// Type is the condition type, e.g. "Ready" or "Synced". Matching is case-insensitive.
+	Type string `json:"type"`
+	// Status is the required status of the condition. A missing condition is treated as "Unknown".
+	// +kubebuilder:validation:Enum=True;False;Unknown
+	Status string `json:"status"`
+}
+
+// Statistics exported by an age aggregation
+const (
+	AgeStatisticOldest  = "oldest"
+	AgeStatisticNewest  = "newest"
+	AgeStatisticAverage = "average"
+)
+
+// AgeAggregation exports age statistics in seconds of the matched resources instead of a data point per resource.
+// The oldest, newest and average age is recorded per resource type with a "statistic" dimension.
+type AgeAggregation struct {
+	// ConditionType measures the time since the last transition of the given condition instead of the time since creation,
+	// e.g. combined with a conditionFilter on Ready=False, how long resources have not been ready.
+	// Resources without the condition are ignored. Matching is case-insensitive.
+	// +optional
+	ConditionType string `json:"conditionType,omitempty"`
+}
+
 // ManagedMetricSpec defines the desired state of ManagedMetric
+// +kubebuilder:validation:XValidation:rule="!has(self.age) || !has(self.dimensions)",message="age cannot be used together with dimensions"
 type ManagedMetricSpec struct {
 	// Sets the name that will be used to identify the metric in Dynatrace(or other providers)
 	Name string `json:"name,omitempty"`
 	// Sets the description that will be used to identify the metric in Dynatrace(or other providers)
 	// +optional
 	Description string `json:"description,omitempty"`
+	// Sets the unit of the metric in UCUM notation, e.g. "1", "s" or "By", that will be shown in Dynatrace(or other providers)
+	// +kubebuilder:validation:MaxLength=63
+	// +optional
+	Unit string `json:"unit,omitempty"`
 	// Defines which managed resources to observe
 	// +optional
 	Target *GroupVersionKind `json:"target,omitempty"`
-	// Defines dimensions of the metric. All specified fields must be nested strings. Nested slices are not supported.
+	// Defines dimensions of the metric, extracted like the projections of a Metric, e.g. from spec.forProvider or status.atProvider.
 	// If not specified, only status.conditions of the CR will be used as dimension.
+	// Dimensions that cannot be extracted are left out of the data points and fail the collection.
+	// +kubebuilder:validation:XValidation:rule="self.all(d, has(d.name) && (has(d.fieldPath) || has(d.source)))",message="every dimension requires a name and a fieldPath or source"
 	// +optional
 	Dimensions []Projection `json:"dimensions,omitempty"`
 	// Define labels of your object to adapt filters of the query
@@ -46,14 +86,97 @@ type ManagedMetricSpec struct {
 	// +kubebuilder:default:="10m"
 	Interval metav1.Duration `json:"interval,omitempty"`
 
+	// Schedule is a cron expression like "0 * * * *" or "@daily", evaluated in UTC, that defines when the managed metric is exported.
+	// If set, it is used instead of the interval and the first export happens at the first scheduled time.
+	// +kubebuilder:validation:MaxLength=100
+	// +optional
+	Schedule string `json:"schedule,omitempty"`
+
+	// Priority decides which metrics are reconciled first when many metrics are due at once,
+	// so important metrics are not delayed behind a large number of less important ones.
+	// +kubebuilder:validation:Enum=high;normal;low
+	// +kubebuilder:default:="normal"
+	// +optional
+	Priority Priority `json:"priority,omitempty"`
+
+	// RetryPolicy defines the delay between the retries of failed collections and after how many failures they stop
+	// +optional
+	RetryPolicy *RetryPolicy `json:"retryPolicy,omitempty"`
+
 	// DataSinkRef specifies the DataSink to be used for this managed metric.
 	// If omitted, no OTLP export is performed; metrics are only exposed via /metrics.
 	// If provided, the referenced DataSink must exist or reconciliation will fail.
 	// +optional
 	DataSinkRef *DataSinkReference `json:"dataSinkRef,omitempty"`
 
+	// MeterName is the name of the OpenTelemetry meter, i.e. the instrumentation scope the metric is exported with,
+	// so downstream pipelines can route by scope. Defaults to "managed".
+	// +optional
+	// +kubebuilder:validation:MaxLength=255
+	// +kubebuilder:validation:Pattern=`^[a-zA-Z][a-zA-Z0-9_./-]*$`
+	MeterName string `json:"meterName,omitempty"`
+
+	// ScopeAttributes are exported as attributes of the instrumentation scope
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	ScopeAttributes []ScopeAttribute `json:"scopeAttributes,omitempty"`
+
+	// StaticDimensions are added to every data point of the metric, e.g. a tenant read from a ConfigMap of the namespace.
+	// They never override the dimensions of the metric itself.
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	StaticDimensions []StaticDimension `json:"staticDimensions,omitempty"`
+
 	// +optional
 	RemoteClusterAccessRef *RemoteClusterAccessRef `json:"remoteClusterAccessRef,omitempty"`
+
+	// CRDCategories defines the categories a CRD must have for its resources to be observed.
+	// All listed categories must be present. Defaults to the Crossplane managed resource categories.
+	// +optional
+	// +kubebuilder:default:={"crossplane","managed"}
+	CRDCategories []string `json:"crdCategories,omitempty"`
+
+	// ConditionTypes defines which status condition types are exported as dimensions
+	// if no custom dimensions are specified. Matching is case-insensitive.
+	// +optional
+	// +kubebuilder:default:={"Ready","Synced"}
+	ConditionTypes []string `json:"conditionTypes,omitempty"`
+
+	// ConditionFilter restricts the exported resources to those whose status conditions
+	// meet all requirements, e.g. only resources with Ready=False.
+	// +optional
+	ConditionFilter []ConditionRequirement `json:"conditionFilter,omitempty"`
+
+	// Age exports age statistics of the matched resources instead of a data point per resource,
+	// e.g. to alert on resources that have not become ready for a long time.
+	// +optional
+	Age *AgeAggregation `json:"age,omitempty"`
+
+	// Debug options of the metric
+	// +optional
+	Debug *DebugOptions `json:"debug,omitempty"`
+
+	// Thresholds send a notification when the latest value of the metric crosses them
+	// +optional
+	Thresholds *Thresholds `json:"thresholds,omitempty"`
+}
+
+// GetCRDCategories returns the CRD categories of managed resources, falling back to the Crossplane defaults
+func (s *ManagedMetricSpec) GetCRDCategories() []string {
+	if len(s.CRDCategories) == 0 {
+		return DefaultManagedCRDCategories
+	}
+	return s.CRDCategories
+}
+
+// GetConditionTypes returns the condition types exported as dimensions, falling back to the Crossplane defaults
+func (s *ManagedMetricSpec) GetConditionTypes() []string {
+	if len(s.ConditionTypes) == 0 {
+		return DefaultManagedConditionTypes
+	}
+	return s.ConditionTypes
 }
 
 // ManagedObservation represents the latest available observation of an object's state
@@ -87,6 +210,21 @@ type ManagedMetricStatus struct {
 
 	// Conditions represent the latest available observations of an object's state
 	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// NextRunTime is the time the managed metric is exported next
+	// +optional
+	NextRunTime *metav1.Time `json:"nextRunTime,omitempty"`
+
+	// ConsecutiveFailures is the number of collections that failed in a row since the last successful one
+	// +optional
+	ConsecutiveFailures int32 `json:"consecutiveFailures,omitempty"`
+
+	ExportStatus `json:",inline"`
+
+	// StaticDimensionsHash identifies the values of the static dimensions of the last export,
+	// the metric is exported again when a value read from a ConfigMap or Secret changes
+	// +optional
+	StaticDimensionsHash string `json:"staticDimensionsHash,omitempty"`
 }
 
 // GvkToString returns group, version and kind as a string
@@ -104,12 +242,29 @@ func (r *ManagedMetric) SetConditions(conditions ...metav1.Condition) {
 	}
 }
 
+// GetPriority returns the priority of the ManagedMetric, metrics without a priority have the normal priority
+func (r *ManagedMetric) GetPriority() Priority {
+	if r.Spec.Priority == "" {
+		return PriorityNormal
+	}
+	return r.Spec.Priority
+}
+
+// GetStaticDimensions returns the static dimensions of the ManagedMetric
+func (r *ManagedMetric) GetStaticDimensions() []StaticDimension {
+	return r.Spec.StaticDimensions
+}
+
 // ManagedMetric is the Schema for the managedmetrics API
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
+// +kubebuilder:resource:shortName=mmtr
 // +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.ready"
 // +kubebuilder:printcolumn:name="VALUE",type="string",JSONPath=".status.observation.resources"
+// +kubebuilder:printcolumn:name="INTERVAL",type="string",JSONPath=".spec.interval"
+// +kubebuilder:printcolumn:name="SINK",type="string",JSONPath=".status.dataSink"
 // +kubebuilder:printcolumn:name="OBSERVED",type="date",JSONPath=".status.observation.timestamp"
+// +kubebuilder:printcolumn:name="EXPORTED",type="date",JSONPath=".status.lastExportTime"
 type ManagedMetric struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
@@ -45,40 +45,452 @@ type DataSinkReference struct {
 	Name string `json:"name,omitempty"`
 }
 
+// MetricTarget defines the kind of object that should be instrumented and, optionally,
+// the namespaces it should be looked up in
+type MetricTarget struct {
+	GroupVersionKind `json:",inline"`
+
+	// Namespaces restricts the query to the listed namespaces.
+	// If neither Namespaces nor NamespaceSelector is set, resources are counted cluster-wide.
+	// +optional
+	Namespaces []string `json:"namespaces,omitempty"`
+
+	// NamespaceSelector restricts the query to namespaces whose labels match the selector.
+	// If both Namespaces and NamespaceSelector are set, the union of both is queried.
+	// +optional
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
+
+	// Name restricts the query to the object with the name, e.g. to export a field of a single object with valueFrom.
+	// Namespaced objects are looked up in the namespaces of the target.
+	// +optional
+	Name string `json:"name,omitempty"`
+}
+
+// IsNamespaceScoped returns true if the target is restricted to a subset of namespaces
+func (t *MetricTarget) IsNamespaceScoped() bool {
+	return len(t.Namespaces) > 0 || t.NamespaceSelector != nil
+}
+
+// CombinePrimaryTarget is the variable name under which the resource count of spec.target
+// is available in a Metric's combine expression
+const CombinePrimaryTarget = "target"
+
+// NamedTarget defines an additional query whose resource count can be referenced by name
+// in a Metric's combine expression
+type NamedTarget struct {
+	// Name is the variable name used to reference the resource count of this target in the combine expression
+	// +kubebuilder:validation:Pattern=`^[a-zA-Z_][a-zA-Z0-9_]*$`
+	Name string `json:"name"`
+	// Target defines the kind of object that should be counted
+	Target MetricTarget `json:"target"`
+	// Define labels of your object to adapt filters of the query
+	// +optional
+	LabelSelector string `json:"labelSelector,omitempty"`
+	// Define fields of your object to adapt filters of the query.
+	// Fields the API server doesn't support for the kind are filtered by the operator instead.
+	// +optional
+	FieldSelector string `json:"fieldSelector,omitempty"`
+}
+
+// Enrichment looks up a resource related to each matched resource and adds projections of it as dimensions
+type Enrichment struct {
+	// Target is the kind of the related resource
+	Target GroupVersionKind `json:"target"`
+	// NameFrom is the path of the field of the matched resource holding the name of the related resource,
+	// e.g. "metadata.namespace" for the Namespace or "spec.nodeName" for the Node of a Pod.
+	// Namespaced related resources are looked up in the namespace of the matched resource.
+	// +kubebuilder:validation:MinLength=1
+	NameFrom string `json:"nameFrom"`
+	// Projections are evaluated on the related resource and added to the dimensions of the matched resource.
+	// If the related resource does not exist, their defaults are used.
+	// +kubebuilder:validation:MinItems=1
+	Projections []Projection `json:"projections"`
+}
+
+// Modes define how observed values are exported
+const (
+	// MetricModeAbsolute exports the observed value as is
+	MetricModeAbsolute = "Absolute"
+	// MetricModeDelta exports the difference to the previous observation
+	MetricModeDelta = "Delta"
+	// MetricModeRate exports the rate of change per rate unit since the previous observation
+	MetricModeRate = "Rate"
+)
+
+// Rate units define the time unit the Rate mode is exported in
+const (
+	// RateUnitSecond exports the rate of change per second
+	RateUnitSecond = "Second"
+	// RateUnitMinute exports the rate of change per minute
+	RateUnitMinute = "Minute"
+	// RateUnitHour exports the rate of change per hour
+	RateUnitHour = "Hour"
+)
+
+// Export policies decide when observed values are exported
+const (
+	// ExportPolicyAlways exports the values of every observation
+	ExportPolicyAlways = "Always"
+	// ExportPolicyOnChange exports the values only if they differ from the last exported ones
+	ExportPolicyOnChange = "OnChange"
+	// ExportPolicyOnChangeWithHeartbeat exports the values if they changed or the heartbeat interval has passed since the last export
+	ExportPolicyOnChangeWithHeartbeat = "OnChangeWithHeartbeat"
+)
+
+// Encodings decide how the series of an observation are exported
+const (
+	// EncodingSeries exports a data point per dimension combination
+	EncodingSeries = "Series"
+	// EncodingJSON exports a single data point with the total value, the series are encoded as JSON in its series dimension
+	EncodingJSON = "JSON"
+)
+
+// SeriesDimension is the dimension holding the series of a metric exported with the JSON encoding
+const SeriesDimension = "series"
+
+// OmittedSeriesDimension is the dimension counting the series of a metric exported with the JSON encoding
+// that were left out of the series dimension because of its size limit
+const OmittedSeriesDimension = "omittedSeries"
+
+// Presets are the built-in metrics of common platform KPIs
+const (
+	// PresetResourceQuotaUsage exports the used share of each resource of the ResourceQuotas in percent
+	PresetResourceQuotaUsage = "ResourceQuotaUsage"
+	// PresetNodeCapacity exports the allocatable and the capacity resources of the Nodes by role
+	PresetNodeCapacity = "NodeCapacity"
+	// PresetPVCRequestedBytes exports the storage requested by the PersistentVolumeClaims
+	PresetPVCRequestedBytes = "PVCRequestedBytes"
+)
+
+// MaxLastExportSeries is the maximum number of series a metric with an OnChange export policy remembers of its last export.
+// Observations with more series fail, as the last export is kept in the status of the metric.
+const MaxLastExportSeries = 500
+
+// MetricLastExport holds the values of the last export that the OnChange export policies compare against
+type MetricLastExport struct {
+	// Timestamp of the last export
+	Timestamp metav1.Time `json:"timestamp,omitempty"`
+
+	// Values maps the dimensions of each exported data point to its value
+	// +optional
+	Values map[string]int64 `json:"values,omitempty"`
+}
+
+// Aggregations of the samples of a sampling window
+const (
+	// SamplingAggregationMin exports the smallest sampled value
+	SamplingAggregationMin = "min"
+	// SamplingAggregationMax exports the largest sampled value
+	SamplingAggregationMax = "max"
+	// SamplingAggregationAvg exports the average of the sampled values, rounded to the nearest integer
+	SamplingAggregationAvg = "avg"
+	// SamplingAggregationLast exports the latest sampled value
+	SamplingAggregationLast = "last"
+)
+
+// SamplingAggregationDimension is the dimension the aggregation of a sampled data point is exported with
+const SamplingAggregationDimension = "aggregation"
+
+// Sampling observes the metric more often than it is exported
+type Sampling struct {
+	// SampleInterval is the time between two samples, it must be shorter than the interval of the metric
+	// +kubebuilder:validation:Required
+	SampleInterval metav1.Duration `json:"sampleInterval"`
+
+	// Aggregations of the samples that are exported every interval. Defaults to all of min, max, avg and last.
+	// +optional
+	// +listType=set
+	// +kubebuilder:validation:items:Enum=min;max;avg;last
+	Aggregations []string `json:"aggregations,omitempty"`
+}
+
+// MaxSamplingWindowSeries is the maximum number of series a metric with sampling aggregates in its sampling window.
+// Observations that add more series fail, as the sampling window is kept in the status of the metric.
+const MaxSamplingWindowSeries = 500
+
+// MetricSamplingWindow holds the samples taken since the last export of a metric with sampling
+type MetricSamplingWindow struct {
+	// Start of the window, the samples are exported once the interval has passed since then
+	Start metav1.Time `json:"start,omitempty"`
+
+	// Samples is the number of samples taken in the window
+	// +optional
+	Samples int32 `json:"samples,omitempty"`
+
+	// Series aggregates the samples of each dimension combination
+	// +optional
+	Series []SampledSeries `json:"series,omitempty"`
+}
+
+// SampledSeries aggregates the sampled values of a dimension combination,
+// samples without the dimension combination count as 0
+type SampledSeries struct {
+	// Dimensions of the data points
+	// +optional
+	Dimensions map[string]string `json:"dimensions,omitempty"`
+	// Min is the smallest sampled value
+	Min int64 `json:"min"`
+	// Max is the largest sampled value
+	Max int64 `json:"max"`
+	// Sum of the sampled values
+	Sum int64 `json:"sum"`
+	// Last is the latest sampled value
+	Last int64 `json:"last"`
+}
+
+// MaxRecordedSeries is the maximum number of series listed in the status of a metric with spec.debug.recordDimensions
+const MaxRecordedSeries = 100
+
+// RecordedSeries lists the series recorded by the last collection of a metric
+type RecordedSeries struct {
+	// Timestamp of the collection
+	Timestamp metav1.Time `json:"timestamp,omitempty"`
+	// Series are the recorded series sorted by their dimensions, at most MaxRecordedSeries.
+	// Their values are taken before the mode, sampling and export policy are applied.
+	// +optional
+	Series []RecordedSeriesValue `json:"series,omitempty"`
+	// Omitted is the number of recorded series beyond MaxRecordedSeries that are not listed
+	// +optional
+	Omitted int32 `json:"omitted,omitempty"`
+}
+
+// RecordedSeriesValue is the value of a dimension combination
+type RecordedSeriesValue struct {
+	// Dimensions of the data point, without the static dimensions
+	// +optional
+	Dimensions map[string]string `json:"dimensions,omitempty"`
+	// Value of the data point
+	Value int64 `json:"value"`
+}
+
+// MaxBaselineSeries is the maximum number of series a metric exported in Delta or Rate mode remembers in its baseline.
+// Observations with more series fail, as the baseline is kept in the status of the metric.
+const MaxBaselineSeries = 500
+
+// MetricBaseline holds the values of the previous observation that Delta and Rate modes are computed against
+type MetricBaseline struct {
+	// Timestamp of the previous observation
+	Timestamp metav1.Time `json:"timestamp,omitempty"`
+
+	// Values maps the dimensions of each exported data point to its absolute value
+	// +optional
+	Values map[string]int64 `json:"values,omitempty"`
+
+	// Dimensions maps the keys of Values to the dimensions of the data point,
+	// so that a data point missing from the next observation can still be exported
+	// +optional
+	Dimensions map[string]DataPointDimensions `json:"dimensions,omitempty"`
+}
+
+// DataPointDimensions are the dimensions of a data point
+type DataPointDimensions map[string]string
+
 // MetricSpec defines the desired state of Metric
+// +kubebuilder:validation:XValidation:rule="!has(self.combine) || (!has(self.projections) && !has(self.valueFrom))",message="combine cannot be used together with projections or valueFrom"
+// +kubebuilder:validation:XValidation:rule="!has(self.targets) || has(self.combine)",message="targets require a combine expression"
+// +kubebuilder:validation:XValidation:rule="!has(self.combine) || !has(self.groupByNamespace) || !self.groupByNamespace",message="combine cannot be used together with groupByNamespace"
+// +kubebuilder:validation:XValidation:rule="!has(self.combine) || !has(self.enrichments)",message="combine cannot be used together with enrichments"
+// +kubebuilder:validation:XValidation:rule="!has(self.preset) || (!has(self.projections) && !has(self.valueFrom) && !has(self.combine) && !has(self.enrichments) && !(has(self.groupByNamespace) && self.groupByNamespace))",message="preset cannot be used together with projections, valueFrom, combine, enrichments or groupByNamespace"
+// +kubebuilder:validation:XValidation:rule="!has(self.preset) || (has(self.target.kind) && !has(self.target.group) && self.target.kind == {'ResourceQuotaUsage': 'ResourceQuota', 'NodeCapacity': 'Node', 'PVCRequestedBytes': 'PersistentVolumeClaim'}[self.preset])",message="the target must be of the kind of the preset"
+// +kubebuilder:validation:XValidation:rule="!has(self.sampling) || !has(self.schedule)",message="sampling cannot be used together with schedule"
+// +kubebuilder:validation:XValidation:rule="!has(self.sampling) || !has(self.mode) || self.mode == 'Absolute'",message="sampling requires the Absolute mode"
+// +kubebuilder:validation:XValidation:rule="!has(self.sampling) || !has(self.interval) || duration(self.sampling.sampleInterval) < duration(self.interval)",message="sampling.sampleInterval must be shorter than interval"
+// +kubebuilder:validation:XValidation:rule="!has(self.heartbeatInterval) || (has(self.exportPolicy) && self.exportPolicy == 'OnChangeWithHeartbeat')",message="heartbeatInterval requires the OnChangeWithHeartbeat export policy"
+// +kubebuilder:validation:XValidation:rule="!has(self.exportPolicy) || self.exportPolicy != 'OnChangeWithHeartbeat' || has(self.heartbeatInterval)",message="the OnChangeWithHeartbeat export policy requires heartbeatInterval"
 type MetricSpec struct {
 	// Sets the name that will be used to identify the metric in Dynatrace(or other providers)
 	Name string `json:"name,omitempty"`
 	// Sets the description that will be used to identify the metric in Dynatrace(or other providers)
 	// +optional
 	Description string `json:"description,omitempty"`
+	// Sets the unit of the metric in UCUM notation, e.g. "1", "s" or "By", that will be shown in Dynatrace(or other providers)
+	// +kubebuilder:validation:MaxLength=63
+	// +optional
+	Unit string `json:"unit,omitempty"`
 	// +kubebuilder:validation:Required
-	Target GroupVersionKind `json:"target,omitempty"`
+	Target MetricTarget `json:"target,omitempty"`
 	// Define labels of your object to adapt filters of the query
 	// +optional
 	LabelSelector string `json:"labelSelector,omitempty"`
-	// Define fields of your object to adapt filters of the query
+	// Define fields of your object to adapt filters of the query.
+	// Fields the API server doesn't support for the kind are filtered by the operator instead.
 	// +optional
 	FieldSelector string `json:"fieldSelector,omitempty"`
+	// ExcludeLabelSelector excludes the resources whose labels match it from the query, e.g. "app=debug"
+	// +optional
+	ExcludeLabelSelector string `json:"excludeLabelSelector,omitempty"`
+	// ExcludeFieldSelector excludes the resources whose fields match it from the query, e.g. "metadata.namespace=kube-system".
+	// It is always evaluated by the operator, so it supports any field path of the resource.
+	// +optional
+	ExcludeFieldSelector string `json:"excludeFieldSelector,omitempty"`
 	// Define in what interval the query should be recorded
 	// +kubebuilder:default:="10m"
 	Interval metav1.Duration `json:"interval,omitempty"`
 
+	// Schedule is a cron expression like "0 * * * *" or "@daily", evaluated in UTC, that defines when the metric is exported.
+	// If set, it is used instead of the interval and the first export happens at the first scheduled time.
+	// +kubebuilder:validation:MaxLength=100
+	// +optional
+	Schedule string `json:"schedule,omitempty"`
+
+	// Priority decides which metrics are reconciled first when many metrics are due at once,
+	// so important metrics are not delayed behind a large number of less important ones.
+	// +kubebuilder:validation:Enum=high;normal;low
+	// +kubebuilder:default:="normal"
+	// +optional
+	Priority Priority `json:"priority,omitempty"`
+
+	// RetryPolicy defines the delay between the retries of failed collections and after how many failures they stop
+	// +optional
+	RetryPolicy *RetryPolicy `json:"retryPolicy,omitempty"`
+
 	// DataSinkRef specifies the DataSink to be used for this metric.
 	// If omitted, no OTLP export is performed; metrics are only exposed via /metrics.
 	// If provided, the referenced DataSink must exist or reconciliation will fail.
 	// +optional
 	DataSinkRef *DataSinkReference `json:"dataSinkRef,omitempty"`
 
+	// MeterName is the name of the OpenTelemetry meter, i.e. the instrumentation scope the metric is exported with,
+	// so downstream pipelines can route by scope. Defaults to "metric".
+	// +optional
+	// +kubebuilder:validation:MaxLength=255
+	// +kubebuilder:validation:Pattern=`^[a-zA-Z][a-zA-Z0-9_./-]*$`
+	MeterName string `json:"meterName,omitempty"`
+
+	// ScopeAttributes are exported as attributes of the instrumentation scope
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	ScopeAttributes []ScopeAttribute `json:"scopeAttributes,omitempty"`
+
+	// StaticDimensions are added to every data point of the metric, e.g. a tenant read from a ConfigMap of the namespace.
+	// They never override the dimensions of the metric itself.
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	StaticDimensions []StaticDimension `json:"staticDimensions,omitempty"`
+
 	// +optional
 	RemoteClusterAccessRef *RemoteClusterAccessRef `json:"remoteClusterAccessRef,omitempty"`
 
 	Projections []Projection `json:"projections,omitempty"`
 
+	// GroupByNamespace exports one data point per namespace of the matched resources with a "namespace" dimension,
+	// in addition to the grouping by the projections. Cluster-scoped resources are counted without the dimension.
+	// +optional
+	GroupByNamespace bool `json:"groupByNamespace,omitempty"`
+
+	// Enrichments add dimensions projected from a resource related to each matched resource,
+	// e.g. a label of its Namespace
+	// +optional
+	Enrichments []Enrichment `json:"enrichments,omitempty"`
+
 	// ValueFrom specifies a field whose value is used as the gauge metric value
 	// instead of the default resource count.
 	// +optional
 	ValueFrom *ValueFromProjection `json:"valueFrom,omitempty"`
+
+	// Preset exports a built-in metric computed from the fields of the target resources instead of their count:
+	// ResourceQuotaUsage the used share of each resource of the ResourceQuotas in percent,
+	// NodeCapacity the allocatable and the capacity resources of the Nodes by role,
+	// and PVCRequestedBytes the storage requested by the PersistentVolumeClaims by namespace and storage class.
+	// The target must be of the kind of the preset, its namespaces, name and selectors still restrict the resources.
+	// +kubebuilder:validation:Enum=ResourceQuotaUsage;NodeCapacity;PVCRequestedBytes
+	// +optional
+	Preset string `json:"preset,omitempty"`
+
+	// Targets declares additional named queries whose resource counts can be used in Combine.
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	Targets []NamedTarget `json:"targets,omitempty"`
+
+	// Combine is an arithmetic expression over resource counts that is exported instead of the plain count.
+	// It supports +, -, *, / and parentheses. The count of spec.target is available as "target",
+	// the counts of spec.targets under their names, e.g. "100 * ready / target".
+	// The result is rounded to the nearest integer.
+	// +optional
+	Combine string `json:"combine,omitempty"`
+
+	// Mode defines how observed values are exported. Absolute exports the observed value,
+	// Delta the difference to the previous observation and Rate the rate of change per rateUnit
+	// since the previous observation, rounded to the nearest integer.
+	// Delta and Rate export nothing for the first observation. Data points that disappeared
+	// since the previous observation are exported once with the negated previous value.
+	// Delta and Rate support at most 500 series, observations with more series fail.
+	// +optional
+	// +kubebuilder:validation:Enum=Absolute;Delta;Rate
+	// +kubebuilder:default:=Absolute
+	Mode string `json:"mode,omitempty"`
+
+	// RateUnit is the time unit the Rate mode exports the rate of change in.
+	// Slowly changing values should use Minute or Hour, as rates below 0.5 per unit are exported as 0.
+	// Ignored unless mode is Rate.
+	// +optional
+	// +kubebuilder:validation:Enum=Second;Minute;Hour
+	// +kubebuilder:default:=Second
+	RateUnit string `json:"rateUnit,omitempty"`
+
+	// ExportPolicy decides when the values of an observation are exported. Always exports every observation,
+	// OnChange only the observations whose values differ from the last exported ones,
+	// and OnChangeWithHeartbeat additionally exports unchanged values once heartbeatInterval has passed since the last export.
+	// The values are compared after they are converted according to the mode.
+	// OnChange and OnChangeWithHeartbeat support at most 500 series, observations with more series fail.
+	// +optional
+	// +kubebuilder:validation:Enum=Always;OnChange;OnChangeWithHeartbeat
+	// +kubebuilder:default:=Always
+	ExportPolicy string `json:"exportPolicy,omitempty"`
+
+	// Encoding decides how the series of an observation are exported. Series exports a data point per dimension combination,
+	// JSON a single data point with the sum of their values, for consumers that want the breakdown as one record.
+	// Its dimensions are those all series share, the series are encoded as a JSON array of
+	// {"dimensions": {...}, "value": n} in its "series" dimension.
+	// The series are encoded after the mode, the export policy and the dimension policy of the DataSink are applied.
+	// Series that exceed 16 KiB are left out, starting with the lowest values, and counted in the "omittedSeries" dimension.
+	// +optional
+	// +kubebuilder:validation:Enum=Series;JSON
+	// +kubebuilder:default:=Series
+	Encoding string `json:"encoding,omitempty"`
+
+	// HeartbeatInterval is the longest time unchanged values are not exported with the OnChangeWithHeartbeat export policy
+	// +optional
+	HeartbeatInterval *metav1.Duration `json:"heartbeatInterval,omitempty"`
+
+	// Sampling observes the metric every sampleInterval and exports the aggregated samples every interval,
+	// so short spikes between two exports are not missed. Each aggregation is exported as a data point
+	// with an "aggregation" dimension. Sampling cannot be combined with a schedule or the Delta and Rate modes.
+	// Sampling supports at most 500 series per window, observations with more series fail.
+	// +optional
+	Sampling *Sampling `json:"sampling,omitempty"`
+
+	// Timeout bounds each phase of a collection: listing the target resources, evaluating the projections
+	// and exporting the data points. If listing or evaluating times out, the data collected until then is exported.
+	// Defaults to the operator's --collection-timeout.
+	// +optional
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+
+	// Snapshot writes the series of every collection into a MetricSnapshot in the namespace of the metric,
+	// a history of the metric within the cluster that needs no data sink.
+	// The values are as observed, before the mode, sampling and export policy are applied.
+	// +optional
+	Snapshot bool `json:"snapshot,omitempty"`
+
+	// SnapshotRetention is how long the MetricSnapshots of the metric are kept, 7 days by default
+	// +optional
+	SnapshotRetention *metav1.Duration `json:"snapshotRetention,omitempty"`
+
+	// MaxSnapshots is how many MetricSnapshots of the metric are kept at most, the oldest are deleted first. 100 by default.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	MaxSnapshots *int32 `json:"maxSnapshots,omitempty"`
+
+	// Debug options of the metric
+	// +optional
+	Debug *DebugOptions `json:"debug,omitempty"`
+
+	// Thresholds send a notification when the latest value of the metric crosses them
+	// +optional
+	Thresholds *Thresholds `json:"thresholds,omitempty"`
 }
 
 // MetricStatus defines the observed state of ManagedMetric
@@ -92,14 +504,54 @@ type MetricStatus struct {
 
 	// Conditions represent the latest available observations of an object's state
 	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// Baseline remembers the previous observation for the Delta and Rate modes
+	// +optional
+	Baseline *MetricBaseline `json:"baseline,omitempty"`
+
+	// LastExport remembers the last exported values for the OnChange export policies
+	// +optional
+	LastExport *MetricLastExport `json:"lastExport,omitempty"`
+
+	// SamplingWindow holds the samples taken since the last export of a metric with sampling
+	// +optional
+	SamplingWindow *MetricSamplingWindow `json:"samplingWindow,omitempty"`
+
+	// NextRunTime is the time the metric is exported next
+	// +optional
+	NextRunTime *metav1.Time `json:"nextRunTime,omitempty"`
+
+	// ConsecutiveFailures is the number of collections that failed in a row since the last successful one
+	// +optional
+	ConsecutiveFailures int32 `json:"consecutiveFailures,omitempty"`
+
+	ExportStatus `json:",inline"`
+
+	// StaticDimensionsHash identifies the values of the static dimensions of the last export,
+	// the metric is exported again when a value read from a ConfigMap or Secret changes
+	// +optional
+	StaticDimensionsHash string `json:"staticDimensionsHash,omitempty"`
+
+	// RecordedSeries lists the series recorded by the last collection of a metric with spec.debug.recordDimensions
+	// +optional
+	RecordedSeries *RecordedSeries `json:"recordedSeries,omitempty"`
+
+	// TargetNotFoundCount counts the consecutive collections that failed because the kind of a target
+	// is not served by the cluster. Once it reaches the cap, the metric is retried at its interval instead of quickly.
+	// +optional
+	TargetNotFoundCount int32 `json:"targetNotFoundCount,omitempty"`
 }
 
 // Metric is the Schema for the metrics API
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
+// +kubebuilder:resource:shortName=mtr
 // +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.ready"
 // +kubebuilder:printcolumn:name="VALUE",type="string",JSONPath=".status.observation.latestValue"
+// +kubebuilder:printcolumn:name="INTERVAL",type="string",JSONPath=".spec.interval"
+// +kubebuilder:printcolumn:name="SINK",type="string",JSONPath=".status.dataSink"
 // +kubebuilder:printcolumn:name="OBSERVED",type="date",JSONPath=".status.observation.timestamp"
+// +kubebuilder:printcolumn:name="EXPORTED",type="date",JSONPath=".status.lastExportTime"
 type Metric struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
@@ -115,6 +567,19 @@ func (r *Metric) SetConditions(conditions ...metav1.Condition) {
 	}
 }
 
+// GetPriority returns the priority of the Metric, metrics without a priority have the normal priority
+func (r *Metric) GetPriority() Priority {
+	if r.Spec.Priority == "" {
+		return PriorityNormal
+	}
+	return r.Spec.Priority
+}
+
+// GetStaticDimensions returns the static dimensions of the Metric
+func (r *Metric) GetStaticDimensions() []StaticDimension {
+	return r.Spec.StaticDimensions
+}
+
 // GvkToString returns the string representation of the metric targe GVK
 func (r *Metric) GvkToString() string {
 	if r.Spec.Target.Group == "" {
@@ -0,0 +1,119 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// MetricSetLabel is set on the Metrics generated by a MetricSet to the name of the MetricSet
+const MetricSetLabel = "metrics.openmcp.cloud/metricset"
+
+// MetricTemplate is the template of the Metrics generated by a MetricSet
+type MetricTemplate struct {
+	// Labels are added to the generated Metrics
+	// +optional
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// Spec is the spec of the generated Metrics, the target is overridden by each entry of the targets
+	Spec MetricSpec `json:"spec"`
+}
+
+// MetricSetTarget is an entry of the list a MetricSet expands its template over
+// +kubebuilder:validation:XValidation:rule="has(self.kind) || has(self.namespaces)",message="a target overrides the kind or the namespaces of the template"
+type MetricSetTarget struct {
+	// Name identifies the target. The generated Metric is named <MetricSet name>-<name>
+	// and exports the metric <template spec.name>-<name>.
+	// +kubebuilder:validation:MaxLength=63
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	Name string `json:"name"`
+
+	// Kind, Group and Version override the kind of object the template counts if the kind is set
+	GroupVersionKind `json:",inline"`
+
+	// Namespaces override the namespaces of the template target, the namespace selector of the template is dropped
+	// +optional
+	Namespaces []string `json:"namespaces,omitempty"`
+}
+
+// MetricSetSpec defines the desired state of MetricSet
+type MetricSetSpec struct {
+	// Template is expanded over the targets, generating one Metric per target
+	Template MetricTemplate `json:"template"`
+
+	// Targets lists the kinds or namespaces a Metric is generated for
+	// +kubebuilder:validation:MinItems=1
+	// +listType=map
+	// +listMapKey=name
+	Targets []MetricSetTarget `json:"targets"`
+}
+
+// MetricSetStatus defines the observed state of MetricSet
+type MetricSetStatus struct {
+	// Metrics is the number of generated Metrics
+	Metrics int `json:"metrics,omitempty"`
+
+	// ReadyMetrics is the number of generated Metrics that are ready
+	ReadyMetrics int `json:"readyMetrics,omitempty"`
+
+	// Ready is True if all generated Metrics are ready
+	Ready string `json:"ready,omitempty"`
+
+	// Conditions represent the latest available observations of an object's state
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// MetricSet is the Schema for the metricsets API
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:shortName=mset
+// +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.ready"
+// +kubebuilder:printcolumn:name="METRICS",type="integer",JSONPath=".status.metrics"
+// +kubebuilder:printcolumn:name="READY METRICS",type="integer",JSONPath=".status.readyMetrics"
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+type MetricSet struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   MetricSetSpec   `json:"spec,omitempty"`
+	Status MetricSetStatus `json:"status,omitempty"`
+}
+
+// SetConditions sets the conditions of the metric set
+func (r *MetricSet) SetConditions(conditions ...metav1.Condition) {
+	for _, c := range conditions {
+		meta.SetStatusCondition(&r.Status.Conditions, c)
+	}
+}
+
+// +kubebuilder:object:root=true
+
+// MetricSetList contains a list of MetricSet
+type MetricSetList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []MetricSet `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(func(s *runtime.Scheme) error {
+		s.AddKnownTypes(GroupVersion, &MetricSet{}, &MetricSetList{})
+		return nil
+	})
+}
@@ -0,0 +1,89 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// MetricSnapshotLabel is set on the MetricSnapshots of a Metric to the name of the Metric,
+// names longer than a label value are shortened and suffixed with their hash
+const MetricSnapshotLabel = "metrics.openmcp.cloud/metric"
+
+// DefaultSnapshotRetention is how long the MetricSnapshots of a Metric are kept unless it sets spec.snapshotRetention
+const DefaultSnapshotRetention = 7 * 24 * time.Hour
+
+// DefaultMaxSnapshots is how many MetricSnapshots of a Metric are kept at most unless it sets spec.maxSnapshots
+const DefaultMaxSnapshots = 100
+
+// MaxSnapshotSeries is the maximum number of series listed in a MetricSnapshot
+const MaxSnapshotSeries = 1000
+
+// MetricSnapshotSpec holds the series of a collection of a Metric
+type MetricSnapshotSpec struct {
+	// Metric is the name of the Metric in the namespace of the snapshot
+	Metric string `json:"metric"`
+
+	// Timestamp of the collection
+	Timestamp metav1.Time `json:"timestamp"`
+
+	// Total is the sum of the values of all series, including the omitted ones
+	Total int64 `json:"total"`
+
+	// Series are the series of the collection sorted by their dimensions, at most MaxSnapshotSeries
+	// +optional
+	Series []RecordedSeriesValue `json:"series,omitempty"`
+
+	// Omitted is the number of series beyond MaxSnapshotSeries that are not listed
+	// +optional
+	Omitted int32 `json:"omitted,omitempty"`
+}
+
+// MetricSnapshot is written by a Metric with spec.snapshot for each of its collections,
+// a history of the metric within the cluster that needs no data sink.
+// Snapshots are deleted with their Metric or once they are older than its spec.snapshotRetention.
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:shortName=msnap
+// +kubebuilder:printcolumn:name="METRIC",type="string",JSONPath=".spec.metric"
+// +kubebuilder:printcolumn:name="TIMESTAMP",type="date",JSONPath=".spec.timestamp"
+// +kubebuilder:printcolumn:name="TOTAL",type="integer",JSONPath=".spec.total"
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+type MetricSnapshot struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec MetricSnapshotSpec `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// MetricSnapshotList contains a list of MetricSnapshot
+type MetricSnapshotList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []MetricSnapshot `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(func(s *runtime.Scheme) error {
+		s.AddKnownTypes(GroupVersion, &MetricSnapshot{}, &MetricSnapshotList{})
+		return nil
+	})
+}
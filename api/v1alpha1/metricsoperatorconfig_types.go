@@ -0,0 +1,132 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// MetricsOperatorConfigName is the name of the MetricsOperatorConfig read by the operator
+const MetricsOperatorConfigName = "default"
+
+// MetricsOperatorConfigSpec overrides the global settings the operator was started with.
+// Settings that are not set keep the value of the corresponding flag of the operator.
+type MetricsOperatorConfigSpec struct {
+	// JitterPercent delays the first export of a metric with an interval by up to this percentage of the interval,
+	// overrides --jitter-percent
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	JitterPercent *int32 `json:"jitterPercent,omitempty"`
+
+	// ErrorRequeueBaseDelay is the delay of the first retry of a failed collection of metrics without a retry policy,
+	// overrides --error-requeue-base-delay
+	// +optional
+	ErrorRequeueBaseDelay *metav1.Duration `json:"errorRequeueBaseDelay,omitempty"`
+
+	// ErrorRequeueMaxDelay is the upper bound of the delay between retries of metrics without a retry policy,
+	// overrides --error-requeue-max-delay
+	// +optional
+	ErrorRequeueMaxDelay *metav1.Duration `json:"errorRequeueMaxDelay,omitempty"`
+
+	// CollectionTimeout is the timeout of each collection phase of metrics that do not set spec.timeout,
+	// overrides --collection-timeout
+	// +optional
+	CollectionTimeout *metav1.Duration `json:"collectionTimeout,omitempty"`
+
+	// EventInterval is the interval in which an unchanged event of an object is emitted again, overrides --event-interval
+	// +optional
+	EventInterval *metav1.Duration `json:"eventInterval,omitempty"`
+
+	// ManagedCacheTTL is how long listed managed resources are shared between ManagedMetrics, overrides --managed-cache-ttl
+	// +optional
+	ManagedCacheTTL *metav1.Duration `json:"managedCacheTTL,omitempty"`
+
+	// DataSinkFailureThreshold is the number of consecutive failed exports after which exports to a DataSink are skipped,
+	// overrides --datasink-failure-threshold
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	DataSinkFailureThreshold *int32 `json:"dataSinkFailureThreshold,omitempty"`
+
+	// DataSinkOpenDuration is how long exports to a failing DataSink are skipped, overrides --datasink-open-duration
+	// +optional
+	DataSinkOpenDuration *metav1.Duration `json:"dataSinkOpenDuration,omitempty"`
+
+	// DefaultDataSink is the name of the DataSink of metrics whose dataSinkRef does not name one, "default" if not set
+	// +optional
+	DefaultDataSink string `json:"defaultDataSink,omitempty"`
+
+	// LogLevel is the level of the logs of the operator, debug, info, error or a verbosity greater than 0,
+	// overrides --zap-log-level
+	// +optional
+	// +kubebuilder:validation:Pattern=`^(debug|info|error|[1-9][0-9]?)$`
+	LogLevel string `json:"logLevel,omitempty"`
+
+	// Variables are added to the variables given by --variables, replacing those with the same name
+	// +optional
+	Variables map[string]string `json:"variables,omitempty"`
+}
+
+// MetricsOperatorConfigStatus reports whether the settings were applied
+type MetricsOperatorConfigStatus struct {
+	// ObservedGeneration is the generation of the spec that was last applied
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Conditions represent the latest available observations of an object's state
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// MetricsOperatorConfig holds the global settings of the operator, which are applied without restarting it.
+// The operator reads the object named "default", deleting it restores the settings of the flags.
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster,shortName=moc
+// +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status"
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+type MetricsOperatorConfig struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   MetricsOperatorConfigSpec   `json:"spec,omitempty"`
+	Status MetricsOperatorConfigStatus `json:"status,omitempty"`
+}
+
+// SetConditions sets the conditions of the MetricsOperatorConfig
+func (r *MetricsOperatorConfig) SetConditions(conditions ...metav1.Condition) {
+	for _, c := range conditions {
+		meta.SetStatusCondition(&r.Status.Conditions, c)
+	}
+}
+
+// +kubebuilder:object:root=true
+
+// MetricsOperatorConfigList contains a list of MetricsOperatorConfig
+type MetricsOperatorConfigList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []MetricsOperatorConfig `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(func(s *runtime.Scheme) error {
+		s.AddKnownTypes(GroupVersion, &MetricsOperatorConfig{}, &MetricsOperatorConfigList{})
+		return nil
+	})
+}
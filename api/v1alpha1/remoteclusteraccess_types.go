@@ -17,6 +17,7 @@ limitations under the License.
 package v1alpha1
 
 import (
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 )
@@ -46,6 +47,32 @@ type RemoteClusterAccessSpec struct {
 
 	// +optional
 	ClusterAccessConfig *ClusterAccessConfig `json:"remoteClusterConfig,omitempty"`
+
+	// ClusterName is exported as cluster dimension of the data points.
+	// Defaults to the host name of the API server of the remote cluster.
+	// +optional
+	ClusterName string `json:"clusterName,omitempty"`
+
+	// ClusterLabels are exported as additional dimensions of every data point of the remote cluster.
+	// They do not override dimensions of the metric.
+	// +optional
+	ClusterLabels map[string]string `json:"clusterLabels,omitempty"`
+
+	// RateLimit limits the requests of the operator to the remote cluster.
+	// Defaults to the client rate limit of the operator.
+	// +optional
+	RateLimit *ClientRateLimit `json:"rateLimit,omitempty"`
+}
+
+// ClientRateLimit limits the requests to the API server of a cluster with a token bucket
+type ClientRateLimit struct {
+	// QPS is the number of requests per second
+	// +kubebuilder:validation:Minimum=1
+	QPS int32 `json:"qps"`
+
+	// Burst is the number of requests that may be sent at once before QPS applies
+	// +kubebuilder:validation:Minimum=1
+	Burst int32 `json:"burst"`
 }
 
 // ClusterAccessConfig defines the configuration to access a remote cluster
@@ -54,6 +81,36 @@ type ClusterAccessConfig struct {
 	ServiceAccountNamespace string `json:"serviceAccountNamespace,omitempty"`
 
 	ClusterSecretRef RemoteClusterSecretRef `json:"clusterSecretRef,omitempty"`
+
+	// OIDC obtains the token to access the remote cluster from an OIDC issuer instead of using the service account token directly.
+	// The issuer URL and the client credentials are read from the cluster secret (keys issuerURL, clientID and clientSecret).
+	// +optional
+	OIDC *OIDCTokenExchange `json:"oidc,omitempty"`
+}
+
+const (
+	// OIDCGrantClientCredentials requests a token with the client credentials from the cluster secret
+	OIDCGrantClientCredentials = "ClientCredentials"
+	// OIDCGrantTokenExchange exchanges a token of the service account for a token of the issuer (RFC 8693)
+	OIDCGrantTokenExchange = "TokenExchange"
+)
+
+// OIDCTokenExchange defines how the token for a remote cluster is requested from an OIDC issuer.
+// Tokens are cached and refreshed before they expire.
+type OIDCTokenExchange struct {
+	// GrantType is the OAuth 2.0 grant used to request the token
+	// +kubebuilder:validation:Enum=ClientCredentials;TokenExchange
+	// +kubebuilder:default:=ClientCredentials
+	GrantType string `json:"grantType,omitempty"`
+
+	// Scopes requested from the issuer
+	// +optional
+	Scopes []string `json:"scopes,omitempty"`
+
+	// SubjectTokenAudience is the audience of the service account token exchanged with the TokenExchange grant.
+	// Defaults to the issuer URL.
+	// +optional
+	SubjectTokenAudience string `json:"subjectTokenAudience,omitempty"`
 }
 
 // RemoteClusterSecretRef is a reference to a secret that contains host, audience, and caData to a remote cluster
@@ -64,12 +121,25 @@ type RemoteClusterSecretRef struct {
 
 // RemoteClusterAccessStatus defines the observed state of RemoteClusterAccess
 type RemoteClusterAccessStatus struct {
-	// INSERT ADDITIONAL STATUS FIELD - define observed state of cluster
-	// Important: Run "make" to regenerate code after modifying this file
+	// CredentialsExpiry is the time the client certificate or token of the kubeconfig expires,
+	// not set if the credentials do not expire or are requested by the operator
+	// +optional
+	CredentialsExpiry *metav1.Time `json:"credentialsExpiry,omitempty"`
+
+	// LastCheckTime is the time the credentials were last checked
+	// +optional
+	LastCheckTime *metav1.Time `json:"lastCheckTime,omitempty"`
+
+	// Conditions represent the latest available observations of an object's state
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
 }
 
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
+// +kubebuilder:resource:shortName=rca
+// +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status"
+// +kubebuilder:printcolumn:name="EXPIRES",type="date",JSONPath=".status.credentialsExpiry"
 
 // RemoteClusterAccess is the Schema for the remoteclusteraccesses API
 type RemoteClusterAccess struct {
@@ -80,6 +150,13 @@ type RemoteClusterAccess struct {
 	Status RemoteClusterAccessStatus `json:"status,omitempty"`
 }
 
+// SetConditions sets the conditions of the remote cluster access
+func (r *RemoteClusterAccess) SetConditions(conditions ...metav1.Condition) {
+	for _, c := range conditions {
+		meta.SetStatusCondition(&r.Status.Conditions, c)
+	}
+}
+
 // +kubebuilder:object:root=true
 
 // RemoteClusterAccessList contains a list of RemoteClusterAccess
@@ -28,6 +28,21 @@ func (in *APIKeyAuthentication) DeepCopy() *APIKeyAuthentication {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AgeAggregation) DeepCopyInto(out *AgeAggregation) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AgeAggregation.
+func (in *AgeAggregation) DeepCopy() *AgeAggregation {
+	if in == nil {
+		return nil
+	}
+	out := new(AgeAggregation)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Authentication) DeepCopyInto(out *Authentication) {
 	*out = *in
@@ -76,57 +91,101 @@ func (in *CertificateAuthentication) DeepCopy() *CertificateAuthentication {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ClusterAccessConfig) DeepCopyInto(out *ClusterAccessConfig) {
+func (in *CircuitBreakerStatus) DeepCopyInto(out *CircuitBreakerStatus) {
 	*out = *in
-	out.ClusterSecretRef = in.ClusterSecretRef
+	if in.OpenedAt != nil {
+		in, out := &in.OpenedAt, &out.OpenedAt
+		*out = (*in).DeepCopy()
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterAccessConfig.
-func (in *ClusterAccessConfig) DeepCopy() *ClusterAccessConfig {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CircuitBreakerStatus.
+func (in *CircuitBreakerStatus) DeepCopy() *CircuitBreakerStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(ClusterAccessConfig)
+	out := new(CircuitBreakerStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *Connection) DeepCopyInto(out *Connection) {
+func (in *ClientRateLimit) DeepCopyInto(out *ClientRateLimit) {
 	*out = *in
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Connection.
-func (in *Connection) DeepCopy() *Connection {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClientRateLimit.
+func (in *ClientRateLimit) DeepCopy() *ClientRateLimit {
 	if in == nil {
 		return nil
 	}
-	out := new(Connection)
+	out := new(ClientRateLimit)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *DataSink) DeepCopyInto(out *DataSink) {
+func (in *ClusterAPIClusters) DeepCopyInto(out *ClusterAPIClusters) {
+	*out = *in
+	if in.Selector != nil {
+		in, out := &in.Selector, &out.Selector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterAPIClusters.
+func (in *ClusterAPIClusters) DeepCopy() *ClusterAPIClusters {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterAPIClusters)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterAccessConfig) DeepCopyInto(out *ClusterAccessConfig) {
+	*out = *in
+	out.ClusterSecretRef = in.ClusterSecretRef
+	if in.OIDC != nil {
+		in, out := &in.OIDC, &out.OIDC
+		*out = new(OIDCTokenExchange)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterAccessConfig.
+func (in *ClusterAccessConfig) DeepCopy() *ClusterAccessConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterAccessConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterAgent) DeepCopyInto(out *ClusterAgent) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	in.Spec.DeepCopyInto(&out.Spec)
+	out.Spec = in.Spec
 	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataSink.
-func (in *DataSink) DeepCopy() *DataSink {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterAgent.
+func (in *ClusterAgent) DeepCopy() *ClusterAgent {
 	if in == nil {
 		return nil
 	}
-	out := new(DataSink)
+	out := new(ClusterAgent)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *DataSink) DeepCopyObject() runtime.Object {
+func (in *ClusterAgent) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -134,31 +193,31 @@ func (in *DataSink) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *DataSinkList) DeepCopyInto(out *DataSinkList) {
+func (in *ClusterAgentList) DeepCopyInto(out *ClusterAgentList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]DataSink, len(*in))
+		*out = make([]ClusterAgent, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataSinkList.
-func (in *DataSinkList) DeepCopy() *DataSinkList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterAgentList.
+func (in *ClusterAgentList) DeepCopy() *ClusterAgentList {
 	if in == nil {
 		return nil
 	}
-	out := new(DataSinkList)
+	out := new(ClusterAgentList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *DataSinkList) DeepCopyObject() runtime.Object {
+func (in *ClusterAgentList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -166,114 +225,105 @@ func (in *DataSinkList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *DataSinkReference) DeepCopyInto(out *DataSinkReference) {
+func (in *ClusterAgentMetric) DeepCopyInto(out *ClusterAgentMetric) {
 	*out = *in
+	if in.LastExportTime != nil {
+		in, out := &in.LastExportTime, &out.LastExportTime
+		*out = (*in).DeepCopy()
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataSinkReference.
-func (in *DataSinkReference) DeepCopy() *DataSinkReference {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterAgentMetric.
+func (in *ClusterAgentMetric) DeepCopy() *ClusterAgentMetric {
 	if in == nil {
 		return nil
 	}
-	out := new(DataSinkReference)
+	out := new(ClusterAgentMetric)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *DataSinkSpec) DeepCopyInto(out *DataSinkSpec) {
+func (in *ClusterAgentSpec) DeepCopyInto(out *ClusterAgentSpec) {
 	*out = *in
-	out.Connection = in.Connection
-	if in.Authentication != nil {
-		in, out := &in.Authentication, &out.Authentication
-		*out = new(Authentication)
-		(*in).DeepCopyInto(*out)
-	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataSinkSpec.
-func (in *DataSinkSpec) DeepCopy() *DataSinkSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterAgentSpec.
+func (in *ClusterAgentSpec) DeepCopy() *ClusterAgentSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(DataSinkSpec)
+	out := new(ClusterAgentSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *DataSinkStatus) DeepCopyInto(out *DataSinkStatus) {
+func (in *ClusterAgentStatus) DeepCopyInto(out *ClusterAgentStatus) {
 	*out = *in
-	if in.Conditions != nil {
-		in, out := &in.Conditions, &out.Conditions
-		*out = make([]metav1.Condition, len(*in))
+	if in.LastHeartbeatTime != nil {
+		in, out := &in.LastHeartbeatTime, &out.LastHeartbeatTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Metrics != nil {
+		in, out := &in.Metrics, &out.Metrics
+		*out = make([]ClusterAgentMetric, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataSinkStatus.
-func (in *DataSinkStatus) DeepCopy() *DataSinkStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterAgentStatus.
+func (in *ClusterAgentStatus) DeepCopy() *ClusterAgentStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(DataSinkStatus)
+	out := new(ClusterAgentStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *Dimension) DeepCopyInto(out *Dimension) {
+func (in *ClusterAgents) DeepCopyInto(out *ClusterAgents) {
 	*out = *in
-}
-
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Dimension.
-func (in *Dimension) DeepCopy() *Dimension {
-	if in == nil {
-		return nil
+	if in.Selector != nil {
+		in, out := &in.Selector, &out.Selector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
 	}
-	out := new(Dimension)
-	in.DeepCopyInto(out)
-	return out
-}
-
-// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *FederateClusterAccessRef) DeepCopyInto(out *FederateClusterAccessRef) {
-	*out = *in
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FederateClusterAccessRef.
-func (in *FederateClusterAccessRef) DeepCopy() *FederateClusterAccessRef {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterAgents.
+func (in *ClusterAgents) DeepCopy() *ClusterAgents {
 	if in == nil {
 		return nil
 	}
-	out := new(FederateClusterAccessRef)
+	out := new(ClusterAgents)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *FederatedClusterAccess) DeepCopyInto(out *FederatedClusterAccess) {
+func (in *ClusterMetricsStatus) DeepCopyInto(out *ClusterMetricsStatus) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FederatedClusterAccess.
-func (in *FederatedClusterAccess) DeepCopy() *FederatedClusterAccess {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterMetricsStatus.
+func (in *ClusterMetricsStatus) DeepCopy() *ClusterMetricsStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(FederatedClusterAccess)
+	out := new(ClusterMetricsStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *FederatedClusterAccess) DeepCopyObject() runtime.Object {
+func (in *ClusterMetricsStatus) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -281,31 +331,31 @@ func (in *FederatedClusterAccess) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *FederatedClusterAccessList) DeepCopyInto(out *FederatedClusterAccessList) {
+func (in *ClusterMetricsStatusList) DeepCopyInto(out *ClusterMetricsStatusList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]FederatedClusterAccess, len(*in))
+		*out = make([]ClusterMetricsStatus, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FederatedClusterAccessList.
-func (in *FederatedClusterAccessList) DeepCopy() *FederatedClusterAccessList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterMetricsStatusList.
+func (in *ClusterMetricsStatusList) DeepCopy() *ClusterMetricsStatusList {
 	if in == nil {
 		return nil
 	}
-	out := new(FederatedClusterAccessList)
+	out := new(ClusterMetricsStatusList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *FederatedClusterAccessList) DeepCopyObject() runtime.Object {
+func (in *ClusterMetricsStatusList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -313,38 +363,38 @@ func (in *FederatedClusterAccessList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *FederatedClusterAccessSpec) DeepCopyInto(out *FederatedClusterAccessSpec) {
+func (in *ClusterMetricsStatusStatus) DeepCopyInto(out *ClusterMetricsStatusStatus) {
 	*out = *in
-	out.Target = in.Target
-}
-
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FederatedClusterAccessSpec.
-func (in *FederatedClusterAccessSpec) DeepCopy() *FederatedClusterAccessSpec {
-	if in == nil {
-		return nil
+	if in.Kinds != nil {
+		in, out := &in.Kinds, &out.Kinds
+		*out = make([]MetricKindSummary, len(*in))
+		copy(*out, *in)
+	}
+	if in.LastUpdateTime != nil {
+		in, out := &in.LastUpdateTime, &out.LastUpdateTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
-	out := new(FederatedClusterAccessSpec)
-	in.DeepCopyInto(out)
-	return out
-}
-
-// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *FederatedClusterAccessStatus) DeepCopyInto(out *FederatedClusterAccessStatus) {
-	*out = *in
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FederatedClusterAccessStatus.
-func (in *FederatedClusterAccessStatus) DeepCopy() *FederatedClusterAccessStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterMetricsStatusStatus.
+func (in *ClusterMetricsStatusStatus) DeepCopy() *ClusterMetricsStatusStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(FederatedClusterAccessStatus)
+	out := new(ClusterMetricsStatusStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *FederatedManagedMetric) DeepCopyInto(out *FederatedManagedMetric) {
+func (in *CompositeMetric) DeepCopyInto(out *CompositeMetric) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
@@ -352,18 +402,18 @@ func (in *FederatedManagedMetric) DeepCopyInto(out *FederatedManagedMetric) {
 	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FederatedManagedMetric.
-func (in *FederatedManagedMetric) DeepCopy() *FederatedManagedMetric {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CompositeMetric.
+func (in *CompositeMetric) DeepCopy() *CompositeMetric {
 	if in == nil {
 		return nil
 	}
-	out := new(FederatedManagedMetric)
+	out := new(CompositeMetric)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *FederatedManagedMetric) DeepCopyObject() runtime.Object {
+func (in *CompositeMetric) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -371,31 +421,31 @@ func (in *FederatedManagedMetric) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *FederatedManagedMetricList) DeepCopyInto(out *FederatedManagedMetricList) {
+func (in *CompositeMetricList) DeepCopyInto(out *CompositeMetricList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]FederatedManagedMetric, len(*in))
+		*out = make([]CompositeMetric, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FederatedManagedMetricList.
-func (in *FederatedManagedMetricList) DeepCopy() *FederatedManagedMetricList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CompositeMetricList.
+func (in *CompositeMetricList) DeepCopy() *CompositeMetricList {
 	if in == nil {
 		return nil
 	}
-	out := new(FederatedManagedMetricList)
+	out := new(CompositeMetricList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *FederatedManagedMetricList) DeepCopyObject() runtime.Object {
+func (in *CompositeMetricList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -403,31 +453,72 @@ func (in *FederatedManagedMetricList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *FederatedManagedMetricSpec) DeepCopyInto(out *FederatedManagedMetricSpec) {
+func (in *CompositeMetricSource) DeepCopyInto(out *CompositeMetricSource) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CompositeMetricSource.
+func (in *CompositeMetricSource) DeepCopy() *CompositeMetricSource {
+	if in == nil {
+		return nil
+	}
+	out := new(CompositeMetricSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CompositeMetricSpec) DeepCopyInto(out *CompositeMetricSpec) {
 	*out = *in
+	if in.Sources != nil {
+		in, out := &in.Sources, &out.Sources
+		*out = make([]CompositeMetricSource, len(*in))
+		copy(*out, *in)
+	}
 	out.Interval = in.Interval
+	if in.RetryPolicy != nil {
+		in, out := &in.RetryPolicy, &out.RetryPolicy
+		*out = new(RetryPolicy)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.DataSinkRef != nil {
 		in, out := &in.DataSinkRef, &out.DataSinkRef
 		*out = new(DataSinkReference)
 		**out = **in
 	}
-	out.FederatedClusterAccessRef = in.FederatedClusterAccessRef
+	if in.ScopeAttributes != nil {
+		in, out := &in.ScopeAttributes, &out.ScopeAttributes
+		*out = make([]ScopeAttribute, len(*in))
+		copy(*out, *in)
+	}
+	if in.StaticDimensions != nil {
+		in, out := &in.StaticDimensions, &out.StaticDimensions
+		*out = make([]StaticDimension, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Thresholds != nil {
+		in, out := &in.Thresholds, &out.Thresholds
+		*out = new(Thresholds)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FederatedManagedMetricSpec.
-func (in *FederatedManagedMetricSpec) DeepCopy() *FederatedManagedMetricSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CompositeMetricSpec.
+func (in *CompositeMetricSpec) DeepCopy() *CompositeMetricSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(FederatedManagedMetricSpec)
+	out := new(CompositeMetricSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *FederatedManagedMetricStatus) DeepCopyInto(out *FederatedManagedMetricStatus) {
+func (in *CompositeMetricStatus) DeepCopyInto(out *CompositeMetricStatus) {
 	*out = *in
-	out.Observation = in.Observation
+	in.Observation.DeepCopyInto(&out.Observation)
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
 		*out = make([]metav1.Condition, len(*in))
@@ -435,24 +526,103 @@ func (in *FederatedManagedMetricStatus) DeepCopyInto(out *FederatedManagedMetric
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
-	if in.LastReconcileTime != nil {
-		in, out := &in.LastReconcileTime, &out.LastReconcileTime
+	if in.NextRunTime != nil {
+		in, out := &in.NextRunTime, &out.NextRunTime
 		*out = (*in).DeepCopy()
 	}
+	in.ExportStatus.DeepCopyInto(&out.ExportStatus)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FederatedManagedMetricStatus.
-func (in *FederatedManagedMetricStatus) DeepCopy() *FederatedManagedMetricStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CompositeMetricStatus.
+func (in *CompositeMetricStatus) DeepCopy() *CompositeMetricStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(FederatedManagedMetricStatus)
+	out := new(CompositeMetricStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *FederatedMetric) DeepCopyInto(out *FederatedMetric) {
+func (in *ConditionRequirement) DeepCopyInto(out *ConditionRequirement) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConditionRequirement.
+func (in *ConditionRequirement) DeepCopy() *ConditionRequirement {
+	if in == nil {
+		return nil
+	}
+	out := new(ConditionRequirement)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Connection) DeepCopyInto(out *Connection) {
+	*out = *in
+	if in.Proxy != nil {
+		in, out := &in.Proxy, &out.Proxy
+		*out = new(Proxy)
+		**out = **in
+	}
+	if in.CABundle != nil {
+		in, out := &in.CABundle, &out.CABundle
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Timeout != nil {
+		in, out := &in.Timeout, &out.Timeout
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.Retry != nil {
+		in, out := &in.Retry, &out.Retry
+		*out = new(ExportRetry)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.GRPC != nil {
+		in, out := &in.GRPC, &out.GRPC
+		*out = new(GRPCSettings)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Connection.
+func (in *Connection) DeepCopy() *Connection {
+	if in == nil {
+		return nil
+	}
+	out := new(Connection)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ControlPlaneFederatedMetricTemplate) DeepCopyInto(out *ControlPlaneFederatedMetricTemplate) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ControlPlaneFederatedMetricTemplate.
+func (in *ControlPlaneFederatedMetricTemplate) DeepCopy() *ControlPlaneFederatedMetricTemplate {
+	if in == nil {
+		return nil
+	}
+	out := new(ControlPlaneFederatedMetricTemplate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ControlPlaneMetricSet) DeepCopyInto(out *ControlPlaneMetricSet) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
@@ -460,18 +630,18 @@ func (in *FederatedMetric) DeepCopyInto(out *FederatedMetric) {
 	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FederatedMetric.
-func (in *FederatedMetric) DeepCopy() *FederatedMetric {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ControlPlaneMetricSet.
+func (in *ControlPlaneMetricSet) DeepCopy() *ControlPlaneMetricSet {
 	if in == nil {
 		return nil
 	}
-	out := new(FederatedMetric)
+	out := new(ControlPlaneMetricSet)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *FederatedMetric) DeepCopyObject() runtime.Object {
+func (in *ControlPlaneMetricSet) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -479,31 +649,31 @@ func (in *FederatedMetric) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *FederatedMetricList) DeepCopyInto(out *FederatedMetricList) {
+func (in *ControlPlaneMetricSetList) DeepCopyInto(out *ControlPlaneMetricSetList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]FederatedMetric, len(*in))
+		*out = make([]ControlPlaneMetricSet, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FederatedMetricList.
-func (in *FederatedMetricList) DeepCopy() *FederatedMetricList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ControlPlaneMetricSetList.
+func (in *ControlPlaneMetricSetList) DeepCopy() *ControlPlaneMetricSetList {
 	if in == nil {
 		return nil
 	}
-	out := new(FederatedMetricList)
+	out := new(ControlPlaneMetricSetList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *FederatedMetricList) DeepCopyObject() runtime.Object {
+func (in *ControlPlaneMetricSetList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -511,44 +681,42 @@ func (in *FederatedMetricList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *FederatedMetricSpec) DeepCopyInto(out *FederatedMetricSpec) {
+func (in *ControlPlaneMetricSetSpec) DeepCopyInto(out *ControlPlaneMetricSetSpec) {
 	*out = *in
-	out.Target = in.Target
-	if in.Projections != nil {
-		in, out := &in.Projections, &out.Projections
-		*out = make([]Projection, len(*in))
+	if in.Selector != nil {
+		in, out := &in.Selector, &out.Selector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Metrics != nil {
+		in, out := &in.Metrics, &out.Metrics
+		*out = make([]ControlPlaneMetricTemplate, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
-	if in.ValueFrom != nil {
-		in, out := &in.ValueFrom, &out.ValueFrom
-		*out = new(ValueFromProjection)
-		(*in).DeepCopyInto(*out)
-	}
-	out.Interval = in.Interval
-	if in.DataSinkRef != nil {
-		in, out := &in.DataSinkRef, &out.DataSinkRef
-		*out = new(DataSinkReference)
-		**out = **in
+	if in.FederatedMetrics != nil {
+		in, out := &in.FederatedMetrics, &out.FederatedMetrics
+		*out = make([]ControlPlaneFederatedMetricTemplate, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
-	out.FederatedClusterAccessRef = in.FederatedClusterAccessRef
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FederatedMetricSpec.
-func (in *FederatedMetricSpec) DeepCopy() *FederatedMetricSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ControlPlaneMetricSetSpec.
+func (in *ControlPlaneMetricSetSpec) DeepCopy() *ControlPlaneMetricSetSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(FederatedMetricSpec)
+	out := new(ControlPlaneMetricSetSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *FederatedMetricStatus) DeepCopyInto(out *FederatedMetricStatus) {
+func (in *ControlPlaneMetricSetStatus) DeepCopyInto(out *ControlPlaneMetricSetStatus) {
 	*out = *in
-	out.Observation = in.Observation
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
 		*out = make([]metav1.Condition, len(*in))
@@ -556,69 +724,64 @@ func (in *FederatedMetricStatus) DeepCopyInto(out *FederatedMetricStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
-	if in.LastReconcileTime != nil {
-		in, out := &in.LastReconcileTime, &out.LastReconcileTime
-		*out = (*in).DeepCopy()
-	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FederatedMetricStatus.
-func (in *FederatedMetricStatus) DeepCopy() *FederatedMetricStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ControlPlaneMetricSetStatus.
+func (in *ControlPlaneMetricSetStatus) DeepCopy() *ControlPlaneMetricSetStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(FederatedMetricStatus)
+	out := new(ControlPlaneMetricSetStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *FederatedObservation) DeepCopyInto(out *FederatedObservation) {
+func (in *ControlPlaneMetricTemplate) DeepCopyInto(out *ControlPlaneMetricTemplate) {
 	*out = *in
-}
-
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FederatedObservation.
-func (in *FederatedObservation) DeepCopy() *FederatedObservation {
-	if in == nil {
-		return nil
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
 	}
-	out := new(FederatedObservation)
-	in.DeepCopyInto(out)
-	return out
-}
-
-// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *GroupVersionKind) DeepCopyInto(out *GroupVersionKind) {
-	*out = *in
+	in.Spec.DeepCopyInto(&out.Spec)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GroupVersionKind.
-func (in *GroupVersionKind) DeepCopy() *GroupVersionKind {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ControlPlaneMetricTemplate.
+func (in *ControlPlaneMetricTemplate) DeepCopy() *ControlPlaneMetricTemplate {
 	if in == nil {
 		return nil
 	}
-	out := new(GroupVersionKind)
+	out := new(ControlPlaneMetricTemplate)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *KubeConfigSecretRef) DeepCopyInto(out *KubeConfigSecretRef) {
-	*out = *in
+func (in DataPointDimensions) DeepCopyInto(out *DataPointDimensions) {
+	{
+		in := &in
+		*out = make(DataPointDimensions, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubeConfigSecretRef.
-func (in *KubeConfigSecretRef) DeepCopy() *KubeConfigSecretRef {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataPointDimensions.
+func (in DataPointDimensions) DeepCopy() DataPointDimensions {
 	if in == nil {
 		return nil
 	}
-	out := new(KubeConfigSecretRef)
+	out := new(DataPointDimensions)
 	in.DeepCopyInto(out)
-	return out
+	return *out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ManagedMetric) DeepCopyInto(out *ManagedMetric) {
+func (in *DataSink) DeepCopyInto(out *DataSink) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
@@ -626,18 +789,18 @@ func (in *ManagedMetric) DeepCopyInto(out *ManagedMetric) {
 	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ManagedMetric.
-func (in *ManagedMetric) DeepCopy() *ManagedMetric {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataSink.
+func (in *DataSink) DeepCopy() *DataSink {
 	if in == nil {
 		return nil
 	}
-	out := new(ManagedMetric)
+	out := new(DataSink)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *ManagedMetric) DeepCopyObject() runtime.Object {
+func (in *DataSink) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -645,31 +808,31 @@ func (in *ManagedMetric) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ManagedMetricList) DeepCopyInto(out *ManagedMetricList) {
+func (in *DataSinkList) DeepCopyInto(out *DataSinkList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]ManagedMetric, len(*in))
+		*out = make([]DataSink, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ManagedMetricList.
-func (in *ManagedMetricList) DeepCopy() *ManagedMetricList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataSinkList.
+func (in *DataSinkList) DeepCopy() *DataSinkList {
 	if in == nil {
 		return nil
 	}
-	out := new(ManagedMetricList)
+	out := new(DataSinkList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *ManagedMetricList) DeepCopyObject() runtime.Object {
+func (in *DataSinkList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -677,47 +840,74 @@ func (in *ManagedMetricList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ManagedMetricSpec) DeepCopyInto(out *ManagedMetricSpec) {
+func (in *DataSinkReference) DeepCopyInto(out *DataSinkReference) {
 	*out = *in
-	if in.Target != nil {
-		in, out := &in.Target, &out.Target
-		*out = new(GroupVersionKind)
-		**out = **in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataSinkReference.
+func (in *DataSinkReference) DeepCopy() *DataSinkReference {
+	if in == nil {
+		return nil
 	}
-	if in.Dimensions != nil {
-		in, out := &in.Dimensions, &out.Dimensions
-		*out = make([]Projection, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
+	out := new(DataSinkReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DataSinkSpec) DeepCopyInto(out *DataSinkSpec) {
+	*out = *in
+	in.Connection.DeepCopyInto(&out.Connection)
+	if in.Authentication != nil {
+		in, out := &in.Authentication, &out.Authentication
+		*out = new(Authentication)
+		(*in).DeepCopyInto(*out)
 	}
-	out.Interval = in.Interval
-	if in.DataSinkRef != nil {
-		in, out := &in.DataSinkRef, &out.DataSinkRef
-		*out = new(DataSinkReference)
+	if in.InfluxDB != nil {
+		in, out := &in.InfluxDB, &out.InfluxDB
+		*out = new(InfluxDBSettings)
 		**out = **in
 	}
-	if in.RemoteClusterAccessRef != nil {
-		in, out := &in.RemoteClusterAccessRef, &out.RemoteClusterAccessRef
-		*out = new(RemoteClusterAccessRef)
+	if in.Webhook != nil {
+		in, out := &in.Webhook, &out.Webhook
+		*out = new(WebhookSettings)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.OTLP != nil {
+		in, out := &in.OTLP, &out.OTLP
+		*out = new(OTLPSettings)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DeletedSeries != nil {
+		in, out := &in.DeletedSeries, &out.DeletedSeries
+		*out = new(DeletedSeries)
 		**out = **in
 	}
+	if in.DimensionPolicy != nil {
+		in, out := &in.DimensionPolicy, &out.DimensionPolicy
+		*out = new(DimensionPolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AllowedNamespaces != nil {
+		in, out := &in.AllowedNamespaces, &out.AllowedNamespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ManagedMetricSpec.
-func (in *ManagedMetricSpec) DeepCopy() *ManagedMetricSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataSinkSpec.
+func (in *DataSinkSpec) DeepCopy() *DataSinkSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(ManagedMetricSpec)
+	out := new(DataSinkSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ManagedMetricStatus) DeepCopyInto(out *ManagedMetricStatus) {
+func (in *DataSinkStatus) DeepCopyInto(out *DataSinkStatus) {
 	*out = *in
-	in.Observation.DeepCopyInto(&out.Observation)
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
 		*out = make([]metav1.Condition, len(*in))
@@ -725,129 +915,133 @@ func (in *ManagedMetricStatus) DeepCopyInto(out *ManagedMetricStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.LastProbeTime != nil {
+		in, out := &in.LastProbeTime, &out.LastProbeTime
+		*out = (*in).DeepCopy()
+	}
+	if in.LastSuccessfulProbeTime != nil {
+		in, out := &in.LastSuccessfulProbeTime, &out.LastSuccessfulProbeTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CircuitBreaker != nil {
+		in, out := &in.CircuitBreaker, &out.CircuitBreaker
+		*out = new(CircuitBreakerStatus)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ManagedMetricStatus.
-func (in *ManagedMetricStatus) DeepCopy() *ManagedMetricStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataSinkStatus.
+func (in *DataSinkStatus) DeepCopy() *DataSinkStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(ManagedMetricStatus)
+	out := new(DataSinkStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ManagedObservation) DeepCopyInto(out *ManagedObservation) {
+func (in *DebugOptions) DeepCopyInto(out *DebugOptions) {
 	*out = *in
-	in.Timestamp.DeepCopyInto(&out.Timestamp)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ManagedObservation.
-func (in *ManagedObservation) DeepCopy() *ManagedObservation {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DebugOptions.
+func (in *DebugOptions) DeepCopy() *DebugOptions {
 	if in == nil {
 		return nil
 	}
-	out := new(ManagedObservation)
+	out := new(DebugOptions)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *Metric) DeepCopyInto(out *Metric) {
+func (in *DeletedSeries) DeepCopyInto(out *DeletedSeries) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	in.Spec.DeepCopyInto(&out.Spec)
-	in.Status.DeepCopyInto(&out.Status)
+	out.GracePeriod = in.GracePeriod
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Metric.
-func (in *Metric) DeepCopy() *Metric {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeletedSeries.
+func (in *DeletedSeries) DeepCopy() *DeletedSeries {
 	if in == nil {
 		return nil
 	}
-	out := new(Metric)
+	out := new(DeletedSeries)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *Metric) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Dimension) DeepCopyInto(out *Dimension) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Dimension.
+func (in *Dimension) DeepCopy() *Dimension {
+	if in == nil {
+		return nil
 	}
-	return nil
+	out := new(Dimension)
+	in.DeepCopyInto(out)
+	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *MetricList) DeepCopyInto(out *MetricList) {
+func (in *DimensionPolicy) DeepCopyInto(out *DimensionPolicy) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ListMeta.DeepCopyInto(&out.ListMeta)
-	if in.Items != nil {
-		in, out := &in.Items, &out.Items
-		*out = make([]Metric, len(*in))
+	if in.AllowedKeys != nil {
+		in, out := &in.AllowedKeys, &out.AllowedKeys
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.DeniedKeys != nil {
+		in, out := &in.DeniedKeys, &out.DeniedKeys
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Redactions != nil {
+		in, out := &in.Redactions, &out.Redactions
+		*out = make([]DimensionRedaction, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MetricList.
-func (in *MetricList) DeepCopy() *MetricList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DimensionPolicy.
+func (in *DimensionPolicy) DeepCopy() *DimensionPolicy {
 	if in == nil {
 		return nil
 	}
-	out := new(MetricList)
+	out := new(DimensionPolicy)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *MetricList) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
-	}
-	return nil
-}
-
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *MetricObservation) DeepCopyInto(out *MetricObservation) {
+func (in *DimensionRedaction) DeepCopyInto(out *DimensionRedaction) {
 	*out = *in
-	in.Timestamp.DeepCopyInto(&out.Timestamp)
-	if in.Dimensions != nil {
-		in, out := &in.Dimensions, &out.Dimensions
-		*out = make([]Dimension, len(*in))
+	if in.Keys != nil {
+		in, out := &in.Keys, &out.Keys
+		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MetricObservation.
-func (in *MetricObservation) DeepCopy() *MetricObservation {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DimensionRedaction.
+func (in *DimensionRedaction) DeepCopy() *DimensionRedaction {
 	if in == nil {
 		return nil
 	}
-	out := new(MetricObservation)
+	out := new(DimensionRedaction)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *MetricSpec) DeepCopyInto(out *MetricSpec) {
+func (in *Enrichment) DeepCopyInto(out *Enrichment) {
 	*out = *in
 	out.Target = in.Target
-	out.Interval = in.Interval
-	if in.DataSinkRef != nil {
-		in, out := &in.DataSinkRef, &out.DataSinkRef
-		*out = new(DataSinkReference)
-		**out = **in
-	}
-	if in.RemoteClusterAccessRef != nil {
-		in, out := &in.RemoteClusterAccessRef, &out.RemoteClusterAccessRef
-		*out = new(RemoteClusterAccessRef)
-		**out = **in
-	}
 	if in.Projections != nil {
 		in, out := &in.Projections, &out.Projections
 		*out = make([]Projection, len(*in))
@@ -855,68 +1049,1695 @@ func (in *MetricSpec) DeepCopyInto(out *MetricSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
-	if in.ValueFrom != nil {
-		in, out := &in.ValueFrom, &out.ValueFrom
-		*out = new(ValueFromProjection)
-		(*in).DeepCopyInto(*out)
-	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MetricSpec.
-func (in *MetricSpec) DeepCopy() *MetricSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Enrichment.
+func (in *Enrichment) DeepCopy() *Enrichment {
 	if in == nil {
 		return nil
 	}
-	out := new(MetricSpec)
+	out := new(Enrichment)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *MetricStatus) DeepCopyInto(out *MetricStatus) {
+func (in *ExportRetry) DeepCopyInto(out *ExportRetry) {
 	*out = *in
-	in.Observation.DeepCopyInto(&out.Observation)
-	if in.Conditions != nil {
-		in, out := &in.Conditions, &out.Conditions
-		*out = make([]metav1.Condition, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
+		**out = **in
 	}
+	out.InitialInterval = in.InitialInterval
+	out.MaxInterval = in.MaxInterval
+	out.MaxElapsedTime = in.MaxElapsedTime
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MetricStatus.
-func (in *MetricStatus) DeepCopy() *MetricStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExportRetry.
+func (in *ExportRetry) DeepCopy() *ExportRetry {
 	if in == nil {
 		return nil
 	}
-	out := new(MetricStatus)
+	out := new(ExportRetry)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *Projection) DeepCopyInto(out *Projection) {
+func (in *ExportStatus) DeepCopyInto(out *ExportStatus) {
 	*out = *in
-	if in.Default != nil {
-		in, out := &in.Default, &out.Default
-		*out = new(ProjectionDefaultValue)
-		(*in).DeepCopyInto(*out)
+	if in.LastExportTime != nil {
+		in, out := &in.LastExportTime, &out.LastExportTime
+		*out = (*in).DeepCopy()
+	}
+	if in.LastExportDuration != nil {
+		in, out := &in.LastExportDuration, &out.LastExportDuration
+		*out = new(metav1.Duration)
+		**out = **in
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Projection.
-func (in *Projection) DeepCopy() *Projection {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExportStatus.
+func (in *ExportStatus) DeepCopy() *ExportStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(Projection)
+	out := new(ExportStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ProjectionDefaultValue) DeepCopyInto(out *ProjectionDefaultValue) {
+func (in *FederateClusterAccessRef) DeepCopyInto(out *FederateClusterAccessRef) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FederateClusterAccessRef.
+func (in *FederateClusterAccessRef) DeepCopy() *FederateClusterAccessRef {
+	if in == nil {
+		return nil
+	}
+	out := new(FederateClusterAccessRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FederatedCluster) DeepCopyInto(out *FederatedCluster) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FederatedCluster.
+func (in *FederatedCluster) DeepCopy() *FederatedCluster {
+	if in == nil {
+		return nil
+	}
+	out := new(FederatedCluster)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FederatedClusterAccess) DeepCopyInto(out *FederatedClusterAccess) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FederatedClusterAccess.
+func (in *FederatedClusterAccess) DeepCopy() *FederatedClusterAccess {
+	if in == nil {
+		return nil
+	}
+	out := new(FederatedClusterAccess)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *FederatedClusterAccess) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FederatedClusterAccessList) DeepCopyInto(out *FederatedClusterAccessList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]FederatedClusterAccess, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FederatedClusterAccessList.
+func (in *FederatedClusterAccessList) DeepCopy() *FederatedClusterAccessList {
+	if in == nil {
+		return nil
+	}
+	out := new(FederatedClusterAccessList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *FederatedClusterAccessList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FederatedClusterAccessSpec) DeepCopyInto(out *FederatedClusterAccessSpec) {
+	*out = *in
+	out.Target = in.Target
+	if in.SecretSelector != nil {
+		in, out := &in.SecretSelector, &out.SecretSelector
+		*out = new(KubeConfigSecretSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Gardener != nil {
+		in, out := &in.Gardener, &out.Gardener
+		*out = new(GardenerShoots)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ClusterAPI != nil {
+		in, out := &in.ClusterAPI, &out.ClusterAPI
+		*out = new(ClusterAPIClusters)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.OCM != nil {
+		in, out := &in.OCM, &out.OCM
+		*out = new(OCMManagedClusters)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Karmada != nil {
+		in, out := &in.Karmada, &out.Karmada
+		*out = new(KarmadaClusters)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Agents != nil {
+		in, out := &in.Agents, &out.Agents
+		*out = new(ClusterAgents)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ClusterLabels != nil {
+		in, out := &in.ClusterLabels, &out.ClusterLabels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.MemberLabels != nil {
+		in, out := &in.MemberLabels, &out.MemberLabels
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FederatedClusterAccessSpec.
+func (in *FederatedClusterAccessSpec) DeepCopy() *FederatedClusterAccessSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(FederatedClusterAccessSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FederatedClusterAccessStatus) DeepCopyInto(out *FederatedClusterAccessStatus) {
+	*out = *in
+	if in.Clusters != nil {
+		in, out := &in.Clusters, &out.Clusters
+		*out = make([]FederatedCluster, len(*in))
+		copy(*out, *in)
+	}
+	if in.LastRefreshTime != nil {
+		in, out := &in.LastRefreshTime, &out.LastRefreshTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CredentialsExpiry != nil {
+		in, out := &in.CredentialsExpiry, &out.CredentialsExpiry
+		*out = (*in).DeepCopy()
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FederatedClusterAccessStatus.
+func (in *FederatedClusterAccessStatus) DeepCopy() *FederatedClusterAccessStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(FederatedClusterAccessStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FederatedManagedMetric) DeepCopyInto(out *FederatedManagedMetric) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FederatedManagedMetric.
+func (in *FederatedManagedMetric) DeepCopy() *FederatedManagedMetric {
+	if in == nil {
+		return nil
+	}
+	out := new(FederatedManagedMetric)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *FederatedManagedMetric) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FederatedManagedMetricList) DeepCopyInto(out *FederatedManagedMetricList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]FederatedManagedMetric, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FederatedManagedMetricList.
+func (in *FederatedManagedMetricList) DeepCopy() *FederatedManagedMetricList {
+	if in == nil {
+		return nil
+	}
+	out := new(FederatedManagedMetricList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *FederatedManagedMetricList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FederatedManagedMetricSpec) DeepCopyInto(out *FederatedManagedMetricSpec) {
+	*out = *in
+	if in.Target != nil {
+		in, out := &in.Target, &out.Target
+		*out = new(GroupVersionKind)
+		**out = **in
+	}
+	if in.Dimensions != nil {
+		in, out := &in.Dimensions, &out.Dimensions
+		*out = make([]Projection, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	out.Interval = in.Interval
+	if in.RetryPolicy != nil {
+		in, out := &in.RetryPolicy, &out.RetryPolicy
+		*out = new(RetryPolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DataSinkRef != nil {
+		in, out := &in.DataSinkRef, &out.DataSinkRef
+		*out = new(DataSinkReference)
+		**out = **in
+	}
+	if in.ScopeAttributes != nil {
+		in, out := &in.ScopeAttributes, &out.ScopeAttributes
+		*out = make([]ScopeAttribute, len(*in))
+		copy(*out, *in)
+	}
+	if in.StaticDimensions != nil {
+		in, out := &in.StaticDimensions, &out.StaticDimensions
+		*out = make([]StaticDimension, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	out.FederatedClusterAccessRef = in.FederatedClusterAccessRef
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FederatedManagedMetricSpec.
+func (in *FederatedManagedMetricSpec) DeepCopy() *FederatedManagedMetricSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(FederatedManagedMetricSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FederatedManagedMetricStatus) DeepCopyInto(out *FederatedManagedMetricStatus) {
+	*out = *in
+	out.Observation = in.Observation
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.LastReconcileTime != nil {
+		in, out := &in.LastReconcileTime, &out.LastReconcileTime
+		*out = (*in).DeepCopy()
+	}
+	if in.NextRunTime != nil {
+		in, out := &in.NextRunTime, &out.NextRunTime
+		*out = (*in).DeepCopy()
+	}
+	in.ExportStatus.DeepCopyInto(&out.ExportStatus)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FederatedManagedMetricStatus.
+func (in *FederatedManagedMetricStatus) DeepCopy() *FederatedManagedMetricStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(FederatedManagedMetricStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FederatedMetric) DeepCopyInto(out *FederatedMetric) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FederatedMetric.
+func (in *FederatedMetric) DeepCopy() *FederatedMetric {
+	if in == nil {
+		return nil
+	}
+	out := new(FederatedMetric)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *FederatedMetric) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FederatedMetricList) DeepCopyInto(out *FederatedMetricList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]FederatedMetric, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FederatedMetricList.
+func (in *FederatedMetricList) DeepCopy() *FederatedMetricList {
+	if in == nil {
+		return nil
+	}
+	out := new(FederatedMetricList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *FederatedMetricList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FederatedMetricSpec) DeepCopyInto(out *FederatedMetricSpec) {
+	*out = *in
+	out.Target = in.Target
+	if in.Projections != nil {
+		in, out := &in.Projections, &out.Projections
+		*out = make([]Projection, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ValueFrom != nil {
+		in, out := &in.ValueFrom, &out.ValueFrom
+		*out = new(ValueFromProjection)
+		(*in).DeepCopyInto(*out)
+	}
+	out.Interval = in.Interval
+	if in.RetryPolicy != nil {
+		in, out := &in.RetryPolicy, &out.RetryPolicy
+		*out = new(RetryPolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DataSinkRef != nil {
+		in, out := &in.DataSinkRef, &out.DataSinkRef
+		*out = new(DataSinkReference)
+		**out = **in
+	}
+	if in.ScopeAttributes != nil {
+		in, out := &in.ScopeAttributes, &out.ScopeAttributes
+		*out = make([]ScopeAttribute, len(*in))
+		copy(*out, *in)
+	}
+	if in.StaticDimensions != nil {
+		in, out := &in.StaticDimensions, &out.StaticDimensions
+		*out = make([]StaticDimension, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	out.FederatedClusterAccessRef = in.FederatedClusterAccessRef
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FederatedMetricSpec.
+func (in *FederatedMetricSpec) DeepCopy() *FederatedMetricSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(FederatedMetricSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FederatedMetricStatus) DeepCopyInto(out *FederatedMetricStatus) {
+	*out = *in
+	out.Observation = in.Observation
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.LastReconcileTime != nil {
+		in, out := &in.LastReconcileTime, &out.LastReconcileTime
+		*out = (*in).DeepCopy()
+	}
+	if in.NextRunTime != nil {
+		in, out := &in.NextRunTime, &out.NextRunTime
+		*out = (*in).DeepCopy()
+	}
+	in.ExportStatus.DeepCopyInto(&out.ExportStatus)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FederatedMetricStatus.
+func (in *FederatedMetricStatus) DeepCopy() *FederatedMetricStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(FederatedMetricStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FederatedObservation) DeepCopyInto(out *FederatedObservation) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FederatedObservation.
+func (in *FederatedObservation) DeepCopy() *FederatedObservation {
+	if in == nil {
+		return nil
+	}
+	out := new(FederatedObservation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GRPCSettings) DeepCopyInto(out *GRPCSettings) {
+	*out = *in
+	if in.KeepaliveTime != nil {
+		in, out := &in.KeepaliveTime, &out.KeepaliveTime
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	out.KeepaliveTimeout = in.KeepaliveTimeout
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GRPCSettings.
+func (in *GRPCSettings) DeepCopy() *GRPCSettings {
+	if in == nil {
+		return nil
+	}
+	out := new(GRPCSettings)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GardenerShoots) DeepCopyInto(out *GardenerShoots) {
+	*out = *in
+	if in.Selector != nil {
+		in, out := &in.Selector, &out.Selector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GardenerShoots.
+func (in *GardenerShoots) DeepCopy() *GardenerShoots {
+	if in == nil {
+		return nil
+	}
+	out := new(GardenerShoots)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GroupVersionKind) DeepCopyInto(out *GroupVersionKind) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GroupVersionKind.
+func (in *GroupVersionKind) DeepCopy() *GroupVersionKind {
+	if in == nil {
+		return nil
+	}
+	out := new(GroupVersionKind)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InfluxDBSettings) DeepCopyInto(out *InfluxDBSettings) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InfluxDBSettings.
+func (in *InfluxDBSettings) DeepCopy() *InfluxDBSettings {
+	if in == nil {
+		return nil
+	}
+	out := new(InfluxDBSettings)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KarmadaClusters) DeepCopyInto(out *KarmadaClusters) {
+	*out = *in
+	if in.Selector != nil {
+		in, out := &in.Selector, &out.Selector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KarmadaClusters.
+func (in *KarmadaClusters) DeepCopy() *KarmadaClusters {
+	if in == nil {
+		return nil
+	}
+	out := new(KarmadaClusters)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KubeConfigSecretRef) DeepCopyInto(out *KubeConfigSecretRef) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubeConfigSecretRef.
+func (in *KubeConfigSecretRef) DeepCopy() *KubeConfigSecretRef {
+	if in == nil {
+		return nil
+	}
+	out := new(KubeConfigSecretRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KubeConfigSecretSelector) DeepCopyInto(out *KubeConfigSecretSelector) {
+	*out = *in
+	in.Selector.DeepCopyInto(&out.Selector)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubeConfigSecretSelector.
+func (in *KubeConfigSecretSelector) DeepCopy() *KubeConfigSecretSelector {
+	if in == nil {
+		return nil
+	}
+	out := new(KubeConfigSecretSelector)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ManagedMetric) DeepCopyInto(out *ManagedMetric) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ManagedMetric.
+func (in *ManagedMetric) DeepCopy() *ManagedMetric {
+	if in == nil {
+		return nil
+	}
+	out := new(ManagedMetric)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ManagedMetric) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ManagedMetricList) DeepCopyInto(out *ManagedMetricList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ManagedMetric, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ManagedMetricList.
+func (in *ManagedMetricList) DeepCopy() *ManagedMetricList {
+	if in == nil {
+		return nil
+	}
+	out := new(ManagedMetricList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ManagedMetricList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ManagedMetricSpec) DeepCopyInto(out *ManagedMetricSpec) {
+	*out = *in
+	if in.Target != nil {
+		in, out := &in.Target, &out.Target
+		*out = new(GroupVersionKind)
+		**out = **in
+	}
+	if in.Dimensions != nil {
+		in, out := &in.Dimensions, &out.Dimensions
+		*out = make([]Projection, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	out.Interval = in.Interval
+	if in.RetryPolicy != nil {
+		in, out := &in.RetryPolicy, &out.RetryPolicy
+		*out = new(RetryPolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DataSinkRef != nil {
+		in, out := &in.DataSinkRef, &out.DataSinkRef
+		*out = new(DataSinkReference)
+		**out = **in
+	}
+	if in.ScopeAttributes != nil {
+		in, out := &in.ScopeAttributes, &out.ScopeAttributes
+		*out = make([]ScopeAttribute, len(*in))
+		copy(*out, *in)
+	}
+	if in.StaticDimensions != nil {
+		in, out := &in.StaticDimensions, &out.StaticDimensions
+		*out = make([]StaticDimension, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.RemoteClusterAccessRef != nil {
+		in, out := &in.RemoteClusterAccessRef, &out.RemoteClusterAccessRef
+		*out = new(RemoteClusterAccessRef)
+		**out = **in
+	}
+	if in.CRDCategories != nil {
+		in, out := &in.CRDCategories, &out.CRDCategories
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ConditionTypes != nil {
+		in, out := &in.ConditionTypes, &out.ConditionTypes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ConditionFilter != nil {
+		in, out := &in.ConditionFilter, &out.ConditionFilter
+		*out = make([]ConditionRequirement, len(*in))
+		copy(*out, *in)
+	}
+	if in.Age != nil {
+		in, out := &in.Age, &out.Age
+		*out = new(AgeAggregation)
+		**out = **in
+	}
+	if in.Debug != nil {
+		in, out := &in.Debug, &out.Debug
+		*out = new(DebugOptions)
+		**out = **in
+	}
+	if in.Thresholds != nil {
+		in, out := &in.Thresholds, &out.Thresholds
+		*out = new(Thresholds)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ManagedMetricSpec.
+func (in *ManagedMetricSpec) DeepCopy() *ManagedMetricSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ManagedMetricSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ManagedMetricStatus) DeepCopyInto(out *ManagedMetricStatus) {
+	*out = *in
+	in.Observation.DeepCopyInto(&out.Observation)
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.NextRunTime != nil {
+		in, out := &in.NextRunTime, &out.NextRunTime
+		*out = (*in).DeepCopy()
+	}
+	in.ExportStatus.DeepCopyInto(&out.ExportStatus)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ManagedMetricStatus.
+func (in *ManagedMetricStatus) DeepCopy() *ManagedMetricStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ManagedMetricStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ManagedObservation) DeepCopyInto(out *ManagedObservation) {
+	*out = *in
+	in.Timestamp.DeepCopyInto(&out.Timestamp)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ManagedObservation.
+func (in *ManagedObservation) DeepCopy() *ManagedObservation {
+	if in == nil {
+		return nil
+	}
+	out := new(ManagedObservation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Metric) DeepCopyInto(out *Metric) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Metric.
+func (in *Metric) DeepCopy() *Metric {
+	if in == nil {
+		return nil
+	}
+	out := new(Metric)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *Metric) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MetricBaseline) DeepCopyInto(out *MetricBaseline) {
+	*out = *in
+	in.Timestamp.DeepCopyInto(&out.Timestamp)
+	if in.Values != nil {
+		in, out := &in.Values, &out.Values
+		*out = make(map[string]int64, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Dimensions != nil {
+		in, out := &in.Dimensions, &out.Dimensions
+		*out = make(map[string]DataPointDimensions, len(*in))
+		for key, val := range *in {
+			var outVal map[string]string
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				inVal := (*in)[key]
+				in, out := &inVal, &outVal
+				*out = make(DataPointDimensions, len(*in))
+				for key, val := range *in {
+					(*out)[key] = val
+				}
+			}
+			(*out)[key] = outVal
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MetricBaseline.
+func (in *MetricBaseline) DeepCopy() *MetricBaseline {
+	if in == nil {
+		return nil
+	}
+	out := new(MetricBaseline)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MetricKindSummary) DeepCopyInto(out *MetricKindSummary) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MetricKindSummary.
+func (in *MetricKindSummary) DeepCopy() *MetricKindSummary {
+	if in == nil {
+		return nil
+	}
+	out := new(MetricKindSummary)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MetricLastExport) DeepCopyInto(out *MetricLastExport) {
+	*out = *in
+	in.Timestamp.DeepCopyInto(&out.Timestamp)
+	if in.Values != nil {
+		in, out := &in.Values, &out.Values
+		*out = make(map[string]int64, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MetricLastExport.
+func (in *MetricLastExport) DeepCopy() *MetricLastExport {
+	if in == nil {
+		return nil
+	}
+	out := new(MetricLastExport)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MetricList) DeepCopyInto(out *MetricList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]Metric, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MetricList.
+func (in *MetricList) DeepCopy() *MetricList {
+	if in == nil {
+		return nil
+	}
+	out := new(MetricList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *MetricList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MetricObservation) DeepCopyInto(out *MetricObservation) {
+	*out = *in
+	in.Timestamp.DeepCopyInto(&out.Timestamp)
+	if in.Dimensions != nil {
+		in, out := &in.Dimensions, &out.Dimensions
+		*out = make([]Dimension, len(*in))
+		copy(*out, *in)
+	}
+	if in.Series != nil {
+		in, out := &in.Series, &out.Series
+		*out = make([]RecordedSeriesValue, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MetricObservation.
+func (in *MetricObservation) DeepCopy() *MetricObservation {
+	if in == nil {
+		return nil
+	}
+	out := new(MetricObservation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MetricSamplingWindow) DeepCopyInto(out *MetricSamplingWindow) {
+	*out = *in
+	in.Start.DeepCopyInto(&out.Start)
+	if in.Series != nil {
+		in, out := &in.Series, &out.Series
+		*out = make([]SampledSeries, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MetricSamplingWindow.
+func (in *MetricSamplingWindow) DeepCopy() *MetricSamplingWindow {
+	if in == nil {
+		return nil
+	}
+	out := new(MetricSamplingWindow)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MetricSet) DeepCopyInto(out *MetricSet) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MetricSet.
+func (in *MetricSet) DeepCopy() *MetricSet {
+	if in == nil {
+		return nil
+	}
+	out := new(MetricSet)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *MetricSet) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MetricSetList) DeepCopyInto(out *MetricSetList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]MetricSet, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MetricSetList.
+func (in *MetricSetList) DeepCopy() *MetricSetList {
+	if in == nil {
+		return nil
+	}
+	out := new(MetricSetList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *MetricSetList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MetricSetSpec) DeepCopyInto(out *MetricSetSpec) {
+	*out = *in
+	in.Template.DeepCopyInto(&out.Template)
+	if in.Targets != nil {
+		in, out := &in.Targets, &out.Targets
+		*out = make([]MetricSetTarget, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MetricSetSpec.
+func (in *MetricSetSpec) DeepCopy() *MetricSetSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(MetricSetSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MetricSetStatus) DeepCopyInto(out *MetricSetStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MetricSetStatus.
+func (in *MetricSetStatus) DeepCopy() *MetricSetStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(MetricSetStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MetricSetTarget) DeepCopyInto(out *MetricSetTarget) {
+	*out = *in
+	out.GroupVersionKind = in.GroupVersionKind
+	if in.Namespaces != nil {
+		in, out := &in.Namespaces, &out.Namespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MetricSetTarget.
+func (in *MetricSetTarget) DeepCopy() *MetricSetTarget {
+	if in == nil {
+		return nil
+	}
+	out := new(MetricSetTarget)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MetricSnapshot) DeepCopyInto(out *MetricSnapshot) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MetricSnapshot.
+func (in *MetricSnapshot) DeepCopy() *MetricSnapshot {
+	if in == nil {
+		return nil
+	}
+	out := new(MetricSnapshot)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *MetricSnapshot) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MetricSnapshotList) DeepCopyInto(out *MetricSnapshotList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]MetricSnapshot, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MetricSnapshotList.
+func (in *MetricSnapshotList) DeepCopy() *MetricSnapshotList {
+	if in == nil {
+		return nil
+	}
+	out := new(MetricSnapshotList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *MetricSnapshotList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MetricSnapshotSpec) DeepCopyInto(out *MetricSnapshotSpec) {
+	*out = *in
+	in.Timestamp.DeepCopyInto(&out.Timestamp)
+	if in.Series != nil {
+		in, out := &in.Series, &out.Series
+		*out = make([]RecordedSeriesValue, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MetricSnapshotSpec.
+func (in *MetricSnapshotSpec) DeepCopy() *MetricSnapshotSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(MetricSnapshotSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MetricSpec) DeepCopyInto(out *MetricSpec) {
+	*out = *in
+	in.Target.DeepCopyInto(&out.Target)
+	out.Interval = in.Interval
+	if in.RetryPolicy != nil {
+		in, out := &in.RetryPolicy, &out.RetryPolicy
+		*out = new(RetryPolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DataSinkRef != nil {
+		in, out := &in.DataSinkRef, &out.DataSinkRef
+		*out = new(DataSinkReference)
+		**out = **in
+	}
+	if in.ScopeAttributes != nil {
+		in, out := &in.ScopeAttributes, &out.ScopeAttributes
+		*out = make([]ScopeAttribute, len(*in))
+		copy(*out, *in)
+	}
+	if in.StaticDimensions != nil {
+		in, out := &in.StaticDimensions, &out.StaticDimensions
+		*out = make([]StaticDimension, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.RemoteClusterAccessRef != nil {
+		in, out := &in.RemoteClusterAccessRef, &out.RemoteClusterAccessRef
+		*out = new(RemoteClusterAccessRef)
+		**out = **in
+	}
+	if in.Projections != nil {
+		in, out := &in.Projections, &out.Projections
+		*out = make([]Projection, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Enrichments != nil {
+		in, out := &in.Enrichments, &out.Enrichments
+		*out = make([]Enrichment, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ValueFrom != nil {
+		in, out := &in.ValueFrom, &out.ValueFrom
+		*out = new(ValueFromProjection)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Targets != nil {
+		in, out := &in.Targets, &out.Targets
+		*out = make([]NamedTarget, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.HeartbeatInterval != nil {
+		in, out := &in.HeartbeatInterval, &out.HeartbeatInterval
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.Sampling != nil {
+		in, out := &in.Sampling, &out.Sampling
+		*out = new(Sampling)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Timeout != nil {
+		in, out := &in.Timeout, &out.Timeout
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.SnapshotRetention != nil {
+		in, out := &in.SnapshotRetention, &out.SnapshotRetention
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.MaxSnapshots != nil {
+		in, out := &in.MaxSnapshots, &out.MaxSnapshots
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Debug != nil {
+		in, out := &in.Debug, &out.Debug
+		*out = new(DebugOptions)
+		**out = **in
+	}
+	if in.Thresholds != nil {
+		in, out := &in.Thresholds, &out.Thresholds
+		*out = new(Thresholds)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MetricSpec.
+func (in *MetricSpec) DeepCopy() *MetricSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(MetricSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MetricStatus) DeepCopyInto(out *MetricStatus) {
+	*out = *in
+	in.Observation.DeepCopyInto(&out.Observation)
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Baseline != nil {
+		in, out := &in.Baseline, &out.Baseline
+		*out = new(MetricBaseline)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.LastExport != nil {
+		in, out := &in.LastExport, &out.LastExport
+		*out = new(MetricLastExport)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SamplingWindow != nil {
+		in, out := &in.SamplingWindow, &out.SamplingWindow
+		*out = new(MetricSamplingWindow)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.NextRunTime != nil {
+		in, out := &in.NextRunTime, &out.NextRunTime
+		*out = (*in).DeepCopy()
+	}
+	in.ExportStatus.DeepCopyInto(&out.ExportStatus)
+	if in.RecordedSeries != nil {
+		in, out := &in.RecordedSeries, &out.RecordedSeries
+		*out = new(RecordedSeries)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MetricStatus.
+func (in *MetricStatus) DeepCopy() *MetricStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(MetricStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MetricTarget) DeepCopyInto(out *MetricTarget) {
+	*out = *in
+	out.GroupVersionKind = in.GroupVersionKind
+	if in.Namespaces != nil {
+		in, out := &in.Namespaces, &out.Namespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.NamespaceSelector != nil {
+		in, out := &in.NamespaceSelector, &out.NamespaceSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MetricTarget.
+func (in *MetricTarget) DeepCopy() *MetricTarget {
+	if in == nil {
+		return nil
+	}
+	out := new(MetricTarget)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MetricTemplate) DeepCopyInto(out *MetricTemplate) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MetricTemplate.
+func (in *MetricTemplate) DeepCopy() *MetricTemplate {
+	if in == nil {
+		return nil
+	}
+	out := new(MetricTemplate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MetricsOperatorConfig) DeepCopyInto(out *MetricsOperatorConfig) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MetricsOperatorConfig.
+func (in *MetricsOperatorConfig) DeepCopy() *MetricsOperatorConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(MetricsOperatorConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *MetricsOperatorConfig) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MetricsOperatorConfigList) DeepCopyInto(out *MetricsOperatorConfigList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]MetricsOperatorConfig, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MetricsOperatorConfigList.
+func (in *MetricsOperatorConfigList) DeepCopy() *MetricsOperatorConfigList {
+	if in == nil {
+		return nil
+	}
+	out := new(MetricsOperatorConfigList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *MetricsOperatorConfigList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MetricsOperatorConfigSpec) DeepCopyInto(out *MetricsOperatorConfigSpec) {
+	*out = *in
+	if in.JitterPercent != nil {
+		in, out := &in.JitterPercent, &out.JitterPercent
+		*out = new(int32)
+		**out = **in
+	}
+	if in.ErrorRequeueBaseDelay != nil {
+		in, out := &in.ErrorRequeueBaseDelay, &out.ErrorRequeueBaseDelay
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.ErrorRequeueMaxDelay != nil {
+		in, out := &in.ErrorRequeueMaxDelay, &out.ErrorRequeueMaxDelay
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.CollectionTimeout != nil {
+		in, out := &in.CollectionTimeout, &out.CollectionTimeout
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.EventInterval != nil {
+		in, out := &in.EventInterval, &out.EventInterval
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.ManagedCacheTTL != nil {
+		in, out := &in.ManagedCacheTTL, &out.ManagedCacheTTL
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.DataSinkFailureThreshold != nil {
+		in, out := &in.DataSinkFailureThreshold, &out.DataSinkFailureThreshold
+		*out = new(int32)
+		**out = **in
+	}
+	if in.DataSinkOpenDuration != nil {
+		in, out := &in.DataSinkOpenDuration, &out.DataSinkOpenDuration
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.Variables != nil {
+		in, out := &in.Variables, &out.Variables
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MetricsOperatorConfigSpec.
+func (in *MetricsOperatorConfigSpec) DeepCopy() *MetricsOperatorConfigSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(MetricsOperatorConfigSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MetricsOperatorConfigStatus) DeepCopyInto(out *MetricsOperatorConfigStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MetricsOperatorConfigStatus.
+func (in *MetricsOperatorConfigStatus) DeepCopy() *MetricsOperatorConfigStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(MetricsOperatorConfigStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NamedTarget) DeepCopyInto(out *NamedTarget) {
+	*out = *in
+	in.Target.DeepCopyInto(&out.Target)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NamedTarget.
+func (in *NamedTarget) DeepCopy() *NamedTarget {
+	if in == nil {
+		return nil
+	}
+	out := new(NamedTarget)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OCMManagedClusters) DeepCopyInto(out *OCMManagedClusters) {
+	*out = *in
+	if in.Selector != nil {
+		in, out := &in.Selector, &out.Selector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OCMManagedClusters.
+func (in *OCMManagedClusters) DeepCopy() *OCMManagedClusters {
+	if in == nil {
+		return nil
+	}
+	out := new(OCMManagedClusters)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OIDCTokenExchange) DeepCopyInto(out *OIDCTokenExchange) {
+	*out = *in
+	if in.Scopes != nil {
+		in, out := &in.Scopes, &out.Scopes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OIDCTokenExchange.
+func (in *OIDCTokenExchange) DeepCopy() *OIDCTokenExchange {
+	if in == nil {
+		return nil
+	}
+	out := new(OIDCTokenExchange)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OTLPSettings) DeepCopyInto(out *OTLPSettings) {
+	*out = *in
+	if in.HistogramBoundaries != nil {
+		in, out := &in.HistogramBoundaries, &out.HistogramBoundaries
+		*out = make([]int64, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OTLPSettings.
+func (in *OTLPSettings) DeepCopy() *OTLPSettings {
+	if in == nil {
+		return nil
+	}
+	out := new(OTLPSettings)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Projection) DeepCopyInto(out *Projection) {
+	*out = *in
+	if in.Default != nil {
+		in, out := &in.Default, &out.Default
+		*out = new(ProjectionDefaultValue)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Projection.
+func (in *Projection) DeepCopy() *Projection {
+	if in == nil {
+		return nil
+	}
+	out := new(Projection)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProjectionDefaultValue) DeepCopyInto(out *ProjectionDefaultValue) {
 	*out = *in
 	if in.RawMessage != nil {
 		in, out := &in.RawMessage, &out.RawMessage
@@ -935,13 +2756,73 @@ func (in *ProjectionDefaultValue) DeepCopy() *ProjectionDefaultValue {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Proxy) DeepCopyInto(out *Proxy) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Proxy.
+func (in *Proxy) DeepCopy() *Proxy {
+	if in == nil {
+		return nil
+	}
+	out := new(Proxy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RecordedSeries) DeepCopyInto(out *RecordedSeries) {
+	*out = *in
+	in.Timestamp.DeepCopyInto(&out.Timestamp)
+	if in.Series != nil {
+		in, out := &in.Series, &out.Series
+		*out = make([]RecordedSeriesValue, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RecordedSeries.
+func (in *RecordedSeries) DeepCopy() *RecordedSeries {
+	if in == nil {
+		return nil
+	}
+	out := new(RecordedSeries)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RecordedSeriesValue) DeepCopyInto(out *RecordedSeriesValue) {
+	*out = *in
+	if in.Dimensions != nil {
+		in, out := &in.Dimensions, &out.Dimensions
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RecordedSeriesValue.
+func (in *RecordedSeriesValue) DeepCopy() *RecordedSeriesValue {
+	if in == nil {
+		return nil
+	}
+	out := new(RecordedSeriesValue)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RemoteClusterAccess) DeepCopyInto(out *RemoteClusterAccess) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RemoteClusterAccess.
@@ -1020,6 +2901,18 @@ func (in *RemoteClusterAccessSpec) DeepCopyInto(out *RemoteClusterAccessSpec) {
 	if in.ClusterAccessConfig != nil {
 		in, out := &in.ClusterAccessConfig, &out.ClusterAccessConfig
 		*out = new(ClusterAccessConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ClusterLabels != nil {
+		in, out := &in.ClusterLabels, &out.ClusterLabels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.RateLimit != nil {
+		in, out := &in.RateLimit, &out.RateLimit
+		*out = new(ClientRateLimit)
 		**out = **in
 	}
 }
@@ -1037,6 +2930,21 @@ func (in *RemoteClusterAccessSpec) DeepCopy() *RemoteClusterAccessSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RemoteClusterAccessStatus) DeepCopyInto(out *RemoteClusterAccessStatus) {
 	*out = *in
+	if in.CredentialsExpiry != nil {
+		in, out := &in.CredentialsExpiry, &out.CredentialsExpiry
+		*out = (*in).DeepCopy()
+	}
+	if in.LastCheckTime != nil {
+		in, out := &in.LastCheckTime, &out.LastCheckTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RemoteClusterAccessStatus.
@@ -1064,6 +2972,159 @@ func (in *RemoteClusterSecretRef) DeepCopy() *RemoteClusterSecretRef {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RetryPolicy) DeepCopyInto(out *RetryPolicy) {
+	*out = *in
+	if in.BaseDelay != nil {
+		in, out := &in.BaseDelay, &out.BaseDelay
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.MaxDelay != nil {
+		in, out := &in.MaxDelay, &out.MaxDelay
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RetryPolicy.
+func (in *RetryPolicy) DeepCopy() *RetryPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(RetryPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SampledSeries) DeepCopyInto(out *SampledSeries) {
+	*out = *in
+	if in.Dimensions != nil {
+		in, out := &in.Dimensions, &out.Dimensions
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SampledSeries.
+func (in *SampledSeries) DeepCopy() *SampledSeries {
+	if in == nil {
+		return nil
+	}
+	out := new(SampledSeries)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Sampling) DeepCopyInto(out *Sampling) {
+	*out = *in
+	out.SampleInterval = in.SampleInterval
+	if in.Aggregations != nil {
+		in, out := &in.Aggregations, &out.Aggregations
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Sampling.
+func (in *Sampling) DeepCopy() *Sampling {
+	if in == nil {
+		return nil
+	}
+	out := new(Sampling)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScopeAttribute) DeepCopyInto(out *ScopeAttribute) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScopeAttribute.
+func (in *ScopeAttribute) DeepCopy() *ScopeAttribute {
+	if in == nil {
+		return nil
+	}
+	out := new(ScopeAttribute)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StaticDimension) DeepCopyInto(out *StaticDimension) {
+	*out = *in
+	if in.ValueFrom != nil {
+		in, out := &in.ValueFrom, &out.ValueFrom
+		*out = new(StaticDimensionSource)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StaticDimension.
+func (in *StaticDimension) DeepCopy() *StaticDimension {
+	if in == nil {
+		return nil
+	}
+	out := new(StaticDimension)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StaticDimensionSource) DeepCopyInto(out *StaticDimensionSource) {
+	*out = *in
+	if in.ConfigMapKeyRef != nil {
+		in, out := &in.ConfigMapKeyRef, &out.ConfigMapKeyRef
+		*out = new(v1.ConfigMapKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SecretKeyRef != nil {
+		in, out := &in.SecretKeyRef, &out.SecretKeyRef
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StaticDimensionSource.
+func (in *StaticDimensionSource) DeepCopy() *StaticDimensionSource {
+	if in == nil {
+		return nil
+	}
+	out := new(StaticDimensionSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Thresholds) DeepCopyInto(out *Thresholds) {
+	*out = *in
+	if in.Above != nil {
+		in, out := &in.Above, &out.Above
+		*out = new(int64)
+		**out = **in
+	}
+	if in.Below != nil {
+		in, out := &in.Below, &out.Below
+		*out = new(int64)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Thresholds.
+func (in *Thresholds) DeepCopy() *Thresholds {
+	if in == nil {
+		return nil
+	}
+	out := new(Thresholds)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ValueFromProjection) DeepCopyInto(out *ValueFromProjection) {
 	*out = *in
@@ -1083,3 +3144,23 @@ func (in *ValueFromProjection) DeepCopy() *ValueFromProjection {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WebhookSettings) DeepCopyInto(out *WebhookSettings) {
+	*out = *in
+	if in.HeadersSecretRef != nil {
+		in, out := &in.HeadersSecretRef, &out.HeadersSecretRef
+		*out = new(v1.LocalObjectReference)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WebhookSettings.
+func (in *WebhookSettings) DeepCopy() *WebhookSettings {
+	if in == nil {
+		return nil
+	}
+	out := new(WebhookSettings)
+	in.DeepCopyInto(out)
+	return out
+}
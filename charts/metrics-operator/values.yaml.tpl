@@ -44,6 +44,21 @@ manager:
   args: []
   extraArgs: []
 
+  # Tuning of the controllers, empty values keep the defaults of the operator.
+  controllers:
+    # Number of workers per controller, e.g. "metric: 4" or "managedmetric: 4".
+    # Controllers: metric, managedmetric, federatedmetric, federatedmanagedmetric,
+    # compositemetric, metricset, clustermetricsstatus, federatedclusteraccess, datasink
+    maxConcurrentReconciles: {}
+    # Backoff and rate limit of the requeues of each controller.
+    rateLimiter:
+      baseDelay: ""
+      maxDelay: ""
+      qps: ""
+      burst: ""
+    # How long each controller waits for its caches to sync on start.
+    cacheSyncTimeout: ""
+
   env: []
   # Extra environment variables to add to the manager container.
   extraEnv: []
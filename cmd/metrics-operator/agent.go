@@ -0,0 +1,135 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/clientcmd"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/healthz"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	"sigs.k8s.io/controller-runtime/pkg/metrics/server"
+
+	"github.com/openmcp-project/metrics-operator/internal/controller"
+)
+
+// runAgent runs the operator as agent in a member cluster that accepts no connections from the hub.
+// The agent collects the FederatedMetrics of the hub whose FederatedClusterAccess selects its ClusterAgent,
+// exports them directly to their DataSinks and reports the results to the hub. It returns the exit code.
+func runAgent(args []string, out io.Writer) int {
+	flags := flag.NewFlagSet("agent", flag.ContinueOnError)
+	flags.SetOutput(out)
+	hubKubeconfig := flags.String("hub-kubeconfig", "",
+		"Path of the kubeconfig of the hub cluster the FederatedMetrics are read from and the results are reported to.")
+	agentNamespace := flags.String("agent-namespace", "",
+		"Namespace of the ClusterAgent on the hub, the namespace of the FederatedClusterAccesses in agents mode.")
+	agentName := flags.String("agent-name", "",
+		"Name of the ClusterAgent on the hub. Defaults to the cluster name.")
+	clusterName := flags.String("cluster-name", "",
+		"Name of the member cluster the agent runs in, the cluster dimension of the exported data points.")
+	agentLabels := flags.String("agent-labels", "",
+		"Labels of the ClusterAgent as key=value pairs separated by commas, matched by the selectors of the FederatedClusterAccesses.")
+	variables := flags.String("variables", "",
+		"Variables of the member cluster as NAME=value pairs separated by commas, replacing their placeholders $(NAME) in the spec of the FederatedMetrics. "+
+			"CLUSTER_NAME defaults to the cluster name.")
+	metricsAddr := flags.String("metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
+	probeAddr := flags.String("health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
+	opts := zap.Options{Development: true}
+	opts.BindFlags(flags)
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if *hubKubeconfig == "" || *agentNamespace == "" || *clusterName == "" {
+		_, _ = fmt.Fprintln(out, "--hub-kubeconfig, --agent-namespace and --cluster-name are required")
+		return 2
+	}
+	parsedLabels, err := labels.ConvertSelectorToLabelsMap(*agentLabels)
+	if err != nil {
+		_, _ = fmt.Fprintf(out, "invalid agent labels: %v\n", err)
+		return 2
+	}
+
+	parsedVariables, err := controller.ParseVariables(*variables)
+	if err != nil {
+		_, _ = fmt.Fprintf(out, "invalid variables: %v\n", err)
+		return 2
+	}
+	if _, ok := parsedVariables["CLUSTER_NAME"]; !ok {
+		parsedVariables["CLUSTER_NAME"] = *clusterName
+	}
+	controller.Variables = parsedVariables
+
+	logger := zap.New(zap.UseFlagOptions(&opts))
+	ctrl.SetLogger(logger)
+
+	hubConfig, err := clientcmd.BuildConfigFromFlags("", *hubKubeconfig)
+	if err != nil {
+		setupLog.Error(err, "unable to load hub kubeconfig")
+		return 1
+	}
+	// the member cluster the agent runs in is queried with the in-cluster config
+	localConfig := ctrl.GetConfigOrDie()
+
+	agent := types.NamespacedName{Namespace: *agentNamespace, Name: *agentName}
+	if agent.Name == "" {
+		agent.Name = *clusterName
+	}
+
+	hubClient, err := client.New(hubConfig, client.Options{Scheme: scheme})
+	if err != nil {
+		setupLog.Error(err, "unable to create hub client")
+		return 1
+	}
+	if err := controller.RegisterClusterAgent(context.Background(), hubClient, agent, *clusterName, parsedLabels); err != nil {
+		setupLog.Error(err, "unable to register cluster agent")
+		return 1
+	}
+
+	mgr, err := ctrl.NewManager(hubConfig, ctrl.Options{
+		Scheme: scheme,
+		// Secrets and ConfigMaps of the hub are read one by one, so the agent needs no permission to list them
+		Client:                 client.Options{Cache: &client.CacheOptions{DisableFor: []client.Object{&corev1.Secret{}, &corev1.ConfigMap{}}}},
+		Metrics:                server.Options{BindAddress: *metricsAddr},
+		HealthProbeBindAddress: *probeAddr,
+		Logger:                 logger,
+	})
+	if err != nil {
+		setupLog.Error(err, "unable to start manager")
+		return 1
+	}
+	if err := controller.NewFederatedMetricAgentReconciler(mgr, localConfig, agent).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create reconciler", "controller", "federated metric agent")
+		return 1
+	}
+	heartbeat := &controller.ClusterAgentHeartbeat{
+		Client:   hubClient,
+		Agent:    agent,
+		Interval: controller.ClusterAgentHeartbeatInterval,
+		Log:      logger.WithName("heartbeat"),
+	}
+	if err := mgr.Add(heartbeat); err != nil {
+		setupLog.Error(err, "unable to set up heartbeat")
+		return 1
+	}
+	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
+		setupLog.Error(err, "unable to set up health check")
+		return 1
+	}
+	if err := mgr.AddReadyzCheck("readyz", healthz.Ping); err != nil {
+		setupLog.Error(err, "unable to set up ready check")
+		return 1
+	}
+
+	setupLog.Info("starting agent", "agent", agent.String(), "cluster", *clusterName)
+	if err := mgr.Start(ctrl.SetupSignalHandler()); err != nil {
+		setupLog.Error(err, "problem running agent")
+		return 1
+	}
+	return 0
+}
@@ -20,14 +20,19 @@ import (
 	"context"
 	"embed"
 	"flag"
+	"fmt"
 	"os"
+	"strings"
+	"time"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
+	"go.uber.org/zap/zapcore"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -41,7 +46,12 @@ import (
 	"github.com/openmcp-project/controller-utils/pkg/init/crds"
 	"github.com/openmcp-project/controller-utils/pkg/init/webhooks"
 
+	"github.com/openmcp-project/metrics-operator/internal/clientoptl"
 	"github.com/openmcp-project/metrics-operator/internal/controller"
+	"github.com/openmcp-project/metrics-operator/internal/crdinstall"
+	"github.com/openmcp-project/metrics-operator/internal/diagnostics"
+	"github.com/openmcp-project/metrics-operator/internal/notification"
+	"github.com/openmcp-project/metrics-operator/internal/orchestrator"
 
 	metricsv1alpha1 "github.com/openmcp-project/metrics-operator/api/v1alpha1"
 	// +kubebuilder:scaffold:imports
@@ -68,7 +78,33 @@ func init() {
 	// +kubebuilder:scaffold:scheme
 }
 
-func runInit(setupClient client.Client) {
+// webhookTypes are the types webhooks are installed for by init and rendered by render-crds
+func webhookTypes() []webhooks.APITypes {
+	return []webhooks.APITypes{
+		{
+			Obj:       &metricsv1alpha1.Metric{},
+			Validator: false,
+			Defaulter: false,
+		},
+		{
+			Obj:       &metricsv1alpha1.ManagedMetric{},
+			Validator: false,
+			Defaulter: false,
+		},
+		{
+			Obj:       &metricsv1alpha1.RemoteClusterAccess{},
+			Validator: false,
+			Defaulter: false,
+		},
+		{
+			Obj:       &metricsv1alpha1.FederatedMetric{},
+			Validator: false,
+			Defaulter: false,
+		},
+	}
+}
+
+func runInit(setupClient client.Client, crdOptions crdinstall.Options) {
 	initContext := context.Background()
 
 	if webhooksFlags.Install {
@@ -78,35 +114,12 @@ func runInit(setupClient client.Client) {
 			os.Exit(1)
 		}
 
-		webhookTypes := []webhooks.APITypes{
-			{
-				Obj:       &metricsv1alpha1.Metric{},
-				Validator: false,
-				Defaulter: false,
-			},
-			{
-				Obj:       &metricsv1alpha1.ManagedMetric{},
-				Validator: false,
-				Defaulter: false,
-			},
-			{
-				Obj:       &metricsv1alpha1.RemoteClusterAccess{},
-				Validator: false,
-				Defaulter: false,
-			},
-			{
-				Obj:       &metricsv1alpha1.FederatedMetric{},
-				Validator: false,
-				Defaulter: false,
-			},
-		}
-
 		// Install webhooks
 		err := webhooks.Install(
 			initContext,
 			setupClient,
 			scheme,
-			webhookTypes,
+			webhookTypes(),
 			webhooksFlags.InstallOptions...,
 		)
 		if err != nil {
@@ -116,8 +129,23 @@ func runInit(setupClient client.Client) {
 	}
 
 	if crdFlags.Install {
-		// Install CRDs
-		if err := crds.Install(initContext, setupClient, crdFiles, crdFlags.InstallOptions...); err != nil {
+		// Install CRDs, upgrades that would remove versions or fields of the installed CRDs are refused
+		results, err := crdinstall.Install(initContext, setupClient, crdFiles, crdOptions)
+		summary := map[string]int{}
+		for _, result := range results {
+			summary[result.Action]++
+			switch {
+			case result.Action == crdinstall.ActionRefused:
+				setupLog.Info("refusing incompatible upgrade of Custom Resource Definition", "name", result.Name, "changes", result.Changes)
+			case len(result.Changes) > 0:
+				setupLog.Info("applied incompatible upgrade of Custom Resource Definition", "name", result.Name, "changes", result.Changes)
+			default:
+				setupLog.Info("Custom Resource Definition "+strings.ToLower(result.Action), "name", result.Name)
+			}
+		}
+		setupLog.Info("installed Custom Resource Definitions", "created", summary[crdinstall.ActionCreated],
+			"updated", summary[crdinstall.ActionUpdated], "unchanged", summary[crdinstall.ActionUnchanged], "refused", summary[crdinstall.ActionRefused])
+		if err != nil {
 			setupLog.Error(err, "unable to install Custom Resource Definitions")
 			os.Exit(1)
 		}
@@ -125,15 +153,106 @@ func runInit(setupClient client.Client) {
 }
 
 func main() {
+	// validate, render-crds, render-rbac, agent and replay have their own flags and do not run the operator
+	if len(os.Args) > 1 && os.Args[1] == "validate" {
+		os.Exit(runValidate(os.Args[2:], os.Stdout))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "render-crds" {
+		os.Exit(runRenderCRDs(os.Args[2:], os.Stdout))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "render-rbac" {
+		os.Exit(runRenderRBAC(os.Args[2:], os.Stdout))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "agent" {
+		os.Exit(runAgent(os.Args[2:], os.Stderr))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "replay" {
+		os.Exit(runReplay(os.Args[2:], os.Stdout))
+	}
+
 	var metricsAddr string
 	var enableLeaderElection bool
 	var probeAddr string
+	var pprofAddr string
+	var managedCacheTTL time.Duration
+	var collectionTimeout time.Duration
+	var dataSinkProbeInterval time.Duration
+	var dataSinkFailureThreshold int
+	var jitterPercent int
+	var startupSpread time.Duration
+	var errorRequeueBaseDelay time.Duration
+	var errorRequeueMaxDelay time.Duration
+	var eventInterval time.Duration
+	var dataSinkOpenDuration time.Duration
+	var rateLimiterBaseDelay time.Duration
+	var rateLimiterMaxDelay time.Duration
+	var rateLimiterQPS float64
+	var rateLimiterBurst int
+	var clientQPS float64
+	var clientBurst int
+	var cacheSyncTimeout time.Duration
+	var notificationSink string
+	var allowIncompatibleCRDUpgrades bool
+	var controlPlaneKind string
+	var variables string
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
+	flag.StringVar(&pprofAddr, "pprof-bind-address", "",
+		"The address the pprof and diagnostics endpoints bind to. Leave empty to disable them.")
 
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
+	flag.DurationVar(&managedCacheTTL, "managed-cache-ttl", orchestrator.DefaultManagedCacheTTL,
+		"How long listed managed resources are shared between ManagedMetrics before they are listed again. "+
+			"Set to 0 to disable caching.")
+	flag.DurationVar(&collectionTimeout, "collection-timeout", orchestrator.DefaultPhaseTimeout,
+		"Timeout of each collection phase (list, projection, export) of Metrics that do not set spec.timeout.")
+	flag.IntVar(&jitterPercent, "jitter-percent", controller.DefaultJitterPercent,
+		"Delays the first export of a metric with an interval by up to this percentage of the interval, "+
+			"so metrics with identical intervals are not exported at the same moment. Set to 0 to disable jitter.")
+	flag.DurationVar(&startupSpread, "startup-spread", controller.DefaultStartupSpread,
+		"Time over which the exports that are due when the operator starts are spread. Set to 0 to export them right away.")
+	flag.DurationVar(&errorRequeueBaseDelay, "error-requeue-base-delay", controller.RequeueAfterError,
+		"Delay of the first retry of a failed collection of a metric, doubled with every further failure, unless the metric sets spec.retryPolicy.")
+	flag.DurationVar(&errorRequeueMaxDelay, "error-requeue-max-delay", controller.DefaultErrorRequeueMaxDelay,
+		"Upper bound of the delay between retries of failed collections of a metric, unless the metric sets spec.retryPolicy.")
+	flag.DurationVar(&eventInterval, "event-interval", controller.DefaultEventInterval,
+		"Interval in which an unchanged event of an object is emitted again, changed events are emitted right away. Set to 0 to emit every event.")
+	flag.DurationVar(&dataSinkProbeInterval, "datasink-probe-interval", controller.DefaultDataSinkProbeInterval,
+		"Interval in which the connectivity of DataSinks is checked.")
+	flag.IntVar(&dataSinkFailureThreshold, "datasink-failure-threshold", clientoptl.DefaultFailureThreshold,
+		"Number of consecutive failed exports after which exports to a DataSink are skipped. Set to 0 to disable the circuit breaker.")
+	flag.DurationVar(&dataSinkOpenDuration, "datasink-open-duration", clientoptl.DefaultOpenDuration,
+		"How long exports to a failing DataSink are skipped before a trial export is let through.")
+	maxConcurrentReconciles := bindMaxConcurrentReconcilesFlags(flag.CommandLine)
+	flag.DurationVar(&rateLimiterBaseDelay, "rate-limiter-base-delay", controller.DefaultRateLimiterBaseDelay,
+		"Delay of the first retry of a failed reconcile, doubled with every further failure of the same object.")
+	flag.DurationVar(&rateLimiterMaxDelay, "rate-limiter-max-delay", controller.DefaultRateLimiterMaxDelay,
+		"Upper bound of the delay between retries of a failed reconcile.")
+	flag.Float64Var(&rateLimiterQPS, "rate-limiter-qps", controller.DefaultRateLimiterQPS,
+		"Number of requeues per second of each controller.")
+	flag.IntVar(&rateLimiterBurst, "rate-limiter-burst", controller.DefaultRateLimiterBurst,
+		"Number of requeues of each controller that may exceed the rate limit at once.")
+	flag.Float64Var(&clientQPS, "cluster-client-qps", orchestrator.DefaultClientQPS,
+		"Number of requests per second sent to the API server of each queried cluster, unless its RemoteClusterAccess sets a rate limit.")
+	flag.IntVar(&clientBurst, "cluster-client-burst", orchestrator.DefaultClientBurst,
+		"Number of requests to the API server of each queried cluster that may exceed the rate limit at once.")
+	flag.DurationVar(&cacheSyncTimeout, "cache-sync-timeout", 0,
+		"How long each controller waits for its caches to sync on start. Set to 0 to use the controller-runtime default.")
+	flag.StringVar(&notificationSink, "notification-sink", "",
+		"URL CloudEvents about failing, stale and threshold-crossing metrics are posted to. Leave empty to send no notifications.")
+
+	flag.StringVar(&controlPlaneKind, "control-plane-kind", "",
+		"Kind of the control planes ControlPlaneMetricSets generate metrics for, as Kind.version.group, "+
+			"e.g. ManagedControlPlane.v1alpha1.core.openmcp.cloud. Leave empty to disable ControlPlaneMetricSets.")
+
+	flag.StringVar(&variables, "variables", "",
+		"Variables of the environment as NAME=value pairs separated by commas, e.g. CLUSTER_NAME=prod-eu. "+
+			"Their placeholders $(NAME) are replaced in the spec of the metrics.")
+
+	flag.BoolVar(&allowIncompatibleCRDUpgrades, "allow-incompatible-crd-upgrades", false,
+		"Let init apply CRDs that remove versions or fields of the installed CRDs, e.g. of a newer operator version, instead of refusing them.")
 
 	opts := zap.Options{
 		Development: true,
@@ -147,9 +266,51 @@ func main() {
 		return
 	}
 
+	// the level of the flags is changed at runtime by the MetricsOperatorConfig
+	logLevel := zapcore.InfoLevel
+	if opts.Development {
+		logLevel = zapcore.DebugLevel
+	}
+	if level, ok := opts.Level.(interface{ Level() zapcore.Level }); ok {
+		logLevel = level.Level()
+	}
+	controller.LogLevel.SetLevel(logLevel)
+	opts.Level = controller.LogLevel
 	logger := zap.New(zap.UseFlagOptions(&opts))
 	ctrl.SetLogger(logger)
 
+	orchestrator.ClientRateLimit = orchestrator.RateLimit{QPS: float32(clientQPS), Burst: clientBurst}
+	parsedVariables, err := controller.ParseVariables(variables)
+	if err != nil {
+		setupLog.Error(err, "unable to parse the variables")
+		os.Exit(1)
+	}
+	// the settings of the flags are overridden at runtime by the MetricsOperatorConfig
+	settings := controller.OperatorSettings{
+		Scheduling:               controller.SchedulingOptions{JitterPercent: jitterPercent, StartupSpread: startupSpread},
+		ErrorRequeue:             controller.ErrorRequeueOptions{BaseDelay: errorRequeueBaseDelay, MaxDelay: errorRequeueMaxDelay},
+		Variables:                parsedVariables,
+		DefaultDataSink:          controller.DefaultDataSinkName,
+		LogLevel:                 logLevel,
+		CollectionTimeout:        collectionTimeout,
+		EventInterval:            eventInterval,
+		ManagedCacheTTL:          managedCacheTTL,
+		DataSinkFailureThreshold: dataSinkFailureThreshold,
+		DataSinkOpenDuration:     dataSinkOpenDuration,
+	}
+	controller.ApplySettings(settings)
+	controller.Controllers = controller.ControllerOptions{
+		MaxConcurrentReconciles: make(map[string]int, len(maxConcurrentReconciles)),
+		RateLimiterBaseDelay:    rateLimiterBaseDelay,
+		RateLimiterMaxDelay:     rateLimiterMaxDelay,
+		RateLimiterQPS:          rateLimiterQPS,
+		RateLimiterBurst:        rateLimiterBurst,
+		CacheSyncTimeout:        cacheSyncTimeout,
+	}
+	for name, workers := range maxConcurrentReconciles {
+		controller.Controllers.MaxConcurrentReconciles[name] = *workers
+	}
+
 	config := ctrl.GetConfigOrDie()
 	setupClient, err := client.New(config, client.Options{Scheme: scheme})
 	if err != nil {
@@ -158,7 +319,7 @@ func main() {
 	}
 
 	if os.Args[1] == "init" {
-		runInit(setupClient)
+		runInit(setupClient, crdinstall.Options{AllowIncompatible: allowIncompatibleCRDUpgrades})
 		return
 	}
 
@@ -194,8 +355,37 @@ func main() {
 
 	setupFederatedManagedMetricController(mgr)
 
+	setupCompositeMetricController(mgr)
+
+	setupMetricSetController(mgr)
+
+	setupClusterMetricsStatusController(mgr)
+
+	setupFederatedClusterAccessController(mgr)
+
+	setupRemoteClusterAccessController(mgr)
+
+	setupDataSinkController(mgr, dataSinkProbeInterval)
+
+	setupMetricsOperatorConfigController(mgr, settings)
+
+	if notificationSink != "" {
+		setupMetricNotificationController(mgr, notificationSink)
+	}
+
+	if controlPlaneKind != "" {
+		setupControlPlaneMetricSetController(mgr, controlPlaneKind)
+	}
+
 	// +kubebuilder:scaffold:builder
 
+	if pprofAddr != "" {
+		if err := mgr.Add(diagnostics.NewServer(pprofAddr, mgr.GetClient())); err != nil {
+			setupLog.Error(err, "unable to set up diagnostics server")
+			os.Exit(1)
+		}
+	}
+
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
 		setupLog.Error(err, "unable to set up health check")
 		os.Exit(1)
@@ -212,6 +402,29 @@ func main() {
 	}
 }
 
+// bindMaxConcurrentReconcilesFlags binds a flag for the number of workers of each controller, e.g. --metric-max-concurrent-reconciles
+func bindMaxConcurrentReconcilesFlags(fs *flag.FlagSet) map[string]*int {
+	kinds := map[string]string{
+		controller.MetricControllerName:                 "Metrics",
+		controller.ManagedMetricControllerName:          "ManagedMetrics",
+		controller.FederatedMetricControllerName:        "FederatedMetrics",
+		controller.FederatedManagedMetricControllerName: "FederatedManagedMetrics",
+		controller.CompositeMetricControllerName:        "CompositeMetrics",
+		controller.MetricSetControllerName:              "MetricSets",
+		controller.ClusterMetricsStatusControllerName:   "ClusterMetricsStatuses",
+		controller.FederatedClusterAccessControllerName: "FederatedClusterAccesses",
+		controller.RemoteClusterAccessControllerName:    "RemoteClusterAccesses",
+		controller.DataSinkControllerName:               "DataSinks",
+		controller.ControlPlaneMetricSetControllerName:  "ControlPlaneMetricSets",
+	}
+	workers := make(map[string]*int, len(kinds))
+	for name, kind := range kinds {
+		workers[name] = fs.Int(name+"-max-concurrent-reconciles", controller.DefaultMaxConcurrentReconciles,
+			"Number of "+kind+" reconciled in parallel.")
+	}
+	return workers
+}
+
 func setupFederatedMetricController(mgr ctrl.Manager) {
 	if err := (controller.NewFederatedMetricReconciler(mgr)).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create reconciler", "controller", "federated metric")
@@ -239,3 +452,73 @@ func setupManagedMetricController(mgr ctrl.Manager) {
 		os.Exit(1)
 	}
 }
+
+func setupCompositeMetricController(mgr ctrl.Manager) {
+	if err := controller.NewCompositeMetricReconciler(mgr).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create reconciler", "controller", "composite metric")
+		os.Exit(1)
+	}
+}
+
+func setupMetricSetController(mgr ctrl.Manager) {
+	if err := controller.NewMetricSetReconciler(mgr).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create reconciler", "controller", "metric set")
+		os.Exit(1)
+	}
+}
+
+func setupClusterMetricsStatusController(mgr ctrl.Manager) {
+	if err := controller.NewClusterMetricsStatusReconciler(mgr).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create reconciler", "controller", "cluster metrics status")
+		os.Exit(1)
+	}
+}
+
+func setupFederatedClusterAccessController(mgr ctrl.Manager) {
+	if err := controller.NewFederatedClusterAccessReconciler(mgr).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create reconciler", "controller", "federated cluster access")
+		os.Exit(1)
+	}
+}
+
+func setupRemoteClusterAccessController(mgr ctrl.Manager) {
+	if err := controller.NewRemoteClusterAccessReconciler(mgr).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create reconciler", "controller", "remote cluster access")
+		os.Exit(1)
+	}
+}
+
+func setupDataSinkController(mgr ctrl.Manager, probeInterval time.Duration) {
+	r := controller.NewDataSinkReconciler(mgr)
+	r.ProbeInterval = probeInterval
+	if err := r.SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create reconciler", "controller", "data sink")
+		os.Exit(1)
+	}
+}
+
+func setupMetricsOperatorConfigController(mgr ctrl.Manager, defaults controller.OperatorSettings) {
+	if err := controller.NewMetricsOperatorConfigReconciler(mgr, defaults).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create reconciler", "controller", "metrics operator config")
+		os.Exit(1)
+	}
+}
+
+func setupMetricNotificationController(mgr ctrl.Manager, sink string) {
+	if err := controller.NewMetricNotificationReconciler(mgr, notification.NewHTTPSender(sink)).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create reconciler", "controller", "metric notification")
+		os.Exit(1)
+	}
+}
+
+func setupControlPlaneMetricSetController(mgr ctrl.Manager, kind string) {
+	gvk, _ := schema.ParseKindArg(kind)
+	if gvk == nil {
+		setupLog.Error(fmt.Errorf("kind '%s' is not of the form Kind.version.group", kind), "invalid control plane kind")
+		os.Exit(1)
+	}
+	if err := controller.NewControlPlaneMetricSetReconciler(mgr, *gvk).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create reconciler", "controller", "control plane metric set")
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	metricsv1alpha1 "github.com/openmcp-project/metrics-operator/api/v1alpha1"
+	"github.com/openmcp-project/metrics-operator/pkg/rbac"
+)
+
+// runRenderRBAC writes a ClusterRole granting access to exactly the resources targeted by the Metrics and MetricSets
+// of the given files and directories, or of the cluster, and optionally applies it. It returns the exit code.
+func runRenderRBAC(args []string, out io.Writer) int {
+	flags := flag.NewFlagSet("render-rbac", flag.ContinueOnError)
+	flags.SetOutput(out)
+	flags.Usage = func() {
+		_, _ = fmt.Fprintln(out, "Usage: metrics-operator render-rbac [flags] [file or directory]...")
+		flags.PrintDefaults()
+	}
+	name := flags.String("name", "metrics-operator-targets", "Name of the ClusterRole.")
+	cluster := flags.Bool("cluster", false,
+		"Read the Metrics and MetricSets of the cluster of the kubeconfig, and look up the resources of their kinds there. "+
+			"Without it, the resources are derived from the kinds of the manifests.")
+	kubeconfig := flags.String("kubeconfig", "",
+		"Path of the kubeconfig used with --cluster and --apply. Defaults to $KUBECONFIG, the in-cluster config or ~/.kube/config.")
+	apply := flags.Bool("apply", false, "Create or update the ClusterRole in the cluster instead of writing it.")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() == 0 && !*cluster {
+		flags.Usage()
+		return 2
+	}
+
+	ctx := context.Background()
+	var cli client.Client
+	resolve := rbac.GuessResolver
+	if *cluster || *apply {
+		restConfig, err := commandRestConfig(*kubeconfig)
+		if err == nil {
+			cli, err = client.New(restConfig, client.Options{Scheme: scheme})
+		}
+		if err != nil {
+			_, _ = fmt.Fprintf(out, "failed to connect to the cluster: %v\n", err)
+			return 2
+		}
+		if *cluster {
+			disco, err := discovery.NewDiscoveryClientForConfig(restConfig)
+			if err != nil {
+				_, _ = fmt.Fprintf(out, "failed to connect to the cluster: %v\n", err)
+				return 2
+			}
+			resolve = rbac.DiscoveryResolver(disco)
+		}
+	}
+
+	var kinds []schema.GroupVersionKind
+	files, err := manifestFiles(flags.Args())
+	if err != nil {
+		_, _ = fmt.Fprintln(out, err)
+		return 2
+	}
+	for _, file := range files {
+		fileKinds, err := manifestKinds(file)
+		if err != nil {
+			_, _ = fmt.Fprintf(out, "%s: %v\n", file, err)
+			return 1
+		}
+		kinds = append(kinds, fileKinds...)
+	}
+	if *cluster {
+		clusterKinds, err := clusterMetricKinds(ctx, cli)
+		if err != nil {
+			_, _ = fmt.Fprintf(out, "failed to list metrics: %v\n", err)
+			return 1
+		}
+		kinds = append(kinds, clusterKinds...)
+	}
+
+	rules, err := rbac.Rules(kinds, resolve)
+	if err != nil {
+		_, _ = fmt.Fprintf(out, "failed to derive the rules: %v\n", err)
+		return 1
+	}
+	role := rbac.ClusterRole(*name, rules)
+
+	if *apply {
+		action, err := applyClusterRole(ctx, cli, role)
+		if err != nil {
+			_, _ = fmt.Fprintf(out, "failed to apply ClusterRole %s: %v\n", role.Name, err)
+			return 1
+		}
+		_, _ = fmt.Fprintf(out, "ClusterRole %s %s with %d rules\n", role.Name, action, len(role.Rules))
+		return 0
+	}
+	data, err := yaml.Marshal(role)
+	if err != nil {
+		_, _ = fmt.Fprintf(out, "failed to render ClusterRole: %v\n", err)
+		return 1
+	}
+	_, _ = fmt.Fprintf(out, "---\n%s", data)
+	return 0
+}
+
+func manifestKinds(file string) ([]schema.GroupVersionKind, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+	return rbac.ManifestKinds(f)
+}
+
+// clusterMetricKinds returns the kinds listed by the Metrics and MetricSets of the cluster
+func clusterMetricKinds(ctx context.Context, cli client.Client) ([]schema.GroupVersionKind, error) {
+	var kinds []schema.GroupVersionKind
+	metrics := &metricsv1alpha1.MetricList{}
+	if err := cli.List(ctx, metrics); err != nil {
+		return nil, err
+	}
+	for i := range metrics.Items {
+		kinds = append(kinds, rbac.MetricKinds(&metrics.Items[i].Spec)...)
+	}
+	sets := &metricsv1alpha1.MetricSetList{}
+	if err := cli.List(ctx, sets); err != nil {
+		return nil, err
+	}
+	for i := range sets.Items {
+		kinds = append(kinds, rbac.MetricSetKinds(&sets.Items[i])...)
+	}
+	return kinds, nil
+}
+
+// applyClusterRole creates the ClusterRole or replaces the rules of the existing one
+func applyClusterRole(ctx context.Context, cli client.Client, role *rbacv1.ClusterRole) (string, error) {
+	existing := &rbacv1.ClusterRole{}
+	err := cli.Get(ctx, client.ObjectKey{Name: role.Name}, existing)
+	if apierrors.IsNotFound(err) {
+		return "created", cli.Create(ctx, role)
+	}
+	if err != nil {
+		return "", err
+	}
+	existing.Rules = role.Rules
+	if existing.Labels == nil {
+		existing.Labels = map[string]string{}
+	}
+	for key, value := range role.Labels {
+		existing.Labels[key] = value
+	}
+	return "updated", cli.Update(ctx, existing)
+}
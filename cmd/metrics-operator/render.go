@@ -0,0 +1,124 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/openmcp-project/controller-utils/pkg/init/crds"
+	"github.com/openmcp-project/controller-utils/pkg/init/webhooks"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/yaml"
+)
+
+// runRenderCRDs writes the CRDs and webhook configurations installed by init as manifests, to stdout or to a directory,
+// so they can be applied by a GitOps tool instead of the operator. It returns the exit code.
+func runRenderCRDs(args []string, out io.Writer) int {
+	flags := flag.NewFlagSet("render-crds", flag.ContinueOnError)
+	flags.SetOutput(out)
+	outputDir := flags.String("output-dir", "",
+		"Directory the manifests are written to, one file per object. Without it, they are written to stdout.")
+	caFile := flags.String("ca-file", "",
+		"PEM encoded CA of the webhook server, added to the webhook configurations and CRD conversion webhooks. Without it, they have no CA.")
+	renderWebhooks := flags.Bool("webhooks", true, "Render the webhook configurations, too.")
+	crdOptions := crds.BindFlags(flags)
+	webhookOptions := webhooks.BindFlags(flags)
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	// the CA is left out unless given, the options of the flags are applied afterwards and take precedence
+	crdInstallOptions := append(crdOptions.InstallOptions[:0:0], crds.WithoutCA)
+	webhookInstallOptions := []webhooks.InstallOption{webhooks.WithoutCA}
+	if *caFile != "" {
+		ca, err := os.ReadFile(*caFile)
+		if err != nil {
+			_, _ = fmt.Fprintf(out, "failed to read CA: %v\n", err)
+			return 2
+		}
+		crdInstallOptions[0] = crds.WithCustomCA(ca)
+		webhookInstallOptions[0] = webhooks.WithCustomCA(ca)
+	}
+
+	// init installs the objects with a client, rendering installs them into an in-memory client and writes them out
+	ctx := context.Background()
+	cli := fake.NewClientBuilder().WithScheme(scheme).Build()
+	if err := crds.Install(ctx, cli, crdFiles, append(crdInstallOptions, crdOptions.InstallOptions...)...); err != nil {
+		_, _ = fmt.Fprintf(out, "failed to render CRDs: %v\n", err)
+		return 1
+	}
+	lists := []client.ObjectList{&apiextensionsv1.CustomResourceDefinitionList{}}
+	if *renderWebhooks {
+		if err := webhooks.Install(ctx, cli, scheme, webhookTypes(), append(webhookInstallOptions, webhookOptions.InstallOptions...)...); err != nil {
+			_, _ = fmt.Fprintf(out, "failed to render webhooks: %v\n", err)
+			return 1
+		}
+		lists = append(lists,
+			&admissionregistrationv1.ValidatingWebhookConfigurationList{},
+			&admissionregistrationv1.MutatingWebhookConfigurationList{},
+		)
+	}
+
+	for _, list := range lists {
+		if err := renderList(ctx, cli, list, out, *outputDir); err != nil {
+			_, _ = fmt.Fprintf(out, "failed to render manifests: %v\n", err)
+			return 1
+		}
+	}
+	return 0
+}
+
+// renderList writes the objects of the list, without their status and the fields set by the API server
+func renderList(ctx context.Context, cli client.Client, list client.ObjectList, out io.Writer, outputDir string) error {
+	if err := cli.List(ctx, list); err != nil {
+		return err
+	}
+	objects, err := meta.ExtractList(list)
+	if err != nil {
+		return err
+	}
+	for _, o := range objects {
+		obj := o.(client.Object)
+		gvk, err := apiutil.GVKForObject(obj, scheme)
+		if err != nil {
+			return err
+		}
+		obj.GetObjectKind().SetGroupVersionKind(gvk)
+
+		content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+		if err != nil {
+			return err
+		}
+		delete(content, "status")
+		unstructured.RemoveNestedField(content, "metadata", "resourceVersion")
+		unstructured.RemoveNestedField(content, "metadata", "creationTimestamp")
+		data, err := yaml.Marshal(content)
+		if err != nil {
+			return err
+		}
+
+		if outputDir == "" {
+			if _, err := fmt.Fprintf(out, "---\n%s", data); err != nil {
+				return err
+			}
+			continue
+		}
+		file := filepath.Join(outputDir, strings.ToLower(gvk.Kind)+"_"+obj.GetName()+".yaml")
+		if err := os.WriteFile(file, data, 0o644); err != nil {
+			return err
+		}
+		_, _ = fmt.Fprintln(out, file)
+	}
+	return nil
+}
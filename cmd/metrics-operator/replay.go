@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/openmcp-project/metrics-operator/pkg/replay"
+)
+
+// runReplay requests the immediate export of the selected metrics of the cluster, bypassing their intervals
+// and schedules, e.g. after an outage of a data sink. It returns the exit code.
+func runReplay(args []string, out io.Writer) int {
+	flags := flag.NewFlagSet("replay", flag.ContinueOnError)
+	flags.SetOutput(out)
+	flags.Usage = func() {
+		_, _ = fmt.Fprintln(out, "Usage: metrics-operator replay [flags]")
+		flags.PrintDefaults()
+	}
+	since := flags.Duration("since", 0,
+		"Replay only the metrics collected within this duration, e.g. 2h for an outage of two hours. Replays all metrics if 0.")
+	kinds := flags.String("kinds", "",
+		"Kinds of the metrics to replay separated by commas, one of "+strings.Join(replay.Kinds, ", ")+". Defaults to all.")
+	namespace := flags.String("namespace", "", "Namespace of the metrics to replay. Defaults to all namespaces.")
+	selector := flags.String("selector", "", "Label selector of the metrics to replay.")
+	dryRun := flags.Bool("dry-run", false, "List the metrics that would be replayed without replaying them.")
+	kubeconfig := flags.String("kubeconfig", "",
+		"Path of the kubeconfig. Defaults to $KUBECONFIG, the in-cluster config or ~/.kube/config.")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() > 0 {
+		flags.Usage()
+		return 2
+	}
+
+	opts := replay.Options{Namespace: *namespace, Since: *since, DryRun: *dryRun}
+	if *kinds != "" {
+		opts.Kinds = strings.Split(*kinds, ",")
+	}
+	if *selector != "" {
+		parsed, err := labels.Parse(*selector)
+		if err != nil {
+			_, _ = fmt.Fprintf(out, "invalid selector: %v\n", err)
+			return 2
+		}
+		opts.Selector = parsed
+	}
+
+	restConfig, err := commandRestConfig(*kubeconfig)
+	var cli client.Client
+	if err == nil {
+		cli, err = client.New(restConfig, client.Options{Scheme: scheme})
+	}
+	if err != nil {
+		_, _ = fmt.Fprintf(out, "failed to connect to the cluster: %v\n", err)
+		return 2
+	}
+
+	replayed, err := replay.Request(context.Background(), cli, opts, time.Now())
+	for _, metric := range replayed {
+		_, _ = fmt.Fprintln(out, metric)
+	}
+	if err != nil {
+		_, _ = fmt.Fprintln(out, err)
+		return 1
+	}
+	action := "requested the replay of"
+	if *dryRun {
+		action = "would replay"
+	}
+	_, _ = fmt.Fprintf(out, "%s %d metric(s)\n", action, len(replayed))
+	return 0
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	"github.com/openmcp-project/metrics-operator/pkg/lint"
+)
+
+// runValidate checks the Metrics, MetricSets and ManagedMetrics in the manifests of the given files and directories
+// and returns the exit code, 1 if a check failed and 2 for invalid arguments
+func runValidate(args []string, out io.Writer) int {
+	flags := flag.NewFlagSet("validate", flag.ContinueOnError)
+	flags.SetOutput(out)
+	flags.Usage = func() {
+		_, _ = fmt.Fprintln(out, "Usage: metrics-operator validate [flags] <file or directory>...")
+		flags.PrintDefaults()
+	}
+	cluster := flags.Bool("cluster", false,
+		"Check that the targeted kinds exist in the cluster of the kubeconfig. Without it, the manifests are checked offline.")
+	kubeconfig := flags.String("kubeconfig", "",
+		"Path of the kubeconfig used with --cluster. Defaults to $KUBECONFIG, the in-cluster config or ~/.kube/config.")
+	minInterval := flags.Duration("min-interval", lint.DefaultMinInterval, "Shortest interval of a metric that passes the checks.")
+	maxInterval := flags.Duration("max-interval", lint.DefaultMaxInterval, "Longest interval of a metric that passes the checks.")
+	strict := flags.Bool("strict", false, "Fail on warnings, too.")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return 2
+	}
+
+	opts := lint.Options{MinInterval: *minInterval, MaxInterval: *maxInterval}
+	if *cluster {
+		disco, err := validateDiscoveryClient(*kubeconfig)
+		if err != nil {
+			_, _ = fmt.Fprintf(out, "failed to connect to the cluster: %v\n", err)
+			return 2
+		}
+		opts.Discovery = disco
+	}
+
+	files, err := manifestFiles(flags.Args())
+	if err != nil {
+		_, _ = fmt.Fprintln(out, err)
+		return 2
+	}
+
+	checked, errors, warnings := 0, 0, 0
+	for _, file := range files {
+		results, err := lintFile(file, opts)
+		if err != nil {
+			_, _ = fmt.Fprintf(out, "%s: %v\n", file, err)
+			errors++
+		}
+		for _, result := range results {
+			checked++
+			for _, finding := range result.Findings {
+				_, _ = fmt.Fprintf(out, "%s: %s: %s\n", file, result, finding)
+				if finding.Severity == lint.SeverityError {
+					errors++
+				} else {
+					warnings++
+				}
+			}
+		}
+	}
+	_, _ = fmt.Fprintf(out, "checked %d metrics in %d files: %d errors, %d warnings\n", checked, len(files), errors, warnings)
+
+	if errors > 0 || (*strict && warnings > 0) {
+		return 1
+	}
+	return 0
+}
+
+func validateDiscoveryClient(kubeconfig string) (discovery.DiscoveryInterface, error) {
+	restConfig, err := commandRestConfig(kubeconfig)
+	if err != nil {
+		return nil, err
+	}
+	return discovery.NewDiscoveryClientForConfig(restConfig)
+}
+
+// commandRestConfig returns the config of the given kubeconfig, or the default one of controller-runtime
+func commandRestConfig(kubeconfig string) (*rest.Config, error) {
+	if kubeconfig != "" {
+		return clientcmd.BuildConfigFromFlags("", kubeconfig)
+	}
+	return ctrl.GetConfig()
+}
+
+// manifestFiles returns the given files and the YAML and JSON files in the given directories
+func manifestFiles(paths []string) ([]string, error) {
+	var files []string
+	for _, path := range paths {
+		err := filepath.WalkDir(path, func(file string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if entry.IsDir() {
+				return nil
+			}
+			switch strings.ToLower(filepath.Ext(file)) {
+			case ".yaml", ".yml", ".json":
+				files = append(files, file)
+			default:
+				// files given explicitly are checked regardless of their extension
+				if file == path {
+					files = append(files, file)
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
+
+func lintFile(file string, opts lint.Options) ([]lint.Result, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+	return lint.Manifests(f, opts)
+}
@@ -0,0 +1,213 @@
+package clientoptl
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/openmcp-project/metrics-operator/api/v1alpha1"
+	internalmetrics "github.com/openmcp-project/metrics-operator/internal/metrics"
+)
+
+const (
+	// DefaultFailureThreshold is the number of consecutive failed exports after which the circuit breaker of a data sink opens
+	DefaultFailureThreshold = 5
+	// DefaultOpenDuration is the time the circuit breaker of a data sink stays open before a trial export is let through
+	DefaultOpenDuration = time.Minute
+)
+
+// ErrCircuitOpen is returned for exports that are skipped because the circuit breaker of the data sink is open
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// SharedCircuitBreakers is used by all metric clients, so all metrics exporting to a data sink share its circuit breaker
+var SharedCircuitBreakers = NewCircuitBreakers(DefaultFailureThreshold, DefaultOpenDuration)
+
+// CircuitBreakers keeps one circuit breaker per data sink
+type CircuitBreakers struct {
+	mu       sync.Mutex
+	breakers map[string]*CircuitBreaker
+
+	failureThreshold int
+	openDuration     time.Duration
+}
+
+// NewCircuitBreakers creates circuit breakers that open after failureThreshold consecutive failures
+// and let a trial export through after openDuration
+func NewCircuitBreakers(failureThreshold int, openDuration time.Duration) *CircuitBreakers {
+	return &CircuitBreakers{
+		breakers:         make(map[string]*CircuitBreaker),
+		failureThreshold: failureThreshold,
+		openDuration:     openDuration,
+	}
+}
+
+// Configure changes the thresholds of all circuit breakers, a failure threshold of zero disables them
+func (c *CircuitBreakers) Configure(failureThreshold int, openDuration time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.failureThreshold = failureThreshold
+	c.openDuration = openDuration
+	for _, b := range c.breakers {
+		b.mu.Lock()
+		b.failureThreshold = failureThreshold
+		b.openDuration = openDuration
+		b.mu.Unlock()
+	}
+}
+
+// For returns the circuit breaker of the data sink with the given name, it is created on first use
+func (c *CircuitBreakers) For(name string) *CircuitBreaker {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if b, ok := c.breakers[name]; ok {
+		return b
+	}
+	b := &CircuitBreaker{
+		name:             name,
+		state:            v1alpha1.CircuitClosed,
+		failureThreshold: c.failureThreshold,
+		openDuration:     c.openDuration,
+		now:              time.Now,
+	}
+	c.breakers[name] = b
+	internalmetrics.RecordCircuitBreakerState(name, string(b.state))
+	return b
+}
+
+// Get returns the circuit breaker of the data sink with the given name, if any export used it
+func (c *CircuitBreakers) Get(name string) (*CircuitBreaker, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	b, ok := c.breakers[name]
+	return b, ok
+}
+
+// Statuses returns the status of all circuit breakers by the name of their data sink
+func (c *CircuitBreakers) Statuses() map[string]v1alpha1.CircuitBreakerStatus {
+	c.mu.Lock()
+	breakers := make(map[string]*CircuitBreaker, len(c.breakers))
+	maps.Copy(breakers, c.breakers)
+	c.mu.Unlock()
+
+	statuses := make(map[string]v1alpha1.CircuitBreakerStatus, len(breakers))
+	for name, b := range breakers {
+		statuses[name] = b.Status()
+	}
+	return statuses
+}
+
+// Remove drops the circuit breaker of a deleted data sink
+func (c *CircuitBreakers) Remove(name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.breakers[name]; ok {
+		delete(c.breakers, name)
+		internalmetrics.DeleteCircuitBreaker(name)
+	}
+}
+
+// CircuitBreaker skips the exports to a data sink after consecutive failures.
+// Once the open duration passed, a single trial export is let through: if it succeeds the circuit closes,
+// otherwise it stays open for another open duration.
+type CircuitBreaker struct {
+	mu sync.Mutex
+
+	name             string
+	state            v1alpha1.CircuitBreakerState
+	failures         int
+	openedAt         time.Time
+	lastError        string
+	trialInFlight    bool
+	failureThreshold int
+	openDuration     time.Duration
+
+	now func() time.Time
+}
+
+// Allow returns ErrCircuitOpen if the export must be skipped
+func (b *CircuitBreaker) Allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.failureThreshold <= 0 {
+		return nil
+	}
+
+	switch b.state {
+	case v1alpha1.CircuitOpen:
+		if b.now().Sub(b.openedAt) < b.openDuration {
+			return b.openError()
+		}
+		b.setState(v1alpha1.CircuitHalfOpen)
+		b.trialInFlight = true
+		return nil
+	case v1alpha1.CircuitHalfOpen:
+		if b.trialInFlight {
+			return b.openError()
+		}
+		b.trialInFlight = true
+		return nil
+	default:
+		return nil
+	}
+}
+
+// Record updates the circuit breaker with the result of an export that was allowed
+func (b *CircuitBreaker) Record(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.trialInFlight = false
+	if err == nil {
+		b.failures = 0
+		b.lastError = ""
+		b.setState(v1alpha1.CircuitClosed)
+		return
+	}
+
+	b.failures++
+	b.lastError = err.Error()
+	if b.failureThreshold > 0 && (b.state == v1alpha1.CircuitHalfOpen || b.failures >= b.failureThreshold) {
+		b.openedAt = b.now()
+		b.setState(v1alpha1.CircuitOpen)
+	}
+}
+
+// Reset closes the circuit breaker, e.g. after the data sink was probed successfully
+func (b *CircuitBreaker) Reset() {
+	b.Record(nil)
+}
+
+// Status returns the state of the circuit breaker as reported in the DataSink status
+func (b *CircuitBreaker) Status() v1alpha1.CircuitBreakerStatus {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	status := v1alpha1.CircuitBreakerStatus{
+		State:               b.state,
+		ConsecutiveFailures: b.failures,
+		LastError:           b.lastError,
+	}
+	if !b.openedAt.IsZero() {
+		openedAt := metav1.NewTime(b.openedAt)
+		status.OpenedAt = &openedAt
+	}
+	return status
+}
+
+func (b *CircuitBreaker) setState(state v1alpha1.CircuitBreakerState) {
+	if b.state != state {
+		b.state = state
+		internalmetrics.RecordCircuitBreakerState(b.name, string(state))
+	}
+}
+
+func (b *CircuitBreaker) openError() error {
+	internalmetrics.DataSinkSkippedExports.WithLabelValues(b.name).Inc()
+	return fmt.Errorf("%w: export to data sink '%s' skipped after %d consecutive failures, last error: %s", ErrCircuitOpen, b.name, b.failures, b.lastError)
+}
@@ -0,0 +1,80 @@
+package clientoptl
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/openmcp-project/metrics-operator/api/v1alpha1"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	now := time.Now()
+	breakers := NewCircuitBreakers(3, time.Minute)
+	b := breakers.For("metrics/default")
+	b.now = func() time.Time { return now }
+	errExport := errors.New("connection refused")
+
+	// failures below the threshold keep the circuit closed
+	for range 2 {
+		require.NoError(t, b.Allow())
+		b.Record(errExport)
+	}
+	require.Equal(t, v1alpha1.CircuitClosed, b.Status().State)
+
+	require.NoError(t, b.Allow())
+	b.Record(errExport)
+	status := b.Status()
+	require.Equal(t, v1alpha1.CircuitOpen, status.State)
+	require.Equal(t, 3, status.ConsecutiveFailures)
+	require.Equal(t, "connection refused", status.LastError)
+	require.NotNil(t, status.OpenedAt)
+
+	// exports are skipped while the circuit is open
+	require.ErrorIs(t, b.Allow(), ErrCircuitOpen)
+
+	// after the open duration a single trial export is let through
+	now = now.Add(time.Minute)
+	require.NoError(t, b.Allow())
+	require.Equal(t, v1alpha1.CircuitHalfOpen, b.Status().State)
+	require.ErrorIs(t, b.Allow(), ErrCircuitOpen)
+
+	// a failed trial opens the circuit again
+	b.Record(errExport)
+	require.Equal(t, v1alpha1.CircuitOpen, b.Status().State)
+	require.ErrorIs(t, b.Allow(), ErrCircuitOpen)
+
+	// a successful trial closes it
+	now = now.Add(time.Minute)
+	require.NoError(t, b.Allow())
+	b.Record(nil)
+	status = b.Status()
+	require.Equal(t, v1alpha1.CircuitClosed, status.State)
+	require.Zero(t, status.ConsecutiveFailures)
+	require.NoError(t, b.Allow())
+}
+
+func TestCircuitBreakers(t *testing.T) {
+	breakers := NewCircuitBreakers(1, time.Hour)
+	a := breakers.For("metrics/a")
+	require.Same(t, a, breakers.For("metrics/a"))
+	require.NotSame(t, a, breakers.For("metrics/b"))
+
+	a.Record(errors.New("connection refused"))
+	require.ErrorIs(t, a.Allow(), ErrCircuitOpen)
+
+	statuses := breakers.Statuses()
+	require.Len(t, statuses, 2)
+	require.Equal(t, v1alpha1.CircuitOpen, statuses["metrics/a"].State)
+	require.Equal(t, v1alpha1.CircuitClosed, statuses["metrics/b"].State)
+
+	// a threshold of zero disables the circuit breakers
+	breakers.Configure(0, time.Hour)
+	require.NoError(t, a.Allow())
+
+	breakers.Remove("metrics/a")
+	_, ok := breakers.Get("metrics/a")
+	require.False(t, ok)
+}
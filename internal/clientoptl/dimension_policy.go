@@ -0,0 +1,75 @@
+package clientoptl
+
+import (
+	"maps"
+	"slices"
+
+	"github.com/openmcp-project/metrics-operator/internal/common"
+)
+
+const (
+	dimensionDropped  = "dropped"
+	dimensionRedacted = "redacted"
+)
+
+// dimensionViolations collects the keys of the dimensions dropped and redacted while recording a series of data points
+type dimensionViolations struct {
+	dropped  map[string]bool
+	redacted map[string]bool
+}
+
+func (v *dimensionViolations) empty() bool {
+	return len(v.dropped) == 0 && len(v.redacted) == 0
+}
+
+func (v *dimensionViolations) add(action, key string) {
+	switch action {
+	case dimensionDropped:
+		if v.dropped == nil {
+			v.dropped = map[string]bool{}
+		}
+		v.dropped[key] = true
+	case dimensionRedacted:
+		if v.redacted == nil {
+			v.redacted = map[string]bool{}
+		}
+		v.redacted[key] = true
+	}
+}
+
+func (v *dimensionViolations) droppedKeys() []string {
+	return slices.Sorted(maps.Keys(v.dropped))
+}
+
+func (v *dimensionViolations) redactedKeys() []string {
+	return slices.Sorted(maps.Keys(v.redacted))
+}
+
+// applyDimensionPolicy returns the dimensions that may be exported under the policy.
+// violation is called for every dimension that is dropped or redacted, the given dimensions are left unchanged.
+func applyDimensionPolicy(policy *common.DimensionPolicy, dimensions map[string]string, violation func(action, key string)) map[string]string {
+	if policy == nil {
+		return dimensions
+	}
+
+	exported := make(map[string]string, len(dimensions))
+	for key, value := range dimensions {
+		if policy.DeniedKeys[key] || (len(policy.AllowedKeys) > 0 && !policy.AllowedKeys[key]) {
+			violation(dimensionDropped, key)
+			continue
+		}
+
+		redacted := value
+		for _, redaction := range policy.Redactions {
+			if len(redaction.Keys) > 0 && !redaction.Keys[key] {
+				continue
+			}
+			redacted = redaction.Pattern.ReplaceAllString(redacted, redaction.Replacement)
+		}
+		if redacted != value {
+			violation(dimensionRedacted, key)
+		}
+		exported[key] = redacted
+	}
+	return exported
+}
@@ -0,0 +1,88 @@
+package clientoptl
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/openmcp-project/metrics-operator/internal/common"
+)
+
+func TestApplyDimensionPolicy(t *testing.T) {
+	dimensions := map[string]string{"name": "web-0", "namespace": "team-a", "kind": "Pod", "cluster": "prod-eu"}
+
+	testCases := []struct {
+		name             string
+		policy           *common.DimensionPolicy
+		expected         map[string]string
+		expectedDropped  []string
+		expectedRedacted []string
+	}{
+		{
+			name:     "NoPolicy",
+			expected: dimensions,
+		},
+		{
+			name:            "DeniedKeys",
+			policy:          &common.DimensionPolicy{DeniedKeys: map[string]bool{"name": true, "namespace": true}},
+			expected:        map[string]string{"kind": "Pod", "cluster": "prod-eu"},
+			expectedDropped: []string{"name", "namespace"},
+		},
+		{
+			name:            "AllowedKeys",
+			policy:          &common.DimensionPolicy{AllowedKeys: map[string]bool{"kind": true, "name": true}},
+			expected:        map[string]string{"kind": "Pod", "name": "web-0"},
+			expectedDropped: []string{"cluster", "namespace"},
+		},
+		{
+			name: "DeniedKeysTakePrecedence",
+			policy: &common.DimensionPolicy{
+				AllowedKeys: map[string]bool{"kind": true, "name": true},
+				DeniedKeys:  map[string]bool{"name": true},
+			},
+			expected:        map[string]string{"kind": "Pod"},
+			expectedDropped: []string{"cluster", "name", "namespace"},
+		},
+		{
+			name: "Redactions",
+			policy: &common.DimensionPolicy{Redactions: []common.DimensionRedaction{
+				{Keys: map[string]bool{"name": true}, Pattern: regexp.MustCompile(`-\d+$`), Replacement: ""},
+				{Pattern: regexp.MustCompile(`^(prod|team)-.*$`), Replacement: "$1-REDACTED"},
+			}},
+			expected:         map[string]string{"name": "web", "namespace": "team-REDACTED", "kind": "Pod", "cluster": "prod-REDACTED"},
+			expectedRedacted: []string{"cluster", "name", "namespace"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			violations := dimensionViolations{}
+			exported := applyDimensionPolicy(tc.policy, dimensions, violations.add)
+
+			require.Equal(t, tc.expected, exported)
+			require.Equal(t, tc.expectedDropped, violations.droppedKeys())
+			require.Equal(t, tc.expectedRedacted, violations.redactedKeys())
+			require.Len(t, dimensions, 4, "the given dimensions are left unchanged")
+		})
+	}
+}
+
+func TestMetricApplyDimensionPolicy(t *testing.T) {
+	dataPoints := []*DataPoint{
+		NewDataPoint().AddDimension("name", "web-0").AddDimension("phase", "Running").SetValue(3),
+		NewDataPoint().AddDimension("name", "web-1").AddDimension("phase", "Pending").SetValue(1),
+	}
+
+	mc := &Metric{dataSink: "default", dimensionPolicy: &common.DimensionPolicy{DeniedKeys: map[string]bool{"name": true}}}
+	applied := mc.ApplyDimensionPolicy(context.Background(), dataPoints)
+	require.Equal(t, []*DataPoint{
+		NewDataPoint().AddDimension("phase", "Running").SetValue(3),
+		NewDataPoint().AddDimension("phase", "Pending").SetValue(1),
+	}, applied)
+	require.Equal(t, "web-0", dataPoints[0].Dimensions["name"], "the given data points are left unchanged")
+
+	// without a policy the data points are returned as they are
+	require.Equal(t, dataPoints, (&Metric{}).ApplyDimensionPolicy(context.Background(), dataPoints))
+}
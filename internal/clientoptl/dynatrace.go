@@ -0,0 +1,222 @@
+package clientoptl
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+
+	"github.com/openmcp-project/metrics-operator/internal/common"
+)
+
+const (
+	// dynatraceMaxLines is the number of lines sent to the ingest API in a single request
+	dynatraceMaxLines = 1000
+	// dynatraceMaxLength is the maximum length of metric keys, dimension keys and dimension values
+	dynatraceMaxLength = 250
+)
+
+var (
+	dynatraceInvalidMetricKey    = regexp.MustCompile(`[^a-zA-Z0-9_\-.]`)
+	dynatraceInvalidDimensionKey = regexp.MustCompile(`[^a-z0-9_\-.:]`)
+)
+
+// dynatraceExporter sends the data points to the Dynatrace metrics ingest API v2 in the metrics ingestion protocol.
+// Metrics with a description or unit are preceded by a metadata line, so Dynatrace shows them annotated.
+type dynatraceExporter struct {
+	endpoint        string
+	token           string
+	compression     string
+	maxPayloadBytes int
+	client          *http.Client
+}
+
+// dynatraceIngestResponse is the body the ingest API returns for accepted and rejected requests
+type dynatraceIngestResponse struct {
+	LinesOk      int `json:"linesOk"`
+	LinesInvalid int `json:"linesInvalid"`
+	Error        *struct {
+		Code         int    `json:"code"`
+		Message      string `json:"message"`
+		InvalidLines []struct {
+			Line  int    `json:"line"`
+			Error string `json:"error"`
+		} `json:"invalidLines"`
+	} `json:"error"`
+}
+
+// invalidLines returns the errors of the lines Dynatrace rejected, separated by semicolons
+func (r dynatraceIngestResponse) invalidLines() string {
+	if r.Error == nil {
+		return ""
+	}
+	errs := make([]string, 0, len(r.Error.InvalidLines))
+	for _, line := range r.Error.InvalidLines {
+		errs = append(errs, fmt.Sprintf("line %d: %s", line.Line, line.Error))
+	}
+	return strings.Join(errs, "; ")
+}
+
+func newDynatraceExporter(_ context.Context, credentials *common.DataSinkCredentials) (MetricsExporter, error) {
+	endpoint, client, err := newHTTPExportClient(credentials, "Dynatrace")
+	if err != nil {
+		return nil, err
+	}
+
+	exporter := &dynatraceExporter{
+		endpoint:        endpoint.String(),
+		compression:     credentials.Compression,
+		maxPayloadBytes: credentials.MaxPayloadBytes,
+		client:          client,
+	}
+	if credentials.APIKey != nil {
+		exporter.token = credentials.APIKey.Token
+	}
+	return exporter, nil
+}
+
+// Export sends the data points in batches of at most dynatraceMaxLines lines and the maximum payload size,
+// an empty export sends nothing
+func (e *dynatraceExporter) Export(ctx context.Context, rm *metricdata.ResourceMetrics) error {
+	for _, batch := range lineBatches(dynatraceLines(gaugeDataPoints(rm)), dynatraceMaxLines, e.maxPayloadBytes) {
+		if err := e.ingest(ctx, batch); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *dynatraceExporter) Shutdown(context.Context) error {
+	e.client.CloseIdleConnections()
+	return nil
+}
+
+// ingest posts the lines to the ingest API, lines rejected by Dynatrace fail the export
+func (e *dynatraceExporter) ingest(ctx context.Context, lines []string) error {
+	req, err := newPayloadRequest(ctx, e.endpoint, []byte(strings.Join(lines, "\n")), e.compression)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	if e.token != "" {
+		req.Header.Set("Authorization", "Api-Token "+e.token)
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+
+	ingestResponse := dynatraceIngestResponse{}
+	_ = json.Unmarshal(body, &ingestResponse)
+	invalidLines := ingestResponse.invalidLines()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		message := string(bytes.TrimSpace(body))
+		if ingestResponse.Error != nil && ingestResponse.Error.Message != "" {
+			message = ingestResponse.Error.Message
+			if invalidLines != "" {
+				message += ": " + invalidLines
+			}
+		}
+		return rejected(resp.StatusCode, message, fmt.Errorf("dynatrace ingest failed with %s: %s", resp.Status, message))
+	}
+	if ingestResponse.LinesInvalid > 0 {
+		err := fmt.Errorf("dynatrace rejected %d of %d lines", ingestResponse.LinesInvalid, len(lines))
+		if invalidLines != "" {
+			err = fmt.Errorf("%w: %s", err, invalidLines)
+		}
+		return rejected(http.StatusBadRequest, invalidLines, err)
+	}
+	return nil
+}
+
+// dynatraceLines converts the data points to lines of the metrics ingestion protocol,
+// with a metadata line for each metric that has a description or unit
+func dynatraceLines(points []gaugeDataPoint) []string {
+	lines := make([]string, 0, len(points))
+	described := map[string]bool{}
+	for _, dp := range points {
+		key := dynatraceMetricKey(dp.Metric)
+		if !described[key] && (dp.Description != "" || dp.Unit != "") {
+			described[key] = true
+			lines = append(lines, dynatraceMetadataLine(key, dp.Description, dp.Unit))
+		}
+
+		dimensionKeys := make([]string, 0, len(dp.Dimensions))
+		for k := range dp.Dimensions {
+			dimensionKeys = append(dimensionKeys, k)
+		}
+		sort.Strings(dimensionKeys)
+
+		var line strings.Builder
+		line.WriteString(key)
+		for _, k := range dimensionKeys {
+			line.WriteString(",")
+			line.WriteString(dynatraceDimensionKey(k))
+			line.WriteString("=")
+			line.WriteString(dynatraceQuote(dp.Dimensions[k]))
+		}
+		fmt.Fprintf(&line, " gauge,%d", dp.Value)
+		if !dp.Time.IsZero() {
+			fmt.Fprintf(&line, " %d", dp.Time.UnixMilli())
+		}
+		lines = append(lines, line.String())
+	}
+	return lines
+}
+
+// dynatraceMetadataLine registers the description and unit of a metric
+func dynatraceMetadataLine(key, description, unit string) string {
+	var properties []string
+	if description != "" {
+		properties = append(properties, "dt.meta.description="+dynatraceQuote(description))
+	}
+	if unit != "" {
+		properties = append(properties, "dt.meta.unit="+dynatraceQuote(unit))
+	}
+	return "#" + key + " gauge " + strings.Join(properties, ",")
+}
+
+// dynatraceMetricKey replaces the characters that are not allowed in metric keys
+func dynatraceMetricKey(name string) string {
+	key := dynatraceInvalidMetricKey.ReplaceAllString(name, "_")
+	if key == "" || !isLetterOrUnderscore(key[0]) {
+		key = "_" + key
+	}
+	return truncate(key, dynatraceMaxLength)
+}
+
+// dynatraceDimensionKey lowercases the key and replaces the characters that are not allowed in dimension keys
+func dynatraceDimensionKey(name string) string {
+	key := dynatraceInvalidDimensionKey.ReplaceAllString(strings.ToLower(name), "_")
+	if key == "" || !isLetterOrUnderscore(key[0]) {
+		key = "_" + key
+	}
+	return truncate(key, dynatraceMaxLength)
+}
+
+// dynatraceQuote quotes a dimension or metadata value, escaping backslashes and quotes
+func dynatraceQuote(value string) string {
+	value = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", " ", "\r", " ").Replace(truncate(value, dynatraceMaxLength))
+	return `"` + value + `"`
+}
+
+func isLetterOrUnderscore(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func truncate(s string, length int) string {
+	if len(s) <= length {
+		return s
+	}
+	return s[:length]
+}
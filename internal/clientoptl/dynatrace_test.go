@@ -0,0 +1,136 @@
+package clientoptl
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+
+	"github.com/openmcp-project/metrics-operator/internal/common"
+)
+
+func dynatraceResourceMetrics(now time.Time) *metricdata.ResourceMetrics {
+	return &metricdata.ResourceMetrics{ScopeMetrics: []metricdata.ScopeMetrics{{
+		Metrics: []metricdata.Metrics{{
+			Name:        "pods",
+			Description: `Pods of "team-a"`,
+			Unit:        "1",
+			Data: metricdata.Gauge[int64]{DataPoints: []metricdata.DataPoint[int64]{
+				{Attributes: attribute.NewSet(attribute.String("Namespace", "team-a"), attribute.String("phase", `Run,ning=1`)), Time: now, Value: 3},
+			}},
+		}},
+	}}}
+}
+
+func TestDynatraceExporter(t *testing.T) {
+	now := time.UnixMilli(1735732800000)
+
+	testCases := []struct {
+		name              string
+		status            int
+		response          string
+		expectedError     string
+		expectedRejection RejectionReason
+	}{
+		{
+			name:     "Accepted",
+			status:   http.StatusAccepted,
+			response: `{"linesOk": 1, "linesInvalid": 0, "error": null}`,
+		},
+		{
+			name:          "LinesRejected",
+			status:        http.StatusBadRequest,
+			response:      `{"linesOk": 0, "linesInvalid": 1, "error": {"code": 400, "message": "1 invalid line"}}`,
+			expectedError: "dynatrace ingest failed with 400 Bad Request: 1 invalid line",
+		},
+		{
+			name:          "PartiallyAccepted",
+			status:        http.StatusAccepted,
+			response:      `{"linesOk": 1, "linesInvalid": 1}`,
+			expectedError: "dynatrace rejected 1 of 2 lines",
+		},
+		{
+			name:              "InvalidDimension",
+			status:            http.StatusAccepted,
+			response:          `{"linesOk": 1, "linesInvalid": 1, "error": {"code": 400, "message": "1 invalid line", "invalidLines": [{"line": 2, "error": "invalid dimension key"}]}}`,
+			expectedError:     "dynatrace rejected 1 of 2 lines: line 2: invalid dimension key",
+			expectedRejection: RejectionInvalidDimension,
+		},
+		{
+			name:              "QuotaExceeded",
+			status:            http.StatusBadRequest,
+			response:          `{"error": {"code": 400, "message": "Metric key limit exceeded"}}`,
+			expectedError:     "dynatrace ingest failed with 400 Bad Request: Metric key limit exceeded",
+			expectedRejection: RejectionQuotaExceeded,
+		},
+		{
+			name:              "Unauthorized",
+			status:            http.StatusUnauthorized,
+			response:          "Token is missing",
+			expectedError:     "dynatrace ingest failed with 401 Unauthorized: Token is missing",
+			expectedRejection: RejectionUnauthorized,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var body, authorization string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				b, _ := io.ReadAll(r.Body)
+				body = string(b)
+				authorization = r.Header.Get("Authorization")
+				w.WriteHeader(tc.status)
+				_, _ = w.Write([]byte(tc.response))
+			}))
+			defer server.Close()
+
+			exporter, err := newDynatraceExporter(context.Background(), &common.DataSinkCredentials{
+				Host:   server.URL + "/api/v2/metrics/ingest",
+				APIKey: &common.APIKeyAuth{Token: "dt0c01.token"},
+			})
+			require.NoError(t, err)
+			defer func() { _ = exporter.Shutdown(context.Background()) }()
+
+			err = exporter.Export(context.Background(), dynatraceResourceMetrics(now))
+			if tc.expectedError != "" {
+				require.EqualError(t, err, tc.expectedError)
+				require.Equal(t, tc.expectedRejection, Rejection(err))
+			} else {
+				require.NoError(t, err)
+			}
+			require.Equal(t, "Api-Token dt0c01.token", authorization)
+			require.Equal(t, strings.Join([]string{
+				`#pods gauge dt.meta.description="Pods of \"team-a\"",dt.meta.unit="1"`,
+				`pods,namespace="team-a",phase="Run,ning=1" gauge,3 1735732800000`,
+			}, "\n"), body)
+		})
+	}
+}
+
+func TestDynatraceExporter_emptyExport(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	exporter, err := newDynatraceExporter(context.Background(), &common.DataSinkCredentials{Host: server.URL})
+	require.NoError(t, err)
+	require.NoError(t, exporter.Export(context.Background(), &metricdata.ResourceMetrics{}))
+	require.Zero(t, requests)
+}
+
+func TestDynatraceKeys(t *testing.T) {
+	require.Equal(t, "openmcp.pods_running", dynatraceMetricKey("openmcp.pods running"))
+	require.Equal(t, "_1pods", dynatraceMetricKey("1pods"))
+	require.Equal(t, "k8s.namespace_name", dynatraceDimensionKey("K8s.Namespace/Name"))
+	require.Equal(t, `"C:\\temp"`, dynatraceQuote(`C:\temp`))
+}
@@ -0,0 +1,137 @@
+package clientoptl
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+
+	"github.com/openmcp-project/metrics-operator/api/v1alpha1"
+	"github.com/openmcp-project/metrics-operator/internal/common"
+)
+
+// exporters holds the ExporterFactory of each data sink type
+var exporters = struct {
+	mu        sync.RWMutex
+	factories map[v1alpha1.DataSinkType]ExporterFactory
+}{factories: map[v1alpha1.DataSinkType]ExporterFactory{}}
+
+func init() {
+	RegisterExporter(v1alpha1.DataSinkTypeOTLP, newMetricsExporter)
+	RegisterExporter(v1alpha1.DataSinkTypeStdout, newStdoutExporter)
+	RegisterExporter(v1alpha1.DataSinkTypeDynatrace, newDynatraceExporter)
+	RegisterExporter(v1alpha1.DataSinkTypeInfluxDB, newInfluxDBExporter)
+	RegisterExporter(v1alpha1.DataSinkTypeWebhook, newWebhookExporter)
+}
+
+// RegisterExporter registers the factory of the exporters of a data sink type, replacing the factory registered before
+func RegisterExporter(dataSinkType v1alpha1.DataSinkType, factory ExporterFactory) {
+	exporters.mu.Lock()
+	defer exporters.mu.Unlock()
+	exporters.factories[dataSinkType] = factory
+}
+
+// newRegisteredExporter creates the exporter registered for the type of the data sink, OTLP if the type is not set
+func newRegisteredExporter(ctx context.Context, credentials *common.DataSinkCredentials) (MetricsExporter, error) {
+	dataSinkType := cmp.Or(v1alpha1.DataSinkType(credentials.Type), v1alpha1.DataSinkTypeOTLP)
+
+	exporters.mu.RLock()
+	factory, ok := exporters.factories[dataSinkType]
+	exporters.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unsupported data sink type '%s'", dataSinkType)
+	}
+	return factory(ctx, credentials)
+}
+
+// defaultExportTimeout bounds a single export request of the exporters sending over HTTP, like the default of the OTLP exporters
+const defaultExportTimeout = 10 * time.Second
+
+// newHTTPExportClient parses the http or https endpoint of the data sink
+// and creates a client with the client certificate, CA bundle and proxy of the data sink, if any.
+// The client retries transient failures like the OTLP exporters, with the retry settings of the data sink.
+// Each attempt times out after the timeout of the data sink if the data sink does not respond,
+// and the export as a whole once the retries would have given up.
+func newHTTPExportClient(credentials *common.DataSinkCredentials, exporter string) (*url.URL, *http.Client, error) {
+	parsedURL, err := url.Parse(credentials.Host)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse endpoint URL: %w", err)
+	}
+	if !isHTTPProtocol(parsedURL.Scheme) {
+		return nil, nil, fmt.Errorf("unsupported protocol scheme for %s, got %s, want http|https", exporter, parsedURL.Scheme)
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	tlsConfig, err := tlsConfigForDataSink(credentials)
+	if err != nil {
+		return nil, nil, err
+	}
+	if tlsConfig != nil {
+		transport.TLSClientConfig = tlsConfig
+	}
+	if proxy := proxyForDataSink(credentials); proxy != nil {
+		transport.Proxy = func(req *http.Request) (*url.URL, error) {
+			return proxy(req.URL)
+		}
+	}
+	timeout := cmp.Or(credentials.Timeout, defaultExportTimeout)
+	// a timed out attempt is a retryable net.Error
+	transport.ResponseHeaderTimeout = timeout
+	retry := credentials.Retry
+	if retry == nil {
+		retry = &common.RetryConfig{Enabled: true}
+	}
+	config := retryConfigForDataSink(retry)
+	// bounds reading the response as well, which ResponseHeaderTimeout does not cover
+	clientTimeout := timeout
+	if config.Enabled {
+		clientTimeout += config.MaxElapsedTime
+	}
+	return parsedURL, &http.Client{Transport: &retryTransport{next: transport, config: config}, Timeout: clientTimeout}, nil
+}
+
+// gaugeDataPoint is a data point of an int64 gauge in the collected metrics
+type gaugeDataPoint struct {
+	Scope       string
+	Metric      string
+	Description string
+	Unit        string
+	Time        time.Time
+	Dimensions  map[string]string
+	Value       int64
+}
+
+// gaugeDataPoints flattens the data points of the int64 gauges recorded by the metric clients,
+// for exporters that do not send the OTLP structure
+func gaugeDataPoints(rm *metricdata.ResourceMetrics) []gaugeDataPoint {
+	var points []gaugeDataPoint
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			gauge, ok := m.Data.(metricdata.Gauge[int64])
+			if !ok {
+				continue
+			}
+			for _, dp := range gauge.DataPoints {
+				dimensions := make(map[string]string, dp.Attributes.Len())
+				for _, kv := range dp.Attributes.ToSlice() {
+					dimensions[string(kv.Key)] = kv.Value.Emit()
+				}
+				points = append(points, gaugeDataPoint{
+					Scope:       sm.Scope.Name,
+					Metric:      m.Name,
+					Description: m.Description,
+					Unit:        m.Unit,
+					Time:        dp.Time,
+					Dimensions:  dimensions,
+					Value:       dp.Value,
+				})
+			}
+		}
+	}
+	return points
+}
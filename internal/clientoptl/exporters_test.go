@@ -0,0 +1,178 @@
+package clientoptl
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/openmcp-project/metrics-operator/internal/common"
+)
+
+func TestNewRegisteredExporter(t *testing.T) {
+	testCases := []struct {
+		name          string
+		dataSinkType  string
+		expectedError string
+		expectStdout  bool
+	}{
+		{
+			name: "DefaultsToOTLP",
+		},
+		{
+			name:         "Stdout",
+			dataSinkType: "Stdout",
+			expectStdout: true,
+		},
+		{
+			name:          "Unsupported",
+			dataSinkType:  "Carrier pigeon",
+			expectedError: "unsupported data sink type 'Carrier pigeon'",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			exporter, err := newRegisteredExporter(context.Background(), &common.DataSinkCredentials{
+				Type: tc.dataSinkType,
+				Host: "https://sink.example.com/otlp/v1/metrics",
+			})
+			if tc.expectedError != "" {
+				require.EqualError(t, err, tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			_, isStdout := exporter.(*stdoutExporter)
+			require.Equal(t, tc.expectStdout, isStdout)
+		})
+	}
+}
+
+func TestStdoutExporter(t *testing.T) {
+	ctx := context.Background()
+	var out bytes.Buffer
+	exporter := &stdoutExporter{out: &lockedWriter{w: &out}, dataSink: "metrics/debug"}
+	factory := func(context.Context, *common.DataSinkCredentials) (MetricsExporter, error) { return exporter, nil }
+
+	mc, err := ExporterFactory(factory).NewMetricClient(ctx, &common.DataSinkCredentials{Name: "metrics/debug", Type: "Stdout"})
+	require.NoError(t, err)
+	mc.SetMeter("metric", nil)
+	gauge, err := mc.NewMetric("pods", "", "")
+	require.NoError(t, err)
+	require.NoError(t, gauge.RecordMetrics(ctx, NewDataPoint().AddDimension("namespace", "team-a").SetValue(3)))
+	require.NoError(t, mc.ExportMetrics(ctx))
+
+	line := stdoutLine{}
+	require.NoError(t, json.Unmarshal(out.Bytes(), &line))
+	require.Equal(t, "metrics/debug", line.DataSink)
+	require.Equal(t, "metric", line.Scope)
+	require.Equal(t, "pods", line.Metric)
+	require.Equal(t, map[string]string{"namespace": "team-a"}, line.Dimensions)
+	require.Equal(t, int64(3), line.Value)
+	require.False(t, line.Time.IsZero())
+}
+
+func TestNewHTTPExportClient_caBundle(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+	caBundle := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+
+	testCases := []struct {
+		name          string
+		caBundle      []byte
+		expectedError string
+	}{
+		{
+			name:          "UnknownCA",
+			expectedError: "certificate signed by unknown authority",
+		},
+		{
+			name:     "CABundle",
+			caBundle: caBundle,
+		},
+		{
+			name:          "InvalidCABundle",
+			caBundle:      []byte("not a certificate"),
+			expectedError: "failed to append CA bundle: no PEM encoded certificates found",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			endpoint, client, err := newHTTPExportClient(&common.DataSinkCredentials{Host: server.URL, CABundle: tc.caBundle}, "Webhook")
+			if err == nil {
+				var resp *http.Response
+				resp, err = client.Get(endpoint.String())
+				if err == nil {
+					_ = resp.Body.Close()
+				}
+			}
+			if tc.expectedError != "" {
+				require.ErrorContains(t, err, tc.expectedError)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestNewHTTPExportClient_timeout(t *testing.T) {
+	// the data sink accepts the connection but never answers
+	var requests atomic.Int32
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	testCases := []struct {
+		name        string
+		retry       *common.RetryConfig
+		wantRetried bool
+	}{
+		{
+			name:  "NotRetried",
+			retry: &common.RetryConfig{Enabled: false},
+		},
+		{
+			name:        "Retried",
+			retry:       &common.RetryConfig{Enabled: true, InitialInterval: time.Millisecond, MaxInterval: time.Millisecond, MaxElapsedTime: 200 * time.Millisecond},
+			wantRetried: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			requests.Store(0)
+			endpoint, client, err := newHTTPExportClient(&common.DataSinkCredentials{Host: server.URL, Timeout: 50 * time.Millisecond, Retry: tc.retry}, "Webhook")
+			require.NoError(t, err)
+
+			start := time.Now()
+			resp, err := client.Get(endpoint.String())
+			if err == nil {
+				_ = resp.Body.Close()
+			}
+			require.Error(t, err)
+			require.Less(t, time.Since(start), 2*time.Second)
+			if tc.wantRetried {
+				require.Greater(t, requests.Load(), int32(1))
+			} else {
+				require.Equal(t, int32(1), requests.Load())
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package clientoptl
+
+import (
+	"context"
+	"sync"
+
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+
+	"github.com/openmcp-project/metrics-operator/internal/common"
+)
+
+// ExportedDataPoint is a data point captured by the FakeExporter
+type ExportedDataPoint struct {
+	Dimensions map[string]string
+	Value      int64
+}
+
+// FakeExporter is an in-memory MetricsExporter for tests.
+// It keeps the exported metrics instead of sending them, so tests can assert the data points without a collector.
+type FakeExporter struct {
+	mu      sync.Mutex
+	exports []metricdata.ResourceMetrics
+	// Err is returned by Export instead of capturing the metrics
+	Err error
+}
+
+// NewFakeExporter creates a FakeExporter without exports
+func NewFakeExporter() *FakeExporter {
+	return &FakeExporter{}
+}
+
+// Factory returns an ExporterFactory that returns the FakeExporter for all data sinks
+func (f *FakeExporter) Factory() ExporterFactory {
+	return func(context.Context, *common.DataSinkCredentials) (MetricsExporter, error) {
+		return f, nil
+	}
+}
+
+// Export captures the metrics or returns Err
+func (f *FakeExporter) Export(_ context.Context, rm *metricdata.ResourceMetrics) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.Err != nil {
+		return f.Err
+	}
+	f.exports = append(f.exports, *rm)
+	return nil
+}
+
+// Shutdown does nothing, the captured metrics are kept
+func (f *FakeExporter) Shutdown(context.Context) error {
+	return nil
+}
+
+// Exports returns the number of successful exports, including empty ones
+func (f *FakeExporter) Exports() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.exports)
+}
+
+// DataPoints returns the data points exported for the gauge with the given name, in the order they were exported
+func (f *FakeExporter) DataPoints(name string) []ExportedDataPoint {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var points []ExportedDataPoint
+	for i := range f.exports {
+		for _, dp := range gaugeDataPoints(&f.exports[i]) {
+			if dp.Metric == name {
+				points = append(points, ExportedDataPoint{Dimensions: dp.Dimensions, Value: dp.Value})
+			}
+		}
+	}
+	return points
+}
+
+// Reset drops the captured metrics
+func (f *FakeExporter) Reset() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.exports = nil
+}
@@ -0,0 +1,42 @@
+package clientoptl
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/openmcp-project/metrics-operator/internal/common"
+)
+
+func TestFakeExporter(t *testing.T) {
+	ctx := context.Background()
+	exporter := NewFakeExporter()
+	mc, err := exporter.Factory().NewMetricClient(ctx, &common.DataSinkCredentials{Host: "https://sink.example.com"})
+	require.NoError(t, err)
+	mc.SetMeter("metric", nil)
+
+	gauge, err := mc.NewMetric("pods", "", "")
+	require.NoError(t, err)
+	require.NoError(t, gauge.RecordMetrics(ctx,
+		NewDataPoint().AddDimension("namespace", "team-a").SetValue(3),
+		NewDataPoint().AddDimension("namespace", "team-b").SetValue(5),
+	))
+	require.NoError(t, mc.ExportMetrics(ctx))
+
+	require.Equal(t, 1, exporter.Exports())
+	require.ElementsMatch(t, []ExportedDataPoint{
+		{Dimensions: map[string]string{"namespace": "team-a"}, Value: 3},
+		{Dimensions: map[string]string{"namespace": "team-b"}, Value: 5},
+	}, exporter.DataPoints("pods"))
+	require.Empty(t, exporter.DataPoints("deployments"))
+
+	// failed exports are not captured
+	exporter.Err = errors.New("connection refused")
+	require.ErrorContains(t, mc.ExportMetrics(ctx), "connection refused")
+	require.Equal(t, 1, exporter.Exports())
+
+	exporter.Reset()
+	require.Zero(t, exporter.Exports())
+}
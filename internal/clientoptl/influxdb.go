@@ -0,0 +1,145 @@
+package clientoptl
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+
+	"github.com/openmcp-project/metrics-operator/internal/common"
+)
+
+// influxDBMaxLines is the number of lines written to InfluxDB in a single request, as recommended by InfluxDB
+const influxDBMaxLines = 5000
+
+var (
+	influxDBMeasurementEscaper = strings.NewReplacer(`\`, `\\`, ",", `\,`, " ", `\ `, "\n", `\ `, "\r", `\ `)
+	influxDBTagEscaper         = strings.NewReplacer(`\`, `\\`, ",", `\,`, "=", `\=`, " ", `\ `, "\n", `\ `, "\r", `\ `)
+)
+
+// influxDBExporter writes the data points in line protocol to the write API of InfluxDB v2.
+// Each metric is a measurement, its dimensions are tags and its value is the integer field "value".
+type influxDBExporter struct {
+	writeURL        string
+	token           string
+	compression     string
+	maxPayloadBytes int
+	client          *http.Client
+}
+
+// influxDBError is the body InfluxDB returns for failed writes
+type influxDBError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func newInfluxDBExporter(_ context.Context, credentials *common.DataSinkCredentials) (MetricsExporter, error) {
+	if credentials.InfluxDB == nil {
+		return nil, errors.New("the organization and bucket of InfluxDB are not configured")
+	}
+	endpoint, client, err := newHTTPExportClient(credentials, "InfluxDB")
+	if err != nil {
+		return nil, err
+	}
+
+	query := url.Values{}
+	query.Set("org", credentials.InfluxDB.Org)
+	query.Set("bucket", credentials.InfluxDB.Bucket)
+	query.Set("precision", "ms")
+	writeURL := endpoint.JoinPath("/api/v2/write")
+	writeURL.RawQuery = query.Encode()
+
+	exporter := &influxDBExporter{
+		writeURL:        writeURL.String(),
+		compression:     credentials.Compression,
+		maxPayloadBytes: credentials.MaxPayloadBytes,
+		client:          client,
+	}
+	if credentials.APIKey != nil {
+		exporter.token = credentials.APIKey.Token
+	}
+	return exporter, nil
+}
+
+// Export writes the data points in batches of at most influxDBMaxLines lines and the maximum payload size,
+// an empty export writes nothing
+func (e *influxDBExporter) Export(ctx context.Context, rm *metricdata.ResourceMetrics) error {
+	for _, batch := range lineBatches(influxDBLines(gaugeDataPoints(rm)), influxDBMaxLines, e.maxPayloadBytes) {
+		if err := e.write(ctx, batch); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *influxDBExporter) Shutdown(context.Context) error {
+	e.client.CloseIdleConnections()
+	return nil
+}
+
+// write posts the lines to the write API, InfluxDB accepts or rejects the request as a whole
+func (e *influxDBExporter) write(ctx context.Context, lines []string) error {
+	req, err := newPayloadRequest(ctx, e.writeURL, []byte(strings.Join(lines, "\n")), e.compression)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	if e.token != "" {
+		req.Header.Set("Authorization", "Token "+e.token)
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	writeError := influxDBError{}
+	message := string(bytes.TrimSpace(body))
+	if json.Unmarshal(body, &writeError) == nil && writeError.Message != "" {
+		message = writeError.Message
+	}
+	return rejected(resp.StatusCode, message, fmt.Errorf("influxdb write failed with %s: %s", resp.Status, message))
+}
+
+// influxDBLines converts the data points to lines of the line protocol,
+// dimensions with an empty value are left out because InfluxDB does not accept empty tag values
+func influxDBLines(points []gaugeDataPoint) []string {
+	lines := make([]string, 0, len(points))
+	for _, dp := range points {
+		tagKeys := make([]string, 0, len(dp.Dimensions))
+		for k, v := range dp.Dimensions {
+			if k != "" && v != "" {
+				tagKeys = append(tagKeys, k)
+			}
+		}
+		sort.Strings(tagKeys)
+
+		var line strings.Builder
+		line.WriteString(influxDBMeasurementEscaper.Replace(dp.Metric))
+		for _, k := range tagKeys {
+			line.WriteString(",")
+			line.WriteString(influxDBTagEscaper.Replace(k))
+			line.WriteString("=")
+			line.WriteString(influxDBTagEscaper.Replace(dp.Dimensions[k]))
+		}
+		fmt.Fprintf(&line, " value=%di", dp.Value)
+		if !dp.Time.IsZero() {
+			fmt.Fprintf(&line, " %d", dp.Time.UnixMilli())
+		}
+		lines = append(lines, line.String())
+	}
+	return lines
+}
@@ -0,0 +1,99 @@
+package clientoptl
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+
+	"github.com/openmcp-project/metrics-operator/internal/common"
+)
+
+func TestInfluxDBExporter(t *testing.T) {
+	now := time.UnixMilli(1735732800000)
+	rm := &metricdata.ResourceMetrics{ScopeMetrics: []metricdata.ScopeMetrics{{
+		Metrics: []metricdata.Metrics{{
+			Name: "pods running",
+			Data: metricdata.Gauge[int64]{DataPoints: []metricdata.DataPoint[int64]{
+				{Attributes: attribute.NewSet(attribute.String("namespace", "team-a"), attribute.String("phase", "Run,ning=1"), attribute.String("node", "")), Time: now, Value: 3},
+			}},
+		}},
+	}}}
+
+	testCases := []struct {
+		name          string
+		status        int
+		response      string
+		expectedError string
+	}{
+		{
+			name:   "Written",
+			status: http.StatusNoContent,
+		},
+		{
+			name:          "Rejected",
+			status:        http.StatusBadRequest,
+			response:      `{"code": "invalid", "message": "unable to parse 'pods': missing fields"}`,
+			expectedError: "influxdb write failed with 400 Bad Request: unable to parse 'pods': missing fields",
+		},
+		{
+			name:          "Unauthorized",
+			status:        http.StatusUnauthorized,
+			response:      "unauthorized access",
+			expectedError: "influxdb write failed with 401 Unauthorized: unauthorized access",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var body, authorization, path string
+			var query map[string][]string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				b, _ := io.ReadAll(r.Body)
+				body = string(b)
+				authorization = r.Header.Get("Authorization")
+				path = r.URL.Path
+				query = r.URL.Query()
+				w.WriteHeader(tc.status)
+				_, _ = w.Write([]byte(tc.response))
+			}))
+			defer server.Close()
+
+			exporter, err := newInfluxDBExporter(context.Background(), &common.DataSinkCredentials{
+				Host:     server.URL,
+				APIKey:   &common.APIKeyAuth{Token: "influx-token"},
+				InfluxDB: &common.InfluxDBTarget{Org: "platform", Bucket: "metrics"},
+			})
+			require.NoError(t, err)
+			defer func() { _ = exporter.Shutdown(context.Background()) }()
+
+			err = exporter.Export(context.Background(), rm)
+			if tc.expectedError != "" {
+				require.EqualError(t, err, tc.expectedError)
+			} else {
+				require.NoError(t, err)
+			}
+			require.Equal(t, "Token influx-token", authorization)
+			require.Equal(t, "/api/v2/write", path)
+			require.Equal(t, map[string][]string{"org": {"platform"}, "bucket": {"metrics"}, "precision": {"ms"}}, query)
+			require.Equal(t, `pods\ running,namespace=team-a,phase=Run\,ning\=1 value=3i 1735732800000`, body)
+		})
+	}
+}
+
+func TestNewInfluxDBExporter_invalid(t *testing.T) {
+	_, err := newInfluxDBExporter(context.Background(), &common.DataSinkCredentials{Host: "https://influx.example.com"})
+	require.EqualError(t, err, "the organization and bucket of InfluxDB are not configured")
+
+	_, err = newInfluxDBExporter(context.Background(), &common.DataSinkCredentials{
+		Host:     "grpc://influx.example.com",
+		InfluxDB: &common.InfluxDBTarget{Org: "platform", Bucket: "metrics"},
+	})
+	require.EqualError(t, err, "unsupported protocol scheme for InfluxDB, got grpc, want http|https")
+}
@@ -5,18 +5,26 @@ import (
 	"crypto/tls"
 	"crypto/x509"
 	"fmt"
+	"maps"
+	"net/http"
 	"net/url"
+	"time"
 
-	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
 	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
 	"go.opentelemetry.io/otel/metric"
 	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
 	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"golang.org/x/net/http/httpproxy"
+	"google.golang.org/grpc"
 	grpccredentials "google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/keepalive"
+	"sigs.k8s.io/controller-runtime/pkg/log"
 
+	"github.com/openmcp-project/metrics-operator/api/v1alpha1"
 	"github.com/openmcp-project/metrics-operator/internal/common"
+	internalmetrics "github.com/openmcp-project/metrics-operator/internal/metrics"
 )
 
 const (
@@ -24,13 +32,28 @@ const (
 	protocolOTLPHTTPSecure   = "https"
 	protocolOTLPGRPCInsecure = "grpc"
 	protocolOTLPGRPCSecure   = "grpcs"
+
+	compressionGzip = "gzip"
+
+	defaultRetryInitialInterval = 5 * time.Second
+	defaultRetryMaxInterval     = 30 * time.Second
+	defaultRetryMaxElapsedTime  = time.Minute
 )
 
 // MetricClient represents a metric client
 type MetricClient struct {
+	// meterProvider is owned by the client, so concurrent reconciles do not record into each other's reader
+	meterProvider   *sdkmetric.MeterProvider
 	meter           metric.Meter
 	manualReader    *sdkmetric.ManualReader
 	metricsExporter MetricsExporter
+
+	// breaker is shared by all metric clients exporting to the same data sink
+	breaker *CircuitBreaker
+
+	// dataSink is the namespace/name of the data sink, used to count the violations of its dimension policy
+	dataSink        string
+	dimensionPolicy *common.DimensionPolicy
 }
 
 // MetricsExporter is the common interface for metric exporters
@@ -66,6 +89,13 @@ type Metric struct {
 	// we just want to send the current value/state always, hence gauge metric
 	gauge          metric.Int64Gauge
 	prometheusFunc PrometheusRecordFunc
+	// staticDimensions are added to every recorded data point
+	staticDimensions map[string]string
+	// zero records 0 instead of the value of every data point
+	zero bool
+
+	dataSink        string
+	dimensionPolicy *common.DimensionPolicy
 }
 
 // SetPrometheusFunc sets a callback that is invoked for each recorded DataPoint.
@@ -73,6 +103,18 @@ func (mc *Metric) SetPrometheusFunc(fn PrometheusRecordFunc) {
 	mc.prometheusFunc = fn
 }
 
+// SetStaticDimensions sets dimensions that are added to every recorded data point.
+// Dimensions of the data points themselves are not overridden.
+func (mc *Metric) SetStaticDimensions(dimensions map[string]string) {
+	mc.staticDimensions = dimensions
+}
+
+// SetZero records 0 instead of the value of every data point,
+// used to end the series of a deleted metric with a final zero
+func (mc *Metric) SetZero(zero bool) {
+	mc.zero = zero
+}
+
 // DataPoint represents a single data point
 type DataPoint struct {
 	Dimensions map[string]string
@@ -98,24 +140,96 @@ func (dp *DataPoint) SetValue(value int64) *DataPoint {
 	return dp
 }
 
+// ExporterFactory creates the exporter for the credentials of a data sink.
+// The zero value creates the exporter registered for the type of the data sink,
+// tests replace it to capture the exported data points, e.g. with FakeExporter.Factory.
+type ExporterFactory func(ctx context.Context, credentials *common.DataSinkCredentials) (MetricsExporter, error)
+
 // NewMetricClient creates a new metric client.
 // If credentials is nil, a no-op client is returned that records nothing to OTLP.
 func NewMetricClient(ctx context.Context, credentials *common.DataSinkCredentials) (*MetricClient, error) {
-	manualReader := sdkmetric.NewManualReader()
+	return ExporterFactory(nil).NewMetricClient(ctx, credentials)
+}
+
+// NewMetricClient creates a new metric client exporting with an exporter of the factory.
+// If credentials is nil, a no-op client is returned that records nothing.
+func (f ExporterFactory) NewMetricClient(ctx context.Context, credentials *common.DataSinkCredentials) (*MetricClient, error) {
+	var otlpConfig *common.OTLPConfig
+	if credentials != nil {
+		otlpConfig = credentials.OTLP
+	}
+	// the exported data points are collected by the reader, so its selectors decide their temporality and aggregation
+	manualReader := sdkmetric.NewManualReader(
+		sdkmetric.WithTemporalitySelector(temporalitySelector(otlpConfig)),
+		sdkmetric.WithAggregationSelector(aggregationSelector(otlpConfig)),
+	)
 	mp := sdkmetric.NewMeterProvider(sdkmetric.WithReader(manualReader))
-	otel.SetMeterProvider(mp)
 
 	if credentials == nil {
 		return &MetricClient{
+			meterProvider:   mp,
 			manualReader:    manualReader,
 			metricsExporter: &noOpExporter{},
 		}, nil
 	}
 
-	deltaTemporalitySelector := func(sdkmetric.InstrumentKind) metricdata.Temporality {
-		return metricdata.DeltaTemporality
+	newExporter := f
+	if newExporter == nil {
+		newExporter = newRegisteredExporter
+	}
+	metricsExporter, err := newExporter(ctx, credentials)
+	if err != nil {
+		return nil, err
 	}
 
+	mc := &MetricClient{
+		meterProvider:   mp,
+		manualReader:    manualReader,
+		metricsExporter: metricsExporter,
+		dataSink:        credentials.Name,
+		dimensionPolicy: credentials.DimensionPolicy,
+	}
+	if credentials.Name != "" {
+		mc.breaker = SharedCircuitBreakers.For(credentials.Name)
+	}
+	return mc, nil
+}
+
+// temporalitySelector returns the temporality of the histograms exported to the data sink, cumulative by default
+func temporalitySelector(config *common.OTLPConfig) sdkmetric.TemporalitySelector {
+	if config != nil && config.Temporality == string(v1alpha1.TemporalityDelta) {
+		return func(sdkmetric.InstrumentKind) metricdata.Temporality {
+			return metricdata.DeltaTemporality
+		}
+	}
+	return sdkmetric.DefaultTemporalitySelector
+}
+
+// aggregationSelector returns the aggregation of the values recorded for the data sink,
+// by default the values of the gauges are exported as they are
+func aggregationSelector(config *common.OTLPConfig) sdkmetric.AggregationSelector {
+	if config == nil {
+		return sdkmetric.DefaultAggregationSelector
+	}
+	var aggregation sdkmetric.Aggregation
+	switch v1alpha1.OTLPAggregation(config.Aggregation) {
+	case v1alpha1.OTLPAggregationHistogram:
+		aggregation = sdkmetric.DefaultAggregationSelector(sdkmetric.InstrumentKindHistogram)
+		if len(config.HistogramBoundaries) > 0 {
+			aggregation = sdkmetric.AggregationExplicitBucketHistogram{Boundaries: config.HistogramBoundaries}
+		}
+	case v1alpha1.OTLPAggregationExponentialHistogram:
+		aggregation = sdkmetric.AggregationBase2ExponentialHistogram{MaxSize: 160, MaxScale: 20}
+	default:
+		return sdkmetric.DefaultAggregationSelector
+	}
+	return func(sdkmetric.InstrumentKind) sdkmetric.Aggregation {
+		return aggregation
+	}
+}
+
+// newMetricsExporter creates the OTLP exporter for the protocol of the data sink endpoint
+func newMetricsExporter(ctx context.Context, credentials *common.DataSinkCredentials) (MetricsExporter, error) {
 	// Parse the dtAPIHost URL to extract host and path components
 	// dtAPIHost is the full endpoint from DataSink, e.g., "https://.../otlp/v1/metrics"
 	parsedURL, err := url.Parse(credentials.Host)
@@ -125,23 +239,37 @@ func NewMetricClient(ctx context.Context, credentials *common.DataSinkCredential
 
 	var metricsExporter MetricsExporter
 	if isHTTPProtocol(parsedURL.Scheme) {
-		metricsExporter, err = newMetricsClientHttp(ctx, credentials, parsedURL, deltaTemporalitySelector)
+		metricsExporter, err = newMetricsClientHttp(ctx, credentials, parsedURL, temporalitySelector(credentials.OTLP))
 		if err != nil {
 			return nil, fmt.Errorf("failed to create HTTP metrics client: %w", err)
 		}
 	} else if isGRPCProtocol(parsedURL.Scheme) {
-		metricsExporter, err = newMetricsClientGrpc(ctx, credentials, parsedURL, deltaTemporalitySelector)
+		metricsExporter, err = newMetricsClientGrpc(ctx, credentials, parsedURL, temporalitySelector(credentials.OTLP))
 		if err != nil {
 			return nil, fmt.Errorf("failed to create gRPC metrics client: %w", err)
 		}
 	} else {
 		return nil, fmt.Errorf("unsupported protocol scheme, got %s, want http|https|grpc|grpcs", parsedURL.Scheme)
 	}
+	if credentials.MaxPayloadBytes > 0 {
+		metricsExporter = &payloadLimitExporter{MetricsExporter: metricsExporter, maxBytes: credentials.MaxPayloadBytes}
+	}
+	return metricsExporter, nil
+}
 
-	return &MetricClient{
-		manualReader:    manualReader,
-		metricsExporter: metricsExporter,
-	}, nil
+// Probe checks that the data sink is reachable and accepts the credentials by exporting an empty batch of metrics.
+// Unlike NewMetricClient, it does not replace the global meter provider.
+func Probe(ctx context.Context, credentials *common.DataSinkCredentials) error {
+	metricsExporter, err := newRegisteredExporter(ctx, credentials)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = metricsExporter.Shutdown(ctx) }()
+
+	if err := metricsExporter.Export(ctx, &metricdata.ResourceMetrics{}); err != nil {
+		return fmt.Errorf("failed to export metrics: %w", err)
+	}
+	return nil
 }
 
 // newMetricsClientHttp creates a new OTLP HTTP metrics exporter
@@ -158,22 +286,32 @@ func newMetricsClientHttp(ctx context.Context, credentials *common.DataSinkCrede
 		opts = append(opts, otlpmetrichttp.WithHeaders(authHeader))
 	}
 
-	if credentials.Certificate != nil {
-		tlsConfig, err := createTLSConfig(
-			credentials.Certificate.ClientCert,
-			credentials.Certificate.ClientKey,
-			credentials.Certificate.CACert,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("failed to create TLS config: %w", err)
-		}
+	tlsConfig, err := tlsConfigForDataSink(credentials)
+	if err != nil {
+		return nil, err
+	}
+	if tlsConfig != nil {
 		opts = append(opts, otlpmetrichttp.WithTLSClientConfig(tlsConfig))
 	}
+	if proxy := proxyForDataSink(credentials); proxy != nil {
+		opts = append(opts, otlpmetrichttp.WithProxy(func(req *http.Request) (*url.URL, error) {
+			return proxy(req.URL)
+		}))
+	}
 
 	// Add insecure option if scheme is http
 	if !isSecureProtocol(parsedURL.Scheme) {
 		opts = append(opts, otlpmetrichttp.WithInsecure())
 	}
+	if credentials.Compression == compressionGzip {
+		opts = append(opts, otlpmetrichttp.WithCompression(otlpmetrichttp.GzipCompression))
+	}
+	if credentials.Timeout > 0 {
+		opts = append(opts, otlpmetrichttp.WithTimeout(credentials.Timeout))
+	}
+	if credentials.Retry != nil {
+		opts = append(opts, otlpmetrichttp.WithRetry(otlpmetrichttp.RetryConfig(retryConfigForDataSink(credentials.Retry))))
+	}
 
 	metricsExporter, err := otlpmetrichttp.New(ctx, opts...)
 	if err != nil {
@@ -195,15 +333,11 @@ func newMetricsClientGrpc(ctx context.Context, credentials *common.DataSinkCrede
 		opts = append(opts, otlpmetricgrpc.WithHeaders(authHeader))
 	}
 
-	if credentials.Certificate != nil {
-		tlsConfig, err := createTLSConfig(
-			credentials.Certificate.ClientCert,
-			credentials.Certificate.ClientKey,
-			credentials.Certificate.CACert,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("failed to create TLS config: %w", err)
-		}
+	tlsConfig, err := tlsConfigForDataSink(credentials)
+	if err != nil {
+		return nil, err
+	}
+	if tlsConfig != nil {
 		tlsCredentials := grpccredentials.NewTLS(tlsConfig)
 		opts = append(opts, otlpmetricgrpc.WithTLSCredentials(tlsCredentials))
 	}
@@ -211,6 +345,21 @@ func newMetricsClientGrpc(ctx context.Context, credentials *common.DataSinkCrede
 	if !isSecureProtocol(parsedURL.Scheme) {
 		opts = append(opts, otlpmetricgrpc.WithInsecure())
 	}
+	if credentials.Compression == compressionGzip {
+		opts = append(opts, otlpmetricgrpc.WithCompressor(compressionGzip))
+	}
+	if credentials.Timeout > 0 {
+		opts = append(opts, otlpmetricgrpc.WithTimeout(credentials.Timeout))
+	}
+	if credentials.Retry != nil {
+		opts = append(opts, otlpmetricgrpc.WithRetry(otlpmetricgrpc.RetryConfig(retryConfigForDataSink(credentials.Retry))))
+	}
+	if credentials.GRPC != nil && credentials.GRPC.KeepaliveTime > 0 {
+		opts = append(opts, otlpmetricgrpc.WithDialOption(grpc.WithKeepaliveParams(keepalive.ClientParameters{
+			Time:    credentials.GRPC.KeepaliveTime,
+			Timeout: credentials.GRPC.KeepaliveTimeout,
+		})))
+	}
 
 	metricsExporter, err := otlpmetricgrpc.New(ctx, opts...)
 	if err != nil {
@@ -220,46 +369,130 @@ func newMetricsClientGrpc(ctx context.Context, credentials *common.DataSinkCrede
 	return metricsExporter, nil
 }
 
+// retryConfigForDataSink returns the backoff of failed OTLP exports, zero intervals are replaced by the defaults of the exporter
+func retryConfigForDataSink(retry *common.RetryConfig) otlpmetrichttp.RetryConfig {
+	config := otlpmetrichttp.RetryConfig{
+		Enabled:         retry.Enabled,
+		InitialInterval: retry.InitialInterval,
+		MaxInterval:     retry.MaxInterval,
+		MaxElapsedTime:  retry.MaxElapsedTime,
+	}
+	if config.InitialInterval <= 0 {
+		config.InitialInterval = defaultRetryInitialInterval
+	}
+	if config.MaxInterval <= 0 {
+		config.MaxInterval = defaultRetryMaxInterval
+	}
+	if config.MaxElapsedTime <= 0 {
+		config.MaxElapsedTime = defaultRetryMaxElapsedTime
+	}
+	return config
+}
+
 // SetMeter creates a new meter with the given name
 // A Meter is an interface for creating instruments (like counters, gauges, and histograms) that are used to record measurements.
 // Used to group related metrics together.
-func (mc *MetricClient) SetMeter(name string) {
-	mc.meter = otel.Meter(name)
+// The scope attributes are exported as attributes of the meter's instrumentation scope.
+func (mc *MetricClient) SetMeter(name string, scopeAttributes map[string]string) {
+	attrs := make([]attribute.KeyValue, 0, len(scopeAttributes))
+	for k, v := range scopeAttributes {
+		attrs = append(attrs, attribute.String(k, v))
+	}
+	mc.meter = mc.meterProvider.Meter(name, metric.WithInstrumentationAttributes(attrs...))
 }
 
-// NewMetric creates a new metric with the given name
-func (mc *MetricClient) NewMetric(name string) (*Metric, error) {
-	gauge, err := mc.meter.Int64Gauge(name)
+// NewMetric creates a new metric with the given name.
+// The description and unit are optional and annotate the metric in the backend.
+func (mc *MetricClient) NewMetric(name, description, unit string) (*Metric, error) {
+	gauge, err := mc.meter.Int64Gauge(name, metric.WithDescription(description), metric.WithUnit(unit))
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to create gauge metric: %w", err)
 	}
 
 	return &Metric{
-		gauge: gauge,
+		gauge:           gauge,
+		dataSink:        mc.dataSink,
+		dimensionPolicy: mc.dimensionPolicy,
 	}, nil
 }
 
-// RecordMetrics records the given series of data points
+// ApplyDimensionPolicy returns the data points with the dimension policy of the data sink applied, the given data points
+// are left unchanged. It is used for data points whose dimensions are encoded into a single dimension before they are recorded,
+// where the policy can no longer tell them apart.
+func (mc *Metric) ApplyDimensionPolicy(ctx context.Context, dataPoints []*DataPoint) []*DataPoint {
+	if mc.dimensionPolicy == nil {
+		return dataPoints
+	}
+	violations := dimensionViolations{}
+	applied := make([]*DataPoint, 0, len(dataPoints))
+	for _, dp := range dataPoints {
+		applied = append(applied, &DataPoint{
+			Dimensions: applyDimensionPolicy(mc.dimensionPolicy, dp.Dimensions, mc.violation(&violations)),
+			Value:      dp.Value,
+		})
+	}
+	mc.logViolations(ctx, &violations)
+	return applied
+}
+
+// violation returns the callback of applyDimensionPolicy that collects and counts the violations
+func (mc *Metric) violation(violations *dimensionViolations) func(action, key string) {
+	return func(action, key string) {
+		violations.add(action, key)
+		internalmetrics.DimensionPolicyViolations.WithLabelValues(mc.dataSink, key, action).Inc()
+	}
+}
+
+func (mc *Metric) logViolations(ctx context.Context, violations *dimensionViolations) {
+	if !violations.empty() {
+		log.FromContext(ctx).Info("dimension policy of the data sink applied",
+			"datasink", mc.dataSink, "dropped", violations.droppedKeys(), "redacted", violations.redactedKeys())
+	}
+}
+
+// RecordMetrics records the given series of data points.
+// The dimension policy of the data sink only applies to the exported data points, not to the Prometheus callback.
 func (mc *Metric) RecordMetrics(ctx context.Context, series ...*DataPoint) error {
+	violations := dimensionViolations{}
+	violation := mc.violation(&violations)
 
 	for _, s := range series {
-		attrs := make([]attribute.KeyValue, 0, len(s.Dimensions))
-		for k, v := range s.Dimensions {
+		dimensions := mc.withStaticDimensions(s.Dimensions)
+		exported := applyDimensionPolicy(mc.dimensionPolicy, dimensions, violation)
+		attrs := make([]attribute.KeyValue, 0, len(exported))
+		for k, v := range exported {
 			attrs = append(attrs, attribute.String(k, v))
 		}
 
-		mc.gauge.Record(ctx, s.Value, metric.WithAttributes(attrs...))
+		value := s.Value
+		if mc.zero {
+			value = 0
+		}
+		mc.gauge.Record(ctx, value, metric.WithAttributes(attrs...))
 
 		if mc.prometheusFunc != nil {
-			mc.prometheusFunc(s.Dimensions, s.Value)
+			mc.prometheusFunc(dimensions, value)
 		}
 	}
 
+	mc.logViolations(ctx, &violations)
 	return nil
 }
 
-// ExportMetrics sends the collected metrics to the exporter
+// withStaticDimensions returns the dimensions of a data point with the static dimensions added,
+// the dimensions of the data point are left unchanged
+func (mc *Metric) withStaticDimensions(dimensions map[string]string) map[string]string {
+	if len(mc.staticDimensions) == 0 {
+		return dimensions
+	}
+	merged := maps.Clone(mc.staticDimensions)
+	maps.Copy(merged, dimensions)
+	return merged
+}
+
+// ExportMetrics sends the collected metrics to the exporter.
+// While the circuit breaker of the data sink is open, the metrics are dropped and ErrCircuitOpen is returned.
 func (mc *MetricClient) ExportMetrics(ctx context.Context) error {
 	resourceMetrics := metricdata.ResourceMetrics{}
 	err := mc.manualReader.Collect(ctx, &resourceMetrics)
@@ -267,19 +500,87 @@ func (mc *MetricClient) ExportMetrics(ctx context.Context) error {
 		return fmt.Errorf("failed to collect metrics: %w", err)
 	}
 
+	if mc.breaker != nil {
+		if err := mc.breaker.Allow(); err != nil {
+			return err
+		}
+	}
+
 	err = mc.metricsExporter.Export(ctx, &resourceMetrics)
+	if mc.breaker != nil {
+		mc.breaker.Record(err)
+	}
 	if err != nil {
+		if reason := Rejection(err); reason != "" {
+			internalmetrics.DataSinkRejectedExports.WithLabelValues(mc.dataSink, string(reason)).Inc()
+		}
 		return fmt.Errorf("failed to export metrics: %w", err)
 	}
 
 	return nil
 }
 
+// DataSink returns the namespace/name of the data sink the client exports to, empty for the no-op client
+func (mc *MetricClient) DataSink() string {
+	return mc.dataSink
+}
+
 // Close shuts down the metric client
 func (mc *MetricClient) Close(ctx context.Context) error {
 	return mc.metricsExporter.Shutdown(ctx)
 }
 
+// tlsConfigForDataSink creates the TLS config with the client certificate and the CA bundle of the data sink,
+// nil if the data sink has neither
+func tlsConfigForDataSink(credentials *common.DataSinkCredentials) (*tls.Config, error) {
+	var tlsConfig *tls.Config
+	if credentials.Certificate != nil {
+		var err error
+		tlsConfig, err = createTLSConfig(
+			credentials.Certificate.ClientCert,
+			credentials.Certificate.ClientKey,
+			credentials.Certificate.CACert,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create TLS config: %w", err)
+		}
+	}
+	if len(credentials.CABundle) == 0 {
+		return tlsConfig, nil
+	}
+
+	if tlsConfig == nil {
+		tlsConfig = &tls.Config{}
+	}
+	// the CA bundle is trusted in addition to the system CAs, or to the CA certificate of the client certificate
+	rootCAs := tlsConfig.RootCAs
+	if rootCAs == nil {
+		var err error
+		if rootCAs, err = x509.SystemCertPool(); err != nil {
+			rootCAs = x509.NewCertPool()
+		}
+	}
+	if !rootCAs.AppendCertsFromPEM(credentials.CABundle) {
+		return nil, fmt.Errorf("failed to append CA bundle: no PEM encoded certificates found")
+	}
+	tlsConfig.RootCAs = rootCAs
+	return tlsConfig, nil
+}
+
+// proxyForDataSink returns the proxy of the data sink for a request URL, with NO_PROXY semantics for its NoProxy list.
+// It returns nil if the data sink has no proxy, then the proxy of the environment is used.
+func proxyForDataSink(credentials *common.DataSinkCredentials) func(*url.URL) (*url.URL, error) {
+	if credentials.Proxy == nil {
+		return nil
+	}
+	config := httpproxy.Config{
+		HTTPProxy:  credentials.Proxy.URL,
+		HTTPSProxy: credentials.Proxy.URL,
+		NoProxy:    credentials.Proxy.NoProxy,
+	}
+	return config.ProxyFunc()
+}
+
 func createTLSConfig(clientCert, clientKey, caCert []byte) (*tls.Config, error) {
 	// Load client certificate and key
 	cert, err := tls.X509KeyPair(clientCert, clientKey)
@@ -0,0 +1,274 @@
+package clientoptl
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+
+	"github.com/openmcp-project/metrics-operator/internal/common"
+)
+
+func TestMetric_RecordMetrics_staticDimensions(t *testing.T) {
+	ctx := context.Background()
+	mc, err := NewMetricClient(ctx, nil)
+	require.NoError(t, err)
+	mc.SetMeter("metric", nil)
+
+	gauge, err := mc.NewMetric("pods", "", "")
+	require.NoError(t, err)
+	var recorded map[string]string
+	gauge.SetPrometheusFunc(func(dimensions map[string]string, _ int64) {
+		recorded = dimensions
+	})
+	gauge.SetStaticDimensions(map[string]string{"tenantId": "t-42", "cluster": "static"})
+
+	dp := NewDataPoint().AddDimension("cluster", "prod-eu").SetValue(3)
+	require.NoError(t, gauge.RecordMetrics(ctx, dp))
+
+	// the dimensions of the data point take precedence and are left unchanged
+	expected := map[string]string{"tenantId": "t-42", "cluster": "prod-eu"}
+	require.Equal(t, expected, recorded)
+	require.Equal(t, map[string]string{"cluster": "prod-eu"}, dp.Dimensions)
+
+	rm := metricdata.ResourceMetrics{}
+	require.NoError(t, mc.manualReader.Collect(ctx, &rm))
+	require.Len(t, rm.ScopeMetrics, 1)
+	points := rm.ScopeMetrics[0].Metrics[0].Data.(metricdata.Gauge[int64]).DataPoints
+	require.Len(t, points, 1)
+	require.Equal(t, attribute.NewSet(attribute.String("tenantId", "t-42"), attribute.String("cluster", "prod-eu")), points[0].Attributes)
+}
+
+func TestMetric_RecordMetrics_dimensionPolicy(t *testing.T) {
+	ctx := context.Background()
+	mc, err := NewMetricClient(ctx, nil)
+	require.NoError(t, err)
+	mc.SetMeter("metric", nil)
+	mc.dataSink = "metrics/default"
+	mc.dimensionPolicy = &common.DimensionPolicy{
+		DeniedKeys: map[string]bool{"name": true},
+		Redactions: []common.DimensionRedaction{
+			{Keys: map[string]bool{"namespace": true}, Pattern: regexp.MustCompile(`^team-.*$`), Replacement: "REDACTED"},
+		},
+	}
+
+	gauge, err := mc.NewMetric("pods", "", "")
+	require.NoError(t, err)
+	var recorded map[string]string
+	gauge.SetPrometheusFunc(func(dimensions map[string]string, _ int64) {
+		recorded = dimensions
+	})
+
+	dp := NewDataPoint().AddDimension("name", "web-0").AddDimension("namespace", "team-a").AddDimension("kind", "Pod").SetValue(1)
+	require.NoError(t, gauge.RecordMetrics(ctx, dp))
+
+	// the policy applies to the exported data points only
+	require.Equal(t, map[string]string{"name": "web-0", "namespace": "team-a", "kind": "Pod"}, recorded)
+
+	rm := metricdata.ResourceMetrics{}
+	require.NoError(t, mc.manualReader.Collect(ctx, &rm))
+	points := rm.ScopeMetrics[0].Metrics[0].Data.(metricdata.Gauge[int64]).DataPoints
+	require.Len(t, points, 1)
+	require.Equal(t, attribute.NewSet(attribute.String("namespace", "REDACTED"), attribute.String("kind", "Pod")), points[0].Attributes)
+}
+
+func TestMetricClient_NewMetric_descriptionAndUnit(t *testing.T) {
+	ctx := context.Background()
+	mc, err := NewMetricClient(ctx, nil)
+	require.NoError(t, err)
+	mc.SetMeter("metric", nil)
+
+	gauge, err := mc.NewMetric("pods", "Pods per namespace", "1")
+	require.NoError(t, err)
+	require.NoError(t, gauge.RecordMetrics(ctx, NewDataPoint().AddDimension("namespace", "team-a").SetValue(3)))
+
+	rm := metricdata.ResourceMetrics{}
+	require.NoError(t, mc.manualReader.Collect(ctx, &rm))
+	require.Len(t, rm.ScopeMetrics, 1)
+	require.Equal(t, "pods", rm.ScopeMetrics[0].Metrics[0].Name)
+	require.Equal(t, "Pods per namespace", rm.ScopeMetrics[0].Metrics[0].Description)
+	require.Equal(t, "1", rm.ScopeMetrics[0].Metrics[0].Unit)
+}
+
+func TestNewMetricsExporter_proxy(t *testing.T) {
+	testCases := []struct {
+		name         string
+		noProxy      string
+		expectedHost string
+	}{
+		{
+			name:         "Proxied",
+			expectedHost: "collector.invalid",
+		},
+		{
+			name:    "NoProxy",
+			noProxy: "other.example.com,.invalid",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			proxiedHost := ""
+			proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				proxiedHost = r.Host
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer proxy.Close()
+
+			exporter, err := newMetricsExporter(context.Background(), &common.DataSinkCredentials{
+				Host:  "http://collector.invalid/otlp/v1/metrics",
+				Proxy: &common.ProxyConfig{URL: proxy.URL, NoProxy: tc.noProxy},
+			})
+			require.NoError(t, err)
+			defer func() { _ = exporter.Shutdown(context.Background()) }()
+
+			err = exporter.Export(context.Background(), &metricdata.ResourceMetrics{})
+			if tc.expectedHost != "" {
+				require.NoError(t, err)
+			} else {
+				// the collector is reached directly, which fails in the test
+				require.Error(t, err)
+			}
+			require.Equal(t, tc.expectedHost, proxiedHost)
+		})
+	}
+}
+
+func TestNewMetricsExporter_compressionAndRetry(t *testing.T) {
+	testCases := []struct {
+		name             string
+		compression      string
+		retry            *common.RetryConfig
+		expectedEncoding string
+		expectedRequests int
+	}{
+		{
+			name:             "Gzip",
+			compression:      "gzip",
+			retry:            &common.RetryConfig{Enabled: false},
+			expectedEncoding: "gzip",
+			expectedRequests: 1,
+		},
+		{
+			name:             "Retried",
+			retry:            &common.RetryConfig{Enabled: true, InitialInterval: time.Millisecond, MaxInterval: time.Millisecond, MaxElapsedTime: time.Second},
+			expectedRequests: 3,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			requests := 0
+			encoding := ""
+			collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests++
+				encoding = r.Header.Get("Content-Encoding")
+				if requests < tc.expectedRequests {
+					w.WriteHeader(http.StatusServiceUnavailable)
+					return
+				}
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer collector.Close()
+
+			exporter, err := newMetricsExporter(context.Background(), &common.DataSinkCredentials{
+				Host:        collector.URL + "/v1/metrics",
+				Compression: tc.compression,
+				Retry:       tc.retry,
+			})
+			require.NoError(t, err)
+			defer func() { _ = exporter.Shutdown(context.Background()) }()
+
+			require.NoError(t, exporter.Export(context.Background(), &metricdata.ResourceMetrics{}))
+			require.Equal(t, tc.expectedRequests, requests)
+			require.Equal(t, tc.expectedEncoding, encoding)
+		})
+	}
+}
+
+func TestNewMetricsExporter_grpc(t *testing.T) {
+	exporter, err := newMetricsExporter(context.Background(), &common.DataSinkCredentials{
+		Host:        "grpc://collector.invalid:4317",
+		Compression: "gzip",
+		Retry:       &common.RetryConfig{Enabled: false},
+		GRPC:        &common.GRPCConfig{KeepaliveTime: 30 * time.Second, KeepaliveTimeout: 20 * time.Second},
+	})
+	require.NoError(t, err)
+	require.NoError(t, exporter.Shutdown(context.Background()))
+}
+
+func TestRetryConfigForDataSink(t *testing.T) {
+	config := retryConfigForDataSink(&common.RetryConfig{Enabled: true, MaxInterval: 10 * time.Second})
+	require.True(t, config.Enabled)
+	require.Equal(t, defaultRetryInitialInterval, config.InitialInterval)
+	require.Equal(t, 10*time.Second, config.MaxInterval)
+	require.Equal(t, defaultRetryMaxElapsedTime, config.MaxElapsedTime)
+}
+
+func TestMetricClient_otlpConfig(t *testing.T) {
+	testCases := []struct {
+		name   string
+		config *common.OTLPConfig
+		verify func(t *testing.T, data metricdata.Aggregation)
+	}{
+		{
+			name: "defaults export gauges",
+			verify: func(t *testing.T, data metricdata.Aggregation) {
+				require.IsType(t, metricdata.Gauge[int64]{}, data)
+			},
+		},
+		{
+			name:   "delta histogram",
+			config: &common.OTLPConfig{Temporality: "Delta", Aggregation: "Histogram"},
+			verify: func(t *testing.T, data metricdata.Aggregation) {
+				histogram, ok := data.(metricdata.Histogram[int64])
+				require.True(t, ok)
+				require.Equal(t, metricdata.DeltaTemporality, histogram.Temporality)
+				require.Equal(t, uint64(2), histogram.DataPoints[0].Count)
+			},
+		},
+		{
+			name:   "histogram with boundaries",
+			config: &common.OTLPConfig{Aggregation: "Histogram", HistogramBoundaries: []float64{1, 10}},
+			verify: func(t *testing.T, data metricdata.Aggregation) {
+				histogram, ok := data.(metricdata.Histogram[int64])
+				require.True(t, ok)
+				require.Equal(t, metricdata.CumulativeTemporality, histogram.Temporality)
+				require.Equal(t, []float64{1, 10}, histogram.DataPoints[0].Bounds)
+				require.Equal(t, []uint64{0, 2, 0}, histogram.DataPoints[0].BucketCounts)
+			},
+		},
+		{
+			name:   "exponential histogram",
+			config: &common.OTLPConfig{Aggregation: "ExponentialHistogram"},
+			verify: func(t *testing.T, data metricdata.Aggregation) {
+				require.IsType(t, metricdata.ExponentialHistogram[int64]{}, data)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			exporter := NewFakeExporter()
+			mc, err := exporter.Factory().NewMetricClient(ctx, &common.DataSinkCredentials{Host: "https://otlp.example.com", OTLP: tc.config})
+			require.NoError(t, err)
+			mc.SetMeter("metric", nil)
+
+			gauge, err := mc.NewMetric("pods", "", "")
+			require.NoError(t, err)
+			require.NoError(t, gauge.RecordMetrics(ctx, NewDataPoint().SetValue(2), NewDataPoint().SetValue(3)))
+
+			rm := metricdata.ResourceMetrics{}
+			require.NoError(t, mc.manualReader.Collect(ctx, &rm))
+			require.Len(t, rm.ScopeMetrics, 1)
+			tc.verify(t, rm.ScopeMetrics[0].Metrics[0].Data)
+		})
+	}
+}
@@ -0,0 +1,155 @@
+package clientoptl
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"net/http"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+const (
+	// estimatedFieldOverhead approximates the tag and length bytes of an encoded protobuf field
+	estimatedFieldOverhead = 4
+	// estimatedDataPointOverhead approximates the timestamps, value and framing of an encoded data point
+	estimatedDataPointOverhead = 32
+)
+
+// newPayloadRequest creates the POST request of a payload, compressed with gzip if the compression is gzip
+func newPayloadRequest(ctx context.Context, endpoint string, payload []byte, compression string) (*http.Request, error) {
+	if compression == compressionGzip {
+		compressed := bytes.Buffer{}
+		writer := gzip.NewWriter(&compressed)
+		if _, err := writer.Write(payload); err != nil {
+			return nil, err
+		}
+		if err := writer.Close(); err != nil {
+			return nil, err
+		}
+		payload = compressed.Bytes()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	if compression == compressionGzip {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+	return req, nil
+}
+
+// lineBatches splits lines into batches of at most maxLines lines.
+// If maxBytes is positive, the lines of a batch joined by newlines are at most maxBytes long,
+// only a single line longer than maxBytes is sent in a batch on its own.
+func lineBatches(lines []string, maxLines, maxBytes int) [][]string {
+	var batches [][]string
+	start, size := 0, 0
+	for i, line := range lines {
+		full := i-start == maxLines || (maxBytes > 0 && i > start && size+1+len(line) > maxBytes)
+		if full {
+			batches = append(batches, lines[start:i])
+			start, size = i, 0
+		}
+		if i > start {
+			size++
+		}
+		size += len(line)
+	}
+	if start < len(lines) {
+		batches = append(batches, lines[start:])
+	}
+	return batches
+}
+
+// payloadLimitExporter splits exports whose estimated OTLP payload is larger than maxBytes into multiple exports,
+// so high-cardinality metrics are not rejected by the request size limit of the collector
+type payloadLimitExporter struct {
+	MetricsExporter
+	maxBytes int
+}
+
+// Export exports the parts of the metrics one after another, the first failing part fails the export
+func (e *payloadLimitExporter) Export(ctx context.Context, rm *metricdata.ResourceMetrics) error {
+	for _, part := range splitResourceMetrics(rm, e.maxBytes) {
+		if err := e.MetricsExporter.Export(ctx, part); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// splitResourceMetrics splits the data points of the int64 gauges into parts whose estimated encoded size is at most maxBytes.
+// Each part keeps the resource, scopes and metrics of its data points; a part holds at least one data point.
+// Metrics of other types are kept in the first part.
+func splitResourceMetrics(rm *metricdata.ResourceMetrics, maxBytes int) []*metricdata.ResourceMetrics {
+	resourceSize := estimatedAttributesSize(rm.Resource.Set())
+
+	var parts []*metricdata.ResourceMetrics
+	var part *metricdata.ResourceMetrics
+	var others []metricdata.ScopeMetrics
+	var size, dataPoints int
+	newPart := func() {
+		part = &metricdata.ResourceMetrics{Resource: rm.Resource}
+		parts = append(parts, part)
+		size, dataPoints = resourceSize, 0
+	}
+	newPart()
+
+	for _, sm := range rm.ScopeMetrics {
+		scopeSize := estimatedFieldOverhead + len(sm.Scope.Name) + len(sm.Scope.Version) + estimatedAttributesSize(&sm.Scope.Attributes)
+		var scope *metricdata.ScopeMetrics
+		for _, m := range sm.Metrics {
+			gauge, ok := m.Data.(metricdata.Gauge[int64])
+			if !ok {
+				others = append(others, metricdata.ScopeMetrics{Scope: sm.Scope, Metrics: []metricdata.Metrics{m}})
+				continue
+			}
+
+			metricSize := estimatedFieldOverhead + len(m.Name) + len(m.Description) + len(m.Unit)
+			var points *metricdata.Metrics
+			for _, dp := range gauge.DataPoints {
+				pointSize := estimatedDataPointOverhead + estimatedAttributesSize(&dp.Attributes)
+				added := pointSize
+				if scope == nil {
+					added += scopeSize
+				}
+				if points == nil {
+					added += metricSize
+				}
+				if dataPoints > 0 && size+added > maxBytes {
+					newPart()
+					scope, points = nil, nil
+				}
+				if scope == nil {
+					part.ScopeMetrics = append(part.ScopeMetrics, metricdata.ScopeMetrics{Scope: sm.Scope})
+					scope = &part.ScopeMetrics[len(part.ScopeMetrics)-1]
+					size += scopeSize
+				}
+				if points == nil {
+					scope.Metrics = append(scope.Metrics, metricdata.Metrics{Name: m.Name, Description: m.Description, Unit: m.Unit, Data: metricdata.Gauge[int64]{}})
+					points = &scope.Metrics[len(scope.Metrics)-1]
+					size += metricSize
+				}
+				data := points.Data.(metricdata.Gauge[int64])
+				data.DataPoints = append(data.DataPoints, dp)
+				points.Data = data
+				size += pointSize
+				dataPoints++
+			}
+		}
+	}
+	parts[0].ScopeMetrics = append(parts[0].ScopeMetrics, others...)
+	return parts
+}
+
+// estimatedAttributesSize approximates the encoded size of the attributes as key-value pairs
+func estimatedAttributesSize(attrs *attribute.Set) int {
+	size := 0
+	for _, kv := range attrs.ToSlice() {
+		size += 2*estimatedFieldOverhead + len(kv.Key) + len(kv.Value.Emit())
+	}
+	return size
+}
@@ -0,0 +1,150 @@
+package clientoptl
+
+import (
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+
+	"github.com/openmcp-project/metrics-operator/internal/common"
+)
+
+func TestLineBatches(t *testing.T) {
+	testCases := []struct {
+		name            string
+		lines           []string
+		maxLines        int
+		maxBytes        int
+		expectedBatches [][]string
+	}{
+		{
+			name:            "Empty",
+			maxLines:        2,
+			expectedBatches: nil,
+		},
+		{
+			name:            "MaxLines",
+			lines:           []string{"a", "b", "c"},
+			maxLines:        2,
+			expectedBatches: [][]string{{"a", "b"}, {"c"}},
+		},
+		{
+			name:            "MaxBytes",
+			lines:           []string{"aaa", "bbb", "ccc"},
+			maxLines:        10,
+			maxBytes:        7,
+			expectedBatches: [][]string{{"aaa", "bbb"}, {"ccc"}},
+		},
+		{
+			name:            "LineLongerThanMaxBytes",
+			lines:           []string{"a", "bbbbbbbbbb", "c"},
+			maxLines:        10,
+			maxBytes:        5,
+			expectedBatches: [][]string{{"a"}, {"bbbbbbbbbb"}, {"c"}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expectedBatches, lineBatches(tc.lines, tc.maxLines, tc.maxBytes))
+		})
+	}
+}
+
+func TestSplitResourceMetrics(t *testing.T) {
+	var points []metricdata.DataPoint[int64]
+	for i := range 10 {
+		points = append(points, metricdata.DataPoint[int64]{Attributes: attribute.NewSet(attribute.String("bucket", fmt.Sprintf("bucket-%d", i))), Value: int64(i)})
+	}
+	rm := &metricdata.ResourceMetrics{ScopeMetrics: []metricdata.ScopeMetrics{{
+		Metrics: []metricdata.Metrics{
+			{Name: "buckets", Data: metricdata.Gauge[int64]{DataPoints: points}},
+			{Name: "histogram", Data: metricdata.Histogram[int64]{}},
+		},
+	}}}
+
+	testCases := []struct {
+		name          string
+		maxBytes      int
+		expectedParts int
+	}{
+		{
+			name:          "Fits",
+			maxBytes:      1 << 20,
+			expectedParts: 1,
+		},
+		{
+			name:          "Split",
+			maxBytes:      200,
+			expectedParts: 4,
+		},
+		{
+			name:          "DataPointLargerThanMaxBytes",
+			maxBytes:      1,
+			expectedParts: 10,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			parts := splitResourceMetrics(rm, tc.maxBytes)
+			require.Len(t, parts, tc.expectedParts)
+
+			var values []int64
+			for _, part := range parts {
+				for _, dp := range gaugeDataPoints(part) {
+					require.Equal(t, "buckets", dp.Metric)
+					values = append(values, dp.Value)
+				}
+			}
+			require.Equal(t, []int64{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}, values)
+			require.Equal(t, "histogram", parts[0].ScopeMetrics[len(parts[0].ScopeMetrics)-1].Metrics[0].Name)
+		})
+	}
+}
+
+func TestInfluxDBExporter_compressionAndMaxPayloadBytes(t *testing.T) {
+	var points []metricdata.DataPoint[int64]
+	for i := range 4 {
+		points = append(points, metricdata.DataPoint[int64]{Attributes: attribute.NewSet(attribute.String("bucket", fmt.Sprintf("bucket-%d", i))), Value: int64(i)})
+	}
+	rm := &metricdata.ResourceMetrics{ScopeMetrics: []metricdata.ScopeMetrics{{
+		Metrics: []metricdata.Metrics{{Name: "buckets", Data: metricdata.Gauge[int64]{DataPoints: points}}},
+	}}}
+
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "gzip", r.Header.Get("Content-Encoding"))
+		reader, err := gzip.NewReader(r.Body)
+		require.NoError(t, err)
+		body, err := io.ReadAll(reader)
+		require.NoError(t, err)
+		bodies = append(bodies, string(body))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	exporter, err := newInfluxDBExporter(context.Background(), &common.DataSinkCredentials{
+		Host:            server.URL,
+		InfluxDB:        &common.InfluxDBTarget{Org: "platform", Bucket: "metrics"},
+		Compression:     "gzip",
+		MaxPayloadBytes: 70,
+	})
+	require.NoError(t, err)
+	defer func() { _ = exporter.Shutdown(context.Background()) }()
+
+	require.NoError(t, exporter.Export(context.Background(), rm))
+	require.Len(t, bodies, 2)
+	for _, body := range bodies {
+		require.LessOrEqual(t, len(body), 70)
+		require.Len(t, strings.Split(body, "\n"), 2)
+	}
+}
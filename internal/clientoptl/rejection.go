@@ -0,0 +1,110 @@
+package clientoptl
+
+import (
+	"errors"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// RejectionReason classifies why a data sink rejected an export, it is used as the reason of the conditions
+// of the exporting metric and as a label of the rejected exports counter
+type RejectionReason string
+
+const (
+	// RejectionInvalidDimension is a data point rejected for a dimension key or value the data sink does not accept
+	RejectionInvalidDimension RejectionReason = "InvalidDimension"
+	// RejectionQuotaExceeded is an export rejected because a quota or rate limit of the data sink is exhausted
+	RejectionQuotaExceeded RejectionReason = "QuotaExceeded"
+	// RejectionUnauthorized is an export rejected because the credentials are missing, invalid or lack a permission
+	RejectionUnauthorized RejectionReason = "Unauthorized"
+)
+
+var (
+	// otlpHTTPStatus matches the status of the errors of the OTLP HTTP exporter,
+	// e.g. "failed to send metrics to https://otlp/v1/metrics: 401 Unauthorized (body: ...)"
+	otlpHTTPStatus = regexp.MustCompile(`: (\d{3}) [^(]*\((body: .*)\)`)
+
+	quotaMessages     = []string{"quota", "limit exceeded", "limit has been reached", "limit reached", "rate limit", "too many requests"}
+	dimensionMessages = []string{"dimension", "tag key", "label name", "attribute"}
+)
+
+// RejectedError is an export the data sink rejected for a known reason
+type RejectedError struct {
+	Reason RejectionReason
+	err    error
+}
+
+func (e *RejectedError) Error() string {
+	return e.err.Error()
+}
+
+func (e *RejectedError) Unwrap() error {
+	return e.err
+}
+
+// rejected returns err as a RejectedError if the status code or message of the response of the data sink
+// tell why it rejected the export, otherwise err itself
+func rejected(statusCode int, message string, err error) error {
+	if reason := classifyRejection(statusCode, message); reason != "" {
+		return &RejectedError{Reason: reason, err: err}
+	}
+	return err
+}
+
+// classifyRejection returns the reason of a rejection from the HTTP status code and the message of the response,
+// or an empty reason if neither is known
+func classifyRejection(statusCode int, message string) RejectionReason {
+	message = strings.ToLower(message)
+	switch {
+	case statusCode == http.StatusUnauthorized || statusCode == http.StatusForbidden:
+		return RejectionUnauthorized
+	case statusCode == http.StatusTooManyRequests || (statusCode != http.StatusRequestEntityTooLarge && containsAny(message, quotaMessages)):
+		return RejectionQuotaExceeded
+	case (statusCode == 0 || statusCode == http.StatusBadRequest || statusCode == http.StatusUnprocessableEntity) && containsAny(message, dimensionMessages):
+		return RejectionInvalidDimension
+	}
+	return ""
+}
+
+// Rejection returns why the data sink rejected the export that failed with err,
+// or an empty reason if the export failed for another or an unknown reason
+func Rejection(err error) RejectionReason {
+	if err == nil {
+		return ""
+	}
+	var rejectedErr *RejectedError
+	if errors.As(err, &rejectedErr) {
+		return rejectedErr.Reason
+	}
+	if s, ok := status.FromError(err); ok && s.Code() != codes.OK && s.Code() != codes.Unknown {
+		switch s.Code() {
+		case codes.Unauthenticated, codes.PermissionDenied:
+			return RejectionUnauthorized
+		case codes.ResourceExhausted:
+			return RejectionQuotaExceeded
+		case codes.InvalidArgument:
+			return classifyRejection(http.StatusBadRequest, s.Message())
+		}
+		return ""
+	}
+	if match := otlpHTTPStatus.FindStringSubmatch(err.Error()); match != nil {
+		statusCode, _ := strconv.Atoi(match[1])
+		return classifyRejection(statusCode, match[2])
+	}
+	return ""
+}
+
+// containsAny reports whether s contains any of the substrings
+func containsAny(s string, substrings []string) bool {
+	for _, substring := range substrings {
+		if strings.Contains(s, substring) {
+			return true
+		}
+	}
+	return false
+}
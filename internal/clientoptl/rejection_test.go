@@ -0,0 +1,65 @@
+package clientoptl
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestRejection(t *testing.T) {
+	testCases := []struct {
+		name     string
+		err      error
+		expected RejectionReason
+	}{
+		{name: "Nil"},
+		{name: "Unknown", err: errors.New("connection refused")},
+		{
+			name:     "Rejected",
+			err:      fmt.Errorf("failed to export metrics: %w", rejected(403, "forbidden", errors.New("webhook failed with 403 Forbidden"))),
+			expected: RejectionUnauthorized,
+		},
+		{name: "NotRejected", err: rejected(500, "internal error", errors.New("webhook failed with 500"))},
+		{name: "PayloadTooLarge", err: rejected(413, "payload size limit exceeded", errors.New("webhook failed with 413"))},
+		{
+			name:     "OTLPHTTPUnauthorized",
+			err:      errors.New("failed to send metrics to https://otlp.example.com/v1/metrics: 401 Unauthorized (body: invalid token)"),
+			expected: RejectionUnauthorized,
+		},
+		{
+			name:     "OTLPHTTPInvalidDimension",
+			err:      errors.New("failed to send metrics to https://otlp.example.com/v1/metrics: 400 Bad Request (body: attribute key must not be empty)"),
+			expected: RejectionInvalidDimension,
+		},
+		{
+			name: "OTLPHTTPBadRequest",
+			err:  errors.New("failed to send metrics to https://otlp.example.com/v1/metrics: 400 Bad Request (body: malformed payload)"),
+		},
+		{
+			name:     "GRPCQuotaExceeded",
+			err:      fmt.Errorf("failed to upload metrics: %w", status.Error(codes.ResourceExhausted, "ingest quota exhausted")),
+			expected: RejectionQuotaExceeded,
+		},
+		{
+			name:     "GRPCUnauthenticated",
+			err:      status.Error(codes.Unauthenticated, "missing token"),
+			expected: RejectionUnauthorized,
+		},
+		{
+			name:     "GRPCInvalidDimension",
+			err:      status.Error(codes.InvalidArgument, "invalid dimension key"),
+			expected: RejectionInvalidDimension,
+		},
+		{name: "GRPCUnavailable", err: status.Error(codes.Unavailable, "connection refused")},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, Rejection(tc.err))
+		})
+	}
+}
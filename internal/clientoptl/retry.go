@@ -0,0 +1,106 @@
+package clientoptl
+
+import (
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// retryTransport retries requests that failed transiently with exponential backoff, in place of the reconcile requeue.
+// Rate limited and unavailable responses (429, 502, 503, 504) and timeouts are retried,
+// waiting as long as the Retry-After header of the response asks for. Once the maximum elapsed time would be exceeded,
+// the last response or error is returned, so only persistent failures reach the controller.
+type retryTransport struct {
+	next   http.RoundTripper
+	config otlpmetrichttp.RetryConfig
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !t.config.Enabled || (req.Body != nil && req.GetBody == nil) {
+		return t.next.RoundTrip(req)
+	}
+
+	start := time.Now()
+	interval := t.config.InitialInterval
+	for attempt := 1; ; attempt++ {
+		resp, err := t.next.RoundTrip(req)
+		if !isRetryable(resp, err) {
+			return resp, err
+		}
+
+		wait := retryAfter(resp, time.Now())
+		if wait == 0 {
+			wait = interval
+			interval = min(2*interval, t.config.MaxInterval)
+		}
+		if time.Since(start)+wait > t.config.MaxElapsedTime {
+			return resp, err
+		}
+		if resp != nil {
+			_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+			_ = resp.Body.Close()
+		}
+		log.FromContext(req.Context()).V(1).Info("retrying export", "url", req.URL.Redacted(), "attempt", attempt, "wait", wait)
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+
+		retry := req.Clone(req.Context())
+		if req.Body != nil {
+			if retry.Body, err = req.GetBody(); err != nil {
+				return nil, err
+			}
+		}
+		req = retry
+	}
+}
+
+// CloseIdleConnections closes the idle connections of the wrapped transport, called by http.Client.CloseIdleConnections
+func (t *retryTransport) CloseIdleConnections() {
+	if closer, ok := t.next.(interface{ CloseIdleConnections() }); ok {
+		closer.CloseIdleConnections()
+	}
+}
+
+// isRetryable reports whether the response or error of a request is transient
+func isRetryable(resp *http.Response, err error) bool {
+	if err != nil {
+		var netErr net.Error
+		return errors.As(err, &netErr) && netErr.Timeout()
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// retryAfter returns the time the Retry-After header of the response asks to wait, in seconds or until a date,
+// or zero if the response has no valid header
+func retryAfter(resp *http.Response, now time.Time) time.Duration {
+	if resp == nil {
+		return 0
+	}
+	header := resp.Header.Get("Retry-After")
+	if header == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(header); err == nil {
+		return max(time.Duration(seconds)*time.Second, 0)
+	}
+	if date, err := http.ParseTime(header); err == nil {
+		return max(date.Sub(now), 0)
+	}
+	return 0
+}
@@ -0,0 +1,128 @@
+package clientoptl
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
+)
+
+func TestRetryTransport(t *testing.T) {
+	testCases := []struct {
+		name             string
+		config           otlpmetrichttp.RetryConfig
+		failures         int
+		status           int
+		expectedStatus   int
+		expectedRequests int
+	}{
+		{
+			name:             "Retried",
+			config:           otlpmetrichttp.RetryConfig{Enabled: true, InitialInterval: time.Millisecond, MaxInterval: time.Millisecond, MaxElapsedTime: time.Second},
+			failures:         2,
+			status:           http.StatusTooManyRequests,
+			expectedStatus:   http.StatusOK,
+			expectedRequests: 3,
+		},
+		{
+			name:             "Persistent",
+			config:           otlpmetrichttp.RetryConfig{Enabled: true, InitialInterval: 20 * time.Millisecond, MaxInterval: 20 * time.Millisecond, MaxElapsedTime: 50 * time.Millisecond},
+			failures:         10,
+			status:           http.StatusServiceUnavailable,
+			expectedStatus:   http.StatusServiceUnavailable,
+			expectedRequests: 3,
+		},
+		{
+			name:             "NotRetryable",
+			config:           otlpmetrichttp.RetryConfig{Enabled: true, InitialInterval: time.Millisecond, MaxInterval: time.Millisecond, MaxElapsedTime: time.Second},
+			failures:         1,
+			status:           http.StatusBadRequest,
+			expectedStatus:   http.StatusBadRequest,
+			expectedRequests: 1,
+		},
+		{
+			name:             "Disabled",
+			config:           otlpmetrichttp.RetryConfig{Enabled: false},
+			failures:         1,
+			status:           http.StatusServiceUnavailable,
+			expectedStatus:   http.StatusServiceUnavailable,
+			expectedRequests: 1,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var bodies []string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+				bodies = append(bodies, string(body))
+				if len(bodies) <= tc.failures {
+					w.WriteHeader(tc.status)
+					return
+				}
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer server.Close()
+
+			client := &http.Client{Transport: &retryTransport{next: http.DefaultTransport, config: tc.config}}
+			resp, err := client.Post(server.URL, "text/plain", strings.NewReader("payload"))
+			require.NoError(t, err)
+			_ = resp.Body.Close()
+			require.Equal(t, tc.expectedStatus, resp.StatusCode)
+			require.Len(t, bodies, tc.expectedRequests)
+			for _, body := range bodies {
+				require.Equal(t, "payload", body)
+			}
+		})
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name     string
+		header   string
+		expected time.Duration
+	}{
+		{
+			name:     "Missing",
+			expected: 0,
+		},
+		{
+			name:     "Seconds",
+			header:   "30",
+			expected: 30 * time.Second,
+		},
+		{
+			name:     "Date",
+			header:   now.Add(time.Minute).Format(http.TimeFormat),
+			expected: time.Minute,
+		},
+		{
+			name:     "PastDate",
+			header:   now.Add(-time.Minute).Format(http.TimeFormat),
+			expected: 0,
+		},
+		{
+			name:     "Invalid",
+			header:   "soon",
+			expected: 0,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			resp := &http.Response{Header: http.Header{}}
+			if tc.header != "" {
+				resp.Header.Set("Retry-After", tc.header)
+			}
+			require.Equal(t, tc.expected, retryAfter(resp, now))
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package clientoptl
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+
+	"github.com/openmcp-project/metrics-operator/internal/common"
+)
+
+// stdoutWriter is shared by all stdout exporters, so the lines of concurrent exports do not interleave
+var stdoutWriter = &lockedWriter{w: os.Stdout}
+
+type lockedWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// stdoutLine is a data point written by the stdout exporter
+type stdoutLine struct {
+	Time       time.Time         `json:"time"`
+	DataSink   string            `json:"dataSink,omitempty"`
+	Scope      string            `json:"scope"`
+	Metric     string            `json:"metric"`
+	Dimensions map[string]string `json:"dimensions,omitempty"`
+	Value      int64             `json:"value"`
+}
+
+// stdoutExporter writes every data point as a JSON line to the standard output of the operator,
+// to debug metrics without a backend
+type stdoutExporter struct {
+	out      *lockedWriter
+	dataSink string
+}
+
+func newStdoutExporter(_ context.Context, credentials *common.DataSinkCredentials) (MetricsExporter, error) {
+	return &stdoutExporter{out: stdoutWriter, dataSink: credentials.Name}, nil
+}
+
+func (e *stdoutExporter) Export(_ context.Context, rm *metricdata.ResourceMetrics) error {
+	e.out.mu.Lock()
+	defer e.out.mu.Unlock()
+
+	encoder := json.NewEncoder(e.out.w)
+	for _, dp := range gaugeDataPoints(rm) {
+		line := stdoutLine{
+			Time:       dp.Time,
+			DataSink:   e.dataSink,
+			Scope:      dp.Scope,
+			Metric:     dp.Metric,
+			Dimensions: dp.Dimensions,
+			Value:      dp.Value,
+		}
+		if err := encoder.Encode(line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *stdoutExporter) Shutdown(context.Context) error { return nil }
@@ -0,0 +1,148 @@
+package clientoptl
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"text/template"
+	"time"
+
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+
+	"github.com/openmcp-project/metrics-operator/internal/common"
+)
+
+// defaultWebhookTemplate posts the data points as a JSON array
+const defaultWebhookTemplate = "{{ json .DataPoints }}"
+
+// webhookPayload is the data the template of a webhook is executed with
+type webhookPayload struct {
+	DataSink   string
+	DataPoints []webhookDataPoint
+}
+
+// webhookDataPoint is a data point in the payload of a webhook
+type webhookDataPoint struct {
+	Metric      string            `json:"metric"`
+	Description string            `json:"description,omitempty"`
+	Unit        string            `json:"unit,omitempty"`
+	Time        time.Time         `json:"time"`
+	Dimensions  map[string]string `json:"dimensions,omitempty"`
+	Value       int64             `json:"value"`
+}
+
+// webhookExporter posts the data points rendered by a Go template to an HTTP endpoint,
+// to integrate ingestion APIs without a dedicated exporter
+type webhookExporter struct {
+	endpoint        string
+	dataSink        string
+	template        *template.Template
+	contentType     string
+	headers         map[string]string
+	compression     string
+	maxPayloadBytes int
+	client          *http.Client
+}
+
+func newWebhookExporter(_ context.Context, credentials *common.DataSinkCredentials) (MetricsExporter, error) {
+	if credentials.Webhook == nil {
+		return nil, errors.New("the webhook is not configured")
+	}
+	endpoint, client, err := newHTTPExportClient(credentials, "Webhook")
+	if err != nil {
+		return nil, err
+	}
+
+	tmpl, err := template.New("webhook").
+		Funcs(template.FuncMap{"json": webhookJSON}).
+		Option("missingkey=error").
+		Parse(cmp.Or(credentials.Webhook.Template, defaultWebhookTemplate))
+	if err != nil {
+		return nil, fmt.Errorf("invalid webhook template: %w", err)
+	}
+
+	return &webhookExporter{
+		endpoint:        endpoint.String(),
+		dataSink:        credentials.Name,
+		template:        tmpl,
+		contentType:     cmp.Or(credentials.Webhook.ContentType, "application/json"),
+		headers:         credentials.Webhook.Headers,
+		compression:     credentials.Compression,
+		maxPayloadBytes: credentials.MaxPayloadBytes,
+		client:          client,
+	}, nil
+}
+
+// webhookJSON marshals a value to JSON in templates
+func webhookJSON(v any) (string, error) {
+	b, err := json.Marshal(v)
+	return string(b), err
+}
+
+// Export posts all data points in a single request, an empty export sends nothing.
+// If the rendered body is larger than the maximum payload size, the data points are split in halves posted separately.
+func (e *webhookExporter) Export(ctx context.Context, rm *metricdata.ResourceMetrics) error {
+	points := gaugeDataPoints(rm)
+	if len(points) == 0 {
+		return nil
+	}
+
+	dataPoints := make([]webhookDataPoint, 0, len(points))
+	for _, dp := range points {
+		dataPoints = append(dataPoints, webhookDataPoint{
+			Metric:      dp.Metric,
+			Description: dp.Description,
+			Unit:        dp.Unit,
+			Time:        dp.Time,
+			Dimensions:  dp.Dimensions,
+			Value:       dp.Value,
+		})
+	}
+	return e.post(ctx, dataPoints)
+}
+
+// post renders the data points and posts them, halving them until the body fits the maximum payload size
+func (e *webhookExporter) post(ctx context.Context, dataPoints []webhookDataPoint) error {
+	body := bytes.Buffer{}
+	if err := e.template.Execute(&body, webhookPayload{DataSink: e.dataSink, DataPoints: dataPoints}); err != nil {
+		return fmt.Errorf("failed to render webhook template: %w", err)
+	}
+	if e.maxPayloadBytes > 0 && body.Len() > e.maxPayloadBytes && len(dataPoints) > 1 {
+		half := len(dataPoints) / 2
+		if err := e.post(ctx, dataPoints[:half]); err != nil {
+			return err
+		}
+		return e.post(ctx, dataPoints[half:])
+	}
+
+	req, err := newPayloadRequest(ctx, e.endpoint, body.Bytes(), e.compression)
+	if err != nil {
+		return err
+	}
+	for key, value := range e.headers {
+		req.Header.Set(key, value)
+	}
+	req.Header.Set("Content-Type", e.contentType)
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+		message := string(bytes.TrimSpace(body))
+		return rejected(resp.StatusCode, message, fmt.Errorf("webhook failed with %s: %s", resp.Status, message))
+	}
+	return nil
+}
+
+func (e *webhookExporter) Shutdown(context.Context) error {
+	e.client.CloseIdleConnections()
+	return nil
+}
@@ -0,0 +1,109 @@
+package clientoptl
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+
+	"github.com/openmcp-project/metrics-operator/internal/common"
+)
+
+func TestWebhookExporter(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	rm := &metricdata.ResourceMetrics{ScopeMetrics: []metricdata.ScopeMetrics{{
+		Metrics: []metricdata.Metrics{{
+			Name: "pods",
+			Unit: "1",
+			Data: metricdata.Gauge[int64]{DataPoints: []metricdata.DataPoint[int64]{
+				{Attributes: attribute.NewSet(attribute.String("namespace", "team-a")), Time: now, Value: 3},
+			}},
+		}},
+	}}}
+
+	testCases := []struct {
+		name                string
+		webhook             common.WebhookTarget
+		status              int
+		expectedBody        string
+		expectedContentType string
+		expectedError       string
+	}{
+		{
+			name:                "DefaultTemplate",
+			webhook:             common.WebhookTarget{},
+			status:              http.StatusOK,
+			expectedBody:        `[{"metric":"pods","unit":"1","time":"2025-01-01T12:00:00Z","dimensions":{"namespace":"team-a"},"value":3}]`,
+			expectedContentType: "application/json",
+		},
+		{
+			name: "CustomTemplate",
+			webhook: common.WebhookTarget{
+				Template:    `{{ range .DataPoints }}{{ .Metric }}{{ "{" }}ns={{ index .Dimensions "namespace" }}{{ "}" }} {{ .Value }} {{ .Time.Unix }}{{ "\n" }}{{ end }}`,
+				ContentType: "text/plain",
+			},
+			status:              http.StatusAccepted,
+			expectedBody:        "pods{ns=team-a} 3 1735732800\n",
+			expectedContentType: "text/plain",
+		},
+		{
+			name:                "Rejected",
+			webhook:             common.WebhookTarget{Template: `{"sink": {{ json .DataSink }}}`},
+			status:              http.StatusBadRequest,
+			expectedBody:        `{"sink": "metrics/webhook"}`,
+			expectedContentType: "application/json",
+			expectedError:       "webhook failed with 400 Bad Request: unknown field sink",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var body, contentType, token string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				b, _ := io.ReadAll(r.Body)
+				body = string(b)
+				contentType = r.Header.Get("Content-Type")
+				token = r.Header.Get("X-Api-Token")
+				w.WriteHeader(tc.status)
+				if tc.status >= 300 {
+					_, _ = w.Write([]byte("unknown field sink"))
+				}
+			}))
+			defer server.Close()
+
+			webhook := tc.webhook
+			webhook.Headers = map[string]string{"X-Api-Token": "secret"}
+			exporter, err := newWebhookExporter(context.Background(), &common.DataSinkCredentials{
+				Name:    "metrics/webhook",
+				Host:    server.URL + "/ingest",
+				Webhook: &webhook,
+			})
+			require.NoError(t, err)
+			defer func() { _ = exporter.Shutdown(context.Background()) }()
+
+			err = exporter.Export(context.Background(), rm)
+			if tc.expectedError != "" {
+				require.EqualError(t, err, tc.expectedError)
+			} else {
+				require.NoError(t, err)
+			}
+			require.Equal(t, tc.expectedBody, body)
+			require.Equal(t, tc.expectedContentType, contentType)
+			require.Equal(t, "secret", token)
+		})
+	}
+}
+
+func TestNewWebhookExporter_invalidTemplate(t *testing.T) {
+	_, err := newWebhookExporter(context.Background(), &common.DataSinkCredentials{
+		Host:    "https://ingest.example.com",
+		Webhook: &common.WebhookTarget{Template: "{{ range .DataPoints }}"},
+	})
+	require.ErrorContains(t, err, "invalid webhook template")
+}
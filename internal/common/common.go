@@ -1,7 +1,17 @@
 package common
 
+import (
+	"regexp"
+	"time"
+)
+
 // DataSinkCredentials holds the credentials to access the data sink
 type DataSinkCredentials struct {
+	// Name is the namespace/name of the DataSink, metrics exporting to the same DataSink share its circuit breaker
+	Name string
+	// Type is the type of the DataSink, it selects the exporter
+	Type string
+
 	Host string
 	Path string
 
@@ -10,6 +20,102 @@ type DataSinkCredentials struct {
 
 	// Certificate-based authentication (mutual TLS)
 	Certificate *CertificateAuth
+
+	// Proxy is the HTTP proxy the data sink is reached through, nil uses the proxy of the environment
+	Proxy *ProxyConfig
+	// CABundle are PEM encoded CA certificates trusted in addition to the system CAs
+	CABundle []byte
+
+	// Compression of the payloads, "gzip" or empty for none
+	Compression string
+	// MaxPayloadBytes is the maximum size of the uncompressed payload of a request, zero does not limit it
+	MaxPayloadBytes int
+	// Timeout of a single export request, zero uses the exporter's default
+	Timeout time.Duration
+	// Retry configures the retries of failed OTLP exports, nil uses the exporter's defaults
+	Retry *RetryConfig
+	// GRPC configures the connection to grpc and grpcs endpoints
+	GRPC *GRPCConfig
+
+	// InfluxDB is the organization and bucket written to by the InfluxDB exporter
+	InfluxDB *InfluxDBTarget
+
+	// Webhook is the payload template and headers of the requests of the webhook exporter
+	Webhook *WebhookTarget
+
+	// OTLP is the temporality and aggregation of the exported metrics, nil uses the defaults of OpenTelemetry
+	OTLP *OTLPConfig
+
+	// FinalZero exports 0 for every series of a metric before the metric is deleted
+	FinalZero bool
+	// FinalZeroGracePeriod is how long the final export is retried before the metric is deleted without it
+	FinalZeroGracePeriod time.Duration
+
+	// DimensionPolicy drops and redacts dimensions before they are exported, nil exports all dimensions unchanged
+	DimensionPolicy *DimensionPolicy
+}
+
+// ProxyConfig is the HTTP proxy the data sink is reached through
+type ProxyConfig struct {
+	URL string
+	// NoProxy lists the hosts, domains and CIDRs reached without the proxy, like NO_PROXY
+	NoProxy string
+}
+
+// RetryConfig is the exponential backoff of failed exports, zero intervals use the exporter's defaults
+type RetryConfig struct {
+	Enabled         bool
+	InitialInterval time.Duration
+	MaxInterval     time.Duration
+	MaxElapsedTime  time.Duration
+}
+
+// GRPCConfig configures the gRPC connection to the data sink
+type GRPCConfig struct {
+	// KeepaliveTime is the time without activity after which the connection is pinged, zero disables keepalive pings
+	KeepaliveTime    time.Duration
+	KeepaliveTimeout time.Duration
+}
+
+// OTLPConfig is the temporality and aggregation of the metrics exported with OTLP
+type OTLPConfig struct {
+	// Temporality is Cumulative or Delta, empty uses cumulative temporality
+	Temporality string
+	// Aggregation is LastValue, Histogram or ExponentialHistogram, empty exports gauges
+	Aggregation string
+	// HistogramBoundaries are the bucket boundaries of the Histogram aggregation, nil uses the default boundaries
+	HistogramBoundaries []float64
+}
+
+// InfluxDBTarget is the organization and bucket the metrics are written to in InfluxDB v2
+type InfluxDBTarget struct {
+	Org    string
+	Bucket string
+}
+
+// WebhookTarget is the payload template and headers of the requests posted by the webhook exporter
+type WebhookTarget struct {
+	// Template renders the body of a request, the exporter's default if empty
+	Template    string
+	ContentType string
+	Headers     map[string]string
+}
+
+// DimensionPolicy drops and redacts dimensions before they are exported to the data sink
+type DimensionPolicy struct {
+	// AllowedKeys are the only keys exported, if empty all keys that are not denied are exported
+	AllowedKeys map[string]bool
+	// DeniedKeys are never exported
+	DeniedKeys map[string]bool
+	Redactions []DimensionRedaction
+}
+
+// DimensionRedaction replaces the parts of dimension values that match Pattern
+type DimensionRedaction struct {
+	// Keys whose values are redacted, if empty the values of all dimensions are redacted
+	Keys        map[string]bool
+	Pattern     *regexp.Regexp
+	Replacement string
 }
 
 type APIKeyAuth struct {
@@ -92,3 +92,25 @@ func Unavailable(message string) metav1.Condition {
 		Message:            message,
 	}
 }
+
+// DegradedTrue returns a condition that indicates the resource works, but not as expected
+func DegradedTrue(reason, message string) metav1.Condition {
+	return metav1.Condition{
+		Type:               v1alpha1.TypeDegraded,
+		Status:             metav1.ConditionTrue,
+		LastTransitionTime: metav1.Now(),
+		Reason:             reason,
+		Message:            message,
+	}
+}
+
+// DegradedFalse returns a condition that indicates the resource works as expected
+func DegradedFalse(message string) metav1.Condition {
+	return metav1.Condition{
+		Type:               v1alpha1.TypeDegraded,
+		Status:             metav1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             "ReconciliationSucceeded",
+		Message:            message,
+	}
+}
@@ -0,0 +1,66 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/rest"
+
+	"github.com/openmcp-project/metrics-operator/api/v1alpha1"
+	"github.com/openmcp-project/metrics-operator/internal/orchestrator"
+)
+
+// ErrQueriedByAgents is returned for federated cluster accesses whose member clusters are queried by their agents,
+// the hub has no access to them
+var ErrQueriedByAgents = errors.New("the member clusters are queried by their agents")
+
+var (
+	clusterAgentGVK = v1alpha1.GroupVersion.WithKind("ClusterAgent")
+	clusterAgentGVR = v1alpha1.GroupVersion.WithResource("clusteragents")
+)
+
+// listClusterAgents lists the ClusterAgents selected by a federated cluster access
+func listClusterAgents(ctx context.Context, set *v1alpha1.FederatedClusterAccess, dynamicClient dynamic.Interface) (*unstructured.UnstructuredList, error) {
+	selector, err := ClusterAgentSelector(set)
+	if err != nil {
+		return nil, err
+	}
+	list, err := dynamicClient.Resource(clusterAgentGVR).Namespace(FederatedMemberNamespace(set)).List(ctx, metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return nil, fmt.Errorf("could not list cluster agents matching selector '%s'. %w", selector.String(), err)
+	}
+	return list, nil
+}
+
+// ClusterAgentSelector returns the selector of the ClusterAgents of a federated cluster access in agents mode
+func ClusterAgentSelector(set *v1alpha1.FederatedClusterAccess) (labels.Selector, error) {
+	if set.Spec.Agents == nil || set.Spec.Agents.Selector == nil {
+		return labels.Everything(), nil
+	}
+	selector, err := metav1.LabelSelectorAsSelector(set.Spec.Agents.Selector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cluster agent selector: %w", err)
+	}
+	return selector, nil
+}
+
+// CreateAgentQueryConfig creates the query config an agent uses to query the member cluster it runs in,
+// with the cluster name and labels the federated cluster access defines for the agent
+func CreateAgentQueryConfig(set *v1alpha1.FederatedClusterAccess, agent *v1alpha1.ClusterAgent, restConfig *rest.Config) (*orchestrator.QueryConfig, error) {
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(agent)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert cluster agent: %w", err)
+	}
+	qc, err := newQueryConfig(restConfig, agent.Spec.ClusterName, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create client: %w", err)
+	}
+	setMemberClusterMetadata(set, &unstructured.Unstructured{Object: content}, qc)
+	return qc, nil
+}
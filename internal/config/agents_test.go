@@ -0,0 +1,82 @@
+package config
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	insight "github.com/openmcp-project/metrics-operator/api/v1alpha1"
+)
+
+func clusterAgent(namespace, name string, labels map[string]string) *unstructured.Unstructured {
+	agent := &unstructured.Unstructured{Object: map[string]interface{}{"spec": map[string]interface{}{"clusterName": name}}}
+	agent.SetGroupVersionKind(clusterAgentGVK)
+	agent.SetNamespace(namespace)
+	agent.SetName(name)
+	agent.SetLabels(labels)
+	return agent
+}
+
+func TestFederatedClusterAccessWithAgents(t *testing.T) {
+	fca := insight.FederatedClusterAccess{
+		ObjectMeta: metav1.ObjectMeta{Name: "fleet", Namespace: "agents"},
+		Spec: insight.FederatedClusterAccessSpec{
+			Agents: &insight.ClusterAgents{Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"env": "prod"}}},
+		},
+	}
+	mockClient := &MockClient{
+		GetFunc: func(_ context.Context, _ client.ObjectKey, obj client.Object, _ ...client.GetOption) error {
+			if obj, ok := obj.(*insight.FederatedClusterAccess); ok {
+				*obj = fca
+			}
+			return nil
+		},
+	}
+
+	prod := map[string]string{"env": "prod"}
+	fakeDynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{clusterAgentGVR: "ClusterAgentList"},
+		clusterAgent("agents", "cluster1", prod),
+		clusterAgent("agents", "dev", map[string]string{"env": "dev"}),
+		clusterAgent("other", "cluster2", prod),
+	)
+	opts := CreateExternalQueryConfigSetOptions{
+		GetDynamicClient: func(*rest.Config) (dynamic.Interface, error) { return fakeDynamicClient, nil },
+	}
+
+	ref := insight.FederateClusterAccessRef{Name: "fleet", Namespace: "agents"}
+	_, err := CreateExternalQueryConfigSet(context.Background(), ref, mockClient, &rest.Config{}, opts)
+	require.ErrorIs(t, err, ErrQueriedByAgents, "the hub does not query member clusters of agents")
+
+	members, err := ListFederatedMembers(context.Background(), &fca, &rest.Config{}, opts)
+	require.NoError(t, err)
+	require.Len(t, members.Items, 1)
+	require.Equal(t, "cluster1", members.Items[0].GetName())
+
+	require.Equal(t, clusterAgentGVK, FederatedMemberGVK(&fca))
+	require.Equal(t, "agents", FederatedMemberNamespace(&fca))
+}
+
+func TestCreateAgentQueryConfig(t *testing.T) {
+	fca := &insight.FederatedClusterAccess{
+		Spec: insight.FederatedClusterAccessSpec{Agents: &insight.ClusterAgents{}},
+	}
+	agent := &insight.ClusterAgent{
+		ObjectMeta: metav1.ObjectMeta{Name: "agent", Namespace: "agents"},
+		Spec:       insight.ClusterAgentSpec{ClusterName: "cluster1"},
+	}
+
+	qc, err := CreateAgentQueryConfig(fca, agent, &rest.Config{Host: "https://localhost:6443"})
+	require.NoError(t, err)
+	require.Equal(t, "cluster1", *qc.ClusterName)
+	require.Equal(t, "https://localhost:6443", qc.RestConfig.Host)
+}
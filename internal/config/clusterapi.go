@@ -0,0 +1,79 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/openmcp-project/metrics-operator/api/v1alpha1"
+	"github.com/openmcp-project/metrics-operator/internal/orchestrator"
+)
+
+const (
+	// capiClusterNameLabel is set by Cluster API on the Secrets of a Cluster
+	capiClusterNameLabel = "cluster.x-k8s.io/cluster-name"
+	// capiKubeconfigSecretSuffix is appended to the name of a Cluster to get the name of its kubeconfig Secret
+	capiKubeconfigSecretSuffix = "-kubeconfig"
+	// capiKubeconfigSecretKey is the key of the kubeconfig in the kubeconfig Secret of a Cluster
+	capiKubeconfigSecretKey = "value"
+)
+
+var (
+	capiClusterGVK = schema.GroupVersionKind{Group: "cluster.x-k8s.io", Version: "v1beta1", Kind: "Cluster"}
+	capiClusterGVR = capiClusterGVK.GroupVersion().WithResource("clusters")
+)
+
+// listClusterAPIClusters lists the Cluster API Clusters selected by a federated cluster access,
+// skipping Clusters in deletion and Clusters whose kubeconfig Secret does not exist yet, e.g. while they are provisioned
+func listClusterAPIClusters(ctx context.Context, set *v1alpha1.FederatedClusterAccess, dynamicClient dynamic.Interface) (*unstructured.UnstructuredList, error) {
+	selector := labels.Everything()
+	if set.Spec.ClusterAPI.Selector != nil {
+		var err error
+		if selector, err = metav1.LabelSelectorAsSelector(set.Spec.ClusterAPI.Selector); err != nil {
+			return nil, fmt.Errorf("invalid cluster selector: %w", err)
+		}
+	}
+
+	namespace := FederatedMemberNamespace(set)
+	list, err := dynamicClient.Resource(capiClusterGVR).Namespace(namespace).List(ctx, metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return nil, fmt.Errorf("could not list clusters matching selector '%s'. %w", selector.String(), err)
+	}
+
+	secrets, err := dynamicClient.Resource(secretGVR).Namespace(namespace).List(ctx, metav1.ListOptions{LabelSelector: capiClusterNameLabel})
+	if err != nil {
+		return nil, fmt.Errorf("could not list kubeconfig secrets of the clusters. %w", err)
+	}
+	kubeconfigSecrets := make(map[string]struct{}, len(secrets.Items))
+	for _, secret := range secrets.Items {
+		if _, found, _ := unstructured.NestedString(secret.Object, "data", capiKubeconfigSecretKey); found {
+			kubeconfigSecrets[secret.GetName()] = struct{}{}
+		}
+	}
+
+	list.Items = slices.DeleteFunc(list.Items, func(cluster unstructured.Unstructured) bool {
+		_, hasKubeconfig := kubeconfigSecrets[cluster.GetName()+capiKubeconfigSecretSuffix]
+		return !hasKubeconfig || cluster.GetDeletionTimestamp() != nil
+	})
+	return list, nil
+}
+
+// queryConfigsFromClusterAPIClusters creates a query config for each of the listed Clusters from its kubeconfig Secret
+func queryConfigsFromClusterAPIClusters(ctx context.Context, set *v1alpha1.FederatedClusterAccess, list *unstructured.UnstructuredList, inClient client.Client) ([]orchestrator.QueryConfig, error) {
+	kubeConfigSecretRefs := make([]v1alpha1.KubeConfigSecretRef, 0, len(list.Items))
+	for _, cluster := range list.Items {
+		kubeConfigSecretRefs = append(kubeConfigSecretRefs, v1alpha1.KubeConfigSecretRef{
+			Name:      cluster.GetName() + capiKubeconfigSecretSuffix,
+			Namespace: cluster.GetNamespace(),
+			Key:       capiKubeconfigSecretKey,
+		})
+	}
+	return queryConfigsFromSecretRefs(ctx, set, list, kubeConfigSecretRefs, inClient)
+}
@@ -0,0 +1,97 @@
+package config
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	insight "github.com/openmcp-project/metrics-operator/api/v1alpha1"
+)
+
+func capiCluster(namespace, name string, labels map[string]string) *unstructured.Unstructured {
+	cluster := &unstructured.Unstructured{}
+	cluster.SetGroupVersionKind(capiClusterGVK)
+	cluster.SetNamespace(namespace)
+	cluster.SetName(name)
+	cluster.SetLabels(labels)
+	return cluster
+}
+
+func capiSecret(namespace, name, cluster string, data map[string][]byte) *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name, Labels: map[string]string{capiClusterNameLabel: cluster}},
+		Data:       data,
+	}
+}
+
+func TestCreateExternalQueryConfigSetWithClusterAPI(t *testing.T) {
+	fca := insight.FederatedClusterAccess{
+		ObjectMeta: metav1.ObjectMeta{Name: "fleet", Namespace: "fleet"},
+		Spec: insight.FederatedClusterAccessSpec{
+			ClusterAPI: &insight.ClusterAPIClusters{
+				Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"env": "prod"}},
+			},
+			ClusterNameFrom: insight.ClusterNameFromMember,
+			MemberLabels:    []string{"region"},
+		},
+	}
+	var secretKeys []client.ObjectKey
+	mockClient := &MockClient{
+		GetFunc: func(_ context.Context, key client.ObjectKey, obj client.Object, _ ...client.GetOption) error {
+			switch obj := obj.(type) {
+			case *insight.FederatedClusterAccess:
+				*obj = fca
+			case *corev1.Secret:
+				secretKeys = append(secretKeys, key)
+				*obj = corev1.Secret{Data: map[string][]byte{capiKubeconfigSecretKey: []byte(createDummyKubeconfigAsString())}}
+			}
+			return nil
+		},
+	}
+
+	scheme := runtime.NewScheme()
+	require.NoError(t, corev1.AddToScheme(scheme))
+	kubeconfig := map[string][]byte{capiKubeconfigSecretKey: []byte(createDummyKubeconfigAsString())}
+	fakeDynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(scheme,
+		map[schema.GroupVersionResource]string{capiClusterGVR: "ClusterList"},
+		capiCluster("fleet", "eu", map[string]string{"env": "prod", "region": "eu"}),
+		capiCluster("fleet", "provisioning", map[string]string{"env": "prod"}),
+		capiCluster("fleet", "dev", map[string]string{"env": "dev"}),
+		capiCluster("other", "us", map[string]string{"env": "prod"}),
+		capiSecret("fleet", "eu-kubeconfig", "eu", kubeconfig),
+		capiSecret("fleet", "eu-ca", "eu", map[string][]byte{"tls.crt": []byte("certificate")}),
+		capiSecret("fleet", "provisioning-ca", "provisioning", map[string][]byte{"tls.crt": []byte("certificate")}),
+		capiSecret("fleet", "dev-kubeconfig", "dev", kubeconfig),
+		capiSecret("other", "us-kubeconfig", "us", kubeconfig),
+	)
+	opts := CreateExternalQueryConfigSetOptions{
+		GetDynamicClient: func(*rest.Config) (dynamic.Interface, error) { return fakeDynamicClient, nil },
+	}
+
+	set := fca.DeepCopy()
+	list, err := ListFederatedMembers(context.Background(), set, &rest.Config{}, opts)
+	require.NoError(t, err)
+	require.Len(t, list.Items, 1, "clusters without kubeconfig secret, of other namespaces and not selected are skipped")
+	require.Equal(t, "eu", list.Items[0].GetName())
+
+	ref := insight.FederateClusterAccessRef{Name: "fleet", Namespace: "fleet"}
+	queryConfigs, err := CreateExternalQueryConfigSet(context.Background(), ref, mockClient, &rest.Config{}, opts)
+	require.NoError(t, err)
+	require.Len(t, queryConfigs, 1)
+	require.Equal(t, "eu", *queryConfigs[0].ClusterName)
+	require.Equal(t, map[string]string{"region": "eu"}, queryConfigs[0].ClusterLabels)
+	require.Equal(t, []client.ObjectKey{{Namespace: "fleet", Name: "eu-kubeconfig"}}, secretKeys)
+
+	require.Equal(t, capiClusterGVK, FederatedMemberGVK(set))
+	require.Equal(t, "fleet", FederatedMemberNamespace(set))
+}
@@ -0,0 +1,81 @@
+package config
+
+import (
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"k8s.io/client-go/rest"
+)
+
+// credentialsExpiry returns the time the client certificate or the bearer token of the rest config expires,
+// whichever is earlier, or the zero time if neither has an expiry. Only JWT bearer tokens carry an expiry.
+func credentialsExpiry(restConfig *rest.Config) (time.Time, error) {
+	certData := restConfig.CertData
+	if len(certData) == 0 && restConfig.CertFile != "" {
+		data, err := os.ReadFile(restConfig.CertFile)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("failed to read client certificate: %w", err)
+		}
+		certData = data
+	}
+	token := restConfig.BearerToken
+	if token == "" && restConfig.BearerTokenFile != "" {
+		data, err := os.ReadFile(restConfig.BearerTokenFile)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("failed to read bearer token: %w", err)
+		}
+		token = strings.TrimSpace(string(data))
+	}
+
+	var expiry time.Time
+	if len(certData) > 0 {
+		notAfter, err := certificateExpiry(certData)
+		if err != nil {
+			return time.Time{}, err
+		}
+		expiry = notAfter
+	}
+	if tokenExpiry := jwtExpiry(token); !tokenExpiry.IsZero() && (expiry.IsZero() || tokenExpiry.Before(expiry)) {
+		expiry = tokenExpiry
+	}
+	return expiry, nil
+}
+
+// certificateExpiry returns the end of the validity of the first certificate of the PEM data
+func certificateExpiry(certData []byte) (time.Time, error) {
+	block, _ := pem.Decode(certData)
+	if block == nil || block.Type != "CERTIFICATE" {
+		return time.Time{}, fmt.Errorf("client certificate is not a PEM encoded certificate")
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to parse client certificate: %w", err)
+	}
+	return cert.NotAfter, nil
+}
+
+// jwtExpiry returns the exp claim of a JWT, or the zero time if the token is not a JWT or does not expire.
+// The signature is not verified, the API server rejects invalid tokens anyway.
+func jwtExpiry(token string) time.Time {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return time.Time{}
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return time.Time{}
+	}
+	var claims struct {
+		Exp int64 `json:"exp"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil || claims.Exp == 0 {
+		return time.Time{}
+	}
+	return time.Unix(claims.Exp, 0)
+}
@@ -0,0 +1,96 @@
+package config
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/rest"
+)
+
+func clientCertificate(t *testing.T, notAfter time.Time) []byte {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "metrics-operator"},
+		NotBefore:    notAfter.Add(-365 * 24 * time.Hour),
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+func jwt(exp time.Time) string {
+	enc := base64.RawURLEncoding
+	return enc.EncodeToString([]byte(`{"alg":"RS256"}`)) + "." +
+		enc.EncodeToString([]byte(fmt.Sprintf(`{"sub":"metrics-operator","exp":%d}`, exp.Unix()))) + "." +
+		enc.EncodeToString([]byte("signature"))
+}
+
+func TestCredentialsExpiry(t *testing.T) {
+	certExpiry := time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)
+	tokenExpiry := time.Date(2026, 12, 1, 0, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name       string
+		restConfig *rest.Config
+		want       time.Time
+		wantErr    string
+	}{
+		{
+			name:       "NoCredentials",
+			restConfig: &rest.Config{},
+		},
+		{
+			name:       "Certificate",
+			restConfig: &rest.Config{TLSClientConfig: rest.TLSClientConfig{CertData: clientCertificate(t, certExpiry)}},
+			want:       certExpiry,
+		},
+		{
+			name:       "Token",
+			restConfig: &rest.Config{BearerToken: jwt(tokenExpiry)},
+			want:       tokenExpiry,
+		},
+		{
+			name: "EarliestWins",
+			restConfig: &rest.Config{
+				TLSClientConfig: rest.TLSClientConfig{CertData: clientCertificate(t, certExpiry)},
+				BearerToken:     jwt(tokenExpiry),
+			},
+			want: tokenExpiry,
+		},
+		{
+			name:       "OpaqueToken",
+			restConfig: &rest.Config{BearerToken: "opaque-token"},
+		},
+		{
+			name:       "InvalidCertificate",
+			restConfig: &rest.Config{TLSClientConfig: rest.TLSClientConfig{CertData: []byte("not a certificate")}},
+			wantErr:    "client certificate is not a PEM encoded certificate",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := credentialsExpiry(tc.restConfig)
+			if tc.wantErr != "" {
+				require.EqualError(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.True(t, tc.want.Equal(got), "want %s, got %s", tc.want, got)
+		})
+	}
+}
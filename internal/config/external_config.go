@@ -4,14 +4,18 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"maps"
 	"net/url"
+	"slices"
 	"strings"
 
+	"golang.org/x/oauth2"
 	corev1 "k8s.io/api/core/v1"
 	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/client-go/discovery"
@@ -19,6 +23,7 @@ import (
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/transport"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/openmcp-project/metrics-operator/api/v1alpha1"
@@ -34,6 +39,8 @@ const (
 
 var (
 	externalScheme = runtime.NewScheme()
+
+	secretGVR = corev1.SchemeGroupVersion.WithResource("secrets")
 )
 
 func init() {
@@ -56,20 +63,27 @@ func CreateExternalQueryConfig(ctx context.Context, racRef *v1alpha1.RemoteClust
 		return nil, errRCA
 	}
 
-	kcRef := rca.Spec.KubeConfigSecretRef
-	if kcRef != nil {
-		return queryConfigFromKubeConfig(ctx, kcRef, inClient, externalScheme)
+	var qc *orchestrator.QueryConfig
+	switch {
+	case rca.Spec.KubeConfigSecretRef != nil:
+		qc, err = queryConfigFromKubeConfig(ctx, rca.Spec.KubeConfigSecretRef, rca.Spec.RateLimit, inClient, externalScheme)
+	case rca.Spec.ClusterAccessConfig != nil:
+		qc, err = queryConfigFromClusterAccessConfig(ctx, rca.Spec.ClusterAccessConfig, rca.Spec.RateLimit, inClient, externalScheme)
+	default:
+		return nil, fmt.Errorf("kubeconfigSecretRef and clusterAccessConfig are both nil")
 	}
-
-	cac := rca.Spec.ClusterAccessConfig
-	if cac != nil {
-		return queryConfigFromClusterAccessConfig(ctx, cac, inClient, externalScheme)
+	if err != nil {
+		return nil, err
 	}
 
-	return nil, fmt.Errorf("kubeconfigSecretRef and clusterAccessConfig are both nil")
+	if rca.Spec.ClusterName != "" {
+		qc.ClusterName = &rca.Spec.ClusterName
+	}
+	qc.ClusterLabels = rca.Spec.ClusterLabels
+	return qc, nil
 }
 
-func queryConfigFromClusterAccessConfig(ctx context.Context, cac *v1alpha1.ClusterAccessConfig, inClient client.Client, externalScheme *runtime.Scheme) (*orchestrator.QueryConfig, error) {
+func queryConfigFromClusterAccessConfig(ctx context.Context, cac *v1alpha1.ClusterAccessConfig, rateLimit *v1alpha1.ClientRateLimit, inClient client.Client, externalScheme *runtime.Scheme) (*orchestrator.QueryConfig, error) {
 	clsData, errData := getCusterDataFromSecret(ctx, cac, inClient)
 	if errData != nil {
 		return nil, errData
@@ -78,38 +92,47 @@ func queryConfigFromClusterAccessConfig(ctx context.Context, cac *v1alpha1.Clust
 	saName := cac.ServiceAccountName
 	saNamespace := cac.ServiceAccountNamespace
 
-	token, errToken := getTokenWithAPI(ctx, inClient, saName, saNamespace, clsData.audience)
-	if errToken != nil {
-		return nil, errToken
-	}
-
 	// Create a restconfig from token, host, caData, and audience
 
 	restConfig := &rest.Config{
-		Host:        clsData.host,
-		BearerToken: token,
+		Host: clsData.host,
 		TLSClientConfig: rest.TLSClientConfig{
 			CAData: []byte(clsData.caData),
 		},
 	}
+	applyRateLimit(restConfig, rateLimit)
 
-	// Create the client
-	externalClient, err := client.New(restConfig, client.Options{Scheme: externalScheme})
-	if err != nil {
-		return nil, fmt.Errorf("failed to create external client: %w", err)
-
+	// the token of the OIDC token source is not part of the rest config, its clients are cached by the issuer configuration
+	var identity string
+	if cac.OIDC != nil {
+		ts, tsIdentity, errTS := getOIDCTokenSource(ctx, inClient, cac, clsData)
+		if errTS != nil {
+			return nil, errTS
+		}
+		// the token source refreshes the token before it expires, for as long as the config is used
+		restConfig.WrapTransport = transport.TokenSourceWrapTransport(ts)
+		identity = tsIdentity
+	} else {
+		token, errToken := getTokenWithAPI(ctx, inClient, saName, saNamespace, clsData.audience)
+		if errToken != nil {
+			return nil, errToken
+		}
+		restConfig.BearerToken = token
 	}
 
 	parsedHost, errParse := url.Parse(clsData.host)
 	if errParse != nil {
 		return nil, fmt.Errorf("failed to parse host URL: %w", errParse)
 	}
-	hostName := parsedHost.Hostname()
 
-	return &orchestrator.QueryConfig{Client: externalClient, RestConfig: *restConfig, ClusterName: &hostName}, nil
+	qc, err := newQueryConfig(restConfig, parsedHost.Hostname(), identity)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create external client: %w", err)
+	}
+	return qc, nil
 }
 
-func queryConfigFromKubeConfig(ctx context.Context, kcRef *v1alpha1.KubeConfigSecretRef, inClient client.Client, externalScheme *runtime.Scheme) (*orchestrator.QueryConfig, error) {
+func queryConfigFromKubeConfig(ctx context.Context, kcRef *v1alpha1.KubeConfigSecretRef, rateLimit *v1alpha1.ClientRateLimit, inClient client.Client, externalScheme *runtime.Scheme) (*orchestrator.QueryConfig, error) {
 	secretName := kcRef.Name
 	secretNamespace := kcRef.Namespace
 
@@ -127,10 +150,22 @@ func queryConfigFromKubeConfig(ctx context.Context, kcRef *v1alpha1.KubeConfigSe
 		return nil, fmt.Errorf("kubeconfig key %s not found in Secret", key)
 	}
 
-	// Create a config from the kubeconfig data
+	qc, err := queryConfigFromKubeConfigData(kubeconfigData, rateLimit)
+	if err != nil {
+		return nil, err
+	}
+	if qc.CredentialsExpiry, err = credentialsExpiry(&qc.RestConfig); err != nil {
+		return nil, fmt.Errorf("invalid credentials in kubeconfig Secret %s/%s: %w", secretNamespace, secretName, err)
+	}
+	return qc, nil
+}
+
+// queryConfigFromKubeConfigData creates a query config from a kubeconfig,
+// named after the host of the API server of its current context
+func queryConfigFromKubeConfigData(kubeconfigData []byte, rateLimit *v1alpha1.ClientRateLimit) (*orchestrator.QueryConfig, error) {
 	config, errRest := clientcmd.RESTConfigFromKubeConfig(kubeconfigData)
 	if errRest != nil {
-		return nil, fmt.Errorf("failed to create config from kubeconfig: %w", err)
+		return nil, fmt.Errorf("failed to create config from kubeconfig: %w", errRest)
 	}
 
 	kubeconfig, errKC := clientcmd.Load(kubeconfigData)
@@ -153,13 +188,62 @@ func queryConfigFromKubeConfig(ctx context.Context, kcRef *v1alpha1.KubeConfigSe
 		return nil, fmt.Errorf("failed to extract hostname from kubeconfig: %w", err)
 	}
 
-	// Create the client
-	externalClient, err := client.New(config, client.Options{Scheme: externalScheme})
+	applyRateLimit(config, rateLimit)
+	qc, err := newQueryConfig(config, clusterName, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create client: %w", err)
+	}
+	return qc, nil
+}
+
+// queryConfigFromToken creates a query config for the API server at the given URL, authenticated with a bearer token
+// and named after the host of the URL
+func queryConfigFromToken(server string, caData []byte, token string, insecure bool) (*orchestrator.QueryConfig, error) {
+	clusterName, err := extractHostName(server)
+	if err != nil {
+		return nil, err
+	}
+	restConfig := &rest.Config{
+		Host:        server,
+		BearerToken: token,
+		TLSClientConfig: rest.TLSClientConfig{
+			CAData:   caData,
+			Insecure: insecure,
+		},
+	}
+	qc, err := newQueryConfig(restConfig, clusterName, "")
 	if err != nil {
 		return nil, fmt.Errorf("failed to create client: %w", err)
 	}
+	qc.CredentialsExpiry = jwtExpiry(token)
+	return qc, nil
+}
 
-	return &orchestrator.QueryConfig{Client: externalClient, RestConfig: *config, ClusterName: &clusterName}, nil
+// applyRateLimit sets the rate limit of a RemoteClusterAccess on the rest config,
+// without one the clients use the client rate limit of the operator
+func applyRateLimit(restConfig *rest.Config, rateLimit *v1alpha1.ClientRateLimit) {
+	if rateLimit == nil {
+		return
+	}
+	restConfig.QPS = float32(rateLimit.QPS)
+	restConfig.Burst = int(rateLimit.Burst)
+}
+
+// newQueryConfig creates a query config with the clients of the shared client factory,
+// so the clients of a cluster are reused as long as its credentials do not change
+func newQueryConfig(restConfig *rest.Config, clusterName, identity string) (*orchestrator.QueryConfig, error) {
+	clients, err := orchestrator.SharedClientFactory.ForConfig(restConfig, externalScheme, identity)
+	if err != nil {
+		return nil, err
+	}
+	return &orchestrator.QueryConfig{
+		Client:          clients.Client,
+		DynamicClient:   clients.Dynamic,
+		DiscoveryClient: clients.Discovery,
+		RestConfig:      *restConfig,
+		ClusterName:     &clusterName,
+		Identity:        identity,
+	}, nil
 }
 
 func getTokenWithAPI(ctx context.Context, inClient client.Client, serviceAccount, namespace, audience string) (string, error) {
@@ -178,6 +262,57 @@ func getTokenWithAPI(ctx context.Context, inClient client.Client, serviceAccount
 	return token, nil
 }
 
+// getOIDCTokenSource returns the token source for the issuer configured in the cluster secret
+// and an identity of the issuer configuration
+func getOIDCTokenSource(ctx context.Context, inClient client.Client, cac *v1alpha1.ClusterAccessConfig, clsData *clusterData) (oauth2.TokenSource, string, error) {
+	if clsData.issuerURL == "" {
+		return nil, "", fmt.Errorf("issuerURL key %s not found in Secret '%s/%s'", issuerURLKey, cac.ClusterSecretRef.Namespace, cac.ClusterSecretRef.Name)
+	}
+
+	grantType := cac.OIDC.GrantType
+	if grantType == "" {
+		grantType = v1alpha1.OIDCGrantClientCredentials
+	}
+	if grantType == v1alpha1.OIDCGrantClientCredentials && (clsData.clientID == "" || clsData.clientSecret == "") {
+		return nil, "", fmt.Errorf("client credentials grant requires the keys %s and %s in Secret '%s/%s'", clientIDKey, clientSecretKey, cac.ClusterSecretRef.Namespace, cac.ClusterSecretRef.Name)
+	}
+
+	subjectAudience := cac.OIDC.SubjectTokenAudience
+	if subjectAudience == "" {
+		subjectAudience = clsData.issuerURL
+	}
+
+	tm, errTM := GetOIDCTokenManager()
+	if errTM != nil {
+		return nil, "", fmt.Errorf("failed to get oidc token manager: %w", errTM)
+	}
+
+	cfg := oidcConfig{
+		issuerURL:    clsData.issuerURL,
+		clientID:     clsData.clientID,
+		clientSecret: clsData.clientSecret,
+		audience:     clsData.audience,
+		grantType:    grantType,
+		scopes:       cac.OIDC.Scopes,
+	}
+	if grantType == v1alpha1.OIDCGrantTokenExchange {
+		cfg.subjectServiceAccount = cac.ServiceAccountNamespace + "/" + cac.ServiceAccountName
+		cfg.subjectAudience = subjectAudience
+	}
+	ts, errTS := tm.TokenSource(ctx, cfg, func(ctx context.Context) (string, error) {
+		return getTokenWithAPI(ctx, inClient, cac.ServiceAccountName, cac.ServiceAccountNamespace, subjectAudience)
+	})
+	if errTS != nil {
+		return nil, "", errTS
+	}
+
+	// a cached token source may request a new token, a failing issuer is reported on the cluster access
+	if _, errToken := ts.Token(); errToken != nil {
+		return nil, "", errToken
+	}
+	return ts, cfg.getKey(), nil
+}
+
 func getCusterDataFromSecret(ctx context.Context, cac *v1alpha1.ClusterAccessConfig, inClient client.Client) (*clusterData, error) {
 	clusterSecretName := cac.ClusterSecretRef.Name
 	clusterSecretNamespace := cac.ClusterSecretRef.Namespace
@@ -208,6 +343,10 @@ func getCusterDataFromSecret(ctx context.Context, cac *v1alpha1.ClusterAccessCon
 		caData:   string(caData),
 		audience: string(audience),
 		host:     string(host),
+
+		issuerURL:    string(secret.Data[issuerURLKey]),
+		clientID:     string(secret.Data[clientIDKey]),
+		clientSecret: string(secret.Data[clientSecretKey]),
 	}
 
 	return &clsData, nil
@@ -217,6 +356,11 @@ type clusterData struct {
 	caData   string
 	audience string
 	host     string
+
+	// issuer configuration, only used with OIDC
+	issuerURL    string
+	clientID     string
+	clientSecret string
 }
 
 type getDiscoveryClientFunc func(restConfig *rest.Config) (discovery.DiscoveryInterface, error)
@@ -244,22 +388,21 @@ type CreateExternalQueryConfigSetOptions struct {
 	GetDynamicClient   getDynamicClientFunc
 }
 
-// CreateExternalQueryConfigSet creates a set of external query configs from a federated cluster access reference
-func CreateExternalQueryConfigSet(ctx context.Context, fcaRef v1alpha1.FederateClusterAccessRef, inClient client.Client, restConfig *rest.Config, opts CreateExternalQueryConfigSetOptions) ([]orchestrator.QueryConfig, error) {
-	// Apply default options
-	options := CreateExternalQueryConfigSetOptions{
-		GetDiscoveryClient: defaultGetDiscoveryClient,
-		GetDynamicClient:   defaultGetDynamicClient,
-	}
-
-	// Apply any provided options
-	if opts.GetDiscoveryClient != nil {
-		options.GetDiscoveryClient = opts.GetDiscoveryClient
+// dynamicClient creates the dynamic client of the given rest config with the configured or the default function
+func (o CreateExternalQueryConfigSetOptions) dynamicClient(restConfig *rest.Config) (dynamic.Interface, error) {
+	getDynamicClient := o.GetDynamicClient
+	if getDynamicClient == nil {
+		getDynamicClient = defaultGetDynamicClient
 	}
-	if opts.GetDynamicClient != nil {
-		options.GetDynamicClient = opts.GetDynamicClient
+	dynamicClient, err := getDynamicClient(restConfig)
+	if err != nil {
+		return nil, fmt.Errorf("could not create dynamic client: %w", err)
 	}
+	return dynamicClient, nil
+}
 
+// CreateExternalQueryConfigSet creates a set of external query configs from a federated cluster access reference
+func CreateExternalQueryConfigSet(ctx context.Context, fcaRef v1alpha1.FederateClusterAccessRef, inClient client.Client, restConfig *rest.Config, opts CreateExternalQueryConfigSetOptions) ([]orchestrator.QueryConfig, error) {
 	rcaSetName := fcaRef.Name
 	rcaSetNamespace := fcaRef.Namespace
 
@@ -270,7 +413,183 @@ func CreateExternalQueryConfigSet(ctx context.Context, fcaRef v1alpha1.FederateC
 		return nil, errRCA
 	}
 
-	kcPath := set.Spec.KubeConfigPath
+	if set.Spec.Agents != nil {
+		return nil, fmt.Errorf("federated cluster access %s/%s: %w", set.Namespace, set.Name, ErrQueriedByAgents)
+	}
+
+	list, err := ListFederatedMembers(ctx, set, restConfig, opts)
+	if err != nil {
+		return nil, err
+	}
+	return QueryConfigsForMembers(ctx, set, list, inClient, restConfig, opts)
+}
+
+// QueryConfigsForMembers creates the query configs of the listed member clusters of a federated cluster access
+func QueryConfigsForMembers(ctx context.Context, set *v1alpha1.FederatedClusterAccess, list *unstructured.UnstructuredList, inClient client.Client, restConfig *rest.Config, opts CreateExternalQueryConfigSetOptions) ([]orchestrator.QueryConfig, error) {
+	if set.Spec.Gardener != nil {
+		// the admin kubeconfigs of the shoots are requested from the garden cluster the shoots are listed in
+		dynamicClient, errCli := opts.dynamicClient(restConfig)
+		if errCli != nil {
+			return nil, errCli
+		}
+		return queryConfigsFromShoots(ctx, set, list, dynamicClient)
+	}
+
+	if set.Spec.ClusterAPI != nil {
+		return queryConfigsFromClusterAPIClusters(ctx, set, list, inClient)
+	}
+
+	if set.Spec.OCM != nil {
+		return queryConfigsFromManagedClusters(ctx, set, list, inClient)
+	}
+
+	if set.Spec.Karmada != nil {
+		return queryConfigsFromKarmadaClusters(ctx, set, list, inClient)
+	}
+
+	if set.Spec.SecretSelector != nil {
+		// each selected secret holds the kubeconfig of a member cluster
+		kubeConfigSecretRefs := make([]v1alpha1.KubeConfigSecretRef, 0, len(list.Items))
+		for _, secret := range list.Items {
+			kubeConfigSecretRefs = append(kubeConfigSecretRefs, v1alpha1.KubeConfigSecretRef{
+				Name:      secret.GetName(),
+				Namespace: secret.GetNamespace(),
+				Key:       set.Spec.SecretSelector.GetKey(),
+			})
+		}
+		return queryConfigsFromSecretRefs(ctx, set, list, kubeConfigSecretRefs, inClient)
+	}
+
+	if set.Spec.SecretRefPath != "" {
+		// extract all secret refs from resources
+		kubeConfigSecretRefs, errRefs := extractSecretRefs(set.Spec.SecretRefPath, list)
+		if errRefs != nil {
+			return nil, fmt.Errorf("failed to extract kubeconfig secret refs: %w", errRefs)
+		}
+		return queryConfigsFromSecretRefs(ctx, set, list, kubeConfigSecretRefs, inClient)
+	}
+
+	return extractKubeConfigs(set, set.Spec.KubeConfigPath, list)
+}
+
+// queryConfigsFromSecretRefs creates a query config for each of the referenced kubeconfig secrets,
+// the secret ref at an index belongs to the member resource at the same index of the list
+func queryConfigsFromSecretRefs(ctx context.Context, set *v1alpha1.FederatedClusterAccess, list *unstructured.UnstructuredList, kubeConfigSecretRefs []v1alpha1.KubeConfigSecretRef, inClient client.Client) ([]orchestrator.QueryConfig, error) {
+	queryConfigs := make([]orchestrator.QueryConfig, 0, len(kubeConfigSecretRefs))
+	for i, kcRef := range kubeConfigSecretRefs {
+		qc, errQC := queryConfigFromKubeConfig(ctx, &kcRef, nil, inClient, externalScheme)
+		if errQC != nil {
+			return nil, fmt.Errorf("failed to create query config from kubeconfig secret ref: %w", errQC)
+		}
+		setMemberClusterMetadata(set, &list.Items[i], qc)
+		queryConfigs = append(queryConfigs, *qc)
+	}
+	return queryConfigs, nil
+}
+
+// setMemberClusterMetadata sets the cluster name and the cluster labels of a member cluster
+func setMemberClusterMetadata(set *v1alpha1.FederatedClusterAccess, member *unstructured.Unstructured, qc *orchestrator.QueryConfig) {
+	if set.Spec.ClusterNameFrom == v1alpha1.ClusterNameFromMember {
+		name := member.GetName()
+		qc.ClusterName = &name
+	}
+
+	if len(set.Spec.ClusterLabels) == 0 && len(set.Spec.MemberLabels) == 0 {
+		return
+	}
+	labels := make(map[string]string, len(set.Spec.ClusterLabels)+len(set.Spec.MemberLabels))
+	maps.Copy(labels, set.Spec.ClusterLabels)
+	memberLabels := member.GetLabels()
+	for _, key := range set.Spec.MemberLabels {
+		labels[key] = memberLabels[key]
+	}
+	qc.ClusterLabels = labels
+}
+
+// FederatedMemberGVK returns the kind of the resources providing access to the member clusters of a federated cluster access
+func FederatedMemberGVK(set *v1alpha1.FederatedClusterAccess) schema.GroupVersionKind {
+	if set.Spec.Gardener != nil {
+		return shootGVK
+	}
+	if set.Spec.ClusterAPI != nil {
+		return capiClusterGVK
+	}
+	if set.Spec.OCM != nil {
+		return ocmManagedClusterGVK
+	}
+	if set.Spec.Karmada != nil {
+		return karmadaClusterGVK
+	}
+	if set.Spec.Agents != nil {
+		return clusterAgentGVK
+	}
+	if set.Spec.SecretSelector != nil {
+		return corev1.SchemeGroupVersion.WithKind("Secret")
+	}
+	return set.Spec.Target.GVK()
+}
+
+// FederatedMemberNamespace returns the namespace the members of a federated cluster access are listed in,
+// an empty namespace stands for all namespaces
+func FederatedMemberNamespace(set *v1alpha1.FederatedClusterAccess) string {
+	if set.Spec.OCM != nil || set.Spec.Karmada != nil {
+		// managed clusters and karmada clusters are cluster-scoped
+		return ""
+	}
+	if (set.Spec.SecretSelector != nil || set.Spec.Gardener != nil || set.Spec.ClusterAPI != nil || set.Spec.Agents != nil) && set.Spec.Namespace == "" {
+		return set.Namespace
+	}
+	return set.Spec.Namespace
+}
+
+// ListFederatedMembers lists the resources matching the target, the secret selector or the cluster inventory
+// of a federated cluster access, each of which provides access to a member cluster
+func ListFederatedMembers(ctx context.Context, set *v1alpha1.FederatedClusterAccess, restConfig *rest.Config, opts CreateExternalQueryConfigSetOptions) (*unstructured.UnstructuredList, error) {
+	getDiscoveryClient := opts.GetDiscoveryClient
+	if getDiscoveryClient == nil {
+		getDiscoveryClient = defaultGetDiscoveryClient
+	}
+	dynamicClient, errCli := opts.dynamicClient(restConfig)
+	if errCli != nil {
+		return nil, errCli
+	}
+
+	if set.Spec.Gardener != nil {
+		return listShoots(ctx, set, dynamicClient)
+	}
+
+	if set.Spec.ClusterAPI != nil {
+		return listClusterAPIClusters(ctx, set, dynamicClient)
+	}
+
+	if set.Spec.OCM != nil {
+		return listManagedClusters(ctx, set, dynamicClient)
+	}
+
+	if set.Spec.Karmada != nil {
+		return listKarmadaClusters(ctx, set, dynamicClient)
+	}
+
+	if set.Spec.Agents != nil {
+		return listClusterAgents(ctx, set, dynamicClient)
+	}
+
+	if set.Spec.SecretSelector != nil {
+		selector, err := metav1.LabelSelectorAsSelector(&set.Spec.SecretSelector.Selector)
+		if err != nil {
+			return nil, fmt.Errorf("invalid secret selector: %w", err)
+		}
+		list, err := dynamicClient.Resource(secretGVR).Namespace(FederatedMemberNamespace(set)).List(ctx, metav1.ListOptions{LabelSelector: selector.String()})
+		if err != nil {
+			return nil, fmt.Errorf("could not list kubeconfig secrets matching selector '%s'. %w", selector.String(), err)
+		}
+		// skip secrets without a kubeconfig, e.g. the CA secrets Cluster API labels the same way
+		list.Items = slices.DeleteFunc(list.Items, func(secret unstructured.Unstructured) bool {
+			_, found, _ := unstructured.NestedString(secret.Object, "data", set.Spec.SecretSelector.GetKey())
+			return !found
+		})
+		return list, nil
+	}
 
 	var listOptions = metav1.ListOptions{}
 	if set.Spec.LabelSelector != "" {
@@ -280,7 +599,7 @@ func CreateExternalQueryConfigSet(ctx context.Context, fcaRef v1alpha1.FederateC
 		listOptions.FieldSelector = set.Spec.FieldSelector
 	}
 
-	discoveryCli, err := options.GetDiscoveryClient(restConfig)
+	discoveryCli, err := getDiscoveryClient(restConfig)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create discovery client: %w", err)
 	}
@@ -289,11 +608,6 @@ func CreateExternalQueryConfigSet(ctx context.Context, fcaRef v1alpha1.FederateC
 		return nil, err
 	}
 
-	dynamicClient, errCli := options.GetDynamicClient(restConfig)
-	if errCli != nil {
-		return nil, fmt.Errorf("could not create dynamic client: %w", errCli)
-	}
-
 	var list *unstructured.UnstructuredList
 	if set.Spec.Namespace != "" {
 		list, err = dynamicClient.Resource(gvr).Namespace(set.Spec.Namespace).List(ctx, listOptions)
@@ -303,28 +617,7 @@ func CreateExternalQueryConfigSet(ctx context.Context, fcaRef v1alpha1.FederateC
 	if err != nil {
 		return nil, fmt.Errorf("could not find any matching resources for metric set with filter '%s'. %w", set.Spec.Target.GVK().String(), err)
 	}
-
-	if set.Spec.SecretRefPath != "" {
-		// extract all secret refs from resources
-		kubeConfigSecretRefs, errRefs := extractSecretRefs(set.Spec.SecretRefPath, list)
-		if errRefs != nil {
-			return nil, fmt.Errorf("failed to extract kubeconfig secret refs: %w", errRefs)
-		}
-
-		// get all kubeconfigs from secret refs
-		queryConfigs := make([]orchestrator.QueryConfig, 0, len(kubeConfigSecretRefs))
-		for _, kcRef := range kubeConfigSecretRefs {
-			qc, errQC := queryConfigFromKubeConfig(ctx, &kcRef, inClient, externalScheme)
-			if errQC != nil {
-				return nil, fmt.Errorf("failed to create query config from kubeconfig secret ref: %w", errQC)
-			}
-			queryConfigs = append(queryConfigs, *qc)
-		}
-
-		return queryConfigs, nil
-	}
-
-	return extractKubeConfigs(kcPath, list)
+	return list, nil
 }
 
 func extractSecretRefs(kcPath string, list *unstructured.UnstructuredList) ([]v1alpha1.KubeConfigSecretRef, error) {
@@ -367,7 +660,7 @@ func extractSecretRefs(kcPath string, list *unstructured.UnstructuredList) ([]v1
 	return kubeConfigSecretRefs, nil
 }
 
-func extractKubeConfigs(kcPath string, list *unstructured.UnstructuredList) ([]orchestrator.QueryConfig, error) {
+func extractKubeConfigs(set *v1alpha1.FederatedClusterAccess, kcPath string, list *unstructured.UnstructuredList) ([]orchestrator.QueryConfig, error) {
 	queryConfigs := make([]orchestrator.QueryConfig, 0, len(list.Items))
 
 	// TODO: not all resources will have kubeconfig data, need to handle this case
@@ -411,13 +704,15 @@ func extractKubeConfigs(kcPath string, list *unstructured.UnstructuredList) ([]o
 			return nil, fmt.Errorf("failed to extract hostname from kubeconfig: %w", err)
 		}
 
-		// Create the client
-		externalClient, err := client.New(config, client.Options{Scheme: externalScheme})
+		qc, err := newQueryConfig(config, clusterName, "")
 		if err != nil {
 			return nil, fmt.Errorf("failed to create external client query config: %w", err)
 		}
-
-		queryConfigs = append(queryConfigs, orchestrator.QueryConfig{Client: externalClient, RestConfig: *config, ClusterName: &clusterName})
+		if qc.CredentialsExpiry, err = credentialsExpiry(config); err != nil {
+			return nil, fmt.Errorf("invalid credentials in kubeconfig of '%s': %w", obj.GetName(), err)
+		}
+		setMemberClusterMetadata(set, &obj, qc)
+		queryConfigs = append(queryConfigs, *qc)
 
 	}
 
@@ -150,6 +150,75 @@ func TestCreateExternalQueryConfig(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "Cluster name and labels override the host name",
+			racRef: &insight.RemoteClusterAccessRef{
+				Name:      "test-rca",
+				Namespace: "default",
+			},
+			mockGet: func(_ context.Context, _ client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+				switch obj := obj.(type) {
+				case *insight.RemoteClusterAccess:
+					*obj = insight.RemoteClusterAccess{
+						Spec: insight.RemoteClusterAccessSpec{
+							KubeConfigSecretRef: &insight.KubeConfigSecretRef{
+								Name:      "test-secret",
+								Namespace: "default",
+								Key:       "kubeconfig",
+							},
+							ClusterName:   "prod-eu",
+							ClusterLabels: map[string]string{"region": "eu-west-1"},
+						},
+					}
+				case *corev1.Secret:
+					*obj = corev1.Secret{
+						Data: map[string][]byte{
+							"kubeconfig": []byte(createDummyKubeconfigAsString()),
+						},
+					}
+				}
+				return nil
+			},
+			want: &orc.QueryConfig{
+				ClusterName:   ptr.To("prod-eu"),
+				ClusterLabels: map[string]string{"region": "eu-west-1"},
+			},
+			wantErr: false,
+		},
+		{
+			name: "Rate limit of the remote cluster access",
+			racRef: &insight.RemoteClusterAccessRef{
+				Name:      "test-rca",
+				Namespace: "default",
+			},
+			mockGet: func(_ context.Context, _ client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+				switch obj := obj.(type) {
+				case *insight.RemoteClusterAccess:
+					*obj = insight.RemoteClusterAccess{
+						Spec: insight.RemoteClusterAccessSpec{
+							KubeConfigSecretRef: &insight.KubeConfigSecretRef{
+								Name:      "test-secret",
+								Namespace: "default",
+								Key:       "kubeconfig",
+							},
+							RateLimit: &insight.ClientRateLimit{QPS: 5, Burst: 10},
+						},
+					}
+				case *corev1.Secret:
+					*obj = corev1.Secret{
+						Data: map[string][]byte{
+							"kubeconfig": []byte(createDummyKubeconfigAsString()),
+						},
+					}
+				}
+				return nil
+			},
+			want: &orc.QueryConfig{
+				ClusterName: ptr.To("example.com"),
+				RestConfig:  rest.Config{QPS: 5, Burst: 10},
+			},
+			wantErr: false,
+		},
 		// Add more test cases here
 	}
 
@@ -168,6 +237,9 @@ func TestCreateExternalQueryConfig(t *testing.T) {
 				require.NoError(t, err)
 				require.NotNil(t, got)
 				require.Equal(t, tt.want.ClusterName, got.ClusterName)
+				require.Equal(t, tt.want.ClusterLabels, got.ClusterLabels)
+				require.Equal(t, tt.want.RestConfig.QPS, got.RestConfig.QPS)
+				require.Equal(t, tt.want.RestConfig.Burst, got.RestConfig.Burst)
 				// Add more assertions based on your requirements
 			}
 		})
@@ -693,6 +765,129 @@ func TestCreateExternalQueryConfigSet(t *testing.T) {
 			wantConfigCount: 1,
 			wantErr:         false,
 		},
+		{
+			name: "Successfully create query config set with secret selector",
+			fcaRef: insight.FederateClusterAccessRef{
+				Name:      "test-fca",
+				Namespace: "default",
+			},
+			mockGet: func(_ context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+				switch obj := obj.(type) {
+				case *insight.FederatedClusterAccess:
+					*obj = insight.FederatedClusterAccess{
+						ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace},
+						Spec: insight.FederatedClusterAccessSpec{
+							SecretSelector: &insight.KubeConfigSecretSelector{
+								Selector: metav1.LabelSelector{MatchLabels: map[string]string{"cluster.x-k8s.io/cluster-name": "workload"}},
+								Key:      "value",
+							},
+						},
+					}
+				case *corev1.Secret:
+					*obj = corev1.Secret{
+						Data: map[string][]byte{
+							"value": []byte(createDummyKubeconfigAsString()),
+						},
+					}
+				}
+				return nil
+			},
+			fakeDynamicObjects: []runtime.Object{
+				&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "workload-kubeconfig",
+						Namespace: "default",
+						Labels: map[string]string{
+							"cluster.x-k8s.io/cluster-name": "workload",
+						},
+					},
+					Data: map[string][]byte{
+						"value": []byte(createDummyKubeconfigAsString()),
+					},
+				},
+				&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "workload-ca",
+						Namespace: "default",
+						Labels: map[string]string{
+							"cluster.x-k8s.io/cluster-name": "workload",
+						},
+					},
+					Data: map[string][]byte{
+						"tls.crt": []byte("certificate"),
+					},
+				},
+				&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "other-kubeconfig",
+						Namespace: "default",
+						Labels: map[string]string{
+							"cluster.x-k8s.io/cluster-name": "other",
+						},
+					},
+					Data: map[string][]byte{
+						"value": []byte(createDummyKubeconfigAsString()),
+					},
+				},
+				&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "workload-kubeconfig",
+						Namespace: "other-namespace",
+						Labels: map[string]string{
+							"cluster.x-k8s.io/cluster-name": "workload",
+						},
+					},
+					Data: map[string][]byte{
+						"value": []byte(createDummyKubeconfigAsString()),
+					},
+				},
+			},
+			wantConfigCount: 1,
+			wantErr:         false,
+		},
+		{
+			name: "Skip secrets without kubeconfig key when using a secret selector",
+			fcaRef: insight.FederateClusterAccessRef{
+				Name:      "test-fca",
+				Namespace: "default",
+			},
+			mockGet: func(_ context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+				switch obj := obj.(type) {
+				case *insight.FederatedClusterAccess:
+					*obj = insight.FederatedClusterAccess{
+						ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace},
+						Spec: insight.FederatedClusterAccessSpec{
+							SecretSelector: &insight.KubeConfigSecretSelector{
+								Selector: metav1.LabelSelector{MatchLabels: map[string]string{"cluster.x-k8s.io/cluster-name": "workload"}},
+							},
+						},
+					}
+				case *corev1.Secret:
+					*obj = corev1.Secret{
+						Data: map[string][]byte{
+							"value": []byte(createDummyKubeconfigAsString()),
+						},
+					}
+				}
+				return nil
+			},
+			fakeDynamicObjects: []runtime.Object{
+				&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "workload-kubeconfig",
+						Namespace: "default",
+						Labels: map[string]string{
+							"cluster.x-k8s.io/cluster-name": "workload",
+						},
+					},
+					Data: map[string][]byte{
+						"value": []byte(createDummyKubeconfigAsString()),
+					},
+				},
+			},
+			wantConfigCount: 0,
+			wantErr:         false,
+		},
 	}
 
 	dummyRestConfig := &rest.Config{}
@@ -805,3 +1000,44 @@ func createDummyKubeconfigAsObject() map[string]interface{} {
 		},
 	}
 }
+
+func TestSetMemberClusterMetadata(t *testing.T) {
+	member := unstructured.Unstructured{}
+	member.SetName("cluster-a")
+	member.SetLabels(map[string]string{"env": "prod"})
+
+	tests := []struct {
+		name       string
+		spec       insight.FederatedClusterAccessSpec
+		wantName   string
+		wantLabels map[string]string
+	}{
+		{
+			name:     "Host name by default",
+			wantName: "example.com",
+		},
+		{
+			name:     "Member name",
+			spec:     insight.FederatedClusterAccessSpec{ClusterNameFrom: insight.ClusterNameFromMember},
+			wantName: "cluster-a",
+		},
+		{
+			name: "Cluster and member labels",
+			spec: insight.FederatedClusterAccessSpec{
+				ClusterLabels: map[string]string{"landscape": "live"},
+				MemberLabels:  []string{"env", "tier"},
+			},
+			wantName:   "example.com",
+			wantLabels: map[string]string{"landscape": "live", "env": "prod", "tier": ""},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			qc := orc.QueryConfig{ClusterName: ptr.To("example.com")}
+			setMemberClusterMetadata(&insight.FederatedClusterAccess{Spec: tt.spec}, &member, &qc)
+			require.Equal(t, tt.wantName, *qc.ClusterName)
+			require.Equal(t, tt.wantLabels, qc.ClusterLabels)
+		})
+	}
+}
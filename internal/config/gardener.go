@@ -0,0 +1,125 @@
+package config
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"slices"
+	"time"
+
+	lru "github.com/hashicorp/golang-lru/v2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+
+	"github.com/openmcp-project/metrics-operator/api/v1alpha1"
+	"github.com/openmcp-project/metrics-operator/internal/orchestrator"
+)
+
+const (
+	adminKubeconfigSubresource = "adminkubeconfig"
+	adminKubeconfigCacheSize   = 256 // one kubeconfig per shoot
+)
+
+var (
+	shootGVK = schema.GroupVersionKind{Group: "core.gardener.cloud", Version: "v1beta1", Kind: "Shoot"}
+	shootGVR = shootGVK.GroupVersion().WithResource("shoots")
+
+	adminKubeconfigRequestGV = schema.GroupVersion{Group: "authentication.gardener.cloud", Version: "v1alpha1"}
+
+	// adminKubeconfigs caches the admin kubeconfigs of the shoots until they are about to expire
+	adminKubeconfigs = newAdminKubeconfigCache()
+)
+
+type cachedKubeconfig struct {
+	kubeconfig []byte
+	expiration time.Time
+}
+
+func newAdminKubeconfigCache() *lru.Cache[string, cachedKubeconfig] {
+	cache, err := lru.New[string, cachedKubeconfig](adminKubeconfigCacheSize)
+	if err != nil {
+		panic(fmt.Sprintf("failed to create admin kubeconfig cache: %s", err))
+	}
+	return cache
+}
+
+// listShoots lists the shoots selected by a federated cluster access, skipping hibernated shoots and shoots in deletion,
+// as no admin kubeconfig can be used to query them
+func listShoots(ctx context.Context, set *v1alpha1.FederatedClusterAccess, dynamicClient dynamic.Interface) (*unstructured.UnstructuredList, error) {
+	selector := labels.Everything()
+	if set.Spec.Gardener.Selector != nil {
+		var err error
+		if selector, err = metav1.LabelSelectorAsSelector(set.Spec.Gardener.Selector); err != nil {
+			return nil, fmt.Errorf("invalid shoot selector: %w", err)
+		}
+	}
+
+	list, err := dynamicClient.Resource(shootGVR).Namespace(FederatedMemberNamespace(set)).List(ctx, metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return nil, fmt.Errorf("could not list shoots matching selector '%s'. %w", selector.String(), err)
+	}
+	list.Items = slices.DeleteFunc(list.Items, func(shoot unstructured.Unstructured) bool {
+		hibernated, _, _ := unstructured.NestedBool(shoot.Object, "spec", "hibernation", "enabled")
+		return hibernated || shoot.GetDeletionTimestamp() != nil
+	})
+	return list, nil
+}
+
+// queryConfigsFromShoots creates a query config for each of the listed shoots from its admin kubeconfig
+func queryConfigsFromShoots(ctx context.Context, set *v1alpha1.FederatedClusterAccess, list *unstructured.UnstructuredList, dynamicClient dynamic.Interface) ([]orchestrator.QueryConfig, error) {
+	queryConfigs := make([]orchestrator.QueryConfig, 0, len(list.Items))
+	for i := range list.Items {
+		shoot := &list.Items[i]
+		kubeconfigData, err := getAdminKubeconfig(ctx, dynamicClient, shoot, set.Spec.Gardener.GetExpirationSeconds())
+		if err != nil {
+			return nil, err
+		}
+		qc, err := queryConfigFromKubeConfigData(kubeconfigData, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create query config for shoot '%s/%s': %w", shoot.GetNamespace(), shoot.GetName(), err)
+		}
+		setMemberClusterMetadata(set, shoot, qc)
+		queryConfigs = append(queryConfigs, *qc)
+	}
+	return queryConfigs, nil
+}
+
+// getAdminKubeconfig returns the cached admin kubeconfig of a shoot, or requests a new one if it is about to expire
+func getAdminKubeconfig(ctx context.Context, dynamicClient dynamic.Interface, shoot *unstructured.Unstructured, expirationSeconds int64) ([]byte, error) {
+	key := fmt.Sprintf("%s/%s/%d", shoot.GetNamespace(), shoot.GetName(), expirationSeconds)
+	if cached, ok := adminKubeconfigs.Get(key); ok {
+		if time.Now().Add(marginFromExpirationTime).Before(cached.expiration) {
+			return cached.kubeconfig, nil
+		}
+	}
+
+	request := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{"expirationSeconds": expirationSeconds},
+	}}
+	request.SetGroupVersionKind(adminKubeconfigRequestGV.WithKind("AdminKubeconfigRequest"))
+	request.SetNamespace(shoot.GetNamespace())
+	request.SetName(shoot.GetName())
+
+	response, err := dynamicClient.Resource(shootGVR).Namespace(shoot.GetNamespace()).Create(ctx, request, metav1.CreateOptions{}, adminKubeconfigSubresource)
+	if err != nil {
+		return nil, fmt.Errorf("failed to request admin kubeconfig for shoot '%s/%s': %w", shoot.GetNamespace(), shoot.GetName(), err)
+	}
+
+	encoded, _, _ := unstructured.NestedString(response.Object, "status", "kubeconfig")
+	kubeconfig, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(kubeconfig) == 0 {
+		return nil, fmt.Errorf("admin kubeconfig request for shoot '%s/%s' returned no kubeconfig", shoot.GetNamespace(), shoot.GetName())
+	}
+
+	expiration := time.Now().Add(time.Duration(expirationSeconds) * time.Second)
+	if timestamp, found, _ := unstructured.NestedString(response.Object, "status", "expirationTimestamp"); found {
+		if parsed, errParse := time.Parse(time.RFC3339, timestamp); errParse == nil {
+			expiration = parsed
+		}
+	}
+	adminKubeconfigs.Add(key, cachedKubeconfig{kubeconfig: kubeconfig, expiration: expiration})
+	return kubeconfig, nil
+}
@@ -0,0 +1,114 @@
+package config
+
+import (
+	"context"
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/rest"
+	clienttesting "k8s.io/client-go/testing"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	insight "github.com/openmcp-project/metrics-operator/api/v1alpha1"
+)
+
+func shoot(name string, labels map[string]string, hibernated bool) *unstructured.Unstructured {
+	s := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{"hibernation": map[string]interface{}{"enabled": hibernated}},
+	}}
+	s.SetGroupVersionKind(shootGVK)
+	s.SetNamespace("garden-project")
+	s.SetName(name)
+	s.SetLabels(labels)
+	return s
+}
+
+func TestCreateExternalQueryConfigSetWithGardener(t *testing.T) {
+	adminKubeconfigs.Purge()
+	t.Cleanup(adminKubeconfigs.Purge)
+
+	fca := insight.FederatedClusterAccess{
+		ObjectMeta: metav1.ObjectMeta{Name: "shoots", Namespace: "garden-project"},
+		Spec: insight.FederatedClusterAccessSpec{
+			Gardener: &insight.GardenerShoots{
+				Selector:          &metav1.LabelSelector{MatchLabels: map[string]string{"stage": "live"}},
+				ExpirationSeconds: 600,
+			},
+			ClusterNameFrom: insight.ClusterNameFromMember,
+			MemberLabels:    []string{"stage"},
+		},
+	}
+	mockClient := &MockClient{
+		GetFunc: func(_ context.Context, _ client.ObjectKey, obj client.Object, _ ...client.GetOption) error {
+			*obj.(*insight.FederatedClusterAccess) = fca
+			return nil
+		},
+	}
+
+	fakeDynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{shootGVR: "ShootList"},
+		shoot("live", map[string]string{"stage": "live"}, false),
+		shoot("sleeping", map[string]string{"stage": "live"}, true),
+		shoot("canary", map[string]string{"stage": "canary"}, false),
+	)
+	var requests []string
+	fakeDynamicClient.PrependReactor("create", "shoots", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		create := action.(clienttesting.CreateAction)
+		require.Equal(t, adminKubeconfigSubresource, create.GetSubresource())
+		request := create.GetObject().(*unstructured.Unstructured)
+		expirationSeconds, _, _ := unstructured.NestedInt64(request.Object, "spec", "expirationSeconds")
+		require.Equal(t, int64(600), expirationSeconds)
+		requests = append(requests, request.GetName())
+
+		return true, &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": adminKubeconfigRequestGV.String(),
+			"kind":       "AdminKubeconfigRequest",
+			"status": map[string]interface{}{
+				"kubeconfig":          base64.StdEncoding.EncodeToString([]byte(createDummyKubeconfigAsString())),
+				"expirationTimestamp": time.Now().Add(10 * time.Minute).UTC().Format(time.RFC3339),
+			},
+		}}, nil
+	})
+	opts := CreateExternalQueryConfigSetOptions{
+		GetDynamicClient: func(*rest.Config) (dynamic.Interface, error) { return fakeDynamicClient, nil },
+	}
+
+	ref := insight.FederateClusterAccessRef{Name: "shoots", Namespace: "garden-project"}
+	queryConfigs, err := CreateExternalQueryConfigSet(context.Background(), ref, mockClient, &rest.Config{}, opts)
+	require.NoError(t, err)
+	require.Len(t, queryConfigs, 1, "hibernated and unselected shoots are skipped")
+	require.Equal(t, "live", *queryConfigs[0].ClusterName)
+	require.Equal(t, map[string]string{"stage": "live"}, queryConfigs[0].ClusterLabels)
+	require.Equal(t, []string{"live"}, requests)
+
+	// the kubeconfig is reused until it is about to expire
+	_, err = CreateExternalQueryConfigSet(context.Background(), ref, mockClient, &rest.Config{}, opts)
+	require.NoError(t, err)
+	require.Equal(t, []string{"live"}, requests)
+
+	adminKubeconfigs.Add("garden-project/live/600", cachedKubeconfig{expiration: time.Now().Add(time.Minute)})
+	_, err = CreateExternalQueryConfigSet(context.Background(), ref, mockClient, &rest.Config{}, opts)
+	require.NoError(t, err)
+	require.Equal(t, []string{"live", "live"}, requests)
+}
+
+func TestFederatedMemberOfGardener(t *testing.T) {
+	fca := &insight.FederatedClusterAccess{
+		ObjectMeta: metav1.ObjectMeta{Name: "shoots", Namespace: "garden-project"},
+		Spec:       insight.FederatedClusterAccessSpec{Gardener: &insight.GardenerShoots{}},
+	}
+	require.Equal(t, shootGVK, FederatedMemberGVK(fca))
+	require.Equal(t, "garden-project", FederatedMemberNamespace(fca))
+	require.Equal(t, int64(insight.DefaultAdminKubeconfigExpirationSeconds), fca.Spec.Gardener.GetExpirationSeconds())
+
+	fca.Spec.Namespace = "garden-other"
+	require.Equal(t, "garden-other", FederatedMemberNamespace(fca))
+}
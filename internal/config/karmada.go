@@ -0,0 +1,83 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/openmcp-project/metrics-operator/api/v1alpha1"
+	"github.com/openmcp-project/metrics-operator/internal/orchestrator"
+)
+
+const (
+	// karmadaTokenKey and karmadaCAKey are the keys of the Secret referenced by a Karmada Cluster
+	karmadaTokenKey = "token"
+	karmadaCAKey    = "caBundle"
+)
+
+var (
+	karmadaClusterGVK = schema.GroupVersionKind{Group: "cluster.karmada.io", Version: "v1alpha1", Kind: "Cluster"}
+	karmadaClusterGVR = karmadaClusterGVK.GroupVersion().WithResource("clusters")
+)
+
+// listKarmadaClusters lists the Karmada Clusters selected by a federated cluster access,
+// skipping Clusters in deletion and Clusters without API endpoint or Secret reference
+func listKarmadaClusters(ctx context.Context, set *v1alpha1.FederatedClusterAccess, dynamicClient dynamic.Interface) (*unstructured.UnstructuredList, error) {
+	selector := labels.Everything()
+	if set.Spec.Karmada.Selector != nil {
+		var err error
+		if selector, err = metav1.LabelSelectorAsSelector(set.Spec.Karmada.Selector); err != nil {
+			return nil, fmt.Errorf("invalid cluster selector: %w", err)
+		}
+	}
+
+	list, err := dynamicClient.Resource(karmadaClusterGVR).List(ctx, metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return nil, fmt.Errorf("could not list karmada clusters matching selector '%s'. %w", selector.String(), err)
+	}
+	list.Items = slices.DeleteFunc(list.Items, func(cluster unstructured.Unstructured) bool {
+		endpoint, _, _ := unstructured.NestedString(cluster.Object, "spec", "apiEndpoint")
+		secretName, _, _ := unstructured.NestedString(cluster.Object, "spec", "secretRef", "name")
+		return endpoint == "" || secretName == "" || cluster.GetDeletionTimestamp() != nil
+	})
+	return list, nil
+}
+
+// queryConfigsFromKarmadaClusters creates a query config for each of the listed Karmada Clusters
+// from its API endpoint and the token of its referenced Secret
+func queryConfigsFromKarmadaClusters(ctx context.Context, set *v1alpha1.FederatedClusterAccess, list *unstructured.UnstructuredList, inClient client.Client) ([]orchestrator.QueryConfig, error) {
+	queryConfigs := make([]orchestrator.QueryConfig, 0, len(list.Items))
+	for i := range list.Items {
+		cluster := &list.Items[i]
+		endpoint, _, _ := unstructured.NestedString(cluster.Object, "spec", "apiEndpoint")
+		insecure, _, _ := unstructured.NestedBool(cluster.Object, "spec", "insecureSkipTLSVerification")
+		secretRef, _, _ := unstructured.NestedStringMap(cluster.Object, "spec", "secretRef")
+
+		secret := &corev1.Secret{}
+		key := types.NamespacedName{Namespace: secretRef["namespace"], Name: secretRef["name"]}
+		if err := inClient.Get(ctx, key, secret); err != nil {
+			return nil, fmt.Errorf("failed to retrieve Secret '%s' of karmada cluster '%s': %w", key, cluster.GetName(), err)
+		}
+		token, ok := secret.Data[karmadaTokenKey]
+		if !ok {
+			return nil, fmt.Errorf("token key %s not found in Secret '%s'", karmadaTokenKey, key)
+		}
+
+		qc, err := queryConfigFromToken(endpoint, secret.Data[karmadaCAKey], string(token), insecure)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create query config for karmada cluster '%s': %w", cluster.GetName(), err)
+		}
+		setMemberClusterMetadata(set, cluster, qc)
+		queryConfigs = append(queryConfigs, *qc)
+	}
+	return queryConfigs, nil
+}
@@ -0,0 +1,74 @@
+package config
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	insight "github.com/openmcp-project/metrics-operator/api/v1alpha1"
+)
+
+func karmadaCluster(name, endpoint string, secretRef map[string]interface{}) *unstructured.Unstructured {
+	spec := map[string]interface{}{"apiEndpoint": endpoint, "insecureSkipTLSVerification": true}
+	if secretRef != nil {
+		spec["secretRef"] = secretRef
+	}
+	cluster := &unstructured.Unstructured{Object: map[string]interface{}{"spec": spec}}
+	cluster.SetGroupVersionKind(karmadaClusterGVK)
+	cluster.SetName(name)
+	return cluster
+}
+
+func TestCreateExternalQueryConfigSetWithKarmada(t *testing.T) {
+	fca := insight.FederatedClusterAccess{
+		ObjectMeta: metav1.ObjectMeta{Name: "karmada", Namespace: "default"},
+		Spec: insight.FederatedClusterAccessSpec{
+			Karmada:         &insight.KarmadaClusters{},
+			ClusterNameFrom: insight.ClusterNameFromMember,
+		},
+	}
+	var secretKeys []client.ObjectKey
+	mockClient := &MockClient{
+		GetFunc: func(_ context.Context, key client.ObjectKey, obj client.Object, _ ...client.GetOption) error {
+			switch obj := obj.(type) {
+			case *insight.FederatedClusterAccess:
+				*obj = fca
+			case *corev1.Secret:
+				secretKeys = append(secretKeys, key)
+				*obj = corev1.Secret{Data: map[string][]byte{karmadaTokenKey: []byte("token")}}
+			}
+			return nil
+		},
+	}
+
+	fakeDynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{karmadaClusterGVR: "ClusterList"},
+		karmadaCluster("member1", "https://member1.example.com", map[string]interface{}{"namespace": "karmada-cluster", "name": "member1"}),
+		karmadaCluster("pull", "https://pull.example.com", nil),
+	)
+	opts := CreateExternalQueryConfigSetOptions{
+		GetDynamicClient: func(*rest.Config) (dynamic.Interface, error) { return fakeDynamicClient, nil },
+	}
+
+	ref := insight.FederateClusterAccessRef{Name: "karmada", Namespace: "default"}
+	queryConfigs, err := CreateExternalQueryConfigSet(context.Background(), ref, mockClient, &rest.Config{}, opts)
+	require.NoError(t, err)
+	require.Len(t, queryConfigs, 1, "clusters without secret ref are skipped")
+	require.Equal(t, "member1", *queryConfigs[0].ClusterName)
+	require.Equal(t, "https://member1.example.com", queryConfigs[0].RestConfig.Host)
+	require.Equal(t, "token", queryConfigs[0].RestConfig.BearerToken)
+	require.True(t, queryConfigs[0].RestConfig.Insecure)
+	require.Equal(t, []client.ObjectKey{{Namespace: "karmada-cluster", Name: "member1"}}, secretKeys)
+
+	require.Equal(t, karmadaClusterGVK, FederatedMemberGVK(&fca))
+}
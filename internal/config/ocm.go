@@ -0,0 +1,103 @@
+package config
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"slices"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/openmcp-project/metrics-operator/api/v1alpha1"
+	"github.com/openmcp-project/metrics-operator/internal/orchestrator"
+)
+
+const (
+	// ocmTokenKey and ocmCAKey are the keys of the token Secret of a ManagedServiceAccount
+	ocmTokenKey = "token"
+	ocmCAKey    = "ca.crt"
+)
+
+var (
+	ocmManagedClusterGVK = schema.GroupVersionKind{Group: "cluster.open-cluster-management.io", Version: "v1", Kind: "ManagedCluster"}
+	ocmManagedClusterGVR = ocmManagedClusterGVK.GroupVersion().WithResource("managedclusters")
+)
+
+// listManagedClusters lists the ManagedClusters selected by a federated cluster access,
+// skipping ManagedClusters in deletion and ManagedClusters without a client config URL
+func listManagedClusters(ctx context.Context, set *v1alpha1.FederatedClusterAccess, dynamicClient dynamic.Interface) (*unstructured.UnstructuredList, error) {
+	selector := labels.Everything()
+	if set.Spec.OCM.Selector != nil {
+		var err error
+		if selector, err = metav1.LabelSelectorAsSelector(set.Spec.OCM.Selector); err != nil {
+			return nil, fmt.Errorf("invalid managed cluster selector: %w", err)
+		}
+	}
+
+	list, err := dynamicClient.Resource(ocmManagedClusterGVR).List(ctx, metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return nil, fmt.Errorf("could not list managed clusters matching selector '%s'. %w", selector.String(), err)
+	}
+	list.Items = slices.DeleteFunc(list.Items, func(cluster unstructured.Unstructured) bool {
+		url, _, _ := managedClusterClientConfig(&cluster)
+		return url == "" || cluster.GetDeletionTimestamp() != nil
+	})
+	return list, nil
+}
+
+// queryConfigsFromManagedClusters creates a query config for each of the listed ManagedClusters
+// from its client config and the token Secret of the ManagedServiceAccount in its namespace on the hub
+func queryConfigsFromManagedClusters(ctx context.Context, set *v1alpha1.FederatedClusterAccess, list *unstructured.UnstructuredList, inClient client.Client) ([]orchestrator.QueryConfig, error) {
+	queryConfigs := make([]orchestrator.QueryConfig, 0, len(list.Items))
+	for i := range list.Items {
+		cluster := &list.Items[i]
+		url, caBundle, err := managedClusterClientConfig(cluster)
+		if err != nil {
+			return nil, fmt.Errorf("invalid client config of managed cluster '%s': %w", cluster.GetName(), err)
+		}
+
+		secret := &corev1.Secret{}
+		key := types.NamespacedName{Namespace: cluster.GetName(), Name: set.Spec.OCM.ManagedServiceAccount}
+		if err := inClient.Get(ctx, key, secret); err != nil {
+			return nil, fmt.Errorf("failed to retrieve token Secret '%s' of managed cluster '%s': %w", key, cluster.GetName(), err)
+		}
+		token, ok := secret.Data[ocmTokenKey]
+		if !ok {
+			return nil, fmt.Errorf("token key %s not found in Secret '%s'", ocmTokenKey, key)
+		}
+		if ca, ok := secret.Data[ocmCAKey]; ok {
+			caBundle = ca
+		}
+
+		qc, err := queryConfigFromToken(url, caBundle, string(token), false)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create query config for managed cluster '%s': %w", cluster.GetName(), err)
+		}
+		setMemberClusterMetadata(set, cluster, qc)
+		queryConfigs = append(queryConfigs, *qc)
+	}
+	return queryConfigs, nil
+}
+
+// managedClusterClientConfig returns the URL and the CA bundle of the first client config of a ManagedCluster
+func managedClusterClientConfig(cluster *unstructured.Unstructured) (string, []byte, error) {
+	clientConfigs, _, _ := unstructured.NestedSlice(cluster.Object, "spec", "managedClusterClientConfigs")
+	if len(clientConfigs) == 0 {
+		return "", nil, nil
+	}
+	clientConfig, _ := clientConfigs[0].(map[string]interface{})
+	url, _, _ := unstructured.NestedString(clientConfig, "url")
+	encoded, _, _ := unstructured.NestedString(clientConfig, "caBundle")
+	caBundle, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid caBundle: %w", err)
+	}
+	return url, caBundle, nil
+}
@@ -0,0 +1,92 @@
+package config
+
+import (
+	"context"
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	insight "github.com/openmcp-project/metrics-operator/api/v1alpha1"
+)
+
+func managedCluster(name string, labels map[string]string, url, caBundle string) *unstructured.Unstructured {
+	cluster := &unstructured.Unstructured{Object: map[string]interface{}{"spec": map[string]interface{}{}}}
+	if url != "" {
+		cluster.Object["spec"] = map[string]interface{}{"managedClusterClientConfigs": []interface{}{
+			map[string]interface{}{"url": url, "caBundle": base64.StdEncoding.EncodeToString([]byte(caBundle))},
+		}}
+	}
+	cluster.SetGroupVersionKind(ocmManagedClusterGVK)
+	cluster.SetName(name)
+	cluster.SetLabels(labels)
+	return cluster
+}
+
+func TestCreateExternalQueryConfigSetWithOCM(t *testing.T) {
+	fca := insight.FederatedClusterAccess{
+		ObjectMeta: metav1.ObjectMeta{Name: "hub", Namespace: "default"},
+		Spec: insight.FederatedClusterAccessSpec{
+			OCM: &insight.OCMManagedClusters{
+				Selector:              &metav1.LabelSelector{MatchLabels: map[string]string{"cluster.open-cluster-management.io/clusterset": "prod"}},
+				ManagedServiceAccount: "metrics",
+			},
+		},
+	}
+	var secretKeys []client.ObjectKey
+	mockClient := &MockClient{
+		GetFunc: func(_ context.Context, key client.ObjectKey, obj client.Object, _ ...client.GetOption) error {
+			switch obj := obj.(type) {
+			case *insight.FederatedClusterAccess:
+				*obj = fca
+			case *corev1.Secret:
+				secretKeys = append(secretKeys, key)
+				*obj = corev1.Secret{Data: map[string][]byte{ocmTokenKey: []byte("token")}}
+			}
+			return nil
+		},
+	}
+
+	prod := map[string]string{"cluster.open-cluster-management.io/clusterset": "prod"}
+	fakeDynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{ocmManagedClusterGVR: "ManagedClusterList"},
+		managedCluster("cluster1", prod, "https://cluster1.example.com:6443", ""),
+		managedCluster("pending", prod, "", ""),
+		managedCluster("dev", map[string]string{"cluster.open-cluster-management.io/clusterset": "dev"}, "https://dev.example.com", ""),
+	)
+	opts := CreateExternalQueryConfigSetOptions{
+		GetDynamicClient: func(*rest.Config) (dynamic.Interface, error) { return fakeDynamicClient, nil },
+	}
+
+	ref := insight.FederateClusterAccessRef{Name: "hub", Namespace: "default"}
+	queryConfigs, err := CreateExternalQueryConfigSet(context.Background(), ref, mockClient, &rest.Config{}, opts)
+	require.NoError(t, err)
+	require.Len(t, queryConfigs, 1, "managed clusters without client config and not selected are skipped")
+	require.Equal(t, "cluster1.example.com", *queryConfigs[0].ClusterName)
+	require.Equal(t, "https://cluster1.example.com:6443", queryConfigs[0].RestConfig.Host)
+	require.Equal(t, "token", queryConfigs[0].RestConfig.BearerToken)
+	require.Equal(t, []client.ObjectKey{{Namespace: "cluster1", Name: "metrics"}}, secretKeys)
+
+	require.Equal(t, ocmManagedClusterGVK, FederatedMemberGVK(&fca))
+	require.Equal(t, "", FederatedMemberNamespace(&fca))
+}
+
+func TestManagedClusterClientConfig(t *testing.T) {
+	url, caBundle, err := managedClusterClientConfig(managedCluster("cluster1", nil, "https://cluster1.example.com", "ca"))
+	require.NoError(t, err)
+	require.Equal(t, "https://cluster1.example.com", url)
+	require.Equal(t, []byte("ca"), caBundle)
+
+	url, _, err = managedClusterClientConfig(managedCluster("pending", nil, "", ""))
+	require.NoError(t, err)
+	require.Empty(t, url)
+}
@@ -0,0 +1,249 @@
+package config
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	lru "github.com/hashicorp/golang-lru/v2"
+	"golang.org/x/oauth2"
+
+	"github.com/openmcp-project/metrics-operator/api/v1alpha1"
+)
+
+const (
+	issuerURLKey    = "issuerURL"
+	clientIDKey     = "clientID"
+	clientSecretKey = "clientSecret"
+
+	grantTypeClientCredentials = "client_credentials"
+	grantTypeTokenExchange     = "urn:ietf:params:oauth:grant-type:token-exchange"
+	tokenTypeJWT               = "urn:ietf:params:oauth:token-type:jwt"
+
+	// defaultOIDCTokenLifetime is assumed for tokens the issuer returns without expires_in
+	defaultOIDCTokenLifetime = 15 * time.Minute
+	oidcRequestTimeout       = 30 * time.Second
+)
+
+var (
+	oidcInstance *OIDCTokenManager
+	oidcOnce     sync.Once
+)
+
+// subjectTokenFunc returns the token that is exchanged at the issuer with the token exchange grant
+type subjectTokenFunc func(ctx context.Context) (string, error)
+
+// oidcConfig is the issuer configuration of a remote cluster, read from the cluster secret and the ClusterAccessConfig
+type oidcConfig struct {
+	issuerURL    string
+	clientID     string
+	clientSecret string
+	audience     string
+	grantType    string
+	scopes       []string
+
+	// subjectServiceAccount is the namespace/name of the service account whose token is exchanged with the
+	// token exchange grant, subjectAudience is the audience of its token
+	subjectServiceAccount string
+	subjectAudience       string
+}
+
+func (c *oidcConfig) getKey() string {
+	// the secret is hashed, so rotated credentials result in a new token source.
+	// The subject is part of the key, so accesses exchanging the tokens of different service accounts at the same
+	// issuer don't share a token source.
+	return fmt.Sprintf("%s-%s-%x-%s-%s-%s-%s-%s", c.issuerURL, c.clientID, sha256.Sum256([]byte(c.clientSecret)), c.audience, c.grantType,
+		strings.Join(c.scopes, " "), c.subjectServiceAccount, c.subjectAudience)
+}
+
+// OIDCTokenManager keeps one token source per issuer configuration.
+// The token sources cache their token and request a new one shortly before it expires.
+// It is a singleton.
+type OIDCTokenManager struct {
+	httpClient *http.Client
+	cache      *lru.Cache[string, oauth2.TokenSource]
+
+	// refreshBuffer is the time before the actual expiration time to refresh the token
+	refreshBuffer time.Duration
+}
+
+// GetOIDCTokenManager returns the singleton instance of OIDCTokenManager.
+func GetOIDCTokenManager() (*OIDCTokenManager, error) {
+	var err error
+	oidcOnce.Do(func() {
+		oidcInstance, err = newOIDCTokenManager(&http.Client{Timeout: oidcRequestTimeout})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return oidcInstance, nil
+}
+
+func newOIDCTokenManager(httpClient *http.Client) (*OIDCTokenManager, error) {
+	cache, err := lru.New[string, oauth2.TokenSource](cacheSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create lru cache: %w", err)
+	}
+
+	return &OIDCTokenManager{
+		httpClient:    httpClient,
+		cache:         cache,
+		refreshBuffer: marginFromExpirationTime,
+	}, nil
+}
+
+// TokenSource returns the token source for the given issuer configuration.
+// A new token source requests its first token with the given context, so a misconfigured issuer is reported right away.
+// The subject token is only requested for the token exchange grant.
+func (m *OIDCTokenManager) TokenSource(ctx context.Context, cfg oidcConfig, subjectToken subjectTokenFunc) (oauth2.TokenSource, error) {
+	key := cfg.getKey()
+	if ts, ok := m.cache.Get(key); ok {
+		return ts, nil
+	}
+
+	src := &oidcTokenSource{
+		httpClient:   m.httpClient,
+		config:       cfg,
+		subjectToken: subjectToken,
+	}
+	token, err := src.token(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ts := oauth2.ReuseTokenSourceWithExpiry(token, src, m.refreshBuffer)
+	m.cache.Add(key, ts)
+	return ts, nil
+}
+
+// oidcTokenSource requests a new token from the issuer on every call
+type oidcTokenSource struct {
+	httpClient   *http.Client
+	config       oidcConfig
+	subjectToken subjectTokenFunc
+
+	// tokenURL is discovered from the issuer on the first request
+	tokenURL string
+}
+
+type oidcDiscovery struct {
+	TokenEndpoint string `json:"token_endpoint"`
+}
+
+type oidcTokenResponse struct {
+	AccessToken string `json:"access_token"`
+	ExpiresIn   int64  `json:"expires_in"`
+}
+
+// Token implements oauth2.TokenSource. It is called by the transport of the clients to refresh the token,
+// which has no context of its own, so the request is only bound by the request timeout.
+func (s *oidcTokenSource) Token() (*oauth2.Token, error) {
+	return s.token(context.Background())
+}
+
+// token requests a new token from the issuer
+func (s *oidcTokenSource) token(ctx context.Context) (*oauth2.Token, error) {
+	ctx, cancel := context.WithTimeout(ctx, oidcRequestTimeout)
+	defer cancel()
+
+	if s.tokenURL == "" {
+		tokenURL, err := s.discoverTokenURL(ctx)
+		if err != nil {
+			return nil, err
+		}
+		s.tokenURL = tokenURL
+	}
+
+	form := url.Values{}
+	if s.config.audience != "" {
+		form.Set("audience", s.config.audience)
+	}
+	if len(s.config.scopes) > 0 {
+		form.Set("scope", strings.Join(s.config.scopes, " "))
+	}
+
+	switch s.config.grantType {
+	case v1alpha1.OIDCGrantTokenExchange:
+		subjectToken, err := s.subjectToken(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get subject token: %w", err)
+		}
+		form.Set("grant_type", grantTypeTokenExchange)
+		form.Set("subject_token", subjectToken)
+		form.Set("subject_token_type", tokenTypeJWT)
+	default:
+		form.Set("grant_type", grantTypeClientCredentials)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if s.config.clientID != "" {
+		req.SetBasicAuth(url.QueryEscape(s.config.clientID), url.QueryEscape(s.config.clientSecret))
+	}
+
+	var tr oidcTokenResponse
+	if err := s.do(req, &tr); err != nil {
+		return nil, fmt.Errorf("failed to request token from issuer '%s': %w", s.config.issuerURL, err)
+	}
+	if tr.AccessToken == "" {
+		return nil, fmt.Errorf("issuer '%s' returned no access token", s.config.issuerURL)
+	}
+
+	lifetime := defaultOIDCTokenLifetime
+	if tr.ExpiresIn > 0 {
+		lifetime = time.Duration(tr.ExpiresIn) * time.Second
+	}
+
+	// the token is always sent as bearer token to the API server, regardless of the token type of the issuer
+	return &oauth2.Token{
+		AccessToken: tr.AccessToken,
+		TokenType:   "Bearer",
+		Expiry:      time.Now().Add(lifetime),
+	}, nil
+}
+
+func (s *oidcTokenSource) discoverTokenURL(ctx context.Context) (string, error) {
+	discoveryURL := strings.TrimSuffix(s.config.issuerURL, "/") + "/.well-known/openid-configuration"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, discoveryURL, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create discovery request: %w", err)
+	}
+
+	var discovery oidcDiscovery
+	if err := s.do(req, &discovery); err != nil {
+		return "", fmt.Errorf("failed to discover issuer '%s': %w", s.config.issuerURL, err)
+	}
+	if discovery.TokenEndpoint == "" {
+		return "", fmt.Errorf("issuer '%s' does not provide a token endpoint", s.config.issuerURL)
+	}
+	return discovery.TokenEndpoint, nil
+}
+
+func (s *oidcTokenSource) do(req *http.Request, into any) error {
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	if err := json.Unmarshal(body, into); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
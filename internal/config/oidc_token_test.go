@@ -0,0 +1,171 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+
+	"github.com/openmcp-project/metrics-operator/api/v1alpha1"
+)
+
+func newFakeIssuer(t *testing.T, expiresIn int64, check func(r *http.Request)) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var tokenRequests atomic.Int32
+
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, _ *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]string{"token_endpoint": srv.URL + "/token"})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		check(r)
+		tokenRequests.Add(1)
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"access_token": "issued-token",
+			"token_type":   "N_A",
+			"expires_in":   expiresIn,
+		})
+	})
+	return srv, &tokenRequests
+}
+
+func TestOIDCTokenSource_ClientCredentials(t *testing.T) {
+	clientSecret := "secret"
+	srv, tokenRequests := newFakeIssuer(t, 3600, func(r *http.Request) {
+		require.Equal(t, grantTypeClientCredentials, r.PostForm.Get("grant_type"))
+		require.Equal(t, "remote-cluster", r.PostForm.Get("audience"))
+		require.Equal(t, "openid groups", r.PostForm.Get("scope"))
+		id, secret, ok := r.BasicAuth()
+		require.True(t, ok)
+		require.Equal(t, "client", id)
+		require.Equal(t, clientSecret, secret)
+	})
+
+	tm, err := newOIDCTokenManager(srv.Client())
+	require.NoError(t, err)
+
+	cfg := oidcConfig{
+		issuerURL:    srv.URL,
+		clientID:     "client",
+		clientSecret: "secret",
+		audience:     "remote-cluster",
+		grantType:    v1alpha1.OIDCGrantClientCredentials,
+		scopes:       []string{"openid", "groups"},
+	}
+	ts, err := tm.TokenSource(context.Background(), cfg, nil)
+	require.NoError(t, err)
+
+	token, err := ts.Token()
+	require.NoError(t, err)
+	require.Equal(t, "issued-token", token.AccessToken)
+	require.Equal(t, "Bearer", token.Type())
+
+	// the token is reused, also by token sources of later reconciles
+	cached, err := tm.TokenSource(context.Background(), cfg, nil)
+	require.NoError(t, err)
+	_, err = cached.Token()
+	require.NoError(t, err)
+	require.Equal(t, int32(1), tokenRequests.Load())
+
+	// rotated credentials use a new token source
+	clientSecret = "rotated"
+	cfg.clientSecret = clientSecret
+	rotated, err := tm.TokenSource(context.Background(), cfg, nil)
+	require.NoError(t, err)
+	require.NotSame(t, ts, rotated)
+}
+
+func TestOIDCTokenSource_RefreshBeforeExpiry(t *testing.T) {
+	// the token expires within the refresh buffer, so it is requested again on every use
+	srv, tokenRequests := newFakeIssuer(t, 60, func(*http.Request) {})
+
+	tm, err := newOIDCTokenManager(srv.Client())
+	require.NoError(t, err)
+
+	ts, err := tm.TokenSource(context.Background(), oidcConfig{issuerURL: srv.URL, clientID: "client", clientSecret: "secret"}, nil)
+	require.NoError(t, err)
+	for range 3 {
+		_, err := ts.Token()
+		require.NoError(t, err)
+	}
+	require.Equal(t, int32(4), tokenRequests.Load())
+}
+
+func TestOIDCTokenSource_TokenExchange(t *testing.T) {
+	srv, _ := newFakeIssuer(t, 3600, func(r *http.Request) {
+		require.Equal(t, grantTypeTokenExchange, r.PostForm.Get("grant_type"))
+		require.Equal(t, "service-account-token", r.PostForm.Get("subject_token"))
+		require.Equal(t, tokenTypeJWT, r.PostForm.Get("subject_token_type"))
+		_, _, ok := r.BasicAuth()
+		require.False(t, ok)
+	})
+
+	tm, err := newOIDCTokenManager(srv.Client())
+	require.NoError(t, err)
+
+	ts, err := tm.TokenSource(context.Background(), oidcConfig{issuerURL: srv.URL, grantType: v1alpha1.OIDCGrantTokenExchange}, func(context.Context) (string, error) {
+		return "service-account-token", nil
+	})
+	require.NoError(t, err)
+	token, err := ts.Token()
+	require.NoError(t, err)
+	require.Equal(t, "issued-token", token.AccessToken)
+}
+
+func TestOIDCTokenSource_IssuerError(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	t.Cleanup(srv.Close)
+
+	tm, err := newOIDCTokenManager(srv.Client())
+	require.NoError(t, err)
+
+	_, err = tm.TokenSource(context.Background(), oidcConfig{issuerURL: srv.URL}, nil)
+	require.ErrorContains(t, err, "failed to discover issuer")
+}
+
+func TestOIDCTokenSource_TokenExchangeSubjects(t *testing.T) {
+	srv, _ := newFakeIssuer(t, 3600, func(*http.Request) {})
+
+	tm, err := newOIDCTokenManager(srv.Client())
+	require.NoError(t, err)
+
+	// accesses exchanging the tokens of different service accounts at the same issuer get their own token source
+	var subjects []string
+	tokenSource := func(serviceAccount, audience string) oauth2.TokenSource {
+		cfg := oidcConfig{issuerURL: srv.URL, grantType: v1alpha1.OIDCGrantTokenExchange, subjectServiceAccount: serviceAccount, subjectAudience: audience}
+		ts, err := tm.TokenSource(context.Background(), cfg, func(context.Context) (string, error) {
+			subjects = append(subjects, serviceAccount)
+			return serviceAccount, nil
+		})
+		require.NoError(t, err)
+		return ts
+	}
+	a := tokenSource("team-a/collector", srv.URL)
+	require.NotSame(t, a, tokenSource("team-b/collector", srv.URL))
+	require.NotSame(t, a, tokenSource("team-a/collector", "other-audience"))
+	require.Same(t, a, tokenSource("team-a/collector", srv.URL))
+	require.Equal(t, []string{"team-a/collector", "team-b/collector", "team-a/collector"}, subjects)
+}
+
+func TestOIDCTokenSource_Context(t *testing.T) {
+	srv, tokenRequests := newFakeIssuer(t, 3600, func(*http.Request) {})
+
+	tm, err := newOIDCTokenManager(srv.Client())
+	require.NoError(t, err)
+
+	// the first token is requested with the context of the caller
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = tm.TokenSource(ctx, oidcConfig{issuerURL: srv.URL, clientID: "client", clientSecret: "secret"}, nil)
+	require.ErrorIs(t, err, context.Canceled)
+	require.Zero(t, tokenRequests.Load())
+}
@@ -0,0 +1,247 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	"github.com/openmcp-project/metrics-operator/api/v1alpha1"
+	"github.com/openmcp-project/metrics-operator/internal/common"
+	"github.com/openmcp-project/metrics-operator/internal/metriclist"
+)
+
+// ClusterMetricsStatusResyncInterval is how often the summary is computed without changes to the metrics,
+// so metrics that are no longer observed are counted as stale
+const ClusterMetricsStatusResyncInterval = time.Minute
+
+// NewClusterMetricsStatusReconciler creates a new ClusterMetricsStatusReconciler
+func NewClusterMetricsStatusReconciler(mgr ctrl.Manager) *ClusterMetricsStatusReconciler {
+	return &ClusterMetricsStatusReconciler{
+		log: mgr.GetLogger().WithName("controllers").WithName("ClusterMetricsStatus"),
+
+		inCli: mgr.GetClient(),
+	}
+}
+
+// ClusterMetricsStatusReconciler maintains the ClusterMetricsStatus summarizing the health of all metrics
+type ClusterMetricsStatusReconciler struct {
+	log logr.Logger
+
+	inCli client.Client
+}
+
+func (r *ClusterMetricsStatusReconciler) getClient() client.Client {
+	return r.inCli
+}
+
+// metricHealth is the part of a metric the summary is computed from
+type metricHealth struct {
+	ready string
+	// lastExport is the time of the last observation, or the creation time of metrics that were never observed
+	lastExport time.Time
+	schedule   exportSchedule
+}
+
+func newMetricHealth(metric metav1.Object, ready string, lastExport time.Time, interval metav1.Duration, schedule string) metricHealth {
+	// an invalid schedule is reported by the metric itself, the interval is used instead
+	s, _ := newExportSchedule(interval, schedule, metric)
+	if lastExport.IsZero() {
+		lastExport = metric.GetCreationTimestamp().Time
+	}
+	return metricHealth{ready: ready, lastExport: lastExport, schedule: s}
+}
+
+// stale returns true if the metric was not observed within twice the time between two of its exports
+func (h metricHealth) stale(now time.Time) bool {
+	period := h.schedule.period(h.lastExport)
+	return period > 0 && now.After(h.lastExport.Add(2*period))
+}
+
+// summarizeMetrics counts the metrics of a kind by their health
+func summarizeMetrics(kind string, metrics []metricHealth, now time.Time) v1alpha1.MetricKindSummary {
+	summary := v1alpha1.MetricKindSummary{Kind: kind, Total: len(metrics)}
+	for _, metric := range metrics {
+		switch metric.ready {
+		case v1alpha1.StatusStringTrue:
+			summary.Ready++
+		case v1alpha1.StatusStringFalse:
+			summary.Failing++
+		}
+		if metric.stale(now) {
+			summary.Stale++
+		}
+	}
+	return summary
+}
+
+// metricKinds are the kinds of metrics in the order they are summarized
+var metricKinds = []string{"Metric", "ManagedMetric", "FederatedMetric", "FederatedManagedMetric", "CompositeMetric"}
+
+// listedMetric is a listed metric of any kind with its health
+type listedMetric struct {
+	metriclist.Metric
+	health metricHealth
+}
+
+// listMetrics lists the metrics of all kinds and computes their health
+func listMetrics(ctx context.Context, c client.Reader) ([]listedMetric, error) {
+	metrics, err := metriclist.List(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+	listed := make([]listedMetric, 0, len(metrics))
+	for _, m := range metrics {
+		listed = append(listed, listedMetric{
+			Metric: m,
+			health: newMetricHealth(m.Object(), m.Ready(), m.LastObservation(), m.Interval(), m.Schedule()),
+		})
+	}
+	return listed, nil
+}
+
+// summarize lists the metrics of all kinds and counts them by their health
+func (r *ClusterMetricsStatusReconciler) summarize(ctx context.Context, now time.Time) ([]v1alpha1.MetricKindSummary, error) {
+	listed, err := listMetrics(ctx, r.getClient())
+	if err != nil {
+		return nil, err
+	}
+	healths := make(map[string][]metricHealth, len(metricKinds))
+	for _, m := range listed {
+		healths[m.Kind()] = append(healths[m.Kind()], m.health)
+	}
+
+	summaries := make([]v1alpha1.MetricKindSummary, 0, len(metricKinds))
+	for _, kind := range metricKinds {
+		summaries = append(summaries, summarizeMetrics(kind, healths[kind], now))
+	}
+	return summaries, nil
+}
+
+// setSummary sets the totals and the Ready condition of the ClusterMetricsStatus from the summaries by kind
+func setSummary(status *v1alpha1.ClusterMetricsStatus, kinds []v1alpha1.MetricKindSummary) {
+	status.Status.Kinds = kinds
+	status.Status.Total, status.Status.Ready, status.Status.Failing, status.Status.Stale = 0, 0, 0, 0
+	for _, kind := range kinds {
+		status.Status.Total += kind.Total
+		status.Status.Ready += kind.Ready
+		status.Status.Failing += kind.Failing
+		status.Status.Stale += kind.Stale
+	}
+
+	var problems []string
+	reason := ""
+	if status.Status.Failing > 0 {
+		reason = "MetricsFailing"
+		problems = append(problems, fmt.Sprintf("%d of %d metrics are failing", status.Status.Failing, status.Status.Total))
+	}
+	if status.Status.Stale > 0 {
+		if reason == "" {
+			reason = "MetricsStale"
+		}
+		problems = append(problems, fmt.Sprintf("%d of %d metrics are stale", status.Status.Stale, status.Status.Total))
+	}
+	if len(problems) > 0 {
+		status.SetConditions(common.ReadyFalse(reason, strings.Join(problems, ", ")))
+		return
+	}
+	status.SetConditions(common.ReadyTrue(fmt.Sprintf("%d of %d metrics are ready", status.Status.Ready, status.Status.Total)))
+}
+
+// +kubebuilder:rbac:groups=metrics.openmcp.cloud,resources=clustermetricsstatuses,verbs=get;list;watch;create;update;patch
+// +kubebuilder:rbac:groups=metrics.openmcp.cloud,resources=clustermetricsstatuses/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=metrics.openmcp.cloud,resources=metrics;managedmetrics;federatedmetrics;federatedmanagedmetrics;compositemetrics,verbs=get;list;watch
+
+// Reconcile creates the ClusterMetricsStatus and summarizes the health of all metrics in its status
+func (r *ClusterMetricsStatusReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	if req.Name != v1alpha1.ClusterMetricsStatusName {
+		return ctrl.Result{}, nil
+	}
+	l := r.log.WithValues("name", req.Name)
+
+	status := v1alpha1.ClusterMetricsStatus{}
+	if errLoad := r.getClient().Get(ctx, req.NamespacedName, &status); errLoad != nil {
+		if !apierrors.IsNotFound(errLoad) {
+			l.Error(errLoad, "unable to fetch ClusterMetricsStatus")
+			return ctrl.Result{RequeueAfter: RequeueAfterError}, errLoad
+		}
+		status = v1alpha1.ClusterMetricsStatus{ObjectMeta: metav1.ObjectMeta{Name: v1alpha1.ClusterMetricsStatusName}}
+		if err := r.getClient().Create(ctx, &status); err != nil {
+			l.Error(err, "unable to create ClusterMetricsStatus")
+			return ctrl.Result{RequeueAfter: RequeueAfterError}, err
+		}
+	}
+
+	patchStatus := statusPatch(r.getClient(), &status)
+	now := time.Now()
+	kinds, err := r.summarize(ctx, now)
+	if err != nil {
+		status.SetConditions(common.ReadyFalse("SummaryFailed", err.Error()))
+	} else {
+		setSummary(&status, kinds)
+	}
+	status.Status.LastUpdateTime = &metav1.Time{Time: now}
+
+	if errUpdate := patchStatus(ctx); errUpdate != nil {
+		l.Error(errUpdate, "Failed to update ClusterMetricsStatus status")
+		return ctrl.Result{RequeueAfter: RequeueAfterError}, errUpdate
+	}
+	if err != nil {
+		return ctrl.Result{RequeueAfter: RequeueAfterError}, err
+	}
+	return ctrl.Result{RequeueAfter: ClusterMetricsStatusResyncInterval}, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *ClusterMetricsStatusReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	summary := types.NamespacedName{Name: v1alpha1.ClusterMetricsStatusName}
+	toSummary := handler.EnqueueRequestsFromMapFunc(func(context.Context, client.Object) []reconcile.Request {
+		return []reconcile.Request{{NamespacedName: summary}}
+	})
+
+	// the ClusterMetricsStatus is created when the operator starts, even if there are no metrics
+	start := make(chan event.GenericEvent, 1)
+	start <- event.GenericEvent{Object: &v1alpha1.ClusterMetricsStatus{ObjectMeta: metav1.ObjectMeta{Name: summary.Name}}}
+
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(ClusterMetricsStatusControllerName).
+		WithOptions(Controllers.forController(ClusterMetricsStatusControllerName)).
+		// the status updates of the controller itself need no reconcile
+		For(&v1alpha1.ClusterMetricsStatus{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Watches(&v1alpha1.Metric{}, toSummary).
+		Watches(&v1alpha1.ManagedMetric{}, toSummary).
+		Watches(&v1alpha1.FederatedMetric{}, toSummary).
+		Watches(&v1alpha1.FederatedManagedMetric{}, toSummary).
+		Watches(&v1alpha1.CompositeMetric{}, toSummary).
+		WatchesRawSource(source.Channel(start, &handler.EnqueueRequestForObject{})).
+		Complete(r)
+}
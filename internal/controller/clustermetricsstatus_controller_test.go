@@ -0,0 +1,143 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/openmcp-project/metrics-operator/api/v1alpha1"
+)
+
+func TestMetricHealth_stale(t *testing.T) {
+	withScheduling(t, SchedulingOptions{})
+	now := time.Now()
+	created := metav1.ObjectMeta{Name: "pods", CreationTimestamp: metav1.NewTime(now.Add(-time.Hour))}
+	tenMinutes := metav1.Duration{Duration: 10 * time.Minute}
+
+	testCases := []struct {
+		name       string
+		lastExport time.Time
+		interval   metav1.Duration
+		schedule   string
+		expected   bool
+	}{
+		{
+			name:       "Observed",
+			lastExport: now.Add(-15 * time.Minute),
+			interval:   tenMinutes,
+			expected:   false,
+		},
+		{
+			name:       "NotObservedWithinTwoIntervals",
+			lastExport: now.Add(-25 * time.Minute),
+			interval:   tenMinutes,
+			expected:   true,
+		},
+		{
+			name:     "NeverObserved",
+			interval: tenMinutes,
+			expected: true,
+		},
+		{
+			name:       "Cron",
+			lastExport: now.Add(-25 * time.Minute),
+			interval:   tenMinutes,
+			schedule:   "@daily",
+			expected:   false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			health := newMetricHealth(&v1alpha1.Metric{ObjectMeta: created}, v1alpha1.StatusStringTrue, tc.lastExport, tc.interval, tc.schedule)
+			require.Equal(t, tc.expected, health.stale(now))
+		})
+	}
+}
+
+func TestClusterMetricsStatusReconciler_Reconcile(t *testing.T) {
+	withScheduling(t, SchedulingOptions{})
+	now := time.Now()
+	metric := func(name, ready string, observed time.Time) *v1alpha1.Metric {
+		return &v1alpha1.Metric{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: name, CreationTimestamp: metav1.NewTime(now.Add(-time.Hour))},
+			Spec:       v1alpha1.MetricSpec{Interval: metav1.Duration{Duration: 10 * time.Minute}},
+			Status: v1alpha1.MetricStatus{
+				Ready:       ready,
+				Observation: v1alpha1.MetricObservation{Timestamp: metav1.NewTime(observed)},
+			},
+		}
+	}
+	managed := &v1alpha1.ManagedMetric{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "buckets", CreationTimestamp: metav1.NewTime(now.Add(-time.Hour))},
+		Spec:       v1alpha1.ManagedMetricSpec{Interval: metav1.Duration{Duration: 10 * time.Minute}},
+		Status: v1alpha1.ManagedMetricStatus{
+			Ready:       v1alpha1.StatusStringTrue,
+			Observation: v1alpha1.ManagedObservation{Timestamp: metav1.NewTime(now.Add(-time.Minute))},
+		},
+	}
+
+	scheme := runtime.NewScheme()
+	require.NoError(t, v1alpha1.AddToScheme(scheme))
+	cli := fake.NewClientBuilder().WithScheme(scheme).
+		WithObjects(
+			metric("ready", v1alpha1.StatusStringTrue, now.Add(-time.Minute)),
+			metric("failing", v1alpha1.StatusStringFalse, now.Add(-time.Minute)),
+			metric("stale", v1alpha1.StatusStringTrue, now.Add(-30*time.Minute)),
+			managed,
+		).
+		WithStatusSubresource(&v1alpha1.ClusterMetricsStatus{}).
+		Build()
+	r := &ClusterMetricsStatusReconciler{log: logr.Discard(), inCli: cli}
+
+	// the ClusterMetricsStatus is created by the first reconcile
+	result, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: types.NamespacedName{Name: v1alpha1.ClusterMetricsStatusName}})
+	require.NoError(t, err)
+	require.Equal(t, ClusterMetricsStatusResyncInterval, result.RequeueAfter)
+
+	status := v1alpha1.ClusterMetricsStatus{}
+	require.NoError(t, cli.Get(context.Background(), types.NamespacedName{Name: v1alpha1.ClusterMetricsStatusName}, &status))
+	require.Equal(t, 4, status.Status.Total)
+	require.Equal(t, 3, status.Status.Ready)
+	require.Equal(t, 1, status.Status.Failing)
+	require.Equal(t, 1, status.Status.Stale)
+	require.Contains(t, status.Status.Kinds, v1alpha1.MetricKindSummary{Kind: "Metric", Total: 3, Ready: 2, Failing: 1, Stale: 1})
+	require.Contains(t, status.Status.Kinds, v1alpha1.MetricKindSummary{Kind: "ManagedMetric", Total: 1, Ready: 1})
+	require.Contains(t, status.Status.Kinds, v1alpha1.MetricKindSummary{Kind: "CompositeMetric"})
+	require.NotNil(t, status.Status.LastUpdateTime)
+
+	condition := meta.FindStatusCondition(status.Status.Conditions, v1alpha1.TypeReady)
+	require.NotNil(t, condition)
+	require.Equal(t, metav1.ConditionFalse, condition.Status)
+	require.Equal(t, "MetricsFailing", condition.Reason)
+	require.Equal(t, "1 of 4 metrics are failing, 1 of 4 metrics are stale", condition.Message)
+
+	// other names are ignored
+	_, err = r.Reconcile(context.Background(), ctrl.Request{NamespacedName: types.NamespacedName{Name: "other"}})
+	require.NoError(t, err)
+}
@@ -0,0 +1,288 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/google/uuid"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/events"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/openmcp-project/metrics-operator/api/v1alpha1"
+	"github.com/openmcp-project/metrics-operator/internal/clientoptl"
+	"github.com/openmcp-project/metrics-operator/internal/common"
+	internalmetrics "github.com/openmcp-project/metrics-operator/internal/metrics"
+	orc "github.com/openmcp-project/metrics-operator/internal/orchestrator"
+)
+
+// collectedMetric is the strategy of reconcileCollectedMetric for a kind of metric the operator collects from clusters.
+// The engine runs the steps all kinds share, from checking the schedule to creating the gauge,
+// the strategy describes the metric and collects it.
+type collectedMetric interface {
+	// describe returns the spec and status fields of the metric the shared steps read and update
+	describe() metricDescription
+	// queryConfigs creates the configs of the clusters the metric is collected from
+	queryConfigs(ctx context.Context) ([]orc.QueryConfig, error)
+	// collect monitors the clusters, exports the recorded data points and sets the status of the metric
+	collect(ctx context.Context, c *collection) (ctrl.Result, error)
+}
+
+// delegatedMetric is implemented by the collected metrics that may be collected by someone else, e.g. by agents.
+// delegate returns true if the operator doesn't collect the metric itself, the reconciliation then ends with its result.
+type delegatedMetric interface {
+	delegate(ctx context.Context, schedule exportSchedule) (ctrl.Result, bool, error)
+}
+
+// conditionedMetric is implemented by all collected metric types
+type conditionedMetric interface {
+	staticDimensionsMetric
+	SetConditions(conditions ...metav1.Condition)
+}
+
+// metricDescription holds the spec and status fields of a collected metric
+type metricDescription struct {
+	metric conditionedMetric
+	// kind names the metric in log and status messages, e.g. "managed metric"
+	kind string
+	// action is the action of the events of the metric
+	action string
+
+	name            string
+	description     string
+	unit            string
+	meterName       string
+	scopeAttributes map[string]string
+	dataSinkRef     *v1alpha1.DataSinkReference
+	exporters       clientoptl.ExporterFactory
+	retryPolicy     *v1alpha1.RetryPolicy
+	interval        metav1.Duration
+	schedule        string
+
+	// lastRun is the time of the last collection, the next collection is due at the next run of the schedule after it
+	lastRun time.Time
+	// collectNow collects the metric before its next run is due, it is optional
+	collectNow func(schedule exportSchedule) bool
+
+	ready                *string
+	conditions           *[]metav1.Condition
+	failures             *int32
+	staticDimensionsHash *string
+	nextRunTime          **metav1.Time
+}
+
+// collection is a due collection of a metric, prepared by reconcileCollectedMetric for the strategy of its kind
+type collection struct {
+	metricDescription
+
+	recorder     events.EventRecorder
+	credentials  common.DataSinkCredentials
+	queryConfigs []orc.QueryConfig
+	client       *clientoptl.MetricClient
+	gauge        *clientoptl.Metric
+	schedule     exportSchedule
+	dimensions   staticDimensions
+	failures     *failureBudget
+	log          logr.Logger
+}
+
+// reconcileCollectedMetric reconciles a loaded metric that is not deleted.
+// The status of the metric is updated when it returns, failed collections are retried with the backoff of its retry policy.
+func reconcileCollectedMetric(ctx context.Context, c client.Client, recorder events.EventRecorder, m collectedMetric, l logr.Logger) (res ctrl.Result, errReconcile error) {
+	d := m.describe()
+	metric := d.metric
+
+	// Defer status update to ensure it's always called
+	patchStatus := statusPatch(c, metric)
+	defer func() {
+		if err := patchStatus(ctx); err != nil {
+			l.Error(err, fmt.Sprintf("Failed to update %s status", d.kind))
+		}
+	}()
+
+	// Initialize Ready condition if not present
+	if meta.FindStatusCondition(*d.conditions, v1alpha1.TypeReady) == nil {
+		metric.SetConditions(common.ReadyUnknown("Reconciling", "Initial reconciliation"))
+	}
+
+	failures := newFailureBudget(metric, d.retryPolicy, d.failures, d.ready, d.conditions)
+	if failures.exhausted() {
+		// the metric is reconciled again once its spec changes
+		return ctrl.Result{}, nil
+	}
+
+	schedule, errSchedule := newExportSchedule(d.interval, d.schedule, metric)
+	if errSchedule != nil {
+		metric.SetConditions(common.ReadyFalse("InvalidSchedule", errSchedule.Error()))
+		*d.ready = v1alpha1.StatusStringFalse
+		recorder.Eventf(metric, nil, "Warning", "InvalidSchedule", d.action, errSchedule.Error())
+		// the metric is reconciled again once the schedule is fixed
+		return ctrl.Result{}, nil
+	}
+
+	dimensions, errDimensions := resolveStaticDimensions(ctx, c, metric)
+	if errDimensions != nil {
+		metric.SetConditions(common.ReadyFalse("StaticDimensionsUnavailable", errDimensions.Error()))
+		*d.ready = v1alpha1.StatusStringFalse
+		recorder.Eventf(metric, nil, "Warning", "StaticDimensionsUnavailable", d.action, errDimensions.Error())
+		return ctrl.Result{RequeueAfter: RequeueAfterError}, nil
+	}
+
+	// Check if enough time has passed since the last reconciliation
+	collectNow := d.collectNow != nil && d.collectNow(schedule)
+	if !schedule.due(d.lastRun) && !dimensions.changedSince(*d.staticDimensionsHash, schedule) && !collectNow {
+		return requeueAt(d.nextRunTime, schedule.next(d.lastRun), metric.GetPriority()), nil
+	}
+
+	if delegated, ok := m.(delegatedMetric); ok {
+		if res, done, err := delegated.delegate(ctx, schedule); done {
+			return res, err
+		}
+	}
+
+	// the logs of the collection, including those of the metric client, carry its ID to correlate them
+	l = l.WithValues("collection", uuid.NewString())
+	ctx = log.IntoContext(ctx, l)
+
+	// failed collections are retried with the backoff of the retry policy of the metric
+	defer func() { res, errReconcile = failures.track(res, errReconcile, l) }()
+
+	/*
+		1. Get the DataSink credentials
+	*/
+	credentials, err := NewDataSinkCredentialsRetriever(c, recorder).GetDataSinkCredentials(ctx, d.dataSinkRef, metric, l)
+	if err != nil {
+		metric.SetConditions(common.ReadyFalse("DataSinkUnavailable", err.Error()))
+		*d.ready = v1alpha1.StatusStringFalse
+		return ctrl.Result{RequeueAfter: failures.retryDelay()}, err
+	}
+	if credentials == nil {
+		l.Info("DataSink not found; metrics will only be available via /metrics endpoint", "metric", d.name)
+	}
+	if err := syncFinalZeroFinalizer(ctx, c, metric, credentials); err != nil {
+		l.Error(err, "unable to update the final zero finalizer", "metric", d.name)
+	}
+
+	col := &collection{
+		metricDescription: d,
+		recorder:          recorder,
+		schedule:          schedule,
+		dimensions:        dimensions,
+		failures:          failures,
+		log:               l,
+	}
+	if credentials != nil {
+		col.credentials = *credentials
+	}
+
+	/*
+		2. Create the QueryConfigs of the clusters the metric is collected from
+	*/
+	col.queryConfigs, err = m.queryConfigs(ctx)
+	if err != nil {
+		return col.fail("QueryConfigCreationFailed", err)
+	}
+
+	/*
+		3. Create the OTel metric client and the gauge of the metric
+	*/
+	col.client, err = d.exporters.NewMetricClient(ctx, credentials)
+	if err != nil {
+		return col.fail("OTLPClientCreationFailed", err)
+	}
+	defer func() {
+		if err := col.client.Close(ctx); err != nil {
+			l.Error(err, fmt.Sprintf("Failed to close metric client during %s reconciliation", d.kind), "metric", d.name)
+		}
+	}()
+
+	col.client.SetMeter(d.meterName, d.scopeAttributes)
+	col.gauge, err = col.client.NewMetric(d.name, d.description, d.unit)
+	if err != nil {
+		return col.fail("MetricCreationFailed", err)
+	}
+	metricName, metricNamespace := d.name, metric.GetNamespace()
+	col.gauge.SetStaticDimensions(dimensions)
+	col.gauge.SetPrometheusFunc(func(dims map[string]string, value int64) {
+		internalmetrics.RecordDataPoint(metricName, metricNamespace, dims, value)
+	})
+
+	/*
+		4. Collect the metric with the strategy of its kind
+	*/
+	return m.collect(ctx, col)
+}
+
+// fail sets the metric not ready for the reason of the error and retries the collection with the backoff of its retry policy
+func (c *collection) fail(reason string, err error) (ctrl.Result, error) {
+	c.metric.SetConditions(common.ReadyFalse(reason, err.Error()))
+	*c.ready = v1alpha1.StatusStringFalse
+	c.log.Error(err, fmt.Sprintf("%s '%s' re-queued for execution in %v\n", c.kind, c.name, c.failures.retryDelay()))
+	return ctrl.Result{RequeueAfter: c.failures.retryDelay()}, err
+}
+
+// monitor monitors the clusters of the collection one after the other with the handler created by withHandler.
+// It returns the result of every cluster, the collection is failed with the error of the first failing cluster.
+func (c *collection) monitor(ctx context.Context, handlers orc.HandlerFactory, withHandler func(o *orc.Orchestrator) (*orc.Orchestrator, error)) ([]orc.MonitorResult, ctrl.Result, error) {
+	results := make([]orc.MonitorResult, 0, len(c.queryConfigs))
+	for _, queryConfig := range c.queryConfigs {
+		orchestrator, err := withHandler(orc.NewOrchestrator(c.credentials, queryConfig).WithHandlers(handlers))
+		if err != nil {
+			c.recorder.Eventf(c.metric, nil, "Warning", "OrchestratorCreation", c.action, "unable to create orchestrator")
+			res, err := c.fail("OrchestratorCreationFailed", err)
+			return nil, res, err
+		}
+
+		result, err := orchestrator.Handler.Monitor(ctx)
+		if err != nil {
+			res, err := c.fail("MonitoringFailed", err)
+			return nil, res, err
+		}
+		results = append(results, result)
+	}
+	return results, ctrl.Result{}, nil
+}
+
+// recordResult sets the Available condition of the metric from the result of a monitored cluster and records it in an event
+func (c *collection) recordResult(result orc.MonitorResult) {
+	switch result.Phase {
+	case v1alpha1.PhaseActive:
+		c.metric.SetConditions(common.Available(result.Message))
+		c.recorder.Eventf(c.metric, nil, "Normal", "MetricAvailable", c.action, result.Message)
+	case v1alpha1.PhaseFailed:
+		c.log.Error(result.Error, result.Message, "reason", result.Reason)
+		c.metric.SetConditions(common.Error(result.Message))
+		c.recorder.Eventf(c.metric, nil, "Warning", "MetricFailed", c.action, result.Message)
+	case v1alpha1.PhasePending:
+		c.metric.SetConditions(common.Creating())
+		c.recorder.Eventf(c.metric, nil, "Normal", "MetricPending", c.action, result.Message)
+	}
+	if len(result.Samples) > 0 {
+		c.recorder.Eventf(c.metric, nil, "Normal", "Samples", c.action, "%s", samplesNote(result.Samples))
+	}
+}
+
+// setExported sets the Ready condition of the metric from the result of its export
+func (c *collection) setExported(errExport error) {
+	if errExport != nil {
+		c.metric.SetConditions(common.ReadyFalse(exportFailedReason(errExport), errExport.Error()))
+		*c.ready = v1alpha1.StatusStringFalse
+		c.log.Error(errExport, fmt.Sprintf("%s '%s' failed to export, re-queued for execution in %v\n", c.kind, c.name, c.failures.retryDelay()))
+		return
+	}
+	c.metric.SetConditions(common.ReadyTrue(fmt.Sprintf("%s%s reconciled successfully", strings.ToUpper(c.kind[:1]), c.kind[1:])))
+	*c.ready = v1alpha1.StatusStringTrue
+}
+
+// requeue remembers the static dimensions of the collection and reconciles the metric again at the next run
+func (c *collection) requeue(nextRun time.Time) ctrl.Result {
+	*c.staticDimensionsHash = c.dimensions.hash()
+	c.log.V(1).Info(fmt.Sprintf("%s '%s' re-queued for execution at %v\n", c.kind, c.name, nextRun))
+	return requeueAt(c.nextRunTime, nextRun, c.metric.GetPriority())
+}
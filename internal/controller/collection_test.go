@@ -0,0 +1,165 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/go-logr/logr/funcr"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/events"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/openmcp-project/metrics-operator/api/v1alpha1"
+	"github.com/openmcp-project/metrics-operator/internal/clientoptl"
+	orc "github.com/openmcp-project/metrics-operator/internal/orchestrator"
+)
+
+func TestReconcileCollectedMetric(t *testing.T) {
+	active := orc.MonitorResult{
+		Phase:       v1alpha1.PhaseActive,
+		Message:     "managed metric is monitoring 3 resources",
+		Observation: &v1alpha1.ManagedObservation{Timestamp: metav1.Now(), Resources: "3"},
+	}
+
+	testCases := []struct {
+		name         string
+		observed     time.Time
+		result       orc.MonitorResult
+		monitorErr   error
+		exportErr    error
+		wantMonitors int
+		wantReady    string
+		wantReason   string
+	}{
+		{
+			name:         "Collected",
+			result:       active,
+			wantMonitors: 1,
+			wantReady:    v1alpha1.StatusStringTrue,
+			wantReason:   "ReconciliationSucceeded",
+		},
+		{
+			name:         "NotDue",
+			observed:     time.Now(),
+			result:       active,
+			wantMonitors: 0,
+		},
+		{
+			name:         "MonitoringFailed",
+			monitorErr:   errors.New("no such cluster"),
+			wantMonitors: 1,
+			wantReady:    v1alpha1.StatusStringFalse,
+			wantReason:   "MonitoringFailed",
+		},
+		{
+			name:         "ExportFailed",
+			result:       active,
+			exportErr:    errors.New("connection refused"),
+			wantMonitors: 1,
+			wantReady:    v1alpha1.StatusStringFalse,
+			wantReason:   "MetricExportFailed",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			withScheduling(t, SchedulingOptions{})
+			t.Setenv("OPERATOR_CONFIG_NAMESPACE", "default")
+			scheme := runtime.NewScheme()
+			require.NoError(t, v1alpha1.AddToScheme(scheme))
+
+			dataSink := &v1alpha1.DataSink{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "default"},
+				Spec:       v1alpha1.DataSinkSpec{Connection: v1alpha1.Connection{Endpoint: "https://sink.example.com/otlp/v1/metrics"}},
+			}
+			metric := &v1alpha1.ManagedMetric{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "buckets"},
+				Spec: v1alpha1.ManagedMetricSpec{
+					Name:        "buckets",
+					Interval:    metav1.Duration{Duration: 10 * time.Minute},
+					DataSinkRef: &v1alpha1.DataSinkReference{Name: "default"},
+				},
+			}
+			if !tc.observed.IsZero() {
+				metric.Status.Observation.Timestamp = metav1.NewTime(tc.observed)
+			}
+			cli := fake.NewClientBuilder().WithScheme(scheme).WithObjects(dataSink, metric).WithStatusSubresource(metric).Build()
+
+			handler := orc.NewFakeHandler(tc.result)
+			handler.Err = tc.monitorErr
+			exporter := clientoptl.NewFakeExporter()
+			exporter.Err = tc.exportErr
+			r := &ManagedMetricReconciler{
+				inClient:     cli,
+				inRestConfig: &rest.Config{Host: "https://cluster.example.com"},
+				Recorder:     events.NewFakeRecorder(10),
+				Exporters:    exporter.Factory(),
+				Handlers:     handler.Factory(),
+			}
+
+			res, err := reconcileCollectedMetric(context.Background(), cli, r.Recorder, &managedMetricCollection{r: r, metric: metric}, logr.Discard())
+			// failed collections are retried after the backoff of the retry policy instead of returning the error
+			require.NoError(t, err)
+			require.Positive(t, res.RequeueAfter)
+			require.Len(t, handler.Monitors(), tc.wantMonitors)
+
+			stored := &v1alpha1.ManagedMetric{}
+			require.NoError(t, cli.Get(context.Background(), client.ObjectKeyFromObject(metric), stored))
+			if tc.wantMonitors == 0 {
+				require.NotNil(t, stored.Status.NextRunTime)
+				require.Equal(t, 0, exporter.Exports())
+				return
+			}
+			require.Equal(t, tc.wantReady, stored.Status.Ready)
+			ready := meta.FindStatusCondition(stored.Status.Conditions, v1alpha1.TypeReady)
+			require.NotNil(t, ready)
+			require.Equal(t, tc.wantReason, ready.Reason)
+			if tc.wantReady == v1alpha1.StatusStringTrue {
+				require.Equal(t, "Managed metric reconciled successfully", ready.Message)
+				require.Equal(t, "3", stored.Status.Observation.Resources)
+				require.NotNil(t, stored.Status.NextRunTime)
+				require.Equal(t, 1, exporter.Exports())
+			} else {
+				require.Equal(t, int32(1), stored.Status.ConsecutiveFailures)
+			}
+		})
+	}
+}
+
+func TestReconcileCollectedMetric_collectionID(t *testing.T) {
+	withScheduling(t, SchedulingOptions{})
+	scheme := runtime.NewScheme()
+	require.NoError(t, v1alpha1.AddToScheme(scheme))
+	metric := &v1alpha1.ManagedMetric{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "buckets"},
+		Spec:       v1alpha1.ManagedMetricSpec{Name: "buckets", Interval: metav1.Duration{Duration: 10 * time.Minute}},
+	}
+	cli := fake.NewClientBuilder().WithScheme(scheme).WithObjects(metric).WithStatusSubresource(metric).Build()
+	r := &ManagedMetricReconciler{
+		inClient:     cli,
+		inRestConfig: &rest.Config{Host: "https://cluster.example.com"},
+		Recorder:     events.NewFakeRecorder(10),
+		Exporters:    clientoptl.NewFakeExporter().Factory(),
+		Handlers:     orc.NewFakeHandler(orc.MonitorResult{Phase: v1alpha1.PhaseActive, Observation: &v1alpha1.ManagedObservation{}}).Factory(),
+	}
+
+	var lines []string
+	l := funcr.New(func(prefix, args string) { lines = append(lines, args) }, funcr.Options{Verbosity: 1})
+	_, err := reconcileCollectedMetric(context.Background(), cli, r.Recorder, &managedMetricCollection{r: r, metric: metric}, l)
+	require.NoError(t, err)
+
+	// the logs of the collection carry its ID
+	requeued := slices.IndexFunc(lines, func(line string) bool { return strings.Contains(line, "re-queued for execution") })
+	require.NotEqual(t, -1, requeued, lines)
+	require.Contains(t, lines[requeued], `"collection"=`)
+}
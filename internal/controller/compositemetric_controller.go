@@ -0,0 +1,388 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/go-logr/logr"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/events"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/openmcp-project/metrics-operator/api/v1alpha1"
+	"github.com/openmcp-project/metrics-operator/internal/clientoptl"
+	"github.com/openmcp-project/metrics-operator/internal/common"
+	"github.com/openmcp-project/metrics-operator/internal/expression"
+	internalmetrics "github.com/openmcp-project/metrics-operator/internal/metrics"
+)
+
+// compositeMetricSourceIndex indexes CompositeMetrics by the names of the Metrics they are derived from
+const compositeMetricSourceIndex = ".spec.sources.metricRef"
+
+// NewCompositeMetricReconciler creates a new CompositeMetricReconciler
+func NewCompositeMetricReconciler(mgr ctrl.Manager) *CompositeMetricReconciler {
+	return &CompositeMetricReconciler{
+		log: mgr.GetLogger().WithName("controllers").WithName("CompositeMetric"),
+
+		inCli:    mgr.GetClient(),
+		Scheme:   mgr.GetScheme(),
+		Recorder: SharedEventThrottle.Recorder(mgr.GetEventRecorder("composite-metric-controller")),
+	}
+}
+
+// CompositeMetricReconciler reconciles a CompositeMetric object
+type CompositeMetricReconciler struct {
+	log logr.Logger
+
+	inCli    client.Client
+	Scheme   *runtime.Scheme
+	Recorder events.EventRecorder
+	// Exporters creates the exporters of the metric clients, the zero value exports with OTLP
+	Exporters clientoptl.ExporterFactory
+}
+
+func (r *CompositeMetricReconciler) getClient() client.Client {
+	return r.inCli
+}
+
+// getDataSinkCredentials fetches DataSink configuration and credentials
+func (r *CompositeMetricReconciler) getDataSinkCredentials(ctx context.Context, metric *v1alpha1.CompositeMetric, l logr.Logger) (*common.DataSinkCredentials, error) {
+	retriever := NewDataSinkCredentialsRetriever(r.getClient(), r.Recorder)
+	return retriever.GetDataSinkCredentials(ctx, metric.Spec.DataSinkRef, metric, l)
+}
+
+func (r *CompositeMetricReconciler) handleGetError(err error, log logr.Logger) (ctrl.Result, error) {
+	// we'll ignore not-found errors, since they can't be fixed by an immediate
+	// requeue (we'll need to wait for a new notification), and we can also get them
+	// on delete requests.
+	if apierrors.IsNotFound(err) {
+		log.Info("CompositeMetric not found")
+		return ctrl.Result{RequeueAfter: RequeueAfterError}, nil
+	}
+	log.Error(err, "unable to fetch CompositeMetric")
+	return ctrl.Result{RequeueAfter: RequeueAfterError}, err
+}
+
+// shouldReconcile returns true if the next export is due or one of the sources
+// has been observed after the last observation of the composite metric.
+// Composite metrics with a cron schedule are only exported at the scheduled times.
+func (r *CompositeMetricReconciler) shouldReconcile(metric *v1alpha1.CompositeMetric, sources []v1alpha1.Metric, schedule exportSchedule) bool {
+	lastObserved := r.lastExport(metric)
+	if schedule.cron == nil && !lastObserved.IsZero() {
+		for _, source := range sources {
+			if source.Status.Observation.Timestamp.After(lastObserved) {
+				return true
+			}
+		}
+	}
+	return schedule.due(lastObserved)
+}
+
+func (r *CompositeMetricReconciler) scheduleNextReconciliation(metric *v1alpha1.CompositeMetric, schedule exportSchedule) ctrl.Result {
+	return requeueAt(&metric.Status.NextRunTime, schedule.next(r.lastExport(metric)), metric.GetPriority())
+}
+
+// lastExport returns the time the composite metric was last exported, or the zero time if it has no value yet
+func (r *CompositeMetricReconciler) lastExport(metric *v1alpha1.CompositeMetric) time.Time {
+	if metric.Status.Observation.LatestValue == "" {
+		return time.Time{}
+	}
+	return metric.Status.Observation.Timestamp.Time
+}
+
+// getSources loads all Metrics referenced by the composite metric
+func (r *CompositeMetricReconciler) getSources(ctx context.Context, metric *v1alpha1.CompositeMetric) ([]v1alpha1.Metric, error) {
+	sources := make([]v1alpha1.Metric, 0, len(metric.Spec.Sources))
+	for _, source := range metric.Spec.Sources {
+		var m v1alpha1.Metric
+		if err := r.getClient().Get(ctx, types.NamespacedName{Namespace: metric.Namespace, Name: source.MetricRef}, &m); err != nil {
+			return nil, fmt.Errorf("failed to get source metric '%s': %w", source.MetricRef, err)
+		}
+		sources = append(sources, m)
+	}
+	return sources, nil
+}
+
+// evaluateComposite evaluates the expression of the composite metric over the latest observed values of its sources
+func evaluateComposite(metric *v1alpha1.CompositeMetric, sources []v1alpha1.Metric) (int64, error) {
+	expr, err := expression.Parse(metric.Spec.Expression)
+	if err != nil {
+		return 0, fmt.Errorf("invalid expression: %w", err)
+	}
+
+	values := make(map[string]float64, len(sources))
+	for i, source := range sources {
+		name := metric.Spec.Sources[i].Name
+		if source.Status.Observation.LatestValue == "" {
+			return 0, fmt.Errorf("source metric '%s' has not been observed yet", source.Name)
+		}
+		v, err := strconv.ParseFloat(source.Status.Observation.LatestValue, 64)
+		if err != nil {
+			return 0, fmt.Errorf("source metric '%s' has a non-numeric value '%s'", source.Name, source.Status.Observation.LatestValue)
+		}
+		values[name] = v
+	}
+
+	value, err := expr.Eval(values)
+	if err != nil {
+		return 0, fmt.Errorf("failed to evaluate expression '%s': %w", expr, err)
+	}
+	return int64(math.Round(value)), nil
+}
+
+// +kubebuilder:rbac:groups=metrics.openmcp.cloud,resources=compositemetrics,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=metrics.openmcp.cloud,resources=compositemetrics/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=metrics.openmcp.cloud,resources=compositemetrics/finalizers,verbs=update
+// +kubebuilder:rbac:groups=metrics.openmcp.cloud,resources=metrics,verbs=get;list;watch
+// +kubebuilder:rbac:groups=metrics.openmcp.cloud,resources=datasinks,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get
+
+// Reconcile handles the reconciliation of a CompositeMetric object
+func (r *CompositeMetricReconciler) Reconcile(ctx context.Context, req ctrl.Request) (res ctrl.Result, errReconcile error) {
+	l := r.log.WithValues("namespace", req.NamespacedName, "name", req.Name)
+
+	l.Info("Reconciling CompositeMetric")
+
+	metric := v1alpha1.CompositeMetric{}
+	if errLoad := r.getClient().Get(ctx, req.NamespacedName, &metric); errLoad != nil {
+		return r.handleGetError(errLoad, l)
+	}
+
+	if errRender := renderVariables(&metric, &metric.Spec); errRender != nil {
+		l.Error(errRender, "unable to replace the variables of the CompositeMetric")
+		return ctrl.Result{RequeueAfter: RequeueAfterError}, errRender
+	}
+
+	// deleted metrics only export a final zero for their series if their data sink asks for it
+	if !metric.DeletionTimestamp.IsZero() {
+		return r.finalize(ctx, &metric, l)
+	}
+
+	// Defer status update to ensure it's always called
+	patchStatus := statusPatch(r.getClient(), &metric)
+	defer func() {
+		if err := patchStatus(ctx); err != nil {
+			l.Error(err, "Failed to update CompositeMetric status")
+		}
+	}()
+
+	// Initialize Ready condition if not present
+	if meta.FindStatusCondition(metric.Status.Conditions, v1alpha1.TypeReady) == nil {
+		metric.SetConditions(common.ReadyUnknown("Reconciling", "Initial reconciliation"))
+	}
+
+	/*
+		1. Load the source metrics and check if the derived value needs to be recomputed
+	*/
+	failures := newFailureBudget(&metric, metric.Spec.RetryPolicy, &metric.Status.ConsecutiveFailures, &metric.Status.Ready, &metric.Status.Conditions)
+	if failures.exhausted() {
+		// the metric is reconciled again once its spec changes
+		return ctrl.Result{}, nil
+	}
+
+	schedule, errSchedule := newExportSchedule(metric.Spec.Interval, metric.Spec.Schedule, &metric)
+	if errSchedule != nil {
+		metric.SetConditions(common.ReadyFalse("InvalidSchedule", errSchedule.Error()))
+		metric.Status.Ready = v1alpha1.StatusStringFalse
+		r.Recorder.Eventf(&metric, nil, "Warning", "InvalidSchedule", "ReconcileCompositeMetric", errSchedule.Error())
+		// the metric is reconciled again once the schedule is fixed
+		return ctrl.Result{}, nil
+	}
+
+	sources, errSources := r.getSources(ctx, &metric)
+	if errSources != nil {
+		metric.SetConditions(common.ReadyFalse("SourceUnavailable", errSources.Error()))
+		metric.Status.Ready = v1alpha1.StatusStringFalse
+		r.Recorder.Eventf(&metric, nil, "Warning", "SourceUnavailable", "ReconcileCompositeMetric", errSources.Error())
+		return ctrl.Result{RequeueAfter: RequeueAfterError}, nil
+	}
+
+	dimensions, errDimensions := resolveStaticDimensions(ctx, r.getClient(), &metric)
+	if errDimensions != nil {
+		metric.SetConditions(common.ReadyFalse("StaticDimensionsUnavailable", errDimensions.Error()))
+		metric.Status.Ready = v1alpha1.StatusStringFalse
+		r.Recorder.Eventf(&metric, nil, "Warning", "StaticDimensionsUnavailable", "ReconcileCompositeMetric", errDimensions.Error())
+		return ctrl.Result{RequeueAfter: RequeueAfterError}, nil
+	}
+
+	if !r.shouldReconcile(&metric, sources, schedule) && !dimensions.changedSince(metric.Status.StaticDimensionsHash, schedule) {
+		return r.scheduleNextReconciliation(&metric, schedule), nil
+	}
+
+	// failed collections are retried with the backoff of the retry policy of the metric
+	defer func() { res, errReconcile = failures.track(res, errReconcile, l) }()
+
+	value, errEval := evaluateComposite(&metric, sources)
+	if errEval != nil {
+		metric.SetConditions(common.ReadyFalse("EvaluationFailed", errEval.Error()))
+		metric.Status.Ready = v1alpha1.StatusStringFalse
+		r.Recorder.Eventf(&metric, nil, "Warning", "EvaluationFailed", "ReconcileCompositeMetric", errEval.Error())
+		return ctrl.Result{RequeueAfter: failures.retryDelay()}, nil
+	}
+
+	/*
+		2. Record and export the derived value
+	*/
+	credentials, err := r.getDataSinkCredentials(ctx, &metric, l)
+	if err != nil {
+		metric.SetConditions(common.ReadyFalse("DataSinkUnavailable", err.Error()))
+		metric.Status.Ready = v1alpha1.StatusStringFalse
+		return ctrl.Result{RequeueAfter: failures.retryDelay()}, err
+	}
+	if credentials == nil {
+		l.Info("DataSink not found; metrics will only be available via /metrics endpoint", "metric", metric.Spec.Name)
+	}
+	if err := syncFinalZeroFinalizer(ctx, r.getClient(), &metric, credentials); err != nil {
+		l.Error(err, "unable to update the final zero finalizer", "metric", metric.Spec.Name)
+	}
+
+	metricClient, errCli := r.Exporters.NewMetricClient(ctx, credentials)
+	if errCli != nil {
+		metric.SetConditions(common.ReadyFalse("OTLPClientCreationFailed", errCli.Error()))
+		metric.Status.Ready = v1alpha1.StatusStringFalse
+		l.Error(errCli, fmt.Sprintf("composite metric '%s' failed to create OTel client, re-queued for execution in %v minutes\n", metric.Spec.Name, failures.retryDelay()))
+		return ctrl.Result{RequeueAfter: failures.retryDelay()}, errCli
+	}
+	defer func() {
+		if err := metricClient.Close(ctx); err != nil {
+			l.Error(err, "Failed to close metric client during composite metric reconciliation", "metric", metric.Spec.Name)
+		}
+	}()
+
+	metricClient.SetMeter(cmp.Or(metric.Spec.MeterName, "composite"), v1alpha1.ScopeAttributesMap(metric.Spec.ScopeAttributes))
+
+	gaugeMetric, errGauge := metricClient.NewMetric(metric.Spec.Name, metric.Spec.Description, metric.Spec.Unit)
+	if errGauge != nil {
+		metric.SetConditions(common.ReadyFalse("MetricCreationFailed", errGauge.Error()))
+		metric.Status.Ready = v1alpha1.StatusStringFalse
+		l.Error(errGauge, fmt.Sprintf("composite metric '%s' failed to create OTel gauge, re-queued for execution in %v minutes\n", metric.Spec.Name, failures.retryDelay()))
+		return ctrl.Result{RequeueAfter: failures.retryDelay()}, errGauge
+	}
+	metricName := metric.Spec.Name
+	metricNamespace := metric.Namespace
+	gaugeMetric.SetStaticDimensions(dimensions)
+	gaugeMetric.SetPrometheusFunc(func(dims map[string]string, value int64) {
+		internalmetrics.RecordDataPoint(metricName, metricNamespace, dims, value)
+	})
+
+	if errRecord := gaugeMetric.RecordMetrics(ctx, clientoptl.NewDataPoint().SetValue(value)); errRecord != nil {
+		metric.SetConditions(common.ReadyFalse("RecordMetricFailed", errRecord.Error()))
+		metric.Status.Ready = v1alpha1.StatusStringFalse
+		return ctrl.Result{RequeueAfter: failures.retryDelay()}, errRecord
+	}
+
+	errExport := exportMetrics(ctx, metricClient, &metric.Status.ExportStatus)
+	if errExport != nil {
+		metric.SetConditions(common.ReadyFalse(exportFailedReason(errExport), errExport.Error()))
+		metric.Status.Ready = v1alpha1.StatusStringFalse
+		l.Error(errExport, fmt.Sprintf("composite metric '%s' failed to export, re-queued for execution in %v minutes\n", metric.Spec.Name, failures.retryDelay()))
+	} else {
+		metric.SetConditions(common.Available(fmt.Sprintf("composite metric value recorded for expression '%s'", metric.Spec.Expression)))
+		metric.SetConditions(common.ReadyTrue("CompositeMetric reconciled successfully"))
+		metric.Status.Ready = v1alpha1.StatusStringTrue
+	}
+
+	metric.Status.StaticDimensionsHash = dimensions.hash()
+	metric.Status.Observation = v1alpha1.MetricObservation{
+		Timestamp:   metav1.Now(),
+		LatestValue: strconv.FormatInt(value, 10),
+	}
+
+	/*
+		3. Requeue the composite metric after the interval or after 2 minutes if an error occurred
+	*/
+	nextRun := schedule.next(metric.Status.Observation.Timestamp.Time)
+	if errExport != nil {
+		nextRun = failures.retryAt(metric.Status.NextRunTime)
+	}
+
+	l.Info(fmt.Sprintf("composite metric '%s' re-queued for execution at %v\n", metric.Spec.Name, nextRun))
+
+	return requeueAt(&metric.Status.NextRunTime, nextRun, metric.GetPriority()), nil
+}
+
+// finalize exports a final zero for the series of the deleted composite metric before its finalizer is removed
+func (r *CompositeMetricReconciler) finalize(ctx context.Context, metric *v1alpha1.CompositeMetric, l logr.Logger) (ctrl.Result, error) {
+	return finalizeDeletedMetric(ctx, r.getClient(), r.Recorder, deletedMetric{
+		metric:          metric,
+		exporters:       r.Exporters,
+		name:            metric.Spec.Name,
+		description:     metric.Spec.Description,
+		unit:            metric.Spec.Unit,
+		meterName:       cmp.Or(metric.Spec.MeterName, "composite"),
+		scopeAttributes: v1alpha1.ScopeAttributesMap(metric.Spec.ScopeAttributes),
+		dataSinkRef:     metric.Spec.DataSinkRef,
+		collect: func(ctx context.Context, credentials common.DataSinkCredentials, gauge *clientoptl.Metric) error {
+			// the value is recorded as 0, the composite metric has a single series per static dimensions
+			return gauge.RecordMetrics(ctx, clientoptl.NewDataPoint())
+		},
+	}, l)
+}
+
+// SetupWithManager sets up the controller with the Manager.
+// CompositeMetrics are reconciled whenever one of their source Metrics changes.
+func (r *CompositeMetricReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &v1alpha1.CompositeMetric{}, compositeMetricSourceIndex, func(obj client.Object) []string {
+		metric := obj.(*v1alpha1.CompositeMetric)
+		refs := make([]string, 0, len(metric.Spec.Sources))
+		for _, source := range metric.Spec.Sources {
+			refs = append(refs, source.MetricRef)
+		}
+		return refs
+	}); err != nil {
+		return err
+	}
+
+	b := ctrl.NewControllerManagedBy(mgr).
+		Named(CompositeMetricControllerName).
+		WithOptions(Controllers.forController(CompositeMetricControllerName)).
+		// metrics are enqueued with their priority, so important metrics are reconciled first
+		Watches(&v1alpha1.CompositeMetric{}, enqueueSelf()).
+		Watches(&v1alpha1.Metric{}, enqueueByPriority{metricsFor: r.compositeMetricsForSource})
+
+	b, err := watchStaticDimensionSources(mgr, b, &v1alpha1.CompositeMetric{}, func() client.ObjectList { return &v1alpha1.CompositeMetricList{} })
+	if err != nil {
+		return err
+	}
+	return b.Complete(r)
+}
+
+// compositeMetricsForSource maps a Metric to the CompositeMetrics derived from it
+func (r *CompositeMetricReconciler) compositeMetricsForSource(ctx context.Context, obj client.Object) []prioritizedMetric {
+	var composites v1alpha1.CompositeMetricList
+	if err := r.getClient().List(ctx, &composites, client.InNamespace(obj.GetNamespace()), client.MatchingFields{compositeMetricSourceIndex: obj.GetName()}); err != nil {
+		r.log.Error(err, "unable to list composite metrics for source", "metric", obj.GetName())
+		return nil
+	}
+
+	metrics := make([]prioritizedMetric, 0, len(composites.Items))
+	for i := range composites.Items {
+		metrics = append(metrics, &composites.Items[i])
+	}
+	return metrics
+}
@@ -0,0 +1,124 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/openmcp-project/metrics-operator/api/v1alpha1"
+)
+
+func sourceMetric(name, value string, observed time.Time) v1alpha1.Metric {
+	return v1alpha1.Metric{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Status: v1alpha1.MetricStatus{
+			Observation: v1alpha1.MetricObservation{Timestamp: metav1.NewTime(observed), LatestValue: value},
+		},
+	}
+}
+
+func TestEvaluateComposite(t *testing.T) {
+	now := time.Now()
+	composite := &v1alpha1.CompositeMetric{Spec: v1alpha1.CompositeMetricSpec{
+		Sources: []v1alpha1.CompositeMetricSource{
+			{Name: "ready", MetricRef: "ready-pods"},
+			{Name: "total", MetricRef: "all-pods"},
+		},
+		Expression: "100 * ready / total",
+	}}
+
+	testCases := []struct {
+		name          string
+		sources       []v1alpha1.Metric
+		expression    string
+		expectedValue int64
+		expectedError bool
+	}{
+		{
+			name:          "Ratio",
+			sources:       []v1alpha1.Metric{sourceMetric("ready-pods", "2", now), sourceMetric("all-pods", "3", now)},
+			expectedValue: 67,
+		},
+		{
+			name:          "Difference",
+			sources:       []v1alpha1.Metric{sourceMetric("ready-pods", "2", now), sourceMetric("all-pods", "3", now)},
+			expression:    "total - ready",
+			expectedValue: 1,
+		},
+		{
+			name:          "NotObserved",
+			sources:       []v1alpha1.Metric{sourceMetric("ready-pods", "", now), sourceMetric("all-pods", "3", now)},
+			expectedError: true,
+		},
+		{
+			name:          "DivisionByZero",
+			sources:       []v1alpha1.Metric{sourceMetric("ready-pods", "2", now), sourceMetric("all-pods", "0", now)},
+			expectedError: true,
+		},
+		{
+			name:          "InvalidExpression",
+			sources:       []v1alpha1.Metric{sourceMetric("ready-pods", "2", now), sourceMetric("all-pods", "3", now)},
+			expression:    "ready +",
+			expectedError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			metric := composite.DeepCopy()
+			if tc.expression != "" {
+				metric.Spec.Expression = tc.expression
+			}
+
+			value, err := evaluateComposite(metric, tc.sources)
+			if tc.expectedError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expectedValue, value)
+		})
+	}
+}
+
+func TestCompositeMetricShouldReconcile(t *testing.T) {
+	r := &CompositeMetricReconciler{}
+	lastObserved := time.Now().Add(-time.Minute)
+	metric := &v1alpha1.CompositeMetric{
+		Spec: v1alpha1.CompositeMetricSpec{Interval: metav1.Duration{Duration: 10 * time.Minute}},
+		Status: v1alpha1.CompositeMetricStatus{
+			Observation: v1alpha1.MetricObservation{Timestamp: metav1.NewTime(lastObserved), LatestValue: "1"},
+		},
+	}
+
+	withScheduling(t, SchedulingOptions{})
+	schedule, err := newExportSchedule(metric.Spec.Interval, "", metric)
+	require.NoError(t, err)
+
+	require.False(t, r.shouldReconcile(metric, []v1alpha1.Metric{sourceMetric("a", "1", lastObserved.Add(-time.Second))}, schedule))
+	require.True(t, r.shouldReconcile(metric, []v1alpha1.Metric{sourceMetric("a", "1", lastObserved.Add(time.Second))}, schedule))
+	require.True(t, r.shouldReconcile(&v1alpha1.CompositeMetric{}, nil, schedule))
+
+	// with a cron schedule, observations of the sources do not trigger an export
+	cronSchedule, err := newExportSchedule(metric.Spec.Interval, "0 0 1 1 *", metric)
+	require.NoError(t, err)
+	require.False(t, r.shouldReconcile(metric, []v1alpha1.Metric{sourceMetric("a", "1", lastObserved.Add(time.Second))}, cronSchedule))
+}
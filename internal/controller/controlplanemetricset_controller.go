@@ -0,0 +1,332 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/go-logr/logr"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/tools/events"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/openmcp-project/metrics-operator/api/v1alpha1"
+	"github.com/openmcp-project/metrics-operator/internal/common"
+)
+
+// NewControlPlaneMetricSetReconciler creates a new ControlPlaneMetricSetReconciler for the control planes of the kind
+func NewControlPlaneMetricSetReconciler(mgr ctrl.Manager, controlPlaneKind schema.GroupVersionKind) *ControlPlaneMetricSetReconciler {
+	return &ControlPlaneMetricSetReconciler{
+		log: mgr.GetLogger().WithName("controllers").WithName("ControlPlaneMetricSet"),
+
+		inCli:            mgr.GetClient(),
+		Scheme:           mgr.GetScheme(),
+		Recorder:         SharedEventThrottle.Recorder(mgr.GetEventRecorder("controlplanemetricset-controller")),
+		ControlPlaneKind: controlPlaneKind,
+	}
+}
+
+// ControlPlaneMetricSetReconciler reconciles a ControlPlaneMetricSet object
+type ControlPlaneMetricSetReconciler struct {
+	log logr.Logger
+
+	inCli    client.Client
+	Scheme   *runtime.Scheme
+	Recorder events.EventRecorder
+
+	// ControlPlaneKind is the kind of the control planes, e.g. ManagedControlPlane.v1alpha1.core.openmcp.cloud
+	ControlPlaneKind schema.GroupVersionKind
+}
+
+func (r *ControlPlaneMetricSetReconciler) getClient() client.Client {
+	return r.inCli
+}
+
+// controlPlaneID identifies a control plane in the names of the generated objects,
+// namespaced control planes are prefixed with their namespace, which contains no dots
+func controlPlaneID(controlPlane *unstructured.Unstructured) string {
+	if controlPlane.GetNamespace() == "" {
+		return controlPlane.GetName()
+	}
+	return controlPlane.GetNamespace() + "." + controlPlane.GetName()
+}
+
+// renderControlPlaneTemplate replaces the placeholders in the string fields of the template spec with the name and
+// namespace of the control plane and decodes the result into out
+func renderControlPlaneTemplate(spec any, controlPlane *unstructured.Unstructured, out any) error {
+	data, err := json.Marshal(spec)
+	if err != nil {
+		return err
+	}
+	// names and namespaces need no escaping within JSON strings
+	data = bytes.ReplaceAll(data, []byte(v1alpha1.ControlPlaneNamePlaceholder), []byte(controlPlane.GetName()))
+	data = bytes.ReplaceAll(data, []byte(v1alpha1.ControlPlaneNamespacePlaceholder), []byte(controlPlane.GetNamespace()))
+	return json.Unmarshal(data, out)
+}
+
+// withControlPlaneDimension returns the static dimensions with the dimension of the control plane,
+// replacing a static dimension of the same name
+func withControlPlaneDimension(dimensions []v1alpha1.StaticDimension, name, controlPlane string) []v1alpha1.StaticDimension {
+	dimensions = slices.DeleteFunc(dimensions, func(d v1alpha1.StaticDimension) bool { return d.Name == name })
+	return append(dimensions, v1alpha1.StaticDimension{Name: name, Value: controlPlane})
+}
+
+// controlPlaneLabels returns the labels of an object generated from a template of the set
+func controlPlaneLabels(set *v1alpha1.ControlPlaneMetricSet, templateLabels map[string]string) map[string]string {
+	labels := maps.Clone(templateLabels)
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	labels[v1alpha1.ControlPlaneMetricSetLabel] = set.Name
+	return labels
+}
+
+// expandControlPlaneMetricSet returns the Metrics and FederatedMetrics generated from the templates of the set,
+// one per template and control plane
+func expandControlPlaneMetricSet(set *v1alpha1.ControlPlaneMetricSet, controlPlanes []unstructured.Unstructured) ([]v1alpha1.Metric, []v1alpha1.FederatedMetric, error) {
+	dimension := cmp.Or(set.Spec.Dimension, "mcp")
+	var metrics []v1alpha1.Metric
+	var federatedMetrics []v1alpha1.FederatedMetric
+	for i := range controlPlanes {
+		controlPlane := &controlPlanes[i]
+		id := controlPlaneID(controlPlane)
+		for _, template := range set.Spec.Metrics {
+			spec := v1alpha1.MetricSpec{}
+			if err := renderControlPlaneTemplate(template.Spec, controlPlane, &spec); err != nil {
+				return nil, nil, fmt.Errorf("metric template '%s': %w", template.Name, err)
+			}
+			spec.Name = cmp.Or(spec.Name, template.Name)
+			spec.StaticDimensions = withControlPlaneDimension(spec.StaticDimensions, dimension, controlPlane.GetName())
+			metrics = append(metrics, v1alpha1.Metric{
+				ObjectMeta: metav1.ObjectMeta{Namespace: set.Namespace, Name: set.Name + "-" + template.Name + "-" + id, Labels: controlPlaneLabels(set, template.Labels)},
+				Spec:       spec,
+			})
+		}
+		for _, template := range set.Spec.FederatedMetrics {
+			spec := v1alpha1.FederatedMetricSpec{}
+			if err := renderControlPlaneTemplate(template.Spec, controlPlane, &spec); err != nil {
+				return nil, nil, fmt.Errorf("federated metric template '%s': %w", template.Name, err)
+			}
+			spec.Name = cmp.Or(spec.Name, template.Name)
+			spec.StaticDimensions = withControlPlaneDimension(spec.StaticDimensions, dimension, controlPlane.GetName())
+			federatedMetrics = append(federatedMetrics, v1alpha1.FederatedMetric{
+				ObjectMeta: metav1.ObjectMeta{Namespace: set.Namespace, Name: set.Name + "-" + template.Name + "-" + id, Labels: controlPlaneLabels(set, template.Labels)},
+				Spec:       spec,
+			})
+		}
+	}
+	return metrics, federatedMetrics, nil
+}
+
+// listControlPlanes returns the control planes selected by the set
+func (r *ControlPlaneMetricSetReconciler) listControlPlanes(ctx context.Context, set *v1alpha1.ControlPlaneMetricSet) ([]unstructured.Unstructured, error) {
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(r.ControlPlaneKind.GroupVersion().WithKind(r.ControlPlaneKind.Kind + "List"))
+	var opts []client.ListOption
+	if set.Spec.Selector != nil {
+		selector, err := metav1.LabelSelectorAsSelector(set.Spec.Selector)
+		if err != nil {
+			return nil, fmt.Errorf("invalid selector: %w", err)
+		}
+		opts = append(opts, client.MatchingLabelsSelector{Selector: selector})
+	}
+	if err := r.getClient().List(ctx, list, opts...); err != nil {
+		return nil, err
+	}
+	return list.Items, nil
+}
+
+// +kubebuilder:rbac:groups=metrics.openmcp.cloud,resources=controlplanemetricsets,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=metrics.openmcp.cloud,resources=controlplanemetricsets/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=metrics.openmcp.cloud,resources=controlplanemetricsets/finalizers,verbs=update
+// +kubebuilder:rbac:groups=metrics.openmcp.cloud,resources=metrics,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=metrics.openmcp.cloud,resources=federatedmetrics,verbs=get;list;watch;create;update;patch;delete
+
+// Reconcile generates the Metrics and FederatedMetrics of a ControlPlaneMetricSet for each selected control plane,
+// and deletes those of control planes that were deleted or are no longer selected
+func (r *ControlPlaneMetricSetReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	l := r.log.WithValues("namespace", req.NamespacedName, "name", req.Name)
+
+	l.Info("Reconciling ControlPlaneMetricSet")
+
+	set := v1alpha1.ControlPlaneMetricSet{}
+	if errLoad := r.getClient().Get(ctx, req.NamespacedName, &set); errLoad != nil {
+		if apierrors.IsNotFound(errLoad) {
+			// the generated objects are deleted by the garbage collector
+			l.Info("ControlPlaneMetricSet not found")
+			return ctrl.Result{}, nil
+		}
+		l.Error(errLoad, "unable to fetch ControlPlaneMetricSet")
+		return ctrl.Result{RequeueAfter: RequeueAfterError}, errLoad
+	}
+
+	// Defer status update to ensure it's always called
+	patchStatus := statusPatch(r.getClient(), &set)
+	defer func() {
+		if err := patchStatus(ctx); err != nil {
+			l.Error(err, "Failed to update ControlPlaneMetricSet status")
+		}
+	}()
+
+	/*
+		1. Expand the templates over the selected control planes
+	*/
+	controlPlanes, err := r.listControlPlanes(ctx, &set)
+	if err != nil {
+		set.SetConditions(common.ReadyFalse("ControlPlanesUnavailable", fmt.Sprintf("failed to list the %s control planes: %s", r.ControlPlaneKind.Kind, err.Error())))
+		set.Status.Ready = v1alpha1.StatusStringFalse
+		r.Recorder.Eventf(&set, nil, "Warning", "ControlPlanesUnavailable", "ReconcileControlPlaneMetricSet", err.Error())
+		return ctrl.Result{RequeueAfter: RequeueAfterError}, err
+	}
+	metrics, federatedMetrics, err := expandControlPlaneMetricSet(&set, controlPlanes)
+	if err != nil {
+		set.SetConditions(common.ReadyFalse("InvalidTemplate", err.Error()))
+		set.Status.Ready = v1alpha1.StatusStringFalse
+		r.Recorder.Eventf(&set, nil, "Warning", "InvalidTemplate", "ReconcileControlPlaneMetricSet", err.Error())
+		return ctrl.Result{}, nil
+	}
+
+	/*
+		2. Create or update the generated objects
+	*/
+	names := make(map[string]bool, len(metrics))
+	var errs []error
+	for _, d := range metrics {
+		names[d.Name] = true
+		metric := v1alpha1.Metric{ObjectMeta: metav1.ObjectMeta{Namespace: d.Namespace, Name: d.Name}}
+		_, err := createOrUpdateGenerated(ctx, r.getClient(), &metric, func() error {
+			metric.Labels = d.Labels
+			metric.Spec = d.Spec
+			return controllerutil.SetControllerReference(&set, &metric, r.Scheme)
+		})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("metric '%s': %w", d.Name, err))
+		}
+	}
+	federatedNames := make(map[string]bool, len(federatedMetrics))
+	for _, d := range federatedMetrics {
+		federatedNames[d.Name] = true
+		metric := v1alpha1.FederatedMetric{ObjectMeta: metav1.ObjectMeta{Namespace: d.Namespace, Name: d.Name}}
+		_, err := createOrUpdateGenerated(ctx, r.getClient(), &metric, func() error {
+			metric.Labels = d.Labels
+			metric.Spec = d.Spec
+			return controllerutil.SetControllerReference(&set, &metric, r.Scheme)
+		})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("federated metric '%s': %w", d.Name, err))
+		}
+	}
+
+	/*
+		3. Delete the objects of removed control planes and templates
+	*/
+	generatedBy := []client.ListOption{client.InNamespace(set.Namespace), client.MatchingLabels{v1alpha1.ControlPlaneMetricSetLabel: set.Name}}
+	existing := v1alpha1.MetricList{}
+	if err := r.getClient().List(ctx, &existing, generatedBy...); err != nil {
+		errs = append(errs, fmt.Errorf("failed to list the generated metrics: %w", err))
+	}
+	for i := range existing.Items {
+		metric := &existing.Items[i]
+		if names[metric.Name] || !metav1.IsControlledBy(metric, &set) {
+			continue
+		}
+		if err := r.getClient().Delete(ctx, metric); client.IgnoreNotFound(err) != nil {
+			errs = append(errs, fmt.Errorf("metric '%s': %w", metric.Name, err))
+			continue
+		}
+		l.Info("deleted metric of removed control plane or template", "metric", metric.Name)
+	}
+	existingFederated := v1alpha1.FederatedMetricList{}
+	if err := r.getClient().List(ctx, &existingFederated, generatedBy...); err != nil {
+		errs = append(errs, fmt.Errorf("failed to list the generated federated metrics: %w", err))
+	}
+	for i := range existingFederated.Items {
+		metric := &existingFederated.Items[i]
+		if federatedNames[metric.Name] || !metav1.IsControlledBy(metric, &set) {
+			continue
+		}
+		if err := r.getClient().Delete(ctx, metric); client.IgnoreNotFound(err) != nil {
+			errs = append(errs, fmt.Errorf("federated metric '%s': %w", metric.Name, err))
+			continue
+		}
+		l.Info("deleted federated metric of removed control plane or template", "federatedMetric", metric.Name)
+	}
+
+	/*
+		4. Report the generated objects
+	*/
+	set.Status.ControlPlanes = len(controlPlanes)
+	set.Status.Generated = len(metrics) + len(federatedMetrics)
+	if err := errors.Join(errs...); err != nil {
+		set.SetConditions(common.ReadyFalse("MetricGenerationFailed", err.Error()))
+		set.Status.Ready = v1alpha1.StatusStringFalse
+		r.Recorder.Eventf(&set, nil, "Warning", "MetricGenerationFailed", "ReconcileControlPlaneMetricSet", err.Error())
+		return ctrl.Result{RequeueAfter: RequeueAfterError}, err
+	}
+	set.SetConditions(common.ReadyTrue(fmt.Sprintf("generated %d objects for %d control planes", set.Status.Generated, len(controlPlanes))))
+	set.Status.Ready = v1alpha1.StatusStringTrue
+	return ctrl.Result{}, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *ControlPlaneMetricSetReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	controlPlane := &unstructured.Unstructured{}
+	controlPlane.SetGroupVersionKind(r.ControlPlaneKind)
+
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(ControlPlaneMetricSetControllerName).
+		WithOptions(Controllers.forController(ControlPlaneMetricSetControllerName)).
+		// status updates of the set itself need no reconcile
+		For(&v1alpha1.ControlPlaneMetricSet{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		// generated objects that were changed or deleted are restored
+		Owns(&v1alpha1.Metric{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Owns(&v1alpha1.FederatedMetric{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		// every set is expanded again when a control plane is created, deleted or relabeled
+		Watches(controlPlane, handler.EnqueueRequestsFromMapFunc(r.allSets), builder.WithPredicates(predicate.LabelChangedPredicate{})).
+		Complete(r)
+}
+
+// allSets returns the requests of all ControlPlaneMetricSets
+func (r *ControlPlaneMetricSetReconciler) allSets(ctx context.Context, _ client.Object) []reconcile.Request {
+	sets := &v1alpha1.ControlPlaneMetricSetList{}
+	if err := r.getClient().List(ctx, sets); err != nil {
+		r.log.Error(err, "unable to list ControlPlaneMetricSets")
+		return nil
+	}
+	requests := make([]reconcile.Request, 0, len(sets.Items))
+	for i := range sets.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&sets.Items[i])})
+	}
+	return requests
+}
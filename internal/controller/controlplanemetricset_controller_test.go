@@ -0,0 +1,146 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/events"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/openmcp-project/metrics-operator/api/v1alpha1"
+)
+
+var managedControlPlaneKind = schema.GroupVersionKind{Group: "core.openmcp.cloud", Version: "v1alpha1", Kind: "ManagedControlPlane"}
+
+func managedControlPlane(namespace, name string, labels map[string]string) *unstructured.Unstructured {
+	mcp := &unstructured.Unstructured{}
+	mcp.SetGroupVersionKind(managedControlPlaneKind)
+	mcp.SetNamespace(namespace)
+	mcp.SetName(name)
+	mcp.SetLabels(labels)
+	return mcp
+}
+
+func controlPlaneMetricSet() *v1alpha1.ControlPlaneMetricSet {
+	return &v1alpha1.ControlPlaneMetricSet{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "platform", Name: "standard", UID: "set-uid"},
+		Spec: v1alpha1.ControlPlaneMetricSetSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"tier": "prod"}},
+			Metrics: []v1alpha1.ControlPlaneMetricTemplate{{
+				Name:   "secrets",
+				Labels: map[string]string{"team": "platform"},
+				Spec: v1alpha1.MetricSpec{
+					Target: v1alpha1.MetricTarget{
+						GroupVersionKind: v1alpha1.GroupVersionKind{Kind: "Secret", Version: "v1"},
+						Namespaces:       []string{"$(CONTROL_PLANE_NAMESPACE)"},
+					},
+					LabelSelector:    "mcp=$(CONTROL_PLANE_NAME)",
+					StaticDimensions: []v1alpha1.StaticDimension{{Name: "mcp", Value: "overridden"}, {Name: "tenant", Value: "a"}},
+				},
+			}},
+			FederatedMetrics: []v1alpha1.ControlPlaneFederatedMetricTemplate{{
+				Name: "pods",
+				Spec: v1alpha1.FederatedMetricSpec{
+					Name:                      "mcp_pods",
+					Target:                    v1alpha1.GroupVersionKind{Kind: "Pod", Version: "v1"},
+					FederatedClusterAccessRef: v1alpha1.FederateClusterAccessRef{Name: "$(CONTROL_PLANE_NAME)-access", Namespace: "platform"},
+				},
+			}},
+		},
+	}
+}
+
+func TestExpandControlPlaneMetricSet(t *testing.T) {
+	controlPlanes := []unstructured.Unstructured{*managedControlPlane("project-a", "alpha", nil)}
+	metrics, federatedMetrics, err := expandControlPlaneMetricSet(controlPlaneMetricSet(), controlPlanes)
+	require.NoError(t, err)
+	require.Len(t, metrics, 1)
+	require.Len(t, federatedMetrics, 1)
+
+	metric := metrics[0]
+	require.Equal(t, "standard-secrets-project-a.alpha", metric.Name)
+	require.Equal(t, "platform", metric.Namespace)
+	require.Equal(t, map[string]string{"team": "platform", v1alpha1.ControlPlaneMetricSetLabel: "standard"}, metric.Labels)
+	require.Equal(t, "secrets", metric.Spec.Name, "the name of the template is the default metric name")
+	require.Equal(t, []string{"project-a"}, metric.Spec.Target.Namespaces)
+	require.Equal(t, "mcp=alpha", metric.Spec.LabelSelector)
+	require.Equal(t, []v1alpha1.StaticDimension{{Name: "tenant", Value: "a"}, {Name: "mcp", Value: "alpha"}}, metric.Spec.StaticDimensions)
+
+	federated := federatedMetrics[0]
+	require.Equal(t, "standard-pods-project-a.alpha", federated.Name)
+	require.Equal(t, "mcp_pods", federated.Spec.Name)
+	require.Equal(t, "alpha-access", federated.Spec.FederatedClusterAccessRef.Name)
+	require.Equal(t, []v1alpha1.StaticDimension{{Name: "mcp", Value: "alpha"}}, federated.Spec.StaticDimensions)
+}
+
+func TestControlPlaneMetricSetReconciler_Reconcile(t *testing.T) {
+	set := controlPlaneMetricSet()
+	controlled := []metav1.OwnerReference{{
+		APIVersion: v1alpha1.GroupVersion.String(), Kind: "ControlPlaneMetricSet", Name: "standard", UID: "set-uid", Controller: ptr.To(true),
+	}}
+	// the Metric of a deleted control plane
+	deleted := &v1alpha1.Metric{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "platform", Name: "standard-secrets-project-a.deleted", Labels: map[string]string{v1alpha1.ControlPlaneMetricSetLabel: "standard"}, OwnerReferences: controlled},
+	}
+
+	scheme := runtime.NewScheme()
+	require.NoError(t, v1alpha1.AddToScheme(scheme))
+	scheme.AddKnownTypeWithName(managedControlPlaneKind, &unstructured.Unstructured{})
+	scheme.AddKnownTypeWithName(managedControlPlaneKind.GroupVersion().WithKind("ManagedControlPlaneList"), &unstructured.UnstructuredList{})
+	cli := fake.NewClientBuilder().WithScheme(scheme).
+		WithObjects(set, deleted,
+			managedControlPlane("project-a", "alpha", map[string]string{"tier": "prod"}),
+			managedControlPlane("project-b", "beta", map[string]string{"tier": "prod"}),
+			managedControlPlane("project-b", "dev", map[string]string{"tier": "dev"})).
+		WithStatusSubresource(set).
+		Build()
+	r := &ControlPlaneMetricSetReconciler{log: logr.Discard(), inCli: cli, Scheme: scheme, Recorder: events.NewFakeRecorder(10), ControlPlaneKind: managedControlPlaneKind}
+
+	_, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "platform", Name: "standard"}})
+	require.NoError(t, err)
+
+	metrics := v1alpha1.MetricList{}
+	require.NoError(t, cli.List(context.Background(), &metrics, client.InNamespace("platform")))
+	var names []string
+	for _, metric := range metrics.Items {
+		names = append(names, metric.Name)
+		require.True(t, metav1.IsControlledBy(&metric, set), "metric %s is owned by the set", metric.Name)
+	}
+	require.ElementsMatch(t, []string{"standard-secrets-project-a.alpha", "standard-secrets-project-b.beta"}, names)
+
+	federatedMetrics := v1alpha1.FederatedMetricList{}
+	require.NoError(t, cli.List(context.Background(), &federatedMetrics, client.InNamespace("platform")))
+	require.Len(t, federatedMetrics.Items, 2)
+
+	updated := v1alpha1.ControlPlaneMetricSet{}
+	require.NoError(t, cli.Get(context.Background(), types.NamespacedName{Namespace: "platform", Name: "standard"}, &updated))
+	require.Equal(t, 2, updated.Status.ControlPlanes)
+	require.Equal(t, 4, updated.Status.Generated)
+	require.Equal(t, v1alpha1.StatusStringTrue, updated.Status.Ready)
+}
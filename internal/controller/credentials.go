@@ -0,0 +1,82 @@
+package controller
+
+import (
+	"fmt"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/openmcp-project/metrics-operator/api/v1alpha1"
+	internalmetrics "github.com/openmcp-project/metrics-operator/internal/metrics"
+	orc "github.com/openmcp-project/metrics-operator/internal/orchestrator"
+)
+
+const (
+	// CredentialsExpiryWarning is how long before they expire the credentials of a remote cluster are reported as expiring
+	CredentialsExpiryWarning = 14 * 24 * time.Hour
+
+	// ReasonCredentialsExpired is used when the credentials of a remote cluster expired
+	ReasonCredentialsExpired = "CredentialsExpired"
+	// ReasonCredentialsInvalid is used when no client can be created from the credentials of a remote cluster
+	ReasonCredentialsInvalid = "CredentialsInvalid"
+)
+
+// credentialsCheck is the result of checking the expiry of the credentials of the clusters of a cluster access
+type credentialsCheck struct {
+	// expiry is the earliest expiry of the credentials, nil if none of them expire
+	expiry *metav1.Time
+	// expired is true if the earliest expiring credentials already expired
+	expired   bool
+	condition metav1.Condition
+}
+
+// checkCredentials records the days until the credentials of the clusters expire
+// and returns the CredentialsExpiring condition for the earliest expiry
+func checkCredentials(kind string, access metav1.Object, configs []orc.QueryConfig, now time.Time) credentialsCheck {
+	internalmetrics.DeleteCredentialsExpiry(kind, access.GetNamespace(), access.GetName())
+
+	var earliest time.Time
+	var earliestCluster string
+	for _, qc := range configs {
+		if qc.CredentialsExpiry.IsZero() {
+			continue
+		}
+		cluster := ""
+		if qc.ClusterName != nil {
+			cluster = *qc.ClusterName
+		}
+		days := qc.CredentialsExpiry.Sub(now).Hours() / 24
+		internalmetrics.CredentialsExpiryDays.WithLabelValues(kind, access.GetNamespace(), access.GetName(), cluster).Set(days)
+		if earliest.IsZero() || qc.CredentialsExpiry.Before(earliest) {
+			earliest, earliestCluster = qc.CredentialsExpiry, cluster
+		}
+	}
+
+	if earliest.IsZero() {
+		return credentialsCheck{condition: credentialsCondition(metav1.ConditionFalse, "CredentialsDoNotExpire", "the credentials do not expire")}
+	}
+	expiry := metav1.NewTime(earliest)
+	at := earliest.UTC().Format(time.RFC3339)
+	switch {
+	case !earliest.After(now):
+		return credentialsCheck{expiry: &expiry, expired: true, condition: credentialsCondition(metav1.ConditionTrue, ReasonCredentialsExpired,
+			fmt.Sprintf("the credentials of cluster '%s' expired at %s", earliestCluster, at))}
+	case earliest.Sub(now) < CredentialsExpiryWarning:
+		return credentialsCheck{expiry: &expiry, condition: credentialsCondition(metav1.ConditionTrue, "CredentialsExpiringSoon",
+			fmt.Sprintf("the credentials of cluster '%s' expire at %s", earliestCluster, at))}
+	default:
+		return credentialsCheck{expiry: &expiry, condition: credentialsCondition(metav1.ConditionFalse, "CredentialsValid",
+			fmt.Sprintf("the credentials expire at %s", at))}
+	}
+}
+
+// credentialsCondition returns a CredentialsExpiring condition
+func credentialsCondition(status metav1.ConditionStatus, reason, message string) metav1.Condition {
+	return metav1.Condition{
+		Type:               v1alpha1.TypeCredentialsExpiring,
+		Status:             status,
+		LastTransitionTime: metav1.Now(),
+		Reason:             reason,
+		Message:            message,
+	}
+}
@@ -0,0 +1,158 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/events"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/openmcp-project/metrics-operator/api/v1alpha1"
+	internalmetrics "github.com/openmcp-project/metrics-operator/internal/metrics"
+	orc "github.com/openmcp-project/metrics-operator/internal/orchestrator"
+)
+
+func TestCheckCredentials(t *testing.T) {
+	now := time.Date(2026, 10, 15, 0, 0, 0, 0, time.UTC)
+	access := &metav1.ObjectMeta{Namespace: "team-a", Name: "fleet"}
+	cluster := func(name string, expiry time.Time) orc.QueryConfig {
+		return orc.QueryConfig{ClusterName: ptr.To(name), CredentialsExpiry: expiry}
+	}
+
+	testCases := []struct {
+		name        string
+		configs     []orc.QueryConfig
+		wantExpiry  time.Time
+		wantExpired bool
+		wantStatus  metav1.ConditionStatus
+		wantReason  string
+	}{
+		{
+			name:       "DoNotExpire",
+			configs:    []orc.QueryConfig{cluster("one", time.Time{})},
+			wantStatus: metav1.ConditionFalse,
+			wantReason: "CredentialsDoNotExpire",
+		},
+		{
+			name:       "Valid",
+			configs:    []orc.QueryConfig{cluster("one", now.Add(90*24*time.Hour)), cluster("two", time.Time{})},
+			wantExpiry: now.Add(90 * 24 * time.Hour),
+			wantStatus: metav1.ConditionFalse,
+			wantReason: "CredentialsValid",
+		},
+		{
+			name:       "ExpiringSoon",
+			configs:    []orc.QueryConfig{cluster("one", now.Add(90*24*time.Hour)), cluster("two", now.Add(3*24*time.Hour))},
+			wantExpiry: now.Add(3 * 24 * time.Hour),
+			wantStatus: metav1.ConditionTrue,
+			wantReason: "CredentialsExpiringSoon",
+		},
+		{
+			name:        "Expired",
+			configs:     []orc.QueryConfig{cluster("one", now.Add(-time.Hour))},
+			wantExpiry:  now.Add(-time.Hour),
+			wantExpired: true,
+			wantStatus:  metav1.ConditionTrue,
+			wantReason:  ReasonCredentialsExpired,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			check := checkCredentials("FederatedClusterAccess", access, tc.configs, now)
+			if tc.wantExpiry.IsZero() {
+				require.Nil(t, check.expiry)
+			} else {
+				require.True(t, tc.wantExpiry.Equal(check.expiry.Time))
+			}
+			require.Equal(t, tc.wantExpired, check.expired)
+			require.Equal(t, v1alpha1.TypeCredentialsExpiring, check.condition.Type)
+			require.Equal(t, tc.wantStatus, check.condition.Status)
+			require.Equal(t, tc.wantReason, check.condition.Reason)
+
+			// only the clusters whose credentials expire are recorded, the series of the previous check are removed
+			var recorded int
+			for _, qc := range tc.configs {
+				if !qc.CredentialsExpiry.IsZero() {
+					recorded++
+					days := testutil.ToFloat64(internalmetrics.CredentialsExpiryDays.WithLabelValues("FederatedClusterAccess", "team-a", "fleet", *qc.ClusterName))
+					require.InDelta(t, qc.CredentialsExpiry.Sub(now).Hours()/24, days, 0.001)
+				}
+			}
+			require.Equal(t, recorded, testutil.CollectAndCount(internalmetrics.CredentialsExpiryDays))
+		})
+	}
+	internalmetrics.DeleteCredentialsExpiry("FederatedClusterAccess", "team-a", "fleet")
+}
+
+func TestRemoteClusterAccessReconcile(t *testing.T) {
+	ctx := context.Background()
+	scheme := runtime.NewScheme()
+	require.NoError(t, v1alpha1.AddToScheme(scheme))
+
+	access := &v1alpha1.RemoteClusterAccess{ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "prod"}}
+	cli := fake.NewClientBuilder().WithScheme(scheme).WithObjects(access).WithStatusSubresource(access).Build()
+
+	var qc *orc.QueryConfig
+	var errQC error
+	recorder := events.NewFakeRecorder(10)
+	r := &RemoteClusterAccessReconciler{
+		log:      logr.Discard(),
+		inCli:    cli,
+		Recorder: recorder,
+		queryConfig: func(context.Context, *v1alpha1.RemoteClusterAccessRef, client.Client) (*orc.QueryConfig, error) {
+			return qc, errQC
+		},
+	}
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "team-a", Name: "prod"}}
+	reconcile := func() *v1alpha1.RemoteClusterAccess {
+		t.Helper()
+		result, err := r.Reconcile(ctx, req)
+		require.NoError(t, err)
+		require.NotZero(t, result.RequeueAfter)
+		stored := &v1alpha1.RemoteClusterAccess{}
+		require.NoError(t, cli.Get(ctx, client.ObjectKeyFromObject(access), stored))
+		return stored
+	}
+
+	// credentials expiring soon keep the access ready and emit a warning once
+	expiry := time.Now().Add(24 * time.Hour)
+	qc = &orc.QueryConfig{ClusterName: ptr.To("prod"), CredentialsExpiry: expiry}
+	stored := reconcile()
+	require.True(t, meta.IsStatusConditionTrue(stored.Status.Conditions, v1alpha1.TypeReady))
+	require.True(t, meta.IsStatusConditionTrue(stored.Status.Conditions, v1alpha1.TypeCredentialsExpiring))
+	require.Equal(t, expiry.Unix(), stored.Status.CredentialsExpiry.Unix())
+	require.NotNil(t, stored.Status.LastCheckTime)
+	require.Len(t, recorder.Events, 1)
+	<-recorder.Events
+	reconcile()
+	require.Empty(t, recorder.Events)
+
+	// expired credentials make the access unhealthy
+	qc = &orc.QueryConfig{ClusterName: ptr.To("prod"), CredentialsExpiry: time.Now().Add(-time.Minute)}
+	stored = reconcile()
+	ready := meta.FindStatusCondition(stored.Status.Conditions, v1alpha1.TypeReady)
+	require.Equal(t, metav1.ConditionFalse, ready.Status)
+	require.Equal(t, ReasonCredentialsExpired, ready.Reason)
+
+	// credentials no client can be created from are reported as invalid
+	qc, errQC = nil, errors.New("failed to parse kubeconfig")
+	stored = reconcile()
+	ready = meta.FindStatusCondition(stored.Status.Conditions, v1alpha1.TypeReady)
+	require.Equal(t, ReasonCredentialsInvalid, ready.Reason)
+	require.Equal(t, metav1.ConditionUnknown, meta.FindStatusCondition(stored.Status.Conditions, v1alpha1.TypeCredentialsExpiring).Status)
+	require.Nil(t, stored.Status.CredentialsExpiry)
+	require.Zero(t, testutil.CollectAndCount(internalmetrics.CredentialsExpiryDays))
+}
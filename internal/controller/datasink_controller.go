@@ -0,0 +1,165 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-logr/logr"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/events"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	"github.com/openmcp-project/metrics-operator/api/v1alpha1"
+	"github.com/openmcp-project/metrics-operator/internal/clientoptl"
+	"github.com/openmcp-project/metrics-operator/internal/common"
+)
+
+const (
+	// DefaultDataSinkProbeInterval is the default interval in which the connectivity of data sinks is checked
+	DefaultDataSinkProbeInterval = 5 * time.Minute
+
+	dataSinkProbeTimeout = 30 * time.Second
+)
+
+// ProbeFunc checks the connectivity of a data sink with the given credentials
+type ProbeFunc func(ctx context.Context, credentials *common.DataSinkCredentials) error
+
+// NewDataSinkReconciler creates a new DataSinkReconciler
+func NewDataSinkReconciler(mgr ctrl.Manager) *DataSinkReconciler {
+	return &DataSinkReconciler{
+		log: mgr.GetLogger().WithName("controllers").WithName("DataSink"),
+
+		inCli:    mgr.GetClient(),
+		Recorder: SharedEventThrottle.Recorder(mgr.GetEventRecorder("datasink-controller")),
+
+		ProbeInterval: DefaultDataSinkProbeInterval,
+		probe:         clientoptl.Probe,
+		breakers:      clientoptl.SharedCircuitBreakers,
+	}
+}
+
+// DataSinkReconciler periodically checks that data sinks are reachable and accept their credentials
+type DataSinkReconciler struct {
+	log logr.Logger
+
+	inCli    client.Client
+	Recorder events.EventRecorder
+
+	// ProbeInterval is the interval in which the connectivity of a data sink is checked
+	ProbeInterval time.Duration
+
+	probe    ProbeFunc
+	breakers *clientoptl.CircuitBreakers
+}
+
+// +kubebuilder:rbac:groups=metrics.openmcp.cloud,resources=datasinks,verbs=get;list;watch
+// +kubebuilder:rbac:groups=metrics.openmcp.cloud,resources=datasinks/status,verbs=get;update;patch
+
+// Reconcile probes the data sink with an empty export and reports the result in the Ready and Degraded conditions
+func (r *DataSinkReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	l := r.log.WithValues("namespace", req.Namespace, "name", req.Name)
+
+	dataSink := v1alpha1.DataSink{}
+	if errLoad := r.inCli.Get(ctx, req.NamespacedName, &dataSink); errLoad != nil {
+		if apierrors.IsNotFound(errLoad) {
+			r.breakers.Remove(req.String())
+			return ctrl.Result{}, nil
+		}
+		l.Error(errLoad, "unable to fetch DataSink")
+		return ctrl.Result{RequeueAfter: RequeueAfterError}, errLoad
+	}
+
+	// Defer status update to ensure it's always called
+	patchStatus := statusPatch(r.inCli, &dataSink)
+	defer func() {
+		if err := patchStatus(ctx); err != nil {
+			l.Error(err, "Failed to update DataSink status")
+		}
+	}()
+
+	now := metav1.Now()
+	dataSink.Status.LastProbeTime = &now
+
+	// the circuit breaker is shared by the metrics exporting to the data sink, its state is reported after the probe
+	breaker := r.breakers.For(req.String())
+	defer func() {
+		status := breaker.Status()
+		dataSink.Status.CircuitBreaker = &status
+	}()
+
+	retriever := NewDataSinkCredentialsRetriever(r.inCli, r.Recorder)
+	credentials, errCredentials := retriever.credentialsForDataSink(ctx, &dataSink, &dataSink, l)
+	if errCredentials != nil {
+		r.setUnhealthy(&dataSink, "CredentialsUnavailable", errCredentials.Error())
+		return ctrl.Result{RequeueAfter: RequeueAfterError}, nil
+	}
+
+	probeCtx, cancel := context.WithTimeout(ctx, dataSinkProbeTimeout)
+	defer cancel()
+
+	if errProbe := r.probe(probeCtx, credentials); errProbe != nil {
+		l.Info("data sink probe failed", "endpoint", dataSink.Spec.Connection.Endpoint, "error", errProbe.Error())
+		reason := "ProbeFailed"
+		if rejection := clientoptl.Rejection(errProbe); rejection != "" {
+			reason = string(rejection)
+		}
+		r.setUnhealthy(&dataSink, reason, errProbe.Error())
+		return ctrl.Result{RequeueAfter: RequeueAfterError}, nil
+	}
+
+	if meta.IsStatusConditionFalse(dataSink.Status.Conditions, v1alpha1.TypeReady) {
+		r.Recorder.Eventf(&dataSink, nil, "Normal", "ProbeSucceeded", "ReconcileDataSink", "data sink is reachable again")
+	}
+	dataSink.Status.LastSuccessfulProbeTime = &now
+	// exports resume right away instead of waiting for the next trial export of the circuit breaker
+	breaker.Reset()
+	dataSink.SetConditions(
+		common.ReadyTrue("data sink accepted the probe export"),
+		common.DegradedFalse("data sink accepted the probe export"),
+	)
+
+	return ctrl.Result{RequeueAfter: r.ProbeInterval}, nil
+}
+
+// setUnhealthy reports the error in the conditions of the data sink.
+// The warning event is only emitted when the data sink becomes unhealthy, not on every failed probe.
+func (r *DataSinkReconciler) setUnhealthy(dataSink *v1alpha1.DataSink, reason, message string) {
+	if !meta.IsStatusConditionFalse(dataSink.Status.Conditions, v1alpha1.TypeReady) {
+		r.Recorder.Eventf(dataSink, nil, "Warning", reason, "ReconcileDataSink", message)
+	}
+	dataSink.SetConditions(
+		common.ReadyFalse(reason, message),
+		common.DegradedTrue(reason, message),
+	)
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *DataSinkReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(DataSinkControllerName).
+		WithOptions(Controllers.forController(DataSinkControllerName)).
+		// only spec changes trigger a probe, status updates of the probe itself are ignored
+		For(&v1alpha1.DataSink{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Complete(r)
+}
@@ -0,0 +1,244 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/events"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/openmcp-project/metrics-operator/api/v1alpha1"
+	"github.com/openmcp-project/metrics-operator/internal/clientoptl"
+	"github.com/openmcp-project/metrics-operator/internal/common"
+)
+
+func TestDataSinkReconciler_Reconcile(t *testing.T) {
+	apiKeyAuth := &v1alpha1.Authentication{APIKey: &v1alpha1.APIKeyAuthentication{
+		SecretKeyRef: corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "sink-token"}, Key: "token"},
+	}}
+
+	testCases := []struct {
+		name             string
+		authentication   *v1alpha1.Authentication
+		dimensionPolicy  *v1alpha1.DimensionPolicy
+		probeErr         error
+		expectedReady    metav1.ConditionStatus
+		expectedDegraded metav1.ConditionStatus
+		expectedReason   string
+		expectedEvent    string
+	}{
+		{
+			name:             "Reachable",
+			authentication:   apiKeyAuth,
+			expectedReady:    metav1.ConditionTrue,
+			expectedDegraded: metav1.ConditionFalse,
+			expectedReason:   "ReconciliationSucceeded",
+		},
+		{
+			name:             "ProbeFailed",
+			authentication:   apiKeyAuth,
+			probeErr:         errors.New("failed to upload metrics: 401 Unauthorized"),
+			expectedReady:    metav1.ConditionFalse,
+			expectedDegraded: metav1.ConditionTrue,
+			expectedReason:   "ProbeFailed",
+			expectedEvent:    "Warning ProbeFailed failed to upload metrics: 401 Unauthorized",
+		},
+		{
+			name: "CredentialsUnavailable",
+			authentication: &v1alpha1.Authentication{APIKey: &v1alpha1.APIKeyAuthentication{
+				SecretKeyRef: corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "missing"}, Key: "token"},
+			}},
+			expectedReady:    metav1.ConditionFalse,
+			expectedDegraded: metav1.ConditionTrue,
+			expectedReason:   "CredentialsUnavailable",
+		},
+		{
+			name:           "InvalidDimensionPolicy",
+			authentication: apiKeyAuth,
+			dimensionPolicy: &v1alpha1.DimensionPolicy{
+				Redactions: []v1alpha1.DimensionRedaction{{Pattern: "team-("}},
+			},
+			expectedReady:    metav1.ConditionFalse,
+			expectedDegraded: metav1.ConditionTrue,
+			expectedReason:   "CredentialsUnavailable",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			require.NoError(t, v1alpha1.AddToScheme(scheme))
+			require.NoError(t, corev1.AddToScheme(scheme))
+
+			dataSink := &v1alpha1.DataSink{
+				ObjectMeta: metav1.ObjectMeta{Name: "default", Namespace: "metrics"},
+				Spec: v1alpha1.DataSinkSpec{
+					Connection:      v1alpha1.Connection{Endpoint: "https://sink.example.com/otlp/v1/metrics"},
+					Authentication:  tc.authentication,
+					DimensionPolicy: tc.dimensionPolicy,
+				},
+			}
+			secret := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "sink-token", Namespace: "metrics"},
+				Data:       map[string][]byte{"token": []byte("secret-token")},
+			}
+			cli := fake.NewClientBuilder().WithScheme(scheme).
+				WithObjects(dataSink, secret).
+				WithStatusSubresource(&v1alpha1.DataSink{}).
+				Build()
+
+			var probedCredentials *common.DataSinkCredentials
+			recorder := events.NewFakeRecorder(10)
+			r := &DataSinkReconciler{
+				log:           logr.Discard(),
+				inCli:         cli,
+				Recorder:      recorder,
+				ProbeInterval: DefaultDataSinkProbeInterval,
+				probe: func(_ context.Context, credentials *common.DataSinkCredentials) error {
+					probedCredentials = credentials
+					return tc.probeErr
+				},
+				breakers: clientoptl.NewCircuitBreakers(clientoptl.DefaultFailureThreshold, clientoptl.DefaultOpenDuration),
+			}
+
+			key := types.NamespacedName{Namespace: "metrics", Name: "default"}
+			_, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: key})
+			require.NoError(t, err)
+
+			updated := v1alpha1.DataSink{}
+			require.NoError(t, cli.Get(context.Background(), key, &updated))
+
+			ready := meta.FindStatusCondition(updated.Status.Conditions, v1alpha1.TypeReady)
+			require.NotNil(t, ready)
+			require.Equal(t, tc.expectedReady, ready.Status)
+			require.Equal(t, tc.expectedReason, ready.Reason)
+
+			degraded := meta.FindStatusCondition(updated.Status.Conditions, v1alpha1.TypeDegraded)
+			require.NotNil(t, degraded)
+			require.Equal(t, tc.expectedDegraded, degraded.Status)
+
+			require.NotNil(t, updated.Status.LastProbeTime)
+			require.Equal(t, tc.expectedReady == metav1.ConditionTrue, updated.Status.LastSuccessfulProbeTime != nil)
+
+			if tc.expectedReason != "CredentialsUnavailable" {
+				require.NotNil(t, probedCredentials)
+				require.Equal(t, "secret-token", probedCredentials.APIKey.Token)
+			}
+			if tc.expectedEvent != "" {
+				require.Contains(t, <-recorder.Events, tc.expectedEvent)
+			}
+		})
+	}
+}
+
+func TestDataSinkReconciler_Reconcile_eventOnlyOnTransition(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, v1alpha1.AddToScheme(scheme))
+
+	dataSink := &v1alpha1.DataSink{
+		ObjectMeta: metav1.ObjectMeta{Name: "default", Namespace: "metrics"},
+		Spec:       v1alpha1.DataSinkSpec{Connection: v1alpha1.Connection{Endpoint: "http://sink:4318/v1/metrics"}},
+	}
+	cli := fake.NewClientBuilder().WithScheme(scheme).
+		WithObjects(dataSink).
+		WithStatusSubresource(&v1alpha1.DataSink{}).
+		Build()
+
+	probeErr := errors.New("connection refused")
+	recorder := events.NewFakeRecorder(10)
+	r := &DataSinkReconciler{
+		log:      logr.Discard(),
+		inCli:    cli,
+		Recorder: recorder,
+		probe: func(context.Context, *common.DataSinkCredentials) error {
+			return probeErr
+		},
+		breakers: clientoptl.NewCircuitBreakers(clientoptl.DefaultFailureThreshold, clientoptl.DefaultOpenDuration),
+	}
+
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "metrics", Name: "default"}}
+	for range 2 {
+		_, err := r.Reconcile(context.Background(), req)
+		require.NoError(t, err)
+	}
+	probeErr = nil
+	_, err := r.Reconcile(context.Background(), req)
+	require.NoError(t, err)
+
+	require.Len(t, recorder.Events, 2)
+	require.Contains(t, <-recorder.Events, "Warning ProbeFailed")
+	require.Contains(t, <-recorder.Events, "Normal ProbeSucceeded")
+}
+
+func TestDataSinkReconciler_Reconcile_circuitBreaker(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, v1alpha1.AddToScheme(scheme))
+
+	dataSink := &v1alpha1.DataSink{
+		ObjectMeta: metav1.ObjectMeta{Name: "default", Namespace: "metrics"},
+		Spec:       v1alpha1.DataSinkSpec{Connection: v1alpha1.Connection{Endpoint: "http://sink:4318/v1/metrics"}},
+	}
+	cli := fake.NewClientBuilder().WithScheme(scheme).
+		WithObjects(dataSink).
+		WithStatusSubresource(&v1alpha1.DataSink{}).
+		Build()
+
+	breakers := clientoptl.NewCircuitBreakers(1, time.Hour)
+	breaker := breakers.For("metrics/default")
+	breaker.Record(errors.New("connection refused"))
+	require.ErrorIs(t, breaker.Allow(), clientoptl.ErrCircuitOpen)
+
+	r := &DataSinkReconciler{
+		log:      logr.Discard(),
+		inCli:    cli,
+		Recorder: events.NewFakeRecorder(10),
+		probe:    func(context.Context, *common.DataSinkCredentials) error { return nil },
+		breakers: breakers,
+	}
+
+	key := types.NamespacedName{Namespace: "metrics", Name: "default"}
+	_, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: key})
+	require.NoError(t, err)
+
+	// the successful probe closes the circuit breaker, so exports resume right away
+	require.NoError(t, breaker.Allow())
+
+	updated := v1alpha1.DataSink{}
+	require.NoError(t, cli.Get(context.Background(), key, &updated))
+	require.NotNil(t, updated.Status.CircuitBreaker)
+	require.Equal(t, v1alpha1.CircuitClosed, updated.Status.CircuitBreaker.State)
+	require.Zero(t, updated.Status.CircuitBreaker.ConsecutiveFailures)
+
+	// the circuit breaker of a deleted data sink is dropped
+	require.NoError(t, cli.Delete(context.Background(), &updated))
+	_, err = r.Reconcile(context.Background(), ctrl.Request{NamespacedName: key})
+	require.NoError(t, err)
+	_, ok := breakers.Get("metrics/default")
+	require.False(t, ok)
+}
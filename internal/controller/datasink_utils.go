@@ -17,9 +17,13 @@ limitations under the License.
 package controller
 
 import (
+	"cmp"
 	"context"
+	"errors"
 	"fmt"
 	"os"
+	"regexp"
+	"slices"
 
 	"github.com/go-logr/logr"
 	corev1 "k8s.io/api/core/v1"
@@ -32,6 +36,12 @@ import (
 	"github.com/openmcp-project/metrics-operator/internal/common"
 )
 
+// DefaultDataSinkName is the default name of the DataSink of metrics whose dataSinkRef does not name one
+const DefaultDataSinkName = "default"
+
+// DefaultDataSink is the name of the DataSink of metrics whose dataSinkRef does not name one
+var DefaultDataSink = DefaultDataSinkName
+
 // DataSinkCredentialsRetriever provides common functionality for retrieving DataSink credentials
 type DataSinkCredentialsRetriever struct {
 	client   client.Client
@@ -50,8 +60,6 @@ func NewDataSinkCredentialsRetriever(client client.Client, recorder events.Event
 // Returns (nil, nil) when dataSinkRef is nil — callers should treat nil credentials as
 // "no DataSink configured" and skip OTLP export. A non-nil error indicates a genuine problem.
 // If dataSinkRef is provided but the DataSink CR cannot be found, an error is returned.
-//
-//nolint:gocyclo
 func (d *DataSinkCredentialsRetriever) GetDataSinkCredentials(ctx context.Context, dataSinkRef *v1alpha1.DataSinkReference, eventObject client.Object, l logr.Logger) (*common.DataSinkCredentials, error) {
 	// dataSinkRef is optional; nil means no OTLP export.
 	if dataSinkRef == nil {
@@ -68,14 +76,16 @@ func (d *DataSinkCredentialsRetriever) GetDataSinkCredentials(ctx context.Contex
 			l.Info("Neither OPERATOR_CONFIG_NAMESPACE nor POD_NAMESPACE is set. Defaulting DataSink lookup to 'default' namespace.")
 			dataSinkLookupNamespace = "default"
 		} else {
-			l.Info("Using POD_NAMESPACE for DataSink lookup.", "namespace", dataSinkLookupNamespace)
+			l.V(1).Info("Using POD_NAMESPACE for DataSink lookup.", "namespace", dataSinkLookupNamespace)
 		}
 	} else {
-		l.Info("Using OPERATOR_CONFIG_NAMESPACE for DataSink lookup.", "namespace", dataSinkLookupNamespace)
+		l.V(1).Info("Using OPERATOR_CONFIG_NAMESPACE for DataSink lookup.", "namespace", dataSinkLookupNamespace)
 	}
 
 	// Determine DataSink name
-	dataSinkName := "default"
+	settingsMu.RLock()
+	dataSinkName := DefaultDataSink
+	settingsMu.RUnlock()
 	if dataSinkRef.Name != "" {
 		dataSinkName = dataSinkRef.Name
 	}
@@ -93,15 +103,111 @@ func (d *DataSinkCredentialsRetriever) GetDataSinkCredentials(ctx context.Contex
 		return nil, err
 	}
 
+	if !namespaceAllowed(dataSink, eventObject.GetNamespace()) {
+		msg := fmt.Sprintf("DataSink '%s' in namespace '%s' does not allow metrics of namespace '%s'", dataSinkName, dataSinkLookupNamespace, eventObject.GetNamespace())
+		d.recorder.Eventf(eventObject, nil, "Warning", "DataSinkNotAllowed", "GetDataSinkCredentials", msg)
+		return nil, errors.New(msg)
+	}
+
+	return d.credentialsForDataSink(ctx, dataSink, eventObject, l)
+}
+
+// namespaceAllowed returns true if metrics of the namespace may export to the data sink
+func namespaceAllowed(dataSink *v1alpha1.DataSink, namespace string) bool {
+	allowed := dataSink.Spec.AllowedNamespaces
+	return len(allowed) == 0 || namespace == dataSink.Namespace || slices.Contains(allowed, namespace)
+}
+
+// credentialsForDataSink reads the credentials of the DataSink from the secrets in its namespace
+//
+//nolint:gocyclo
+func (d *DataSinkCredentialsRetriever) credentialsForDataSink(ctx context.Context, dataSink *v1alpha1.DataSink, eventObject client.Object, l logr.Logger) (*common.DataSinkCredentials, error) {
+	dataSinkName := dataSink.Name
+	dataSinkLookupNamespace := dataSink.Namespace
+
 	// Extract endpoint from DataSink
 	endpoint := dataSink.Spec.Connection.Endpoint
 	// Construct credentials compatible with clientoptl.NewMetricClient
 	// For now, we'll use the full endpoint as Host and empty Path
 	// TODO: Parse endpoint to separate host and path if needed based on protocol
 	credentials := common.DataSinkCredentials{
+		Name: dataSinkLookupNamespace + "/" + dataSinkName,
+		Type: string(dataSink.Spec.Type),
 		Host: endpoint, // Full endpoint URL (e.g., https://example.dynatrace.com)
 		Path: "",       // Base path for API (will be combined with /otlp/v1/metrics in clientoptl)
 	}
+	if proxy := dataSink.Spec.Connection.Proxy; proxy != nil {
+		credentials.Proxy = &common.ProxyConfig{URL: proxy.URL, NoProxy: proxy.NoProxy}
+	}
+	if caBundle := dataSink.Spec.Connection.CABundle; caBundle != nil {
+		secret := &corev1.Secret{}
+		if err := fetchSecret(ctx, d.client, types.NamespacedName{Namespace: dataSinkLookupNamespace, Name: caBundle.Name}, secret, l); err != nil {
+			return nil, err
+		}
+		bundle, err := getSecretKeyData(secret, caBundle.Key, caBundle.Name, l)
+		if err != nil {
+			d.recorder.Eventf(eventObject, nil, "Error", "SecretKeyNotFound", "GetDataSinkCredentials", fmt.Sprintf("key '%s' not found in secret '%s'", caBundle.Key, caBundle.Name))
+			return nil, err
+		}
+		credentials.CABundle = bundle
+	}
+	if dataSink.Spec.Connection.Compression == v1alpha1.CompressionGzip {
+		credentials.Compression = "gzip"
+	}
+	credentials.MaxPayloadBytes = int(dataSink.Spec.Connection.MaxPayloadBytes)
+	if timeout := dataSink.Spec.Connection.Timeout; timeout != nil {
+		credentials.Timeout = timeout.Duration
+	}
+	if retry := dataSink.Spec.Connection.Retry; retry != nil {
+		credentials.Retry = &common.RetryConfig{
+			Enabled:         retry.Enabled == nil || *retry.Enabled,
+			InitialInterval: retry.InitialInterval.Duration,
+			MaxInterval:     retry.MaxInterval.Duration,
+			MaxElapsedTime:  retry.MaxElapsedTime.Duration,
+		}
+	}
+	if grpcSettings := dataSink.Spec.Connection.GRPC; grpcSettings != nil {
+		credentials.GRPC = &common.GRPCConfig{KeepaliveTimeout: grpcSettings.KeepaliveTimeout.Duration}
+		if grpcSettings.KeepaliveTime != nil {
+			credentials.GRPC.KeepaliveTime = grpcSettings.KeepaliveTime.Duration
+		}
+	}
+	if influx := dataSink.Spec.InfluxDB; influx != nil {
+		credentials.InfluxDB = &common.InfluxDBTarget{Org: influx.Org, Bucket: influx.Bucket}
+	}
+	if otlp := dataSink.Spec.OTLP; otlp != nil {
+		credentials.OTLP = &common.OTLPConfig{Temporality: string(otlp.Temporality), Aggregation: string(otlp.Aggregation)}
+		for _, boundary := range otlp.HistogramBoundaries {
+			credentials.OTLP.HistogramBoundaries = append(credentials.OTLP.HistogramBoundaries, float64(boundary))
+		}
+	}
+	if webhook := dataSink.Spec.Webhook; webhook != nil {
+		credentials.Webhook = &common.WebhookTarget{Template: webhook.Template, ContentType: webhook.ContentType}
+		if webhook.HeadersSecretRef != nil {
+			secret := &corev1.Secret{}
+			secretNamespacedName := types.NamespacedName{Namespace: dataSinkLookupNamespace, Name: webhook.HeadersSecretRef.Name}
+			if err := fetchSecret(ctx, d.client, secretNamespacedName, secret, l); err != nil {
+				return nil, err
+			}
+			credentials.Webhook.Headers = make(map[string]string, len(secret.Data))
+			for key, value := range secret.Data {
+				credentials.Webhook.Headers[key] = string(value)
+			}
+		}
+	}
+	if deleted := dataSink.Spec.DeletedSeries; deleted != nil && deleted.Policy == v1alpha1.DeletedSeriesFinalZero {
+		credentials.FinalZero = true
+		credentials.FinalZeroGracePeriod = deleted.GracePeriod.Duration
+	}
+	if dataSink.Spec.DimensionPolicy != nil {
+		policy, err := newDimensionPolicy(dataSink.Spec.DimensionPolicy)
+		if err != nil {
+			l.Error(err, fmt.Sprintf("invalid dimension policy of DataSink '%s'", dataSinkName))
+			d.recorder.Eventf(eventObject, nil, "Error", "InvalidDimensionPolicy", "GetDataSinkCredentials", err.Error())
+			return nil, err
+		}
+		credentials.DimensionPolicy = policy
+	}
 
 	// Handle token authentication
 	var token string
@@ -190,11 +296,40 @@ func (d *DataSinkCredentialsRetriever) GetDataSinkCredentials(ctx context.Contex
 		}
 	}
 
-	l.Info(fmt.Sprintf("Using DataSink '%s' with endpoint '%s'", dataSinkName, endpoint))
+	l.V(1).Info(fmt.Sprintf("Using DataSink '%s' with endpoint '%s'", dataSinkName, endpoint))
 
 	return &credentials, nil
 }
 
+// newDimensionPolicy compiles the dimension policy of a DataSink
+func newDimensionPolicy(spec *v1alpha1.DimensionPolicy) (*common.DimensionPolicy, error) {
+	keySet := func(keys []string) map[string]bool {
+		set := make(map[string]bool, len(keys))
+		for _, key := range keys {
+			set[key] = true
+		}
+		return set
+	}
+
+	policy := &common.DimensionPolicy{
+		AllowedKeys: keySet(spec.AllowedKeys),
+		DeniedKeys:  keySet(spec.DeniedKeys),
+		Redactions:  make([]common.DimensionRedaction, 0, len(spec.Redactions)),
+	}
+	for _, redaction := range spec.Redactions {
+		pattern, err := regexp.Compile(redaction.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redaction pattern '%s' in dimension policy: %w", redaction.Pattern, err)
+		}
+		policy.Redactions = append(policy.Redactions, common.DimensionRedaction{
+			Keys:        keySet(redaction.Keys),
+			Pattern:     pattern,
+			Replacement: cmp.Or(redaction.Replacement, "REDACTED"),
+		})
+	}
+	return policy, nil
+}
+
 func fetchSecret(ctx context.Context, c client.Client, namespacedName types.NamespacedName, secret *corev1.Secret, l logr.Logger) error {
 	if err := c.Get(ctx, namespacedName, secret); err != nil {
 		if apierrors.IsNotFound(err) {
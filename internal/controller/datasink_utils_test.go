@@ -0,0 +1,71 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/events"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/openmcp-project/metrics-operator/api/v1alpha1"
+)
+
+func TestGetDataSinkCredentials_allowedNamespaces(t *testing.T) {
+	t.Setenv("OPERATOR_CONFIG_NAMESPACE", "metrics")
+
+	tests := []struct {
+		name              string
+		allowedNamespaces []string
+		namespace         string
+		wantErr           bool
+	}{
+		{name: "no restriction", namespace: "team-b"},
+		{name: "allowed namespace", allowedNamespaces: []string{"team-a", "team-b"}, namespace: "team-b"},
+		{name: "namespace of the data sink", allowedNamespaces: []string{"team-a"}, namespace: "metrics"},
+		{name: "other namespace", allowedNamespaces: []string{"team-a"}, namespace: "team-b", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			require.NoError(t, v1alpha1.AddToScheme(scheme))
+			dataSink := &v1alpha1.DataSink{
+				ObjectMeta: metav1.ObjectMeta{Name: "team-a", Namespace: "metrics"},
+				Spec: v1alpha1.DataSinkSpec{
+					Connection:        v1alpha1.Connection{Endpoint: "https://team-a.example.com"},
+					AllowedNamespaces: tt.allowedNamespaces,
+				},
+			}
+			cli := fake.NewClientBuilder().WithScheme(scheme).WithObjects(dataSink).Build()
+			metric := &v1alpha1.Metric{ObjectMeta: metav1.ObjectMeta{Name: "pods", Namespace: tt.namespace}}
+
+			credentials, err := NewDataSinkCredentialsRetriever(cli, events.NewFakeRecorder(10)).
+				GetDataSinkCredentials(context.Background(), &v1alpha1.DataSinkReference{Name: "team-a"}, metric, logr.Discard())
+			if tt.wantErr {
+				require.ErrorContains(t, err, "does not allow metrics of namespace 'team-b'")
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, "metrics/team-a", credentials.Name)
+		})
+	}
+}
@@ -0,0 +1,136 @@
+package controller
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/events"
+
+	internalmetrics "github.com/openmcp-project/metrics-operator/internal/metrics"
+)
+
+// DefaultEventInterval is the default interval in which an unchanged event of an object is emitted again
+const DefaultEventInterval = time.Hour
+
+// SharedEventThrottle is used by the event recorders of all reconcilers
+var SharedEventThrottle = NewEventThrottle(DefaultEventInterval)
+
+// eventKey identifies the events of an object with the same reason
+type eventKey struct {
+	object string
+	reason string
+}
+
+// emittedEvent is the last emitted event of an eventKey
+type emittedEvent struct {
+	eventtype string
+	note      string
+	at        time.Time
+}
+
+// EventThrottle drops the events that repeat a recent event of the same object, so reconciles that emit
+// the same event every interval do not flood the API server. An event is emitted if its object had no event
+// with the same reason yet, if its note or type changed, or if the last one was emitted at least an interval ago.
+// Every emitted warning forgets the normal events of its object, so the recovery of a failing object is always emitted.
+type EventThrottle struct {
+	mu        sync.Mutex
+	interval  time.Duration
+	emitted   map[eventKey]emittedEvent
+	lastPrune time.Time
+
+	now func() time.Time
+}
+
+// NewEventThrottle creates an event throttle that emits unchanged events once per interval, an interval of zero disables it
+func NewEventThrottle(interval time.Duration) *EventThrottle {
+	return &EventThrottle{
+		interval: interval,
+		emitted:  make(map[eventKey]emittedEvent),
+		now:      time.Now,
+	}
+}
+
+// SetInterval changes the interval in which unchanged events are emitted again, zero emits all events
+func (t *EventThrottle) SetInterval(interval time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.interval = interval
+}
+
+// Recorder returns an event recorder that emits the events of the recorder allowed by the throttle
+func (t *EventThrottle) Recorder(recorder events.EventRecorder) events.EventRecorder {
+	return &throttledRecorder{recorder: recorder, throttle: t}
+}
+
+// allow returns true if the event is to be emitted and remembers it
+func (t *EventThrottle) allow(regarding runtime.Object, eventtype, reason, note string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.interval <= 0 {
+		return true
+	}
+	now := t.now()
+	t.prune(now)
+
+	key := eventKey{object: objectKey(regarding), reason: reason}
+	if last, ok := t.emitted[key]; ok && last.eventtype == eventtype && last.note == note && now.Sub(last.at) < t.interval {
+		return false
+	}
+	if eventtype != corev1.EventTypeNormal {
+		for k, e := range t.emitted {
+			if k.object == key.object && e.eventtype == corev1.EventTypeNormal {
+				delete(t.emitted, k)
+			}
+		}
+	}
+	t.emitted[key] = emittedEvent{eventtype: eventtype, note: note, at: now}
+	return true
+}
+
+// prune forgets the events emitted more than an interval ago, e.g. of deleted objects, at most once per interval
+func (t *EventThrottle) prune(now time.Time) {
+	if now.Sub(t.lastPrune) < t.interval {
+		return
+	}
+	for k, e := range t.emitted {
+		if now.Sub(e.at) >= t.interval {
+			delete(t.emitted, k)
+		}
+	}
+	t.lastPrune = now
+}
+
+// objectKey identifies the object of an event by its UID, or by its type, namespace and name if it has none
+func objectKey(obj runtime.Object) string {
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return fmt.Sprintf("%T", obj)
+	}
+	if uid := accessor.GetUID(); uid != "" {
+		return string(uid)
+	}
+	return fmt.Sprintf("%T/%s/%s", obj, accessor.GetNamespace(), accessor.GetName())
+}
+
+// throttledRecorder emits the events allowed by its throttle
+type throttledRecorder struct {
+	recorder events.EventRecorder
+	throttle *EventThrottle
+}
+
+// Eventf emits the event unless it repeats a recent event of the same object
+func (r *throttledRecorder) Eventf(regarding runtime.Object, related runtime.Object, eventtype, reason, action, note string, args ...interface{}) {
+	message := note
+	if len(args) > 0 {
+		message = fmt.Sprintf(note, args...)
+	}
+	if !r.throttle.allow(regarding, eventtype, reason, message) {
+		internalmetrics.SuppressedEvents.WithLabelValues(reason).Inc()
+		return
+	}
+	r.recorder.Eventf(regarding, related, eventtype, reason, action, note, args...)
+}
@@ -0,0 +1,62 @@
+package controller
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/events"
+
+	"github.com/openmcp-project/metrics-operator/api/v1alpha1"
+)
+
+func TestEventThrottle(t *testing.T) {
+	now := time.Date(2024, time.May, 15, 12, 0, 0, 0, time.UTC)
+	throttle := NewEventThrottle(time.Hour)
+	throttle.now = func() time.Time { return now }
+	fake := events.NewFakeRecorder(20)
+	recorder := throttle.Recorder(fake)
+
+	pods := &v1alpha1.Metric{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "pods", UID: "pods-uid"}}
+	nodes := &v1alpha1.Metric{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "nodes"}}
+	available := func(metric *v1alpha1.Metric) {
+		recorder.Eventf(metric, nil, corev1.EventTypeNormal, "MetricAvailable", "ReconcileMetric", "metric is monitoring resource '%s'", "Pod")
+	}
+	emitted := func() []string {
+		var got []string
+		for len(fake.Events) > 0 {
+			got = append(got, <-fake.Events)
+		}
+		return got
+	}
+
+	available(pods)
+	available(pods)
+	available(nodes)
+	require.Equal(t, []string{
+		"Normal MetricAvailable metric is monitoring resource 'Pod'",
+		"Normal MetricAvailable metric is monitoring resource 'Pod'",
+	}, emitted(), "repeated events of an object are dropped, other objects are throttled separately")
+
+	now = now.Add(10 * time.Minute)
+	recorder.Eventf(pods, nil, corev1.EventTypeNormal, "MetricAvailable", "ReconcileMetric", "metric is monitoring resource 'Deployment'")
+	require.Equal(t, []string{"Normal MetricAvailable metric is monitoring resource 'Deployment'"}, emitted(), "changed notes are emitted right away")
+
+	recorder.Eventf(pods, nil, corev1.EventTypeWarning, "MetricFailed", "ReconcileMetric", "forbidden")
+	recorder.Eventf(pods, nil, corev1.EventTypeWarning, "MetricFailed", "ReconcileMetric", "forbidden")
+	recorder.Eventf(pods, nil, corev1.EventTypeNormal, "MetricAvailable", "ReconcileMetric", "metric is monitoring resource 'Deployment'")
+	require.Equal(t, []string{
+		"Warning MetricFailed forbidden",
+		"Normal MetricAvailable metric is monitoring resource 'Deployment'",
+	}, emitted(), "the recovery after a warning is emitted")
+
+	now = now.Add(time.Hour)
+	available(nodes)
+	require.Equal(t, []string{"Normal MetricAvailable metric is monitoring resource 'Pod'"}, emitted(), "unchanged events are emitted again after the interval")
+
+	throttle.SetInterval(0)
+	available(nodes)
+	require.Len(t, emitted(), 1, "an interval of zero emits all events")
+}
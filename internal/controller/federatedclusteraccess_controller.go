@@ -0,0 +1,270 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/events"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	"github.com/openmcp-project/metrics-operator/api/v1alpha1"
+	"github.com/openmcp-project/metrics-operator/internal/common"
+	"github.com/openmcp-project/metrics-operator/internal/config"
+	internalmetrics "github.com/openmcp-project/metrics-operator/internal/metrics"
+)
+
+// federatedMemberResync is the interval in which the member clusters are re-listed
+// in addition to the changes observed by the member watch
+const federatedMemberResync = 10 * time.Minute
+
+// federatedClusterAccessKind is the kind label of the credentials expiry of FederatedClusterAccesses
+const federatedClusterAccessKind = "FederatedClusterAccess"
+
+// NewFederatedClusterAccessReconciler creates a new FederatedClusterAccessReconciler
+func NewFederatedClusterAccessReconciler(mgr ctrl.Manager) *FederatedClusterAccessReconciler {
+	return &FederatedClusterAccessReconciler{
+		log: mgr.GetLogger().WithName("controllers").WithName("FederatedClusterAccess"),
+
+		inCli:      mgr.GetClient(),
+		RestConfig: mgr.GetConfig(),
+		Scheme:     mgr.GetScheme(),
+		Recorder:   SharedEventThrottle.Recorder(mgr.GetEventRecorder("federatedclusteraccess-controller")),
+
+		watched: make(map[schema.GroupVersionKind]struct{}),
+	}
+}
+
+// FederatedClusterAccessReconciler keeps the member clusters of a FederatedClusterAccess object up to date
+type FederatedClusterAccessReconciler struct {
+	log logr.Logger
+
+	inCli      client.Client
+	Scheme     *runtime.Scheme
+	RestConfig *rest.Config
+	Recorder   events.EventRecorder
+
+	// member resources are watched once per target kind, the watches are added on demand
+	controller controller.Controller
+	cache      cache.Cache
+	watchMu    sync.Mutex
+	watched    map[schema.GroupVersionKind]struct{}
+
+	listOptions config.CreateExternalQueryConfigSetOptions
+}
+
+// +kubebuilder:rbac:groups=metrics.openmcp.cloud,resources=federatedclusteraccesses,verbs=get;list;watch
+// +kubebuilder:rbac:groups=metrics.openmcp.cloud,resources=federatedclusteraccesses/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=core.gardener.cloud,resources=shoots,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core.gardener.cloud,resources=shoots/adminkubeconfig,verbs=create
+// +kubebuilder:rbac:groups=cluster.x-k8s.io,resources=clusters,verbs=get;list;watch
+// +kubebuilder:rbac:groups=cluster.open-cluster-management.io,resources=managedclusters,verbs=get;list;watch
+// +kubebuilder:rbac:groups=cluster.karmada.io,resources=clusters,verbs=get;list;watch
+
+// Reconcile refreshes the member clusters of a FederatedClusterAccess and reports clusters joining or leaving
+func (r *FederatedClusterAccessReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	l := r.log.WithValues("namespace", req.Namespace, "name", req.Name)
+
+	access := v1alpha1.FederatedClusterAccess{}
+	if errLoad := r.inCli.Get(ctx, req.NamespacedName, &access); errLoad != nil {
+		if apierrors.IsNotFound(errLoad) {
+			internalmetrics.DeleteCredentialsExpiry(federatedClusterAccessKind, req.Namespace, req.Name)
+			return ctrl.Result{}, nil
+		}
+		l.Error(errLoad, "unable to fetch FederatedClusterAccess")
+		return ctrl.Result{RequeueAfter: RequeueAfterError}, errLoad
+	}
+
+	// Defer status update to ensure it's always called
+	patchStatus := statusPatch(r.inCli, &access)
+	defer func() {
+		if err := patchStatus(ctx); err != nil {
+			l.Error(err, "Failed to update FederatedClusterAccess status")
+		}
+	}()
+
+	memberGVK := config.FederatedMemberGVK(&access)
+	if errWatch := r.watchMembers(memberGVK); errWatch != nil {
+		access.SetConditions(common.ReadyFalse("WatchFailed", errWatch.Error()))
+		l.Error(errWatch, "unable to watch member resources", "target", memberGVK.String())
+		return ctrl.Result{RequeueAfter: RequeueAfterError}, nil
+	}
+
+	list, errList := config.ListFederatedMembers(ctx, &access, r.RestConfig, r.listOptions)
+	if errList != nil {
+		access.SetConditions(common.ReadyFalse("ListMembersFailed", errList.Error()))
+		r.Recorder.Eventf(&access, nil, "Warning", "ListMembersFailed", "ReconcileFederatedClusterAccess", errList.Error())
+		return ctrl.Result{RequeueAfter: RequeueAfterError}, nil
+	}
+
+	clusters := federatedClusters(list)
+	joined, left := diffFederatedClusters(access.Status.Clusters, clusters)
+	for _, cluster := range joined {
+		r.Recorder.Eventf(&access, nil, "Normal", "ClusterJoined", "ReconcileFederatedClusterAccess", "cluster '%s' joined", cluster)
+	}
+	for _, cluster := range left {
+		r.Recorder.Eventf(&access, nil, "Normal", "ClusterLeft", "ReconcileFederatedClusterAccess", "cluster '%s' left", cluster)
+	}
+
+	now := metav1.Now()
+	access.Status.Clusters = clusters
+	access.Status.LastRefreshTime = &now
+	r.checkMemberCredentials(ctx, &access, list, now.Time)
+	access.SetConditions(common.ReadyTrue(fmt.Sprintf("%d member cluster(s) found", len(clusters))))
+
+	return ctrl.Result{RequeueAfter: federatedMemberResync}, nil
+}
+
+// checkMemberCredentials reports the expiry of the credentials of the member clusters.
+// The short-lived credentials requested by the operator for Gardener Shoots and the agents are not checked.
+func (r *FederatedClusterAccessReconciler) checkMemberCredentials(ctx context.Context, access *v1alpha1.FederatedClusterAccess, list *unstructured.UnstructuredList, now time.Time) {
+	if access.Spec.Gardener != nil || access.Spec.Agents != nil {
+		internalmetrics.DeleteCredentialsExpiry(federatedClusterAccessKind, access.Namespace, access.Name)
+		access.Status.CredentialsExpiry = nil
+		meta.RemoveStatusCondition(&access.Status.Conditions, v1alpha1.TypeCredentialsExpiring)
+		return
+	}
+
+	configs, errQC := config.QueryConfigsForMembers(ctx, access, list, r.inCli, r.RestConfig, r.listOptions)
+	if errQC != nil {
+		internalmetrics.DeleteCredentialsExpiry(federatedClusterAccessKind, access.Namespace, access.Name)
+		access.Status.CredentialsExpiry = nil
+		access.SetConditions(credentialsCondition(metav1.ConditionUnknown, ReasonCredentialsInvalid, errQC.Error()))
+		return
+	}
+
+	check := checkCredentials(federatedClusterAccessKind, access, configs, now)
+	access.Status.CredentialsExpiry = check.expiry
+	if check.condition.Status == metav1.ConditionTrue && !meta.IsStatusConditionTrue(access.Status.Conditions, v1alpha1.TypeCredentialsExpiring) {
+		r.Recorder.Eventf(access, nil, "Warning", check.condition.Reason, "ReconcileFederatedClusterAccess", check.condition.Message)
+	}
+	access.SetConditions(check.condition)
+}
+
+// watchMembers starts watching the resources of the given kind, unless they are already watched.
+// Creations, deletions and label changes of member resources trigger a refresh of the matching accesses.
+func (r *FederatedClusterAccessReconciler) watchMembers(gvk schema.GroupVersionKind) error {
+	r.watchMu.Lock()
+	defer r.watchMu.Unlock()
+
+	if _, ok := r.watched[gvk]; ok || r.controller == nil {
+		return nil
+	}
+
+	member := &unstructured.Unstructured{}
+	member.SetGroupVersionKind(gvk)
+	src := source.Kind(r.cache, member,
+		handler.TypedEnqueueRequestsFromMapFunc(r.accessesForMember),
+		predicate.TypedLabelChangedPredicate[*unstructured.Unstructured]{},
+	)
+	if err := r.controller.Watch(src); err != nil {
+		return err
+	}
+	r.watched[gvk] = struct{}{}
+	return nil
+}
+
+// accessesForMember maps a member resource to the FederatedClusterAccess objects whose members are of its kind
+func (r *FederatedClusterAccessReconciler) accessesForMember(ctx context.Context, member *unstructured.Unstructured) []reconcile.Request {
+	var accesses v1alpha1.FederatedClusterAccessList
+	if err := r.inCli.List(ctx, &accesses); err != nil {
+		r.log.Error(err, "unable to list federated cluster accesses for member", "member", member.GetName())
+		return nil
+	}
+
+	gvk := member.GroupVersionKind()
+	var requests []reconcile.Request
+	for _, access := range accesses.Items {
+		if config.FederatedMemberGVK(&access) != gvk {
+			continue
+		}
+		if ns := config.FederatedMemberNamespace(&access); ns != "" && ns != member.GetNamespace() {
+			continue
+		}
+		requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: access.Namespace, Name: access.Name}})
+	}
+	return requests
+}
+
+// federatedClusters returns the sorted member clusters of the listed resources
+func federatedClusters(list *unstructured.UnstructuredList) []v1alpha1.FederatedCluster {
+	clusters := make([]v1alpha1.FederatedCluster, 0, len(list.Items))
+	for _, item := range list.Items {
+		clusters = append(clusters, v1alpha1.FederatedCluster{Name: item.GetName(), Namespace: item.GetNamespace()})
+	}
+	slices.SortFunc(clusters, func(a, b v1alpha1.FederatedCluster) int {
+		if a.Namespace != b.Namespace {
+			return cmp.Compare(a.Namespace, b.Namespace)
+		}
+		return cmp.Compare(a.Name, b.Name)
+	})
+	return clusters
+}
+
+// diffFederatedClusters returns the clusters that joined and left between the previous and the current list
+func diffFederatedClusters(previous, current []v1alpha1.FederatedCluster) (joined, left []v1alpha1.FederatedCluster) {
+	for _, cluster := range current {
+		if !slices.Contains(previous, cluster) {
+			joined = append(joined, cluster)
+		}
+	}
+	for _, cluster := range previous {
+		if !slices.Contains(current, cluster) {
+			left = append(left, cluster)
+		}
+	}
+	return joined, left
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *FederatedClusterAccessReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	c, err := ctrl.NewControllerManagedBy(mgr).
+		Named(FederatedClusterAccessControllerName).
+		WithOptions(Controllers.forController(FederatedClusterAccessControllerName)).
+		// the status updates of the controller itself need no reconcile, the members are refreshed
+		// by the member watch and every federatedMemberResync
+		For(&v1alpha1.FederatedClusterAccess{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Build(r)
+	if err != nil {
+		return err
+	}
+	r.controller = c
+	r.cache = mgr.GetCache()
+	return nil
+}
@@ -0,0 +1,130 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/openmcp-project/metrics-operator/api/v1alpha1"
+)
+
+func memberResource(namespace, name string) unstructured.Unstructured {
+	u := unstructured.Unstructured{}
+	u.SetAPIVersion("core.openmcp.cloud/v1alpha1")
+	u.SetKind("ManagedControlPlane")
+	u.SetNamespace(namespace)
+	u.SetName(name)
+	return u
+}
+
+func TestFederatedClusters(t *testing.T) {
+	list := &unstructured.UnstructuredList{Items: []unstructured.Unstructured{
+		memberResource("team-b", "one"),
+		memberResource("team-a", "two"),
+		memberResource("team-a", "one"),
+	}}
+
+	require.Equal(t, []v1alpha1.FederatedCluster{
+		{Namespace: "team-a", Name: "one"},
+		{Namespace: "team-a", Name: "two"},
+		{Namespace: "team-b", Name: "one"},
+	}, federatedClusters(list))
+}
+
+func TestDiffFederatedClusters(t *testing.T) {
+	a := v1alpha1.FederatedCluster{Namespace: "team-a", Name: "a"}
+	b := v1alpha1.FederatedCluster{Namespace: "team-a", Name: "b"}
+	c := v1alpha1.FederatedCluster{Namespace: "team-b", Name: "c"}
+
+	testCases := []struct {
+		name           string
+		previous       []v1alpha1.FederatedCluster
+		current        []v1alpha1.FederatedCluster
+		expectedJoined []v1alpha1.FederatedCluster
+		expectedLeft   []v1alpha1.FederatedCluster
+	}{
+		{
+			name:           "InitialRefresh",
+			current:        []v1alpha1.FederatedCluster{a, b},
+			expectedJoined: []v1alpha1.FederatedCluster{a, b},
+		},
+		{
+			name:     "Unchanged",
+			previous: []v1alpha1.FederatedCluster{a, b},
+			current:  []v1alpha1.FederatedCluster{a, b},
+		},
+		{
+			name:           "JoinedAndLeft",
+			previous:       []v1alpha1.FederatedCluster{a, b},
+			current:        []v1alpha1.FederatedCluster{b, c},
+			expectedJoined: []v1alpha1.FederatedCluster{c},
+			expectedLeft:   []v1alpha1.FederatedCluster{a},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			joined, left := diffFederatedClusters(tc.previous, tc.current)
+			require.Equal(t, tc.expectedJoined, joined)
+			require.Equal(t, tc.expectedLeft, left)
+		})
+	}
+}
+
+func TestAccessesForMember(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, v1alpha1.AddToScheme(scheme))
+
+	access := func(name, namespace, kind string) *v1alpha1.FederatedClusterAccess {
+		return &v1alpha1.FederatedClusterAccess{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec: v1alpha1.FederatedClusterAccessSpec{
+				Target:    v1alpha1.GroupVersionKind{Group: "core.openmcp.cloud", Version: "v1alpha1", Kind: kind},
+				Namespace: namespace,
+			},
+		}
+	}
+	cli := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		access("all-namespaces", "", "ManagedControlPlane"),
+		access("team-a", "team-a", "ManagedControlPlane"),
+		access("team-b", "team-b", "ManagedControlPlane"),
+		access("other-kind", "", "Workspace"),
+	).Build()
+
+	r := &FederatedClusterAccessReconciler{log: logr.Discard(), inCli: cli}
+	member := memberResource("team-a", "one")
+
+	requests := r.accessesForMember(context.Background(), &member)
+
+	names := make([]types.NamespacedName, 0, len(requests))
+	for _, req := range requests {
+		names = append(names, req.NamespacedName)
+	}
+	require.ElementsMatch(t, []types.NamespacedName{
+		{Namespace: "default", Name: "all-namespaces"},
+		{Namespace: "default", Name: "team-a"},
+	}, names)
+}
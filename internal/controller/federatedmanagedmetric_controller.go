@@ -17,13 +17,11 @@ limitations under the License.
 package controller
 
 import (
+	"cmp"
 	"context"
-	"fmt"
-	"time"
 
 	"github.com/go-logr/logr"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
-	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/rest"
@@ -35,7 +33,6 @@ import (
 	"github.com/openmcp-project/metrics-operator/internal/clientoptl"
 	"github.com/openmcp-project/metrics-operator/internal/common"
 	"github.com/openmcp-project/metrics-operator/internal/config"
-	internalmetrics "github.com/openmcp-project/metrics-operator/internal/metrics"
 	orc "github.com/openmcp-project/metrics-operator/internal/orchestrator"
 )
 
@@ -47,7 +44,7 @@ func NewFederatedManagedMetricReconciler(mgr ctrl.Manager) *FederatedManagedMetr
 		inCli:      mgr.GetClient(),
 		RestConfig: mgr.GetConfig(),
 		Scheme:     mgr.GetScheme(),
-		Recorder:   mgr.GetEventRecorder("federated-managed-controller"),
+		Recorder:   SharedEventThrottle.Recorder(mgr.GetEventRecorder("federated-managed-controller")),
 	}
 }
 
@@ -59,6 +56,10 @@ type FederatedManagedMetricReconciler struct {
 	Scheme     *runtime.Scheme
 	RestConfig *rest.Config
 	Recorder   events.EventRecorder
+	// Exporters creates the exporters of the metric clients, the zero value exports with OTLP
+	Exporters clientoptl.ExporterFactory
+	// Handlers creates the handlers monitoring the metrics, the zero value queries the target clusters
+	Handlers orc.HandlerFactory
 }
 
 func (r *FederatedManagedMetricReconciler) getClient() client.Client {
@@ -69,12 +70,6 @@ func (r *FederatedManagedMetricReconciler) getRestConfig() *rest.Config {
 	return r.RestConfig
 }
 
-// getDataSinkCredentials fetches DataSink configuration and credentials
-func (r *FederatedManagedMetricReconciler) getDataSinkCredentials(ctx context.Context, federatedManagedMetric *v1alpha1.FederatedManagedMetric, l logr.Logger) (*common.DataSinkCredentials, error) {
-	retriever := NewDataSinkCredentialsRetriever(r.getClient(), r.Recorder)
-	return retriever.GetDataSinkCredentials(ctx, federatedManagedMetric.Spec.DataSinkRef, federatedManagedMetric, l)
-}
-
 func (r *FederatedManagedMetricReconciler) handleGetError(err error, log logr.Logger) (ctrl.Result, error) {
 	// We'll ignore not-found errors. They can't be fixed by an immediate requeue.
 	// We'll need to wait for a new notification. We can also get them on delete requests.
@@ -86,36 +81,16 @@ func (r *FederatedManagedMetricReconciler) handleGetError(err error, log logr.Lo
 	return ctrl.Result{RequeueAfter: RequeueAfterError}, err
 }
 
-func (r *FederatedManagedMetricReconciler) scheduleNextReconciliation(metric *v1alpha1.FederatedManagedMetric) ctrl.Result {
-
-	elapsed := time.Since(metric.Status.LastReconcileTime.Time)
-	return ctrl.Result{
-		RequeueAfter: metric.Spec.Interval.Duration - elapsed,
-	}
-}
-
-func (r *FederatedManagedMetricReconciler) shouldReconcile(metric *v1alpha1.FederatedManagedMetric) bool {
-	if metric.Status.LastReconcileTime == nil {
-		return true
-	}
-	elapsed := time.Since(metric.Status.LastReconcileTime.Time)
-	return elapsed >= metric.Spec.Interval.Duration
-}
-
 // Reconcile reads that state of the cluster for a FederatedManagedMetric object
 // +kubebuilder:rbac:groups=metrics.openmcp.cloud,resources=federatedmanagedmetrics,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=metrics.openmcp.cloud,resources=federatedmanagedmetrics/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=metrics.openmcp.cloud,resources=federatedmanagedmetrics/finalizers,verbs=update
 // +kubebuilder:rbac:groups=metrics.openmcp.cloud,resources=datasinks,verbs=get;list;watch
 // +kubebuilder:rbac:groups="",resources=secrets,verbs=get
-//
-//nolint:gocyclo
 func (r *FederatedManagedMetricReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	l := r.log.WithValues("namespace", req.NamespacedName, "name", req.Name)
 
-	l.Info("Reconciling FederatedManagedMetric")
-
-	l.Info(time.Now().String())
+	l.V(1).Info("Reconciling FederatedManagedMetric")
 
 	/*
 			1. Load the generic metric using the client
@@ -126,139 +101,120 @@ func (r *FederatedManagedMetricReconciler) Reconcile(ctx context.Context, req ct
 		return r.handleGetError(errLoad, l)
 	}
 
-	// Defer status update to ensure it's always called
-	defer func() {
-		if err := r.getClient().Status().Update(ctx, &metric); err != nil {
-			l.Error(err, "Failed to update FederatedManagedMetric status")
-		}
-	}()
-
-	// Initialize Ready condition if not present
-	if meta.FindStatusCondition(metric.Status.Conditions, v1alpha1.TypeReady) == nil {
-		metric.SetConditions(common.ReadyUnknown("Reconciling", "Initial reconciliation"))
+	if errRender := renderVariables(&metric, &metric.Spec); errRender != nil {
+		l.Error(errRender, "unable to replace the variables of the FederatedManagedMetric")
+		return ctrl.Result{RequeueAfter: RequeueAfterError}, errRender
 	}
 
-	// Check if enough time has passed since the last reconciliation
-	if !r.shouldReconcile(&metric) {
-		return r.scheduleNextReconciliation(&metric), nil
-	}
-
-	/*
-		1.1 Get the DataSink credentials
-	*/
-	credentials, errCredentials := r.getDataSinkCredentials(ctx, &metric, l)
-	if errCredentials != nil {
-		metric.SetConditions(common.ReadyFalse("DataSinkUnavailable", errCredentials.Error()))
-		metric.Status.Ready = v1alpha1.StatusStringFalse
-		return ctrl.Result{RequeueAfter: RequeueAfterError}, errCredentials
-	}
-	if credentials == nil {
-		l.Info("DataSink not found; metrics will only be available via /metrics endpoint", "metric", metric.Spec.Name)
-	}
-
-	/*
-		1.2 Create QueryConfig to query the resources in the K8S cluster or external cluster based on the kubeconfig secret reference
-	*/
-	queryConfigs, err := config.CreateExternalQueryConfigSet(ctx, metric.Spec.FederatedClusterAccessRef, r.getClient(), r.getRestConfig(), config.CreateExternalQueryConfigSetOptions{})
-	if err != nil {
-		metric.SetConditions(common.ReadyFalse("QueryConfigCreationFailed", err.Error()))
-		metric.Status.Ready = v1alpha1.StatusStringFalse
-		l.Error(err, "unable to create query configs")
-		return ctrl.Result{RequeueAfter: RequeueAfterError}, err
+	// deleted metrics only export a final zero for their series if their data sink asks for it
+	if !metric.DeletionTimestamp.IsZero() {
+		return r.finalize(ctx, &metric, l)
 	}
 
-	metricClient, errCli := clientoptl.NewMetricClient(ctx, credentials)
-	if errCli != nil {
-		metric.SetConditions(common.ReadyFalse("OTLPClientCreationFailed", errCli.Error()))
-		metric.Status.Ready = v1alpha1.StatusStringFalse
-		l.Error(errCli, fmt.Sprintf("federated managed metric '%s' re-queued for execution in %v minutes\n", metric.Spec.Name, RequeueAfterError))
-		return ctrl.Result{RequeueAfter: RequeueAfterError}, errCli
-	}
-
-	defer func() {
-		if err := metricClient.Close(ctx); err != nil {
-			l.Error(err, "Failed to close metric client during federated managed metric reconciliation", "metric", metric.Spec.Name)
-		}
-	}()
-
-	// should this be the group fo the gvr?
-	metricClient.SetMeter("managed")
+	return reconcileCollectedMetric(ctx, r.getClient(), r.Recorder, &federatedManagedMetricCollection{r: r, metric: &metric}, l)
+}
 
-	gaugeMetric, errGauge := metricClient.NewMetric(metric.Spec.Name)
-	if errGauge != nil {
-		metric.SetConditions(common.ReadyFalse("MetricCreationFailed", errGauge.Error()))
-		metric.Status.Ready = v1alpha1.StatusStringFalse
-		l.Error(errGauge, fmt.Sprintf("federated managed metric '%s' re-queued for execution in %v minutes\n", metric.Spec.Name, RequeueAfterError))
-		return ctrl.Result{RequeueAfter: RequeueAfterError}, errGauge
-	}
-	metricName := metric.Spec.Name
-	metricNamespace := metric.Namespace
-	gaugeMetric.SetPrometheusFunc(func(dims map[string]string, value int64) {
-		internalmetrics.RecordDataPoint(metricName, metricNamespace, dims, value)
-	})
+// federatedManagedMetricCollection is the collectedMetric strategy of FederatedManagedMetrics
+type federatedManagedMetricCollection struct {
+	r      *FederatedManagedMetricReconciler
+	metric *v1alpha1.FederatedManagedMetric
+}
 
-	creds := common.DataSinkCredentials{}
-	if credentials != nil {
-		creds = *credentials
+func (m *federatedManagedMetricCollection) describe() metricDescription {
+	metric := m.metric
+	return metricDescription{
+		metric:               metric,
+		kind:                 "federated managed metric",
+		action:               "FederatedManagedMetricReconcile",
+		name:                 metric.Spec.Name,
+		description:          metric.Spec.Description,
+		unit:                 metric.Spec.Unit,
+		meterName:            cmp.Or(metric.Spec.MeterName, "managed"),
+		scopeAttributes:      v1alpha1.ScopeAttributesMap(metric.Spec.ScopeAttributes),
+		dataSinkRef:          metric.Spec.DataSinkRef,
+		exporters:            m.r.Exporters,
+		retryPolicy:          metric.Spec.RetryPolicy,
+		interval:             metric.Spec.Interval,
+		schedule:             metric.Spec.Schedule,
+		lastRun:              lastReconcileTime(metric.Status.LastReconcileTime),
+		ready:                &metric.Status.Ready,
+		conditions:           &metric.Status.Conditions,
+		failures:             &metric.Status.ConsecutiveFailures,
+		staticDimensionsHash: &metric.Status.StaticDimensionsHash,
+		nextRunTime:          &metric.Status.NextRunTime,
 	}
-	for _, queryConfig := range queryConfigs {
-
-		orchestrator, errOrch := orc.NewOrchestrator(creds, queryConfig).WithFederatedManaged(metric, gaugeMetric)
-		if errOrch != nil {
-			metric.SetConditions(common.ReadyFalse("OrchestratorCreationFailed", errOrch.Error()))
-			metric.Status.Ready = v1alpha1.StatusStringFalse
-			l.Error(errOrch, "unable to create federate metric orchestrator monitor")
-			r.Recorder.Eventf(&metric, nil, "Warning", "OrchestratorCreation", "Reconcile", "unable to create orchestrator")
-			return ctrl.Result{RequeueAfter: RequeueAfterError}, errOrch
-		}
-
-		_, errMon := orchestrator.Handler.Monitor(ctx)
+}
 
-		if errMon != nil {
-			metric.SetConditions(common.ReadyFalse("MonitoringFailed", errMon.Error()))
-			metric.Status.Ready = v1alpha1.StatusStringFalse
-			l.Error(errMon, fmt.Sprintf("federated managed metric '%s' re-queued for execution in %v minutes\n", metric.Spec.Name, RequeueAfterError))
-			return ctrl.Result{RequeueAfter: RequeueAfterError}, errMon
-		}
+// queryConfigs returns the configs of the member clusters of the federated cluster access of the metric
+func (m *federatedManagedMetricCollection) queryConfigs(ctx context.Context) ([]orc.QueryConfig, error) {
+	return config.CreateExternalQueryConfigSet(ctx, m.metric.Spec.FederatedClusterAccessRef, m.r.getClient(), m.r.getRestConfig(), config.CreateExternalQueryConfigSetOptions{})
+}
 
+func (m *federatedManagedMetricCollection) collect(ctx context.Context, c *collection) (ctrl.Result, error) {
+	metric := m.metric
+	if _, res, err := c.monitor(ctx, m.r.Handlers, func(o *orc.Orchestrator) (*orc.Orchestrator, error) {
+		return o.WithFederatedManaged(*metric, c.gauge)
+	}); err != nil {
+		return res, err
 	}
+	// every member cluster was collected, a failed collection aborts the reconcile above
+	metric.Status.Observation = v1alpha1.FederatedObservation{ActiveCount: len(c.queryConfigs)}
 
-	errExport := metricClient.ExportMetrics(ctx)
-	if errExport != nil {
-		metric.SetConditions(common.ReadyFalse("MetricExportFailed", errExport.Error()))
-		metric.Status.Ready = v1alpha1.StatusStringFalse
-		l.Error(errExport, fmt.Sprintf("federated managed metric '%s' re-queued for execution in %v minutes\n", metric.Spec.Name, RequeueAfterError))
-	} else {
-		metric.SetConditions(common.ReadyTrue("Federated managed metric reconciled successfully"))
-		metric.Status.Ready = v1alpha1.StatusStringTrue
-	}
+	errExport := exportMetrics(ctx, c.client, &metric.Status.ExportStatus)
+	c.setExported(errExport)
 
-	// Update LastReconcileTime
 	now := metav1.Now()
 	metric.Status.LastReconcileTime = &now
 
-	// Note: Status update is handled by the defer function at the beginning
-
-	/*
-		4. Re-queue the metric after the frequency or 2 minutes if an error occurred
-	*/
-	var requeueTime time.Duration
+	// Requeue the metric at the next run of its schedule, or with the backoff of its retry policy if the export failed
+	nextRun := c.schedule.next(now.Time)
 	if errExport != nil {
-		requeueTime = RequeueAfterError
-	} else {
-		requeueTime = metric.Spec.Interval.Duration
+		nextRun = c.failures.retryAt(metric.Status.NextRunTime)
 	}
+	return c.requeue(nextRun), nil
+}
 
-	l.Info(fmt.Sprintf("federated managed metric '%s' re-queued for execution in %v\n", metric.Spec.Name, requeueTime))
-
-	return ctrl.Result{
-		RequeueAfter: requeueTime,
-	}, nil
+// finalize exports a final zero for the series of the deleted federated managed metric before its finalizer is removed
+func (r *FederatedManagedMetricReconciler) finalize(ctx context.Context, metric *v1alpha1.FederatedManagedMetric, l logr.Logger) (ctrl.Result, error) {
+	return finalizeDeletedMetric(ctx, r.getClient(), r.Recorder, deletedMetric{
+		metric:          metric,
+		exporters:       r.Exporters,
+		name:            metric.Spec.Name,
+		description:     metric.Spec.Description,
+		unit:            metric.Spec.Unit,
+		meterName:       cmp.Or(metric.Spec.MeterName, "managed"),
+		scopeAttributes: v1alpha1.ScopeAttributesMap(metric.Spec.ScopeAttributes),
+		dataSinkRef:     metric.Spec.DataSinkRef,
+		collect: func(ctx context.Context, credentials common.DataSinkCredentials, gauge *clientoptl.Metric) error {
+			queryConfigs, err := config.CreateExternalQueryConfigSet(ctx, metric.Spec.FederatedClusterAccessRef, r.getClient(), r.getRestConfig(), config.CreateExternalQueryConfigSetOptions{})
+			if err != nil {
+				return err
+			}
+			for _, queryConfig := range queryConfigs {
+				orchestrator, err := orc.NewOrchestrator(credentials, queryConfig).WithHandlers(r.Handlers).WithFederatedManaged(*metric, gauge)
+				if err != nil {
+					return err
+				}
+				if _, err := orchestrator.Handler.Monitor(ctx); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}, l)
 }
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *FederatedManagedMetricReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
-		For(&v1alpha1.FederatedManagedMetric{}).
-		Complete(r)
+	b := ctrl.NewControllerManagedBy(mgr).
+		Named(FederatedManagedMetricControllerName).
+		WithOptions(Controllers.forController(FederatedManagedMetricControllerName)).
+		// metrics are enqueued with their priority, so important metrics are reconciled first
+		Watches(&v1alpha1.FederatedManagedMetric{}, enqueueSelf())
+
+	b, err := watchStaticDimensionSources(mgr, b, &v1alpha1.FederatedManagedMetric{}, func() client.ObjectList { return &v1alpha1.FederatedManagedMetricList{} })
+	if err != nil {
+		return err
+	}
+	return b.Complete(r)
 }
@@ -0,0 +1,342 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/go-logr/logr"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/events"
+	"k8s.io/client-go/util/retry"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/openmcp-project/metrics-operator/api/v1alpha1"
+	"github.com/openmcp-project/metrics-operator/internal/clientoptl"
+	"github.com/openmcp-project/metrics-operator/internal/common"
+	"github.com/openmcp-project/metrics-operator/internal/config"
+	orc "github.com/openmcp-project/metrics-operator/internal/orchestrator"
+)
+
+const (
+	// ClusterAgentHeartbeatInterval is the interval in which an agent reports its heartbeat to the hub
+	ClusterAgentHeartbeatInterval = time.Minute
+	// ClusterAgentHeartbeatTimeout is the age of the heartbeat after which the hub considers the results of an agent pending
+	ClusterAgentHeartbeatTimeout = 5 * time.Minute
+)
+
+// NewFederatedMetricAgentReconciler creates a reconciler that collects the FederatedMetrics of the hub
+// in the member cluster the agent runs in. The manager is the one of the hub.
+func NewFederatedMetricAgentReconciler(mgr ctrl.Manager, localConfig *rest.Config, agent types.NamespacedName) *FederatedMetricAgentReconciler {
+	return &FederatedMetricAgentReconciler{
+		log: mgr.GetLogger().WithName("controllers").WithName("FederatedMetricAgent"),
+
+		hubCli:      mgr.GetClient(),
+		Recorder:    SharedEventThrottle.Recorder(mgr.GetEventRecorder("federated-agent")),
+		LocalConfig: localConfig,
+		Agent:       agent,
+	}
+}
+
+// FederatedMetricAgentReconciler collects the FederatedMetrics whose FederatedClusterAccess selects the ClusterAgent of the agent,
+// exports them to their DataSinks and reports the results in the status of the ClusterAgent
+type FederatedMetricAgentReconciler struct {
+	log logr.Logger
+
+	hubCli   client.Client
+	Recorder events.EventRecorder
+	// LocalConfig is the rest config of the member cluster the agent runs in
+	LocalConfig *rest.Config
+	// Agent is the ClusterAgent of the agent on the hub
+	Agent types.NamespacedName
+	// Exporters creates the exporters of the metric clients, the zero value exports with OTLP
+	Exporters clientoptl.ExporterFactory
+	// Handlers creates the handlers monitoring the metrics, the zero value queries the target clusters
+	Handlers orc.HandlerFactory
+}
+
+// Reconcile collects a FederatedMetric in the member cluster if it is due
+func (r *FederatedMetricAgentReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	l := r.log.WithValues("namespace", req.Namespace, "name", req.Name)
+
+	metric := v1alpha1.FederatedMetric{}
+	if err := r.hubCli.Get(ctx, req.NamespacedName, &metric); err != nil {
+		if apierrors.IsNotFound(err) {
+			return ctrl.Result{}, r.setResult(ctx, req.NamespacedName, nil)
+		}
+		return ctrl.Result{RequeueAfter: RequeueAfterError}, err
+	}
+	if !metric.DeletionTimestamp.IsZero() {
+		return ctrl.Result{}, r.setResult(ctx, req.NamespacedName, nil)
+	}
+
+	if errRender := renderVariables(&metric, &metric.Spec); errRender != nil {
+		l.Error(errRender, "unable to replace the variables of the FederatedMetric")
+		return ctrl.Result{RequeueAfter: RequeueAfterError}, errRender
+	}
+
+	agent := v1alpha1.ClusterAgent{}
+	if err := r.hubCli.Get(ctx, r.Agent, &agent); err != nil {
+		l.Error(err, "unable to fetch the ClusterAgent of the agent", "agent", r.Agent.String())
+		return ctrl.Result{RequeueAfter: RequeueAfterError}, err
+	}
+	access, selected, err := r.selectingAccess(ctx, &metric, &agent)
+	if err != nil {
+		return ctrl.Result{RequeueAfter: RequeueAfterError}, err
+	}
+	if !selected {
+		// the metric is not, or no longer, collected by this agent
+		return ctrl.Result{}, r.setResult(ctx, req.NamespacedName, nil)
+	}
+
+	schedule, err := newExportSchedule(metric.Spec.Interval, metric.Spec.Schedule, &metric)
+	if err != nil {
+		return ctrl.Result{}, r.setResult(ctx, req.NamespacedName, &v1alpha1.ClusterAgentMetric{Ready: v1alpha1.StatusStringFalse, Message: err.Error()})
+	}
+	var lastExport time.Time
+	if previous := agent.Status.FindMetric(metric.Namespace, metric.Name); previous != nil && previous.LastExportTime != nil {
+		lastExport = previous.LastExportTime.Time
+	}
+	if !schedule.due(lastExport) {
+		return ctrl.Result{RequeueAfter: max(time.Until(schedule.next(lastExport)), time.Second)}, nil
+	}
+
+	result := v1alpha1.ClusterAgentMetric{Ready: v1alpha1.StatusStringTrue, Message: "Federated metric exported successfully"}
+	if errCollect := r.collect(ctx, &metric, access, &agent); errCollect != nil {
+		l.Error(errCollect, "unable to collect federated metric", "metric", metric.Spec.Name)
+		result = v1alpha1.ClusterAgentMetric{Ready: v1alpha1.StatusStringFalse, Message: errCollect.Error()}
+	}
+	now := metav1.Now()
+	result.LastExportTime = &now
+	if err := r.setResult(ctx, req.NamespacedName, &result); err != nil {
+		return ctrl.Result{RequeueAfter: RequeueAfterError}, err
+	}
+
+	if result.Ready != v1alpha1.StatusStringTrue {
+		return ctrl.Result{RequeueAfter: RequeueAfterError}, nil
+	}
+	return ctrl.Result{RequeueAfter: max(time.Until(schedule.next(now.Time)), time.Second)}, nil
+}
+
+// selectingAccess returns the FederatedClusterAccess of the metric, and whether it is in agents mode and selects the agent
+func (r *FederatedMetricAgentReconciler) selectingAccess(ctx context.Context, metric *v1alpha1.FederatedMetric, agent *v1alpha1.ClusterAgent) (*v1alpha1.FederatedClusterAccess, bool, error) {
+	access := &v1alpha1.FederatedClusterAccess{}
+	key := types.NamespacedName{Namespace: metric.Spec.FederatedClusterAccessRef.Namespace, Name: metric.Spec.FederatedClusterAccessRef.Name}
+	if err := r.hubCli.Get(ctx, key, access); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+	if access.Spec.Agents == nil || config.FederatedMemberNamespace(access) != agent.Namespace {
+		return access, false, nil
+	}
+	selector, err := config.ClusterAgentSelector(access)
+	if err != nil {
+		return access, false, nil
+	}
+	return access, selector.Matches(labels.Set(agent.Labels)), nil
+}
+
+// collect collects the federated metric in the member cluster and exports it to its data sink
+func (r *FederatedMetricAgentReconciler) collect(ctx context.Context, metric *v1alpha1.FederatedMetric, access *v1alpha1.FederatedClusterAccess, agent *v1alpha1.ClusterAgent) error {
+	dimensions, err := resolveStaticDimensions(ctx, r.hubCli, metric)
+	if err != nil {
+		return err
+	}
+	credentials, err := NewDataSinkCredentialsRetriever(r.hubCli, r.Recorder).GetDataSinkCredentials(ctx, metric.Spec.DataSinkRef, metric, r.log)
+	if err != nil {
+		return err
+	}
+	queryConfig, err := config.CreateAgentQueryConfig(access, agent, r.LocalConfig)
+	if err != nil {
+		return err
+	}
+
+	metricClient, err := r.Exporters.NewMetricClient(ctx, credentials)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := metricClient.Close(ctx); err != nil {
+			r.log.Error(err, "Failed to close metric client", "metric", metric.Spec.Name)
+		}
+	}()
+	metricClient.SetMeter(cmp.Or(metric.Spec.MeterName, "federated"), v1alpha1.ScopeAttributesMap(metric.Spec.ScopeAttributes))
+	gaugeMetric, err := metricClient.NewMetric(metric.Spec.Name, metric.Spec.Description, metric.Spec.Unit)
+	if err != nil {
+		return err
+	}
+	gaugeMetric.SetStaticDimensions(dimensions)
+
+	creds := common.DataSinkCredentials{}
+	if credentials != nil {
+		creds = *credentials
+	}
+	orchestrator, err := orc.NewOrchestrator(creds, *queryConfig).WithHandlers(r.Handlers).WithFederated(*metric, gaugeMetric)
+	if err != nil {
+		return err
+	}
+	if _, err := orchestrator.Handler.Monitor(ctx); err != nil {
+		return err
+	}
+	return metricClient.ExportMetrics(ctx)
+}
+
+// setResult sets the result of a federated metric in the status of the ClusterAgent, or removes it if the result is nil,
+// along with the heartbeat of the agent.
+// The results of all metrics of the agent are a single list, so it is updated with the resource version it was read at.
+func (r *FederatedMetricAgentReconciler) setResult(ctx context.Context, metric types.NamespacedName, result *v1alpha1.ClusterAgentMetric) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		agent := v1alpha1.ClusterAgent{}
+		if err := r.hubCli.Get(ctx, r.Agent, &agent); err != nil {
+			return client.IgnoreNotFound(err)
+		}
+		agent.Status.Metrics = slices.DeleteFunc(agent.Status.Metrics, func(m v1alpha1.ClusterAgentMetric) bool {
+			return m.Namespace == metric.Namespace && m.Name == metric.Name
+		})
+		if result != nil {
+			result.Namespace, result.Name = metric.Namespace, metric.Name
+			agent.Status.Metrics = append(agent.Status.Metrics, *result)
+			slices.SortFunc(agent.Status.Metrics, func(a, b v1alpha1.ClusterAgentMetric) int {
+				return cmp.Or(cmp.Compare(a.Namespace, b.Namespace), cmp.Compare(a.Name, b.Name))
+			})
+		}
+		now := metav1.Now()
+		agent.Status.LastHeartbeatTime = &now
+		return r.hubCli.Status().Update(ctx, &agent)
+	})
+}
+
+// SetupWithManager sets up the controller with the Manager of the hub.
+// FederatedMetrics are also reconciled when their FederatedClusterAccess or the labels of the ClusterAgent change,
+// as either decides whether the agent collects them.
+func (r *FederatedMetricAgentReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &v1alpha1.FederatedMetric{}, federatedMetricAccessIndex, federatedMetricAccess); err != nil {
+		return err
+	}
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(FederatedMetricAgentControllerName).
+		WithOptions(Controllers.forController(FederatedMetricAgentControllerName)).
+		For(&v1alpha1.FederatedMetric{}).
+		Watches(&v1alpha1.FederatedClusterAccess{}, handler.EnqueueRequestsFromMapFunc(r.metricsForAccess),
+			builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		// the status of the ClusterAgent is updated with every result, only its labels are matched by the selectors
+		Watches(&v1alpha1.ClusterAgent{}, handler.EnqueueRequestsFromMapFunc(r.metricsForAgent),
+			builder.WithPredicates(predicate.LabelChangedPredicate{})).
+		Complete(r)
+}
+
+// federatedMetricAccessIndex indexes FederatedMetrics by the namespace and name of their FederatedClusterAccess
+const federatedMetricAccessIndex = "spec.federatedClusterAccessRef"
+
+func federatedMetricAccess(obj client.Object) []string {
+	ref := obj.(*v1alpha1.FederatedMetric).Spec.FederatedClusterAccessRef
+	return []string{ref.Namespace + "/" + ref.Name}
+}
+
+// metricsForAccess maps a FederatedClusterAccess to the FederatedMetrics referencing it
+func (r *FederatedMetricAgentReconciler) metricsForAccess(ctx context.Context, obj client.Object) []reconcile.Request {
+	return r.federatedMetrics(ctx, client.MatchingFields{federatedMetricAccessIndex: obj.GetNamespace() + "/" + obj.GetName()})
+}
+
+// metricsForAgent maps the ClusterAgent of the agent to all FederatedMetrics, any of them may be selected by its labels
+func (r *FederatedMetricAgentReconciler) metricsForAgent(ctx context.Context, obj client.Object) []reconcile.Request {
+	if obj.GetNamespace() != r.Agent.Namespace || obj.GetName() != r.Agent.Name {
+		return nil
+	}
+	return r.federatedMetrics(ctx)
+}
+
+func (r *FederatedMetricAgentReconciler) federatedMetrics(ctx context.Context, opts ...client.ListOption) []reconcile.Request {
+	metrics := v1alpha1.FederatedMetricList{}
+	if err := r.hubCli.List(ctx, &metrics, opts...); err != nil {
+		r.log.Error(err, "unable to list federated metrics")
+		return nil
+	}
+	requests := make([]reconcile.Request, 0, len(metrics.Items))
+	for _, metric := range metrics.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&metric)})
+	}
+	return requests
+}
+
+// RegisterClusterAgent creates or updates the ClusterAgent of an agent on the hub
+func RegisterClusterAgent(ctx context.Context, hubCli client.Client, key types.NamespacedName, clusterName string, agentLabels map[string]string) error {
+	agent := &v1alpha1.ClusterAgent{ObjectMeta: metav1.ObjectMeta{Namespace: key.Namespace, Name: key.Name}}
+	_, err := controllerutil.CreateOrUpdate(ctx, hubCli, agent, func() error {
+		if agent.Labels == nil {
+			agent.Labels = map[string]string{}
+		}
+		for k, v := range agentLabels {
+			agent.Labels[k] = v
+		}
+		agent.Spec.ClusterName = clusterName
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to register cluster agent %s: %w", key, err)
+	}
+	return nil
+}
+
+// ClusterAgentHeartbeat reports the heartbeat of an agent in the status of its ClusterAgent, so the hub notices agents that stopped
+type ClusterAgentHeartbeat struct {
+	Client   client.Client
+	Agent    types.NamespacedName
+	Interval time.Duration
+	Log      logr.Logger
+}
+
+// Start reports the heartbeat in the interval until the context is done
+func (h *ClusterAgentHeartbeat) Start(ctx context.Context) error {
+	ticker := time.NewTicker(h.Interval)
+	defer ticker.Stop()
+	for {
+		if err := h.beat(ctx); err != nil {
+			h.Log.Error(err, "unable to report the heartbeat of the cluster agent", "agent", h.Agent.String())
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// beat patches the heartbeat only, so it doesn't conflict with the results the agent sets in the status
+func (h *ClusterAgentHeartbeat) beat(ctx context.Context) error {
+	agent := &v1alpha1.ClusterAgent{ObjectMeta: metav1.ObjectMeta{Namespace: h.Agent.Namespace, Name: h.Agent.Name}}
+	patchStatus := statusPatch(h.Client, agent)
+	now := metav1.Now()
+	agent.Status.LastHeartbeatTime = &now
+	return patchStatus(ctx)
+}
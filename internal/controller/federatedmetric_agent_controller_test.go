@@ -0,0 +1,231 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/events"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/openmcp-project/metrics-operator/api/v1alpha1"
+	"github.com/openmcp-project/metrics-operator/internal/clientoptl"
+	orc "github.com/openmcp-project/metrics-operator/internal/orchestrator"
+)
+
+func agentScheme(t *testing.T) *runtime.Scheme {
+	scheme := runtime.NewScheme()
+	require.NoError(t, v1alpha1.AddToScheme(scheme))
+	return scheme
+}
+
+func clusterAgent(name string, labels map[string]string, heartbeat time.Time, metrics ...v1alpha1.ClusterAgentMetric) *v1alpha1.ClusterAgent {
+	agent := &v1alpha1.ClusterAgent{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "agents", Name: name, Labels: labels},
+		Spec:       v1alpha1.ClusterAgentSpec{ClusterName: name},
+		Status:     v1alpha1.ClusterAgentStatus{Metrics: metrics},
+	}
+	if !heartbeat.IsZero() {
+		agent.Status.LastHeartbeatTime = &metav1.Time{Time: heartbeat}
+	}
+	return agent
+}
+
+func agentsAccess(namespace string, matchLabels map[string]string) *v1alpha1.FederatedClusterAccess {
+	return &v1alpha1.FederatedClusterAccess{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "agents", Name: "fleet"},
+		Spec: v1alpha1.FederatedClusterAccessSpec{
+			Namespace: namespace,
+			Agents:    &v1alpha1.ClusterAgents{Selector: &metav1.LabelSelector{MatchLabels: matchLabels}},
+		},
+	}
+}
+
+func TestSelectingAccess(t *testing.T) {
+	metric := &v1alpha1.FederatedMetric{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "pods"},
+		Spec:       v1alpha1.FederatedMetricSpec{FederatedClusterAccessRef: v1alpha1.FederateClusterAccessRef{Namespace: "agents", Name: "fleet"}},
+	}
+	agent := clusterAgent("cluster1", map[string]string{"env": "prod"}, time.Time{})
+	secretRefs := agentsAccess("", nil)
+	secretRefs.Spec.Agents = nil
+	secretRefs.Spec.SecretRefPath = "kubeconfigs"
+
+	testCases := []struct {
+		name     string
+		access   *v1alpha1.FederatedClusterAccess
+		selected bool
+	}{
+		{name: "AccessNotFound"},
+		{name: "Selected", access: agentsAccess("", map[string]string{"env": "prod"}), selected: true},
+		{name: "NotMatchingSelector", access: agentsAccess("", map[string]string{"env": "dev"})},
+		{name: "OtherNamespace", access: agentsAccess("other", map[string]string{"env": "prod"})},
+		{name: "NotAgentsMode", access: secretRefs},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			builder := fake.NewClientBuilder().WithScheme(agentScheme(t))
+			if tc.access != nil {
+				builder = builder.WithObjects(tc.access)
+			}
+			r := &FederatedMetricAgentReconciler{log: logr.Discard(), hubCli: builder.Build()}
+
+			_, selected, err := r.selectingAccess(context.Background(), metric, agent)
+			require.NoError(t, err)
+			require.Equal(t, tc.selected, selected)
+		})
+	}
+}
+
+func TestFederatedMetricAgent_selectorChanged(t *testing.T) {
+	withScheduling(t, SchedulingOptions{})
+	key := types.NamespacedName{Namespace: "agents", Name: "cluster1"}
+	agent := clusterAgent("cluster1", map[string]string{"env": "prod"}, time.Time{})
+	access := agentsAccess("", map[string]string{"env": "dev"})
+	metric := &v1alpha1.FederatedMetric{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "pods"},
+		Spec: v1alpha1.FederatedMetricSpec{
+			Name:                      "pods",
+			FederatedClusterAccessRef: v1alpha1.FederateClusterAccessRef{Namespace: "agents", Name: "fleet"},
+			Interval:                  metav1.Duration{Duration: time.Minute},
+		},
+	}
+	cli := fake.NewClientBuilder().WithScheme(agentScheme(t)).
+		WithObjects(agent, access, metric).
+		WithStatusSubresource(agent).
+		WithIndex(&v1alpha1.FederatedMetric{}, federatedMetricAccessIndex, federatedMetricAccess).
+		Build()
+	r := &FederatedMetricAgentReconciler{
+		log:         logr.Discard(),
+		hubCli:      cli,
+		Recorder:    events.NewFakeRecorder(10),
+		LocalConfig: &rest.Config{Host: "https://cluster.example.com"},
+		Agent:       key,
+		Exporters:   clientoptl.NewFakeExporter().Factory(),
+		Handlers:    orc.NewFakeHandler(orc.MonitorResult{Phase: v1alpha1.PhaseActive}).Factory(),
+	}
+	ctx := context.Background()
+	req := ctrl.Request{NamespacedName: client.ObjectKeyFromObject(metric)}
+
+	_, err := r.Reconcile(ctx, req)
+	require.NoError(t, err)
+	require.NoError(t, cli.Get(ctx, key, agent))
+	require.Empty(t, agent.Status.Metrics, "the metric is not selected by the agent")
+
+	access.Spec.Agents.Selector.MatchLabels = map[string]string{"env": "prod"}
+	require.NoError(t, cli.Update(ctx, access))
+	require.Equal(t, []reconcile.Request{req}, r.metricsForAccess(ctx, access))
+	other := agentsAccess("", nil)
+	other.Name = "other"
+	require.Empty(t, r.metricsForAccess(ctx, other), "no metric references the other access")
+	require.Equal(t, []reconcile.Request{req}, r.metricsForAgent(ctx, agent))
+	require.Empty(t, r.metricsForAgent(ctx, clusterAgent("cluster2", nil, time.Time{})), "another agent")
+
+	_, err = r.Reconcile(ctx, req)
+	require.NoError(t, err)
+	require.NoError(t, cli.Get(ctx, key, agent))
+	require.Len(t, agent.Status.Metrics, 1, "the metric is collected once the selector matches the agent")
+	require.Equal(t, "pods", agent.Status.Metrics[0].Name)
+	require.Equal(t, v1alpha1.StatusStringTrue, agent.Status.Metrics[0].Ready, agent.Status.Metrics[0].Message)
+	require.NotNil(t, agent.Status.Metrics[0].LastExportTime)
+}
+
+func TestSetAgentResult(t *testing.T) {
+	key := types.NamespacedName{Namespace: "agents", Name: "cluster1"}
+	agent := clusterAgent("cluster1", nil, time.Time{},
+		v1alpha1.ClusterAgentMetric{Namespace: "default", Name: "pods", Ready: v1alpha1.StatusStringFalse},
+	)
+	cli := fake.NewClientBuilder().WithScheme(agentScheme(t)).WithObjects(agent).WithStatusSubresource(agent).Build()
+	r := &FederatedMetricAgentReconciler{log: logr.Discard(), hubCli: cli, Agent: key}
+	ctx := context.Background()
+
+	require.NoError(t, r.setResult(ctx, types.NamespacedName{Namespace: "default", Name: "pods"}, &v1alpha1.ClusterAgentMetric{Ready: v1alpha1.StatusStringTrue}))
+	require.NoError(t, r.setResult(ctx, types.NamespacedName{Namespace: "default", Name: "deployments"}, &v1alpha1.ClusterAgentMetric{Ready: v1alpha1.StatusStringTrue}))
+
+	updated := v1alpha1.ClusterAgent{}
+	require.NoError(t, cli.Get(ctx, key, &updated))
+	require.NotNil(t, updated.Status.LastHeartbeatTime)
+	require.Equal(t, []v1alpha1.ClusterAgentMetric{
+		{Namespace: "default", Name: "deployments", Ready: v1alpha1.StatusStringTrue},
+		{Namespace: "default", Name: "pods", Ready: v1alpha1.StatusStringTrue},
+	}, updated.Status.Metrics, "the results are replaced and sorted")
+
+	require.NoError(t, r.setResult(ctx, types.NamespacedName{Namespace: "default", Name: "pods"}, nil))
+	require.NoError(t, cli.Get(ctx, key, &updated))
+	require.Len(t, updated.Status.Metrics, 1)
+	require.Equal(t, "deployments", updated.Status.Metrics[0].Name)
+}
+
+func TestRegisterClusterAgent(t *testing.T) {
+	key := types.NamespacedName{Namespace: "agents", Name: "cluster1"}
+	cli := fake.NewClientBuilder().WithScheme(agentScheme(t)).Build()
+	ctx := context.Background()
+
+	require.NoError(t, RegisterClusterAgent(ctx, cli, key, "cluster1", map[string]string{"env": "prod"}))
+	require.NoError(t, RegisterClusterAgent(ctx, cli, key, "cluster-one", map[string]string{"region": "eu"}))
+
+	agent := v1alpha1.ClusterAgent{}
+	require.NoError(t, cli.Get(ctx, key, &agent))
+	require.Equal(t, "cluster-one", agent.Spec.ClusterName)
+	require.Equal(t, map[string]string{"env": "prod", "region": "eu"}, agent.Labels)
+}
+
+func TestAggregateAgentResults(t *testing.T) {
+	now := time.Now()
+	prod := map[string]string{"env": "prod"}
+	result := func(ready, message string) v1alpha1.ClusterAgentMetric {
+		return v1alpha1.ClusterAgentMetric{Namespace: "default", Name: "pods", Ready: ready, Message: message}
+	}
+	cli := fake.NewClientBuilder().WithScheme(agentScheme(t)).WithObjects(
+		clusterAgent("active", prod, now, result(v1alpha1.StatusStringTrue, "")),
+		clusterAgent("failed", prod, now, result(v1alpha1.StatusStringFalse, "forbidden")),
+		clusterAgent("not-reported", prod, now),
+		clusterAgent("stale", prod, now.Add(-2*ClusterAgentHeartbeatTimeout), result(v1alpha1.StatusStringTrue, "")),
+		clusterAgent("not-selected", map[string]string{"env": "dev"}, now, result(v1alpha1.StatusStringTrue, "")),
+	).Build()
+	r := &FederatedMetricReconciler{log: logr.Discard(), inCli: cli}
+
+	metric := &v1alpha1.FederatedMetric{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "pods"},
+		Spec:       v1alpha1.FederatedMetricSpec{Interval: metav1.Duration{Duration: time.Minute}},
+	}
+	schedule, err := newExportSchedule(metric.Spec.Interval, "", metric)
+	require.NoError(t, err)
+
+	_, err = r.aggregateAgentResults(context.Background(), metric, agentsAccess("", prod), schedule)
+	require.NoError(t, err)
+	require.Equal(t, v1alpha1.FederatedObservation{ActiveCount: 1, FailedCount: 1, PendingCount: 2}, metric.Status.Observation)
+	require.Equal(t, v1alpha1.StatusStringFalse, metric.Status.Ready)
+
+	ready := metric.Status.Conditions
+	require.Len(t, ready, 1)
+	require.Equal(t, "AgentCollectionFailed", ready[0].Reason)
+	require.Equal(t, "failed: forbidden", ready[0].Message)
+	require.NotNil(t, metric.Status.LastReconcileTime)
+}
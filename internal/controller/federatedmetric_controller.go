@@ -17,15 +17,17 @@ limitations under the License.
 package controller
 
 import (
+	"cmp"
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/go-logr/logr"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
-	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/events"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -35,7 +37,6 @@ import (
 	"github.com/openmcp-project/metrics-operator/internal/clientoptl"
 	"github.com/openmcp-project/metrics-operator/internal/common"
 	"github.com/openmcp-project/metrics-operator/internal/config"
-	internalmetrics "github.com/openmcp-project/metrics-operator/internal/metrics"
 	orc "github.com/openmcp-project/metrics-operator/internal/orchestrator"
 )
 
@@ -47,7 +48,7 @@ func NewFederatedMetricReconciler(mgr ctrl.Manager) *FederatedMetricReconciler {
 		inCli:      mgr.GetClient(),
 		RestConfig: mgr.GetConfig(),
 		Scheme:     mgr.GetScheme(),
-		Recorder:   mgr.GetEventRecorder("federated-controller"),
+		Recorder:   SharedEventThrottle.Recorder(mgr.GetEventRecorder("federated-controller")),
 	}
 }
 
@@ -59,6 +60,10 @@ type FederatedMetricReconciler struct {
 	Scheme     *runtime.Scheme
 	RestConfig *rest.Config
 	Recorder   events.EventRecorder
+	// Exporters creates the exporters of the metric clients, the zero value exports with OTLP
+	Exporters clientoptl.ExporterFactory
+	// Handlers creates the handlers monitoring the metrics, the zero value queries the target clusters
+	Handlers orc.HandlerFactory
 }
 
 func (r *FederatedMetricReconciler) getClient() client.Client {
@@ -69,12 +74,6 @@ func (r *FederatedMetricReconciler) getRestConfig() *rest.Config {
 	return r.RestConfig
 }
 
-// getDataSinkCredentials fetches DataSink configuration and credentials
-func (r *FederatedMetricReconciler) getDataSinkCredentials(ctx context.Context, federatedMetric *v1alpha1.FederatedMetric, l logr.Logger) (*common.DataSinkCredentials, error) {
-	retriever := NewDataSinkCredentialsRetriever(r.getClient(), r.Recorder)
-	return retriever.GetDataSinkCredentials(ctx, federatedMetric.Spec.DataSinkRef, federatedMetric, l)
-}
-
 func handleGetError(err error, log logr.Logger) (ctrl.Result, error) {
 	// we'll ignore not-found errors, since they can't be fixed by an immediate
 	// requeue (we'll need to wait for a new notification), and we can also get them
@@ -87,37 +86,26 @@ func handleGetError(err error, log logr.Logger) (ctrl.Result, error) {
 	return ctrl.Result{RequeueAfter: RequeueAfterError}, err
 }
 
-func scheduleNextReconciliation(metric *v1alpha1.FederatedMetric) ctrl.Result {
-
-	elapsed := time.Since(metric.Status.LastReconcileTime.Time)
-	return ctrl.Result{
-		RequeueAfter: metric.Spec.Interval.Duration - elapsed,
+// lastReconcileTime returns the time of the last reconciliation, or the zero time if there was none
+func lastReconcileTime(t *metav1.Time) time.Time {
+	if t == nil {
+		return time.Time{}
 	}
-}
-
-func shouldReconcile(metric *v1alpha1.FederatedMetric) bool {
-	if metric.Status.LastReconcileTime == nil {
-		return true
-	}
-	elapsed := time.Since(metric.Status.LastReconcileTime.Time)
-	return elapsed >= metric.Spec.Interval.Duration
+	return t.Time
 }
 
 // +kubebuilder:rbac:groups=metrics.openmcp.cloud,resources=federatedmetrics,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=metrics.openmcp.cloud,resources=federatedmetrics/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=metrics.openmcp.cloud,resources=federatedmetrics/finalizers,verbs=update
 // +kubebuilder:rbac:groups=metrics.openmcp.cloud,resources=datasinks,verbs=get;list;watch
+// +kubebuilder:rbac:groups=metrics.openmcp.cloud,resources=clusteragents,verbs=get;list;watch
 // +kubebuilder:rbac:groups="",resources=secrets,verbs=get
 
 // Reconcile handles the reconciliation of the FederatedMetric object
-//
-//nolint:gocyclo
 func (r *FederatedMetricReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	l := r.log.WithValues("namespace", req.NamespacedName, "name", req.Name)
 
-	l.Info("Reconciling FederatedMetric")
-
-	l.Info(time.Now().String())
+	l.V(1).Info("Reconciling FederatedMetric")
 
 	/*
 			1. Load the generic metric using the client
@@ -128,139 +116,177 @@ func (r *FederatedMetricReconciler) Reconcile(ctx context.Context, req ctrl.Requ
 		return handleGetError(errLoad, l)
 	}
 
-	// Defer status update to ensure it's always called
-	defer func() {
-		if err := r.getClient().Status().Update(ctx, &metric); err != nil {
-			l.Error(err, "Failed to update FederatedMetric status")
-		}
-	}()
-
-	// Initialize Ready condition if not present
-	if meta.FindStatusCondition(metric.Status.Conditions, v1alpha1.TypeReady) == nil {
-		metric.SetConditions(common.ReadyUnknown("Reconciling", "Initial reconciliation"))
+	if errRender := renderVariables(&metric, &metric.Spec); errRender != nil {
+		l.Error(errRender, "unable to replace the variables of the FederatedMetric")
+		return ctrl.Result{RequeueAfter: RequeueAfterError}, errRender
 	}
 
-	// Check if enough time has passed since the last reconciliation
-	if !shouldReconcile(&metric) {
-		return scheduleNextReconciliation(&metric), nil
+	// deleted metrics only export a final zero for their series if their data sink asks for it
+	if !metric.DeletionTimestamp.IsZero() {
+		return r.finalize(ctx, &metric, l)
 	}
 
-	/*
-		1.1 Get DataSink configuration and credentials
-	*/
-	credentials, err := r.getDataSinkCredentials(ctx, &metric, l)
-	if err != nil {
-		metric.SetConditions(common.ReadyFalse("DataSinkUnavailable", err.Error()))
-		metric.Status.Ready = v1alpha1.StatusStringFalse
-		return ctrl.Result{RequeueAfter: RequeueAfterError}, err
-	}
-	if credentials == nil {
-		l.Info("DataSink not found; metrics will only be available via /metrics endpoint", "metric", metric.Spec.Name)
+	return reconcileCollectedMetric(ctx, r.getClient(), r.Recorder, &federatedMetricCollection{r: r, metric: &metric}, l)
+}
+
+// federatedMetricCollection is the collectedMetric strategy of FederatedMetrics
+type federatedMetricCollection struct {
+	r      *FederatedMetricReconciler
+	metric *v1alpha1.FederatedMetric
+}
+
+func (m *federatedMetricCollection) describe() metricDescription {
+	metric := m.metric
+	return metricDescription{
+		metric:               metric,
+		kind:                 "federated metric",
+		action:               "FederatedMetricReconcile",
+		name:                 metric.Spec.Name,
+		description:          metric.Spec.Description,
+		unit:                 metric.Spec.Unit,
+		meterName:            cmp.Or(metric.Spec.MeterName, "federated"),
+		scopeAttributes:      v1alpha1.ScopeAttributesMap(metric.Spec.ScopeAttributes),
+		dataSinkRef:          metric.Spec.DataSinkRef,
+		exporters:            m.r.Exporters,
+		retryPolicy:          metric.Spec.RetryPolicy,
+		interval:             metric.Spec.Interval,
+		schedule:             metric.Spec.Schedule,
+		lastRun:              lastReconcileTime(metric.Status.LastReconcileTime),
+		ready:                &metric.Status.Ready,
+		conditions:           &metric.Status.Conditions,
+		failures:             &metric.Status.ConsecutiveFailures,
+		staticDimensionsHash: &metric.Status.StaticDimensionsHash,
+		nextRunTime:          &metric.Status.NextRunTime,
 	}
+}
 
-	/*
-		1.2 Create QueryConfig to query the resources in the K8S cluster or external cluster based on the kubeconfig secret reference
-	*/
-	queryConfigs, err := config.CreateExternalQueryConfigSet(ctx, metric.Spec.FederatedClusterAccessRef, r.getClient(), r.getRestConfig(), config.CreateExternalQueryConfigSetOptions{})
-	if err != nil {
-		metric.SetConditions(common.ReadyFalse("QueryConfigCreationFailed", err.Error()))
-		metric.Status.Ready = v1alpha1.StatusStringFalse
-		l.Error(err, "unable to create query configs")
-		return ctrl.Result{RequeueAfter: RequeueAfterError}, err
+// delegate aggregates the results of the agents if the member clusters of the access are collected by agents,
+// the hub then doesn't collect the metric itself
+func (m *federatedMetricCollection) delegate(ctx context.Context, schedule exportSchedule) (ctrl.Result, bool, error) {
+	access := v1alpha1.FederatedClusterAccess{}
+	accessKey := types.NamespacedName{Namespace: m.metric.Spec.FederatedClusterAccessRef.Namespace, Name: m.metric.Spec.FederatedClusterAccessRef.Name}
+	if errAccess := m.r.getClient().Get(ctx, accessKey, &access); errAccess != nil || access.Spec.Agents == nil {
+		return ctrl.Result{}, false, nil
 	}
+	res, err := m.r.aggregateAgentResults(ctx, m.metric, &access, schedule)
+	return res, true, err
+}
 
-	metricClient, errCli := clientoptl.NewMetricClient(ctx, credentials)
-	if errCli != nil {
-		metric.SetConditions(common.ReadyFalse("OTLPClientCreationFailed", errCli.Error()))
-		metric.Status.Ready = v1alpha1.StatusStringFalse
-		l.Error(errCli, fmt.Sprintf("federated metric '%s' re-queued for execution in %v minutes\n", metric.Spec.Name, RequeueAfterError))
-		return ctrl.Result{RequeueAfter: RequeueAfterError}, errCli
+// queryConfigs returns the configs of the member clusters of the federated cluster access of the metric
+func (m *federatedMetricCollection) queryConfigs(ctx context.Context) ([]orc.QueryConfig, error) {
+	return config.CreateExternalQueryConfigSet(ctx, m.metric.Spec.FederatedClusterAccessRef, m.r.getClient(), m.r.getRestConfig(), config.CreateExternalQueryConfigSetOptions{})
+}
+
+func (m *federatedMetricCollection) collect(ctx context.Context, c *collection) (ctrl.Result, error) {
+	metric := m.metric
+	if _, res, err := c.monitor(ctx, m.r.Handlers, func(o *orc.Orchestrator) (*orc.Orchestrator, error) {
+		return o.WithFederated(*metric, c.gauge)
+	}); err != nil {
+		return res, err
 	}
+	// every member cluster was collected, a failed collection aborts the reconcile above
+	metric.Status.Observation = v1alpha1.FederatedObservation{ActiveCount: len(c.queryConfigs)}
 
-	defer func() {
-		if err := metricClient.Close(ctx); err != nil {
-			l.Error(err, "Failed to close metric client during federated metric reconciliation", "metric", metric.Spec.Name)
-		}
-	}()
+	errExport := exportMetrics(ctx, c.client, &metric.Status.ExportStatus)
+	c.setExported(errExport)
 
-	// should this be the group fo the gvr?
-	metricClient.SetMeter("federated")
+	now := metav1.Now()
+	metric.Status.LastReconcileTime = &now
+
+	// Requeue the metric at the next run of its schedule, or with the backoff of its retry policy if the export failed
+	nextRun := c.schedule.next(now.Time)
+	if errExport != nil {
+		nextRun = c.failures.retryAt(metric.Status.NextRunTime)
+	}
+	return c.requeue(nextRun), nil
+}
 
-	gaugeMetric, errGauge := metricClient.NewMetric(metric.Spec.Name)
-	if errGauge != nil {
-		metric.SetConditions(common.ReadyFalse("MetricCreationFailed", errGauge.Error()))
+// aggregateAgentResults sets the status of a federated metric from the results the agents of its access report in their ClusterAgents.
+// Agents that have not reported the metric yet, or whose heartbeat is older than ClusterAgentHeartbeatTimeout, are pending.
+func (r *FederatedMetricReconciler) aggregateAgentResults(ctx context.Context, metric *v1alpha1.FederatedMetric, access *v1alpha1.FederatedClusterAccess, schedule exportSchedule) (ctrl.Result, error) {
+	selector, err := config.ClusterAgentSelector(access)
+	if err != nil {
+		metric.SetConditions(common.ReadyFalse("InvalidAgentSelector", err.Error()))
 		metric.Status.Ready = v1alpha1.StatusStringFalse
-		l.Error(errGauge, fmt.Sprintf("federated metric '%s' re-queued for execution in %v minutes\n", metric.Spec.Name, RequeueAfterError))
-		return ctrl.Result{RequeueAfter: RequeueAfterError}, errGauge
+		return ctrl.Result{RequeueAfter: RequeueAfterError}, nil
 	}
-	metricName := metric.Spec.Name
-	metricNamespace := metric.Namespace
-	gaugeMetric.SetPrometheusFunc(func(dims map[string]string, value int64) {
-		internalmetrics.RecordDataPoint(metricName, metricNamespace, dims, value)
-	})
-
-	creds := common.DataSinkCredentials{}
-	if credentials != nil {
-		creds = *credentials
+	agents := v1alpha1.ClusterAgentList{}
+	if err := r.getClient().List(ctx, &agents, client.InNamespace(config.FederatedMemberNamespace(access)), client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		metric.SetConditions(common.ReadyFalse("ListAgentsFailed", err.Error()))
+		metric.Status.Ready = v1alpha1.StatusStringFalse
+		return ctrl.Result{RequeueAfter: RequeueAfterError}, err
 	}
-	for _, queryConfig := range queryConfigs {
-
-		orchestrator, errOrch := orc.NewOrchestrator(creds, queryConfig).WithFederated(metric, gaugeMetric)
-		if errOrch != nil {
-			metric.SetConditions(common.ReadyFalse("OrchestratorCreationFailed", errOrch.Error()))
-			metric.Status.Ready = v1alpha1.StatusStringFalse
-			l.Error(errOrch, "unable to create federate metric orchestrator monitor")
-			r.Recorder.Eventf(&metric, nil, "Warning", "OrchestratorCreation", "FederatedMetricReconcile", "unable to create orchestrator")
-			return ctrl.Result{RequeueAfter: RequeueAfterError}, errOrch
-		}
-
-		_, errMon := orchestrator.Handler.Monitor(ctx)
 
-		if errMon != nil {
-			metric.SetConditions(common.ReadyFalse("MonitoringFailed", errMon.Error()))
-			metric.Status.Ready = v1alpha1.StatusStringFalse
-			l.Error(errMon, fmt.Sprintf("federated metric '%s' re-queued for execution in %v minutes\n", metric.Spec.Name, RequeueAfterError))
-			return ctrl.Result{RequeueAfter: RequeueAfterError}, errMon
+	observation := v1alpha1.FederatedObservation{}
+	var failed []string
+	for _, agent := range agents.Items {
+		result := agent.Status.FindMetric(metric.Namespace, metric.Name)
+		switch {
+		case result == nil, agent.Status.LastHeartbeatTime == nil, time.Since(agent.Status.LastHeartbeatTime.Time) > ClusterAgentHeartbeatTimeout:
+			observation.PendingCount++
+		case result.Ready == v1alpha1.StatusStringTrue:
+			observation.ActiveCount++
+		default:
+			observation.FailedCount++
+			failed = append(failed, fmt.Sprintf("%s: %s", agent.Spec.ClusterName, result.Message))
 		}
-
 	}
+	metric.Status.Observation = observation
 
-	errExport := metricClient.ExportMetrics(ctx)
-	if errExport != nil {
-		metric.SetConditions(common.ReadyFalse("MetricExportFailed", errExport.Error()))
+	if len(failed) > 0 {
+		metric.SetConditions(common.ReadyFalse("AgentCollectionFailed", strings.Join(failed, "; ")))
 		metric.Status.Ready = v1alpha1.StatusStringFalse
-		l.Error(errExport, fmt.Sprintf("federated metric '%s' re-queued for execution in %v minutes\n", metric.Spec.Name, RequeueAfterError))
 	} else {
-		metric.SetConditions(common.ReadyTrue("Federated metric reconciled successfully"))
+		metric.SetConditions(common.ReadyTrue(fmt.Sprintf("%d of %d agent(s) exported the federated metric", observation.ActiveCount, len(agents.Items))))
 		metric.Status.Ready = v1alpha1.StatusStringTrue
 	}
 
-	// Update LastReconcileTime
 	now := metav1.Now()
 	metric.Status.LastReconcileTime = &now
+	return requeueAt(&metric.Status.NextRunTime, schedule.next(now.Time), metric.GetPriority()), nil
+}
 
-	// Note: Status update is handled by the defer function at the beginning
-
-	/*
-		4. Requeue the metric after the frequency or after 2 minutes if an error occurred
-	*/
-	var requeueTime time.Duration
-	if errExport != nil {
-		requeueTime = RequeueAfterError
-	} else {
-		requeueTime = metric.Spec.Interval.Duration
-	}
-
-	l.Info(fmt.Sprintf("federated metric '%s' re-queued for execution in %v\n", metric.Spec.Name, requeueTime))
-
-	return ctrl.Result{
-		RequeueAfter: requeueTime,
-	}, nil
+// finalize exports a final zero for the series of the deleted federated metric before its finalizer is removed
+func (r *FederatedMetricReconciler) finalize(ctx context.Context, metric *v1alpha1.FederatedMetric, l logr.Logger) (ctrl.Result, error) {
+	return finalizeDeletedMetric(ctx, r.getClient(), r.Recorder, deletedMetric{
+		metric:          metric,
+		exporters:       r.Exporters,
+		name:            metric.Spec.Name,
+		description:     metric.Spec.Description,
+		unit:            metric.Spec.Unit,
+		meterName:       cmp.Or(metric.Spec.MeterName, "federated"),
+		scopeAttributes: v1alpha1.ScopeAttributesMap(metric.Spec.ScopeAttributes),
+		dataSinkRef:     metric.Spec.DataSinkRef,
+		collect: func(ctx context.Context, credentials common.DataSinkCredentials, gauge *clientoptl.Metric) error {
+			queryConfigs, err := config.CreateExternalQueryConfigSet(ctx, metric.Spec.FederatedClusterAccessRef, r.getClient(), r.getRestConfig(), config.CreateExternalQueryConfigSetOptions{})
+			if err != nil {
+				return err
+			}
+			for _, queryConfig := range queryConfigs {
+				orchestrator, err := orc.NewOrchestrator(credentials, queryConfig).WithHandlers(r.Handlers).WithFederated(*metric, gauge)
+				if err != nil {
+					return err
+				}
+				if _, err := orchestrator.Handler.Monitor(ctx); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}, l)
 }
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *FederatedMetricReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
-		For(&v1alpha1.FederatedMetric{}).
-		Complete(r)
+	b := ctrl.NewControllerManagedBy(mgr).
+		Named(FederatedMetricControllerName).
+		WithOptions(Controllers.forController(FederatedMetricControllerName)).
+		// metrics are enqueued with their priority, so important metrics are reconciled first
+		Watches(&v1alpha1.FederatedMetric{}, enqueueSelf())
+
+	b, err := watchStaticDimensionSources(mgr, b, &v1alpha1.FederatedMetric{}, func() client.ObjectList { return &v1alpha1.FederatedMetricList{} })
+	if err != nil {
+		return err
+	}
+	return b.Complete(r)
 }
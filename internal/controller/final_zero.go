@@ -0,0 +1,155 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/tools/events"
+	"k8s.io/client-go/util/retry"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	"github.com/openmcp-project/metrics-operator/api/v1alpha1"
+	"github.com/openmcp-project/metrics-operator/internal/clientoptl"
+	"github.com/openmcp-project/metrics-operator/internal/common"
+	internalmetrics "github.com/openmcp-project/metrics-operator/internal/metrics"
+)
+
+// FinalZeroFinalizer keeps a deleted metric until 0 has been exported for its series,
+// it is added to the metrics exporting to a DataSink with the FinalZero policy for deleted series
+const FinalZeroFinalizer = "metrics.openmcp.cloud/final-zero"
+
+// deletedMetric describes how the series of a deleted metric are recorded for the final export
+type deletedMetric struct {
+	metric          staticDimensionsMetric
+	name            string
+	description     string
+	unit            string
+	meterName       string
+	scopeAttributes map[string]string
+	dataSinkRef     *v1alpha1.DataSinkReference
+	exporters       clientoptl.ExporterFactory
+	// collect records the series of the metric with the gauge, which records 0 for every data point
+	collect func(ctx context.Context, credentials common.DataSinkCredentials, gauge *clientoptl.Metric) error
+}
+
+// syncFinalZeroFinalizer adds the finalizer if the data sink of the metric exports a final zero for deleted series
+// and removes it otherwise. Only the metadata is patched, changes to the status of the metric are kept.
+func syncFinalZeroFinalizer(ctx context.Context, c client.Client, metric client.Object, credentials *common.DataSinkCredentials) error {
+	finalZero := credentials != nil && credentials.FinalZero
+	if finalZero == controllerutil.ContainsFinalizer(metric, FinalZeroFinalizer) {
+		return nil
+	}
+
+	if err := patchFinalZeroFinalizer(ctx, c, metric, finalZero); err != nil {
+		return fmt.Errorf("failed to update the finalizers: %w", err)
+	}
+	return nil
+}
+
+// patchFinalZeroFinalizer adds or removes the finalizer and sets the finalizers and the resource version of the patched metric.
+// The finalizers are a list, so they are patched with the resource version they were read at,
+// and a conflicting update of the metric is retried with its latest version.
+func patchFinalZeroFinalizer(ctx context.Context, c client.Client, metric client.Object, add bool) error {
+	patched, ok := metric.DeepCopyObject().(client.Object)
+	if !ok {
+		return fmt.Errorf("unable to copy %T", metric)
+	}
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		patch := client.MergeFromWithOptions(patched.DeepCopyObject().(client.Object), client.MergeFromWithOptimisticLock{})
+		if add {
+			controllerutil.AddFinalizer(patched, FinalZeroFinalizer)
+		} else {
+			controllerutil.RemoveFinalizer(patched, FinalZeroFinalizer)
+		}
+		err := c.Patch(ctx, patched, patch)
+		if apierrors.IsConflict(err) {
+			if errGet := c.Get(ctx, client.ObjectKeyFromObject(metric), patched); errGet != nil {
+				return errGet
+			}
+		}
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	metric.SetFinalizers(patched.GetFinalizers())
+	metric.SetResourceVersion(patched.GetResourceVersion())
+	return nil
+}
+
+// finalizeDeletedMetric exports 0 for the series of the deleted metric and removes its finalizer.
+// A failed export is retried until the grace period of the data sink has passed since the deletion.
+func finalizeDeletedMetric(ctx context.Context, c client.Client, recorder events.EventRecorder, deleted deletedMetric, l logr.Logger) (ctrl.Result, error) {
+	metric := deleted.metric
+	if !controllerutil.ContainsFinalizer(metric, FinalZeroFinalizer) {
+		return ctrl.Result{}, nil
+	}
+
+	credentials, err := NewDataSinkCredentialsRetriever(c, recorder).GetDataSinkCredentials(ctx, deleted.dataSinkRef, metric, l)
+	switch {
+	case err != nil:
+		recorder.Eventf(metric, nil, "Warning", "FinalZeroSkipped", "FinalizeMetric", "DataSink unavailable, no final zero exported: %s", err.Error())
+		return removeFinalZeroFinalizer(ctx, c, deleted)
+	case credentials == nil || !credentials.FinalZero:
+		recorder.Eventf(metric, nil, "Normal", "FinalZeroSkipped", "FinalizeMetric", "DataSink no longer exports a final zero for deleted series")
+		return removeFinalZeroFinalizer(ctx, c, deleted)
+	}
+
+	if errExport := exportFinalZero(ctx, c, deleted, *credentials); errExport != nil {
+		remaining := credentials.FinalZeroGracePeriod - time.Since(metric.GetDeletionTimestamp().Time)
+		if remaining > 0 {
+			l.Error(errExport, "failed to export the final zero, retrying", "metric", deleted.name)
+			recorder.Eventf(metric, nil, "Warning", "FinalZeroFailed", "FinalizeMetric", "failed to export the final zero, retrying: %s", errExport.Error())
+			return ctrl.Result{RequeueAfter: min(RequeueAfterError, remaining)}, nil
+		}
+		recorder.Eventf(metric, nil, "Warning", "FinalZeroFailed", "FinalizeMetric", "grace period of %v passed, metric deleted without a final zero: %s", credentials.FinalZeroGracePeriod, errExport.Error())
+		return removeFinalZeroFinalizer(ctx, c, deleted)
+	}
+
+	recorder.Eventf(metric, nil, "Normal", "FinalZeroExported", "FinalizeMetric", "final zero exported for the series of metric '%s'", deleted.name)
+	return removeFinalZeroFinalizer(ctx, c, deleted)
+}
+
+// exportFinalZero records the series of the deleted metric with the value 0 and exports them to the data sink
+func exportFinalZero(ctx context.Context, c client.Client, deleted deletedMetric, credentials common.DataSinkCredentials) error {
+	// the static dimensions identify the series as much as the dimensions of the data points
+	dimensions, err := resolveStaticDimensions(ctx, c, deleted.metric)
+	if err != nil {
+		return err
+	}
+
+	metricClient, err := deleted.exporters.NewMetricClient(ctx, &credentials)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = metricClient.Close(ctx)
+	}()
+
+	metricClient.SetMeter(deleted.meterName, deleted.scopeAttributes)
+	gauge, err := metricClient.NewMetric(deleted.name, deleted.description, deleted.unit)
+	if err != nil {
+		return err
+	}
+	gauge.SetZero(true)
+	gauge.SetStaticDimensions(dimensions)
+
+	if err := deleted.collect(ctx, credentials, gauge); err != nil {
+		return err
+	}
+	return metricClient.ExportMetrics(ctx)
+}
+
+// removeFinalZeroFinalizer lets the deleted metric go and removes its series from the /metrics endpoint
+func removeFinalZeroFinalizer(ctx context.Context, c client.Client, deleted deletedMetric) (ctrl.Result, error) {
+	if err := patchFinalZeroFinalizer(ctx, c, deleted.metric, false); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	internalmetrics.DeleteMetricSeries(deleted.name, deleted.metric.GetNamespace())
+	return ctrl.Result{}, nil
+}
@@ -0,0 +1,211 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/events"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/openmcp-project/metrics-operator/api/v1alpha1"
+	"github.com/openmcp-project/metrics-operator/internal/clientoptl"
+	"github.com/openmcp-project/metrics-operator/internal/common"
+)
+
+func TestSyncFinalZeroFinalizer(t *testing.T) {
+	testCases := []struct {
+		name        string
+		finalizers  []string
+		credentials *common.DataSinkCredentials
+		expected    []string
+	}{
+		{
+			name:        "Added",
+			credentials: &common.DataSinkCredentials{FinalZero: true},
+			expected:    []string{FinalZeroFinalizer},
+		},
+		{
+			name:        "Removed",
+			finalizers:  []string{"other", FinalZeroFinalizer},
+			credentials: &common.DataSinkCredentials{},
+			expected:    []string{"other"},
+		},
+		{
+			name:       "NoDataSink",
+			finalizers: []string{FinalZeroFinalizer},
+			expected:   nil,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			require.NoError(t, v1alpha1.AddToScheme(scheme))
+			metric := &v1alpha1.Metric{ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "pods", Finalizers: tc.finalizers}}
+			cli := fake.NewClientBuilder().WithScheme(scheme).WithObjects(metric).WithStatusSubresource(metric).Build()
+
+			// changes to the status are not lost by updating the finalizers
+			metric.Status.Ready = v1alpha1.StatusStringTrue
+			require.NoError(t, syncFinalZeroFinalizer(context.Background(), cli, metric, tc.credentials))
+			require.Equal(t, tc.expected, metric.Finalizers)
+			require.Equal(t, v1alpha1.StatusStringTrue, metric.Status.Ready)
+			require.NoError(t, cli.Status().Update(context.Background(), metric), "the resource version is up to date")
+
+			stored := &v1alpha1.Metric{}
+			require.NoError(t, cli.Get(context.Background(), client.ObjectKeyFromObject(metric), stored))
+			require.Equal(t, tc.expected, stored.Finalizers)
+		})
+	}
+}
+
+func TestSyncFinalZeroFinalizer_conflict(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, v1alpha1.AddToScheme(scheme))
+	metric := &v1alpha1.Metric{ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "pods"}}
+	cli := fake.NewClientBuilder().WithScheme(scheme).WithObjects(metric).WithStatusSubresource(metric).Build()
+
+	// another controller adds its finalizer after the metric was read
+	concurrent := &v1alpha1.Metric{}
+	require.NoError(t, cli.Get(context.Background(), client.ObjectKeyFromObject(metric), concurrent))
+	concurrent.Finalizers = []string{"other"}
+	require.NoError(t, cli.Update(context.Background(), concurrent))
+
+	require.NoError(t, syncFinalZeroFinalizer(context.Background(), cli, metric, &common.DataSinkCredentials{FinalZero: true}))
+	require.Equal(t, []string{"other", FinalZeroFinalizer}, metric.Finalizers)
+
+	stored := &v1alpha1.Metric{}
+	require.NoError(t, cli.Get(context.Background(), client.ObjectKeyFromObject(metric), stored))
+	require.Equal(t, []string{"other", FinalZeroFinalizer}, stored.Finalizers, "the finalizer of the other controller is kept")
+}
+
+func TestFinalizeDeletedMetric(t *testing.T) {
+	var exports int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		exports++
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	finalZeroSink := func(gracePeriod time.Duration) *v1alpha1.DataSink {
+		return &v1alpha1.DataSink{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "default"},
+			Spec: v1alpha1.DataSinkSpec{
+				Connection:    v1alpha1.Connection{Endpoint: server.URL},
+				DeletedSeries: &v1alpha1.DeletedSeries{Policy: v1alpha1.DeletedSeriesFinalZero, GracePeriod: metav1.Duration{Duration: gracePeriod}},
+			},
+		}
+	}
+	recordPoint := func(ctx context.Context, _ common.DataSinkCredentials, gauge *clientoptl.Metric) error {
+		return gauge.RecordMetrics(ctx, clientoptl.NewDataPoint().AddDimension("cluster", "prod").SetValue(42))
+	}
+	failCollect := func(context.Context, common.DataSinkCredentials, *clientoptl.Metric) error {
+		return fmt.Errorf("cluster unreachable")
+	}
+
+	testCases := []struct {
+		name            string
+		dataSink        *v1alpha1.DataSink
+		deletedSince    time.Duration
+		collect         func(ctx context.Context, credentials common.DataSinkCredentials, gauge *clientoptl.Metric) error
+		expectedDeleted bool
+		expectedRequeue bool
+		expectedExports int
+		expectedEvent   string
+	}{
+		{
+			name:            "Exported",
+			dataSink:        finalZeroSink(5 * time.Minute),
+			collect:         recordPoint,
+			expectedDeleted: true,
+			expectedExports: 1,
+			expectedEvent:   "FinalZeroExported",
+		},
+		{
+			name:            "Retried",
+			dataSink:        finalZeroSink(5 * time.Minute),
+			deletedSince:    time.Minute,
+			collect:         failCollect,
+			expectedRequeue: true,
+			expectedEvent:   "FinalZeroFailed",
+		},
+		{
+			name:            "GracePeriodPassed",
+			dataSink:        finalZeroSink(5 * time.Minute),
+			deletedSince:    10 * time.Minute,
+			collect:         failCollect,
+			expectedDeleted: true,
+			expectedEvent:   "FinalZeroFailed",
+		},
+		{
+			name:            "DataSinkMissing",
+			collect:         recordPoint,
+			expectedDeleted: true,
+			expectedEvent:   "FinalZeroSkipped",
+		},
+		{
+			name: "PolicyKeep",
+			dataSink: &v1alpha1.DataSink{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "default"},
+				Spec:       v1alpha1.DataSinkSpec{Connection: v1alpha1.Connection{Endpoint: server.URL}},
+			},
+			collect:         recordPoint,
+			expectedDeleted: true,
+			expectedEvent:   "FinalZeroSkipped",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			exports = 0
+			scheme := runtime.NewScheme()
+			require.NoError(t, v1alpha1.AddToScheme(scheme))
+			metric := &v1alpha1.Metric{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace:         "team-a",
+					Name:              "pods",
+					Finalizers:        []string{FinalZeroFinalizer},
+					DeletionTimestamp: &metav1.Time{Time: time.Now().Add(-tc.deletedSince)},
+				},
+				Spec: v1alpha1.MetricSpec{Name: "pods", DataSinkRef: &v1alpha1.DataSinkReference{Name: "default"}},
+			}
+			builder := fake.NewClientBuilder().WithScheme(scheme).WithObjects(metric)
+			if tc.dataSink != nil {
+				builder = builder.WithObjects(tc.dataSink)
+			}
+			cli := builder.Build()
+			recorder := events.NewFakeRecorder(10)
+
+			result, err := finalizeDeletedMetric(context.Background(), cli, recorder, deletedMetric{
+				metric:      metric,
+				name:        metric.Spec.Name,
+				meterName:   "metric",
+				dataSinkRef: metric.Spec.DataSinkRef,
+				collect:     tc.collect,
+			}, logr.Discard())
+			require.NoError(t, err)
+			require.Equal(t, tc.expectedRequeue, result.RequeueAfter > 0)
+			require.Equal(t, tc.expectedExports, exports)
+
+			errGet := cli.Get(context.Background(), types.NamespacedName{Namespace: "team-a", Name: "pods"}, &v1alpha1.Metric{})
+			require.Equal(t, tc.expectedDeleted, apierrors.IsNotFound(errGet), "metric deleted")
+
+			var reasons []string
+			for len(recorder.Events) > 0 {
+				reasons = append(reasons, <-recorder.Events)
+			}
+			require.NotEmpty(t, reasons)
+			require.Contains(t, reasons[len(reasons)-1], tc.expectedEvent)
+		})
+	}
+}
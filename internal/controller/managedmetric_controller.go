@@ -17,13 +17,12 @@ limitations under the License.
 package controller
 
 import (
+	"cmp"
 	"context"
 	"fmt"
 	"net/url"
-	"time"
 
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
-	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/rest"
@@ -36,7 +35,6 @@ import (
 	"github.com/openmcp-project/metrics-operator/internal/clientoptl"
 	"github.com/openmcp-project/metrics-operator/internal/common"
 	"github.com/openmcp-project/metrics-operator/internal/config"
-	internalmetrics "github.com/openmcp-project/metrics-operator/internal/metrics"
 	"github.com/openmcp-project/metrics-operator/internal/orchestrator"
 
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -50,7 +48,7 @@ func NewManagedMetricReconciler(mgr ctrl.Manager) *ManagedMetricReconciler {
 		inClient:     mgr.GetClient(),
 		inRestConfig: mgr.GetConfig(),
 		Scheme:       mgr.GetScheme(),
-		Recorder:     mgr.GetEventRecorder("managedmetrics-controller"),
+		Recorder:     SharedEventThrottle.Recorder(mgr.GetEventRecorder("managedmetrics-controller")),
 	}
 }
 
@@ -62,21 +60,6 @@ func (r *ManagedMetricReconciler) getRestConfig() *rest.Config {
 	return r.inRestConfig
 }
 
-func (r *ManagedMetricReconciler) scheduleNextReconciliation(metric *v1alpha1.ManagedMetric) ctrl.Result {
-	elapsed := time.Since(metric.Status.Observation.Timestamp.Time)
-	return ctrl.Result{
-		RequeueAfter: metric.Spec.Interval.Duration - elapsed,
-	}
-}
-
-func (r *ManagedMetricReconciler) shouldReconcile(metric *v1alpha1.ManagedMetric) bool {
-	if metric.Status.Observation.Timestamp.Time.IsZero() {
-		return true
-	}
-	elapsed := time.Since(metric.Status.Observation.Timestamp.Time)
-	return elapsed >= metric.Spec.Interval.Duration
-}
-
 // ManagedMetricReconciler reconciles a ManagedMetric object
 type ManagedMetricReconciler struct {
 	inClient     client.Client
@@ -84,12 +67,10 @@ type ManagedMetricReconciler struct {
 	Scheme       *runtime.Scheme
 
 	Recorder events.EventRecorder
-}
-
-// getDataSinkCredentials fetches DataSink configuration and credentials
-func (r *ManagedMetricReconciler) getDataSinkCredentials(ctx context.Context, managedMetric *v1alpha1.ManagedMetric, l logr.Logger) (*common.DataSinkCredentials, error) {
-	retriever := NewDataSinkCredentialsRetriever(r.getClient(), r.Recorder)
-	return retriever.GetDataSinkCredentials(ctx, managedMetric.Spec.DataSinkRef, managedMetric, l)
+	// Exporters creates the exporters of the metric clients, the zero value exports with OTLP
+	Exporters clientoptl.ExporterFactory
+	// Handlers creates the handlers monitoring the metrics, the zero value queries the target clusters
+	Handlers orchestrator.HandlerFactory
 }
 
 // +kubebuilder:rbac:groups=metrics.openmcp.cloud,resources=managedmetrics,verbs=get;list;watch;create;update;patch;delete
@@ -102,8 +83,6 @@ func (r *ManagedMetricReconciler) getDataSinkCredentials(ctx context.Context, ma
 // move the current state of the cluster closer to the desired state.
 // For more details, check Reconcile and its Result here:
 // - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.16.3/pkg/reconcile
-//
-//nolint:gocyclo
 func (r *ManagedMetricReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	var l = log.FromContext(ctx)
 
@@ -124,134 +103,73 @@ func (r *ManagedMetricReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 		return ctrl.Result{RequeueAfter: RequeueAfterError}, errLoad
 	}
 
-	// Defer status update to ensure it's always called
-	defer func() {
-		if err := r.inClient.Status().Update(ctx, &metric); err != nil {
-			l.Error(err, "Failed to update ManagedMetric status")
-		}
-	}()
-
-	// Initialize Ready condition if not present
-	if meta.FindStatusCondition(metric.Status.Conditions, v1alpha1.TypeReady) == nil {
-		metric.SetConditions(common.ReadyUnknown("Reconciling", "Initial reconciliation"))
-	}
-
-	// Check if enough time has passed since the last reconciliation
-	if !r.shouldReconcile(&metric) {
-		return r.scheduleNextReconciliation(&metric), nil
-	}
-
-	/*
-		1.1 Get DataSink configuration and credentials
-	*/
-	credentials, err := r.getDataSinkCredentials(ctx, &metric, l)
-	if err != nil {
-		metric.SetConditions(common.ReadyFalse("DataSinkUnavailable", err.Error()))
-		metric.Status.Ready = v1alpha1.StatusStringFalse
-		return ctrl.Result{RequeueAfter: RequeueAfterError}, err
-	}
-	if credentials == nil {
-		l.Info("DataSink not found; metrics will only be available via /metrics endpoint", "metric", metric.Spec.Name)
-	}
-
-	/*
-		1.2 Create QueryConfig to query the resources in the K8S cluster or external cluster based on the kubeconfig secret reference
-	*/
-	queryConfig, err := createQueryConfig(ctx, metric.Spec.RemoteClusterAccessRef, r)
-	if err != nil {
-		metric.SetConditions(common.ReadyFalse("QueryConfigCreationFailed", err.Error()))
-		metric.Status.Ready = v1alpha1.StatusStringFalse
-		return ctrl.Result{RequeueAfter: RequeueAfterError}, err
+	if errRender := renderVariables(&metric, &metric.Spec); errRender != nil {
+		l.Error(errRender, "unable to replace the variables of the Managed Metric")
+		return ctrl.Result{RequeueAfter: RequeueAfterError}, errRender
 	}
 
-	/*
-		1.3 Create OTel metric client and gauge metric
-	*/
-	metricClient, errCli := clientoptl.NewMetricClient(ctx, credentials)
-	if errCli != nil {
-		metric.SetConditions(common.ReadyFalse("OTLPClientCreationFailed", errCli.Error()))
-		metric.Status.Ready = v1alpha1.StatusStringFalse
-		l.Error(errCli, fmt.Sprintf("managed metric '%s' re-queued for execution in %v minutes\n", metric.Spec.Name, RequeueAfterError))
-		return ctrl.Result{RequeueAfter: RequeueAfterError}, errCli
+	// deleted metrics only export a final zero for their series if their data sink asks for it
+	if !metric.DeletionTimestamp.IsZero() {
+		return r.finalize(ctx, &metric, l)
 	}
 
-	defer func() {
-		if err := metricClient.Close(ctx); err != nil {
-			l.Error(err, "Failed to close metric client during managed metric reconciliation", "metric", metric.Spec.Name)
-		}
-	}()
+	return reconcileCollectedMetric(ctx, r.getClient(), r.Recorder, &managedMetricCollection{r: r, metric: &metric}, l)
+}
 
-	// Set meter name for managed metrics
-	metricClient.SetMeter("managed")
+// managedMetricCollection is the collectedMetric strategy of ManagedMetrics
+type managedMetricCollection struct {
+	r      *ManagedMetricReconciler
+	metric *v1alpha1.ManagedMetric
+}
 
-	gaugeMetric, errGauge := metricClient.NewMetric(metric.Spec.Name)
-	if errGauge != nil {
-		metric.SetConditions(common.ReadyFalse("MetricCreationFailed", errGauge.Error()))
-		metric.Status.Ready = v1alpha1.StatusStringFalse
-		l.Error(errGauge, fmt.Sprintf("managed metric '%s' re-queued for execution in %v minutes\n", metric.Spec.Name, RequeueAfterError))
-		return ctrl.Result{RequeueAfter: RequeueAfterError}, errGauge
+func (m *managedMetricCollection) describe() metricDescription {
+	metric := m.metric
+	return metricDescription{
+		metric:               metric,
+		kind:                 "managed metric",
+		action:               "ManagedMetricReconcile",
+		name:                 metric.Spec.Name,
+		description:          metric.Spec.Description,
+		unit:                 metric.Spec.Unit,
+		meterName:            cmp.Or(metric.Spec.MeterName, "managed"),
+		scopeAttributes:      v1alpha1.ScopeAttributesMap(metric.Spec.ScopeAttributes),
+		dataSinkRef:          metric.Spec.DataSinkRef,
+		exporters:            m.r.Exporters,
+		retryPolicy:          metric.Spec.RetryPolicy,
+		interval:             metric.Spec.Interval,
+		schedule:             metric.Spec.Schedule,
+		lastRun:              metric.Status.Observation.Timestamp.Time,
+		ready:                &metric.Status.Ready,
+		conditions:           &metric.Status.Conditions,
+		failures:             &metric.Status.ConsecutiveFailures,
+		staticDimensionsHash: &metric.Status.StaticDimensionsHash,
+		nextRunTime:          &metric.Status.NextRunTime,
 	}
-	metricName := metric.Spec.Name
-	metricNamespace := metric.Namespace
-	gaugeMetric.SetPrometheusFunc(func(dims map[string]string, value int64) {
-		internalmetrics.RecordDataPoint(metricName, metricNamespace, dims, value)
-	})
+}
 
-	/*
-		2. Create a new orchestrator
-	*/
-	creds := common.DataSinkCredentials{}
-	if credentials != nil {
-		creds = *credentials
-	}
-	orchestrator, errOrch := orchestrator.NewOrchestrator(creds, queryConfig).WithManaged(metric, gaugeMetric)
-	if errOrch != nil {
-		metric.SetConditions(common.ReadyFalse("OrchestratorCreationFailed", errOrch.Error()))
-		metric.Status.Ready = v1alpha1.StatusStringFalse
-		l.Error(errOrch, "unable to create managed metric orchestrator monitor")
-		r.Recorder.Eventf(&metric, nil, "Warning", "OrchestratorCreation", "ManagedMetricReconcile", "unable to create orchestrator")
-		return ctrl.Result{RequeueAfter: RequeueAfterError}, errOrch
+// queryConfigs returns the config of the local cluster or of the remote cluster the metric refers to
+func (m *managedMetricCollection) queryConfigs(ctx context.Context) ([]orchestrator.QueryConfig, error) {
+	queryConfig, err := createQueryConfig(ctx, m.metric.Spec.RemoteClusterAccessRef, m.r)
+	if err != nil {
+		return nil, err
 	}
+	return []orchestrator.QueryConfig{queryConfig}, nil
+}
 
-	result, errMon := orchestrator.Handler.Monitor(ctx)
-
-	if errMon != nil {
-		metric.SetConditions(common.ReadyFalse("MonitoringFailed", errMon.Error()))
-		metric.Status.Ready = v1alpha1.StatusStringFalse
-		l.Error(errMon, fmt.Sprintf("managed metric '%s' re-queued for execution in %v minutes\n", metric.Spec.Name, RequeueAfterError))
-		return ctrl.Result{RequeueAfter: RequeueAfterError}, errMon
+func (m *managedMetricCollection) collect(ctx context.Context, c *collection) (ctrl.Result, error) {
+	metric := m.metric
+	results, res, err := c.monitor(ctx, m.r.Handlers, func(o *orchestrator.Orchestrator) (*orchestrator.Orchestrator, error) {
+		return o.WithManaged(*metric, c.gauge)
+	})
+	if err != nil {
+		return res, err
 	}
+	result := results[0]
 
-	/*
-		2.1 Export metrics to data sink
-	*/
-	errExport := metricClient.ExportMetrics(ctx)
+	errExport := exportMetrics(ctx, c.client, &metric.Status.ExportStatus)
 
-	/*
-		3. Update the status of the metric with conditions and phase
-	*/
-	switch result.Phase {
-	case v1alpha1.PhaseActive:
-		metric.SetConditions(common.Available(result.Message))
-		r.Recorder.Eventf(&metric, nil, "Normal", "MetricAvailable", "ManagedMetricReconcile", result.Message)
-	case v1alpha1.PhaseFailed:
-		l.Error(result.Error, result.Message, "reason", result.Reason)
-		metric.SetConditions(common.Error(result.Message))
-		r.Recorder.Eventf(&metric, nil, "Warning", "MetricFailed", "ManagedMetricReconcile", result.Message)
-	case v1alpha1.PhasePending:
-		metric.SetConditions(common.Creating())
-		r.Recorder.Eventf(&metric, nil, "Normal", "MetricPending", "ManagedMetricReconcile", result.Message)
-	}
-
-	// Set Ready condition based on export result
-	if errExport != nil {
-		metric.SetConditions(common.ReadyFalse("MetricExportFailed", errExport.Error()))
-		metric.Status.Ready = v1alpha1.StatusStringFalse
-		l.Error(errExport, fmt.Sprintf("managed metric '%s' re-queued for execution in %v minutes\n", metric.Spec.Name, RequeueAfterError))
-	} else {
-		metric.SetConditions(common.ReadyTrue("Managed metric reconciled successfully"))
-		metric.Status.Ready = v1alpha1.StatusStringTrue
-	}
+	c.recordResult(result)
+	c.setExported(errExport)
 
 	// Update the observation timestamp to track when this reconciliation happened
 	metric.Status.Observation = v1alpha1.ManagedObservation{
@@ -259,30 +177,56 @@ func (r *ManagedMetricReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 		Resources: result.Observation.GetValue(),
 	}
 
-	// Note: Status update is handled by the defer function at the beginning
-
-	/*
-		4. Requeue the metric after the frequency or after 2 minutes if an error occurred
-	*/
-	var requeueTime time.Duration
+	// Requeue the metric at the next run of its schedule, or with the backoff of its retry policy if an error occurred
+	nextRun := c.schedule.next(metric.Status.Observation.Timestamp.Time)
 	if result.Error != nil || errExport != nil {
-		requeueTime = RequeueAfterError
-	} else {
-		requeueTime = metric.Spec.Interval.Duration
+		nextRun = c.failures.retryAt(metric.Status.NextRunTime)
 	}
+	return c.requeue(nextRun), nil
+}
 
-	l.Info(fmt.Sprintf("managed metric '%s' re-queued for execution in %v\n", metric.Spec.Name, requeueTime))
-
-	return ctrl.Result{
-		RequeueAfter: requeueTime,
-	}, nil
+// finalize exports a final zero for the series of the deleted managed metric before its finalizer is removed
+func (r *ManagedMetricReconciler) finalize(ctx context.Context, metric *v1alpha1.ManagedMetric, l logr.Logger) (ctrl.Result, error) {
+	return finalizeDeletedMetric(ctx, r.getClient(), r.Recorder, deletedMetric{
+		metric:          metric,
+		exporters:       r.Exporters,
+		name:            metric.Spec.Name,
+		description:     metric.Spec.Description,
+		unit:            metric.Spec.Unit,
+		meterName:       cmp.Or(metric.Spec.MeterName, "managed"),
+		scopeAttributes: v1alpha1.ScopeAttributesMap(metric.Spec.ScopeAttributes),
+		dataSinkRef:     metric.Spec.DataSinkRef,
+		collect: func(ctx context.Context, credentials common.DataSinkCredentials, gauge *clientoptl.Metric) error {
+			queryConfig, err := createQueryConfig(ctx, metric.Spec.RemoteClusterAccessRef, r)
+			if err != nil {
+				return err
+			}
+			orc, err := orchestrator.NewOrchestrator(credentials, queryConfig).WithHandlers(r.Handlers).WithManaged(*metric, gauge)
+			if err != nil {
+				return err
+			}
+			result, err := orc.Handler.Monitor(ctx)
+			if err != nil {
+				return err
+			}
+			return result.Error
+		},
+	}, l)
 }
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *ManagedMetricReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
-		For(&v1alpha1.ManagedMetric{}).
-		Complete(r)
+	b := ctrl.NewControllerManagedBy(mgr).
+		Named(ManagedMetricControllerName).
+		WithOptions(Controllers.forController(ManagedMetricControllerName)).
+		// metrics are enqueued with their priority, so important metrics are reconciled first
+		Watches(&v1alpha1.ManagedMetric{}, enqueueSelf())
+
+	b, err := watchStaticDimensionSources(mgr, b, &v1alpha1.ManagedMetric{}, func() client.ObjectList { return &v1alpha1.ManagedMetricList{} })
+	if err != nil {
+		return err
+	}
+	return b.Complete(r)
 }
 
 func createQueryConfig(ctx context.Context, rcaRef *v1alpha1.RemoteClusterAccessRef, r InsightReconciler) (orchestrator.QueryConfig, error) {
@@ -298,6 +242,13 @@ func createQueryConfig(ctx context.Context, rcaRef *v1alpha1.RemoteClusterAccess
 		// local cluster name (where operator is deployed)
 		clusterName, _ := getClusterInfo(r.getRestConfig())
 		queryConfig = orchestrator.QueryConfig{Client: r.getClient(), RestConfig: *r.getRestConfig(), ClusterName: &clusterName}
+		// the manager's client is used as is, only the dynamic and discovery clients are shared
+		clients, err := orchestrator.SharedClientFactory.ForConfig(r.getRestConfig(), r.getClient().Scheme(), "")
+		if err != nil {
+			return orchestrator.QueryConfig{}, err
+		}
+		queryConfig.DynamicClient = clients.Dynamic
+		queryConfig.DiscoveryClient = clients.Discovery
 	}
 	return queryConfig, nil
 }
@@ -324,8 +275,5 @@ func getClusterInfo(config *rest.Config) (string, error) {
 	return hostname, nil
 }
 
-// OrchestratorFactory is a function type for creating orchestrators
-type OrchestratorFactory func(creds common.DataSinkCredentials, qConfig orchestrator.QueryConfig) *orchestrator.Orchestrator
-
 // QueryConfigFactory is a function type for creating query configs
 type QueryConfigFactory func(ctx context.Context, rcaRef *v1alpha1.RemoteClusterAccessRef, r InsightReconciler) (orchestrator.QueryConfig, error)
@@ -17,8 +17,11 @@ limitations under the License.
 package controller
 
 import (
+	"cmp"
 	"context"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/go-logr/logr"
@@ -26,6 +29,7 @@ import (
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/events"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -34,14 +38,16 @@ import (
 	"github.com/openmcp-project/metrics-operator/api/v1alpha1"
 	"github.com/openmcp-project/metrics-operator/internal/clientoptl"
 	"github.com/openmcp-project/metrics-operator/internal/common"
-	"github.com/openmcp-project/metrics-operator/internal/config"
-	internalmetrics "github.com/openmcp-project/metrics-operator/internal/metrics"
 	orc "github.com/openmcp-project/metrics-operator/internal/orchestrator"
 )
 
 const (
 	// RequeueAfterError is the time to requeue the metric after an error
 	RequeueAfterError = 2 * time.Minute
+
+	// MaxTargetNotFound is the number of consecutive collections of a metric whose target kind is not served
+	// that are retried after RequeueAfterError, further collections are retried at the interval of the metric
+	MaxTargetNotFound = 5
 )
 
 // NewMetricReconciler creates a new MetricReconciler
@@ -52,7 +58,7 @@ func NewMetricReconciler(mgr ctrl.Manager) *MetricReconciler {
 		inCli:      mgr.GetClient(),
 		RestConfig: mgr.GetConfig(),
 		Scheme:     mgr.GetScheme(),
-		Recorder:   mgr.GetEventRecorder("Metric-controller"),
+		Recorder:   SharedEventThrottle.Recorder(mgr.GetEventRecorder("Metric-controller")),
 	}
 }
 
@@ -64,6 +70,13 @@ type MetricReconciler struct {
 	Scheme     *runtime.Scheme
 	RestConfig *rest.Config
 	Recorder   events.EventRecorder
+	// Exporters creates the exporters of the metric clients, the zero value exports with OTLP
+	Exporters clientoptl.ExporterFactory
+	// Handlers creates the handlers monitoring the metrics, the zero value queries the target clusters
+	Handlers orc.HandlerFactory
+
+	// targetChanges are the metrics to collect right away because their target kinds were installed or removed
+	targetChanges targetKindChanges
 }
 
 // GetClient returns the client
@@ -76,26 +89,20 @@ func (r *MetricReconciler) getRestConfig() *rest.Config {
 	return r.RestConfig
 }
 
-// getDataSinkCredentials fetches DataSink configuration and credentials
-func (r *MetricReconciler) getDataSinkCredentials(ctx context.Context, metric *v1alpha1.Metric, l logr.Logger) (*common.DataSinkCredentials, error) {
-	retriever := NewDataSinkCredentialsRetriever(r.getClient(), r.Recorder)
-	return retriever.GetDataSinkCredentials(ctx, metric.Spec.DataSinkRef, metric, l)
-}
-
-func (r *MetricReconciler) scheduleNextReconciliation(metric *v1alpha1.Metric) ctrl.Result {
-
-	elapsed := time.Since(metric.Status.Observation.Timestamp.Time)
-	return ctrl.Result{
-		RequeueAfter: metric.Spec.Interval.Duration - elapsed,
+// reconcileInterval returns the interval the metric is observed in, metrics with sampling are observed for every sample
+func reconcileInterval(metric *v1alpha1.Metric) metav1.Duration {
+	if metric.Spec.Sampling != nil {
+		return metric.Spec.Sampling.SampleInterval
 	}
+	return metric.Spec.Interval
 }
 
-func (r *MetricReconciler) shouldReconcile(metric *v1alpha1.Metric) bool {
-	if metric.Status.Observation.LatestValue == "" || metric.Status.Observation.Timestamp.Time.IsZero() {
-		return true
+// lastExport returns the time the metric was last exported, or the zero time if it has no value yet
+func (r *MetricReconciler) lastExport(metric *v1alpha1.Metric) time.Time {
+	if metric.Status.Observation.LatestValue == "" {
+		return time.Time{}
 	}
-	elapsed := time.Since(metric.Status.Observation.Timestamp.Time)
-	return elapsed >= metric.Spec.Interval.Duration
+	return metric.Status.Observation.Timestamp.Time
 }
 
 func (r *MetricReconciler) handleGetError(err error, log logr.Logger) (ctrl.Result, error) {
@@ -117,12 +124,10 @@ func (r *MetricReconciler) handleGetError(err error, log logr.Logger) (ctrl.Resu
 // +kubebuilder:rbac:groups="",resources=secrets,verbs=get
 
 // Reconcile handles the reconciliation of a Metric object
-//
-//nolint:gocyclo
 func (r *MetricReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	l := r.log.WithValues("namespace", req.NamespacedName, "name", req.Name)
 
-	l.Info("Reconciling Metric")
+	l.V(1).Info("Reconciling Metric")
 
 	/*
 			1. Load the generic metric using the client
@@ -133,176 +138,237 @@ func (r *MetricReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctr
 		return r.handleGetError(errLoad, l)
 	}
 
-	// Defer status update to ensure it's always called
-	defer func() {
-		if err := r.getClient().Status().Update(ctx, &metric); err != nil {
-			l.Error(err, "Failed to update Metric status")
-		}
-	}()
-
-	// Initialize Ready condition if not present
-	if meta.FindStatusCondition(metric.Status.Conditions, v1alpha1.TypeReady) == nil {
-		metric.SetConditions(common.ReadyUnknown("Reconciling", "Initial reconciliation"))
+	if errRender := renderVariables(&metric, &metric.Spec); errRender != nil {
+		l.Error(errRender, "unable to replace the variables of the Metric")
+		return ctrl.Result{RequeueAfter: RequeueAfterError}, errRender
 	}
 
-	// Check if enough time has passed since the last reconciliation
-	if !r.shouldReconcile(&metric) {
-		return r.scheduleNextReconciliation(&metric), nil
+	// deleted metrics only export a final zero for their series if their data sink asks for it
+	if !metric.DeletionTimestamp.IsZero() {
+		return r.finalize(ctx, &metric, l)
 	}
 
-	/*
-		1.1 Get DataSink configuration and credentials
-	*/
-	credentials, err := r.getDataSinkCredentials(ctx, &metric, l)
-	if err != nil {
-		metric.SetConditions(common.ReadyFalse("DataSinkUnavailable", err.Error()))
-		metric.Status.Ready = v1alpha1.StatusStringFalse
-		return ctrl.Result{RequeueAfter: RequeueAfterError}, err
-	}
-	if credentials == nil {
-		l.Info("DataSink not found; metrics will only be available via /metrics endpoint", "metric", metric.Spec.Name)
+	return reconcileCollectedMetric(ctx, r.getClient(), r.Recorder, &metricCollection{r: r, metric: &metric, key: req.NamespacedName}, l)
+}
+
+// metricCollection is the collectedMetric strategy of Metrics
+type metricCollection struct {
+	r      *MetricReconciler
+	metric *v1alpha1.Metric
+	key    types.NamespacedName
+}
+
+func (m *metricCollection) describe() metricDescription {
+	metric := m.metric
+	return metricDescription{
+		metric:          metric,
+		kind:            "metric",
+		action:          "ReconcileMetric",
+		name:            metric.Spec.Name,
+		description:     metric.Spec.Description,
+		unit:            metric.Spec.Unit,
+		meterName:       cmp.Or(metric.Spec.MeterName, "metric"),
+		scopeAttributes: v1alpha1.ScopeAttributesMap(metric.Spec.ScopeAttributes),
+		dataSinkRef:     metric.Spec.DataSinkRef,
+		exporters:       m.r.Exporters,
+		retryPolicy:     metric.Spec.RetryPolicy,
+		interval:        reconcileInterval(metric),
+		schedule:        metric.Spec.Schedule,
+		lastRun:         m.r.lastExport(metric),
+		// metrics whose target kind was installed or removed are collected right away, unless they run on a cron schedule
+		collectNow: func(schedule exportSchedule) bool {
+			return m.r.targetChanges.take(m.key) && schedule.cron == nil
+		},
+		ready:                &metric.Status.Ready,
+		conditions:           &metric.Status.Conditions,
+		failures:             &metric.Status.ConsecutiveFailures,
+		staticDimensionsHash: &metric.Status.StaticDimensionsHash,
+		nextRunTime:          &metric.Status.NextRunTime,
 	}
+}
 
-	/*
-		1.2 Create QueryConfig to query the resources in the K8S cluster or external cluster based on the kubeconfig secret reference
-	*/
-	queryConfig, err := createQC(ctx, metric.Spec.RemoteClusterAccessRef, r)
+// queryConfigs returns the config of the local cluster or of the remote cluster the metric refers to
+func (m *metricCollection) queryConfigs(ctx context.Context) ([]orc.QueryConfig, error) {
+	queryConfig, err := createQueryConfig(ctx, m.metric.Spec.RemoteClusterAccessRef, m.r)
 	if err != nil {
-		metric.SetConditions(common.ReadyFalse("QueryConfigCreationFailed", err.Error()))
-		metric.Status.Ready = v1alpha1.StatusStringFalse
-		return ctrl.Result{RequeueAfter: RequeueAfterError}, err
+		return nil, err
 	}
+	return []orc.QueryConfig{queryConfig}, nil
+}
 
-	metricClient, errCli := clientoptl.NewMetricClient(ctx, credentials)
-	if errCli != nil {
-		metric.SetConditions(common.ReadyFalse("OTLPClientCreationFailed", errCli.Error()))
-		metric.Status.Ready = v1alpha1.StatusStringFalse
-		l.Error(errCli, fmt.Sprintf("metric '%s' failed to create OTel client, re-queued for execution in %v minutes\n", metric.Spec.Name, RequeueAfterError))
-		return ctrl.Result{RequeueAfter: RequeueAfterError}, errCli
-	}
-	defer func() {
-		if err := metricClient.Close(ctx); err != nil {
-			l.Error(err, "Failed to close metric client during metric reconciliation", "metric", metric.Spec.Name)
+//nolint:gocyclo
+func (m *metricCollection) collect(ctx context.Context, c *collection) (ctrl.Result, error) {
+	metric := m.metric
+
+	// Missing permissions are reported before the first collection instead of the failed list
+	if needsAccessCheck(metric) {
+		errAccess := orc.CheckMetricAccess(ctx, c.queryConfigs[0], &metric.Spec)
+		var denied *orc.AccessDeniedError
+		if errors.As(errAccess, &denied) {
+			metric.SetConditions(common.ReadyFalse(orc.ReasonInsufficientPermissions, denied.Error()))
+			metric.Status.Ready = v1alpha1.StatusStringFalse
+			c.recorder.Eventf(metric, nil, "Warning", orc.ReasonInsufficientPermissions, c.action, denied.Error())
+			return ctrl.Result{RequeueAfter: c.failures.retryDelay()}, nil
 		}
-	}() // Ensure exporter is shut down
-
-	metricClient.SetMeter("metric")
+		if errAccess != nil {
+			// the collection reports a denied list as well
+			c.log.Error(errAccess, "unable to check the permissions of the metric", "metric", metric.Spec.Name)
+		}
+	}
 
-	gaugeMetric, errGauge := metricClient.NewMetric(metric.Spec.Name)
-	if errGauge != nil {
-		metric.SetConditions(common.ReadyFalse("MetricCreationFailed", errGauge.Error()))
-		metric.Status.Ready = v1alpha1.StatusStringFalse
-		l.Error(errGauge, fmt.Sprintf("metric '%s' failed to create OTel gauge, re-queued for execution in %v minutes\n", metric.Spec.Name, RequeueAfterError))
-		return ctrl.Result{RequeueAfter: RequeueAfterError}, errGauge
+	// changed static dimensions make a new series, so the values are exported even if they did not change
+	if metric.Status.StaticDimensionsHash != c.dimensions.hash() {
+		metric.Status.LastExport = nil
 	}
-	metricName := metric.Spec.Name
-	metricNamespace := metric.Namespace
-	gaugeMetric.SetPrometheusFunc(func(dims map[string]string, value int64) {
-		internalmetrics.RecordDataPoint(metricName, metricNamespace, dims, value)
+	results, res, err := c.monitor(ctx, m.r.Handlers, func(o *orc.Orchestrator) (*orc.Orchestrator, error) {
+		return o.WithMetric(*metric, c.gauge)
 	})
-	/*
-		2. Create a new orchestrator
-	*/
-	creds := common.DataSinkCredentials{}
-	if credentials != nil {
-		creds = *credentials
+	if err != nil {
+		return res, err
 	}
-	orchestrator, errOrch := orc.NewOrchestrator(creds, queryConfig).WithMetric(metric, gaugeMetric) // Pass gaugeMetric
-	if errOrch != nil {
-		metric.SetConditions(common.ReadyFalse("OrchestratorCreationFailed", errOrch.Error()))
-		metric.Status.Ready = v1alpha1.StatusStringFalse
-		l.Error(errOrch, "unable to create metric orchestrator monitor")
-		r.Recorder.Eventf(&metric, nil, "Warning", "OrchestratorCreation", "ReconcileMetric", "unable to create orchestrator")
-		return ctrl.Result{RequeueAfter: RequeueAfterError}, errOrch
+	result := results[0]
+
+	timeout := orc.PhaseTimeout(metric.Spec.Timeout)
+	timedOut := result.TimedOut
+	var errExport error
+	// samples are exported aggregated once the sampling window is complete
+	if !result.SampleOnly {
+		exportCtx, cancelExport := context.WithTimeout(ctx, timeout)
+		errExport = exportMetrics(exportCtx, c.client, &metric.Status.ExportStatus)
+		if exportCtx.Err() == context.DeadlineExceeded {
+			timedOut = append(timedOut, orc.CollectionPhaseExport)
+		}
+		cancelExport()
 	}
 
-	result, errMon := orchestrator.Handler.Monitor(ctx)
-
-	if errMon != nil {
-		metric.SetConditions(common.ReadyFalse("MonitoringFailed", errMon.Error()))
-		metric.Status.Ready = v1alpha1.StatusStringFalse
-		l.Error(errMon, fmt.Sprintf("metric '%s' re-queued for execution in %v minutes\n", metric.Spec.Name, RequeueAfterError))
-		return ctrl.Result{RequeueAfter: RequeueAfterError}, errMon
+	// snapshots are written once per export, with or without a data sink
+	if result.Snapshot != nil && !result.SampleOnly {
+		if errSnapshot := writeSnapshot(ctx, m.r.getClient(), metric, *result.Snapshot); errSnapshot != nil {
+			c.log.Error(errSnapshot, "unable to write the snapshot of the metric", "metric", metric.Spec.Name)
+			c.recorder.Eventf(metric, nil, "Warning", "SnapshotFailed", c.action, errSnapshot.Error())
+		}
 	}
 
-	errExport := metricClient.ExportMetrics(ctx)
+	c.recordResult(result)
+	c.setExported(errExport)
 
-	/*
-		3. Update the status of the metric with conditions and phase
-	*/
-	switch result.Phase {
-	case v1alpha1.PhaseActive:
-		metric.SetConditions(common.Available(result.Message))
-		r.Recorder.Eventf(&metric, nil, "Normal", "MetricAvailable", "ReconcileMetric", result.Message)
-	case v1alpha1.PhaseFailed:
-		l.Error(result.Error, result.Message, "reason", result.Reason)
-		metric.SetConditions(common.Error(result.Message))
-		r.Recorder.Eventf(&metric, nil, "Warning", "MetricFailed", "ReconcileMetric", result.Message)
-	case v1alpha1.PhasePending:
-		metric.SetConditions(common.Creating())
-		r.Recorder.Eventf(&metric, nil, "Normal", "MetricPending", "ReconcileMetric", result.Message)
+	switch result.Reason {
+	case orc.ReasonInsufficientPermissions:
+		metric.SetConditions(common.ReadyFalse(orc.ReasonInsufficientPermissions, result.Message))
+		metric.Status.Ready = v1alpha1.StatusStringFalse
+	case orc.ReasonTargetNotFound:
+		msg := result.Message
+		if metric.Status.TargetNotFoundCount+1 >= MaxTargetNotFound {
+			msg = fmt.Sprintf("%s, retrying at the interval of the metric after %d attempts", msg, metric.Status.TargetNotFoundCount+1)
+		}
+		metric.SetConditions(common.ReadyFalse(orc.ReasonTargetNotFound, msg))
+		metric.Status.Ready = v1alpha1.StatusStringFalse
 	}
 
-	cObs := result.Observation.(*v1alpha1.MetricObservation)
-
-	// Set Ready condition based on export result
-	if errExport != nil {
-		metric.SetConditions(common.ReadyFalse("MetricExportFailed", errExport.Error()))
+	// Report phases that timed out, the data collected until then has been exported
+	if len(timedOut) > 0 {
+		msg := fmt.Sprintf("collection phase(s) %s timed out after %v, partial results were exported", strings.Join(timedOut, ", "), timeout)
+		metric.SetConditions(common.ReadyFalse("CollectionTimeout", msg))
 		metric.Status.Ready = v1alpha1.StatusStringFalse
-		l.Error(errExport, fmt.Sprintf("metric '%s' failed to export, re-queued for execution in %v minutes\n", metric.Spec.Name, RequeueAfterError))
-	} else {
-		metric.SetConditions(common.ReadyTrue("Metric reconciled successfully"))
-		metric.Status.Ready = v1alpha1.StatusStringTrue
+		c.recorder.Eventf(metric, nil, "Warning", "CollectionTimeout", c.action, msg)
 	}
 
+	if result.Reason == orc.ReasonTargetNotFound {
+		metric.Status.TargetNotFoundCount++
+	} else {
+		metric.Status.TargetNotFoundCount = 0
+	}
+	observation := result.Observation.(*v1alpha1.MetricObservation)
 	metric.Status.Observation = v1alpha1.MetricObservation{
-		Timestamp:   result.Observation.GetTimestamp(),
-		LatestValue: cObs.LatestValue,
-		Dimensions:  cObs.Dimensions,
+		// Update LastReconcileTime
+		Timestamp:     metav1.Now(),
+		LatestValue:   observation.LatestValue,
+		Dimensions:    observation.Dimensions,
+		Series:        observation.Series,
+		OmittedSeries: observation.OmittedSeries,
 	}
 
-	// Update LastReconcileTime
-	metric.Status.Observation.Timestamp.Time = metav1.Now().Time
-
-	// Note: Status update is handled by the defer function at the beginning
-
-	/*
-		4. Requeue the metric after the frequency or after 2 minutes if an error occurred
-	*/
-	var requeueTime time.Duration
-	if result.Error != nil || errExport != nil { // Requeue faster on monitor or export error
-		requeueTime = RequeueAfterError
-	} else {
-		requeueTime = metric.Spec.Interval.Duration
+	if result.Phase == v1alpha1.PhaseActive {
+		metric.Status.RecordedSeries = result.RecordedSeries
+	}
+	// Remember the recorded values for metrics exported as delta or rate, the exported values for the OnChange
+	// export policies and the samples of the sampling window. Failed exports are retried against the previous ones,
+	// so the changes they missed are exported with the next successful export.
+	if result.Phase == v1alpha1.PhaseActive && errExport == nil {
+		metric.Status.Baseline = result.Baseline
+		metric.Status.LastExport = result.LastExport
+		metric.Status.SamplingWindow = result.SamplingWindow
 	}
 
-	l.Info(fmt.Sprintf("metric '%s' re-queued for execution in %v\n", metric.Spec.Name, requeueTime))
+	// Requeue the metric at the next run of its schedule, or with the backoff of its retry policy if an error occurred.
+	// A target kind that is still not served is not retried faster than the metric's interval.
+	targetNotFoundCapped := metric.Status.TargetNotFoundCount >= MaxTargetNotFound
+	nextRun := c.schedule.next(metric.Status.Observation.Timestamp.Time)
+	if (result.Error != nil && !targetNotFoundCapped) || errExport != nil || len(timedOut) > 0 {
+		nextRun = c.failures.retryAt(metric.Status.NextRunTime)
+	}
+	return c.requeue(nextRun), nil
+}
 
-	return ctrl.Result{
-		RequeueAfter: requeueTime,
-	}, nil
+// finalize exports a final zero for the series of the deleted metric before its finalizer is removed
+func (r *MetricReconciler) finalize(ctx context.Context, metric *v1alpha1.Metric, l logr.Logger) (ctrl.Result, error) {
+	return finalizeDeletedMetric(ctx, r.getClient(), r.Recorder, deletedMetric{
+		metric:          metric,
+		exporters:       r.Exporters,
+		name:            metric.Spec.Name,
+		description:     metric.Spec.Description,
+		unit:            metric.Spec.Unit,
+		meterName:       cmp.Or(metric.Spec.MeterName, "metric"),
+		scopeAttributes: v1alpha1.ScopeAttributesMap(metric.Spec.ScopeAttributes),
+		dataSinkRef:     metric.Spec.DataSinkRef,
+		collect: func(ctx context.Context, credentials common.DataSinkCredentials, gauge *clientoptl.Metric) error {
+			queryConfig, err := createQueryConfig(ctx, metric.Spec.RemoteClusterAccessRef, r)
+			if err != nil {
+				return err
+			}
+			// the final zero is exported regardless of the export policy and of an incomplete sampling window
+			final := metric.DeepCopy()
+			final.Status.LastExport = nil
+			if final.Spec.Sampling != nil {
+				final.Status.SamplingWindow = &v1alpha1.MetricSamplingWindow{}
+			}
+			orchestrator, err := orc.NewOrchestrator(credentials, queryConfig).WithHandlers(r.Handlers).WithMetric(*final, gauge)
+			if err != nil {
+				return err
+			}
+			result, err := orchestrator.Handler.Monitor(ctx)
+			if err != nil {
+				return err
+			}
+			return result.Error
+		},
+	}, l)
 }
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *MetricReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
-		For(&v1alpha1.Metric{}).
-		Complete(r)
+	b := ctrl.NewControllerManagedBy(mgr).
+		Named(MetricControllerName).
+		WithOptions(Controllers.forController(MetricControllerName)).
+		// metrics are enqueued with their priority, so important metrics are reconciled first
+		Watches(&v1alpha1.Metric{}, enqueueSelf())
+
+	b, err := watchStaticDimensionSources(mgr, b, &v1alpha1.Metric{}, func() client.ObjectList { return &v1alpha1.MetricList{} })
+	if err != nil {
+		return err
+	}
+	b, err = watchTargetKinds(mgr, b, &r.targetChanges)
+	if err != nil {
+		return err
+	}
+	return b.Complete(r)
 }
 
-func createQC(ctx context.Context, rcaRef *v1alpha1.RemoteClusterAccessRef, r InsightReconciler) (orc.QueryConfig, error) {
-	var queryConfig orc.QueryConfig
-	// Kubernetes client to the external cluster if defined
-	if rcaRef != nil {
-		qc, err := config.CreateExternalQueryConfig(ctx, rcaRef, r.getClient())
-		if err != nil {
-			return orc.QueryConfig{}, err
-		}
-		queryConfig = *qc
-	} else {
-		// local cluster name (where operator is deployed)
-		clusterName, _ := getClusterInfo(r.getRestConfig())
-		queryConfig = orc.QueryConfig{Client: r.getClient(), RestConfig: *r.getRestConfig(), ClusterName: &clusterName}
+// needsAccessCheck returns true if the metric has not been collected yet, or was not allowed to list its resources
+func needsAccessCheck(metric *v1alpha1.Metric) bool {
+	if metric.Status.Observation.Timestamp.IsZero() {
+		return true
 	}
-	return queryConfig, nil
+	ready := meta.FindStatusCondition(metric.Status.Conditions, v1alpha1.TypeReady)
+	return ready != nil && ready.Reason == orc.ReasonInsufficientPermissions
 }
@@ -17,11 +17,17 @@ limitations under the License.
 package controller
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
 
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/rest"
+
+	"github.com/openmcp-project/metrics-operator/api/v1alpha1"
+	"github.com/openmcp-project/metrics-operator/internal/common"
+	orc "github.com/openmcp-project/metrics-operator/internal/orchestrator"
 )
 
 func TestGetClusterInfo(t *testing.T) {
@@ -84,3 +90,21 @@ func TestGetClusterInfo(t *testing.T) {
 		})
 	}
 }
+
+func TestSamplesNote(t *testing.T) {
+	require.Equal(t, "sampled 2 matched resource(s): team-a/pod-1, team-a/pod-2", samplesNote([]string{"team-a/pod-1", "team-a/pod-2"}))
+
+	long := samplesNote([]string{strings.Repeat("a", maxEventNoteLength)})
+	require.Len(t, long, maxEventNoteLength)
+	require.True(t, strings.HasSuffix(long, "..."))
+}
+
+func TestNeedsAccessCheck(t *testing.T) {
+	collected := v1alpha1.MetricStatus{Observation: v1alpha1.MetricObservation{Timestamp: metav1.Now()}}
+	denied := *collected.DeepCopy()
+	denied.Conditions = []metav1.Condition{common.ReadyFalse(orc.ReasonInsufficientPermissions, "not allowed")}
+
+	require.True(t, needsAccessCheck(&v1alpha1.Metric{}))
+	require.False(t, needsAccessCheck(&v1alpha1.Metric{Status: collected}))
+	require.True(t, needsAccessCheck(&v1alpha1.Metric{Status: denied}))
+}
@@ -18,6 +18,11 @@ package controller
 
 import (
 	"context"
+	"os"
+	"path/filepath"
+	goruntime "runtime"
+	"slices"
+	"strings"
 	"testing"
 	"time"
 
@@ -25,11 +30,14 @@ import (
 	"k8s.io/client-go/tools/events"
 
 	"github.com/openmcp-project/metrics-operator/api/v1alpha1"
-	"github.com/openmcp-project/metrics-operator/internal/common"
+	"github.com/openmcp-project/metrics-operator/internal/clientoptl"
 	orc "github.com/openmcp-project/metrics-operator/internal/orchestrator"
 
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/version"
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/rest"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -39,94 +47,6 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 )
 
-// FakeObservation implements the ObservationImpl interface
-type FakeObservation struct {
-	timestamp   metav1.Time
-	latestValue string
-}
-
-func (f *FakeObservation) GetTimestamp() metav1.Time {
-	return f.timestamp
-}
-
-func (f *FakeObservation) GetValue() string {
-	return f.latestValue
-}
-
-// FakeMetricHandler implements the MetricHandler interface
-type FakeMetricHandler struct {
-	result orc.MonitorResult
-	err    error
-}
-
-func NewFakeMetricHandler(result orc.MonitorResult, err error) *FakeMetricHandler {
-	return &FakeMetricHandler{
-		result: result,
-		err:    err,
-	}
-}
-
-func (f *FakeMetricHandler) Monitor() (orc.MonitorResult, error) {
-	return f.result, f.err
-}
-
-// Let's take a completely different approach
-// Instead of trying to mock the orchestrator, let's mock the entire Reconcile method
-
-// TestMetricReconciler is a custom implementation for testing
-type TestMetricReconciler struct {
-	MetricReconciler
-	fakeHandler *FakeMetricHandler
-}
-
-// Override the Reconcile method to skip the real implementation
-func (r *TestMetricReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
-	// Get the metric
-	metric := v1alpha1.Metric{}
-	if err := r.getClient().Get(ctx, req.NamespacedName, &metric); err != nil {
-		return ctrl.Result{RequeueAfter: RequeueAfterError}, err
-	}
-
-	// Use our fake handler to get the result
-	result, err := r.fakeHandler.Monitor()
-	if err != nil {
-		return ctrl.Result{RequeueAfter: RequeueAfterError}, err
-	}
-
-	// Update the status
-	switch result.Phase {
-	case v1alpha1.PhaseActive:
-		metric.SetConditions(common.Available(result.Message))
-		r.Recorder.Eventf(&metric, nil, "Normal", "MetricAvailable", "ReconcileTestMetric", result.Message)
-	case v1alpha1.PhaseFailed:
-		metric.SetConditions(common.Error(result.Message))
-		r.Recorder.Eventf(&metric, nil, "Warning", "MetricFailed", "ReconcileTestMetric", result.Message)
-	case v1alpha1.PhasePending:
-		metric.SetConditions(common.Creating())
-		r.Recorder.Eventf(&metric, nil, "Normal", "MetricPending", "ReconcileTestMetric", result.Message)
-	}
-
-	metric.Status.Ready = v1alpha1.StatusFalse
-	if result.Phase == v1alpha1.PhaseActive {
-		metric.Status.Ready = v1alpha1.StatusTrue
-	}
-
-	metric.Status.Observation = v1alpha1.MetricObservation{
-		Timestamp:   result.Observation.GetTimestamp(),
-		LatestValue: result.Observation.GetValue(),
-	}
-
-	// Update the status
-	if err := r.getClient().Status().Update(ctx, &metric); err != nil {
-		return ctrl.Result{RequeueAfter: RequeueAfterError}, err
-	}
-
-	// Requeue
-	return ctrl.Result{
-		RequeueAfter: metric.Spec.Interval.Duration,
-	}, nil
-}
-
 var (
 	cfg       *rest.Config
 	k8sClient client.Client
@@ -141,7 +61,7 @@ func TestMetricController(t *testing.T) {
 	testEnv = &envtest.Environment{
 		CRDDirectoryPaths:     []string{"../../config/crd/bases"},
 		ErrorIfCRDPathMissing: true,
-		BinaryAssetsDirectory: "../../bin/k8s/1.27.1-darwin-arm64", // Use the binaries in the bin directory
+		BinaryAssetsDirectory: envtestBinaryAssetsDirectory(t),
 	}
 
 	var err error
@@ -166,6 +86,91 @@ func TestMetricController(t *testing.T) {
 	t.Run("TestReconcileMetricNotFound", testReconcileMetricNotFound)
 	t.Run("TestReconcileDataSinkNotFound", testReconcileSecretNotFound)
 	t.Run("TestDataSinkRefDefault", testDataSinkRefDefault)
+	t.Run("TestReconcileMetricExport", testReconcileMetricExport)
+}
+
+// envtestBinaryAssetsDirectory returns the directory of the envtest binaries for the platform the tests run on:
+// KUBEBUILDER_ASSETS if set, otherwise the latest version installed by setup-envtest into bin/k8s.
+// The test is skipped if there are no binaries for the platform.
+func envtestBinaryAssetsDirectory(t *testing.T) string {
+	if dir := os.Getenv("KUBEBUILDER_ASSETS"); dir != "" {
+		return dir
+	}
+
+	dirs, _ := filepath.Glob(filepath.Join("..", "..", "bin", "k8s", "*-"+goruntime.GOOS+"-"+goruntime.GOARCH))
+	var latest string
+	var latestVersion *version.Version
+	for _, dir := range dirs {
+		v, err := version.ParseGeneric(strings.TrimSuffix(filepath.Base(dir), "-"+goruntime.GOOS+"-"+goruntime.GOARCH))
+		if err != nil {
+			continue
+		}
+		if latestVersion == nil || v.GreaterThan(latestVersion) {
+			latest, latestVersion = dir, v
+		}
+	}
+	if latest == "" {
+		t.Skipf("no envtest binaries for %s/%s, set KUBEBUILDER_ASSETS or install them with setup-envtest into bin/k8s", goruntime.GOOS, goruntime.GOARCH)
+	}
+	return latest
+}
+
+// testReconcileMetricExport reconciles a Metric with the real reconciler and asserts the exported data points
+func testReconcileMetricExport(t *testing.T) {
+	ctx := context.Background()
+	t.Setenv("OPERATOR_CONFIG_NAMESPACE", "default")
+
+	dataSink := &v1alpha1.DataSink{
+		ObjectMeta: metav1.ObjectMeta{Name: "fake", Namespace: "default"},
+		Spec: v1alpha1.DataSinkSpec{
+			Connection: v1alpha1.Connection{Endpoint: "https://sink.example.com/otlp/v1/metrics"},
+		},
+	}
+	require.NoError(t, k8sClient.Create(ctx, dataSink))
+	defer func() {
+		require.NoError(t, k8sClient.Delete(ctx, dataSink))
+	}()
+
+	for _, name := range []string{"export-a", "export-b"} {
+		configMap := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Labels: map[string]string{"test": "export"}}}
+		require.NoError(t, k8sClient.Create(ctx, configMap))
+		defer func() {
+			require.NoError(t, k8sClient.Delete(ctx, configMap))
+		}()
+	}
+
+	metric := &v1alpha1.Metric{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-metric-export", Namespace: "default"},
+		Spec: v1alpha1.MetricSpec{
+			Name:          "configmaps",
+			Target:        v1alpha1.MetricTarget{GroupVersionKind: v1alpha1.GroupVersionKind{Kind: "ConfigMap", Version: "v1"}},
+			LabelSelector: "test=export",
+			Interval:      metav1.Duration{Duration: 5 * time.Minute},
+			DataSinkRef:   &v1alpha1.DataSinkReference{Name: "fake"},
+		},
+	}
+	require.NoError(t, k8sClient.Create(ctx, metric))
+	defer func() {
+		require.NoError(t, k8sClient.Delete(ctx, metric))
+	}()
+
+	exporter := clientoptl.NewFakeExporter()
+	reconciler := &MetricReconciler{
+		inCli:      k8sClient,
+		RestConfig: cfg,
+		Scheme:     scheme.Scheme,
+		Recorder:   events.NewFakeRecorder(10),
+		Exporters:  exporter.Factory(),
+	}
+
+	_, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: metric.Name, Namespace: metric.Namespace}})
+	require.NoError(t, err)
+
+	require.Equal(t, 1, exporter.Exports())
+	points := exporter.DataPoints("configmaps")
+	require.Len(t, points, 1)
+	require.Equal(t, int64(2), points[0].Value)
+	require.Equal(t, "ConfigMap", points[0].Dimensions["kind"])
 }
 
 func testDataSinkRefDefault(t *testing.T) {
@@ -249,10 +254,12 @@ func testReconcileSecretNotFound(t *testing.T) {
 		Spec: v1alpha1.MetricSpec{
 			Name:        "test-metric-no-datasink",
 			Description: "Test metric description",
-			Target: v1alpha1.GroupVersionKind{
-				Kind:    "Pod",
-				Group:   "",
-				Version: "v1",
+			Target: v1alpha1.MetricTarget{
+				GroupVersionKind: v1alpha1.GroupVersionKind{
+					Kind:    "Pod",
+					Group:   "",
+					Version: "v1",
+				},
 			},
 			Interval: metav1.Duration{Duration: 5 * time.Minute},
 		},
@@ -300,6 +307,18 @@ func testReconcileMetricHappyPath(t *testing.T) {
 	)
 
 	ctx := context.Background()
+	t.Setenv("OPERATOR_CONFIG_NAMESPACE", "default")
+
+	dataSink := &v1alpha1.DataSink{
+		ObjectMeta: metav1.ObjectMeta{Name: "happy-path", Namespace: "default"},
+		Spec: v1alpha1.DataSinkSpec{
+			Connection: v1alpha1.Connection{Endpoint: "https://sink.example.com/otlp/v1/metrics"},
+		},
+	}
+	require.NoError(t, k8sClient.Create(ctx, dataSink))
+	defer func() {
+		require.NoError(t, k8sClient.Delete(ctx, dataSink))
+	}()
 
 	// Create a test Metric
 	metric := &v1alpha1.Metric{
@@ -310,54 +329,46 @@ func testReconcileMetricHappyPath(t *testing.T) {
 		Spec: v1alpha1.MetricSpec{
 			Name:        "test-metric",
 			Description: "Test metric description",
-			Target: v1alpha1.GroupVersionKind{
-				Kind:    "Pod",
-				Group:   "",
-				Version: "v1",
+			Target: v1alpha1.MetricTarget{
+				GroupVersionKind: v1alpha1.GroupVersionKind{
+					Kind:    "Pod",
+					Group:   "",
+					Version: "v1",
+				},
 			},
-			Interval: metav1.Duration{Duration: 5 * time.Minute},
+			Interval:    metav1.Duration{Duration: 5 * time.Minute},
+			DataSinkRef: &v1alpha1.DataSinkReference{Name: "happy-path"},
 		},
 	}
 	err := k8sClient.Create(ctx, metric)
 	require.NoError(t, err)
 
-	// Set up fake implementations
-	timestamp := metav1.Now()
-	fakeObservation := &FakeObservation{
-		timestamp:   timestamp,
-		latestValue: "5",
-	}
+	// Clean up resources after test
+	defer func() {
+		err := k8sClient.Delete(ctx, metric)
+		require.NoError(t, err)
+	}()
 
-	fakeResult := orc.MonitorResult{
+	// The fake handler replaces the query of the pods
+	handler := orc.NewFakeHandler(orc.MonitorResult{
 		Phase:       v1alpha1.PhaseActive,
 		Reason:      "MonitoringActive",
 		Message:     "metric is monitoring resource '/v1, Kind=Pod'",
-		Observation: fakeObservation,
-		Error:       nil,
-	}
-
-	fakeHandler := NewFakeMetricHandler(fakeResult, nil)
+		Observation: &v1alpha1.MetricObservation{Timestamp: metav1.Now(), LatestValue: "5"},
+	})
 
 	// Create a recorder for events
 	recorder := events.NewFakeRecorder(10)
 
-	// Create a test reconciler with our fake handler
-	reconciler := &TestMetricReconciler{
-		MetricReconciler: MetricReconciler{
-			inCli:      k8sClient,
-			RestConfig: cfg,
-			Scheme:     scheme.Scheme,
-			Recorder:   recorder,
-		},
-		fakeHandler: fakeHandler,
+	reconciler := &MetricReconciler{
+		inCli:      k8sClient,
+		RestConfig: cfg,
+		Scheme:     scheme.Scheme,
+		Recorder:   recorder,
+		Exporters:  clientoptl.NewFakeExporter().Factory(),
+		Handlers:   handler.Factory(),
 	}
 
-	// Clean up resources after test
-	defer func() {
-		err := k8sClient.Delete(ctx, metric)
-		require.NoError(t, err)
-	}()
-
 	// Reconcile the Metric
 	req := ctrl.Request{
 		NamespacedName: types.NamespacedName{
@@ -369,7 +380,9 @@ func testReconcileMetricHappyPath(t *testing.T) {
 
 	// Verify the result
 	require.NoError(t, err)
-	require.Equal(t, 5*time.Minute, result.RequeueAfter)
+	require.Equal(t, []string{MetricName}, handler.Monitors())
+	require.Positive(t, result.RequeueAfter)
+	require.LessOrEqual(t, result.RequeueAfter, 5*time.Minute)
 
 	// Verify the Metric status was updated correctly
 	updatedMetric := &v1alpha1.Metric{}
@@ -377,24 +390,22 @@ func testReconcileMetricHappyPath(t *testing.T) {
 	require.NoError(t, err)
 
 	// Check status fields
-	require.Equal(t, "True", updatedMetric.Status.Ready)
+	require.Equal(t, v1alpha1.StatusStringTrue, updatedMetric.Status.Ready)
 	require.Equal(t, "5", updatedMetric.Status.Observation.LatestValue)
 
 	// Check conditions
-	require.GreaterOrEqual(t, len(updatedMetric.Status.Conditions), 1)
-	var availableCondition *metav1.Condition
-	for i := range updatedMetric.Status.Conditions {
-		if updatedMetric.Status.Conditions[i].Type == v1alpha1.TypeAvailable {
-			availableCondition = &updatedMetric.Status.Conditions[i]
-			break
-		}
-	}
+	availableCondition := meta.FindStatusCondition(updatedMetric.Status.Conditions, v1alpha1.TypeAvailable)
 	require.NotNil(t, availableCondition)
 	require.Equal(t, metav1.ConditionTrue, availableCondition.Status)
 	require.Equal(t, "MonitoringActive", availableCondition.Reason)
 	require.Equal(t, "metric is monitoring resource '/v1, Kind=Pod'", availableCondition.Message)
 
 	// Verify that events were recorded
-	event := <-recorder.Events
-	require.Contains(t, event, "MetricAvailable")
+	var recorded []string
+	for len(recorder.Events) > 0 {
+		recorded = append(recorded, <-recorder.Events)
+	}
+	require.True(t, slices.ContainsFunc(recorded, func(event string) bool {
+		return strings.Contains(event, "MetricAvailable")
+	}), "expected a MetricAvailable event, got %v", recorded)
 }
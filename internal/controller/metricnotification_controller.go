@@ -0,0 +1,225 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	"github.com/openmcp-project/metrics-operator/api/v1alpha1"
+	"github.com/openmcp-project/metrics-operator/internal/notification"
+)
+
+// MetricNotificationResyncInterval is how often the metrics are checked without changes to them,
+// so metrics that are no longer observed are notified as stale
+const MetricNotificationResyncInterval = time.Minute
+
+// metricNotificationRequest is the single request all changes to metrics are mapped to
+var metricNotificationRequest = types.NamespacedName{Name: "notifications"}
+
+// NewMetricNotificationReconciler creates a new MetricNotificationReconciler sending the CloudEvents with the sender
+func NewMetricNotificationReconciler(mgr ctrl.Manager, sender notification.Sender) *MetricNotificationReconciler {
+	return &MetricNotificationReconciler{
+		log: mgr.GetLogger().WithName("controllers").WithName("MetricNotification"),
+
+		inCli:  mgr.GetClient(),
+		Sender: sender,
+	}
+}
+
+// MetricNotificationReconciler sends CloudEvents when the Ready condition of a metric flips,
+// its latest value crosses a threshold or it becomes stale.
+// The state of the metrics when the operator starts is the baseline, it is not notified.
+type MetricNotificationReconciler struct {
+	log logr.Logger
+
+	inCli client.Client
+	// Sender sends the CloudEvents
+	Sender notification.Sender
+
+	mu sync.Mutex
+	// notified is the last notified state by metric, nil until the first reconcile took the baseline
+	notified map[string]notifiedState
+}
+
+func (r *MetricNotificationReconciler) getClient() client.Client {
+	return r.inCli
+}
+
+// notifiedState is the state of a metric the notifications are sent about
+type notifiedState struct {
+	ready     metav1.ConditionStatus
+	threshold notification.ThresholdState
+	stale     bool
+}
+
+// thresholdState returns on which side of the thresholds the value is, empty if there are no thresholds or no value
+func thresholdState(thresholds *v1alpha1.Thresholds, latestValue string) (notification.ThresholdState, *int64) {
+	value, err := strconv.ParseInt(latestValue, 10, 64)
+	if err != nil {
+		return "", nil
+	}
+	switch {
+	case thresholds == nil:
+		return "", &value
+	case thresholds.Above != nil && value > *thresholds.Above:
+		return notification.ThresholdAbove, &value
+	case thresholds.Below != nil && value < *thresholds.Below:
+		return notification.ThresholdBelow, &value
+	default:
+		return notification.ThresholdWithin, &value
+	}
+}
+
+// changedEvents returns the types of the events to send for the change of the state of a metric.
+// A new metric becoming ready or within its thresholds is not notified, neither are removed thresholds.
+func changedEvents(previous, current notifiedState) []string {
+	var eventTypes []string
+	if current.ready != previous.ready && (current.ready == metav1.ConditionFalse || previous.ready == metav1.ConditionFalse) {
+		eventTypes = append(eventTypes, notification.TypeReadyChanged)
+	}
+	if current.threshold != previous.threshold && current.threshold != "" &&
+		(previous.threshold != "" || current.threshold != notification.ThresholdWithin) {
+		eventTypes = append(eventTypes, notification.TypeThresholdCrossed)
+	}
+	if current.stale != previous.stale {
+		eventTypes = append(eventTypes, notification.TypeStaleChanged)
+	}
+	return eventTypes
+}
+
+// metricState returns the state of the metric and the data of its events.
+// While the Ready condition is unknown or there is no value, the previous state is kept.
+func metricState(m listedMetric, previous notifiedState, now time.Time) (notifiedState, notification.MetricData) {
+	threshold, value := thresholdState(m.Thresholds(), m.LatestValue())
+	current := notifiedState{ready: previous.ready, threshold: threshold, stale: m.health.stale(now)}
+	if value == nil {
+		current.threshold = previous.threshold
+	}
+	data := notification.MetricData{
+		Kind:      m.Kind(),
+		Namespace: m.Namespace(),
+		Name:      m.Name(),
+		Value:     value,
+		Threshold: current.threshold,
+		Stale:     current.stale,
+	}
+	if condition := m.ReadyCondition(); condition != nil {
+		if condition.Status != metav1.ConditionUnknown {
+			current.ready = condition.Status
+		}
+		data.Ready, data.Reason, data.Message = string(condition.Status), condition.Reason, condition.Message
+	}
+	if !m.health.lastExport.IsZero() {
+		data.LastObservation = &m.health.lastExport
+	}
+	return current, data
+}
+
+// sendChanges sends an event for each change between the previous and the current state of a metric
+func (r *MetricNotificationReconciler) sendChanges(ctx context.Context, previous, current notifiedState, data notification.MetricData, now time.Time) error {
+	for _, eventType := range changedEvents(previous, current) {
+		if err := r.Sender.Send(ctx, notification.NewMetricEvent(eventType, data, now)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// +kubebuilder:rbac:groups=metrics.openmcp.cloud,resources=metrics;managedmetrics;federatedmetrics;federatedmanagedmetrics;compositemetrics,verbs=get;list;watch
+
+// Reconcile compares the state of all metrics with the state last notified and sends a CloudEvent for each change.
+// A metric whose events could not be sent keeps its previous state, so the events are sent with the next reconcile.
+func (r *MetricNotificationReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	if req.NamespacedName != metricNotificationRequest {
+		return ctrl.Result{}, nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	listed, err := listMetrics(ctx, r.getClient())
+	if err != nil {
+		r.log.Error(err, "unable to list metrics")
+		return ctrl.Result{RequeueAfter: RequeueAfterError}, err
+	}
+
+	now := time.Now()
+	baseline := r.notified == nil
+	notified := make(map[string]notifiedState, len(listed))
+	var errs []error
+	for _, m := range listed {
+		key := m.Kind() + "/" + m.Namespace() + "/" + m.Name()
+		// previous is the zero state for metrics that were not seen before
+		previous, known := r.notified[key]
+		current, data := metricState(m, previous, now)
+		if baseline {
+			notified[key] = current
+			continue
+		}
+
+		if errSend := r.sendChanges(ctx, previous, current, data, now); errSend != nil {
+			r.log.Error(errSend, "unable to send CloudEvent", "metric", key)
+			errs = append(errs, errSend)
+			if known {
+				notified[key] = previous
+			}
+			continue
+		}
+		notified[key] = current
+	}
+	r.notified = notified
+
+	if len(errs) > 0 {
+		return ctrl.Result{RequeueAfter: RequeueAfterError}, errors.Join(errs...)
+	}
+	return ctrl.Result{RequeueAfter: MetricNotificationResyncInterval}, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *MetricNotificationReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	toNotifications := handler.EnqueueRequestsFromMapFunc(func(context.Context, client.Object) []reconcile.Request {
+		return []reconcile.Request{{NamespacedName: metricNotificationRequest}}
+	})
+
+	// the baseline is taken when the operator starts, even if there are no metrics
+	start := make(chan event.GenericEvent, 1)
+	start <- event.GenericEvent{Object: &v1alpha1.Metric{ObjectMeta: metav1.ObjectMeta{Name: metricNotificationRequest.Name}}}
+
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(MetricNotificationControllerName).
+		WithOptions(Controllers.forController(MetricNotificationControllerName)).
+		Watches(&v1alpha1.Metric{}, toNotifications).
+		Watches(&v1alpha1.ManagedMetric{}, toNotifications).
+		Watches(&v1alpha1.FederatedMetric{}, toNotifications).
+		Watches(&v1alpha1.FederatedManagedMetric{}, toNotifications).
+		Watches(&v1alpha1.CompositeMetric{}, toNotifications).
+		WatchesRawSource(source.Channel(start, &handler.EnqueueRequestForObject{})).
+		Complete(r)
+}
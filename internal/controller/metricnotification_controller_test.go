@@ -0,0 +1,164 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/openmcp-project/metrics-operator/api/v1alpha1"
+	"github.com/openmcp-project/metrics-operator/internal/notification"
+)
+
+// fakeSender records the CloudEvents instead of sending them
+type fakeSender struct {
+	events []notification.CloudEvent
+	err    error
+}
+
+func (s *fakeSender) Send(_ context.Context, event notification.CloudEvent) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.events = append(s.events, event)
+	return nil
+}
+
+func TestChangedEvents(t *testing.T) {
+	testCases := []struct {
+		name     string
+		previous notifiedState
+		current  notifiedState
+		expected []string
+	}{
+		{
+			name:    "NewMetricReady",
+			current: notifiedState{ready: metav1.ConditionTrue, threshold: notification.ThresholdWithin},
+		},
+		{
+			name:     "NewMetricFailing",
+			current:  notifiedState{ready: metav1.ConditionFalse},
+			expected: []string{notification.TypeReadyChanged},
+		},
+		{
+			name:     "NewMetricAboveThreshold",
+			current:  notifiedState{ready: metav1.ConditionTrue, threshold: notification.ThresholdAbove},
+			expected: []string{notification.TypeThresholdCrossed},
+		},
+		{
+			name:     "Recovered",
+			previous: notifiedState{ready: metav1.ConditionFalse, threshold: notification.ThresholdBelow, stale: true},
+			current:  notifiedState{ready: metav1.ConditionTrue, threshold: notification.ThresholdWithin},
+			expected: []string{notification.TypeReadyChanged, notification.TypeThresholdCrossed, notification.TypeStaleChanged},
+		},
+		{
+			name:     "ThresholdsRemoved",
+			previous: notifiedState{ready: metav1.ConditionTrue, threshold: notification.ThresholdAbove},
+			current:  notifiedState{ready: metav1.ConditionTrue},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, changedEvents(tc.previous, tc.current))
+		})
+	}
+}
+
+func TestMetricNotificationReconciler_Reconcile(t *testing.T) {
+	withScheduling(t, SchedulingOptions{})
+	ctx := context.Background()
+	now := time.Now()
+	metric := func(name string, ready metav1.ConditionStatus, value string) *v1alpha1.Metric {
+		return &v1alpha1.Metric{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: name, CreationTimestamp: metav1.NewTime(now.Add(-time.Hour))},
+			Spec: v1alpha1.MetricSpec{
+				Interval:   metav1.Duration{Duration: 10 * time.Minute},
+				Thresholds: &v1alpha1.Thresholds{Above: ptr.To[int64](10)},
+			},
+			Status: v1alpha1.MetricStatus{
+				Observation: v1alpha1.MetricObservation{Timestamp: metav1.NewTime(now.Add(-time.Minute)), LatestValue: value},
+				Conditions:  []metav1.Condition{{Type: v1alpha1.TypeReady, Status: ready, Reason: "MetricExported"}},
+			},
+		}
+	}
+
+	scheme := runtime.NewScheme()
+	require.NoError(t, v1alpha1.AddToScheme(scheme))
+	pods := metric("pods", metav1.ConditionTrue, "5")
+	cli := fake.NewClientBuilder().WithScheme(scheme).WithObjects(pods).Build()
+	sender := &fakeSender{}
+	r := &MetricNotificationReconciler{log: logr.Discard(), inCli: cli, Sender: sender}
+	request := ctrl.Request{NamespacedName: metricNotificationRequest}
+
+	// the first reconcile takes the baseline
+	result, err := r.Reconcile(ctx, request)
+	require.NoError(t, err)
+	require.Equal(t, MetricNotificationResyncInterval, result.RequeueAfter)
+	require.Empty(t, sender.events)
+
+	// the value crosses the threshold and the metric fails
+	pods.Status.Observation.LatestValue = "12"
+	pods.Status.Conditions[0].Status = metav1.ConditionFalse
+	pods.Status.Conditions[0].Reason = "MetricExportFailed"
+	require.NoError(t, cli.Update(ctx, pods))
+	// a new metric that is ready is not notified
+	require.NoError(t, cli.Create(ctx, metric("deployments", metav1.ConditionTrue, "1")))
+
+	_, err = r.Reconcile(ctx, request)
+	require.NoError(t, err)
+	require.Len(t, sender.events, 2)
+	require.Equal(t, notification.TypeReadyChanged, sender.events[0].Type)
+	require.Equal(t, notification.TypeThresholdCrossed, sender.events[1].Type)
+	require.Equal(t, "Metric/team-a/pods", sender.events[1].Subject)
+	require.Equal(t, notification.MetricData{
+		Kind: "Metric", Namespace: "team-a", Name: "pods",
+		Ready: "False", Reason: "MetricExportFailed",
+		Value: ptr.To[int64](12), Threshold: notification.ThresholdAbove,
+		LastObservation: sender.events[1].Data.LastObservation,
+	}, sender.events[1].Data)
+
+	// without changes nothing is sent
+	sender.events = nil
+	_, err = r.Reconcile(ctx, request)
+	require.NoError(t, err)
+	require.Empty(t, sender.events)
+
+	// events that could not be sent are sent with the next reconcile
+	pods.Status.Conditions[0].Status = metav1.ConditionTrue
+	require.NoError(t, cli.Update(ctx, pods))
+	sender.err = errors.New("connection refused")
+	_, err = r.Reconcile(ctx, request)
+	require.EqualError(t, err, "connection refused")
+
+	sender.err = nil
+	_, err = r.Reconcile(ctx, request)
+	require.NoError(t, err)
+	require.Len(t, sender.events, 1)
+	require.Equal(t, notification.TypeReadyChanged, sender.events[0].Type)
+	require.Equal(t, "True", sender.events[0].Data.Ready)
+}
@@ -0,0 +1,261 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"strings"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/events"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	"github.com/openmcp-project/metrics-operator/api/v1alpha1"
+	"github.com/openmcp-project/metrics-operator/internal/common"
+)
+
+// NewMetricSetReconciler creates a new MetricSetReconciler
+func NewMetricSetReconciler(mgr ctrl.Manager) *MetricSetReconciler {
+	return &MetricSetReconciler{
+		log: mgr.GetLogger().WithName("controllers").WithName("MetricSet"),
+
+		inCli:    mgr.GetClient(),
+		Scheme:   mgr.GetScheme(),
+		Recorder: SharedEventThrottle.Recorder(mgr.GetEventRecorder("metricset-controller")),
+	}
+}
+
+// MetricSetReconciler reconciles a MetricSet object
+type MetricSetReconciler struct {
+	log logr.Logger
+
+	inCli    client.Client
+	Scheme   *runtime.Scheme
+	Recorder events.EventRecorder
+}
+
+func (r *MetricSetReconciler) getClient() client.Client {
+	return r.inCli
+}
+
+// expandMetricSet returns the Metrics generated from the template of the MetricSet, one per target
+func expandMetricSet(set *v1alpha1.MetricSet) []v1alpha1.Metric {
+	metrics := make([]v1alpha1.Metric, 0, len(set.Spec.Targets))
+	for _, target := range set.Spec.Targets {
+		spec := *set.Spec.Template.Spec.DeepCopy()
+		spec.Name = cmp.Or(spec.Name, set.Name) + "-" + target.Name
+		if target.Kind != "" {
+			spec.Target.GroupVersionKind = target.GroupVersionKind
+		}
+		if len(target.Namespaces) > 0 {
+			spec.Target.Namespaces = target.Namespaces
+			spec.Target.NamespaceSelector = nil
+		}
+
+		labels := maps.Clone(set.Spec.Template.Labels)
+		if labels == nil {
+			labels = map[string]string{}
+		}
+		labels[v1alpha1.MetricSetLabel] = set.Name
+
+		metrics = append(metrics, v1alpha1.Metric{
+			ObjectMeta: metav1.ObjectMeta{Namespace: set.Namespace, Name: set.Name + "-" + target.Name, Labels: labels},
+			Spec:       spec,
+		})
+	}
+	return metrics
+}
+
+// rollUpMetricSet sets the status of the MetricSet from the readiness of the generated Metrics
+func rollUpMetricSet(set *v1alpha1.MetricSet, metrics []v1alpha1.Metric) {
+	var notReady []string
+	for _, metric := range metrics {
+		if metric.Status.Ready != v1alpha1.StatusStringTrue {
+			notReady = append(notReady, metric.Name)
+		}
+	}
+	set.Status.Metrics = len(metrics)
+	set.Status.ReadyMetrics = len(metrics) - len(notReady)
+
+	if len(notReady) > 0 {
+		set.SetConditions(common.ReadyFalse("MetricsNotReady", fmt.Sprintf("%d of %d metrics are not ready: %s", len(notReady), len(metrics), strings.Join(notReady, ", "))))
+		set.Status.Ready = v1alpha1.StatusStringFalse
+		return
+	}
+	set.SetConditions(common.ReadyTrue(fmt.Sprintf("all %d metrics are ready", len(metrics))))
+	set.Status.Ready = v1alpha1.StatusStringTrue
+}
+
+// +kubebuilder:rbac:groups=metrics.openmcp.cloud,resources=metricsets,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=metrics.openmcp.cloud,resources=metricsets/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=metrics.openmcp.cloud,resources=metricsets/finalizers,verbs=update
+// +kubebuilder:rbac:groups=metrics.openmcp.cloud,resources=metrics,verbs=get;list;watch;create;update;patch;delete
+
+// Reconcile generates the Metrics of a MetricSet and rolls up their readiness
+func (r *MetricSetReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	l := r.log.WithValues("namespace", req.Namespace, "name", req.Name)
+
+	l.V(1).Info("Reconciling MetricSet")
+
+	set := v1alpha1.MetricSet{}
+	if errLoad := r.getClient().Get(ctx, req.NamespacedName, &set); errLoad != nil {
+		if apierrors.IsNotFound(errLoad) {
+			// the generated Metrics are deleted by the garbage collector
+			l.Info("MetricSet not found")
+			return ctrl.Result{}, nil
+		}
+		l.Error(errLoad, "unable to fetch MetricSet")
+		return ctrl.Result{RequeueAfter: RequeueAfterError}, errLoad
+	}
+
+	// Defer status update to ensure it's always called
+	patchStatus := statusPatch(r.getClient(), &set)
+	defer func() {
+		if err := patchStatus(ctx); err != nil {
+			l.Error(err, "Failed to update MetricSet status")
+		}
+	}()
+
+	/*
+		1. Create or update the Metrics of the targets
+	*/
+	desired := expandMetricSet(&set)
+	generated := make([]v1alpha1.Metric, 0, len(desired))
+	names := make(map[string]bool, len(desired))
+	var errs []error
+	for _, d := range desired {
+		names[d.Name] = true
+		metric := v1alpha1.Metric{ObjectMeta: metav1.ObjectMeta{Namespace: d.Namespace, Name: d.Name}}
+		_, err := createOrUpdateGenerated(ctx, r.getClient(), &metric, func() error {
+			// labels set by others, e.g. by a GitOps tool, are kept
+			if metric.Labels == nil {
+				metric.Labels = make(map[string]string, len(d.Labels))
+			}
+			maps.Copy(metric.Labels, d.Labels)
+			metric.Spec = d.Spec
+			return controllerutil.SetControllerReference(&set, &metric, r.Scheme)
+		})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("metric '%s': %w", d.Name, err))
+			continue
+		}
+		generated = append(generated, metric)
+	}
+
+	/*
+		2. Delete the Metrics of targets that were removed
+	*/
+	existing := v1alpha1.MetricList{}
+	if err := r.getClient().List(ctx, &existing, client.InNamespace(set.Namespace), client.MatchingLabels{v1alpha1.MetricSetLabel: set.Name}); err != nil {
+		errs = append(errs, fmt.Errorf("failed to list the generated metrics: %w", err))
+	}
+	for i := range existing.Items {
+		metric := &existing.Items[i]
+		if names[metric.Name] || !metav1.IsControlledBy(metric, &set) {
+			continue
+		}
+		if err := r.getClient().Delete(ctx, metric); client.IgnoreNotFound(err) != nil {
+			errs = append(errs, fmt.Errorf("metric '%s': %w", metric.Name, err))
+			continue
+		}
+		l.Info("deleted metric of removed target", "metric", metric.Name)
+	}
+
+	/*
+		3. Roll up the readiness of the generated Metrics
+	*/
+	rollUpMetricSet(&set, generated)
+	if err := errors.Join(errs...); err != nil {
+		set.SetConditions(common.ReadyFalse("MetricGenerationFailed", err.Error()))
+		set.Status.Ready = v1alpha1.StatusStringFalse
+		r.Recorder.Eventf(&set, nil, "Warning", "MetricGenerationFailed", "ReconcileMetricSet", err.Error())
+		return ctrl.Result{RequeueAfter: RequeueAfterError}, err
+	}
+
+	return ctrl.Result{}, nil
+}
+
+// createOrUpdateGenerated creates or updates an object generated from a template like controllerutil.CreateOrUpdate.
+// The API server defaults the fields the template leaves empty, so the mutated object differs from the stored one
+// on every reconcile. The update is sent as a dry run first and only stored if the defaulted object differs,
+// otherwise every reconcile would update all generated objects and trigger another reconcile of their owner.
+func createOrUpdateGenerated(ctx context.Context, c client.Client, obj client.Object, mutate controllerutil.MutateFn) (controllerutil.OperationResult, error) {
+	if err := c.Get(ctx, client.ObjectKeyFromObject(obj), obj); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return controllerutil.OperationResultNone, err
+		}
+		if err := mutate(); err != nil {
+			return controllerutil.OperationResultNone, err
+		}
+		if err := c.Create(ctx, obj); err != nil {
+			return controllerutil.OperationResultNone, err
+		}
+		return controllerutil.OperationResultCreated, nil
+	}
+
+	existing := obj.DeepCopyObject()
+	if err := mutate(); err != nil {
+		return controllerutil.OperationResultNone, err
+	}
+	if equality.Semantic.DeepEqual(existing, obj) {
+		return controllerutil.OperationResultNone, nil
+	}
+	if err := c.Update(ctx, obj, client.DryRunAll); err != nil {
+		return controllerutil.OperationResultNone, err
+	}
+	if equality.Semantic.DeepEqual(existing, obj) {
+		return controllerutil.OperationResultNone, nil
+	}
+	if err := c.Update(ctx, obj); err != nil {
+		return controllerutil.OperationResultNone, err
+	}
+	return controllerutil.OperationResultUpdated, nil
+}
+
+// metricReadyChanged passes the updates changing the readiness of a Metric
+var metricReadyChanged = predicate.Funcs{
+	UpdateFunc: func(e event.UpdateEvent) bool {
+		oldMetric, okOld := e.ObjectOld.(*v1alpha1.Metric)
+		newMetric, okNew := e.ObjectNew.(*v1alpha1.Metric)
+		return okOld && okNew && oldMetric.Status.Ready != newMetric.Status.Ready
+	},
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *MetricSetReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(MetricSetControllerName).
+		WithOptions(Controllers.forController(MetricSetControllerName)).
+		// status updates of the MetricSet itself need no reconcile
+		For(&v1alpha1.MetricSet{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		// the readiness of the generated Metrics is rolled up into the status of the MetricSet
+		Owns(&v1alpha1.Metric{}, builder.WithPredicates(predicate.Or[client.Object](predicate.GenerationChangedPredicate{}, metricReadyChanged))).
+		Complete(r)
+}
@@ -0,0 +1,177 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/events"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	"github.com/openmcp-project/metrics-operator/api/v1alpha1"
+)
+
+func podsMetricSet() *v1alpha1.MetricSet {
+	return &v1alpha1.MetricSet{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "counts", UID: "set-uid"},
+		Spec: v1alpha1.MetricSetSpec{
+			Template: v1alpha1.MetricTemplate{
+				Labels: map[string]string{"team": "a"},
+				Spec: v1alpha1.MetricSpec{
+					Name: "resources",
+					Target: v1alpha1.MetricTarget{
+						GroupVersionKind:  v1alpha1.GroupVersionKind{Kind: "Pod", Version: "v1"},
+						NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"team": "a"}},
+					},
+				},
+			},
+			Targets: []v1alpha1.MetricSetTarget{
+				{Name: "deployments", GroupVersionKind: v1alpha1.GroupVersionKind{Kind: "Deployment", Group: "apps", Version: "v1"}},
+				{Name: "pods-prod", Namespaces: []string{"prod"}},
+			},
+		},
+	}
+}
+
+func TestExpandMetricSet(t *testing.T) {
+	metrics := expandMetricSet(podsMetricSet())
+	require.Len(t, metrics, 2)
+
+	deployments := metrics[0]
+	require.Equal(t, "counts-deployments", deployments.Name)
+	require.Equal(t, "team-a", deployments.Namespace)
+	require.Equal(t, map[string]string{"team": "a", v1alpha1.MetricSetLabel: "counts"}, deployments.Labels)
+	require.Equal(t, "resources-deployments", deployments.Spec.Name)
+	require.Equal(t, v1alpha1.GroupVersionKind{Kind: "Deployment", Group: "apps", Version: "v1"}, deployments.Spec.Target.GroupVersionKind)
+	require.NotNil(t, deployments.Spec.Target.NamespaceSelector, "the namespaces of the template are kept")
+
+	pods := metrics[1]
+	require.Equal(t, "resources-pods-prod", pods.Spec.Name)
+	require.Equal(t, v1alpha1.GroupVersionKind{Kind: "Pod", Version: "v1"}, pods.Spec.Target.GroupVersionKind, "the kind of the template is kept")
+	require.Equal(t, []string{"prod"}, pods.Spec.Target.Namespaces)
+	require.Nil(t, pods.Spec.Target.NamespaceSelector)
+}
+
+func TestMetricSetReconciler_Reconcile(t *testing.T) {
+	set := podsMetricSet()
+	controlled := []metav1.OwnerReference{{
+		APIVersion: v1alpha1.GroupVersion.String(), Kind: "MetricSet", Name: "counts", UID: "set-uid", Controller: ptr.To(true),
+	}}
+	// a ready Metric of an existing target and a Metric of a removed target
+	ready := &v1alpha1.Metric{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "counts-deployments", Labels: map[string]string{v1alpha1.MetricSetLabel: "counts", "app.kubernetes.io/managed-by": "argocd"}, OwnerReferences: controlled},
+		Status:     v1alpha1.MetricStatus{Ready: v1alpha1.StatusStringTrue},
+	}
+	removed := &v1alpha1.Metric{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "counts-services", Labels: map[string]string{v1alpha1.MetricSetLabel: "counts"}, OwnerReferences: controlled},
+	}
+
+	scheme := runtime.NewScheme()
+	require.NoError(t, v1alpha1.AddToScheme(scheme))
+	cli := fake.NewClientBuilder().WithScheme(scheme).
+		WithObjects(set, ready, removed).
+		WithStatusSubresource(set, ready).
+		Build()
+	r := &MetricSetReconciler{log: logr.Discard(), inCli: cli, Scheme: scheme, Recorder: events.NewFakeRecorder(10)}
+
+	_, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "team-a", Name: "counts"}})
+	require.NoError(t, err)
+
+	metrics := v1alpha1.MetricList{}
+	require.NoError(t, cli.List(context.Background(), &metrics, client.InNamespace("team-a")))
+	names := make([]string, 0, len(metrics.Items))
+	for _, metric := range metrics.Items {
+		names = append(names, metric.Name)
+		require.True(t, metav1.IsControlledBy(&metric, set), "metric %s is owned by the set", metric.Name)
+	}
+	require.ElementsMatch(t, []string{"counts-deployments", "counts-pods-prod"}, names)
+
+	// labels of the template are added to the labels set by others
+	updatedMetric := v1alpha1.Metric{}
+	require.NoError(t, cli.Get(context.Background(), types.NamespacedName{Namespace: "team-a", Name: "counts-deployments"}, &updatedMetric))
+	require.Equal(t, "argocd", updatedMetric.Labels["app.kubernetes.io/managed-by"])
+	require.Equal(t, "counts", updatedMetric.Labels[v1alpha1.MetricSetLabel])
+	require.Equal(t, "a", updatedMetric.Labels["team"])
+
+	updated := v1alpha1.MetricSet{}
+	require.NoError(t, cli.Get(context.Background(), types.NamespacedName{Namespace: "team-a", Name: "counts"}, &updated))
+	require.Equal(t, 2, updated.Status.Metrics)
+	require.Equal(t, 1, updated.Status.ReadyMetrics)
+	require.Equal(t, v1alpha1.StatusStringFalse, updated.Status.Ready)
+	condition := meta.FindStatusCondition(updated.Status.Conditions, v1alpha1.TypeReady)
+	require.NotNil(t, condition)
+	require.Equal(t, "MetricsNotReady", condition.Reason)
+	require.Equal(t, "1 of 2 metrics are not ready: counts-pods-prod", condition.Message)
+}
+
+func TestMetricSetReconciler_Reconcile_defaulted(t *testing.T) {
+	set := podsMetricSet()
+	// the API server defaults the priority the template leaves empty
+	defaulting := func(obj client.Object) {
+		if metric, ok := obj.(*v1alpha1.Metric); ok && metric.Spec.Priority == "" {
+			metric.Spec.Priority = v1alpha1.PriorityNormal
+		}
+	}
+	updates := 0
+	scheme := runtime.NewScheme()
+	require.NoError(t, v1alpha1.AddToScheme(scheme))
+	cli := fake.NewClientBuilder().WithScheme(scheme).
+		WithObjects(set).
+		WithStatusSubresource(set).
+		WithInterceptorFuncs(interceptor.Funcs{
+			Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+				defaulting(obj)
+				return c.Create(ctx, obj, opts...)
+			},
+			Update: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.UpdateOption) error {
+				defaulting(obj)
+				if !slices.Contains((&client.UpdateOptions{}).ApplyOptions(opts).DryRun, metav1.DryRunAll) {
+					updates++
+				}
+				return c.Update(ctx, obj, opts...)
+			},
+		}).
+		Build()
+	r := &MetricSetReconciler{log: logr.Discard(), inCli: cli, Scheme: scheme, Recorder: events.NewFakeRecorder(10)}
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "team-a", Name: "counts"}}
+
+	_, err := r.Reconcile(context.Background(), req)
+	require.NoError(t, err)
+	_, err = r.Reconcile(context.Background(), req)
+	require.NoError(t, err)
+	require.Zero(t, updates, "the generated metrics are not updated if they only differ by the defaults")
+
+	require.NoError(t, cli.Get(context.Background(), req.NamespacedName, set))
+	set.Spec.Template.Spec.Description = "changed"
+	require.NoError(t, cli.Update(context.Background(), set))
+	updates = 0
+	_, err = r.Reconcile(context.Background(), req)
+	require.NoError(t, err)
+	require.Equal(t, 2, updates, "a changed template updates the generated metrics")
+}
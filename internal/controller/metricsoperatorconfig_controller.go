@@ -0,0 +1,239 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	"github.com/openmcp-project/metrics-operator/api/v1alpha1"
+	"github.com/openmcp-project/metrics-operator/internal/clientoptl"
+	"github.com/openmcp-project/metrics-operator/internal/common"
+	"github.com/openmcp-project/metrics-operator/internal/orchestrator"
+)
+
+// settingsMu guards Scheduling, ErrorRequeue, Variables and DefaultDataSink,
+// which are changed by the MetricsOperatorConfig while the reconcilers read them
+var settingsMu sync.RWMutex
+
+// LogLevel is the level of the logger of the operator, it is changed at runtime by the MetricsOperatorConfig
+var LogLevel = zap.NewAtomicLevelAt(zapcore.DebugLevel)
+
+// OperatorSettings are the global settings of the operator. They are given by flags when the operator starts
+// and overridden at runtime by the MetricsOperatorConfig.
+type OperatorSettings struct {
+	Scheduling      SchedulingOptions
+	ErrorRequeue    ErrorRequeueOptions
+	Variables       map[string]string
+	DefaultDataSink string
+	LogLevel        zapcore.Level
+
+	CollectionTimeout        time.Duration
+	EventInterval            time.Duration
+	ManagedCacheTTL          time.Duration
+	DataSinkFailureThreshold int
+	DataSinkOpenDuration     time.Duration
+}
+
+// ApplySettings makes the settings effective for all reconcilers, collections that are running keep the settings they started with
+func ApplySettings(s OperatorSettings) {
+	settingsMu.Lock()
+	Scheduling = SchedulingOptions{JitterPercent: min(max(s.Scheduling.JitterPercent, 0), 100), StartupSpread: s.Scheduling.StartupSpread}
+	ErrorRequeue = ErrorRequeueOptions{BaseDelay: s.ErrorRequeue.BaseDelay, MaxDelay: max(s.ErrorRequeue.MaxDelay, s.ErrorRequeue.BaseDelay)}
+	Variables = s.Variables
+	DefaultDataSink = s.DefaultDataSink
+	settingsMu.Unlock()
+
+	LogLevel.SetLevel(s.LogLevel)
+	orchestrator.SetDefaultPhaseTimeout(s.CollectionTimeout)
+	orchestrator.SharedManagedCache.SetTTL(s.ManagedCacheTTL)
+	SharedEventThrottle.SetInterval(s.EventInterval)
+	clientoptl.SharedCircuitBreakers.Configure(s.DataSinkFailureThreshold, s.DataSinkOpenDuration)
+}
+
+// override returns the settings with the settings of the spec that are set, or an error if one of them is invalid
+//
+//nolint:gocyclo
+func (s OperatorSettings) override(spec v1alpha1.MetricsOperatorConfigSpec) (OperatorSettings, error) {
+	durations := map[string]*metav1.Duration{
+		"errorRequeueBaseDelay": spec.ErrorRequeueBaseDelay,
+		"errorRequeueMaxDelay":  spec.ErrorRequeueMaxDelay,
+		"eventInterval":         spec.EventInterval,
+		"managedCacheTTL":       spec.ManagedCacheTTL,
+		"dataSinkOpenDuration":  spec.DataSinkOpenDuration,
+	}
+	for field, d := range durations {
+		if d != nil && d.Duration < 0 {
+			return s, fmt.Errorf("%s must not be negative", field)
+		}
+	}
+	if spec.CollectionTimeout != nil && spec.CollectionTimeout.Duration <= 0 {
+		return s, fmt.Errorf("collectionTimeout must be positive")
+	}
+	for name := range spec.Variables {
+		if !validVariableName(name) {
+			return s, fmt.Errorf("invalid variable name '%s'", name)
+		}
+	}
+
+	if spec.JitterPercent != nil {
+		s.Scheduling.JitterPercent = int(*spec.JitterPercent)
+	}
+	if spec.ErrorRequeueBaseDelay != nil {
+		s.ErrorRequeue.BaseDelay = spec.ErrorRequeueBaseDelay.Duration
+	}
+	if spec.ErrorRequeueMaxDelay != nil {
+		s.ErrorRequeue.MaxDelay = spec.ErrorRequeueMaxDelay.Duration
+	}
+	if spec.CollectionTimeout != nil {
+		s.CollectionTimeout = spec.CollectionTimeout.Duration
+	}
+	if spec.EventInterval != nil {
+		s.EventInterval = spec.EventInterval.Duration
+	}
+	if spec.ManagedCacheTTL != nil {
+		s.ManagedCacheTTL = spec.ManagedCacheTTL.Duration
+	}
+	if spec.DataSinkFailureThreshold != nil {
+		s.DataSinkFailureThreshold = int(*spec.DataSinkFailureThreshold)
+	}
+	if spec.DataSinkOpenDuration != nil {
+		s.DataSinkOpenDuration = spec.DataSinkOpenDuration.Duration
+	}
+	if spec.DefaultDataSink != "" {
+		s.DefaultDataSink = spec.DefaultDataSink
+	}
+	if spec.LogLevel != "" {
+		logLevel, err := ParseLogLevel(spec.LogLevel)
+		if err != nil {
+			return s, err
+		}
+		s.LogLevel = logLevel
+	}
+	if len(spec.Variables) > 0 {
+		variables := maps.Clone(s.Variables)
+		if variables == nil {
+			variables = make(map[string]string, len(spec.Variables))
+		}
+		maps.Copy(variables, spec.Variables)
+		s.Variables = variables
+	}
+	return s, nil
+}
+
+// ParseLogLevel parses a log level like --zap-log-level, debug, info, error or a verbosity greater than 0
+func ParseLogLevel(s string) (zapcore.Level, error) {
+	switch s {
+	case "debug":
+		return zapcore.DebugLevel, nil
+	case "info":
+		return zapcore.InfoLevel, nil
+	case "error":
+		return zapcore.ErrorLevel, nil
+	}
+	verbosity, err := strconv.ParseInt(s, 10, 8)
+	if err != nil || verbosity < 1 {
+		return 0, fmt.Errorf("invalid log level '%s'", s)
+	}
+	return zapcore.Level(-verbosity), nil
+}
+
+// NewMetricsOperatorConfigReconciler creates a new MetricsOperatorConfigReconciler, the settings given by the flags
+// are restored when the MetricsOperatorConfig is deleted
+func NewMetricsOperatorConfigReconciler(mgr ctrl.Manager, defaults OperatorSettings) *MetricsOperatorConfigReconciler {
+	return &MetricsOperatorConfigReconciler{
+		log: mgr.GetLogger().WithName("controllers").WithName("MetricsOperatorConfig"),
+
+		inCli:    mgr.GetClient(),
+		defaults: defaults,
+		apply:    ApplySettings,
+	}
+}
+
+// MetricsOperatorConfigReconciler applies the settings of the MetricsOperatorConfig at runtime
+type MetricsOperatorConfigReconciler struct {
+	log logr.Logger
+
+	inCli    client.Client
+	defaults OperatorSettings
+	apply    func(OperatorSettings)
+}
+
+// +kubebuilder:rbac:groups=metrics.openmcp.cloud,resources=metricsoperatorconfigs,verbs=get;list;watch
+// +kubebuilder:rbac:groups=metrics.openmcp.cloud,resources=metricsoperatorconfigs/status,verbs=get;update;patch
+
+// Reconcile applies the settings of the MetricsOperatorConfig on top of the settings given by the flags
+func (r *MetricsOperatorConfigReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	if req.Name != v1alpha1.MetricsOperatorConfigName {
+		return ctrl.Result{}, nil
+	}
+	l := r.log.WithValues("name", req.Name)
+
+	config := v1alpha1.MetricsOperatorConfig{}
+	if errLoad := r.inCli.Get(ctx, req.NamespacedName, &config); errLoad != nil {
+		if apierrors.IsNotFound(errLoad) {
+			l.Info("MetricsOperatorConfig deleted, restoring the settings of the flags")
+			r.apply(r.defaults)
+			return ctrl.Result{}, nil
+		}
+		l.Error(errLoad, "unable to fetch MetricsOperatorConfig")
+		return ctrl.Result{RequeueAfter: RequeueAfterError}, errLoad
+	}
+
+	patchStatus := statusPatch(r.inCli, &config)
+	defer func() {
+		if err := patchStatus(ctx); err != nil {
+			l.Error(err, "Failed to update MetricsOperatorConfig status")
+		}
+	}()
+
+	// an invalid config keeps the settings applied last, so a typo does not reset the operator to its flags
+	settings, err := r.defaults.override(config.Spec)
+	if err != nil {
+		config.SetConditions(common.ReadyFalse("InvalidConfig", err.Error()))
+		return ctrl.Result{}, nil
+	}
+	r.apply(settings)
+	l.Info("applied MetricsOperatorConfig", "generation", config.Generation)
+	config.Status.ObservedGeneration = config.Generation
+	config.SetConditions(common.ReadyTrue("settings applied"))
+	return ctrl.Result{}, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *MetricsOperatorConfigReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(MetricsOperatorConfigControllerName).
+		WithOptions(Controllers.forController(MetricsOperatorConfigControllerName)).
+		// the status updates of the controller itself need no reconcile
+		For(&v1alpha1.MetricsOperatorConfig{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Complete(r)
+}
@@ -0,0 +1,178 @@
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zapcore"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/openmcp-project/metrics-operator/api/v1alpha1"
+)
+
+var flagSettings = OperatorSettings{
+	Scheduling:               SchedulingOptions{JitterPercent: DefaultJitterPercent, StartupSpread: DefaultStartupSpread},
+	ErrorRequeue:             ErrorRequeueOptions{BaseDelay: RequeueAfterError, MaxDelay: DefaultErrorRequeueMaxDelay},
+	Variables:                map[string]string{"CLUSTER_NAME": "prod-eu", "LANDSCAPE": "live"},
+	DefaultDataSink:          DefaultDataSinkName,
+	CollectionTimeout:        time.Minute,
+	EventInterval:            DefaultEventInterval,
+	DataSinkFailureThreshold: 5,
+	DataSinkOpenDuration:     time.Minute,
+}
+
+func TestOperatorSettingsOverride(t *testing.T) {
+	testCases := []struct {
+		name    string
+		spec    v1alpha1.MetricsOperatorConfigSpec
+		want    func(s *OperatorSettings)
+		wantErr string
+	}{
+		{
+			name: "Empty",
+			want: func(*OperatorSettings) {},
+		},
+		{
+			name: "Overrides",
+			spec: v1alpha1.MetricsOperatorConfigSpec{
+				JitterPercent:            ptr.To[int32](0),
+				ErrorRequeueMaxDelay:     &metav1.Duration{Duration: time.Hour},
+				CollectionTimeout:        &metav1.Duration{Duration: 2 * time.Minute},
+				DataSinkFailureThreshold: ptr.To[int32](0),
+				DefaultDataSink:          "central",
+				LogLevel:                 "2",
+				Variables:                map[string]string{"LANDSCAPE": "canary", "REGION": "eu"},
+			},
+			want: func(s *OperatorSettings) {
+				s.Scheduling.JitterPercent = 0
+				s.ErrorRequeue.MaxDelay = time.Hour
+				s.CollectionTimeout = 2 * time.Minute
+				s.DataSinkFailureThreshold = 0
+				s.DefaultDataSink = "central"
+				s.LogLevel = zapcore.Level(-2)
+				s.Variables = map[string]string{"CLUSTER_NAME": "prod-eu", "LANDSCAPE": "canary", "REGION": "eu"}
+			},
+		},
+		{
+			name:    "NegativeDuration",
+			spec:    v1alpha1.MetricsOperatorConfigSpec{EventInterval: &metav1.Duration{Duration: -time.Minute}},
+			wantErr: "eventInterval must not be negative",
+		},
+		{
+			name:    "ZeroCollectionTimeout",
+			spec:    v1alpha1.MetricsOperatorConfigSpec{CollectionTimeout: &metav1.Duration{}},
+			wantErr: "collectionTimeout must be positive",
+		},
+		{
+			name:    "InvalidVariable",
+			spec:    v1alpha1.MetricsOperatorConfigSpec{Variables: map[string]string{"$(REGION)": "eu"}},
+			wantErr: "invalid variable name '$(REGION)'",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := flagSettings.override(tc.spec)
+			if tc.wantErr != "" {
+				require.EqualError(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			want := flagSettings
+			tc.want(&want)
+			require.Equal(t, want, got)
+		})
+	}
+	// the settings of the flags are not changed by an override
+	require.Equal(t, map[string]string{"CLUSTER_NAME": "prod-eu", "LANDSCAPE": "live"}, flagSettings.Variables)
+}
+
+func TestParseLogLevel(t *testing.T) {
+	testCases := []struct {
+		in      string
+		want    zapcore.Level
+		wantErr bool
+	}{
+		{in: "debug", want: zapcore.DebugLevel},
+		{in: "info", want: zapcore.InfoLevel},
+		{in: "error", want: zapcore.ErrorLevel},
+		{in: "3", want: zapcore.Level(-3)},
+		{in: "0", wantErr: true},
+		{in: "warn", wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.in, func(t *testing.T) {
+			got, err := ParseLogLevel(tc.in)
+			if tc.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestMetricsOperatorConfigReconcile(t *testing.T) {
+	ctx := context.Background()
+	scheme := runtime.NewScheme()
+	require.NoError(t, v1alpha1.AddToScheme(scheme))
+
+	config := &v1alpha1.MetricsOperatorConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: v1alpha1.MetricsOperatorConfigName, Generation: 2},
+		Spec:       v1alpha1.MetricsOperatorConfigSpec{JitterPercent: ptr.To[int32](25)},
+	}
+	cli := fake.NewClientBuilder().WithScheme(scheme).WithObjects(config).WithStatusSubresource(config).Build()
+
+	var applied []OperatorSettings
+	r := &MetricsOperatorConfigReconciler{
+		log:      logr.Discard(),
+		inCli:    cli,
+		defaults: flagSettings,
+		apply:    func(s OperatorSettings) { applied = append(applied, s) },
+	}
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: v1alpha1.MetricsOperatorConfigName}}
+
+	_, err := r.Reconcile(ctx, req)
+	require.NoError(t, err)
+	require.Len(t, applied, 1)
+	require.Equal(t, 25, applied[0].Scheduling.JitterPercent)
+	stored := &v1alpha1.MetricsOperatorConfig{}
+	require.NoError(t, cli.Get(ctx, client.ObjectKeyFromObject(config), stored))
+	require.True(t, meta.IsStatusConditionTrue(stored.Status.Conditions, v1alpha1.TypeReady))
+	require.Equal(t, stored.Generation, stored.Status.ObservedGeneration)
+
+	// an invalid config keeps the settings applied last
+	stored.Spec.CollectionTimeout = &metav1.Duration{}
+	require.NoError(t, cli.Update(ctx, stored))
+	_, err = r.Reconcile(ctx, req)
+	require.NoError(t, err)
+	require.Len(t, applied, 1)
+	require.NoError(t, cli.Get(ctx, client.ObjectKeyFromObject(config), stored))
+	ready := meta.FindStatusCondition(stored.Status.Conditions, v1alpha1.TypeReady)
+	require.Equal(t, metav1.ConditionFalse, ready.Status)
+	require.Equal(t, "InvalidConfig", ready.Reason)
+
+	// configs with other names are ignored
+	_, err = r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: "other"}})
+	require.NoError(t, err)
+	require.Len(t, applied, 1)
+
+	// deleting the config restores the settings of the flags
+	require.NoError(t, cli.Delete(ctx, stored))
+	_, err = r.Reconcile(ctx, req)
+	require.NoError(t, err)
+	require.Len(t, applied, 2)
+	require.Equal(t, flagSettings, applied[1])
+}
@@ -0,0 +1,86 @@
+package controller
+
+import (
+	"time"
+
+	"golang.org/x/time/rate"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// Names of the controllers, used to configure the number of workers per controller
+const (
+	MetricControllerName                 = "metric"
+	ManagedMetricControllerName          = "managedmetric"
+	FederatedMetricControllerName        = "federatedmetric"
+	FederatedManagedMetricControllerName = "federatedmanagedmetric"
+	CompositeMetricControllerName        = "compositemetric"
+	MetricSetControllerName              = "metricset"
+	ClusterMetricsStatusControllerName   = "clustermetricsstatus"
+	FederatedClusterAccessControllerName = "federatedclusteraccess"
+	RemoteClusterAccessControllerName    = "remoteclusteraccess"
+	DataSinkControllerName               = "datasink"
+	MetricNotificationControllerName     = "metricnotification"
+	ControlPlaneMetricSetControllerName  = "controlplanemetricset"
+	FederatedMetricAgentControllerName   = "federatedmetricagent"
+	MetricsOperatorConfigControllerName  = "metricsoperatorconfig"
+)
+
+const (
+	// DefaultMaxConcurrentReconciles is the default number of workers of each controller
+	DefaultMaxConcurrentReconciles = 1
+	// DefaultRateLimiterBaseDelay is the default delay of the first retry of a failed reconcile, doubled with every further failure
+	DefaultRateLimiterBaseDelay = 5 * time.Millisecond
+	// DefaultRateLimiterMaxDelay is the default upper bound of the delay between retries of a failed reconcile
+	DefaultRateLimiterMaxDelay = 1000 * time.Second
+	// DefaultRateLimiterQPS is the default number of requeues per second of each controller
+	DefaultRateLimiterQPS = 10
+	// DefaultRateLimiterBurst is the default number of requeues that may exceed the rate limit at once
+	DefaultRateLimiterBurst = 100
+)
+
+// ControllerOptions tune the workers, the rate limiting and the cache sync of the controllers.
+// The rate limiter defaults match the controller-runtime defaults.
+type ControllerOptions struct {
+	// MaxConcurrentReconciles is the number of workers by controller name, controllers not listed use DefaultMaxConcurrentReconciles
+	MaxConcurrentReconciles map[string]int
+
+	// RateLimiterBaseDelay and RateLimiterMaxDelay bound the exponential backoff of failed reconciles
+	RateLimiterBaseDelay time.Duration
+	RateLimiterMaxDelay  time.Duration
+	// RateLimiterQPS and RateLimiterBurst limit the requeues of all objects of a controller together
+	RateLimiterQPS   float64
+	RateLimiterBurst int
+
+	// CacheSyncTimeout is how long a controller waits for its caches to sync, 0 uses the controller-runtime default
+	CacheSyncTimeout time.Duration
+}
+
+// Controllers is used by the SetupWithManager functions of all controllers
+var Controllers = ControllerOptions{
+	RateLimiterBaseDelay: DefaultRateLimiterBaseDelay,
+	RateLimiterMaxDelay:  DefaultRateLimiterMaxDelay,
+	RateLimiterQPS:       DefaultRateLimiterQPS,
+	RateLimiterBurst:     DefaultRateLimiterBurst,
+}
+
+// forController returns the options of the controller with the given name
+func (o ControllerOptions) forController(name string) controller.Options {
+	workers, ok := o.MaxConcurrentReconciles[name]
+	if !ok || workers < 1 {
+		workers = DefaultMaxConcurrentReconciles
+	}
+
+	return controller.Options{
+		MaxConcurrentReconciles: workers,
+		CacheSyncTimeout:        o.CacheSyncTimeout,
+		// the priority queue reconciles metrics with a higher spec.priority first
+		UsePriorityQueue: ptr.To(true),
+		RateLimiter: workqueue.NewTypedMaxOfRateLimiter(
+			workqueue.NewTypedItemExponentialFailureRateLimiter[reconcile.Request](o.RateLimiterBaseDelay, o.RateLimiterMaxDelay),
+			&workqueue.TypedBucketRateLimiter[reconcile.Request]{Limiter: rate.NewLimiter(rate.Limit(o.RateLimiterQPS), o.RateLimiterBurst)},
+		),
+	}
+}
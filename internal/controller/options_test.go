@@ -0,0 +1,54 @@
+package controller
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestControllerOptions_forController(t *testing.T) {
+	options := ControllerOptions{
+		MaxConcurrentReconciles: map[string]int{MetricControllerName: 4, DataSinkControllerName: 0},
+		RateLimiterBaseDelay:    time.Second,
+		RateLimiterMaxDelay:     time.Minute,
+		RateLimiterQPS:          DefaultRateLimiterQPS,
+		RateLimiterBurst:        DefaultRateLimiterBurst,
+		CacheSyncTimeout:        5 * time.Minute,
+	}
+
+	testCases := []struct {
+		name            string
+		controller      string
+		expectedWorkers int
+	}{
+		{name: "Configured", controller: MetricControllerName, expectedWorkers: 4},
+		{name: "NotConfigured", controller: ManagedMetricControllerName, expectedWorkers: DefaultMaxConcurrentReconciles},
+		{name: "Invalid", controller: DataSinkControllerName, expectedWorkers: DefaultMaxConcurrentReconciles},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			opts := options.forController(tc.controller)
+			require.Equal(t, tc.expectedWorkers, opts.MaxConcurrentReconciles)
+			require.Equal(t, 5*time.Minute, opts.CacheSyncTimeout)
+		})
+	}
+
+	t.Run("RateLimiter", func(t *testing.T) {
+		limiter := options.forController(MetricControllerName).RateLimiter
+		req := reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "pods"}}
+
+		require.Equal(t, time.Second, limiter.When(req))
+		require.Equal(t, 2*time.Second, limiter.When(req))
+		for range 10 {
+			limiter.When(req)
+		}
+		require.Equal(t, time.Minute, limiter.When(req))
+
+		limiter.Forget(req)
+		require.Equal(t, time.Second, limiter.When(req), "backoff is reset after a successful reconcile")
+	})
+}
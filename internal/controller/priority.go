@@ -0,0 +1,107 @@
+package controller
+
+import (
+	"context"
+	"maps"
+
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/priorityqueue"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/openmcp-project/metrics-operator/api/v1alpha1"
+)
+
+// prioritizedMetric is implemented by all metric types
+type prioritizedMetric interface {
+	client.Object
+	GetPriority() v1alpha1.Priority
+}
+
+// queuePriority maps the priority of a metric to the priority of its work queue items, higher values are reconciled first
+func queuePriority(priority v1alpha1.Priority) int {
+	switch priority {
+	case v1alpha1.PriorityHigh:
+		return 10
+	case v1alpha1.PriorityLow:
+		return -10
+	default:
+		return 0
+	}
+}
+
+// enqueueByPriority enqueues the metrics affected by an event with the priority of the metrics.
+// Without a priority queue, the metrics are enqueued in the order of the events.
+type enqueueByPriority struct {
+	// metricsFor returns the metrics to reconcile for the changed object
+	metricsFor func(ctx context.Context, obj client.Object) []prioritizedMetric
+	// ignoreStatusUpdates drops updates that changed nothing but the status of the object
+	ignoreStatusUpdates bool
+}
+
+var _ handler.EventHandler = enqueueByPriority{}
+
+// enqueueSelf enqueues the changed metric itself. The status patches of the reconciler are not enqueued,
+// the reconciler requeues the metric at its next run, or with the backoff of its retry policy if the collection failed.
+func enqueueSelf() enqueueByPriority {
+	return enqueueByPriority{
+		metricsFor: func(_ context.Context, obj client.Object) []prioritizedMetric {
+			if metric, ok := obj.(prioritizedMetric); ok {
+				return []prioritizedMetric{metric}
+			}
+			return nil
+		},
+		ignoreStatusUpdates: true,
+	}
+}
+
+// statusUpdate returns true if only the status of the object changed, a changed spec, labels, annotations
+// like the replay annotation or the deletion of the object are no status updates
+func statusUpdate(oldObj, newObj client.Object) bool {
+	return oldObj.GetGeneration() == newObj.GetGeneration() &&
+		maps.Equal(oldObj.GetLabels(), newObj.GetLabels()) &&
+		maps.Equal(oldObj.GetAnnotations(), newObj.GetAnnotations()) &&
+		oldObj.GetDeletionTimestamp().Equal(newObj.GetDeletionTimestamp())
+}
+
+// Create implements handler.EventHandler
+func (e enqueueByPriority) Create(ctx context.Context, evt event.CreateEvent, q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+	e.enqueue(ctx, evt.Object, q)
+}
+
+// Update implements handler.EventHandler
+func (e enqueueByPriority) Update(ctx context.Context, evt event.UpdateEvent, q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+	if e.ignoreStatusUpdates && evt.ObjectOld != nil && evt.ObjectNew != nil && statusUpdate(evt.ObjectOld, evt.ObjectNew) {
+		return
+	}
+	e.enqueue(ctx, evt.ObjectNew, q)
+}
+
+// Delete implements handler.EventHandler
+func (e enqueueByPriority) Delete(ctx context.Context, evt event.DeleteEvent, q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+	e.enqueue(ctx, evt.Object, q)
+}
+
+// Generic implements handler.EventHandler
+func (e enqueueByPriority) Generic(ctx context.Context, evt event.GenericEvent, q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+	e.enqueue(ctx, evt.Object, q)
+}
+
+func (e enqueueByPriority) enqueue(ctx context.Context, obj client.Object, q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+	if obj == nil {
+		return
+	}
+	pq, isPriorityQueue := q.(priorityqueue.PriorityQueue[reconcile.Request])
+	for _, metric := range e.metricsFor(ctx, obj) {
+		req := reconcile.Request{NamespacedName: types.NamespacedName{Namespace: metric.GetNamespace(), Name: metric.GetName()}}
+		if !isPriorityQueue {
+			q.Add(req)
+			continue
+		}
+		pq.AddWithOpts(priorityqueue.AddOpts{Priority: ptr.To(queuePriority(metric.GetPriority()))}, req)
+	}
+}
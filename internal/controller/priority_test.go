@@ -0,0 +1,122 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/priorityqueue"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/openmcp-project/metrics-operator/api/v1alpha1"
+)
+
+func TestEnqueueByPriority(t *testing.T) {
+	metric := func(name string, priority v1alpha1.Priority) *v1alpha1.Metric {
+		return &v1alpha1.Metric{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name},
+			Spec:       v1alpha1.MetricSpec{Priority: priority},
+		}
+	}
+
+	q := priorityqueue.New[reconcile.Request]("test")
+	defer q.ShutDown()
+
+	h := enqueueSelf()
+	ctx := context.Background()
+	for _, m := range []*v1alpha1.Metric{
+		metric("inventory", v1alpha1.PriorityLow),
+		metric("pods", ""),
+		metric("billing", v1alpha1.PriorityHigh),
+	} {
+		h.Create(ctx, event.CreateEvent{Object: m}, q)
+	}
+	// Len flushes the items added so far, so they are ordered before the first one is handed out
+	require.Equal(t, 3, q.Len())
+
+	// the queue hands out the items in the order of their priority, not in the order they were added
+	for _, expected := range []struct {
+		name     string
+		priority int
+	}{
+		{name: "billing", priority: 10},
+		{name: "pods", priority: 0},
+		{name: "inventory", priority: -10},
+	} {
+		req, priority, _ := q.GetWithPriority()
+		require.Equal(t, expected.name, req.Name)
+		require.Equal(t, expected.priority, priority)
+		q.Done(req)
+	}
+}
+
+func TestEnqueueByPriority_metricsFor(t *testing.T) {
+	composite := &v1alpha1.CompositeMetric{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "ratio"},
+		Spec:       v1alpha1.CompositeMetricSpec{Priority: v1alpha1.PriorityHigh},
+	}
+	h := enqueueByPriority{metricsFor: func(context.Context, client.Object) []prioritizedMetric {
+		return []prioritizedMetric{composite}
+	}}
+
+	q := priorityqueue.New[reconcile.Request]("test")
+	defer q.ShutDown()
+
+	source := &v1alpha1.Metric{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "pods"}}
+	h.Update(context.Background(), event.UpdateEvent{ObjectOld: source, ObjectNew: source}, q)
+
+	req, priority, _ := q.GetWithPriority()
+	require.Equal(t, "ratio", req.Name)
+	require.Equal(t, 10, priority)
+}
+
+func TestEnqueueSelf_statusUpdates(t *testing.T) {
+	old := &v1alpha1.Metric{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "pods", Generation: 1}}
+	testCases := []struct {
+		name    string
+		update  func(m *v1alpha1.Metric)
+		enqueue bool
+	}{
+		{
+			name:   "Status",
+			update: func(m *v1alpha1.Metric) { m.Status.ConsecutiveFailures = 1 },
+		},
+		{
+			name:    "Spec",
+			update:  func(m *v1alpha1.Metric) { m.Generation = 2 },
+			enqueue: true,
+		},
+		{
+			name: "ReplayAnnotation",
+			update: func(m *v1alpha1.Metric) {
+				m.Annotations = map[string]string{v1alpha1.ReplayAnnotation: "2026-10-15T00:00:00Z"}
+			},
+			enqueue: true,
+		},
+		{
+			name:    "Deletion",
+			update:  func(m *v1alpha1.Metric) { m.DeletionTimestamp = ptr.To(metav1.Now()) },
+			enqueue: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			q := priorityqueue.New[reconcile.Request]("test")
+			defer q.ShutDown()
+
+			updated := old.DeepCopy()
+			tc.update(updated)
+			enqueueSelf().Update(context.Background(), event.UpdateEvent{ObjectOld: old, ObjectNew: updated}, q)
+			if tc.enqueue {
+				require.Equal(t, 1, q.Len())
+			} else {
+				require.Zero(t, q.Len())
+			}
+		})
+	}
+}
@@ -1,8 +1,18 @@
 package controller
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/rest"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/openmcp-project/metrics-operator/api/v1alpha1"
+	"github.com/openmcp-project/metrics-operator/internal/clientoptl"
 )
 
 // InsightReconciler is an interface for the reconciler of Insight objects
@@ -10,3 +20,53 @@ type InsightReconciler interface {
 	getClient() client.Client
 	getRestConfig() *rest.Config
 }
+
+// maxEventNoteLength is the maximum length of the note of an event
+const maxEventNoteLength = 1024
+
+// samplesNote lists the names of sampled resources in the note of a Samples event,
+// shortened to the maximum length of an event note
+func samplesNote(samples []string) string {
+	note := fmt.Sprintf("sampled %d matched resource(s): %s", len(samples), strings.Join(samples, ", "))
+	if len(note) > maxEventNoteLength {
+		note = note[:maxEventNoteLength-3] + "..."
+	}
+	return note
+}
+
+// exportFailedReason returns the reason of the Ready condition of a metric that could not be exported.
+// Exports skipped by the circuit breaker of the data sink and exports the data sink rejected for a known reason,
+// e.g. QuotaExceeded, are told apart from failed exports.
+func exportFailedReason(errExport error) string {
+	if errors.Is(errExport, clientoptl.ErrCircuitOpen) {
+		return "DataSinkCircuitOpen"
+	}
+	if reason := clientoptl.Rejection(errExport); reason != "" {
+		return string(reason)
+	}
+	return "MetricExportFailed"
+}
+
+// exportMetrics exports the data points of the metric client and records the attempt in the export status of the metric
+func exportMetrics(ctx context.Context, metricClient *clientoptl.MetricClient, status *v1alpha1.ExportStatus) error {
+	start := time.Now()
+	err := metricClient.ExportMetrics(ctx)
+	status.ExportAttempts++
+	status.LastExportDuration = &metav1.Duration{Duration: time.Since(start)}
+	if err == nil {
+		now := metav1.Now()
+		status.LastExportTime = &now
+		status.DataSink = metricClient.DataSink()
+	}
+	return err
+}
+
+// statusPatch returns a function patching the status of the object with the changes made to it since statusPatch was called.
+// Only the changed fields are sent, so the patch doesn't conflict with other writers of the status
+// and doesn't revert the fields they changed in the meantime.
+func statusPatch(c client.Client, obj client.Object) func(ctx context.Context) error {
+	base := obj.DeepCopyObject().(client.Object)
+	return func(ctx context.Context) error {
+		return c.Status().Patch(ctx, obj, client.MergeFrom(base))
+	}
+}
@@ -0,0 +1,65 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/openmcp-project/metrics-operator/api/v1alpha1"
+	"github.com/openmcp-project/metrics-operator/internal/clientoptl"
+	"github.com/openmcp-project/metrics-operator/internal/common"
+)
+
+func TestExportMetrics(t *testing.T) {
+	ctx := context.Background()
+	exporter := clientoptl.NewFakeExporter()
+	metricClient, err := exporter.Factory().NewMetricClient(ctx, &common.DataSinkCredentials{Name: "metrics-operator-system/export-status", Host: "https://sink.example.com"})
+	require.NoError(t, err)
+	metricClient.SetMeter("metric", nil)
+	status := &v1alpha1.ExportStatus{}
+
+	require.NoError(t, exportMetrics(ctx, metricClient, status))
+	require.Equal(t, int64(1), status.ExportAttempts)
+	require.NotNil(t, status.LastExportTime)
+	require.NotNil(t, status.LastExportDuration)
+	require.Equal(t, "metrics-operator-system/export-status", status.DataSink)
+	lastExport := *status.LastExportTime
+
+	// failed exports are counted but keep the time of the last successful export
+	exporter.Err = errors.New("connection refused")
+	require.ErrorContains(t, exportMetrics(ctx, metricClient, status), "connection refused")
+	require.Equal(t, int64(2), status.ExportAttempts)
+	require.Equal(t, lastExport, *status.LastExportTime)
+}
+
+func TestStatusPatch(t *testing.T) {
+	ctx := context.Background()
+	scheme := runtime.NewScheme()
+	require.NoError(t, v1alpha1.AddToScheme(scheme))
+	metric := &v1alpha1.ManagedMetric{ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "buckets"}}
+	cli := fake.NewClientBuilder().WithScheme(scheme).WithObjects(metric).WithStatusSubresource(metric).Build()
+
+	loaded := &v1alpha1.ManagedMetric{}
+	require.NoError(t, cli.Get(ctx, client.ObjectKeyFromObject(metric), loaded))
+	patchStatus := statusPatch(cli, loaded)
+	loaded.Status.Ready = v1alpha1.StatusStringTrue
+
+	// another writer changes a different status field after the metric was loaded
+	concurrent := &v1alpha1.ManagedMetric{}
+	require.NoError(t, cli.Get(ctx, client.ObjectKeyFromObject(metric), concurrent))
+	concurrent.Status.ConsecutiveFailures = 3
+	require.NoError(t, cli.Status().Update(ctx, concurrent))
+
+	// the patch neither conflicts nor reverts the change of the other writer
+	require.NoError(t, patchStatus(ctx))
+	stored := &v1alpha1.ManagedMetric{}
+	require.NoError(t, cli.Get(ctx, client.ObjectKeyFromObject(metric), stored))
+	require.Equal(t, v1alpha1.StatusStringTrue, stored.Status.Ready)
+	require.Equal(t, int32(3), stored.Status.ConsecutiveFailures)
+}
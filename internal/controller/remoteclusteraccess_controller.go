@@ -0,0 +1,137 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-logr/logr"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/events"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	"github.com/openmcp-project/metrics-operator/api/v1alpha1"
+	"github.com/openmcp-project/metrics-operator/internal/common"
+	"github.com/openmcp-project/metrics-operator/internal/config"
+	internalmetrics "github.com/openmcp-project/metrics-operator/internal/metrics"
+	orc "github.com/openmcp-project/metrics-operator/internal/orchestrator"
+)
+
+// CredentialsCheckInterval is the interval in which the credentials of remote clusters are checked
+const CredentialsCheckInterval = time.Hour
+
+// remoteClusterAccessKind is the kind label of the credentials expiry of RemoteClusterAccesses
+const remoteClusterAccessKind = "RemoteClusterAccess"
+
+// QueryConfigFunc creates the query config of a remote cluster access
+type QueryConfigFunc func(ctx context.Context, ref *v1alpha1.RemoteClusterAccessRef, inClient client.Client) (*orc.QueryConfig, error)
+
+// NewRemoteClusterAccessReconciler creates a new RemoteClusterAccessReconciler
+func NewRemoteClusterAccessReconciler(mgr ctrl.Manager) *RemoteClusterAccessReconciler {
+	return &RemoteClusterAccessReconciler{
+		log: mgr.GetLogger().WithName("controllers").WithName("RemoteClusterAccess"),
+
+		inCli:    mgr.GetClient(),
+		Recorder: SharedEventThrottle.Recorder(mgr.GetEventRecorder("remoteclusteraccess-controller")),
+
+		queryConfig: config.CreateExternalQueryConfig,
+	}
+}
+
+// RemoteClusterAccessReconciler periodically checks that the credentials of remote clusters are valid and not about to expire
+type RemoteClusterAccessReconciler struct {
+	log logr.Logger
+
+	inCli    client.Client
+	Recorder events.EventRecorder
+
+	queryConfig QueryConfigFunc
+}
+
+// +kubebuilder:rbac:groups=metrics.openmcp.cloud,resources=remoteclusteraccesses,verbs=get;list;watch
+// +kubebuilder:rbac:groups=metrics.openmcp.cloud,resources=remoteclusteraccesses/status,verbs=get;update;patch
+
+// Reconcile creates the client of the remote cluster and reports the expiry of its credentials
+func (r *RemoteClusterAccessReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	l := r.log.WithValues("namespace", req.Namespace, "name", req.Name)
+
+	access := v1alpha1.RemoteClusterAccess{}
+	if errLoad := r.inCli.Get(ctx, req.NamespacedName, &access); errLoad != nil {
+		if apierrors.IsNotFound(errLoad) {
+			internalmetrics.DeleteCredentialsExpiry(remoteClusterAccessKind, req.Namespace, req.Name)
+			return ctrl.Result{}, nil
+		}
+		l.Error(errLoad, "unable to fetch RemoteClusterAccess")
+		return ctrl.Result{RequeueAfter: RequeueAfterError}, errLoad
+	}
+
+	patchStatus := statusPatch(r.inCli, &access)
+	defer func() {
+		if err := patchStatus(ctx); err != nil {
+			l.Error(err, "Failed to update RemoteClusterAccess status")
+		}
+	}()
+
+	now := metav1.Now()
+	access.Status.LastCheckTime = &now
+
+	qc, errQC := r.queryConfig(ctx, &v1alpha1.RemoteClusterAccessRef{Name: access.Name, Namespace: access.Namespace}, r.inCli)
+	if errQC != nil {
+		internalmetrics.DeleteCredentialsExpiry(remoteClusterAccessKind, access.Namespace, access.Name)
+		access.Status.CredentialsExpiry = nil
+		r.setUnhealthy(&access, ReasonCredentialsInvalid, errQC.Error())
+		access.SetConditions(credentialsCondition(metav1.ConditionUnknown, ReasonCredentialsInvalid, errQC.Error()))
+		return ctrl.Result{RequeueAfter: RequeueAfterError}, nil
+	}
+
+	check := checkCredentials(remoteClusterAccessKind, &access, []orc.QueryConfig{*qc}, now.Time)
+	access.Status.CredentialsExpiry = check.expiry
+	if check.condition.Status == metav1.ConditionTrue && !check.expired && !meta.IsStatusConditionTrue(access.Status.Conditions, v1alpha1.TypeCredentialsExpiring) {
+		r.Recorder.Eventf(&access, nil, "Warning", check.condition.Reason, "ReconcileRemoteClusterAccess", check.condition.Message)
+	}
+	access.SetConditions(check.condition)
+	if check.expired {
+		r.setUnhealthy(&access, ReasonCredentialsExpired, check.condition.Message)
+		return ctrl.Result{RequeueAfter: CredentialsCheckInterval}, nil
+	}
+	access.SetConditions(common.ReadyTrue("a client for the remote cluster was created"))
+	return ctrl.Result{RequeueAfter: CredentialsCheckInterval}, nil
+}
+
+// setUnhealthy sets the Ready condition to false and emits a warning when the access becomes unhealthy
+func (r *RemoteClusterAccessReconciler) setUnhealthy(access *v1alpha1.RemoteClusterAccess, reason, message string) {
+	if !meta.IsStatusConditionFalse(access.Status.Conditions, v1alpha1.TypeReady) {
+		r.Recorder.Eventf(access, nil, "Warning", reason, "ReconcileRemoteClusterAccess", message)
+	}
+	access.SetConditions(common.ReadyFalse(reason, message))
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *RemoteClusterAccessReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(RemoteClusterAccessControllerName).
+		WithOptions(Controllers.forController(RemoteClusterAccessControllerName)).
+		// the status updates of the controller itself need no reconcile
+		For(&v1alpha1.RemoteClusterAccess{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Complete(r)
+}
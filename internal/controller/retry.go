@@ -0,0 +1,125 @@
+package controller
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	"github.com/openmcp-project/metrics-operator/api/v1alpha1"
+	"github.com/openmcp-project/metrics-operator/internal/common"
+)
+
+const (
+	// DefaultErrorRequeueMaxDelay is the default upper bound of the delay between the retries of failed collections
+	DefaultErrorRequeueMaxDelay = 30 * time.Minute
+
+	// ReasonFailureThresholdReached is the reason of the Ready condition of a metric whose collections failed
+	// as often in a row as the failure threshold of its retry policy allows
+	ReasonFailureThresholdReached = "FailureThresholdReached"
+)
+
+// ErrorRequeueOptions bound the exponential backoff of failed collections of metrics without a retry policy
+type ErrorRequeueOptions struct {
+	// BaseDelay is the delay of the first retry, doubled with every further failure
+	BaseDelay time.Duration
+	// MaxDelay is the upper bound of the delay
+	MaxDelay time.Duration
+}
+
+// ErrorRequeue is used by the reconcilers of all metric kinds
+var ErrorRequeue = ErrorRequeueOptions{BaseDelay: RequeueAfterError, MaxDelay: DefaultErrorRequeueMaxDelay}
+
+// failureBudget tracks the consecutive failed collections of a metric, decides when they are retried
+// and stops retrying once the failure threshold of the retry policy of the metric is reached
+type failureBudget struct {
+	policy     v1alpha1.RetryPolicy
+	generation int64
+	failures   *int32
+	ready      *string
+	conditions *[]metav1.Condition
+}
+
+// newFailureBudget returns the failure budget of a metric, the pointers refer to its status
+func newFailureBudget(metric metav1.Object, policy *v1alpha1.RetryPolicy, failures *int32, ready *string, conditions *[]metav1.Condition) *failureBudget {
+	b := &failureBudget{generation: metric.GetGeneration(), failures: failures, ready: ready, conditions: conditions}
+	if policy != nil {
+		b.policy = *policy
+	}
+	return b
+}
+
+// exhausted returns true if the metric reached its failure threshold and its spec has not changed since.
+// A changed spec resets the consecutive failures.
+func (b *failureBudget) exhausted() bool {
+	condition := meta.FindStatusCondition(*b.conditions, v1alpha1.TypeReady)
+	if condition == nil || condition.Reason != ReasonFailureThresholdReached {
+		return false
+	}
+	if condition.ObservedGeneration == b.generation {
+		return true
+	}
+	*b.failures = 0
+	return false
+}
+
+// retryDelay returns the delay after which the current collection is retried if it fails
+func (b *failureBudget) retryDelay() time.Duration {
+	settingsMu.RLock()
+	base, maxDelay := ErrorRequeue.BaseDelay, ErrorRequeue.MaxDelay
+	settingsMu.RUnlock()
+	if b.policy.BaseDelay != nil {
+		base = b.policy.BaseDelay.Duration
+	}
+	if b.policy.MaxDelay != nil {
+		maxDelay = b.policy.MaxDelay.Duration
+	}
+	delay := base
+	for i := int32(0); i < *b.failures && delay < maxDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, maxDelay)
+}
+
+// retryAt returns the time the failed collection is retried. If the previous collection failed as well,
+// the scheduled time is its retry, and a retry that is scheduled later is kept, so a collection that failed ahead
+// of its retry, e.g. when a changed DataSink triggered it, does not move the retry. After a successful collection
+// the scheduled time is the next regular run, which does not delay the retry.
+func (b *failureBudget) retryAt(scheduled *metav1.Time) time.Time {
+	retry := time.Now().Add(b.retryDelay())
+	if *b.failures > 0 && scheduled != nil && scheduled.After(retry) {
+		return scheduled.Time
+	}
+	return retry
+}
+
+// track counts the collection as failed if it returned an error or left the metric not ready, or resets the count otherwise.
+// Errors are logged instead of returned, so failed collections are retried after the retry delay the reconciler requeued them with
+// instead of the backoff of the controller, and not at all once the failure threshold is reached.
+func (b *failureBudget) track(result ctrl.Result, err error, l logr.Logger) (ctrl.Result, error) {
+	if err == nil && *b.ready != v1alpha1.StatusStringFalse {
+		*b.failures = 0
+		return result, nil
+	}
+	delay := b.retryDelay()
+	*b.failures++
+	if err != nil {
+		l.Error(err, "collection failed", "consecutiveFailures", *b.failures, "retryAfter", delay)
+	}
+
+	if b.policy.FailureThreshold > 0 && *b.failures >= b.policy.FailureThreshold {
+		message := fmt.Sprintf("collection failed %d times in a row, retried after the spec changes", *b.failures)
+		if condition := meta.FindStatusCondition(*b.conditions, v1alpha1.TypeReady); condition != nil && condition.Message != "" {
+			message = fmt.Sprintf("%s: %s", message, condition.Message)
+		}
+		condition := common.ReadyFalse(ReasonFailureThresholdReached, message)
+		condition.ObservedGeneration = b.generation
+		meta.SetStatusCondition(b.conditions, condition)
+		*b.ready = v1alpha1.StatusStringFalse
+		return ctrl.Result{}, nil
+	}
+	return result, nil
+}
@@ -0,0 +1,121 @@
+package controller
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	"github.com/openmcp-project/metrics-operator/api/v1alpha1"
+	"github.com/openmcp-project/metrics-operator/internal/common"
+)
+
+// withErrorRequeue replaces the error requeue options for the duration of the test
+func withErrorRequeue(t *testing.T, options ErrorRequeueOptions) {
+	previous := ErrorRequeue
+	ErrorRequeue = options
+	t.Cleanup(func() { ErrorRequeue = previous })
+}
+
+func budgetOf(metric *v1alpha1.Metric) *failureBudget {
+	return newFailureBudget(metric, metric.Spec.RetryPolicy, &metric.Status.ConsecutiveFailures, &metric.Status.Ready, &metric.Status.Conditions)
+}
+
+func TestFailureBudget_retryDelay(t *testing.T) {
+	withErrorRequeue(t, ErrorRequeueOptions{BaseDelay: 2 * time.Minute, MaxDelay: 30 * time.Minute})
+
+	testCases := []struct {
+		name     string
+		policy   *v1alpha1.RetryPolicy
+		failures int32
+		expected time.Duration
+	}{
+		{name: "FirstFailure", expected: 2 * time.Minute},
+		{name: "Doubled", failures: 2, expected: 8 * time.Minute},
+		{name: "Capped", failures: 10, expected: 30 * time.Minute},
+		{
+			name:     "Policy",
+			policy:   &v1alpha1.RetryPolicy{BaseDelay: &metav1.Duration{Duration: 10 * time.Second}, MaxDelay: &metav1.Duration{Duration: time.Minute}},
+			failures: 1,
+			expected: 20 * time.Second,
+		},
+		{
+			name:     "PolicyCapped",
+			policy:   &v1alpha1.RetryPolicy{BaseDelay: &metav1.Duration{Duration: 10 * time.Second}, MaxDelay: &metav1.Duration{Duration: time.Minute}},
+			failures: 5,
+			expected: time.Minute,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			metric := &v1alpha1.Metric{Spec: v1alpha1.MetricSpec{RetryPolicy: tc.policy}}
+			metric.Status.ConsecutiveFailures = tc.failures
+			require.Equal(t, tc.expected, budgetOf(metric).retryDelay())
+		})
+	}
+}
+
+func TestFailureBudget_retryAt(t *testing.T) {
+	withErrorRequeue(t, ErrorRequeueOptions{BaseDelay: 2 * time.Minute, MaxDelay: 30 * time.Minute})
+	metric := &v1alpha1.Metric{}
+	budget := budgetOf(metric)
+	later := metav1.NewTime(time.Now().Add(10 * time.Minute))
+
+	// after a successful collection, the scheduled time is the next regular run, which does not delay the retry
+	for _, scheduled := range []*metav1.Time{nil, {Time: time.Now().Add(-time.Minute)}, &later} {
+		require.WithinDuration(t, time.Now().Add(2*time.Minute), budget.retryAt(scheduled), time.Second)
+	}
+
+	// after a failed collection, the scheduled time is its retry
+	metric.Status.ConsecutiveFailures = 1
+	require.WithinDuration(t, time.Now().Add(4*time.Minute), budget.retryAt(&metav1.Time{Time: time.Now().Add(-time.Minute)}), time.Second)
+	// a retry scheduled later is kept
+	require.Equal(t, later.Time, budget.retryAt(&later))
+}
+
+func TestFailureBudget_track(t *testing.T) {
+	withErrorRequeue(t, ErrorRequeueOptions{BaseDelay: time.Minute, MaxDelay: time.Hour})
+	metric := &v1alpha1.Metric{
+		ObjectMeta: metav1.ObjectMeta{Generation: 1},
+		Spec:       v1alpha1.MetricSpec{RetryPolicy: &v1alpha1.RetryPolicy{FailureThreshold: 2}},
+	}
+	fail := func(err error) (ctrl.Result, error) {
+		metric.SetConditions(common.ReadyFalse("MonitoringFailed", "connection refused"))
+		metric.Status.Ready = v1alpha1.StatusStringFalse
+		b := budgetOf(metric)
+		require.False(t, b.exhausted())
+		return b.track(ctrl.Result{RequeueAfter: b.retryDelay()}, err, logr.Discard())
+	}
+
+	result, err := fail(errors.New("connection refused"))
+	require.NoError(t, err, "errors are reported in the status instead of the backoff of the controller")
+	require.Equal(t, time.Minute, result.RequeueAfter)
+	require.Equal(t, int32(1), metric.Status.ConsecutiveFailures)
+
+	// a successful collection resets the failures
+	metric.Status.Ready = v1alpha1.StatusStringTrue
+	_, err = budgetOf(metric).track(ctrl.Result{}, nil, logr.Discard())
+	require.NoError(t, err)
+	require.Zero(t, metric.Status.ConsecutiveFailures)
+
+	_, _ = fail(nil)
+	result, err = fail(nil)
+	require.NoError(t, err)
+	require.Equal(t, ctrl.Result{}, result, "the metric is not retried once the threshold is reached")
+	require.Equal(t, int32(2), metric.Status.ConsecutiveFailures)
+	condition := meta.FindStatusCondition(metric.Status.Conditions, v1alpha1.TypeReady)
+	require.Equal(t, ReasonFailureThresholdReached, condition.Reason)
+	require.Equal(t, "collection failed 2 times in a row, retried after the spec changes: connection refused", condition.Message)
+	require.True(t, budgetOf(metric).exhausted())
+
+	// a changed spec resets the failures
+	metric.Generation = 2
+	require.False(t, budgetOf(metric).exhausted())
+	require.Zero(t, metric.Status.ConsecutiveFailures)
+}
@@ -0,0 +1,142 @@
+package controller
+
+import (
+	"fmt"
+	"hash/fnv"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	"github.com/openmcp-project/metrics-operator/api/v1alpha1"
+	"github.com/openmcp-project/metrics-operator/internal/cron"
+)
+
+const (
+	// DefaultJitterPercent is the default share of the interval by which first exports are delayed at most
+	DefaultJitterPercent = 10
+	// DefaultStartupSpread is the default time over which the exports due at the start of the operator are spread
+	DefaultStartupSpread = time.Minute
+)
+
+// SchedulingOptions spread the exports of many metrics with identical intervals,
+// so they do not all hit the API servers and data sinks at the same moment
+type SchedulingOptions struct {
+	// JitterPercent delays the first export of a metric with an interval by up to this percentage of the interval
+	JitterPercent int
+	// StartupSpread is the time over which the exports that are due when the operator starts are spread
+	StartupSpread time.Duration
+}
+
+// Scheduling is used by all reconcilers
+var Scheduling = SchedulingOptions{JitterPercent: DefaultJitterPercent, StartupSpread: DefaultStartupSpread}
+
+// operatorStart is the time the exports due at the start of the operator are spread from
+var operatorStart = time.Now()
+
+// exportSchedule decides when a metric is exported, either in a fixed interval or at the times of a cron schedule
+type exportSchedule struct {
+	interval time.Duration
+	cron     *cron.Schedule
+	options  SchedulingOptions
+
+	// key identifies the metric, its hash determines the jitter and the startup offset of the metric
+	key string
+	// created is the creation time of the metric, a metric with a cron schedule is first exported at the first scheduled time after it
+	created time.Time
+	// replay is the time of the last replay requested with the replay annotation, exports older than it are due right away
+	replay time.Time
+}
+
+// newExportSchedule returns the schedule of a metric, the cron schedule takes precedence over the interval
+func newExportSchedule(interval metav1.Duration, schedule string, metric metav1.Object) (exportSchedule, error) {
+	settingsMu.RLock()
+	options := Scheduling
+	settingsMu.RUnlock()
+	s := exportSchedule{
+		interval: interval.Duration,
+		options:  options,
+		key:      metric.GetNamespace() + "/" + metric.GetName(),
+		created:  metric.GetCreationTimestamp().Time,
+	}
+	// replays requested in the future are ignored, they would be due again after every export until then
+	if replay, err := time.Parse(time.RFC3339, metric.GetAnnotations()[v1alpha1.ReplayAnnotation]); err == nil && !replay.After(time.Now()) {
+		s.replay = replay
+	}
+	if schedule == "" {
+		return s, nil
+	}
+
+	c, err := cron.Parse(schedule)
+	if err != nil {
+		return s, err
+	}
+	if c.Next(time.Now().UTC()).IsZero() {
+		return s, fmt.Errorf("cron expression '%s' never fires", schedule)
+	}
+	s.cron = c
+	return s, nil
+}
+
+// next returns the time of the export following the last one.
+// Metrics with an interval that were never exported are due after the jitter has passed since their creation,
+// unless the operator just started, and every interval after the last export then.
+// The jitter and the startup offset are derived from the metric only, so they are the same for every call.
+func (s exportSchedule) next(lastExport time.Time) time.Time {
+	var next time.Time
+	switch {
+	case s.cron != nil:
+		if lastExport.IsZero() {
+			lastExport = s.created
+		}
+		next = s.cron.Next(lastExport.UTC())
+	case lastExport.IsZero():
+		// the offset of the first export is kept by the following ones, so it spreads metrics created at once for good
+		jitter := time.Duration(s.fraction("") * float64(s.interval) * float64(s.options.JitterPercent) / 100)
+		next = s.created.Add(jitter)
+	default:
+		next = lastExport.Add(s.interval)
+	}
+
+	// exports that are due when the operator starts are spread over the startup spread
+	if s.options.StartupSpread > 0 {
+		notBefore := operatorStart.Add(time.Duration(s.fraction("") * float64(s.options.StartupSpread)))
+		if next.Before(notBefore) {
+			return notBefore
+		}
+	}
+	return next
+}
+
+// period returns the time between the given export and the one following it, without the jitter and the startup spread
+func (s exportSchedule) period(lastExport time.Time) time.Duration {
+	if s.cron != nil {
+		return s.cron.Next(lastExport.UTC()).Sub(lastExport)
+	}
+	return s.interval
+}
+
+// fraction returns a number in [0, 1) derived from the key of the metric and the given salt
+func (s exportSchedule) fraction(salt string) float64 {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(s.key + salt))
+	return float64(h.Sum32()) / (1 << 32)
+}
+
+// due returns true if the next export after the last one is due, or a replay was requested after the last export
+func (s exportSchedule) due(lastExport time.Time) bool {
+	if lastExport.Before(s.replay) {
+		return true
+	}
+	return !time.Now().Before(s.next(lastExport))
+}
+
+// requeueAt records the next run time in the status of the metric and returns the result that requeues the metric at that time.
+// The priority is set on every requeue, so a changed priority of the metric takes effect with its next run.
+func requeueAt(nextRunTime **metav1.Time, next time.Time, priority v1alpha1.Priority) ctrl.Result {
+	t := metav1.NewTime(next)
+	*nextRunTime = &t
+	// a zero RequeueAfter does not requeue at all, so overdue runs are requeued shortly
+	return ctrl.Result{RequeueAfter: max(time.Until(next), time.Second), Priority: ptr.To(queuePriority(priority))}
+}
@@ -0,0 +1,177 @@
+package controller
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	"github.com/openmcp-project/metrics-operator/api/v1alpha1"
+)
+
+// scheduledMetric returns a metric created at the given time to derive an export schedule from
+func scheduledMetric(name string, created time.Time) *v1alpha1.Metric {
+	return &v1alpha1.Metric{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name, CreationTimestamp: metav1.NewTime(created)}}
+}
+
+// withScheduling replaces the scheduling options for the duration of the test
+func withScheduling(t *testing.T, options SchedulingOptions) {
+	previous := Scheduling
+	Scheduling = options
+	t.Cleanup(func() { Scheduling = previous })
+}
+
+func TestExportSchedule(t *testing.T) {
+	withScheduling(t, SchedulingOptions{})
+	created := time.Date(2024, time.May, 15, 10, 17, 0, 0, time.UTC)
+	lastExport := time.Date(2024, time.May, 15, 11, 0, 5, 0, time.UTC)
+
+	testCases := []struct {
+		name         string
+		interval     time.Duration
+		schedule     string
+		lastExport   time.Time
+		expectedNext time.Time
+	}{
+		{
+			name:         "IntervalNeverExported",
+			interval:     10 * time.Minute,
+			expectedNext: created,
+		},
+		{
+			name:         "Interval",
+			interval:     10 * time.Minute,
+			lastExport:   lastExport,
+			expectedNext: lastExport.Add(10 * time.Minute),
+		},
+		{
+			name:         "CronNeverExported",
+			interval:     10 * time.Minute,
+			schedule:     "0 * * * *",
+			expectedNext: time.Date(2024, time.May, 15, 11, 0, 0, 0, time.UTC),
+		},
+		{
+			name:         "Cron",
+			interval:     10 * time.Minute,
+			schedule:     "@daily",
+			lastExport:   lastExport,
+			expectedNext: time.Date(2024, time.May, 16, 0, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s, err := newExportSchedule(metav1.Duration{Duration: tc.interval}, tc.schedule, scheduledMetric("pods", created))
+			require.NoError(t, err)
+			require.Equal(t, tc.expectedNext, s.next(tc.lastExport))
+		})
+	}
+}
+
+func TestExportSchedule_due(t *testing.T) {
+	withScheduling(t, SchedulingOptions{})
+	s, err := newExportSchedule(metav1.Duration{Duration: time.Hour}, "", scheduledMetric("pods", time.Now()))
+	require.NoError(t, err)
+	require.True(t, s.due(time.Time{}))
+	require.True(t, s.due(time.Now().Add(-time.Hour)))
+	require.False(t, s.due(time.Now().Add(-time.Minute)))
+
+	// a new metric with a cron schedule waits for the first scheduled time
+	s, err = newExportSchedule(metav1.Duration{Duration: time.Hour}, "0 0 1 1 *", scheduledMetric("pods", time.Now()))
+	require.NoError(t, err)
+	require.False(t, s.due(time.Time{}))
+}
+
+func TestExportSchedule_replay(t *testing.T) {
+	withScheduling(t, SchedulingOptions{})
+	replay := time.Now().Add(-10 * time.Minute).Truncate(time.Second)
+	metric := scheduledMetric("pods", time.Now().Add(-24*time.Hour))
+	metric.Annotations = map[string]string{v1alpha1.ReplayAnnotation: replay.Format(time.RFC3339)}
+
+	s, err := newExportSchedule(metav1.Duration{Duration: time.Hour}, "0 0 1 1 *", metric)
+	require.NoError(t, err)
+	require.True(t, s.due(replay.Add(-time.Minute)), "exports before the replay are due regardless of the schedule")
+	require.False(t, s.due(replay), "the replay is exported once")
+
+	metric.Annotations[v1alpha1.ReplayAnnotation] = time.Now().Add(time.Hour).Format(time.RFC3339)
+	s, err = newExportSchedule(metav1.Duration{Duration: time.Hour}, "", metric)
+	require.NoError(t, err)
+	require.False(t, s.due(time.Now().Add(-time.Minute)), "replays in the future are ignored")
+
+	metric.Annotations[v1alpha1.ReplayAnnotation] = "now"
+	s, err = newExportSchedule(metav1.Duration{Duration: time.Hour}, "", metric)
+	require.NoError(t, err)
+	require.False(t, s.due(time.Now().Add(-time.Minute)), "invalid replays are ignored")
+}
+
+func TestExportSchedule_invalid(t *testing.T) {
+	_, err := newExportSchedule(metav1.Duration{}, "every hour", scheduledMetric("pods", time.Now()))
+	require.ErrorContains(t, err, "expected 5 fields")
+
+	_, err = newExportSchedule(metav1.Duration{}, "0 0 30 2 *", scheduledMetric("pods", time.Now()))
+	require.ErrorContains(t, err, "never fires")
+}
+
+func TestExportSchedule_jitter(t *testing.T) {
+	withScheduling(t, SchedulingOptions{JitterPercent: 20})
+	created := time.Now().Add(-time.Hour)
+	lastExport := time.Now().Add(-time.Minute)
+
+	firsts := map[time.Time]struct{}{}
+	for i := range 20 {
+		s, err := newExportSchedule(metav1.Duration{Duration: 5 * time.Minute}, "", scheduledMetric(fmt.Sprintf("metric-%d", i), created))
+		require.NoError(t, err)
+
+		// the first export is delayed by the jitter, the same for repeated calls
+		first := s.next(time.Time{})
+		require.False(t, first.Before(created))
+		require.True(t, first.Before(created.Add(time.Minute)))
+		require.Equal(t, first, s.next(time.Time{}))
+		firsts[first] = struct{}{}
+
+		// the following exports keep the offset of the first one
+		require.Equal(t, lastExport.Add(5*time.Minute), s.next(lastExport))
+	}
+	require.Greater(t, len(firsts), 10, "metrics with the same interval are exported at different times")
+}
+
+func TestExportSchedule_startupSpread(t *testing.T) {
+	withScheduling(t, SchedulingOptions{StartupSpread: time.Hour})
+	overdue := operatorStart.Add(-24 * time.Hour)
+
+	offsets := map[time.Time]struct{}{}
+	for i := range 20 {
+		s, err := newExportSchedule(metav1.Duration{Duration: 5 * time.Minute}, "", scheduledMetric(fmt.Sprintf("metric-%d", i), overdue))
+		require.NoError(t, err)
+
+		// overdue and new metrics are exported within the startup spread
+		next := s.next(overdue)
+		require.False(t, next.Before(operatorStart))
+		require.True(t, next.Before(operatorStart.Add(time.Hour)))
+		require.Equal(t, next, s.next(time.Time{}))
+		offsets[next] = struct{}{}
+
+		// later exports are not affected
+		later := operatorStart.Add(2 * time.Hour)
+		require.Equal(t, later.Add(5*time.Minute), s.next(later))
+	}
+	require.Greater(t, len(offsets), 10, "overdue metrics are exported at different times")
+}
+
+func TestRequeueAt(t *testing.T) {
+	var nextRunTime *metav1.Time
+	next := time.Now().Add(time.Hour)
+
+	result := requeueAt(&nextRunTime, next, v1alpha1.PriorityHigh)
+	require.NotNil(t, nextRunTime)
+	require.True(t, nextRunTime.Time.Equal(next))
+	require.InDelta(t, time.Hour, result.RequeueAfter, float64(time.Second))
+	require.Equal(t, ptr.To(queuePriority(v1alpha1.PriorityHigh)), result.Priority)
+
+	// overdue runs are requeued shortly instead of not at all
+	result = requeueAt(&nextRunTime, time.Now().Add(-time.Minute), v1alpha1.PriorityNormal)
+	require.Equal(t, time.Second, result.RequeueAfter)
+}
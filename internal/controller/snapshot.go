@@ -0,0 +1,93 @@
+package controller
+
+import (
+	"cmp"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	"github.com/openmcp-project/metrics-operator/api/v1alpha1"
+)
+
+// +kubebuilder:rbac:groups=metrics.openmcp.cloud,resources=metricsnapshots,verbs=get;list;watch;create;delete
+
+// writeSnapshot creates the MetricSnapshot of a collection of the metric and deletes its snapshots older than its retention
+// or beyond its maximum number of snapshots. The snapshots are owned by the metric, so they are deleted with it.
+func writeSnapshot(ctx context.Context, c client.Client, metric *v1alpha1.Metric, spec v1alpha1.MetricSnapshotSpec) error {
+	spec.Metric = metric.Name
+	suffix := fmt.Sprintf("-%d", spec.Timestamp.Unix())
+	snapshot := &v1alpha1.MetricSnapshot{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: metric.Namespace,
+			Name:      shortenName(metric.Name, validation.DNS1123SubdomainMaxLength-len(suffix)) + suffix,
+			Labels:    map[string]string{v1alpha1.MetricSnapshotLabel: snapshotLabel(metric)},
+		},
+		Spec: spec,
+	}
+	if err := controllerutil.SetControllerReference(metric, snapshot, c.Scheme()); err != nil {
+		return err
+	}
+	// a collection retried within the same second keeps its first snapshot
+	if err := c.Create(ctx, snapshot); err != nil && !apierrors.IsAlreadyExists(err) {
+		return fmt.Errorf("failed to create the snapshot: %w", err)
+	}
+
+	retention := v1alpha1.DefaultSnapshotRetention
+	if metric.Spec.SnapshotRetention != nil {
+		retention = metric.Spec.SnapshotRetention.Duration
+	}
+	maxSnapshots := v1alpha1.DefaultMaxSnapshots
+	if metric.Spec.MaxSnapshots != nil {
+		maxSnapshots = int(*metric.Spec.MaxSnapshots)
+	}
+	return pruneSnapshots(ctx, c, metric, time.Now().Add(-retention), maxSnapshots)
+}
+
+// pruneSnapshots deletes the MetricSnapshots of the metric taken before the time and the oldest ones beyond the maximum number
+func pruneSnapshots(ctx context.Context, c client.Client, metric *v1alpha1.Metric, before time.Time, maxSnapshots int) error {
+	snapshots := &v1alpha1.MetricSnapshotList{}
+	if err := c.List(ctx, snapshots, client.InNamespace(metric.Namespace), client.MatchingLabels{v1alpha1.MetricSnapshotLabel: snapshotLabel(metric)}); err != nil {
+		return fmt.Errorf("failed to list the snapshots: %w", err)
+	}
+	// the newest snapshots come first
+	slices.SortFunc(snapshots.Items, func(a, b v1alpha1.MetricSnapshot) int {
+		return cmp.Compare(b.Spec.Timestamp.UnixNano(), a.Spec.Timestamp.UnixNano())
+	})
+	var errs []error
+	for i := range snapshots.Items {
+		if i < maxSnapshots && !snapshots.Items[i].Spec.Timestamp.Time.Before(before) {
+			continue
+		}
+		if err := c.Delete(ctx, &snapshots.Items[i]); client.IgnoreNotFound(err) != nil {
+			errs = append(errs, err)
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("failed to delete expired snapshots: %w", err)
+	}
+	return nil
+}
+
+// snapshotLabel returns the value of the MetricSnapshotLabel of the snapshots of the metric
+func snapshotLabel(metric *v1alpha1.Metric) string {
+	return shortenName(metric.Name, validation.LabelValueMaxLength)
+}
+
+// shortenName returns the name if it fits into the maximum length, or its beginning suffixed with a hash of the whole name
+func shortenName(name string, maxLength int) string {
+	if len(name) <= maxLength {
+		return name
+	}
+	hash := fmt.Sprintf("-%x", sha256.Sum256([]byte(name)))[:9]
+	return strings.TrimRight(name[:maxLength-len(hash)], "-.") + hash
+}
@@ -0,0 +1,117 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/openmcp-project/metrics-operator/api/v1alpha1"
+)
+
+func TestWriteSnapshot(t *testing.T) {
+	ctx := context.Background()
+	scheme := runtime.NewScheme()
+	require.NoError(t, v1alpha1.AddToScheme(scheme))
+
+	metric := &v1alpha1.Metric{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "pods", UID: "metric-uid"},
+		Spec:       v1alpha1.MetricSpec{Snapshot: true, SnapshotRetention: &metav1.Duration{Duration: time.Hour}},
+	}
+	snapshotAt := func(name string, metricName string, age time.Duration) *v1alpha1.MetricSnapshot {
+		return &v1alpha1.MetricSnapshot{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: name, Labels: map[string]string{v1alpha1.MetricSnapshotLabel: metricName}},
+			Spec:       v1alpha1.MetricSnapshotSpec{Metric: metricName, Timestamp: metav1.NewTime(time.Now().Add(-age))},
+		}
+	}
+	cli := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		metric,
+		snapshotAt("pods-recent", "pods", 30*time.Minute),
+		snapshotAt("pods-expired", "pods", 2*time.Hour),
+		snapshotAt("nodes-expired", "nodes", 2*time.Hour),
+	).Build()
+
+	now := metav1.Now()
+	name := fmt.Sprintf("pods-%d", now.Unix())
+	require.NoError(t, writeSnapshot(ctx, cli, metric, v1alpha1.MetricSnapshotSpec{
+		Timestamp: now,
+		Total:     3,
+		Series:    []v1alpha1.RecordedSeriesValue{{Dimensions: map[string]string{"phase": "Running"}, Value: 3}},
+	}))
+
+	snapshot := &v1alpha1.MetricSnapshot{}
+	require.NoError(t, cli.Get(ctx, client.ObjectKey{Namespace: "team-a", Name: name}, snapshot))
+	require.Equal(t, "pods", snapshot.Spec.Metric)
+	require.Equal(t, int64(3), snapshot.Spec.Total)
+	require.Len(t, snapshot.Spec.Series, 1)
+	require.Equal(t, "pods", snapshot.Labels[v1alpha1.MetricSnapshotLabel])
+	require.Len(t, snapshot.OwnerReferences, 1)
+	require.Equal(t, metric.UID, snapshot.OwnerReferences[0].UID)
+
+	// the snapshots of the metric older than its retention are deleted, those of other metrics are kept
+	snapshots := &v1alpha1.MetricSnapshotList{}
+	require.NoError(t, cli.List(ctx, snapshots, client.InNamespace("team-a")))
+	names := make([]string, 0, len(snapshots.Items))
+	for _, s := range snapshots.Items {
+		names = append(names, s.Name)
+	}
+	require.ElementsMatch(t, []string{name, "pods-recent", "nodes-expired"}, names)
+
+	// a retried collection keeps the first snapshot
+	require.NoError(t, writeSnapshot(ctx, cli, metric, v1alpha1.MetricSnapshotSpec{Timestamp: now, Total: 5}))
+	require.NoError(t, cli.Get(ctx, client.ObjectKey{Namespace: "team-a", Name: name}, snapshot))
+	require.Equal(t, int64(3), snapshot.Spec.Total)
+}
+
+func TestWriteSnapshot_maxSnapshots(t *testing.T) {
+	ctx := context.Background()
+	scheme := runtime.NewScheme()
+	require.NoError(t, v1alpha1.AddToScheme(scheme))
+
+	metric := &v1alpha1.Metric{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "pods", UID: "metric-uid"},
+		Spec:       v1alpha1.MetricSpec{Snapshot: true, MaxSnapshots: ptr.To[int32](2)},
+	}
+	cli := fake.NewClientBuilder().WithScheme(scheme).WithObjects(metric).Build()
+
+	now := time.Now()
+	for _, age := range []time.Duration{3 * time.Minute, 2 * time.Minute, time.Minute, 0} {
+		require.NoError(t, writeSnapshot(ctx, cli, metric, v1alpha1.MetricSnapshotSpec{Timestamp: metav1.NewTime(now.Add(-age))}))
+	}
+
+	// only the newest snapshots are kept
+	snapshots := &v1alpha1.MetricSnapshotList{}
+	require.NoError(t, cli.List(ctx, snapshots, client.InNamespace("team-a")))
+	names := make([]string, 0, len(snapshots.Items))
+	for _, s := range snapshots.Items {
+		names = append(names, s.Name)
+	}
+	require.ElementsMatch(t, []string{fmt.Sprintf("pods-%d", now.Add(-time.Minute).Unix()), fmt.Sprintf("pods-%d", now.Unix())}, names)
+}
+
+func TestWriteSnapshot_longName(t *testing.T) {
+	ctx := context.Background()
+	scheme := runtime.NewScheme()
+	require.NoError(t, v1alpha1.AddToScheme(scheme))
+
+	metric := &v1alpha1.Metric{ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: strings.Repeat("pods.", 50) + "by-phase", UID: "metric-uid"}}
+	cli := fake.NewClientBuilder().WithScheme(scheme).WithObjects(metric).Build()
+	require.NoError(t, writeSnapshot(ctx, cli, metric, v1alpha1.MetricSnapshotSpec{Timestamp: metav1.Now()}))
+
+	snapshots := &v1alpha1.MetricSnapshotList{}
+	require.NoError(t, cli.List(ctx, snapshots, client.MatchingLabels{v1alpha1.MetricSnapshotLabel: snapshotLabel(metric)}))
+	require.Len(t, snapshots.Items, 1)
+	snapshot := snapshots.Items[0]
+	require.Empty(t, validation.IsDNS1123Subdomain(snapshot.Name))
+	require.Empty(t, validation.IsValidLabelValue(snapshot.Labels[v1alpha1.MetricSnapshotLabel]))
+	require.Equal(t, metric.Name, snapshot.Spec.Metric)
+}
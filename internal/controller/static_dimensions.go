@@ -0,0 +1,171 @@
+package controller
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"maps"
+	"slices"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/openmcp-project/metrics-operator/api/v1alpha1"
+)
+
+// staticDimensionSourceIndex indexes metrics by the ConfigMaps and Secrets their static dimensions are read from
+const staticDimensionSourceIndex = "spec.staticDimensions.valueFrom"
+
+// staticDimensionsMetric is implemented by all metric types
+type staticDimensionsMetric interface {
+	prioritizedMetric
+	GetStaticDimensions() []v1alpha1.StaticDimension
+}
+
+// staticDimensions are the resolved values of the static dimensions of a metric
+type staticDimensions map[string]string
+
+// hash identifies the values of the static dimensions, it is empty if there are none
+func (d staticDimensions) hash() string {
+	if len(d) == 0 {
+		return ""
+	}
+	h := sha256.New()
+	for _, name := range slices.Sorted(maps.Keys(d)) {
+		_, _ = fmt.Fprintf(h, "%s=%s\n", name, d[name])
+	}
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
+
+// changedSince returns true if the values differ from the ones of the last export with the given hash.
+// Metrics that were not exported with static dimensions before and metrics with a cron schedule
+// pick up the values with their next scheduled export.
+func (d staticDimensions) changedSince(exportedHash string, schedule exportSchedule) bool {
+	return schedule.cron == nil && exportedHash != "" && exportedHash != d.hash()
+}
+
+// resolveStaticDimensions returns the values of the static dimensions of the metric,
+// values from ConfigMaps and Secrets are read from the namespace of the metric
+func resolveStaticDimensions(ctx context.Context, c client.Client, metric staticDimensionsMetric) (staticDimensions, error) {
+	dimensions := metric.GetStaticDimensions()
+	if len(dimensions) == 0 {
+		return nil, nil
+	}
+
+	resolved := make(staticDimensions, len(dimensions))
+	for _, dimension := range dimensions {
+		if dimension.ValueFrom == nil {
+			resolved[dimension.Name] = dimension.Value
+			continue
+		}
+
+		value, found, err := staticDimensionValue(ctx, c, metric.GetNamespace(), dimension.ValueFrom)
+		if err != nil {
+			return nil, fmt.Errorf("static dimension '%s': %w", dimension.Name, err)
+		}
+		if found {
+			resolved[dimension.Name] = value
+		}
+	}
+	return resolved, nil
+}
+
+// staticDimensionValue reads the value of the selected key, missing optional keys are not found without an error
+func staticDimensionValue(ctx context.Context, c client.Client, namespace string, source *v1alpha1.StaticDimensionSource) (string, bool, error) {
+	switch {
+	case source.ConfigMapKeyRef != nil:
+		ref := source.ConfigMapKeyRef
+		configMap := corev1.ConfigMap{}
+		if err := c.Get(ctx, types.NamespacedName{Namespace: namespace, Name: ref.Name}, &configMap); err != nil {
+			if apierrors.IsNotFound(err) && ptr.Deref(ref.Optional, false) {
+				return "", false, nil
+			}
+			return "", false, fmt.Errorf("failed to get ConfigMap '%s': %w", ref.Name, err)
+		}
+		value, ok := configMap.Data[ref.Key]
+		if !ok && !ptr.Deref(ref.Optional, false) {
+			return "", false, fmt.Errorf("key '%s' not found in ConfigMap '%s'", ref.Key, ref.Name)
+		}
+		return value, ok, nil
+	case source.SecretKeyRef != nil:
+		ref := source.SecretKeyRef
+		secret := corev1.Secret{}
+		if err := c.Get(ctx, types.NamespacedName{Namespace: namespace, Name: ref.Name}, &secret); err != nil {
+			if apierrors.IsNotFound(err) && ptr.Deref(ref.Optional, false) {
+				return "", false, nil
+			}
+			return "", false, fmt.Errorf("failed to get Secret '%s': %w", ref.Name, err)
+		}
+		value, ok := secret.Data[ref.Key]
+		if !ok && !ptr.Deref(ref.Optional, false) {
+			return "", false, fmt.Errorf("key '%s' not found in Secret '%s'", ref.Key, ref.Name)
+		}
+		return string(value), ok, nil
+	default:
+		return "", false, fmt.Errorf("neither configMapKeyRef nor secretKeyRef is set")
+	}
+}
+
+// staticDimensionSources returns the index keys of the ConfigMaps and Secrets the static dimensions are read from
+func staticDimensionSources(obj client.Object) []string {
+	metric, ok := obj.(staticDimensionsMetric)
+	if !ok {
+		return nil
+	}
+	var keys []string
+	for _, dimension := range metric.GetStaticDimensions() {
+		switch {
+		case dimension.ValueFrom == nil:
+		case dimension.ValueFrom.ConfigMapKeyRef != nil:
+			keys = append(keys, "ConfigMap/"+dimension.ValueFrom.ConfigMapKeyRef.Name)
+		case dimension.ValueFrom.SecretKeyRef != nil:
+			keys = append(keys, "Secret/"+dimension.ValueFrom.SecretKeyRef.Name)
+		}
+	}
+	return keys
+}
+
+// +kubebuilder:rbac:groups="",resources=configmaps;secrets,verbs=get;list;watch
+
+// watchStaticDimensionSources indexes the metrics of the list type by the sources of their static dimensions
+// and enqueues the metrics referencing a ConfigMap or Secret when it changes
+func watchStaticDimensionSources(mgr ctrl.Manager, b *builder.Builder, metric client.Object, newList func() client.ObjectList) (*builder.Builder, error) {
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), metric, staticDimensionSourceIndex, staticDimensionSources); err != nil {
+		return nil, err
+	}
+
+	c := mgr.GetClient()
+	log := mgr.GetLogger().WithName("controllers").WithName("StaticDimensions")
+	metricsFor := func(kind string) func(ctx context.Context, obj client.Object) []prioritizedMetric {
+		return func(ctx context.Context, obj client.Object) []prioritizedMetric {
+			list := newList()
+			if err := c.List(ctx, list, client.InNamespace(obj.GetNamespace()), client.MatchingFields{staticDimensionSourceIndex: kind + "/" + obj.GetName()}); err != nil {
+				log.Error(err, "unable to list metrics for static dimension source", "kind", kind, "name", obj.GetName())
+				return nil
+			}
+			items, err := meta.ExtractList(list)
+			if err != nil {
+				log.Error(err, "unable to extract metrics for static dimension source", "kind", kind, "name", obj.GetName())
+				return nil
+			}
+			metrics := make([]prioritizedMetric, 0, len(items))
+			for _, item := range items {
+				if m, ok := item.(prioritizedMetric); ok {
+					metrics = append(metrics, m)
+				}
+			}
+			return metrics
+		}
+	}
+
+	return b.
+		Watches(&corev1.ConfigMap{}, enqueueByPriority{metricsFor: metricsFor("ConfigMap")}).
+		Watches(&corev1.Secret{}, enqueueByPriority{metricsFor: metricsFor("Secret")}), nil
+}
@@ -0,0 +1,142 @@
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/openmcp-project/metrics-operator/api/v1alpha1"
+)
+
+func TestResolveStaticDimensions(t *testing.T) {
+	fromConfigMap := func(name, key string, optional bool) *v1alpha1.StaticDimensionSource {
+		return &v1alpha1.StaticDimensionSource{ConfigMapKeyRef: &corev1.ConfigMapKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{Name: name}, Key: key, Optional: ptr.To(optional),
+		}}
+	}
+
+	testCases := []struct {
+		name        string
+		dimensions  []v1alpha1.StaticDimension
+		expected    staticDimensions
+		expectedErr string
+	}{
+		{
+			name:     "None",
+			expected: nil,
+		},
+		{
+			name: "Value",
+			dimensions: []v1alpha1.StaticDimension{
+				{Name: "costCenter", Value: "cc-4711"},
+			},
+			expected: staticDimensions{"costCenter": "cc-4711"},
+		},
+		{
+			name: "ConfigMapAndSecret",
+			dimensions: []v1alpha1.StaticDimension{
+				{Name: "tenantId", ValueFrom: fromConfigMap("tenant", "tenantId", false)},
+				{Name: "contract", ValueFrom: &v1alpha1.StaticDimensionSource{SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "billing"}, Key: "contract",
+				}}},
+			},
+			expected: staticDimensions{"tenantId": "t-42", "contract": "c-1"},
+		},
+		{
+			name: "MissingKey",
+			dimensions: []v1alpha1.StaticDimension{
+				{Name: "tenantId", ValueFrom: fromConfigMap("tenant", "unknown", false)},
+			},
+			expectedErr: "static dimension 'tenantId': key 'unknown' not found in ConfigMap 'tenant'",
+		},
+		{
+			name: "MissingConfigMap",
+			dimensions: []v1alpha1.StaticDimension{
+				{Name: "tenantId", ValueFrom: fromConfigMap("missing", "tenantId", false)},
+			},
+			expectedErr: "failed to get ConfigMap 'missing'",
+		},
+		{
+			name: "MissingOptional",
+			dimensions: []v1alpha1.StaticDimension{
+				{Name: "tenantId", ValueFrom: fromConfigMap("missing", "tenantId", true)},
+				{Name: "region", ValueFrom: fromConfigMap("tenant", "region", true)},
+				{Name: "costCenter", Value: "cc-4711"},
+			},
+			expected: staticDimensions{"costCenter": "cc-4711"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			require.NoError(t, corev1.AddToScheme(scheme))
+			cli := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+				&corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "tenant"},
+					Data:       map[string]string{"tenantId": "t-42"},
+				},
+				&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "billing"},
+					Data:       map[string][]byte{"contract": []byte("c-1")},
+				},
+			).Build()
+
+			metric := &v1alpha1.Metric{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "pods"},
+				Spec:       v1alpha1.MetricSpec{StaticDimensions: tc.dimensions},
+			}
+
+			dimensions, err := resolveStaticDimensions(context.Background(), cli, metric)
+			if tc.expectedErr != "" {
+				require.ErrorContains(t, err, tc.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, dimensions)
+		})
+	}
+}
+
+func TestStaticDimensions_changedSince(t *testing.T) {
+	withScheduling(t, SchedulingOptions{})
+	metric := scheduledMetric("pods", time.Now())
+	interval, err := newExportSchedule(metav1.Duration{Duration: time.Hour}, "", metric)
+	require.NoError(t, err)
+	cron, err := newExportSchedule(metav1.Duration{}, "@daily", metric)
+	require.NoError(t, err)
+
+	exported := staticDimensions{"tenantId": "t-42"}
+	changed := staticDimensions{"tenantId": "t-43"}
+
+	require.Empty(t, staticDimensions(nil).hash())
+	require.Equal(t, exported.hash(), staticDimensions{"tenantId": "t-42"}.hash())
+
+	require.False(t, exported.changedSince(exported.hash(), interval))
+	require.True(t, changed.changedSince(exported.hash(), interval))
+	require.True(t, staticDimensions(nil).changedSince(exported.hash(), interval), "removed values are exported right away")
+	require.False(t, changed.changedSince("", interval), "metrics exported without static dimensions wait for their next export")
+	require.False(t, changed.changedSince(exported.hash(), cron), "metrics with a cron schedule are only exported at the scheduled times")
+}
+
+func TestStaticDimensionSources(t *testing.T) {
+	metric := &v1alpha1.ManagedMetric{Spec: v1alpha1.ManagedMetricSpec{StaticDimensions: []v1alpha1.StaticDimension{
+		{Name: "costCenter", Value: "cc-4711"},
+		{Name: "tenantId", ValueFrom: &v1alpha1.StaticDimensionSource{ConfigMapKeyRef: &corev1.ConfigMapKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{Name: "tenant"}, Key: "tenantId",
+		}}},
+		{Name: "contract", ValueFrom: &v1alpha1.StaticDimensionSource{SecretKeyRef: &corev1.SecretKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{Name: "billing"}, Key: "contract",
+		}}},
+	}}}
+
+	require.Equal(t, []string{"ConfigMap/tenant", "Secret/billing"}, staticDimensionSources(metric))
+	require.Nil(t, staticDimensionSources(&corev1.ConfigMap{}))
+}
@@ -0,0 +1,130 @@
+package controller
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"sync"
+
+	apiextensionshelpers "k8s.io/apiextensions-apiserver/pkg/apihelpers"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	"github.com/openmcp-project/metrics-operator/api/v1alpha1"
+)
+
+// targetKindIndex indexes the metrics of the local cluster by the kinds they list
+const targetKindIndex = "spec.target.groupKind"
+
+// targetKindKey returns the index key of a kind, kinds are matched case-insensitively like by discovery
+func targetKindKey(group, kind string) string {
+	return schema.GroupKind{Group: group, Kind: strings.ToLower(kind)}.String()
+}
+
+// targetKinds returns the index keys of the kinds listed by a metric of the local cluster
+func targetKinds(obj client.Object) []string {
+	metric, ok := obj.(*v1alpha1.Metric)
+	if !ok || metric.Spec.RemoteClusterAccessRef != nil {
+		return nil
+	}
+	keys := []string{targetKindKey(metric.Spec.Target.Group, metric.Spec.Target.Kind)}
+	for _, target := range metric.Spec.Targets {
+		keys = append(keys, targetKindKey(target.Target.Group, target.Target.Kind))
+	}
+	for _, enrichment := range metric.Spec.Enrichments {
+		keys = append(keys, targetKindKey(enrichment.Target.Group, enrichment.Target.Kind))
+	}
+	slices.Sort(keys)
+	return slices.Compact(keys)
+}
+
+// targetKindChanges remembers the metrics whose target kinds were installed or removed since their last collection,
+// they are collected right away instead of at their next run
+type targetKindChanges struct {
+	mu      sync.Mutex
+	metrics map[types.NamespacedName]bool
+}
+
+func (c *targetKindChanges) add(key types.NamespacedName) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.metrics == nil {
+		c.metrics = map[types.NamespacedName]bool{}
+	}
+	c.metrics[key] = true
+}
+
+// take returns true if a target kind of the metric changed, and forgets the change
+func (c *targetKindChanges) take(key types.NamespacedName) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	changed := c.metrics[key]
+	delete(c.metrics, key)
+	return changed
+}
+
+// servedVersions returns the versions served by the CRD, none before it is established
+func servedVersions(crd *apiextensionsv1.CustomResourceDefinition) []string {
+	if !apiextensionshelpers.IsCRDConditionTrue(crd, apiextensionsv1.Established) {
+		return nil
+	}
+	var versions []string
+	for _, version := range crd.Spec.Versions {
+		if version.Served {
+			versions = append(versions, version.Name)
+		}
+	}
+	return versions
+}
+
+// servedVersionsChanged passes the creation and deletion of CRDs and the updates changing their served versions.
+// The CRDs listed when the operator starts are skipped, the metrics are collected anyway then.
+var servedVersionsChanged = predicate.Funcs{
+	CreateFunc: func(e event.CreateEvent) bool {
+		return !e.IsInInitialList
+	},
+	UpdateFunc: func(e event.UpdateEvent) bool {
+		oldCRD, okOld := e.ObjectOld.(*apiextensionsv1.CustomResourceDefinition)
+		newCRD, okNew := e.ObjectNew.(*apiextensionsv1.CustomResourceDefinition)
+		return okOld && okNew && !slices.Equal(servedVersions(oldCRD), servedVersions(newCRD))
+	},
+}
+
+// +kubebuilder:rbac:groups=apiextensions.k8s.io,resources=customresourcedefinitions,verbs=get;list;watch
+
+// watchTargetKinds indexes the metrics by the kinds they list and collects the metrics listing the kind of a CRD
+// right away when the CRD is installed or removed, or its served versions change
+func watchTargetKinds(mgr ctrl.Manager, b *builder.Builder, changes *targetKindChanges) (*builder.Builder, error) {
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &v1alpha1.Metric{}, targetKindIndex, targetKinds); err != nil {
+		return nil, err
+	}
+
+	c := mgr.GetClient()
+	log := mgr.GetLogger().WithName("controllers").WithName("TargetKinds")
+	metricsFor := func(ctx context.Context, obj client.Object) []prioritizedMetric {
+		crd, ok := obj.(*apiextensionsv1.CustomResourceDefinition)
+		if !ok {
+			return nil
+		}
+		metrics := &v1alpha1.MetricList{}
+		if err := c.List(ctx, metrics, client.MatchingFields{targetKindIndex: targetKindKey(crd.Spec.Group, crd.Spec.Names.Kind)}); err != nil {
+			log.Error(err, "unable to list metrics for CRD", "name", crd.Name)
+			return nil
+		}
+		items := make([]prioritizedMetric, 0, len(metrics.Items))
+		for i := range metrics.Items {
+			changes.add(client.ObjectKeyFromObject(&metrics.Items[i]))
+			items = append(items, &metrics.Items[i])
+		}
+		return items
+	}
+
+	return b.Watches(&apiextensionsv1.CustomResourceDefinition{}, enqueueByPriority{metricsFor: metricsFor},
+		builder.WithPredicates(servedVersionsChanged)), nil
+}
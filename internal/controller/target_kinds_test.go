@@ -0,0 +1,71 @@
+package controller
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	"github.com/openmcp-project/metrics-operator/api/v1alpha1"
+)
+
+func TestTargetKinds(t *testing.T) {
+	buckets := v1alpha1.GroupVersionKind{Group: "example.com", Version: "v1", Kind: "Bucket"}
+	metric := &v1alpha1.Metric{Spec: v1alpha1.MetricSpec{
+		Target:      v1alpha1.MetricTarget{GroupVersionKind: buckets},
+		Targets:     []v1alpha1.NamedTarget{{Name: "buckets", Target: v1alpha1.MetricTarget{GroupVersionKind: buckets}}},
+		Enrichments: []v1alpha1.Enrichment{{Target: v1alpha1.GroupVersionKind{Version: "v1", Kind: "Namespace"}}},
+	}}
+	require.Equal(t, []string{"bucket.example.com", "namespace"}, targetKinds(metric))
+
+	metric.Spec.RemoteClusterAccessRef = &v1alpha1.RemoteClusterAccessRef{Name: "remote"}
+	require.Empty(t, targetKinds(metric))
+
+	require.Equal(t, "bucket.example.com", targetKindKey("example.com", "Bucket"))
+}
+
+func TestServedVersionsChanged(t *testing.T) {
+	crd := func(established bool, served ...string) *apiextensionsv1.CustomResourceDefinition {
+		crd := &apiextensionsv1.CustomResourceDefinition{}
+		for _, version := range []string{"v1alpha1", "v1"} {
+			crd.Spec.Versions = append(crd.Spec.Versions, apiextensionsv1.CustomResourceDefinitionVersion{Name: version, Served: slices.Contains(served, version)})
+		}
+		if established {
+			crd.Status.Conditions = []apiextensionsv1.CustomResourceDefinitionCondition{{Type: apiextensionsv1.Established, Status: apiextensionsv1.ConditionTrue}}
+		}
+		return crd
+	}
+
+	tests := []struct {
+		name     string
+		old, new *apiextensionsv1.CustomResourceDefinition
+		want     bool
+	}{
+		{name: "established", old: crd(false, "v1"), new: crd(true, "v1"), want: true},
+		{name: "version served", old: crd(true, "v1"), new: crd(true, "v1alpha1", "v1"), want: true},
+		{name: "version no longer served", old: crd(true, "v1alpha1", "v1"), new: crd(true, "v1"), want: true},
+		{name: "unchanged", old: crd(true, "v1"), new: crd(true, "v1")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, servedVersionsChanged.Update(event.UpdateEvent{ObjectOld: tt.old, ObjectNew: tt.new}))
+		})
+	}
+
+	require.True(t, servedVersionsChanged.Create(event.CreateEvent{Object: crd(false)}))
+	require.False(t, servedVersionsChanged.Create(event.CreateEvent{Object: crd(true, "v1"), IsInInitialList: true}))
+	require.True(t, servedVersionsChanged.Delete(event.DeleteEvent{Object: crd(true, "v1")}))
+}
+
+func TestTargetKindChanges(t *testing.T) {
+	changes := targetKindChanges{}
+	key := types.NamespacedName{Namespace: "team-a", Name: "buckets"}
+	require.False(t, changes.take(key))
+
+	changes.add(key)
+	require.True(t, changes.take(key))
+	require.False(t, changes.take(key), "the change is taken once")
+}
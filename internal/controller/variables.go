@@ -0,0 +1,83 @@
+package controller
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/openmcp-project/metrics-operator/api/v1alpha1"
+)
+
+// Variables describe the environment the operator runs in, e.g. the name of its cluster or landscape.
+// Their placeholders $(<NAME>) are replaced in the spec of the metrics when they are reconciled,
+// so the same metric can be deployed unchanged to every landscape.
+var Variables map[string]string
+
+// placeholderPattern matches the placeholders $(<NAME>) of names accepted by validVariableName
+var placeholderPattern = regexp.MustCompile(`\$\([^$()]+\)`)
+
+// renderVariables replaces the placeholders of the variables and of the name and namespace of the metric
+// in the string fields of its spec. Placeholders of unknown variables are kept.
+// The spec is rendered in memory only, the stored metric keeps its placeholders.
+func renderVariables[T any](metric metav1.Object, spec *T) error {
+	data, err := json.Marshal(spec)
+	if err != nil {
+		return err
+	}
+	if !bytes.Contains(data, []byte("$(")) {
+		return nil
+	}
+
+	settingsMu.RLock()
+	replacements := make(map[string]string, len(Variables)+2)
+	for name, value := range Variables {
+		replacements["$("+name+")"] = value
+	}
+	settingsMu.RUnlock()
+	// the name and namespace of the metric can't be overridden by variables
+	replacements[v1alpha1.MetricNamePlaceholder] = metric.GetName()
+	replacements[v1alpha1.MetricNamespacePlaceholder] = metric.GetNamespace()
+	// all placeholders are replaced in a single pass, so values containing placeholders are not expanded again
+	data = placeholderPattern.ReplaceAllFunc(data, func(placeholder []byte) []byte {
+		value, ok := replacements[string(placeholder)]
+		if !ok {
+			return placeholder
+		}
+		// the values are escaped, as they are placed within JSON strings, marshalling a string can't fail
+		escaped, _ := json.Marshal(value)
+		return escaped[1 : len(escaped)-1]
+	})
+
+	var rendered T
+	if err := json.Unmarshal(data, &rendered); err != nil {
+		return fmt.Errorf("failed to replace the variables of %s/%s: %w", metric.GetNamespace(), metric.GetName(), err)
+	}
+	*spec = rendered
+	return nil
+}
+
+// ParseVariables parses variables given as NAME=value pairs separated by commas, e.g. CLUSTER_NAME=prod-eu,LANDSCAPE=live
+func ParseVariables(s string) (map[string]string, error) {
+	variables := map[string]string{}
+	if s == "" {
+		return variables, nil
+	}
+	for pair := range strings.SplitSeq(s, ",") {
+		name, value, ok := strings.Cut(pair, "=")
+		name = strings.TrimSpace(name)
+		if !ok || !validVariableName(name) {
+			return nil, fmt.Errorf("invalid variable '%s', expected NAME=value", pair)
+		}
+		variables[name] = value
+	}
+	return variables, nil
+}
+
+// validVariableName returns true if the name can be used in a placeholder
+func validVariableName(name string) bool {
+	return name != "" && !strings.ContainsAny(name, "$()")
+}
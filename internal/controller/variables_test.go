@@ -0,0 +1,86 @@
+package controller
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/openmcp-project/metrics-operator/api/v1alpha1"
+)
+
+// withVariables replaces the variables of the operator for the duration of the test
+func withVariables(t *testing.T, variables map[string]string) {
+	previous := Variables
+	Variables = variables
+	t.Cleanup(func() { Variables = previous })
+}
+
+func TestRenderVariables(t *testing.T) {
+	withVariables(t, map[string]string{"CLUSTER_NAME": "prod-eu", "QUOTED": `say "hi"`, "METRIC_NAME": "overridden"})
+	metric := &v1alpha1.Metric{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "pods"},
+		Spec: v1alpha1.MetricSpec{
+			Name:          "pods_$(CLUSTER_NAME)",
+			Description:   "$(QUOTED) from $(METRIC_NAME) in $(METRIC_NAMESPACE)",
+			LabelSelector: "team=$(METRIC_NAMESPACE),stage=$(UNKNOWN)",
+			Interval:      metav1.Duration{Duration: 5 * time.Minute},
+			StaticDimensions: []v1alpha1.StaticDimension{
+				{Name: "cluster", Value: "$(CLUSTER_NAME)"},
+			},
+		},
+	}
+
+	require.NoError(t, renderVariables(metric, &metric.Spec))
+	require.Equal(t, "pods_prod-eu", metric.Spec.Name)
+	require.Equal(t, `say "hi" from pods in team-a`, metric.Spec.Description)
+	// placeholders of unknown variables are kept
+	require.Equal(t, "team=team-a,stage=$(UNKNOWN)", metric.Spec.LabelSelector)
+	require.Equal(t, "prod-eu", metric.Spec.StaticDimensions[0].Value)
+	require.Equal(t, 5*time.Minute, metric.Spec.Interval.Duration)
+}
+
+func TestRenderVariables_singlePass(t *testing.T) {
+	// values containing placeholders are not expanded again, whatever order the variables are replaced in
+	withVariables(t, map[string]string{"A": "$(B)", "B": "$(METRIC_NAME)", "C": "$(A)"})
+	metric := &v1alpha1.Metric{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "pods"},
+		Spec:       v1alpha1.MetricSpec{Name: "pods", Description: "$(A) $(B) $(C) $$(A)"},
+	}
+
+	for range 10 {
+		spec := metric.Spec
+		require.NoError(t, renderVariables(metric, &spec))
+		require.Equal(t, "$(B) $(METRIC_NAME) $(A) $$(B)", spec.Description)
+	}
+}
+
+func TestParseVariables(t *testing.T) {
+	testCases := []struct {
+		name    string
+		in      string
+		want    map[string]string
+		wantErr bool
+	}{
+		{name: "Empty", in: "", want: map[string]string{}},
+		{name: "Pairs", in: "CLUSTER_NAME=prod-eu, LANDSCAPE=live", want: map[string]string{"CLUSTER_NAME": "prod-eu", "LANDSCAPE": "live"}},
+		{name: "EmptyValue", in: "LANDSCAPE=", want: map[string]string{"LANDSCAPE": ""}},
+		{name: "ValueWithEquals", in: "URL=https://example.com/?a=b", want: map[string]string{"URL": "https://example.com/?a=b"}},
+		{name: "MissingValue", in: "LANDSCAPE", wantErr: true},
+		{name: "MissingName", in: "=live", wantErr: true},
+		{name: "Placeholder", in: "$(LANDSCAPE)=live", wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := ParseVariables(tc.in)
+			if tc.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.want, got)
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package crdinstall
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+)
+
+// IncompatibleChanges returns the changes from the installed to the embedded CRD that break existing objects or clients:
+// a changed scope, removed versions, and removed fields, changed field types and newly required fields of the schemas.
+// Added versions and fields are compatible.
+func IncompatibleChanges(installed, embedded *apiextensionsv1.CustomResourceDefinition) []string {
+	var changes []string
+	if installed.Spec.Scope != embedded.Spec.Scope {
+		changes = append(changes, fmt.Sprintf("scope changes from %s to %s", installed.Spec.Scope, embedded.Spec.Scope))
+	}
+
+	embeddedVersions := map[string]*apiextensionsv1.CustomResourceDefinitionVersion{}
+	for i := range embedded.Spec.Versions {
+		embeddedVersions[embedded.Spec.Versions[i].Name] = &embedded.Spec.Versions[i]
+	}
+	for _, version := range installed.Spec.Versions {
+		embeddedVersion, ok := embeddedVersions[version.Name]
+		if !ok {
+			changes = append(changes, fmt.Sprintf("version %s is removed", version.Name))
+			continue
+		}
+		if version.Served && !embeddedVersion.Served {
+			changes = append(changes, fmt.Sprintf("version %s is no longer served", version.Name))
+		}
+		if version.Schema == nil || embeddedVersion.Schema == nil {
+			continue
+		}
+		for _, change := range schemaChanges("", version.Schema.OpenAPIV3Schema, embeddedVersion.Schema.OpenAPIV3Schema) {
+			changes = append(changes, version.Name+": "+change)
+		}
+	}
+	for _, stored := range installed.Status.StoredVersions {
+		if _, ok := embeddedVersions[stored]; !ok {
+			changes = append(changes, fmt.Sprintf("objects are stored in version %s, which is removed", stored))
+		}
+	}
+	return changes
+}
+
+// schemaChanges compares the schema of a field and its nested fields
+func schemaChanges(field string, installed, embedded *apiextensionsv1.JSONSchemaProps) []string {
+	if installed == nil || embedded == nil {
+		return nil
+	}
+	name := field
+	if name == "" {
+		name = "the root"
+	}
+	if installed.Type != "" && embedded.Type != "" && installed.Type != embedded.Type {
+		return []string{fmt.Sprintf("type of %s changes from %s to %s", name, installed.Type, embedded.Type)}
+	}
+
+	var changes []string
+	for _, property := range slices.Sorted(maps.Keys(installed.Properties)) {
+		child := property
+		if field != "" {
+			child = field + "." + property
+		}
+		embeddedProperty, ok := embedded.Properties[property]
+		if !ok {
+			// fields of an object that preserves unknown fields are kept, just no longer validated
+			if embedded.XPreserveUnknownFields == nil || !*embedded.XPreserveUnknownFields {
+				changes = append(changes, fmt.Sprintf("field %s is removed", child))
+			}
+			continue
+		}
+		installedProperty := installed.Properties[property]
+		changes = append(changes, schemaChanges(child, &installedProperty, &embeddedProperty)...)
+	}
+	for _, required := range embedded.Required {
+		if !slices.Contains(installed.Required, required) {
+			child := required
+			if field != "" {
+				child = field + "." + required
+			}
+			changes = append(changes, fmt.Sprintf("field %s becomes required", child))
+		}
+	}
+	if installed.Items != nil && embedded.Items != nil {
+		changes = append(changes, schemaChanges(field+"[]", installed.Items.Schema, embedded.Items.Schema)...)
+	}
+	if installed.AdditionalProperties != nil && embedded.AdditionalProperties != nil {
+		changes = append(changes, schemaChanges(field+"[*]", installed.AdditionalProperties.Schema, embedded.AdditionalProperties.Schema)...)
+	}
+	return changes
+}
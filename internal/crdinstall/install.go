@@ -0,0 +1,142 @@
+// Package crdinstall installs the CRDs embedded in the operator, refusing upgrades that would
+// remove versions or fields from the CRDs installed in the cluster, e.g. when an older operator is started.
+package crdinstall
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Actions taken for a CRD
+const (
+	ActionCreated   = "Created"
+	ActionUpdated   = "Updated"
+	ActionUnchanged = "Unchanged"
+	ActionRefused   = "Refused"
+)
+
+// ErrIncompatible is returned if the upgrade of a CRD was refused
+var ErrIncompatible = errors.New("incompatible CRD upgrades were refused")
+
+// Options configure the installation
+type Options struct {
+	// AllowIncompatible applies the embedded CRDs even if they remove versions or fields of the installed ones
+	AllowIncompatible bool
+}
+
+// Result is the outcome of the installation of a CRD
+type Result struct {
+	Name   string
+	Action string
+	// Changes are the incompatible changes of the schema, they were applied if the CRD was updated
+	Changes []string
+}
+
+// Install creates the CRDs read from the YAML files of crdFiles, or updates the installed ones.
+// An update that is incompatible with the installed CRD is refused, unless the options allow it,
+// the other CRDs are installed anyway and ErrIncompatible is returned.
+// The conversion webhook settings of installed CRDs are preserved.
+func Install(ctx context.Context, c client.Client, crdFiles fs.FS, opts Options) ([]Result, error) {
+	crds, err := readCRDs(crdFiles)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]Result, 0, len(crds))
+	refused := false
+	for _, crd := range crds {
+		result, err := install(ctx, c, crd, opts)
+		if err != nil {
+			return results, fmt.Errorf("failed to install CRD %s: %w", crd.Name, err)
+		}
+		refused = refused || result.Action == ActionRefused
+		results = append(results, result)
+	}
+	if refused {
+		return results, ErrIncompatible
+	}
+	return results, nil
+}
+
+func install(ctx context.Context, c client.Client, crd *apiextensionsv1.CustomResourceDefinition, opts Options) (Result, error) {
+	result := Result{Name: crd.Name}
+
+	installed := &apiextensionsv1.CustomResourceDefinition{}
+	err := c.Get(ctx, client.ObjectKey{Name: crd.Name}, installed)
+	if apierrors.IsNotFound(err) {
+		result.Action = ActionCreated
+		return result, c.Create(ctx, crd)
+	}
+	if err != nil {
+		return result, err
+	}
+
+	result.Changes = IncompatibleChanges(installed, crd)
+	if len(result.Changes) > 0 && !opts.AllowIncompatible {
+		result.Action = ActionRefused
+		return result, nil
+	}
+
+	spec := crd.Spec.DeepCopy()
+	preserveConversion(spec, &installed.Spec)
+	if equality.Semantic.DeepEqual(&installed.Spec, spec) {
+		result.Action = ActionUnchanged
+		return result, nil
+	}
+	installed.Spec = *spec
+	result.Action = ActionUpdated
+	return result, c.Update(ctx, installed)
+}
+
+// preserveConversion keeps the conversion webhook of the installed CRD,
+// its client config is set up for the cluster and not part of the embedded CRD
+func preserveConversion(spec, installed *apiextensionsv1.CustomResourceDefinitionSpec) {
+	if installed.Conversion == nil || installed.Conversion.Strategy != apiextensionsv1.WebhookConverter {
+		return
+	}
+	if spec.Conversion == nil || spec.Conversion.Strategy == apiextensionsv1.NoneConverter {
+		spec.Conversion = installed.Conversion.DeepCopy()
+		return
+	}
+	if spec.Conversion.Webhook == nil {
+		spec.Conversion.Webhook = installed.Conversion.Webhook.DeepCopy()
+		return
+	}
+	if spec.Conversion.Webhook.ClientConfig == nil && installed.Conversion.Webhook != nil {
+		spec.Conversion.Webhook.ClientConfig = installed.Conversion.Webhook.ClientConfig.DeepCopy()
+	}
+}
+
+// readCRDs decodes the CRDs of all files in crdFiles, in the order of their paths
+func readCRDs(crdFiles fs.FS) ([]*apiextensionsv1.CustomResourceDefinition, error) {
+	var crds []*apiextensionsv1.CustomResourceDefinition
+	err := fs.WalkDir(crdFiles, ".", func(file string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+		if ext := path.Ext(file); ext != ".yaml" && ext != ".yml" {
+			return nil
+		}
+		content, err := fs.ReadFile(crdFiles, file)
+		if err != nil {
+			return err
+		}
+		crd := &apiextensionsv1.CustomResourceDefinition{}
+		if err := yaml.NewYAMLOrJSONDecoder(bytes.NewReader(content), 4096).Decode(crd); err != nil {
+			return fmt.Errorf("failed to decode %s: %w", file, err)
+		}
+		crds = append(crds, crd)
+		return nil
+	})
+	return crds, err
+}
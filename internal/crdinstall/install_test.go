@@ -0,0 +1,236 @@
+package crdinstall
+
+import (
+	"context"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/require"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/yaml"
+)
+
+// widgetCRD returns a CRD whose spec has the given string fields
+func widgetCRD(versions []string, fields ...string) *apiextensionsv1.CustomResourceDefinition {
+	properties := map[string]apiextensionsv1.JSONSchemaProps{}
+	for _, field := range fields {
+		properties[field] = apiextensionsv1.JSONSchemaProps{Type: "string"}
+	}
+	crd := &apiextensionsv1.CustomResourceDefinition{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "apiextensions.k8s.io/v1", Kind: "CustomResourceDefinition"},
+		ObjectMeta: metav1.ObjectMeta{Name: "widgets.example.com"},
+		Spec: apiextensionsv1.CustomResourceDefinitionSpec{
+			Group: "example.com",
+			Names: apiextensionsv1.CustomResourceDefinitionNames{Plural: "widgets", Kind: "Widget"},
+			Scope: apiextensionsv1.NamespaceScoped,
+		},
+	}
+	for i, version := range versions {
+		crd.Spec.Versions = append(crd.Spec.Versions, apiextensionsv1.CustomResourceDefinitionVersion{
+			Name:    version,
+			Served:  true,
+			Storage: i == len(versions)-1,
+			Schema: &apiextensionsv1.CustomResourceValidation{OpenAPIV3Schema: &apiextensionsv1.JSONSchemaProps{
+				Type: "object",
+				Properties: map[string]apiextensionsv1.JSONSchemaProps{
+					"spec": {Type: "object", Properties: properties},
+				},
+			}},
+		})
+	}
+	return crd
+}
+
+func crdFiles(t *testing.T, crd *apiextensionsv1.CustomResourceDefinition) fstest.MapFS {
+	t.Helper()
+	data, err := yaml.Marshal(crd)
+	require.NoError(t, err)
+	return fstest.MapFS{"crds/example.com_widgets.yaml": {Data: data}, "crds/README.md": {Data: []byte("# CRDs")}}
+}
+
+func newClient(t *testing.T, objects ...client.Object) client.Client {
+	t.Helper()
+	scheme := runtime.NewScheme()
+	require.NoError(t, apiextensionsv1.AddToScheme(scheme))
+	return fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build()
+}
+
+func TestInstall(t *testing.T) {
+	tests := []struct {
+		name        string
+		installed   *apiextensionsv1.CustomResourceDefinition
+		embedded    *apiextensionsv1.CustomResourceDefinition
+		opts        Options
+		wantAction  string
+		wantChanges []string
+		wantErr     error
+		wantFields  []string
+	}{
+		{
+			name:       "not installed",
+			embedded:   widgetCRD([]string{"v1"}, "size"),
+			wantAction: ActionCreated,
+			wantFields: []string{"size"},
+		},
+		{
+			name:       "unchanged",
+			installed:  widgetCRD([]string{"v1"}, "size"),
+			embedded:   widgetCRD([]string{"v1"}, "size"),
+			wantAction: ActionUnchanged,
+			wantFields: []string{"size"},
+		},
+		{
+			name:       "added field",
+			installed:  widgetCRD([]string{"v1"}, "size"),
+			embedded:   widgetCRD([]string{"v1"}, "size", "color"),
+			wantAction: ActionUpdated,
+			wantFields: []string{"color", "size"},
+		},
+		{
+			name:        "removed field is refused",
+			installed:   widgetCRD([]string{"v1"}, "size", "color"),
+			embedded:    widgetCRD([]string{"v1"}, "size"),
+			wantAction:  ActionRefused,
+			wantChanges: []string{"v1: field spec.color is removed"},
+			wantErr:     ErrIncompatible,
+			wantFields:  []string{"color", "size"},
+		},
+		{
+			name:        "removed field is applied if allowed",
+			installed:   widgetCRD([]string{"v1"}, "size", "color"),
+			embedded:    widgetCRD([]string{"v1"}, "size"),
+			opts:        Options{AllowIncompatible: true},
+			wantAction:  ActionUpdated,
+			wantChanges: []string{"v1: field spec.color is removed"},
+			wantFields:  []string{"size"},
+		},
+		{
+			name:        "removed version is refused",
+			installed:   widgetCRD([]string{"v1", "v2"}, "size"),
+			embedded:    widgetCRD([]string{"v1"}, "size"),
+			wantAction:  ActionRefused,
+			wantChanges: []string{"version v2 is removed"},
+			wantErr:     ErrIncompatible,
+			wantFields:  []string{"size"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var objects []client.Object
+			if tt.installed != nil {
+				objects = append(objects, tt.installed)
+			}
+			cli := newClient(t, objects...)
+
+			results, err := Install(context.Background(), cli, crdFiles(t, tt.embedded), tt.opts)
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+			require.Len(t, results, 1)
+			require.Equal(t, "widgets.example.com", results[0].Name)
+			require.Equal(t, tt.wantAction, results[0].Action)
+			require.Equal(t, tt.wantChanges, results[0].Changes)
+
+			crd := &apiextensionsv1.CustomResourceDefinition{}
+			require.NoError(t, cli.Get(context.Background(), client.ObjectKey{Name: "widgets.example.com"}, crd))
+			fields := crd.Spec.Versions[0].Schema.OpenAPIV3Schema.Properties["spec"].Properties
+			require.Len(t, fields, len(tt.wantFields))
+			for _, field := range tt.wantFields {
+				require.Contains(t, fields, field)
+			}
+		})
+	}
+}
+
+func TestInstall_preservesConversion(t *testing.T) {
+	installed := widgetCRD([]string{"v1"}, "size")
+	installed.Spec.Conversion = &apiextensionsv1.CustomResourceConversion{
+		Strategy: apiextensionsv1.WebhookConverter,
+		Webhook: &apiextensionsv1.WebhookConversion{
+			ConversionReviewVersions: []string{"v1"},
+			ClientConfig: &apiextensionsv1.WebhookClientConfig{
+				Service: &apiextensionsv1.ServiceReference{Namespace: "metrics", Name: "webhook", Path: ptr.To("/convert")},
+			},
+		},
+	}
+	cli := newClient(t, installed)
+
+	results, err := Install(context.Background(), cli, crdFiles(t, widgetCRD([]string{"v1"}, "size", "color")), Options{})
+	require.NoError(t, err)
+	require.Equal(t, ActionUpdated, results[0].Action)
+
+	crd := &apiextensionsv1.CustomResourceDefinition{}
+	require.NoError(t, cli.Get(context.Background(), client.ObjectKey{Name: "widgets.example.com"}, crd))
+	require.Equal(t, installed.Spec.Conversion, crd.Spec.Conversion)
+	require.Contains(t, crd.Spec.Versions[0].Schema.OpenAPIV3Schema.Properties["spec"].Properties, "color")
+}
+
+func TestIncompatibleChanges(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(installed, embedded *apiextensionsv1.CustomResourceDefinition)
+		want   []string
+	}{
+		{
+			name:   "compatible",
+			modify: func(_, _ *apiextensionsv1.CustomResourceDefinition) {},
+		},
+		{
+			name: "scope",
+			modify: func(_, embedded *apiextensionsv1.CustomResourceDefinition) {
+				embedded.Spec.Scope = apiextensionsv1.ClusterScoped
+			},
+			want: []string{"scope changes from Namespaced to Cluster"},
+		},
+		{
+			name: "type",
+			modify: func(_, embedded *apiextensionsv1.CustomResourceDefinition) {
+				embedded.Spec.Versions[0].Schema.OpenAPIV3Schema.Properties["spec"].Properties["size"] = apiextensionsv1.JSONSchemaProps{Type: "integer"}
+			},
+			want: []string{"v1: type of spec.size changes from string to integer"},
+		},
+		{
+			name: "required",
+			modify: func(_, embedded *apiextensionsv1.CustomResourceDefinition) {
+				spec := embedded.Spec.Versions[0].Schema.OpenAPIV3Schema.Properties["spec"]
+				spec.Required = []string{"size"}
+				embedded.Spec.Versions[0].Schema.OpenAPIV3Schema.Properties["spec"] = spec
+			},
+			want: []string{"v1: field spec.size becomes required"},
+		},
+		{
+			name: "removed field of an object preserving unknown fields",
+			modify: func(_, embedded *apiextensionsv1.CustomResourceDefinition) {
+				embedded.Spec.Versions[0].Schema.OpenAPIV3Schema.Properties["spec"] = apiextensionsv1.JSONSchemaProps{Type: "object", XPreserveUnknownFields: ptr.To(true)}
+			},
+		},
+		{
+			name: "no longer served",
+			modify: func(_, embedded *apiextensionsv1.CustomResourceDefinition) {
+				embedded.Spec.Versions[0].Served = false
+			},
+			want: []string{"version v1 is no longer served"},
+		},
+		{
+			name: "stored version removed",
+			modify: func(installed, _ *apiextensionsv1.CustomResourceDefinition) {
+				installed.Status.StoredVersions = []string{"v1beta1", "v1"}
+			},
+			want: []string{"objects are stored in version v1beta1, which is removed"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			installed, embedded := widgetCRD([]string{"v1"}, "size"), widgetCRD([]string{"v1"}, "size")
+			tt.modify(installed, embedded)
+			require.Equal(t, tt.want, IncompatibleChanges(installed, embedded))
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package cron
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNext(t *testing.T) {
+	// a Wednesday
+	from := time.Date(2024, time.May, 15, 10, 17, 30, 0, time.UTC)
+
+	testCases := []struct {
+		name     string
+		expr     string
+		expected time.Time
+	}{
+		{name: "EveryMinute", expr: "* * * * *", expected: time.Date(2024, time.May, 15, 10, 18, 0, 0, time.UTC)},
+		{name: "HourlyOnTheHour", expr: "0 * * * *", expected: time.Date(2024, time.May, 15, 11, 0, 0, 0, time.UTC)},
+		{name: "Hourly", expr: "@hourly", expected: time.Date(2024, time.May, 15, 11, 0, 0, 0, time.UTC)},
+		{name: "DailyAtMidnight", expr: "@daily", expected: time.Date(2024, time.May, 16, 0, 0, 0, 0, time.UTC)},
+		{name: "Step", expr: "*/15 * * * *", expected: time.Date(2024, time.May, 15, 10, 30, 0, 0, time.UTC)},
+		{name: "StepFromOffset", expr: "5/20 * * * *", expected: time.Date(2024, time.May, 15, 10, 25, 0, 0, time.UTC)},
+		{name: "List", expr: "0 9,17 * * *", expected: time.Date(2024, time.May, 15, 17, 0, 0, 0, time.UTC)},
+		{name: "WeekdaysByName", expr: "30 8 * * mon-fri", expected: time.Date(2024, time.May, 16, 8, 30, 0, 0, time.UTC)},
+		{name: "SundayAsSeven", expr: "0 0 * * 7", expected: time.Date(2024, time.May, 19, 0, 0, 0, 0, time.UTC)},
+		{name: "FirstOfMonth", expr: "@monthly", expected: time.Date(2024, time.June, 1, 0, 0, 0, 0, time.UTC)},
+		{name: "MonthByName", expr: "0 0 1 jan *", expected: time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)},
+		{name: "DayOfMonthOrDayOfWeek", expr: "0 0 20 * mon", expected: time.Date(2024, time.May, 20, 0, 0, 0, 0, time.UTC)},
+		{name: "LeapDay", expr: "0 0 29 2 *", expected: time.Date(2028, time.February, 29, 0, 0, 0, 0, time.UTC)},
+		{name: "Never", expr: "0 0 30 2 *", expected: time.Time{}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s, err := Parse(tc.expr)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, s.Next(from))
+		})
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"10-5 * * * *",
+		"* * * foo *",
+		"@every 5m",
+	} {
+		_, err := Parse(expr)
+		require.Error(t, err, expr)
+	}
+}
@@ -0,0 +1,154 @@
+// Package diagnostics serves pprof and a JSON dump of the operator's internal state for debugging.
+package diagnostics
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	"sort"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	"github.com/openmcp-project/metrics-operator/api/v1alpha1"
+	"github.com/openmcp-project/metrics-operator/internal/clientoptl"
+	"github.com/openmcp-project/metrics-operator/internal/metriclist"
+	"github.com/openmcp-project/metrics-operator/internal/orchestrator"
+)
+
+// DiagnosticsPath is the path of the JSON diagnostics endpoint
+const DiagnosticsPath = "/debug/diagnostics"
+
+// Runtime reports the memory and goroutines of the operator process
+type Runtime struct {
+	Goroutines     int    `json:"goroutines"`
+	HeapAllocBytes uint64 `json:"heapAllocBytes"`
+	HeapObjects    uint64 `json:"heapObjects"`
+	NumGC          uint32 `json:"numGC"`
+}
+
+// ManagedCache reports the size of the managed resource cache shared by the ManagedMetrics
+type ManagedCache struct {
+	Entries   int `json:"entries"`
+	Resources int `json:"resources"`
+}
+
+// FailingMetric is a metric whose Ready condition is not true, with the error it last reported
+type FailingMetric struct {
+	Kind      string       `json:"kind"`
+	Namespace string       `json:"namespace"`
+	Name      string       `json:"name"`
+	Reason    string       `json:"reason,omitempty"`
+	Message   string       `json:"message,omitempty"`
+	Since     *metav1.Time `json:"since,omitempty"`
+}
+
+// Snapshot is the state of the operator returned by the diagnostics endpoint
+type Snapshot struct {
+	Time            metav1.Time                              `json:"time"`
+	Runtime         Runtime                                  `json:"runtime"`
+	ManagedCache    ManagedCache                             `json:"managedCache"`
+	CircuitBreakers map[string]v1alpha1.CircuitBreakerStatus `json:"circuitBreakers"`
+	FailingMetrics  []FailingMetric                          `json:"failingMetrics"`
+}
+
+// NewServer returns a server serving net/http/pprof and the diagnostics endpoint on the given address,
+// to be added to the manager
+func NewServer(addr string, cli client.Reader) *manager.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	mux.Handle(DiagnosticsPath, Handler(cli))
+
+	return &manager.Server{
+		Name: "diagnostics",
+		Server: &http.Server{
+			Addr:              addr,
+			Handler:           mux,
+			ReadHeaderTimeout: 10 * time.Second,
+		},
+	}
+}
+
+// Handler serves the Snapshot of the operator as JSON
+func Handler(cli client.Reader) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		snapshot, err := TakeSnapshot(req.Context(), cli)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		_ = encoder.Encode(snapshot)
+	})
+}
+
+// TakeSnapshot collects the state of the operator
+func TakeSnapshot(ctx context.Context, cli client.Reader) (*Snapshot, error) {
+	var memStats runtime.MemStats
+	runtime.ReadMemStats(&memStats)
+	entries, resources := orchestrator.SharedManagedCache.Size()
+
+	failing, err := failingMetrics(ctx, cli)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Snapshot{
+		Time: metav1.Now(),
+		Runtime: Runtime{
+			Goroutines:     runtime.NumGoroutine(),
+			HeapAllocBytes: memStats.HeapAlloc,
+			HeapObjects:    memStats.HeapObjects,
+			NumGC:          memStats.NumGC,
+		},
+		ManagedCache:    ManagedCache{Entries: entries, Resources: resources},
+		CircuitBreakers: clientoptl.SharedCircuitBreakers.Statuses(),
+		FailingMetrics:  failing,
+	}, nil
+}
+
+// failingMetrics lists the metrics of all kinds whose Ready condition is not true
+func failingMetrics(ctx context.Context, cli client.Reader) ([]FailingMetric, error) {
+	listed, err := metriclist.List(ctx, cli)
+	if err != nil {
+		return nil, err
+	}
+
+	failing := []FailingMetric{}
+	for _, m := range listed {
+		condition := m.ReadyCondition()
+		if condition == nil || condition.Status == metav1.ConditionTrue {
+			continue
+		}
+		since := condition.LastTransitionTime
+		failing = append(failing, FailingMetric{
+			Kind:      m.Kind(),
+			Namespace: m.Namespace(),
+			Name:      m.Name(),
+			Reason:    condition.Reason,
+			Message:   condition.Message,
+			Since:     &since,
+		})
+	}
+
+	sort.Slice(failing, func(i, j int) bool {
+		if failing[i].Kind != failing[j].Kind {
+			return failing[i].Kind < failing[j].Kind
+		}
+		if failing[i].Namespace != failing[j].Namespace {
+			return failing[i].Namespace < failing[j].Namespace
+		}
+		return failing[i].Name < failing[j].Name
+	})
+	return failing, nil
+}
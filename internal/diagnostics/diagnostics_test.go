@@ -0,0 +1,49 @@
+package diagnostics
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/openmcp-project/metrics-operator/api/v1alpha1"
+)
+
+func TestHandler(t *testing.T) {
+	ready := &v1alpha1.Metric{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "pods"},
+		Status: v1alpha1.MetricStatus{Conditions: []metav1.Condition{
+			{Type: v1alpha1.TypeReady, Status: metav1.ConditionTrue, Reason: "MetricExported"},
+		}},
+	}
+	failing := &v1alpha1.ManagedMetric{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "buckets"},
+		Status: v1alpha1.ManagedMetricStatus{Conditions: []metav1.Condition{
+			{Type: v1alpha1.TypeReady, Status: metav1.ConditionFalse, Reason: "MonitoringFailed", Message: "no matches for kind Bucket"},
+		}},
+	}
+
+	scheme := runtime.NewScheme()
+	require.NoError(t, v1alpha1.AddToScheme(scheme))
+	cli := fake.NewClientBuilder().WithScheme(scheme).WithObjects(ready, failing).Build()
+
+	rec := httptest.NewRecorder()
+	Handler(cli).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, DiagnosticsPath, nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+
+	snapshot := Snapshot{}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &snapshot))
+	require.Positive(t, snapshot.Runtime.Goroutines)
+	require.NotNil(t, snapshot.CircuitBreakers)
+	require.Len(t, snapshot.FailingMetrics, 1)
+	require.Equal(t, "ManagedMetric", snapshot.FailingMetrics[0].Kind)
+	require.Equal(t, "buckets", snapshot.FailingMetrics[0].Name)
+	require.Equal(t, "MonitoringFailed", snapshot.FailingMetrics[0].Reason)
+	require.Equal(t, "no matches for kind Bucket", snapshot.FailingMetrics[0].Message)
+}
@@ -0,0 +1,59 @@
+package expression
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEval(t *testing.T) {
+	vars := map[string]float64{"ready": 3, "total": 4, "paused": 1}
+
+	tests := []struct {
+		name    string
+		expr    string
+		want    float64
+		wantErr bool
+	}{
+		{name: "literal", expr: "42", want: 42},
+		{name: "variable", expr: "total", want: 4},
+		{name: "difference", expr: "total - paused", want: 3},
+		{name: "precedence", expr: "1 + 2 * 3", want: 7},
+		{name: "parentheses", expr: "(1 + 2) * 3", want: 9},
+		{name: "ratio", expr: "100 * ready / total", want: 75},
+		{name: "unary minus", expr: "-ready + total", want: 1},
+		{name: "fraction literal", expr: "0.5 * total", want: 2},
+		{name: "division by zero", expr: "ready / (total - 4)", wantErr: true},
+		{name: "unknown variable", expr: "ready / missing", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := Parse(tt.expr)
+			require.NoError(t, err)
+
+			got, err := e.Eval(vars)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.InDelta(t, tt.want, got, 1e-9)
+		})
+	}
+}
+
+func TestParse_invalid(t *testing.T) {
+	for _, expr := range []string{"", "1 +", "(1 + 2", "1 + 2)", "a $ b", "1..2", "a b"} {
+		t.Run(expr, func(t *testing.T) {
+			_, err := Parse(expr)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestVariables(t *testing.T) {
+	e, err := Parse("(total - paused) / total + ready")
+	require.NoError(t, err)
+	require.Equal(t, []string{"paused", "ready", "total"}, e.Variables())
+}
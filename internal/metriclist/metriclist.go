@@ -0,0 +1,144 @@
+// Package metriclist lists the metrics of all kinds with the parts of them their health is computed from.
+package metriclist
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/openmcp-project/metrics-operator/api/v1alpha1"
+)
+
+// Metric is a read-only view of a listed metric of any kind
+type Metric struct {
+	kind       string
+	object     metav1.Object
+	conditions []metav1.Condition
+	ready      string
+	// lastObservation is the time of the last observation, or of the last reconcile for the federated kinds
+	lastObservation time.Time
+	interval        metav1.Duration
+	schedule        string
+	// latestValue is the latest observed value of the kinds with a single value, empty for the federated kinds
+	latestValue string
+	thresholds  *v1alpha1.Thresholds
+}
+
+// Kind returns the kind of the metric
+func (m Metric) Kind() string { return m.kind }
+
+// Namespace returns the namespace of the metric
+func (m Metric) Namespace() string { return m.object.GetNamespace() }
+
+// Name returns the name of the metric
+func (m Metric) Name() string { return m.object.GetName() }
+
+// Object returns the metadata of the metric, it must not be modified
+func (m Metric) Object() metav1.Object { return m.object }
+
+// Ready returns the ready field of the status of the metric
+func (m Metric) Ready() string { return m.ready }
+
+// ReadyCondition returns the Ready condition of the metric, or nil if it has none
+func (m Metric) ReadyCondition() *metav1.Condition {
+	return meta.FindStatusCondition(m.conditions, v1alpha1.TypeReady)
+}
+
+// LastObservation returns the time the metric was last observed, zero if it never was
+func (m Metric) LastObservation() time.Time { return m.lastObservation }
+
+// Interval returns the interval of the metric
+func (m Metric) Interval() metav1.Duration { return m.interval }
+
+// Schedule returns the cron schedule of the metric
+func (m Metric) Schedule() string { return m.schedule }
+
+// LatestValue returns the latest observed value of the metric, empty for the federated kinds
+func (m Metric) LatestValue() string { return m.latestValue }
+
+// Thresholds returns the thresholds of the metric, nil for the federated kinds
+func (m Metric) Thresholds() *v1alpha1.Thresholds { return m.thresholds }
+
+// List lists the metrics of all kinds
+//
+//nolint:gocyclo
+func List(ctx context.Context, c client.Reader) ([]Metric, error) {
+	var listed []Metric
+
+	metrics := v1alpha1.MetricList{}
+	if err := c.List(ctx, &metrics); err != nil {
+		return nil, fmt.Errorf("failed to list Metrics: %w", err)
+	}
+	for i := range metrics.Items {
+		m := &metrics.Items[i]
+		listed = append(listed, Metric{
+			kind: "Metric", object: m, conditions: m.Status.Conditions, ready: m.Status.Ready,
+			lastObservation: m.Status.Observation.Timestamp.Time, interval: m.Spec.Interval, schedule: m.Spec.Schedule,
+			latestValue: m.Status.Observation.LatestValue, thresholds: m.Spec.Thresholds,
+		})
+	}
+
+	managed := v1alpha1.ManagedMetricList{}
+	if err := c.List(ctx, &managed); err != nil {
+		return nil, fmt.Errorf("failed to list ManagedMetrics: %w", err)
+	}
+	for i := range managed.Items {
+		m := &managed.Items[i]
+		listed = append(listed, Metric{
+			kind: "ManagedMetric", object: m, conditions: m.Status.Conditions, ready: m.Status.Ready,
+			lastObservation: m.Status.Observation.Timestamp.Time, interval: m.Spec.Interval, schedule: m.Spec.Schedule,
+			latestValue: m.Status.Observation.Resources, thresholds: m.Spec.Thresholds,
+		})
+	}
+
+	federated := v1alpha1.FederatedMetricList{}
+	if err := c.List(ctx, &federated); err != nil {
+		return nil, fmt.Errorf("failed to list FederatedMetrics: %w", err)
+	}
+	for i := range federated.Items {
+		m := &federated.Items[i]
+		listed = append(listed, Metric{
+			kind: "FederatedMetric", object: m, conditions: m.Status.Conditions, ready: m.Status.Ready,
+			lastObservation: timeOf(m.Status.LastReconcileTime), interval: m.Spec.Interval, schedule: m.Spec.Schedule,
+		})
+	}
+
+	federatedManaged := v1alpha1.FederatedManagedMetricList{}
+	if err := c.List(ctx, &federatedManaged); err != nil {
+		return nil, fmt.Errorf("failed to list FederatedManagedMetrics: %w", err)
+	}
+	for i := range federatedManaged.Items {
+		m := &federatedManaged.Items[i]
+		listed = append(listed, Metric{
+			kind: "FederatedManagedMetric", object: m, conditions: m.Status.Conditions, ready: m.Status.Ready,
+			lastObservation: timeOf(m.Status.LastReconcileTime), interval: m.Spec.Interval, schedule: m.Spec.Schedule,
+		})
+	}
+
+	composite := v1alpha1.CompositeMetricList{}
+	if err := c.List(ctx, &composite); err != nil {
+		return nil, fmt.Errorf("failed to list CompositeMetrics: %w", err)
+	}
+	for i := range composite.Items {
+		m := &composite.Items[i]
+		listed = append(listed, Metric{
+			kind: "CompositeMetric", object: m, conditions: m.Status.Conditions, ready: m.Status.Ready,
+			lastObservation: m.Status.Observation.Timestamp.Time, interval: m.Spec.Interval, schedule: m.Spec.Schedule,
+			latestValue: m.Status.Observation.LatestValue, thresholds: m.Spec.Thresholds,
+		})
+	}
+
+	return listed, nil
+}
+
+// timeOf returns the time or the zero time if it is not set
+func timeOf(t *metav1.Time) time.Time {
+	if t == nil {
+		return time.Time{}
+	}
+	return t.Time
+}
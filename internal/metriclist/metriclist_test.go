@@ -0,0 +1,61 @@
+package metriclist
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/openmcp-project/metrics-operator/api/v1alpha1"
+)
+
+func TestList(t *testing.T) {
+	observed := time.Date(2024, time.May, 15, 10, 0, 0, 0, time.UTC)
+	metric := &v1alpha1.Metric{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "pods"},
+		Spec:       v1alpha1.MetricSpec{Interval: metav1.Duration{Duration: time.Minute}, Thresholds: &v1alpha1.Thresholds{}},
+		Status: v1alpha1.MetricStatus{
+			Ready:       v1alpha1.StatusStringTrue,
+			Conditions:  []metav1.Condition{{Type: v1alpha1.TypeReady, Status: metav1.ConditionTrue, Reason: "MetricExported"}},
+			Observation: v1alpha1.MetricObservation{Timestamp: metav1.NewTime(observed), LatestValue: "3"},
+		},
+	}
+	federated := &v1alpha1.FederatedMetric{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "team-b", Name: "nodes"},
+		Spec:       v1alpha1.FederatedMetricSpec{Schedule: "@hourly"},
+		Status:     v1alpha1.FederatedMetricStatus{LastReconcileTime: &metav1.Time{Time: observed}},
+	}
+
+	scheme := runtime.NewScheme()
+	require.NoError(t, v1alpha1.AddToScheme(scheme))
+	cli := fake.NewClientBuilder().WithScheme(scheme).WithObjects(metric, federated).Build()
+
+	listed, err := List(context.Background(), cli)
+	require.NoError(t, err)
+	require.Len(t, listed, 2)
+
+	m := listed[0]
+	require.Equal(t, "Metric", m.Kind())
+	require.Equal(t, "team-a", m.Namespace())
+	require.Equal(t, "pods", m.Name())
+	require.Equal(t, v1alpha1.StatusStringTrue, m.Ready())
+	require.NotNil(t, m.ReadyCondition())
+	require.Equal(t, "MetricExported", m.ReadyCondition().Reason)
+	require.True(t, observed.Equal(m.LastObservation()))
+	require.Equal(t, time.Minute, m.Interval().Duration)
+	require.Equal(t, "3", m.LatestValue())
+	require.NotNil(t, m.Thresholds())
+
+	f := listed[1]
+	require.Equal(t, "FederatedMetric", f.Kind())
+	require.Equal(t, "nodes", f.Name())
+	require.Nil(t, f.ReadyCondition())
+	require.True(t, observed.Equal(f.LastObservation()), "the federated kinds are observed when they are reconciled")
+	require.Equal(t, "@hourly", f.Schedule())
+	require.Empty(t, f.LatestValue())
+	require.Nil(t, f.Thresholds())
+}
@@ -25,8 +25,103 @@ var ResourceCountGauge = prometheus.NewGaugeVec(
 	},
 )
 
+// DataSinkCircuitBreakerState is 1 for the current state of the circuit breaker of a data sink and 0 for the other states
+var DataSinkCircuitBreakerState = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Name: "metrics_operator_datasink_circuit_breaker_state",
+		Help: "State of the circuit breaker of a data sink, 1 for the current state.",
+	},
+	[]string{"datasink", "state"},
+)
+
+// DataSinkSkippedExports counts the exports skipped because the circuit breaker of the data sink was open
+var DataSinkSkippedExports = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "metrics_operator_datasink_skipped_exports_total",
+		Help: "Number of exports skipped because the circuit breaker of the data sink was open.",
+	},
+	[]string{"datasink"},
+)
+
+// DataSinkRejectedExports counts the exports a data sink rejected by the reason parsed from its response
+var DataSinkRejectedExports = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "metrics_operator_datasink_rejected_exports_total",
+		Help: "Number of exports rejected by a data sink, by reason (InvalidDimension, QuotaExceeded, Unauthorized).",
+	},
+	[]string{"datasink", "reason"},
+)
+
+// DimensionPolicyViolations counts the dimensions dropped or redacted by the dimension policy of a data sink
+var DimensionPolicyViolations = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "metrics_operator_dimension_policy_violations_total",
+		Help: "Number of dimensions dropped or redacted by the dimension policy of a data sink.",
+	},
+	[]string{"datasink", "key", "action"},
+)
+
+// ClientRateLimiterWait observes how long requests to the API server of a cluster waited for the client-side rate limiter
+var ClientRateLimiterWait = prometheus.NewHistogramVec(
+	prometheus.HistogramOpts{
+		Name:    "metrics_operator_client_rate_limiter_wait_seconds",
+		Help:    "Time requests to the API server of a cluster waited for the client-side rate limiter.",
+		Buckets: []float64{0.001, 0.01, 0.1, 0.5, 1, 2.5, 5, 10, 30},
+	},
+	[]string{"host"},
+)
+
+// SuppressedEvents counts the Kubernetes events the operator did not emit because they repeated a recent event
+var SuppressedEvents = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "metrics_operator_suppressed_events_total",
+		Help: "Number of Kubernetes events not emitted because an identical event of the object was emitted recently.",
+	},
+	[]string{"reason"},
+)
+
+// CredentialsExpiryDays is the number of days until the credentials stored for a remote cluster expire
+var CredentialsExpiryDays = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Name: "metrics_operator_credentials_expiry_days",
+		Help: "Days until the client certificate or token of a remote cluster expires, negative once it expired.",
+	},
+	[]string{"kind", "namespace", "name", "cluster"},
+)
+
+// circuitBreakerStates are the states reported by DataSinkCircuitBreakerState
+var circuitBreakerStates = []string{"Closed", "Open", "HalfOpen"}
+
 func init() {
-	ctrlmetrics.Registry.MustRegister(ResourceCountGauge)
+	ctrlmetrics.Registry.MustRegister(ResourceCountGauge, DataSinkCircuitBreakerState, DataSinkSkippedExports, DataSinkRejectedExports, DimensionPolicyViolations, ClientRateLimiterWait, SuppressedEvents, CredentialsExpiryDays)
+}
+
+// RecordCircuitBreakerState sets the current state of the circuit breaker of a data sink
+func RecordCircuitBreakerState(dataSink, state string) {
+	for _, s := range circuitBreakerStates {
+		value := 0.0
+		if s == state {
+			value = 1
+		}
+		DataSinkCircuitBreakerState.WithLabelValues(dataSink, s).Set(value)
+	}
+}
+
+// DeleteCircuitBreaker removes the series of the circuit breaker of a deleted data sink
+func DeleteCircuitBreaker(dataSink string) {
+	DataSinkCircuitBreakerState.DeletePartialMatch(map[string]string{"datasink": dataSink})
+	DataSinkSkippedExports.DeleteLabelValues(dataSink)
+	DataSinkRejectedExports.DeletePartialMatch(prometheus.Labels{"datasink": dataSink})
+}
+
+// DeleteCredentialsExpiry removes the series of the clusters of a cluster access
+func DeleteCredentialsExpiry(kind, namespace, name string) {
+	CredentialsExpiryDays.DeletePartialMatch(prometheus.Labels{"kind": kind, "namespace": namespace, "name": name})
+}
+
+// DeleteMetricSeries removes the series of a deleted metric from ResourceCountGauge
+func DeleteMetricSeries(metricName, namespace string) {
+	ResourceCountGauge.DeletePartialMatch(prometheus.Labels{"metric_name": metricName, "namespace": namespace})
 }
 
 // RecordDataPoint records a single data point into ResourceCountGauge.
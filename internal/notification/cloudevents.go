@@ -0,0 +1,125 @@
+// Package notification sends CloudEvents about the health of metrics to an HTTP sink,
+// so downstream automation can react to failing metrics without watching their statuses.
+package notification
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Types of the CloudEvents sent about metrics
+const (
+	// TypeReadyChanged is sent when the Ready condition of a metric changes between true and false
+	TypeReadyChanged = "cloud.openmcp.metrics.ready.changed"
+	// TypeThresholdCrossed is sent when the latest value of a metric crosses one of its thresholds
+	TypeThresholdCrossed = "cloud.openmcp.metrics.threshold.crossed"
+	// TypeStaleChanged is sent when a metric becomes stale or is observed again
+	TypeStaleChanged = "cloud.openmcp.metrics.stale.changed"
+)
+
+// Source is the source of all CloudEvents sent by the operator
+const Source = "metrics.openmcp.cloud/metrics-operator"
+
+// contentType is the content type of CloudEvents in structured mode
+const contentType = "application/cloudevents+json"
+
+// ThresholdState tells on which side of its thresholds the latest value of a metric is
+type ThresholdState string
+
+const (
+	ThresholdAbove  ThresholdState = "Above"
+	ThresholdBelow  ThresholdState = "Below"
+	ThresholdWithin ThresholdState = "Within"
+)
+
+// MetricData is the data of the CloudEvents sent about a metric
+type MetricData struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	// Ready is the status of the Ready condition, True, False or Unknown
+	Ready   string `json:"ready,omitempty"`
+	Reason  string `json:"reason,omitempty"`
+	Message string `json:"message,omitempty"`
+	// Value is the latest value of metrics with a single value
+	Value *int64 `json:"value,omitempty"`
+	// Threshold is the side of the thresholds the value is on, empty for metrics without thresholds
+	Threshold ThresholdState `json:"threshold,omitempty"`
+	// Stale is true if the metric was not observed within twice the time between two of its exports
+	Stale bool `json:"stale"`
+	// LastObservation is the time the metric was last observed
+	LastObservation *time.Time `json:"lastObservation,omitempty"`
+}
+
+// CloudEvent is a CloudEvent 1.0 in the JSON event format
+type CloudEvent struct {
+	SpecVersion     string     `json:"specversion"`
+	ID              string     `json:"id"`
+	Source          string     `json:"source"`
+	Type            string     `json:"type"`
+	Subject         string     `json:"subject,omitempty"`
+	Time            time.Time  `json:"time"`
+	DataContentType string     `json:"datacontenttype"`
+	Data            MetricData `json:"data"`
+}
+
+// NewMetricEvent creates a CloudEvent of the given type about a metric, the subject is kind/namespace/name of the metric
+func NewMetricEvent(eventType string, data MetricData, now time.Time) CloudEvent {
+	return CloudEvent{
+		SpecVersion:     "1.0",
+		ID:              uuid.NewString(),
+		Source:          Source,
+		Type:            eventType,
+		Subject:         data.Kind + "/" + data.Namespace + "/" + data.Name,
+		Time:            now.UTC(),
+		DataContentType: "application/json",
+		Data:            data,
+	}
+}
+
+// Sender sends CloudEvents
+type Sender interface {
+	Send(ctx context.Context, event CloudEvent) error
+}
+
+// HTTPSender posts CloudEvents in structured mode to an HTTP sink, e.g. a Knative broker
+type HTTPSender struct {
+	url    string
+	client *http.Client
+}
+
+// NewHTTPSender creates a sender posting to the given URL
+func NewHTTPSender(url string) *HTTPSender {
+	return &HTTPSender{url: url, client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+// Send posts the event, any response other than 2xx fails
+func (s *HTTPSender) Send(ctx context.Context, event CloudEvent) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal CloudEvent: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 4*1024))
+		return fmt.Errorf("sending CloudEvent %s failed with %s: %s", event.Type, resp.Status, string(bytes.TrimSpace(message)))
+	}
+	return nil
+}
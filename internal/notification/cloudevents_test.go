@@ -0,0 +1,73 @@
+package notification
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTPSender_Send(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	value := int64(42)
+	event := NewMetricEvent(TypeThresholdCrossed, MetricData{
+		Kind: "Metric", Namespace: "team-a", Name: "pods", Ready: "True", Value: &value, Threshold: ThresholdAbove,
+	}, now)
+
+	testCases := []struct {
+		name          string
+		status        int
+		expectedError string
+	}{
+		{
+			name:   "Accepted",
+			status: http.StatusAccepted,
+		},
+		{
+			name:          "Rejected",
+			status:        http.StatusBadRequest,
+			expectedError: "sending CloudEvent cloud.openmcp.metrics.threshold.crossed failed with 400 Bad Request: invalid event",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var received map[string]any
+			var contentType string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				contentType = r.Header.Get("Content-Type")
+				body, _ := io.ReadAll(r.Body)
+				_ = json.Unmarshal(body, &received)
+				w.WriteHeader(tc.status)
+				if tc.status >= 300 {
+					_, _ = w.Write([]byte("invalid event\n"))
+				}
+			}))
+			defer server.Close()
+
+			err := NewHTTPSender(server.URL).Send(context.Background(), event)
+			if tc.expectedError != "" {
+				require.EqualError(t, err, tc.expectedError)
+			} else {
+				require.NoError(t, err)
+			}
+
+			require.Equal(t, "application/cloudevents+json", contentType)
+			require.Equal(t, "1.0", received["specversion"])
+			require.Equal(t, event.ID, received["id"])
+			require.Equal(t, Source, received["source"])
+			require.Equal(t, TypeThresholdCrossed, received["type"])
+			require.Equal(t, "Metric/team-a/pods", received["subject"])
+			require.Equal(t, "2025-01-01T12:00:00Z", received["time"])
+			require.Equal(t, map[string]any{
+				"kind": "Metric", "namespace": "team-a", "name": "pods", "ready": "True",
+				"value": float64(42), "threshold": "Above", "stale": false,
+			}, received["data"])
+		})
+	}
+}
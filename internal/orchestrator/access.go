@@ -0,0 +1,112 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/openmcp-project/metrics-operator/api/v1alpha1"
+)
+
+// ReasonInsufficientPermissions is the reason of metrics that are not allowed to list their target resources
+const ReasonInsufficientPermissions = "InsufficientPermissions"
+
+// accessVerb is the verb the metrics use on their target resources
+const accessVerb = "list"
+
+// namespaceGVK is listed by targets with a namespace selector
+var namespaceGVK = schema.GroupVersionKind{Version: "v1", Kind: "Namespace"}
+
+// AccessDeniedError lists the resources a metric is not allowed to list
+type AccessDeniedError struct {
+	Denied []string
+}
+
+func (e *AccessDeniedError) Error() string {
+	return fmt.Sprintf("not allowed to %s %s", accessVerb, strings.Join(e.Denied, ", "))
+}
+
+// accessCheck is a kind listed in a namespace, or cluster-wide if the namespace is empty
+type accessCheck struct {
+	gvk       schema.GroupVersionKind
+	namespace string
+}
+
+// metricAccessChecks returns the kinds listed by a metric with the spec. Targets are checked in their listed namespaces,
+// the namespaces matched by a namespace selector are only known once the namespaces are listed.
+func metricAccessChecks(spec *v1alpha1.MetricSpec) []accessCheck {
+	var checks []accessCheck
+	addTarget := func(target v1alpha1.MetricTarget) {
+		if target.NamespaceSelector != nil {
+			checks = append(checks, accessCheck{gvk: namespaceGVK})
+		}
+		if !target.IsNamespaceScoped() {
+			checks = append(checks, accessCheck{gvk: target.GVK()})
+		}
+		for _, ns := range target.Namespaces {
+			checks = append(checks, accessCheck{gvk: target.GVK(), namespace: ns})
+		}
+	}
+	addTarget(spec.Target)
+	for _, target := range spec.Targets {
+		addTarget(target.Target)
+	}
+	for _, enrichment := range spec.Enrichments {
+		checks = append(checks, accessCheck{gvk: enrichment.Target.GVK()})
+	}
+	return checks
+}
+
+// CheckMetricAccess reviews with SelfSubjectAccessReviews whether the client of the query config is allowed to list
+// the resources of a metric with the spec, and returns an *AccessDeniedError if it is not.
+// Kinds that are not served by the cluster are skipped, listing them reports the missing kind.
+func CheckMetricAccess(ctx context.Context, qc QueryConfig, spec *v1alpha1.MetricSpec) error {
+	disco, err := qc.discoveryClient()
+	if err != nil {
+		return err
+	}
+
+	var denied []string
+	reviewed := map[accessCheck]bool{}
+	for _, check := range metricAccessChecks(spec) {
+		if reviewed[check] {
+			continue
+		}
+		reviewed[check] = true
+
+		gvr, err := GetGVRfromGVK(check.gvk, disco)
+		if err != nil || gvr.Resource == "" {
+			continue
+		}
+		review := &authorizationv1.SelfSubjectAccessReview{
+			Spec: authorizationv1.SelfSubjectAccessReviewSpec{
+				ResourceAttributes: &authorizationv1.ResourceAttributes{
+					Namespace: check.namespace,
+					Verb:      accessVerb,
+					Group:     gvr.Group,
+					Version:   gvr.Version,
+					Resource:  gvr.Resource,
+				},
+			},
+		}
+		if err := qc.Client.Create(ctx, review); err != nil {
+			return fmt.Errorf("failed to review the access to %s: %w", gvr.GroupResource(), err)
+		}
+		if review.Status.Allowed {
+			continue
+		}
+		if check.namespace != "" {
+			denied = append(denied, fmt.Sprintf("%s in namespace %s", gvr.GroupResource(), check.namespace))
+		} else {
+			denied = append(denied, fmt.Sprintf("%s cluster-wide", gvr.GroupResource()))
+		}
+	}
+
+	if len(denied) > 0 {
+		return &AccessDeniedError{Denied: denied}
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package orchestrator
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	clienttesting "k8s.io/client-go/testing"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	"github.com/openmcp-project/metrics-operator/api/v1alpha1"
+)
+
+func TestCheckMetricAccess(t *testing.T) {
+	disco := &fakediscovery.FakeDiscovery{Fake: &clienttesting.Fake{Resources: []*metav1.APIResourceList{
+		{GroupVersion: "v1", APIResources: []metav1.APIResource{{Name: "pods", Kind: "Pod"}, {Name: "namespaces", Kind: "Namespace"}}},
+		{GroupVersion: "apps/v1", APIResources: []metav1.APIResource{{Name: "deployments", Kind: "Deployment"}}},
+	}}}
+	pods := v1alpha1.GroupVersionKind{Kind: "Pod", Version: "v1"}
+	deployments := v1alpha1.GroupVersionKind{Kind: "Deployment", Group: "apps", Version: "v1"}
+
+	tests := []struct {
+		name string
+		spec v1alpha1.MetricSpec
+		// allowed are the resources that may be listed, by namespace
+		allowed    map[string][]string
+		wantDenied []string
+	}{
+		{
+			name:    "allowed cluster-wide",
+			spec:    v1alpha1.MetricSpec{Target: v1alpha1.MetricTarget{GroupVersionKind: pods}},
+			allowed: map[string][]string{"": {"pods"}},
+		},
+		{
+			name:       "denied cluster-wide",
+			spec:       v1alpha1.MetricSpec{Target: v1alpha1.MetricTarget{GroupVersionKind: pods}},
+			allowed:    map[string][]string{"team-a": {"pods"}},
+			wantDenied: []string{"pods cluster-wide"},
+		},
+		{
+			name: "denied in a namespace",
+			spec: v1alpha1.MetricSpec{
+				Target:  v1alpha1.MetricTarget{GroupVersionKind: pods, Namespaces: []string{"team-a", "team-b"}},
+				Targets: []v1alpha1.NamedTarget{{Name: "deployments", Target: v1alpha1.MetricTarget{GroupVersionKind: deployments}}},
+			},
+			allowed:    map[string][]string{"team-a": {"pods"}},
+			wantDenied: []string{"pods in namespace team-b", "deployments.apps cluster-wide"},
+		},
+		{
+			name:       "namespace selector",
+			spec:       v1alpha1.MetricSpec{Target: v1alpha1.MetricTarget{GroupVersionKind: pods, NamespaceSelector: &metav1.LabelSelector{}}},
+			wantDenied: []string{"namespaces cluster-wide"},
+		},
+		{
+			name: "kind not served",
+			spec: v1alpha1.MetricSpec{Target: v1alpha1.MetricTarget{GroupVersionKind: v1alpha1.GroupVersionKind{Kind: "Bucket", Group: "example.com", Version: "v1"}}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			require.NoError(t, clientgoscheme.AddToScheme(scheme))
+			cli := fake.NewClientBuilder().WithScheme(scheme).WithInterceptorFuncs(interceptor.Funcs{
+				Create: func(_ context.Context, _ client.WithWatch, obj client.Object, _ ...client.CreateOption) error {
+					attributes := obj.(*authorizationv1.SelfSubjectAccessReview).Spec.ResourceAttributes
+					require.Equal(t, "list", attributes.Verb)
+					for _, resource := range tt.allowed[attributes.Namespace] {
+						if resource == attributes.Resource {
+							obj.(*authorizationv1.SelfSubjectAccessReview).Status.Allowed = true
+						}
+					}
+					return nil
+				},
+			}).Build()
+
+			err := CheckMetricAccess(context.Background(), QueryConfig{Client: cli, DiscoveryClient: disco}, &tt.spec)
+			if tt.wantDenied == nil {
+				require.NoError(t, err)
+				return
+			}
+			denied := &AccessDeniedError{}
+			require.ErrorAs(t, err, &denied)
+			require.Equal(t, tt.wantDenied, denied.Denied)
+		})
+	}
+}
@@ -0,0 +1,139 @@
+package orchestrator
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"sync"
+
+	lru "github.com/hashicorp/golang-lru/v2"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/rest"
+	rcli "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// DefaultClientCacheSize is the number of clusters the SharedClientFactory keeps clients for
+const DefaultClientCacheSize = 128
+
+// SharedClientFactory is used by all controllers, so the clients of a cluster are created once
+// and reused across reconciles instead of being set up again for every reconcile
+var SharedClientFactory = NewClientFactory(DefaultClientCacheSize)
+
+// ClusterClients are the clients used to query a cluster
+type ClusterClients struct {
+	Client    rcli.Client
+	Dynamic   dynamic.Interface
+	Discovery discovery.DiscoveryInterface
+}
+
+// ClientFactory creates the clients of a cluster and caches them by the host and a hash of the credentials.
+// Once the credentials change, e.g. because a token was refreshed, new clients are created
+// and the outdated ones are evicted eventually.
+type ClientFactory struct {
+	mu    sync.Mutex
+	cache *lru.Cache[string, *ClusterClients]
+
+	newClients func(restConfig *rest.Config, scheme *runtime.Scheme) (*ClusterClients, error)
+}
+
+// NewClientFactory creates a ClientFactory that keeps the clients of at most size clusters
+func NewClientFactory(size int) *ClientFactory {
+	cache, _ := lru.New[string, *ClusterClients](max(size, 1)) // only fails for sizes below one
+	return &ClientFactory{cache: cache, newClients: newClusterClients}
+}
+
+// ForConfig returns the clients for the rest config.
+// The identity distinguishes credentials that are not part of the rest config, e.g. of a wrapped transport.
+func (f *ClientFactory) ForConfig(restConfig *rest.Config, scheme *runtime.Scheme, identity string) (*ClusterClients, error) {
+	key := clientCacheKey(restConfig, scheme, identity)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if clients, ok := f.cache.Get(key); ok {
+		return clients, nil
+	}
+
+	clients, err := f.newClients(restConfig, scheme)
+	if err != nil {
+		return nil, err
+	}
+	f.cache.Add(key, clients)
+	return clients, nil
+}
+
+// Purge removes all cached clients
+func (f *ClientFactory) Purge() {
+	f.cache.Purge()
+}
+
+func newClusterClients(restConfig *rest.Config, scheme *runtime.Scheme) (*ClusterClients, error) {
+	restConfig = withRateLimiter(restConfig)
+	cli, err := rcli.New(restConfig, rcli.Options{Scheme: scheme})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create client: %w", err)
+	}
+	dynamicClient, err := dynamic.NewForConfig(restConfig)
+	if err != nil {
+		return nil, fmt.Errorf("could not create dynamic client: %w", err)
+	}
+	disco, err := discovery.NewDiscoveryClientForConfig(restConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create discovery client: %w", err)
+	}
+	return &ClusterClients{Client: cli, Dynamic: dynamicClient, Discovery: disco}, nil
+}
+
+// clientCacheKey returns the host of the rest config and a hash of everything that authenticates the clients
+// and of their rate limit
+func clientCacheKey(restConfig *rest.Config, scheme *runtime.Scheme, identity string) string {
+	return credentialsKey(restConfig, identity, fmt.Sprint(restConfig.QPS, restConfig.Burst), fmt.Sprintf("%p", scheme))
+}
+
+// credentialsKey returns the host of the rest config and a hash of everything that authenticates requests to it,
+// the identity distinguishes credentials that are not part of the rest config. The extra values are hashed as well.
+func credentialsKey(restConfig *rest.Config, identity string, extra ...string) string {
+	h := sha256.New()
+	for _, v := range append([]string{
+		identity,
+		restConfig.APIPath,
+		restConfig.BearerToken,
+		restConfig.BearerTokenFile,
+		restConfig.Username,
+		restConfig.Password,
+		restConfig.Impersonate.UserName,
+		restConfig.TLSClientConfig.ServerName,
+		restConfig.TLSClientConfig.CAFile,
+		restConfig.TLSClientConfig.CertFile,
+		restConfig.TLSClientConfig.KeyFile,
+		string(restConfig.TLSClientConfig.CAData),
+		string(restConfig.TLSClientConfig.CertData),
+		string(restConfig.TLSClientConfig.KeyData),
+		fmt.Sprint(restConfig.TLSClientConfig.Insecure),
+		fmt.Sprintf("%v", restConfig.ExecProvider),
+		fmt.Sprintf("%v", restConfig.AuthProvider),
+	}, extra...) {
+		_, _ = io.WriteString(h, v)
+		_, _ = h.Write([]byte{0})
+	}
+	return restConfig.Host + "/" + hex.EncodeToString(h.Sum(nil))
+}
+
+// dynamicClient returns the dynamic client of the query config, or creates one for its rest config
+func (qc *QueryConfig) dynamicClient() (dynamic.Interface, error) {
+	if qc.DynamicClient != nil {
+		return qc.DynamicClient, nil
+	}
+	return dynamic.NewForConfig(&qc.RestConfig)
+}
+
+// discoveryClient returns the discovery client of the query config, or creates one for its rest config
+func (qc *QueryConfig) discoveryClient() (discovery.DiscoveryInterface, error) {
+	if qc.DiscoveryClient != nil {
+		return qc.DiscoveryClient, nil
+	}
+	return discovery.NewDiscoveryClientForConfig(&qc.RestConfig)
+}
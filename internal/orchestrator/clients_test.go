@@ -0,0 +1,123 @@
+package orchestrator
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/rest"
+
+	internalmetrics "github.com/openmcp-project/metrics-operator/internal/metrics"
+)
+
+func TestClientFactory(t *testing.T) {
+	scheme := runtime.NewScheme()
+	factory := NewClientFactory(DefaultClientCacheSize)
+
+	calls := 0
+	factory.newClients = func(*rest.Config, *runtime.Scheme) (*ClusterClients, error) {
+		calls++
+		return &ClusterClients{}, nil
+	}
+	get := func(restConfig *rest.Config, identity string) *ClusterClients {
+		t.Helper()
+		clients, err := factory.ForConfig(restConfig, scheme, identity)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return clients
+	}
+	expectCalls := func(want int) {
+		t.Helper()
+		if calls != want {
+			t.Errorf("unexpected number of created clients: wanted=%v, got=%v", want, calls)
+		}
+	}
+
+	first := get(&rest.Config{Host: "https://a.example.com", BearerToken: "token"}, "")
+	second := get(&rest.Config{Host: "https://a.example.com", BearerToken: "token"}, "")
+	if first != second {
+		t.Error("expected the clients to be reused")
+	}
+	expectCalls(1)
+
+	// other clusters, credentials and rate limits get their own clients
+	get(&rest.Config{Host: "https://b.example.com", BearerToken: "token"}, "")
+	get(&rest.Config{Host: "https://a.example.com", BearerToken: "refreshed"}, "")
+	get(&rest.Config{Host: "https://a.example.com", BearerToken: "token"}, "issuer")
+	get(&rest.Config{Host: "https://a.example.com", BearerToken: "token", QPS: 5, Burst: 10}, "")
+	expectCalls(5)
+
+	factory.Purge()
+	get(&rest.Config{Host: "https://a.example.com", BearerToken: "token"}, "")
+	expectCalls(6)
+}
+
+func TestWithRateLimiter(t *testing.T) {
+	tests := []struct {
+		name        string
+		restConfig  *rest.Config
+		wantLimiter bool
+		wantQPS     float32
+		wantBurst   int
+	}{
+		{
+			name:        "operator default",
+			restConfig:  &rest.Config{Host: "https://default.example.com"},
+			wantLimiter: true,
+			wantQPS:     ClientRateLimit.QPS,
+			wantBurst:   ClientRateLimit.Burst,
+		},
+		{
+			name:        "rate limit of the rest config",
+			restConfig:  &rest.Config{Host: "https://custom.example.com", QPS: 5, Burst: 10},
+			wantLimiter: true,
+			wantQPS:     5,
+			wantBurst:   10,
+		},
+		{
+			name:       "negative QPS disables the rate limiter",
+			restConfig: &rest.Config{Host: "https://unlimited.example.com", QPS: -1},
+			wantQPS:    -1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := withRateLimiter(tt.restConfig)
+			if tt.restConfig.RateLimiter != nil {
+				t.Error("expected the given rest config to be left unchanged")
+			}
+			if got.QPS != tt.wantQPS || got.Burst != tt.wantBurst {
+				t.Errorf("unexpected rate limit: wanted=%v/%v, got=%v/%v", tt.wantQPS, tt.wantBurst, got.QPS, got.Burst)
+			}
+			if (got.RateLimiter != nil) != tt.wantLimiter {
+				t.Fatalf("unexpected rate limiter: wanted=%v, got=%v", tt.wantLimiter, got.RateLimiter)
+			}
+			if !tt.wantLimiter {
+				return
+			}
+			series := countSeries(internalmetrics.ClientRateLimiterWait)
+			if err := got.RateLimiter.Wait(context.Background()); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if observed := countSeries(internalmetrics.ClientRateLimiterWait) - series; observed != 1 {
+				t.Errorf("expected the wait to be observed for the host, got %v new series", observed)
+			}
+		})
+	}
+}
+
+// countSeries returns the number of series of a collector
+func countSeries(collector prometheus.Collector) int {
+	ch := make(chan prometheus.Metric)
+	go func() {
+		collector.Collect(ch)
+		close(ch)
+	}()
+	count := 0
+	for range ch {
+		count++
+	}
+	return count
+}
@@ -0,0 +1,115 @@
+package orchestrator
+
+import (
+	"cmp"
+	"encoding/json"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/openmcp-project/metrics-operator/api/v1alpha1"
+	"github.com/openmcp-project/metrics-operator/internal/clientoptl"
+)
+
+// maxEncodedSeriesSize is the maximum size in bytes of the series dimension of the JSON encoding
+const maxEncodedSeriesSize = 16 * 1024
+
+// seriesEncoder encodes the data points of an observation into the data points that are exported
+type seriesEncoder func(dataPoints []*clientoptl.DataPoint) ([]*clientoptl.DataPoint, error)
+
+// seriesEncoders are the encoders of the encodings of a metric, the Series encoding exports the data points as they are
+var seriesEncoders = map[string]seriesEncoder{
+	v1alpha1.EncodingJSON: encodeJSON,
+}
+
+// encodesDimensions returns true if the encoding encodes the dimensions of the series into a single dimension
+func encodesDimensions(encoding string) bool {
+	_, ok := seriesEncoders[encoding]
+	return ok
+}
+
+// encodeSeries encodes the data points with the encoder of the encoding
+func encodeSeries(encoding string, dataPoints []*clientoptl.DataPoint) ([]*clientoptl.DataPoint, error) {
+	encoder, ok := seriesEncoders[encoding]
+	if !ok || len(dataPoints) == 0 {
+		return dataPoints, nil
+	}
+	return encoder(dataPoints)
+}
+
+// encodedSeries is a series in the JSON encoding
+type encodedSeries struct {
+	Dimensions map[string]string `json:"dimensions,omitempty"`
+	Value      int64             `json:"value"`
+}
+
+// encodeJSON encodes the data points into a single data point with the sum of their values.
+// It keeps the dimensions all data points share, the other dimensions and the values of the data points
+// are encoded as JSON in its series dimension. If they exceed maxEncodedSeriesSize, only the series with
+// the highest values are encoded and the others are counted in the omitted series dimension.
+func encodeJSON(dataPoints []*clientoptl.DataPoint) ([]*clientoptl.DataPoint, error) {
+	shared := maps.Clone(dataPoints[0].Dimensions)
+	for _, dp := range dataPoints[1:] {
+		maps.DeleteFunc(shared, func(name, value string) bool {
+			v, ok := dp.Dimensions[name]
+			return !ok || v != value
+		})
+	}
+
+	var total int64
+	series := make([]encodedSeries, 0, len(dataPoints))
+	for _, dp := range dataPoints {
+		total += dp.Value
+		dimensions := maps.Clone(dp.Dimensions)
+		maps.DeleteFunc(dimensions, func(name, _ string) bool {
+			_, ok := shared[name]
+			return ok
+		})
+		series = append(series, encodedSeries{Dimensions: dimensions, Value: dp.Value})
+	}
+	encoded, omitted, err := marshalSeries(series)
+	if err != nil {
+		return nil, err
+	}
+
+	dataPoint := clientoptl.NewDataPoint().SetValue(total)
+	for name, value := range shared {
+		dataPoint.AddDimension(name, value)
+	}
+	dataPoint.AddDimension(v1alpha1.SeriesDimension, string(encoded))
+	if omitted > 0 {
+		dataPoint.AddDimension(v1alpha1.OmittedSeriesDimension, strconv.Itoa(omitted))
+	}
+	return []*clientoptl.DataPoint{dataPoint}, nil
+}
+
+// marshalSeries encodes the series sorted by their dimensions. Series with the lowest values are left out
+// until the encoding fits into maxEncodedSeriesSize, it returns how many were left out.
+func marshalSeries(series []encodedSeries) ([]byte, int, error) {
+	byKey := func(a, b encodedSeries) int {
+		return strings.Compare(dimensionsKey(a.Dimensions), dimensionsKey(b.Dimensions))
+	}
+	slices.SortFunc(series, byKey)
+	encoded, err := json.Marshal(series)
+	if err != nil || len(encoded) <= maxEncodedSeriesSize {
+		return encoded, 0, err
+	}
+
+	// the series with the highest values are kept, ties are broken by the dimensions to keep the encoding stable
+	ranked := slices.Clone(series)
+	slices.SortStableFunc(ranked, func(a, b encodedSeries) int {
+		return cmp.Compare(b.Value, a.Value)
+	})
+	kept := len(ranked)
+	for kept > 0 && len(encoded) > maxEncodedSeriesSize {
+		// shrink in proportion to the excess, at least by one series
+		kept = min(kept-1, kept*maxEncodedSeriesSize/len(encoded))
+		subset := slices.Clone(ranked[:kept])
+		slices.SortFunc(subset, byKey)
+		if encoded, err = json.Marshal(subset); err != nil {
+			return nil, 0, err
+		}
+	}
+	return encoded, len(series) - kept, nil
+}
@@ -0,0 +1,105 @@
+package orchestrator
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/openmcp-project/metrics-operator/api/v1alpha1"
+	"github.com/openmcp-project/metrics-operator/internal/clientoptl"
+)
+
+func TestEncodeSeries(t *testing.T) {
+	dataPoints := func() []*clientoptl.DataPoint {
+		return []*clientoptl.DataPoint{
+			clientoptl.NewDataPoint().AddDimension("cluster", "prod").AddDimension("phase", "Running").SetValue(3),
+			clientoptl.NewDataPoint().AddDimension("cluster", "prod").AddDimension("phase", "Pending").SetValue(1),
+		}
+	}
+
+	tests := []struct {
+		name       string
+		encoding   string
+		dataPoints []*clientoptl.DataPoint
+		want       map[string]string
+		wantValue  int64
+		wantCount  int
+	}{
+		{
+			name:       "series are exported as they are",
+			encoding:   v1alpha1.EncodingSeries,
+			dataPoints: dataPoints(),
+			wantCount:  2,
+		},
+		{
+			name:       "default encoding",
+			dataPoints: dataPoints(),
+			wantCount:  2,
+		},
+		{
+			name:       "json keeps the shared dimensions",
+			encoding:   v1alpha1.EncodingJSON,
+			dataPoints: dataPoints(),
+			want: map[string]string{
+				"cluster":                "prod",
+				v1alpha1.SeriesDimension: `[{"dimensions":{"phase":"Pending"},"value":1},{"dimensions":{"phase":"Running"},"value":3}]`,
+			},
+			wantValue: 4,
+			wantCount: 1,
+		},
+		{
+			name:       "json of a single series",
+			encoding:   v1alpha1.EncodingJSON,
+			dataPoints: []*clientoptl.DataPoint{clientoptl.NewDataPoint().AddDimension("cluster", "prod").SetValue(7)},
+			want:       map[string]string{"cluster": "prod", v1alpha1.SeriesDimension: `[{"value":7}]`},
+			wantValue:  7,
+			wantCount:  1,
+		},
+		{
+			name:     "json of no series",
+			encoding: v1alpha1.EncodingJSON,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := encodeSeries(tt.encoding, tt.dataPoints)
+			require.NoError(t, err)
+			require.Len(t, got, tt.wantCount)
+			if tt.want != nil {
+				require.Equal(t, tt.want, got[0].Dimensions)
+				require.Equal(t, tt.wantValue, got[0].Value)
+			}
+		})
+	}
+}
+
+func TestEncodeJSON_sizeLimit(t *testing.T) {
+	dataPoints := make([]*clientoptl.DataPoint, 0, 1000)
+	for i := range 1000 {
+		dataPoints = append(dataPoints, clientoptl.NewDataPoint().
+			AddDimension("cluster", "prod").
+			AddDimension("name", fmt.Sprintf("pod-with-a-rather-long-name-%04d", i)).
+			SetValue(int64(i)))
+	}
+
+	got, err := encodeSeries(v1alpha1.EncodingJSON, dataPoints)
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+	require.Equal(t, int64(999*1000/2), got[0].Value, "the total covers all series")
+
+	encoded := got[0].Dimensions[v1alpha1.SeriesDimension]
+	require.LessOrEqual(t, len(encoded), maxEncodedSeriesSize)
+	var series []encodedSeries
+	require.NoError(t, json.Unmarshal([]byte(encoded), &series))
+	omitted, err := strconv.Atoi(got[0].Dimensions[v1alpha1.OmittedSeriesDimension])
+	require.NoError(t, err)
+	require.Equal(t, 1000, len(series)+omitted)
+
+	// the series with the highest values are kept, sorted by their dimensions
+	require.Equal(t, int64(1000-len(series)), series[0].Value)
+	require.Equal(t, int64(999), series[len(series)-1].Value)
+}
@@ -0,0 +1,68 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+
+	"github.com/openmcp-project/metrics-operator/api/v1alpha1"
+)
+
+// relatedResources holds the resources of an enrichment, indexed by namespace/name.
+// They are listed once per collection, so a metric with many matched resources does not get a resource each.
+type relatedResources struct {
+	enrichment v1alpha1.Enrichment
+	resources  map[string]*unstructured.Unstructured
+}
+
+// listRelatedResources lists the resources of each enrichment
+func listRelatedResources(ctx context.Context, dCli dynamic.Interface, disco discovery.DiscoveryInterface, enrichments []v1alpha1.Enrichment) ([]*relatedResources, error) {
+	related := make([]*relatedResources, 0, len(enrichments))
+	for _, enrichment := range enrichments {
+		gvr, err := GetGVRfromGVK(enrichment.Target.GVK(), disco)
+		if err != nil {
+			return nil, err
+		}
+		list, err := dCli.Resource(gvr).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("could not list the related resources '%s' of the enrichment. %w", gvr.String(), err)
+		}
+
+		r := &relatedResources{enrichment: enrichment, resources: make(map[string]*unstructured.Unstructured, len(list.Items))}
+		for i := range list.Items {
+			r.resources[objectName(list.Items[i].GetNamespace(), list.Items[i].GetName())] = &list.Items[i]
+		}
+		related = append(related, r)
+	}
+	return related, nil
+}
+
+// fields projects the resource related to the object.
+// A namespaced related resource is looked up in the namespace of the object, a cluster-scoped one by its name only.
+// If there is no related resource, the projections are evaluated on an empty object, so their defaults apply.
+func (r *relatedResources) fields(obj unstructured.Unstructured) []projectedField {
+	related := &unstructured.Unstructured{Object: map[string]interface{}{}}
+	name, found, err := nestedFieldValue(obj, r.enrichment.NameFrom, v1alpha1.TypePrimitive, nil)
+	if err == nil && found && name != "" {
+		if resource, ok := r.resources[objectName(obj.GetNamespace(), name)]; ok {
+			related = resource
+		} else if resource, ok := r.resources[objectName("", name)]; ok {
+			related = resource
+		}
+	}
+
+	uid := string(obj.GetUID())
+	fields := make([]projectedField, 0, len(r.enrichment.Projections))
+	for _, projection := range r.enrichment.Projections {
+		if projection.Name == "" || (projection.FieldPath == "" && projection.Source == "") {
+			continue
+		}
+		value, found, err := projectionValue(*related, projection)
+		fields = append(fields, projectedField{uid: uid, name: projection.Name, value: value, found: found, error: err})
+	}
+	return fields
+}
@@ -0,0 +1,48 @@
+package orchestrator
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// exclusion matches the listed resources that are excluded from a metric by their labels or fields
+type exclusion struct {
+	labels labels.Selector
+	fields fields.Selector
+}
+
+// newExclusion parses the exclude selectors, it returns nil if neither is set
+func newExclusion(labelSelector, fieldSelector string) (*exclusion, error) {
+	if labelSelector == "" && fieldSelector == "" {
+		return nil, nil
+	}
+
+	e := &exclusion{}
+	if labelSelector != "" {
+		selector, err := labels.Parse(labelSelector)
+		if err != nil {
+			return nil, fmt.Errorf("invalid exclude label selector '%s': %w", labelSelector, err)
+		}
+		e.labels = selector
+	}
+	if fieldSelector != "" {
+		selector, err := fields.ParseSelector(fieldSelector)
+		if err != nil {
+			return nil, fmt.Errorf("invalid exclude field selector '%s': %w", fieldSelector, err)
+		}
+		e.fields = selector
+	}
+	return e, nil
+}
+
+// matches returns true if the labels or the fields of the resource match one of the exclude selectors.
+// Fields are resolved like projections, a missing field has an empty value.
+func (e *exclusion) matches(obj unstructured.Unstructured) bool {
+	if e.labels != nil && e.labels.Matches(labels.Set(obj.GetLabels())) {
+		return true
+	}
+	return e.fields != nil && matchesFields(e.fields, obj)
+}
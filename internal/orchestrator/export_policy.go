@@ -0,0 +1,45 @@
+package orchestrator
+
+import (
+	"maps"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/openmcp-project/metrics-operator/api/v1alpha1"
+	"github.com/openmcp-project/metrics-operator/internal/clientoptl"
+)
+
+// applyExportPolicy decides whether the data points are exported according to the export policy of the metric.
+// It returns the data points to export, none if they are suppressed, and the last export the next observation
+// is compared against, which is nil for the Always policy.
+func applyExportPolicy(spec *v1alpha1.MetricSpec, last *v1alpha1.MetricLastExport, dataPoints []*clientoptl.DataPoint, now time.Time) ([]*clientoptl.DataPoint, *v1alpha1.MetricLastExport, error) {
+	if spec.ExportPolicy != v1alpha1.ExportPolicyOnChange && spec.ExportPolicy != v1alpha1.ExportPolicyOnChangeWithHeartbeat {
+		return dataPoints, nil, nil
+	}
+	// nothing is exported, e.g. for the first observation of the Delta mode
+	if len(dataPoints) == 0 {
+		return nil, last, nil
+	}
+	if len(dataPoints) > v1alpha1.MaxLastExportSeries {
+		return nil, nil, &TooManySeriesError{Field: "status.lastExport", Series: len(dataPoints), Limit: v1alpha1.MaxLastExportSeries}
+	}
+
+	values := make(map[string]int64, len(dataPoints))
+	for _, dp := range dataPoints {
+		values[dimensionsKey(dp.Dimensions)] = dp.Value
+	}
+
+	if last != nil && !last.Timestamp.IsZero() && maps.Equal(last.Values, values) && !heartbeatDue(spec, last, now) {
+		return nil, last, nil
+	}
+	return dataPoints, &v1alpha1.MetricLastExport{Timestamp: metav1.NewTime(now), Values: values}, nil
+}
+
+// heartbeatDue returns true if unchanged values are exported again because the heartbeat interval has passed
+func heartbeatDue(spec *v1alpha1.MetricSpec, last *v1alpha1.MetricLastExport, now time.Time) bool {
+	if spec.ExportPolicy != v1alpha1.ExportPolicyOnChangeWithHeartbeat || spec.HeartbeatInterval == nil {
+		return false
+	}
+	return !now.Before(last.Timestamp.Add(spec.HeartbeatInterval.Duration))
+}
@@ -0,0 +1,112 @@
+package orchestrator
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/openmcp-project/metrics-operator/api/v1alpha1"
+	"github.com/openmcp-project/metrics-operator/internal/clientoptl"
+)
+
+func TestApplyExportPolicy(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	dataPoints := []*clientoptl.DataPoint{
+		clientoptl.NewDataPoint().AddDimension("phase", "Running").SetValue(130),
+		clientoptl.NewDataPoint().AddDimension("phase", "Pending").SetValue(4),
+	}
+	unchanged := &v1alpha1.MetricLastExport{
+		Timestamp: metav1.NewTime(now.Add(-time.Hour)),
+		Values:    map[string]int64{"phase=Running": 130, "phase=Pending": 4},
+	}
+	changed := &v1alpha1.MetricLastExport{
+		Timestamp: metav1.NewTime(now.Add(-time.Hour)),
+		Values:    map[string]int64{"phase=Running": 129, "phase=Pending": 4},
+	}
+	fewer := &v1alpha1.MetricLastExport{
+		Timestamp: metav1.NewTime(now.Add(-time.Hour)),
+		Values:    map[string]int64{"phase=Running": 130},
+	}
+
+	tests := []struct {
+		name         string
+		policy       string
+		heartbeat    time.Duration
+		last         *v1alpha1.MetricLastExport
+		dataPoints   []*clientoptl.DataPoint
+		wantExported bool
+		wantLast     *v1alpha1.MetricLastExport
+	}{
+		{name: "default", last: unchanged, dataPoints: dataPoints, wantExported: true},
+		{name: "always", policy: v1alpha1.ExportPolicyAlways, last: unchanged, dataPoints: dataPoints, wantExported: true},
+		{name: "on change first observation", policy: v1alpha1.ExportPolicyOnChange, dataPoints: dataPoints, wantExported: true},
+		{name: "on change unchanged", policy: v1alpha1.ExportPolicyOnChange, last: unchanged, dataPoints: dataPoints, wantLast: unchanged},
+		{name: "on change changed value", policy: v1alpha1.ExportPolicyOnChange, last: changed, dataPoints: dataPoints, wantExported: true},
+		{name: "on change new dimensions", policy: v1alpha1.ExportPolicyOnChange, last: fewer, dataPoints: dataPoints, wantExported: true},
+		{name: "on change nothing to export", policy: v1alpha1.ExportPolicyOnChange, last: changed, wantLast: changed},
+		{name: "heartbeat not due", policy: v1alpha1.ExportPolicyOnChangeWithHeartbeat, heartbeat: 2 * time.Hour, last: unchanged, dataPoints: dataPoints, wantLast: unchanged},
+		{name: "heartbeat due", policy: v1alpha1.ExportPolicyOnChangeWithHeartbeat, heartbeat: time.Hour, last: unchanged, dataPoints: dataPoints, wantExported: true},
+		{name: "heartbeat changed value", policy: v1alpha1.ExportPolicyOnChangeWithHeartbeat, heartbeat: 2 * time.Hour, last: changed, dataPoints: dataPoints, wantExported: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec := &v1alpha1.MetricSpec{ExportPolicy: tt.policy}
+			if tt.heartbeat > 0 {
+				spec.HeartbeatInterval = &metav1.Duration{Duration: tt.heartbeat}
+			}
+			got, last, err := applyExportPolicy(spec, tt.last, tt.dataPoints, now)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !tt.wantExported {
+				if len(got) != 0 {
+					t.Errorf("unexpected data points: %v", got)
+				}
+				if last != tt.wantLast {
+					t.Errorf("unexpected last export: wanted=%v, got=%v", tt.wantLast, last)
+				}
+				return
+			}
+			if len(got) != len(tt.dataPoints) {
+				t.Fatalf("unexpected number of data points: wanted=%v, got=%v", len(tt.dataPoints), len(got))
+			}
+			if tt.policy != v1alpha1.ExportPolicyOnChange && tt.policy != v1alpha1.ExportPolicyOnChangeWithHeartbeat {
+				if last != nil {
+					t.Errorf("unexpected last export: %v", last)
+				}
+				return
+			}
+			if last == nil {
+				t.Fatal("expected a last export")
+			}
+			if !last.Timestamp.Time.Equal(now) {
+				t.Errorf("unexpected last export timestamp: %v", last.Timestamp)
+			}
+			if len(last.Values) != 2 || last.Values["phase=Running"] != 130 || last.Values["phase=Pending"] != 4 {
+				t.Errorf("unexpected last export values: %v", last.Values)
+			}
+		})
+	}
+}
+
+func TestApplyExportPolicy_tooManySeries(t *testing.T) {
+	dataPoints := make([]*clientoptl.DataPoint, 0, v1alpha1.MaxLastExportSeries+1)
+	for i := range v1alpha1.MaxLastExportSeries + 1 {
+		dataPoints = append(dataPoints, clientoptl.NewDataPoint().AddDimension("name", fmt.Sprintf("pod-%d", i)).SetValue(1))
+	}
+
+	_, last, err := applyExportPolicy(&v1alpha1.MetricSpec{ExportPolicy: v1alpha1.ExportPolicyOnChange}, nil, dataPoints, time.Now())
+	var tooMany *TooManySeriesError
+	if !errors.As(err, &tooMany) || tooMany.Field != "status.lastExport" || last != nil {
+		t.Errorf("unexpected result: %v, %v", last, err)
+	}
+	// the Always policy does not remember the series
+	if _, _, err := applyExportPolicy(&v1alpha1.MetricSpec{}, nil, dataPoints, time.Now()); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
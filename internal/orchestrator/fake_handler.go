@@ -0,0 +1,61 @@
+package orchestrator
+
+import (
+	"context"
+	"sync"
+
+	rcli "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/openmcp-project/metrics-operator/internal/clientoptl"
+)
+
+// FakeHandler is a GenericHandler for tests.
+// It returns Result and Err instead of querying a cluster, so reconcilers can be tested without the target resources.
+type FakeHandler struct {
+	mu       sync.Mutex
+	monitors []string
+	// Result and Err are returned by Monitor
+	Result MonitorResult
+	Err    error
+}
+
+// NewFakeHandler creates a FakeHandler returning the result
+func NewFakeHandler(result MonitorResult) *FakeHandler {
+	return &FakeHandler{Result: result}
+}
+
+// Factory returns a HandlerFactory that returns the FakeHandler for all metrics
+func (f *FakeHandler) Factory() HandlerFactory {
+	return func(metric rcli.Object, _ QueryConfig, _ *clientoptl.Metric) (GenericHandler, error) {
+		return &fakeMonitor{handler: f, name: metric.GetName()}, nil
+	}
+}
+
+// Monitors returns the names of the metrics monitored so far, in the order they were monitored
+func (f *FakeHandler) Monitors() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.monitors...)
+}
+
+// Monitor returns Result and Err
+func (f *FakeHandler) Monitor(context.Context) (MonitorResult, error) {
+	return f.monitor("")
+}
+
+func (f *FakeHandler) monitor(name string) (MonitorResult, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.monitors = append(f.monitors, name)
+	return f.Result, f.Err
+}
+
+// fakeMonitor is the handler of a single metric created by the factory of a FakeHandler
+type fakeMonitor struct {
+	handler *FakeHandler
+	name    string
+}
+
+func (m *fakeMonitor) Monitor(context.Context) (MonitorResult, error) {
+	return m.handler.monitor(m.name)
+}
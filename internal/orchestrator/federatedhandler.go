@@ -21,22 +21,23 @@ import (
 
 // NewFederatedHandler creates a new FederatedHandler
 func NewFederatedHandler(metric v1alpha1.FederatedMetric, qc QueryConfig, gaugeMetric *clientoptl.Metric) (*FederatedHandler, error) {
-	dynamicClient, errCli := dynamic.NewForConfig(&qc.RestConfig)
+	dynamicClient, errCli := qc.dynamicClient()
 	if errCli != nil {
 		return nil, errCli
 	}
 
-	disco, errDisco := discovery.NewDiscoveryClientForConfig(&qc.RestConfig)
+	disco, errDisco := qc.discoveryClient()
 	if errDisco != nil {
 		return nil, errDisco
 	}
 
 	var handler = &FederatedHandler{
-		metric:      metric,
-		dCli:        dynamicClient,
-		discoClient: disco,
-		gauge:       gaugeMetric,
-		clusterName: qc.ClusterName,
+		metric:        metric,
+		dCli:          dynamicClient,
+		discoClient:   disco,
+		gauge:         gaugeMetric,
+		clusterName:   qc.ClusterName,
+		clusterLabels: qc.ClusterLabels,
 	}
 
 	return handler, nil
@@ -49,8 +50,9 @@ type FederatedHandler struct {
 
 	metric v1alpha1.FederatedMetric
 
-	gauge       *clientoptl.Metric
-	clusterName *string
+	gauge         *clientoptl.Metric
+	clusterName   *string
+	clusterLabels map[string]string
 }
 
 // Monitor is used to monitor the metric
@@ -111,6 +113,7 @@ func (h *FederatedHandler) Monitor(ctx context.Context) (MonitorResult, error) {
 			}
 		}
 
+		addClusterLabels(h.clusterLabels, dp)
 		err = h.gauge.RecordMetrics(ctx, dp)
 		if err != nil {
 			return MonitorResult{}, fmt.Errorf("could not record metric: %w", err)
@@ -160,7 +163,7 @@ func (h *FederatedHandler) getResources(ctx context.Context) (*unstructured.Unst
 		return nil, false, fmt.Errorf("failed to get target GVK: %w", err)
 	}
 
-	list, err := h.dCli.Resource(gvr).List(ctx, options)
+	list, err := listSelected(ctx, h.dCli.Resource(gvr), options)
 
 	if err != nil {
 		if isDNSLookupError(err) || apierrors.IsNotFound(err) {
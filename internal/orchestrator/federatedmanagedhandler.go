@@ -20,23 +20,24 @@ import (
 
 // NewFederatedManagedHandler creates a new FederatedManagedHandler
 func NewFederatedManagedHandler(metric v1alpha1.FederatedManagedMetric, qc QueryConfig, gaugeMetric *clientoptl.Metric) (*FederatedManagedHandler, error) {
-	dynamicClient, errCli := dynamic.NewForConfig(&qc.RestConfig)
+	dynamicClient, errCli := qc.dynamicClient()
 	if errCli != nil {
 		return nil, errCli
 	}
 
-	disco, errDisco := discovery.NewDiscoveryClientForConfig(&qc.RestConfig)
+	disco, errDisco := qc.discoveryClient()
 	if errDisco != nil {
 		return nil, errDisco
 	}
 
 	var handler = &FederatedManagedHandler{
-		client:      qc.Client,
-		metric:      metric,
-		dCli:        dynamicClient,
-		discoClient: disco,
-		gauge:       gaugeMetric,
-		clusterName: qc.ClusterName,
+		client:        qc.Client,
+		metric:        metric,
+		dCli:          dynamicClient,
+		discoClient:   disco,
+		gauge:         gaugeMetric,
+		clusterName:   qc.ClusterName,
+		clusterLabels: qc.ClusterLabels,
 	}
 
 	return handler, nil
@@ -50,8 +51,9 @@ type FederatedManagedHandler struct {
 
 	metric v1alpha1.FederatedManagedMetric
 
-	gauge       *clientoptl.Metric
-	clusterName *string
+	gauge         *clientoptl.Metric
+	clusterName   *string
+	clusterLabels map[string]string
 }
 
 // Monitor is used to monitor the metric
@@ -81,19 +83,26 @@ func (h *FederatedManagedHandler) Monitor(ctx context.Context) (MonitorResult, e
 	//
 	// }
 
+	var projectionErrs []error
 	for _, cr := range resources {
-		dp := clientoptl.NewDataPoint().
-			AddDimension(CLUSTER, *h.clusterName).
-			AddDimension(KIND, cr.MangedResource.Kind).
-			AddDimension(APIVERSION, cr.MangedResource.APIVersion).
-			AddDimension("UUID", string(cr.MangedResource.Metadata.UID)). // this has to be unique, otherwise all the tuples are the same and the metric is not recorded properly
-			SetValue(int64(1))
-
-		for fieldName, state := range cr.Status {
-			dp.AddDimension(fieldName, strconv.FormatBool(state))
-			dimensions = append(dimensions, v1alpha1.Dimension{Name: fieldName, Value: strconv.FormatBool(state)})
+		dp := clientoptl.NewDataPoint().SetValue(int64(1))
+
+		// custom dimensions replace the default ones, like those of a ManagedMetric
+		if h.metric.Spec.Dimensions == nil {
+			dp.AddDimension(KIND, cr.MangedResource.Kind).
+				AddDimension(APIVERSION, cr.MangedResource.APIVersion).
+				AddDimension("UUID", string(cr.MangedResource.Metadata.UID)) // this has to be unique, otherwise all the tuples are the same and the metric is not recorded properly
+
+			for fieldName, state := range cr.Status {
+				dp.AddDimension(fieldName, strconv.FormatBool(state))
+				dimensions = append(dimensions, v1alpha1.Dimension{Name: fieldName, Value: strconv.FormatBool(state)})
+			}
+		} else if err := addManagedDimensions(dp, cr.MangedResource, h.metric.Spec.Dimensions); err != nil {
+			projectionErrs = append(projectionErrs, fmt.Errorf("%s %s: %w", cr.MangedResource.Kind, objectName(cr.MangedResource.Metadata.Namespace, cr.MangedResource.Metadata.Name), err))
 		}
+		dp.AddDimension(CLUSTER, *h.clusterName)
 
+		addClusterLabels(h.clusterLabels, dp)
 		err = h.gauge.RecordMetrics(ctx, dp)
 		if err != nil {
 			return MonitorResult{}, fmt.Errorf("could not record metric: %w", err)
@@ -101,9 +110,16 @@ func (h *FederatedManagedHandler) Monitor(ctx context.Context) (MonitorResult, e
 
 	}
 
-	result.Phase = v1alpha1.PhaseActive
-	result.Reason = "MonitoringActive"
-	result.Message = fmt.Sprintf("metric is monitoring federated managed resources '%s'", h.metric.Name)
+	if len(projectionErrs) > 0 {
+		result.Error = fmt.Errorf("failed to extract the dimensions of %d of %d resources, first error: %w", len(projectionErrs), len(resources), projectionErrs[0])
+		result.Phase = v1alpha1.PhaseFailed
+		result.Reason = "RecordMetricFailed"
+		result.Message = fmt.Sprintf("failed to record metric value(s): %s", result.Error.Error())
+	} else {
+		result.Phase = v1alpha1.PhaseActive
+		result.Reason = "MonitoringActive"
+		result.Message = fmt.Sprintf("metric is monitoring federated managed resources '%s'", h.metric.Name)
+	}
 
 	if dimensions != nil {
 		result.Observation = &v1alpha1.MetricObservation{Timestamp: metav1.Now(), Dimensions: []v1alpha1.Dimension{{Name: dimensions[0].Name, Value: strconv.Itoa(len(resources))}}}
@@ -156,7 +172,8 @@ func (h *FederatedManagedHandler) getManagedResources(ctx context.Context) ([]Ma
 
 	var resourceCRDs []apiextensionsv1.CustomResourceDefinition
 	for _, crd := range crds.Items {
-		if h.hasCategory("crossplane", crd) && h.hasCategory("managed", crd) { // filter previously acquired crds
+		// filter previously acquired crds, and drop those that don't match the spec gvk
+		if h.hasCategory("crossplane", crd) && h.hasCategory("managed", crd) && matchesTarget(h.metric.Spec.Target, crd) {
 			resourceCRDs = append(resourceCRDs, crd)
 		}
 	}
@@ -174,6 +191,10 @@ func (h *FederatedManagedHandler) getManagedResources(ctx context.Context) ([]Ma
 			if !crdv.Served || !storedVersions[crdv.Name] {
 				continue
 			}
+			// drop versions that don't match the user provided target
+			if target := h.metric.Spec.Target; target != nil && target.Version != "" && target.Version != crdv.Name {
+				continue
+			}
 
 			gvr := schema.GroupVersionResource{
 				Resource: crd.Spec.Names.Plural,
@@ -0,0 +1,92 @@
+package orchestrator
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/utils/ptr"
+
+	"github.com/openmcp-project/metrics-operator/api/v1alpha1"
+	"github.com/openmcp-project/metrics-operator/internal/clientoptl"
+)
+
+func TestFederatedManagedHandler_Monitor(t *testing.T) {
+	objectGVK := schema.GroupVersionKind{Group: "kubernetes.m.crossplane.io", Version: "v1alpha1", Kind: "Object"}
+	releaseGVK := schema.GroupVersionKind{Group: "helm.m.crossplane.io", Version: "v1beta1", Kind: "Release"}
+	// the federated handler only lists stored versions
+	storedCRD := func(gvk schema.GroupVersionKind) string {
+		return managedAndServedCRD(gvk) + "status:\n  storedVersions:\n  - " + gvk.Version + "\n"
+	}
+	resources := []string{fakeResource(objectGVK), fakeResource(objectGVK), fakeResource(releaseGVK)}
+
+	tests := []struct {
+		name       string
+		spec       v1alpha1.FederatedManagedMetricSpec
+		wantPoints int
+		want       map[string]string
+	}{
+		{
+			name: "default dimensions",
+			// the fake resources have no UIDs
+			wantPoints: 2,
+			want:       map[string]string{CLUSTER: "member", "Ready": "true", "Synced": "true"},
+		},
+		{
+			name:       "target",
+			spec:       v1alpha1.FederatedManagedMetricSpec{Target: &v1alpha1.GroupVersionKind{Group: releaseGVK.Group, Kind: releaseGVK.Kind}},
+			wantPoints: 1,
+			want:       map[string]string{CLUSTER: "member", KIND: "Release", APIVERSION: "helm.m.crossplane.io/v1beta1"},
+		},
+		{
+			name: "custom dimensions",
+			spec: v1alpha1.FederatedManagedMetricSpec{
+				Target:     &v1alpha1.GroupVersionKind{Kind: objectGVK.Kind},
+				Dimensions: []v1alpha1.Projection{{Name: "type", FieldPath: "kind", Type: v1alpha1.TypePrimitive}},
+			},
+			// the data points of both objects have the same dimensions
+			wantPoints: 1,
+			want:       map[string]string{CLUSTER: "member", "type": "Object"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			metricClient, err := clientoptl.NewMetricClient(ctx, nil)
+			require.NoError(t, err)
+			metricClient.SetMeter("managed", nil)
+			gauge, err := metricClient.NewMetric("test", "", "")
+			require.NoError(t, err)
+
+			var recorded []map[string]string
+			gauge.SetPrometheusFunc(func(dims map[string]string, _ int64) {
+				recorded = append(recorded, dims)
+			})
+
+			h := &FederatedManagedHandler{
+				client:      setupFakeClient(t, []string{storedCRD(objectGVK), storedCRD(releaseGVK)}),
+				dCli:        setupFakeDynamicClient(t, resources),
+				metric:      v1alpha1.FederatedManagedMetric{Spec: tt.spec},
+				gauge:       gauge,
+				clusterName: ptr.To("member"),
+			}
+			result, err := h.Monitor(ctx)
+			require.NoError(t, err)
+			require.NoError(t, result.Error)
+			require.Equal(t, v1alpha1.PhaseActive, result.Phase)
+
+			unique := map[string]bool{}
+			for _, dims := range recorded {
+				for name, value := range tt.want {
+					require.Equal(t, value, dims[name], "dimension %s of %v", name, dims)
+				}
+				if tt.spec.Dimensions != nil {
+					require.Len(t, dims, len(tt.want), "unexpected dimensions %v", dims)
+				}
+				unique[dimensionsKey(dims)] = true
+			}
+			require.Len(t, unique, tt.wantPoints)
+		})
+	}
+}
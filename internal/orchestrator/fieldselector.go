@@ -0,0 +1,52 @@
+package orchestrator
+
+import (
+	"context"
+	"slices"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/client-go/dynamic"
+
+	"github.com/openmcp-project/metrics-operator/api/v1alpha1"
+)
+
+// listSelected lists the resources matching the list options.
+// Most kinds, custom resources in particular, only support field selectors on metadata.name and metadata.namespace
+// and reject others as a bad request. The resources are then listed without the field selector
+// and the operator filters them by the fields instead.
+func listSelected(ctx context.Context, ri dynamic.ResourceInterface, options metav1.ListOptions) (*unstructured.UnstructuredList, error) {
+	list, err := ri.List(ctx, options)
+	if err == nil || options.FieldSelector == "" || !apierrors.IsBadRequest(err) {
+		return list, err
+	}
+	selector, errParse := fields.ParseSelector(options.FieldSelector)
+	if errParse != nil {
+		return nil, err
+	}
+
+	options.FieldSelector = ""
+	list, err = ri.List(ctx, options)
+	if err != nil {
+		return nil, err
+	}
+	list.Items = slices.DeleteFunc(list.Items, func(obj unstructured.Unstructured) bool {
+		return !matchesFields(selector, obj)
+	})
+	return list, nil
+}
+
+// matchesFields returns true if the fields of the resource match the selector.
+// Fields are resolved like projections, a missing field has an empty value.
+func matchesFields(selector fields.Selector, obj unstructured.Unstructured) bool {
+	values := fields.Set{}
+	for _, requirement := range selector.Requirements() {
+		value, _, err := nestedFieldValue(obj, requirement.Field, v1alpha1.TypePrimitive, nil)
+		if err == nil {
+			values[requirement.Field] = value
+		}
+	}
+	return selector.Matches(values)
+}
@@ -0,0 +1,173 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// DefaultManagedCacheTTL is the default time a listed set of managed resources is reused
+const DefaultManagedCacheTTL = 30 * time.Second
+
+// SharedManagedCache is the managed resource cache shared by all ManagedHandler instances
+var SharedManagedCache = NewManagedResourceCache(DefaultManagedCacheTTL)
+
+// managedCacheKey identifies a list of managed resources of a single type in a single cluster,
+// listed with the credentials the cluster key stands for
+type managedCacheKey struct {
+	cluster string
+	gvr     schema.GroupVersionResource
+}
+
+type managedCacheEntry struct {
+	resources []Managed
+	expires   time.Time
+}
+
+// ManagedResourceCache caches managed resource lists by cluster and GVR for a limited time,
+// so that ManagedMetrics targeting the same resource types don't list them over and over again
+type ManagedResourceCache struct {
+	mu      sync.RWMutex
+	ttl     time.Duration
+	entries map[managedCacheKey]managedCacheEntry
+	// generations counts the invalidations of each cluster and purges those of all clusters,
+	// resources listed before an invalidation are not cached
+	generations map[string]uint64
+	purges      uint64
+
+	group singleflight.Group
+	now   func() time.Time
+}
+
+// NewManagedResourceCache creates a new ManagedResourceCache, a ttl of zero disables caching
+func NewManagedResourceCache(ttl time.Duration) *ManagedResourceCache {
+	return &ManagedResourceCache{
+		ttl:         ttl,
+		entries:     make(map[managedCacheKey]managedCacheEntry),
+		generations: make(map[string]uint64),
+		now:         time.Now,
+	}
+}
+
+// SetTTL changes the time entries are kept, a ttl of zero disables caching and drops all entries
+func (c *ManagedResourceCache) SetTTL(ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.ttl = ttl
+	if ttl <= 0 {
+		c.entries = make(map[managedCacheKey]managedCacheEntry)
+		c.purges++
+	}
+}
+
+// generation returns the number of invalidations of the cluster, the caller must hold the lock
+func (c *ManagedResourceCache) generation(cluster string) uint64 {
+	return c.generations[cluster] + c.purges
+}
+
+// Get returns the cached resources for the given cluster and GVR, or calls list to retrieve them.
+// The cluster identifies the cluster and the credentials the resources are listed with.
+// Concurrent calls for the same key share a single list call, it is not canceled with the context of the caller
+// that started it, so the other callers still get its result. Each caller stops waiting once its context is done.
+// Calls made after the cluster was invalidated don't share a list call started before, whose result is not cached.
+// The returned slice is shared between callers and must not be modified.
+func (c *ManagedResourceCache) Get(ctx context.Context, cluster string, gvr schema.GroupVersionResource, list func(context.Context) ([]Managed, error)) ([]Managed, error) {
+	if c == nil {
+		return list(ctx)
+	}
+
+	key := managedCacheKey{cluster: cluster, gvr: gvr}
+
+	c.mu.RLock()
+	ttl := c.ttl
+	entry, ok := c.entries[key]
+	generation := c.generation(cluster)
+	c.mu.RUnlock()
+
+	if ttl <= 0 {
+		return list(ctx)
+	}
+	if ok && c.now().Before(entry.expires) {
+		return entry.resources, nil
+	}
+
+	results := c.group.DoChan(fmt.Sprintf("%s/%s/%d", cluster, gvr, generation), func() (any, error) {
+		// the list is bound by the default phase timeout instead of the context of the first caller
+		listCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), PhaseTimeout(nil))
+		defer cancel()
+		resources, err := list(listCtx)
+		if err != nil {
+			return nil, err
+		}
+		c.mu.Lock()
+		// resources listed before an invalidation may be outdated, they are returned to the waiting callers only
+		if c.generation(cluster) == generation {
+			c.entries[key] = managedCacheEntry{resources: resources, expires: c.now().Add(ttl)}
+		}
+		c.mu.Unlock()
+		return resources, nil
+	})
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case result := <-results:
+		if result.Err != nil {
+			return nil, result.Err
+		}
+		return result.Val.([]Managed), nil
+	}
+}
+
+// Invalidate drops the cached resources for the given cluster and GVR
+func (c *ManagedResourceCache) Invalidate(cluster string, gvr schema.GroupVersionResource) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, managedCacheKey{cluster: cluster, gvr: gvr})
+	c.generations[cluster]++
+}
+
+// InvalidateCluster drops all cached resources of the given cluster
+func (c *ManagedResourceCache) InvalidateCluster(cluster string) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key := range c.entries {
+		if key.cluster == cluster {
+			delete(c.entries, key)
+		}
+	}
+	c.generations[cluster]++
+}
+
+// Size returns the number of cached lists and the number of resources in them, expired lists included
+func (c *ManagedResourceCache) Size() (entries, resources int) {
+	if c == nil {
+		return 0, 0
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for _, entry := range c.entries {
+		resources += len(entry.resources)
+	}
+	return len(c.entries), resources
+}
+
+// Purge drops all cached resources
+func (c *ManagedResourceCache) Purge() {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = make(map[managedCacheKey]managedCacheEntry)
+	c.purges++
+}
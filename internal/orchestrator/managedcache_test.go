@@ -0,0 +1,206 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/rest"
+)
+
+func TestManagedResourceCache(t *testing.T) {
+	gvr := schema.GroupVersionResource{Group: "nop.crossplane.io", Version: "v1alpha1", Resource: "nopresources"}
+	otherGVR := schema.GroupVersionResource{Group: "helm.m.crossplane.io", Version: "v1beta1", Resource: "releases"}
+
+	now := time.Now()
+	cache := NewManagedResourceCache(time.Minute)
+	cache.now = func() time.Time { return now }
+
+	calls := 0
+	list := func(context.Context) ([]Managed, error) {
+		calls++
+		return []Managed{{Kind: "NopResource"}}, nil
+	}
+	get := func(cluster string, gvr schema.GroupVersionResource) {
+		t.Helper()
+		resources, err := cache.Get(context.Background(), cluster, gvr, list)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(resources) != 1 {
+			t.Errorf("unexpected result length: wanted=1, got=%v", len(resources))
+		}
+	}
+	expectCalls := func(want int) {
+		t.Helper()
+		if calls != want {
+			t.Errorf("unexpected number of list calls: wanted=%v, got=%v", want, calls)
+		}
+	}
+
+	get("a", gvr)
+	get("a", gvr)
+	expectCalls(1)
+
+	// other clusters and resource types are cached separately
+	get("b", gvr)
+	get("a", otherGVR)
+	expectCalls(3)
+
+	cache.Invalidate("a", gvr)
+	get("a", gvr)
+	get("a", otherGVR)
+	expectCalls(4)
+
+	cache.InvalidateCluster("a")
+	get("a", gvr)
+	get("a", otherGVR)
+	get("b", gvr)
+	expectCalls(6)
+
+	if entries, resources := cache.Size(); entries != 3 || resources != 3 {
+		t.Errorf("unexpected cache size: wanted=3/3, got=%v/%v", entries, resources)
+	}
+
+	now = now.Add(2 * time.Minute)
+	get("b", gvr)
+	expectCalls(7)
+
+	cache.Purge()
+	get("b", gvr)
+	expectCalls(8)
+
+	cache.SetTTL(0)
+	get("b", gvr)
+	get("b", gvr)
+	expectCalls(10)
+}
+
+func TestManagedResourceCacheError(t *testing.T) {
+	gvr := schema.GroupVersionResource{Group: "nop.crossplane.io", Version: "v1alpha1", Resource: "nopresources"}
+	cache := NewManagedResourceCache(time.Minute)
+
+	_, err := cache.Get(context.Background(), "a", gvr, func(context.Context) ([]Managed, error) {
+		return nil, errors.New("list failed")
+	})
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+
+	// failed lists are not cached
+	resources, err := cache.Get(context.Background(), "a", gvr, func(context.Context) ([]Managed, error) {
+		return []Managed{{}}, nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(resources) != 1 {
+		t.Errorf("unexpected result length: wanted=1, got=%v", len(resources))
+	}
+}
+
+func TestManagedResourceCacheCanceledCaller(t *testing.T) {
+	gvr := schema.GroupVersionResource{Group: "nop.crossplane.io", Version: "v1alpha1", Resource: "nopresources"}
+	cache := NewManagedResourceCache(time.Minute)
+
+	started, release := make(chan struct{}), make(chan struct{})
+	list := func(ctx context.Context) ([]Managed, error) {
+		close(started)
+		<-release
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return []Managed{{Kind: "NopResource"}}, nil
+	}
+
+	// the caller that started the list gives up, the list goes on for the callers waiting for it
+	first, cancel := context.WithCancel(context.Background())
+	firstErr := make(chan error)
+	go func() {
+		_, err := cache.Get(first, "a", gvr, list)
+		firstErr <- err
+	}()
+	<-started
+	waiterResult := make(chan []Managed)
+	go func() {
+		resources, _ := cache.Get(context.Background(), "a", gvr, list)
+		waiterResult <- resources
+	}()
+	cancel()
+	if err := <-firstErr; !errors.Is(err, context.Canceled) {
+		t.Errorf("expected the canceled caller to stop waiting, got: %v", err)
+	}
+	close(release)
+	if resources := <-waiterResult; len(resources) != 1 {
+		t.Errorf("unexpected result length of the waiting caller: wanted=1, got=%v", len(resources))
+	}
+}
+
+func TestManagedResourceCacheInvalidatedDuringList(t *testing.T) {
+	gvr := schema.GroupVersionResource{Group: "nop.crossplane.io", Version: "v1alpha1", Resource: "nopresources"}
+	cache := NewManagedResourceCache(time.Minute)
+
+	started, release := make(chan struct{}), make(chan struct{})
+	calls := 0
+	stale := func(ctx context.Context) ([]Managed, error) {
+		calls++
+		close(started)
+		<-release
+		return []Managed{{Kind: "NopResource", Metadata: metav1.ObjectMeta{Name: "stale"}}}, nil
+	}
+	fresh := func(ctx context.Context) ([]Managed, error) {
+		calls++
+		return []Managed{{Kind: "NopResource", Metadata: metav1.ObjectMeta{Name: "fresh"}}}, nil
+	}
+
+	staleResult := make(chan []Managed)
+	go func() {
+		resources, _ := cache.Get(context.Background(), "a", gvr, stale)
+		staleResult <- resources
+	}()
+	<-started
+	cache.InvalidateCluster("a")
+
+	// callers after the invalidation don't wait for the list started before it
+	resources, err := cache.Get(context.Background(), "a", gvr, fresh)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(resources) != 1 || resources[0].Metadata.Name != "fresh" {
+		t.Errorf("expected the resources listed after the invalidation, got: %v", resources)
+	}
+
+	close(release)
+	if resources := <-staleResult; len(resources) != 1 || resources[0].Metadata.Name != "stale" {
+		t.Errorf("expected the waiting caller to get the resources of its list, got: %v", resources)
+	}
+	resources, _ = cache.Get(context.Background(), "a", gvr, fresh)
+	if len(resources) != 1 || resources[0].Metadata.Name != "fresh" {
+		t.Errorf("expected the resources listed before the invalidation not to be cached, got: %v", resources)
+	}
+	if calls != 2 {
+		t.Errorf("unexpected number of list calls: wanted=2, got=%v", calls)
+	}
+}
+
+func TestQueryConfigCredentialsKey(t *testing.T) {
+	key := func(qc QueryConfig) string { return qc.credentialsKey() }
+	base := QueryConfig{RestConfig: rest.Config{Host: "https://a.example.com", BearerToken: "token"}}
+
+	if key(base) != key(QueryConfig{RestConfig: rest.Config{Host: "https://a.example.com", BearerToken: "token", QPS: 5}}) {
+		t.Error("expected the rate limit not to change the key")
+	}
+	// other credentials, also those not part of the rest config, get their own key
+	for _, other := range []QueryConfig{
+		{RestConfig: rest.Config{Host: "https://a.example.com", BearerToken: "other"}},
+		{RestConfig: rest.Config{Host: "https://a.example.com", BearerToken: "token"}, Identity: "issuer"},
+		{RestConfig: rest.Config{Host: "https://b.example.com", BearerToken: "token"}},
+	} {
+		if key(base) == key(other) {
+			t.Errorf("expected a different key for %+v", other)
+		}
+	}
+}
@@ -2,11 +2,14 @@ package orchestrator
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"slices"
 	"strconv"
 	"strings"
+	"time"
 
+	"golang.org/x/sync/errgroup"
 	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
@@ -14,12 +17,18 @@ import (
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/dynamic"
 	rcli "sigs.k8s.io/controller-runtime/pkg/client"
-	"sigs.k8s.io/controller-runtime/pkg/log"
 
 	"github.com/openmcp-project/metrics-operator/api/v1alpha1"
 	"github.com/openmcp-project/metrics-operator/internal/clientoptl"
 )
 
+const (
+	// managedListPageSize is the maximum number of resources retrieved per list request
+	managedListPageSize = 500
+	// managedListConcurrency is the maximum number of resource types listed in parallel
+	managedListConcurrency = 10
+)
+
 // ManagedHandler is used to monitor the metric
 type ManagedHandler struct {
 	client rcli.Client
@@ -28,35 +37,57 @@ type ManagedHandler struct {
 	metric      v1alpha1.ManagedMetric
 	gaugeMetric *clientoptl.Metric
 
-	clusterName *string
+	clusterName   *string
+	clusterLabels map[string]string
+
+	// samples are names of the matched resources for debugging
+	samples []string
+
+	// cache is shared between handlers, resource lists are keyed by the queried cluster and the credentials,
+	// so accesses with different permissions don't see each other's resources
+	cache    *ManagedResourceCache
+	cacheKey string
 }
 
 // NewManagedHandler creates a new ManagedHandler
 func NewManagedHandler(metric v1alpha1.ManagedMetric, qc QueryConfig, gaugeMetric *clientoptl.Metric) (*ManagedHandler, error) {
-	dynamicClient, errCli := dynamic.NewForConfig(&qc.RestConfig)
+	dynamicClient, errCli := qc.dynamicClient()
 	if errCli != nil {
 		return nil, fmt.Errorf("could not create dynamic client: %w", errCli)
 	}
 
 	var handler = &ManagedHandler{
-		client:      qc.Client,
-		dCli:        dynamicClient,
-		metric:      metric,
-		gaugeMetric: gaugeMetric,
-		clusterName: qc.ClusterName,
+		client:        qc.Client,
+		dCli:          dynamicClient,
+		metric:        metric,
+		gaugeMetric:   gaugeMetric,
+		clusterName:   qc.ClusterName,
+		clusterLabels: qc.ClusterLabels,
+		cache:         SharedManagedCache,
+		cacheKey:      qc.credentialsKey(),
 	}
 
 	return handler, nil
 }
 
 func (h *ManagedHandler) sendStatusBasedMetricValue(ctx context.Context) (string, error) {
-	l := log.FromContext(ctx)
 	resources, err := h.getResourcesStatus(ctx)
 	if err != nil {
 		return "", err
 	}
+	h.samples = samples(resources, h.metric.Spec.Debug, func(cr ClusterResourceStatus) string {
+		return cr.MangedResource.Kind + "/" + objectName(cr.MangedResource.Metadata.Namespace, cr.MangedResource.Metadata.Name)
+	})
+
+	if h.metric.Spec.Age != nil {
+		if err := h.sendAgeMetricValues(ctx, resources, time.Now()); err != nil {
+			return "", err
+		}
+		return strconv.Itoa(len(resources)), nil
+	}
 
 	// data point split by dimensions
+	var projectionErrs []error
 	for _, cr := range resources {
 		// Create a new data point for each resource
 		dataPoint := clientoptl.NewDataPoint()
@@ -73,36 +104,21 @@ func (h *ManagedHandler) sendStatusBasedMetricValue(ctx context.Context) (string
 			dataPoint.AddDimension(GROUP, gv.Group)
 			dataPoint.AddDimension(VERSION, gv.Version)
 
+			conditionTypes := h.metric.Spec.GetConditionTypes()
 			for typ, state := range cr.Status {
-				t := strings.ToLower(typ)
-				if t == "ready" || t == "synced" {
-					dataPoint.AddDimension(t, strconv.FormatBool(state))
-				}
-			}
-		} else {
-			objMap, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&cr.MangedResource)
-			if err != nil {
-				return "", err
-			}
-
-			u := &unstructured.Unstructured{Object: objMap}
-
-			for _, dimension := range h.metric.Spec.Dimensions {
-				if dimension.Name != "" && dimension.FieldPath != "" {
-					value, _, err := nestedFieldValue(*u, dimension.FieldPath, dimension.Type, dimension.Default)
-					if err != nil {
-						l.Error(err, fmt.Sprintf("WARN: Could not parse expression '%s' for dimension field '%s'. Error: %v\n", dimension.Name, dimension.FieldPath, err))
-						continue
-					}
-					dataPoint.AddDimension(dimension.Name, value)
+				if slices.ContainsFunc(conditionTypes, func(ct string) bool { return strings.EqualFold(ct, typ) }) {
+					dataPoint.AddDimension(strings.ToLower(typ), strconv.FormatBool(state))
 				}
 			}
+		} else if err := addManagedDimensions(dataPoint, cr.MangedResource, h.metric.Spec.Dimensions); err != nil {
+			projectionErrs = append(projectionErrs, fmt.Errorf("%s %s: %w", cr.MangedResource.Kind, objectName(cr.MangedResource.Metadata.Namespace, cr.MangedResource.Metadata.Name), err))
 		}
 
 		// Add cluster dimension if available
 		if h.clusterName != nil {
 			dataPoint.AddDimension(CLUSTER, *h.clusterName)
 		}
+		addClusterLabels(h.clusterLabels, dataPoint)
 
 		// Set the value to 1 for each resource
 		dataPoint.SetValue(1)
@@ -115,9 +131,122 @@ func (h *ManagedHandler) sendStatusBasedMetricValue(ctx context.Context) (string
 	}
 
 	resourcesCount := len(resources)
+	if len(projectionErrs) > 0 {
+		// the data points are recorded without the dimensions that failed, the first error tells which field to fix
+		return strconv.Itoa(resourcesCount), &projectionError{err: fmt.Errorf("failed to extract the dimensions of %d of %d resources, first error: %w", len(projectionErrs), resourcesCount, projectionErrs[0])}
+	}
+
+	return strconv.Itoa(resourcesCount), nil
+}
+
+// projectionError is returned if the dimensions of some resources could not be extracted, their data points are recorded nonetheless
+type projectionError struct {
+	err error
+}
+
+func (e *projectionError) Error() string {
+	return e.err.Error()
+}
 
-	// if no err, returns nil...duh!
-	return strconv.Itoa(resourcesCount), err
+func (e *projectionError) Unwrap() error {
+	return e.err
+}
+
+// addManagedDimensions adds the dimensions projected from the managed resource to the data point.
+// Dimensions whose value cannot be extracted are left out and returned as error.
+func addManagedDimensions(dataPoint *clientoptl.DataPoint, managed Managed, dimensions []v1alpha1.Projection) error {
+	objMap, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&managed)
+	if err != nil {
+		return err
+	}
+
+	u := &unstructured.Unstructured{Object: objMap}
+
+	var errs []error
+	for _, dimension := range dimensions {
+		if dimension.Name != "" && (dimension.FieldPath != "" || dimension.Source != "") {
+			value, _, err := projectionValue(*u, dimension)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("dimension %s: %w", dimension.Name, err))
+				continue
+			}
+			dataPoint.AddDimension(dimension.Name, value)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// sendAgeMetricValues records the oldest, newest and average age of the resources in seconds per resource type
+func (h *ManagedHandler) sendAgeMetricValues(ctx context.Context, resources []ClusterResourceStatus, now time.Time) error {
+	ages := make(map[schema.GroupVersionKind][]time.Duration)
+	var gvks []schema.GroupVersionKind
+	for _, cr := range resources {
+		since, ok := resourceAgeReference(cr.MangedResource, h.metric.Spec.Age.ConditionType)
+		if !ok {
+			continue
+		}
+		gvk := schema.FromAPIVersionAndKind(cr.MangedResource.APIVersion, cr.MangedResource.Kind)
+		if _, exists := ages[gvk]; !exists {
+			gvks = append(gvks, gvk)
+		}
+		ages[gvk] = append(ages[gvk], now.Sub(since))
+	}
+
+	for _, gvk := range gvks {
+		for statistic, age := range ageStatistics(ages[gvk]) {
+			dataPoint := clientoptl.NewDataPoint()
+			dataPoint.AddDimension(KIND, gvk.Kind)
+			dataPoint.AddDimension(GROUP, gvk.Group)
+			dataPoint.AddDimension(VERSION, gvk.Version)
+			dataPoint.AddDimension(STATISTIC, statistic)
+			if h.clusterName != nil {
+				dataPoint.AddDimension(CLUSTER, *h.clusterName)
+			}
+			addClusterLabels(h.clusterLabels, dataPoint)
+			dataPoint.SetValue(int64(age.Seconds()))
+
+			if err := h.gaugeMetric.RecordMetrics(ctx, dataPoint); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// resourceAgeReference returns the point in time the age of a resource is measured from,
+// which is the last transition of the given condition type or the creation of the resource if no type is given
+func resourceAgeReference(managed Managed, conditionType string) (time.Time, bool) {
+	if conditionType == "" {
+		return managed.Metadata.CreationTimestamp.Time, !managed.Metadata.CreationTimestamp.IsZero()
+	}
+	for _, condition := range managed.Status.Conditions {
+		if !strings.EqualFold(condition.Type, conditionType) {
+			continue
+		}
+		transition, err := time.Parse(time.RFC3339, condition.LastTransitionTime)
+		if err != nil {
+			return time.Time{}, false
+		}
+		return transition, true
+	}
+	return time.Time{}, false
+}
+
+// ageStatistics returns the oldest, newest and average of the given ages
+func ageStatistics(ages []time.Duration) map[string]time.Duration {
+	if len(ages) == 0 {
+		return nil
+	}
+	var total time.Duration
+	for _, age := range ages {
+		total += age
+	}
+	return map[string]time.Duration{
+		v1alpha1.AgeStatisticOldest:  slices.Max(ages),
+		v1alpha1.AgeStatisticNewest:  slices.Min(ages),
+		v1alpha1.AgeStatisticAverage: total / time.Duration(len(ages)),
+	}
 }
 
 // Monitor executes the monitoring of the metric
@@ -125,16 +254,27 @@ func (h *ManagedHandler) Monitor(ctx context.Context) (MonitorResult, error) {
 	result := MonitorResult{}
 	resources, err := h.sendStatusBasedMetricValue(ctx)
 
-	if err != nil {
+	var projectionErr *projectionError
+	if errors.As(err, &projectionErr) {
+		result.Error = err
+		result.Phase = v1alpha1.PhaseFailed
+		result.Reason = "RecordMetricFailed"
+		result.Message = fmt.Sprintf("failed to record metric value(s): %s", err.Error())
+		result.Observation = &v1alpha1.ManagedObservation{Timestamp: metav1.Now(), Resources: resources}
+		result.Samples = h.samples
+	} else if err != nil {
 		result.Error = err
 		result.Phase = v1alpha1.PhaseFailed
 		result.Reason = "SendMetricFailed"
 		result.Message = fmt.Sprintf("failed to send metric value to data sink. %s", err.Error())
+		// drop the cached resource lists of the cluster, so the next attempt starts from a fresh state
+		h.cache.InvalidateCluster(h.cacheKey)
 	} else {
 		result.Phase = v1alpha1.PhaseActive
 		result.Observation = &v1alpha1.ManagedObservation{Timestamp: metav1.Now(), Resources: resources}
 		result.Reason = "MonitoringActive"
 		result.Message = fmt.Sprintf("metric is monitoring resource '%s'", h.metric.GvkToString())
+		result.Samples = h.samples
 	}
 
 	return result, nil
@@ -151,6 +291,17 @@ func (h *ManagedHandler) hasCategory(category string, crd apiextensionsv1.Custom
 	return false
 }
 
+// is used to check if a resource from the cluster has all the given categories
+func (h *ManagedHandler) hasCategories(categories []string, crd apiextensionsv1.CustomResourceDefinition) bool {
+	for _, category := range categories {
+		if !h.hasCategory(category, crd) {
+			return false
+		}
+	}
+
+	return true
+}
+
 func (h *ManagedHandler) getResourcesStatus(ctx context.Context) ([]ClusterResourceStatus, error) {
 	managedResources, err := h.getManagedResources(ctx)
 	if err != nil {
@@ -160,6 +311,9 @@ func (h *ManagedHandler) getResourcesStatus(ctx context.Context) ([]ClusterResou
 	crStatuses := make([]ClusterResourceStatus, 0)
 
 	for _, item := range managedResources {
+		if !matchesConditionFilter(item, h.metric.Spec.ConditionFilter) {
+			continue
+		}
 		rsStatus := ClusterResourceStatus{MangedResource: item, Status: make(map[string]bool)}
 		for _, condition := range item.Status.Conditions {
 			status, _ := strconv.ParseBool(condition.Status)
@@ -181,8 +335,8 @@ func (h *ManagedHandler) getManagedResources(ctx context.Context) ([]Managed, er
 
 	resourceCRDs := make([]apiextensionsv1.CustomResourceDefinition, 0, len(crds.Items))
 	for _, crd := range crds.Items {
-		// drop non-crossplane crds
-		if !h.hasCategory("crossplane", crd) || !h.hasCategory("managed", crd) {
+		// drop crds without the configured categories (crossplane managed resources by default)
+		if !h.hasCategories(h.metric.Spec.GetCRDCategories(), crd) {
 			continue
 		}
 		// drop crds that don't match the spec gvk
@@ -192,9 +346,8 @@ func (h *ManagedHandler) getManagedResources(ctx context.Context) ([]Managed, er
 		resourceCRDs = append(resourceCRDs, crd)
 	}
 
-	var resources []unstructured.Unstructured
+	var gvrs []schema.GroupVersionResource
 	for _, crd := range resourceCRDs {
-		versionsToRetrieve := make([]string, 0, len(crd.Spec.Versions))
 		for _, crdv := range crd.Spec.Versions {
 			// only use served versions for retrieval
 			if !crdv.Served {
@@ -205,39 +358,62 @@ func (h *ManagedHandler) getManagedResources(ctx context.Context) ([]Managed, er
 			if target != nil && target.Version != "" && target.Version != crdv.Name {
 				continue
 			}
-			versionsToRetrieve = append(versionsToRetrieve, crdv.Name)
-		}
-		// finally retrieve all matching resources
-		for _, version := range versionsToRetrieve {
-			gvr := schema.GroupVersionResource{
+			gvrs = append(gvrs, schema.GroupVersionResource{
 				Resource: crd.Spec.Names.Plural,
 				Group:    crd.Spec.Group,
-				Version:  version,
-			}
+				Version:  crdv.Name,
+			})
+		}
+	}
 
-			list, err := h.dCli.Resource(gvr).List(ctx, metav1.ListOptions{}) // gets resources from all the available crds
+	// finally retrieve all matching resources, listing multiple resource types concurrently
+	// and reusing lists recently retrieved by other handlers
+	results := make([][]Managed, len(gvrs))
+	g, gCtx := errgroup.WithContext(ctx)
+	g.SetLimit(managedListConcurrency)
+	for i, gvr := range gvrs {
+		g.Go(func() error {
+			managed, err := h.cache.Get(gCtx, h.cacheKey, gvr, func(ctx context.Context) ([]Managed, error) {
+				return h.listManaged(ctx, gvr)
+			})
 			if err != nil {
-				return nil, fmt.Errorf("could not find any matching resources for metric with filter '%s'. %w", h.metric.GvkToString(), err)
+				return fmt.Errorf("could not find any matching resources for metric with filter '%s'. %w", h.metric.GvkToString(), err)
 			}
-
-			if len(list.Items) > 0 {
-				resources = append(resources, list.Items...)
-			}
-		}
+			results[i] = managed
+			return nil
+		})
 	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
+	return slices.Concat(results...), nil
+}
 
-	managedResources := make([]Managed, 0, len(resources))
-	for _, u := range resources {
-		managed := Managed{}
-		err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.UnstructuredContent(), &managed)
+// listManaged lists all resources of the given type page by page and converts each page
+// right away, so that only a single page of unstructured objects is held in memory at a time
+func (h *ManagedHandler) listManaged(ctx context.Context, gvr schema.GroupVersionResource) ([]Managed, error) {
+	var managedResources []Managed
+	options := metav1.ListOptions{Limit: managedListPageSize}
+	for {
+		list, err := h.dCli.Resource(gvr).List(ctx, options)
 		if err != nil {
 			return nil, err
 		}
 
-		managedResources = append(managedResources, managed)
-	}
+		for _, u := range list.Items {
+			managed := Managed{}
+			if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.UnstructuredContent(), &managed); err != nil {
+				return nil, err
+			}
+			managedResources = append(managedResources, managed)
+		}
 
-	return managedResources, nil
+		options.Continue = list.GetContinue()
+		if options.Continue == "" {
+			return managedResources, nil
+		}
+	}
 }
 
 // Managed is a struct that holds the managed resource
@@ -251,7 +427,7 @@ type Managed struct {
 
 // Status is a struct that holds the status of a resource
 type Status struct {
-	AtProvider map[string]any `json:"forProvider"`
+	AtProvider map[string]any `json:"atProvider"`
 	Conditions []Condition    `json:"conditions"`
 }
 
@@ -275,8 +451,29 @@ type ClusterResourceStatus struct {
 	Status         map[string]bool
 }
 
+// matchesConditionFilter returns true if the managed resource meets all condition requirements
+func matchesConditionFilter(managed Managed, filter []v1alpha1.ConditionRequirement) bool {
+	for _, requirement := range filter {
+		status := v1alpha1.StatusStringUnknown
+		for _, condition := range managed.Status.Conditions {
+			if strings.EqualFold(condition.Type, requirement.Type) {
+				status = condition.Status
+				break
+			}
+		}
+		if !strings.EqualFold(status, requirement.Status) {
+			return false
+		}
+	}
+	return true
+}
+
 func (h *ManagedHandler) matchesGroupVersionKind(crd apiextensionsv1.CustomResourceDefinition) bool {
-	target := h.metric.Spec.Target
+	return matchesTarget(h.metric.Spec.Target, crd)
+}
+
+// matchesTarget returns true if the CRD matches the target of a managed metric
+func matchesTarget(target *v1alpha1.GroupVersionKind, crd apiextensionsv1.CustomResourceDefinition) bool {
 	// if the user does not specify a GVK target, any managed CRD is considered a match
 	if target == nil {
 		return true
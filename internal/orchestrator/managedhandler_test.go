@@ -3,20 +3,26 @@ package orchestrator
 import (
 	"context"
 	"fmt"
+	"maps"
 	"slices"
 	"strings"
 	"testing"
+	"time"
 
 	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/util/rand"
 	"k8s.io/apimachinery/pkg/util/yaml"
 	dynamicfake "k8s.io/client-go/dynamic/fake"
+	clienttesting "k8s.io/client-go/testing"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
 	"github.com/openmcp-project/metrics-operator/api/v1alpha1"
+	"github.com/openmcp-project/metrics-operator/internal/clientoptl"
 )
 
 func TestGetManagedResources(t *testing.T) {
@@ -72,6 +78,7 @@ func TestGetManagedResources(t *testing.T) {
 	tests := []struct {
 		name             string
 		gvkTarget        schema.GroupVersionKind
+		crdCategories    []string
 		clusterCRDs      []string
 		clusterResources []string
 		wantResources    []string
@@ -201,6 +208,25 @@ func TestGetManagedResources(t *testing.T) {
 				resourceFixture[nopResources],
 			),
 		},
+		{
+			name:          "custom crd categories",
+			gvkTarget:     schema.GroupVersionKind{},
+			crdCategories: []string{"kro"},
+			clusterCRDs: []string{
+				managedAndServedCRD(k8sObjectGVK),
+				categorizedCRD(nopResourceGVK, "kro"),
+				categorizedCRD(helmReleaseGVK, "kro", "all"),
+			},
+			clusterResources: slices.Concat(
+				resourceFixture[k8sObjects],
+				resourceFixture[nopResources],
+				resourceFixture[helmReleases],
+			),
+			wantResources: slices.Concat(
+				resourceFixture[nopResources],
+				resourceFixture[helmReleases],
+			),
+		},
 	}
 
 	for _, tt := range tests {
@@ -216,6 +242,7 @@ func TestGetManagedResources(t *testing.T) {
 							Version: tt.gvkTarget.Version,
 							Kind:    tt.gvkTarget.Kind,
 						},
+						CRDCategories: tt.crdCategories,
 					},
 				},
 			}
@@ -341,12 +368,25 @@ func unmanagedCRD(gvk schema.GroupVersionKind) string {
 	return fakeCRDTemplate(gvk, false, true)
 }
 
+func categorizedCRD(gvk schema.GroupVersionKind, categories ...string) string {
+	return fakeCRDWithCategories(gvk, categories, true)
+}
+
 func fakeCRDTemplate(gvk schema.GroupVersionKind, managed bool, served bool) string {
-	categories := "[]"
+	var categories []string
 	if managed {
-		categories = `
-    - crossplane
-    - managed`
+		categories = []string{"crossplane", "managed"}
+	}
+	return fakeCRDWithCategories(gvk, categories, served)
+}
+
+func fakeCRDWithCategories(gvk schema.GroupVersionKind, crdCategories []string, served bool) string {
+	categories := "[]"
+	if len(crdCategories) > 0 {
+		categories = ""
+		for _, category := range crdCategories {
+			categories += "\n    - " + category
+		}
 	}
 	return fmt.Sprintf(`apiVersion: apiextensions.k8s.io/v1
 kind: CustomResourceDefinition
@@ -376,3 +416,198 @@ spec:
 		gvk.Version,
 		served)
 }
+
+func TestMatchesConditionFilter(t *testing.T) {
+	managed := Managed{Status: Status{Conditions: []Condition{
+		{Type: "Ready", Status: "False"},
+		{Type: "Synced", Status: "True"},
+	}}}
+
+	tests := []struct {
+		name   string
+		filter []v1alpha1.ConditionRequirement
+		want   bool
+	}{
+		{name: "no filter", want: true},
+		{name: "single match", filter: []v1alpha1.ConditionRequirement{{Type: "Ready", Status: "False"}}, want: true},
+		{name: "case-insensitive type", filter: []v1alpha1.ConditionRequirement{{Type: "synced", Status: "True"}}, want: true},
+		{name: "single mismatch", filter: []v1alpha1.ConditionRequirement{{Type: "Ready", Status: "True"}}, want: false},
+		{
+			name:   "all requirements must match",
+			filter: []v1alpha1.ConditionRequirement{{Type: "Ready", Status: "False"}, {Type: "Synced", Status: "False"}},
+			want:   false,
+		},
+		{name: "missing condition is unknown", filter: []v1alpha1.ConditionRequirement{{Type: "Healthy", Status: "Unknown"}}, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := matchesConditionFilter(managed, tt.filter); got != tt.want {
+				t.Errorf("matchesConditionFilter() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAddManagedDimensions(t *testing.T) {
+	obj := map[string]any{
+		"apiVersion": "s3.aws.upbound.io/v1beta1",
+		"kind":       "Bucket",
+		"metadata":   map[string]any{"name": "logs", "creationTimestamp": "2024-05-15T12:00:00Z"},
+		"spec":       map[string]any{"forProvider": map[string]any{"region": "eu-central-1", "tags": map[string]any{"team": "a"}}},
+		"status": map[string]any{
+			"atProvider": map[string]any{"arn": "arn:aws:s3:::logs"},
+			"conditions": []any{map[string]any{"type": "Ready", "status": "True"}},
+		},
+	}
+	managed := Managed{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj, &managed); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		dimensions []v1alpha1.Projection
+		want       map[string]string
+		wantErr    string
+	}{
+		{
+			name: "fields of spec.forProvider and status.atProvider",
+			dimensions: []v1alpha1.Projection{
+				{Name: "region", FieldPath: "spec.forProvider.region"},
+				{Name: "arn", FieldPath: "status.atProvider.arn"},
+				{Name: "ready", FieldPath: "status.conditions[type=Ready].status"},
+			},
+			want: map[string]string{"region": "eu-central-1", "arn": "arn:aws:s3:::logs", "ready": "True"},
+		},
+		{
+			name: "typed extraction",
+			dimensions: []v1alpha1.Projection{
+				{Name: "tags", FieldPath: "spec.forProvider.tags", Type: v1alpha1.TypeMap},
+				{Name: "created", FieldPath: "metadata.creationTimestamp", Type: v1alpha1.TypeTimestamp},
+			},
+			want: map[string]string{"tags": `{"team":"a"}`, "created": "1715774400"},
+		},
+		{
+			name: "failing dimensions are left out and reported",
+			dimensions: []v1alpha1.Projection{
+				{Name: "region", FieldPath: "spec.forProvider.region"},
+				{Name: "tags", FieldPath: "spec.forProvider.tags", Type: v1alpha1.TypeTimestamp},
+			},
+			want:    map[string]string{"region": "eu-central-1"},
+			wantErr: "dimension tags",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dp := clientoptl.NewDataPoint()
+			err := addManagedDimensions(dp, managed, tt.dimensions)
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("addManagedDimensions() error = %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("addManagedDimensions() error = %v, want %q", err, tt.wantErr)
+			}
+			if !maps.Equal(dp.Dimensions, tt.want) {
+				t.Errorf("addManagedDimensions() dimensions = %v, want %v", dp.Dimensions, tt.want)
+			}
+		})
+	}
+}
+
+func TestResourceAgeReference(t *testing.T) {
+	created := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	transitioned := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	managed := Managed{
+		Metadata: metav1.ObjectMeta{CreationTimestamp: metav1.NewTime(created)},
+		Status: Status{Conditions: []Condition{
+			{Type: "Ready", Status: "False", LastTransitionTime: transitioned.Format(time.RFC3339)},
+			{Type: "Synced", Status: "True", LastTransitionTime: "invalid"},
+		}},
+	}
+
+	tests := []struct {
+		name          string
+		managed       Managed
+		conditionType string
+		want          time.Time
+		wantOK        bool
+	}{
+		{name: "creation timestamp", managed: managed, want: created, wantOK: true},
+		{name: "missing creation timestamp", managed: Managed{}, wantOK: false},
+		{name: "condition transition", managed: managed, conditionType: "ready", want: transitioned, wantOK: true},
+		{name: "invalid condition transition", managed: managed, conditionType: "Synced", wantOK: false},
+		{name: "missing condition", managed: managed, conditionType: "Healthy", wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := resourceAgeReference(tt.managed, tt.conditionType)
+			if ok != tt.wantOK {
+				t.Fatalf("resourceAgeReference() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if ok && !got.Equal(tt.want) {
+				t.Errorf("resourceAgeReference() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAgeStatistics(t *testing.T) {
+	if got := ageStatistics(nil); got != nil {
+		t.Errorf("ageStatistics() = %v, want nil", got)
+	}
+
+	got := ageStatistics([]time.Duration{time.Hour, 10 * time.Minute, 2 * time.Hour})
+	want := map[string]time.Duration{
+		v1alpha1.AgeStatisticOldest:  2 * time.Hour,
+		v1alpha1.AgeStatisticNewest:  10 * time.Minute,
+		v1alpha1.AgeStatisticAverage: time.Hour + 10*time.Minute/3,
+	}
+	for statistic, age := range want {
+		if got[statistic] != age {
+			t.Errorf("unexpected %s age: wanted=%v, got=%v", statistic, age, got[statistic])
+		}
+	}
+}
+
+func TestListManagedPaging(t *testing.T) {
+	gvk := schema.GroupVersionKind{Group: "nop.crossplane.io", Version: "v1alpha1", Kind: "NopResource"}
+	gvr := schema.GroupVersionResource{Group: gvk.Group, Version: gvk.Version, Resource: "nopresources"}
+
+	items := make([]unstructured.Unstructured, 0, 3)
+	for range 3 {
+		items = append(items, toUnstructured(t, fakeResource(gvk)))
+	}
+
+	dCli := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{gvr: "NopResourceList"})
+	// the fake client drops the continue token from the list options, so pages are served by call count
+	calls := 0
+	dCli.PrependReactor("list", "nopresources", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		calls++
+		list := &unstructured.UnstructuredList{Object: map[string]any{"apiVersion": gvk.GroupVersion().String(), "kind": "NopResourceList"}}
+		switch calls {
+		case 1:
+			list.Items = items[:2]
+			list.SetContinue("page-2")
+		case 2:
+			list.Items = items[2:]
+		default:
+			return true, nil, fmt.Errorf("unexpected list request %d", calls)
+		}
+		return true, list, nil
+	})
+
+	handler := ManagedHandler{dCli: dCli}
+	result, err := handler.listManaged(context.Background(), gvr)
+	if err != nil {
+		t.Fatalf("listManaged failed: %v", err)
+	}
+	if len(result) != len(items) {
+		t.Errorf("unexpected result length: wanted=%v, got=%v", len(items), len(result))
+	}
+	if calls != 2 {
+		t.Errorf("unexpected number of list requests: wanted=2, got=%v", calls)
+	}
+}
@@ -2,20 +2,30 @@ package orchestrator
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"maps"
+	"math"
+	"slices"
 	"strconv"
 	"strings"
+	"time"
 
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/client-go/discovery"
 	"k8s.io/client-go/dynamic"
 
 	"github.com/openmcp-project/metrics-operator/api/v1alpha1"
 	"github.com/openmcp-project/metrics-operator/internal/clientoptl"
+	"github.com/openmcp-project/metrics-operator/internal/expression"
 )
 
+var namespaceGVR = schema.GroupVersionResource{Version: "v1", Resource: "namespaces"}
+
 // MetricHandler is used to monitor a metric
 type MetricHandler struct {
 	dCli        dynamic.Interface
@@ -23,8 +33,29 @@ type MetricHandler struct {
 
 	metric v1alpha1.Metric
 
-	gaugeMetric *clientoptl.Metric // Changed from dtClient
-	clusterName *string
+	gaugeMetric   *clientoptl.Metric // Changed from dtClient
+	clusterName   *string
+	clusterLabels map[string]string
+
+	// baseline is the baseline of the recorded data points for the next observation
+	baseline *v1alpha1.MetricBaseline
+	// lastExport is the last export the next observation is compared against by the export policy
+	lastExport *v1alpha1.MetricLastExport
+	// samplingWindow is the sampling window after the observation, sampleOnly is true if it was not exported
+	samplingWindow *v1alpha1.MetricSamplingWindow
+	sampleOnly     bool
+
+	// recordedSeries are the series of the observation, if requested with spec.debug.recordDimensions
+	recordedSeries *v1alpha1.RecordedSeries
+	// snapshot is the snapshot of the observation, if requested with spec.snapshot
+	snapshot *v1alpha1.MetricSnapshotSpec
+	// exported are the data points recorded for the export
+	exported []*clientoptl.DataPoint
+
+	// timedOut lists the collection phases that exceeded the timeout of the metric
+	timedOut []string
+	// samples are names of the listed resources for debugging
+	samples []string
 }
 
 // Monitor is used to monitor the metric
@@ -36,8 +67,35 @@ func (h *MetricHandler) Monitor(ctx context.Context) (MonitorResult, error) {
 	// This handler focuses on fetching resources, grouping, and recording data points.
 	result := MonitorResult{Observation: &v1alpha1.MetricObservation{Timestamp: metav1.Now()}}
 
-	list, errGet := h.getResources(ctx)
-	if errGet != nil {
+	timeout := PhaseTimeout(h.metric.Spec.Timeout)
+	listCtx, cancelList := context.WithTimeout(ctx, timeout)
+	list, errGet := h.getResources(listCtx)
+	listTimedOut := listCtx.Err() == context.DeadlineExceeded
+	cancelList()
+	switch {
+	case errGet != nil && listTimedOut && list != nil && len(list.Items) > 0:
+		// continue with the resources of the namespaces listed so far
+		h.timedOut = append(h.timedOut, CollectionPhaseList)
+	case errGet != nil && listTimedOut:
+		result.Error = errGet
+		result.Phase = v1alpha1.PhaseFailed
+		result.Reason = "CollectionTimeout"
+		result.Message = fmt.Sprintf("listing the target resource(s) timed out after %v", timeout)
+		result.TimedOut = []string{CollectionPhaseList}
+		return result, nil
+	case errGet != nil && errors.As(errGet, new(*TargetNotFoundError)):
+		result.Error = errGet
+		result.Phase = v1alpha1.PhaseFailed
+		result.Reason = ReasonTargetNotFound
+		result.Message = errGet.Error()
+		return result, nil
+	case errGet != nil && apierrors.IsForbidden(errGet):
+		result.Error = errGet
+		result.Phase = v1alpha1.PhaseFailed
+		result.Reason = ReasonInsufficientPermissions
+		result.Message = fmt.Sprintf("not allowed to list the target resource(s): %s", errGet.Error())
+		return result, nil
+	case errGet != nil:
 		result.Error = errGet
 		result.Phase = v1alpha1.PhaseFailed
 		result.Reason = "GetResourcesFailed"
@@ -45,29 +103,105 @@ func (h *MetricHandler) Monitor(ctx context.Context) (MonitorResult, error) {
 		return result, nil // Return error state, but not the error itself to controller
 	}
 
-	if len(h.metric.Spec.Projections) == 0 {
-		return h.simpleMonitor(ctx, list)
+	h.samples = samples(list.Items, h.metric.Spec.Debug, func(item unstructured.Unstructured) string {
+		return objectName(item.GetNamespace(), item.GetName())
+	})
+
+	var err error
+	switch {
+	case h.metric.Spec.Preset != "":
+		result, err = h.presetMonitor(ctx, list)
+	case h.metric.Spec.Combine != "":
+		result, err = h.combineMonitor(ctx, list)
+	case len(h.projections()) == 0 && len(h.metric.Spec.Enrichments) == 0:
+		result, err = h.simpleMonitor(ctx, list)
+	default:
+		result, err = h.projectionsMonitor(ctx, list)
 	}
-	return h.projectionsMonitor(ctx, list)
+	result.Baseline = h.baseline
+	result.LastExport = h.lastExport
+	result.SamplingWindow = h.samplingWindow
+	result.SampleOnly = h.sampleOnly
+	result.RecordedSeries = h.recordedSeries
+	result.Snapshot = h.snapshot
+	result.TimedOut = h.timedOut
+	result.Samples = h.samples
+	return result, err
+}
+
+// recordMetrics records the data points aggregated over the sampling window and converted according to the metric's mode,
+// unless they are only sampled or the export policy suppresses them,
+// and remembers the sampling window, baseline and last export for the next observation
+func (h *MetricHandler) recordMetrics(ctx context.Context, dataPoints ...*clientoptl.DataPoint) error {
+	addClusterLabels(h.clusterLabels, dataPoints...)
+	now := time.Now()
+	h.recordedSeries = recordedSeries(h.metric.Spec.Debug, dataPoints, now)
+	h.snapshot = snapshot(&h.metric.Spec, dataPoints, now)
+	sampled, window, sampleOnly, err := applySampling(&h.metric.Spec, h.metric.Status.SamplingWindow, dataPoints, now)
+	if err != nil {
+		return err
+	}
+	h.samplingWindow = window
+	h.sampleOnly = sampleOnly
+	if sampleOnly {
+		h.baseline = h.metric.Status.Baseline
+		h.lastExport = h.metric.Status.LastExport
+		return nil
+	}
+	converted, baseline, err := applyMode(&h.metric.Spec, h.metric.Status.Baseline, sampled, len(h.timedOut) > 0, now)
+	if err != nil {
+		return err
+	}
+	h.baseline = baseline
+	exported, lastExport, err := applyExportPolicy(&h.metric.Spec, h.metric.Status.LastExport, converted, now)
+	if err != nil {
+		return err
+	}
+	h.lastExport = lastExport
+	h.exported = exported
+	// the dimension policy of the data sink can't tell the dimensions apart once they are encoded into a single dimension
+	if encodesDimensions(h.metric.Spec.Encoding) {
+		exported = h.gaugeMetric.ApplyDimensionPolicy(ctx, exported)
+	}
+	encoded, err := encodeSeries(h.metric.Spec.Encoding, exported)
+	if err != nil {
+		return err
+	}
+	return h.gaugeMetric.RecordMetrics(ctx, encoded...)
 }
 
 func (h *MetricHandler) simpleMonitor(ctx context.Context, list *unstructured.UnstructuredList) (MonitorResult, error) {
-	primaryCount := len(list.Items)
-	dataPoint := clientoptl.NewDataPoint().SetValue(int64(primaryCount))
+	value := int64(len(list.Items))
+	// without projections, the values of all matched resources are aggregated, e.g. the field of a single named resource
+	if vf := h.metric.Spec.ValueFrom; vf != nil {
+		valueByUID := resolveValueFrom(list, vf)
+		v, ok := aggregateGroupValue(slices.Collect(maps.Keys(valueByUID)), valueByUID, vf)
+		if !ok {
+			return MonitorResult{
+				Observation: &v1alpha1.MetricObservation{Timestamp: metav1.Now()},
+				Error:       fmt.Errorf("field '%s' not found in %d matched resource(s)", vf.FieldPath, len(list.Items)),
+				Phase:       v1alpha1.PhaseFailed,
+				Reason:      "ValueNotFound",
+				Message:     fmt.Sprintf("no value of field '%s' found in the %d matched resource(s)", vf.FieldPath, len(list.Items)),
+			}, nil
+		}
+		value = v
+	}
+	dataPoint := clientoptl.NewDataPoint().SetValue(value)
 	h.setDataPointBaseDimensions(dataPoint)
 
 	metricObservation := &v1alpha1.MetricObservation{
 		Timestamp:   metav1.Now(),
-		LatestValue: strconv.Itoa(len(list.Items)),
+		LatestValue: strconv.FormatInt(value, 10),
 	}
 
-	if err := h.gaugeMetric.RecordMetrics(ctx, dataPoint); err != nil {
+	if err := h.recordMetrics(ctx, dataPoint); err != nil {
 		// TODO: we should really return the error to the controller and handle it there.
 		return MonitorResult{
 			Observation: metricObservation,
 			Error:       err,
 			Phase:       v1alpha1.PhaseFailed,
-			Reason:      "RecordMetricFailed",
+			Reason:      recordFailureReason(err),
 			Message:     fmt.Sprintf("failed to record metric value: %s", err.Error()),
 		}, nil // Return the result, error indicates failure in Monitor execution, not necessarily metric export failure (handled by controller)
 	}
@@ -79,10 +213,84 @@ func (h *MetricHandler) simpleMonitor(ctx context.Context, list *unstructured.Un
 	}, nil
 }
 
+// combineMonitor counts the resources of all additional targets and records the result of
+// the combine expression evaluated over those counts and the count of the primary target
+func (h *MetricHandler) combineMonitor(ctx context.Context, list *unstructured.UnstructuredList) (MonitorResult, error) {
+	result := MonitorResult{Observation: &v1alpha1.MetricObservation{Timestamp: metav1.Now()}}
+
+	expr, err := expression.Parse(h.metric.Spec.Combine)
+	if err != nil {
+		result.Error = err
+		result.Phase = v1alpha1.PhaseFailed
+		result.Reason = "InvalidCombineExpression"
+		result.Message = fmt.Sprintf("failed to parse combine expression: %s", err.Error())
+		return result, nil
+	}
+
+	counts := map[string]float64{v1alpha1.CombinePrimaryTarget: float64(len(list.Items))}
+	for _, t := range h.metric.Spec.Targets {
+		if _, exists := counts[t.Name]; exists {
+			result.Error = fmt.Errorf("duplicate target name '%s'", t.Name)
+			result.Phase = v1alpha1.PhaseFailed
+			result.Reason = "InvalidCombineExpression"
+			result.Message = fmt.Sprintf("target name '%s' is used more than once or shadows '%s'", t.Name, v1alpha1.CombinePrimaryTarget)
+			return result, nil
+		}
+		targetList, errGet := h.listTarget(ctx, t.Target, t.LabelSelector, t.FieldSelector)
+		if errGet != nil {
+			result.Error = errGet
+			result.Phase = v1alpha1.PhaseFailed
+			result.Reason = "GetResourcesFailed"
+			result.Message = fmt.Sprintf("failed to retrieve resource(s) of target '%s': %s", t.Name, errGet.Error())
+			return result, nil
+		}
+		counts[t.Name] = float64(len(targetList.Items))
+	}
+
+	value, err := expr.Eval(counts)
+	if err != nil {
+		result.Error = err
+		result.Phase = v1alpha1.PhaseFailed
+		result.Reason = "CombineFailed"
+		result.Message = fmt.Sprintf("failed to evaluate combine expression '%s': %s", expr, err.Error())
+		return result, nil
+	}
+
+	combined := int64(math.Round(value))
+	dataPoint := clientoptl.NewDataPoint().SetValue(combined)
+	h.setDataPointBaseDimensions(dataPoint)
+	result.Observation = &v1alpha1.MetricObservation{Timestamp: metav1.Now(), LatestValue: strconv.FormatInt(combined, 10)}
+
+	if err := h.recordMetrics(ctx, dataPoint); err != nil {
+		result.Error = err
+		result.Phase = v1alpha1.PhaseFailed
+		result.Reason = recordFailureReason(err)
+		result.Message = fmt.Sprintf("failed to record metric value: %s", err.Error())
+		return result, nil
+	}
+
+	result.Phase = v1alpha1.PhaseActive
+	result.Reason = v1alpha1.ReasonMonitoringActive
+	result.Message = fmt.Sprintf("combined metric value recorded for resource '%s'", h.metric.GvkToString())
+	return result, nil
+}
+
 func (h *MetricHandler) projectionsMonitor(ctx context.Context, list *unstructured.UnstructuredList) (MonitorResult, error) {
-	groups := extractProjectionGroupsFrom(list, h.metric.Spec.Projections)
+	projectionCtx, cancel := context.WithTimeout(ctx, PhaseTimeout(h.metric.Spec.Timeout))
+	defer cancel()
+
 	result := MonitorResult{Observation: &v1alpha1.MetricObservation{Timestamp: metav1.Now()}}
 
+	related, err := listRelatedResources(projectionCtx, h.dCli, h.discoClient, h.metric.Spec.Enrichments)
+	if err != nil {
+		result.Error = err
+		result.Phase = v1alpha1.PhaseFailed
+		result.Reason = "EnrichmentFailed"
+		result.Message = fmt.Sprintf("failed to retrieve related resource(s): %s", err.Error())
+		return result, nil
+	}
+	groups := extractProjectionGroupsFrom(list, h.projections(), related...)
+
 	// Pre-resolve valueFrom per object UID
 	valueByUID := resolveValueFrom(list, h.metric.Spec.ValueFrom)
 
@@ -90,6 +298,11 @@ func (h *MetricHandler) projectionsMonitor(ctx context.Context, list *unstructur
 	var recordErrors []error
 
 	for _, group := range groups {
+		if projectionCtx.Err() != nil {
+			// keep the groups recorded so far
+			h.timedOut = append(h.timedOut, CollectionPhaseProjection)
+			break
+		}
 		groupCount := len(group)
 		dataPoint := clientoptl.NewDataPoint().SetValue(int64(groupCount))
 
@@ -111,6 +324,9 @@ func (h *MetricHandler) projectionsMonitor(ctx context.Context, list *unstructur
 				// Add projected dimension only if the value is non-empty and no error occurred
 				if pField.error == nil && pField.value != "" {
 					dataPoint.AddDimension(pField.name, pField.value)
+				} else if pField.error == nil && !pField.found && pField.name == NAMESPACE && h.addsNamespaceProjection() {
+					// cluster-scoped resources have no namespace
+					continue
 				} else {
 					recordErrors = append(recordErrors, fmt.Errorf("projection error for %s: %w", pField.name, pField.error))
 				}
@@ -118,9 +334,11 @@ func (h *MetricHandler) projectionsMonitor(ctx context.Context, list *unstructur
 		}
 
 		dataPoints = append(dataPoints, dataPoint)
+	}
 
-		// Record all collected data points
-		errRecord := h.gaugeMetric.RecordMetrics(ctx, dataPoints...)
+	// Record all collected data points at once, so the mode and export policy see all of them
+	if len(dataPoints) > 0 {
+		errRecord := h.recordMetrics(ctx, dataPoints...)
 		if errRecord != nil {
 			recordErrors = append(recordErrors, errRecord)
 		}
@@ -131,20 +349,42 @@ func (h *MetricHandler) projectionsMonitor(ctx context.Context, list *unstructur
 			combinedError := fmt.Errorf("errors during metric recording: %v", recordErrors)
 			result.Error = combinedError
 			result.Phase = v1alpha1.PhaseFailed
-			result.Reason = "RecordMetricFailed"
+			result.Reason = recordFailureReason(errors.Join(recordErrors...))
 			result.Message = fmt.Sprintf("failed to record metric value(s): %s", combinedError.Error())
 		} else {
 			result.Phase = v1alpha1.PhaseActive
 			result.Reason = v1alpha1.ReasonMonitoringActive
 			result.Message = fmt.Sprintf("metric values recorded for resource '%s'", h.metric.GvkToString())
-			// Observation might need adjustment depending on how results should be represented in status
-			result.Observation = &v1alpha1.MetricObservation{Timestamp: metav1.Now(), LatestValue: strconv.Itoa(len(list.Items))} // Report total count for now
+			// the total count of the matched resources, broken down by the exported series
+			observation := &v1alpha1.MetricObservation{Timestamp: metav1.Now(), LatestValue: strconv.Itoa(len(list.Items))}
+			observation.Series, observation.OmittedSeries = seriesValues(h.exported, v1alpha1.MaxObservedSeries)
+			result.Observation = observation
 		}
 		// Return the result, error indicates failure in Monitor execution, not necessarily metric export failure (handled by controller)
 	}
+	if result.Phase == "" && len(h.timedOut) > 0 {
+		result.Phase = v1alpha1.PhaseFailed
+		result.Reason = "CollectionTimeout"
+		result.Message = fmt.Sprintf("evaluating the projections timed out after %v", PhaseTimeout(h.metric.Spec.Timeout))
+	}
 	return result, nil
 }
 
+// projections returns the projections of the metric, with the namespace projection appended if it is grouped by namespace
+func (h *MetricHandler) projections() []v1alpha1.Projection {
+	if !h.addsNamespaceProjection() {
+		return h.metric.Spec.Projections
+	}
+	return append(slices.Clip(h.metric.Spec.Projections), v1alpha1.Projection{Name: NAMESPACE, FieldPath: "metadata.namespace", Type: v1alpha1.TypePrimitive})
+}
+
+// addsNamespaceProjection returns true if the metric is grouped by namespace and has no projection named namespace itself
+func (h *MetricHandler) addsNamespaceProjection() bool {
+	return h.metric.Spec.GroupByNamespace && !slices.ContainsFunc(h.metric.Spec.Projections, func(projection v1alpha1.Projection) bool {
+		return projection.Name == NAMESPACE
+	})
+}
+
 func (h *MetricHandler) setDataPointBaseDimensions(dataPoint *clientoptl.DataPoint) {
 	if h.metric.Spec.Target.Kind != "" {
 		dataPoint.AddDimension(RESOURCE, h.metric.Spec.Target.Kind)
@@ -173,53 +413,136 @@ func (e *projectedField) GetID() string {
 }
 
 func (h *MetricHandler) getResources(ctx context.Context) (*unstructured.UnstructuredList, error) {
+	exclude, err := newExclusion(h.metric.Spec.ExcludeLabelSelector, h.metric.Spec.ExcludeFieldSelector)
+	if err != nil {
+		return nil, err
+	}
+	list, err := h.listTarget(ctx, h.metric.Spec.Target, h.metric.Spec.LabelSelector, h.metric.Spec.FieldSelector)
+	if list != nil && exclude != nil {
+		list.Items = slices.DeleteFunc(list.Items, exclude.matches)
+	}
+	return list, err
+}
+
+func (h *MetricHandler) listTarget(ctx context.Context, target v1alpha1.MetricTarget, labelSelector, fieldSelector string) (*unstructured.UnstructuredList, error) {
 	var options = metav1.ListOptions{}
 	// if not defined in the metric, the list options need to be empty to get resources based on GVR only
 	// Add label selector if present
-	if h.metric.Spec.LabelSelector != "" {
-		options.LabelSelector = h.metric.Spec.LabelSelector
+	if labelSelector != "" {
+		options.LabelSelector = labelSelector
 	}
 
 	// Add field selector if present
-	if h.metric.Spec.FieldSelector != "" {
-		options.FieldSelector = h.metric.Spec.FieldSelector
+	if fieldSelector != "" {
+		options.FieldSelector = fieldSelector
+	}
+
+	// Select the named resource only
+	if target.Name != "" {
+		nameSelector := "metadata.name=" + target.Name
+		if options.FieldSelector != "" {
+			nameSelector = options.FieldSelector + "," + nameSelector
+		}
+		options.FieldSelector = nameSelector
 	}
 
-	gvr, err := GetGVRfromGVK(h.metric.Spec.Target.GVK(), h.discoClient)
+	gvr, err := resolveTarget(target.GVK(), h.discoClient)
 	if err != nil {
 		return nil, err
 	}
-	list, err := h.dCli.Resource(gvr).List(ctx, options)
+	if !target.IsNamespaceScoped() {
+		list, err := listSelected(ctx, h.dCli.Resource(gvr), options)
+		if err != nil {
+			return nil, fmt.Errorf("could not find any matching resources for metric set with filter '%s'. %w", gvr.String(), err)
+		}
+		return list, nil
+	}
+
+	namespaces, err := h.resolveNamespaces(ctx, target)
 	if err != nil {
-		return nil, fmt.Errorf("could not find any matching resources for metric set with filter '%s'. %w", gvr.String(), err)
+		return nil, err
+	}
+
+	list := &unstructured.UnstructuredList{}
+	for _, ns := range namespaces {
+		nsList, err := listSelected(ctx, h.dCli.Resource(gvr).Namespace(ns), options)
+		if err != nil {
+			// the resources of the namespaces listed so far are returned as well
+			return list, fmt.Errorf("could not find any matching resources for metric set with filter '%s' in namespace '%s'. %w", gvr.String(), ns, err)
+		}
+		list.Items = append(list.Items, nsList.Items...)
 	}
 
 	return list, nil
 }
 
+// resolveNamespaces returns the sorted union of the explicitly listed namespaces
+// and the namespaces matching the target's namespace selector
+func (h *MetricHandler) resolveNamespaces(ctx context.Context, target v1alpha1.MetricTarget) ([]string, error) {
+	namespaces := sets.New(target.Namespaces...)
+
+	if target.NamespaceSelector != nil {
+		selector, err := metav1.LabelSelectorAsSelector(target.NamespaceSelector)
+		if err != nil {
+			return nil, fmt.Errorf("invalid namespace selector: %w", err)
+		}
+		nsList, err := h.dCli.Resource(namespaceGVR).List(ctx, metav1.ListOptions{LabelSelector: selector.String()})
+		if err != nil {
+			return nil, fmt.Errorf("could not list namespaces matching selector '%s'. %w", selector.String(), err)
+		}
+		for _, ns := range nsList.Items {
+			namespaces.Insert(ns.GetName())
+		}
+	}
+
+	return sets.List(namespaces), nil
+}
+
 // NewMetricHandler creates a new MetricHandler
 func NewMetricHandler(metric v1alpha1.Metric, qc QueryConfig, gaugeMetric *clientoptl.Metric) (*MetricHandler, error) { // Changed dtClient to gaugeMetric
-	dynamicClient, errCli := dynamic.NewForConfig(&qc.RestConfig)
+	dynamicClient, errCli := qc.dynamicClient()
 	if errCli != nil {
 		return nil, errCli
 	}
 
-	disco, errDisco := discovery.NewDiscoveryClientForConfig(&qc.RestConfig)
+	disco, errDisco := qc.discoveryClient()
 	if errDisco != nil {
 		return nil, errDisco
 	}
 
 	var handler = &MetricHandler{
-		metric:      metric,
-		dCli:        dynamicClient,
-		discoClient: disco,
-		gaugeMetric: gaugeMetric,
-		clusterName: qc.ClusterName,
+		metric:        metric,
+		dCli:          dynamicClient,
+		discoClient:   disco,
+		gaugeMetric:   gaugeMetric,
+		clusterName:   qc.ClusterName,
+		clusterLabels: qc.ClusterLabels,
 	}
 
 	return handler, nil
 }
 
+// ReasonTargetNotFound is the reason of metrics whose target kind is not served by the cluster
+const ReasonTargetNotFound = "TargetNotFound"
+
+// TargetNotFoundError reports a target kind that is not served by the cluster
+type TargetNotFoundError struct {
+	GVK schema.GroupVersionKind
+}
+
+func (e *TargetNotFoundError) Error() string {
+	return fmt.Sprintf("kind %s is not served by the cluster in group version %s", e.GVK.Kind, e.GVK.GroupVersion())
+}
+
+// resolveTarget returns the resource of the target kind, or a *TargetNotFoundError if the cluster does not serve it
+func resolveTarget(gvk schema.GroupVersionKind, disco discovery.DiscoveryInterface) (schema.GroupVersionResource, error) {
+	gvr, err := GetGVRfromGVK(gvk, disco)
+	if apierrors.IsNotFound(err) || (err == nil && gvr.Resource == "") {
+		return gvr, &TargetNotFoundError{GVK: gvk}
+	}
+	return gvr, err
+}
+
 // GetGVRfromGVK converts GVK to GVR
 func GetGVRfromGVK(gvk schema.GroupVersionKind, disco discovery.DiscoveryInterface) (schema.GroupVersionResource, error) {
 	// TODO: this could be optimized later (e.g. by caching the discovery client)
@@ -0,0 +1,668 @@
+package orchestrator
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	clienttesting "k8s.io/client-go/testing"
+
+	"github.com/openmcp-project/metrics-operator/api/v1alpha1"
+	"github.com/openmcp-project/metrics-operator/internal/clientoptl"
+)
+
+func TestMetricHandler_getResources_namespaces(t *testing.T) {
+	objects := []runtime.Object{
+		fakeNamespace("team-a", map[string]string{"team": "a"}),
+		fakeNamespace("team-b", map[string]string{"team": "b"}),
+		fakeNamespace("other", nil),
+		fakePod("team-a", "pod-a1"),
+		fakePod("team-a", "pod-a2"),
+		fakePod("team-b", "pod-b1"),
+		fakePod("other", "pod-o1"),
+	}
+
+	tests := []struct {
+		name     string
+		target   v1alpha1.MetricTarget
+		wantPods []string
+	}{
+		{
+			name:     "cluster-wide",
+			target:   v1alpha1.MetricTarget{},
+			wantPods: []string{"pod-a1", "pod-a2", "pod-b1", "pod-o1"},
+		},
+		{
+			name:     "explicit namespaces",
+			target:   v1alpha1.MetricTarget{Namespaces: []string{"team-b", "other"}},
+			wantPods: []string{"pod-b1", "pod-o1"},
+		},
+		{
+			name: "namespace selector",
+			target: v1alpha1.MetricTarget{
+				NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"team": "a"}},
+			},
+			wantPods: []string{"pod-a1", "pod-a2"},
+		},
+		{
+			name: "union of namespaces and selector",
+			target: v1alpha1.MetricTarget{
+				Namespaces:        []string{"team-a"},
+				NamespaceSelector: &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "team", Operator: metav1.LabelSelectorOpExists}}},
+			},
+			wantPods: []string{"pod-a1", "pod-a2", "pod-b1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.target.GroupVersionKind = v1alpha1.GroupVersionKind{Version: "v1", Kind: "Pod"}
+
+			h := newFakeMetricHandler(v1alpha1.Metric{Spec: v1alpha1.MetricSpec{Target: tt.target}}, objects...)
+
+			list, err := h.getResources(context.Background())
+			require.NoError(t, err)
+
+			names := make([]string, 0, len(list.Items))
+			for _, item := range list.Items {
+				names = append(names, item.GetName())
+			}
+			require.ElementsMatch(t, tt.wantPods, names)
+		})
+	}
+}
+
+func TestMetricHandler_combineMonitor(t *testing.T) {
+	podGVK := v1alpha1.GroupVersionKind{Version: "v1", Kind: "Pod"}
+
+	objects := []runtime.Object{
+		fakeNamespace("team-a", nil),
+		fakeNamespace("team-b", nil),
+		fakePod("team-a", "pod-a1"),
+		fakePod("team-a", "pod-a2"),
+		fakePod("team-a", "pod-a3"),
+		fakePod("team-b", "pod-b1"),
+	}
+
+	tests := []struct {
+		name       string
+		combine    string
+		targets    []v1alpha1.NamedTarget
+		wantValue  int64
+		wantReason string
+	}{
+		{
+			name:    "ratio of namespace to total",
+			combine: "100 * team_a / target",
+			targets: []v1alpha1.NamedTarget{
+				{Name: "team_a", Target: v1alpha1.MetricTarget{GroupVersionKind: podGVK, Namespaces: []string{"team-a"}}},
+			},
+			wantValue:  75,
+			wantReason: v1alpha1.ReasonMonitoringActive,
+		},
+		{
+			name:    "difference",
+			combine: "target - team_b",
+			targets: []v1alpha1.NamedTarget{
+				{Name: "team_b", Target: v1alpha1.MetricTarget{GroupVersionKind: podGVK, Namespaces: []string{"team-b"}}},
+			},
+			wantValue:  3,
+			wantReason: v1alpha1.ReasonMonitoringActive,
+		},
+		{
+			name:       "invalid expression",
+			combine:    "target +",
+			wantReason: "InvalidCombineExpression",
+		},
+		{
+			name:       "unknown variable",
+			combine:    "target / missing",
+			wantReason: "CombineFailed",
+		},
+		{
+			name:    "shadowed primary target",
+			combine: "target",
+			targets: []v1alpha1.NamedTarget{
+				{Name: v1alpha1.CombinePrimaryTarget, Target: v1alpha1.MetricTarget{GroupVersionKind: podGVK}},
+			},
+			wantReason: "InvalidCombineExpression",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			metric := v1alpha1.Metric{Spec: v1alpha1.MetricSpec{
+				Target:  v1alpha1.MetricTarget{GroupVersionKind: podGVK},
+				Targets: tt.targets,
+				Combine: tt.combine,
+			}}
+			h := newFakeMetricHandler(metric, objects...)
+
+			metricClient, err := clientoptl.NewMetricClient(ctx, nil)
+			require.NoError(t, err)
+			metricClient.SetMeter("metric", nil)
+			h.gaugeMetric, err = metricClient.NewMetric("test", "", "")
+			require.NoError(t, err)
+
+			var recorded []int64
+			h.gaugeMetric.SetPrometheusFunc(func(_ map[string]string, value int64) {
+				recorded = append(recorded, value)
+			})
+
+			result, err := h.Monitor(ctx)
+			require.NoError(t, err)
+			require.Equal(t, tt.wantReason, result.Reason)
+			if tt.wantReason != v1alpha1.ReasonMonitoringActive {
+				require.Error(t, result.Error)
+				require.Empty(t, recorded)
+				return
+			}
+			require.NoError(t, result.Error)
+			require.Equal(t, []int64{tt.wantValue}, recorded)
+		})
+	}
+}
+
+func TestMetricHandler_Monitor_listTimeout(t *testing.T) {
+	podGVK := v1alpha1.GroupVersionKind{Version: "v1", Kind: "Pod"}
+	objects := []runtime.Object{
+		fakePod("team-a", "pod-a1"),
+		fakePod("team-a", "pod-a2"),
+		fakePod("team-b", "pod-b1"),
+	}
+
+	tests := []struct {
+		name         string
+		target       v1alpha1.MetricTarget
+		wantReason   string
+		wantRecorded []int64
+	}{
+		{
+			name:         "partial result of the namespaces listed in time",
+			target:       v1alpha1.MetricTarget{GroupVersionKind: podGVK, Namespaces: []string{"team-a", "team-b"}},
+			wantReason:   v1alpha1.ReasonMonitoringActive,
+			wantRecorded: []int64{2},
+		},
+		{
+			name:       "nothing listed in time",
+			target:     v1alpha1.MetricTarget{GroupVersionKind: podGVK, Namespaces: []string{"team-b"}},
+			wantReason: "CollectionTimeout",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			metric := v1alpha1.Metric{Spec: v1alpha1.MetricSpec{
+				Target:  tt.target,
+				Timeout: &metav1.Duration{Duration: 20 * time.Millisecond},
+			}}
+			h := newFakeMetricHandler(metric, objects...)
+			// listing team-b hangs until the list phase timed out
+			h.dCli.(*dynamicfake.FakeDynamicClient).PrependReactor("list", "pods", func(action clienttesting.Action) (bool, runtime.Object, error) {
+				if action.GetNamespace() != "team-b" {
+					return false, nil, nil
+				}
+				time.Sleep(50 * time.Millisecond)
+				return true, nil, context.DeadlineExceeded
+			})
+
+			metricClient, err := clientoptl.NewMetricClient(ctx, nil)
+			require.NoError(t, err)
+			metricClient.SetMeter("metric", nil)
+			h.gaugeMetric, err = metricClient.NewMetric("test", "", "")
+			require.NoError(t, err)
+
+			var recorded []int64
+			h.gaugeMetric.SetPrometheusFunc(func(_ map[string]string, value int64) {
+				recorded = append(recorded, value)
+			})
+
+			result, err := h.Monitor(ctx)
+			require.NoError(t, err)
+			require.Equal(t, tt.wantReason, result.Reason)
+			require.Equal(t, []string{CollectionPhaseList}, result.TimedOut)
+			require.Equal(t, tt.wantRecorded, recorded)
+		})
+	}
+}
+
+func TestMetricHandler_Monitor_listTimeoutDelta(t *testing.T) {
+	ctx := context.Background()
+	podGVK := v1alpha1.GroupVersionKind{Version: "v1", Kind: "Pod"}
+	objects := []runtime.Object{
+		fakePod("team-a", "pod-a1"),
+		fakePod("team-a", "pod-a2"),
+		fakePod("team-b", "pod-b1"),
+	}
+	metric := v1alpha1.Metric{Spec: v1alpha1.MetricSpec{
+		Target:           v1alpha1.MetricTarget{GroupVersionKind: podGVK, Namespaces: []string{"team-a", "team-b"}},
+		GroupByNamespace: true,
+		Mode:             v1alpha1.MetricModeDelta,
+		Timeout:          &metav1.Duration{Duration: 20 * time.Millisecond},
+	}}
+	monitor := func(hangTeamB bool) (MonitorResult, map[string]int64) {
+		t.Helper()
+		h := newFakeMetricHandler(metric, objects...)
+		if hangTeamB {
+			// listing team-b hangs until the list phase timed out
+			h.dCli.(*dynamicfake.FakeDynamicClient).PrependReactor("list", "pods", func(action clienttesting.Action) (bool, runtime.Object, error) {
+				if action.GetNamespace() != "team-b" {
+					return false, nil, nil
+				}
+				time.Sleep(50 * time.Millisecond)
+				return true, nil, context.DeadlineExceeded
+			})
+		}
+		metricClient, err := clientoptl.NewMetricClient(ctx, nil)
+		require.NoError(t, err)
+		metricClient.SetMeter("metric", nil)
+		h.gaugeMetric, err = metricClient.NewMetric("test", "", "")
+		require.NoError(t, err)
+		recorded := map[string]int64{}
+		h.gaugeMetric.SetPrometheusFunc(func(dims map[string]string, value int64) {
+			recorded[dims[NAMESPACE]] = value
+		})
+
+		result, err := h.Monitor(ctx)
+		require.NoError(t, err)
+		require.Equal(t, v1alpha1.ReasonMonitoringActive, result.Reason)
+		return result, recorded
+	}
+
+	// the first observation establishes the baseline
+	result, _ := monitor(false)
+	require.Len(t, result.Baseline.Values, 2)
+	metric.Status.Baseline = result.Baseline
+
+	// team-b is not listed in time, it is neither reported as disappeared nor dropped from the baseline
+	result, recorded := monitor(true)
+	require.Equal(t, []string{CollectionPhaseList}, result.TimedOut)
+	require.Equal(t, map[string]int64{"team-a": 0}, recorded)
+	require.Equal(t, metric.Status.Baseline.Values, result.Baseline.Values)
+	require.Len(t, result.Baseline.Dimensions, 2)
+	metric.Status.Baseline = result.Baseline
+
+	// the next complete observation does not report team-b as new
+	_, recorded = monitor(false)
+	require.Equal(t, map[string]int64{"team-a": 0, "team-b": 0}, recorded)
+}
+
+func TestMetricHandler_Monitor_targetNotFound(t *testing.T) {
+	tests := []struct {
+		name string
+		gvk  v1alpha1.GroupVersionKind
+	}{
+		{name: "kind not served", gvk: v1alpha1.GroupVersionKind{Version: "v1", Kind: "Bogus"}},
+		{name: "group version not served", gvk: v1alpha1.GroupVersionKind{Group: "example.com", Version: "v1", Kind: "Bucket"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newFakeMetricHandler(v1alpha1.Metric{Spec: v1alpha1.MetricSpec{Target: v1alpha1.MetricTarget{GroupVersionKind: tt.gvk}}})
+
+			result, err := h.Monitor(context.Background())
+			require.NoError(t, err)
+			require.Equal(t, v1alpha1.PhaseFailed, result.Phase)
+			require.Equal(t, ReasonTargetNotFound, result.Reason)
+			require.ErrorAs(t, result.Error, new(*TargetNotFoundError))
+		})
+	}
+}
+
+func TestMetricHandler_Monitor_valueFrom(t *testing.T) {
+	pod := func(name, uid string, fields map[string]interface{}) runtime.Object {
+		obj := fakePod("team-a", name)
+		obj.SetUID(types.UID(uid))
+		for key, value := range fields {
+			obj.Object[key] = value
+		}
+		return obj
+	}
+	objects := []runtime.Object{
+		pod("pod-1", "1", map[string]interface{}{"status": map[string]interface{}{"restarts": int64(2), "memory": "1Gi"}}),
+		pod("pod-2", "2", map[string]interface{}{"status": map[string]interface{}{"restarts": int64(5), "memory": "512Mi"}}),
+	}
+
+	tests := []struct {
+		name       string
+		valueFrom  v1alpha1.ValueFromProjection
+		wantReason string
+		wantValue  string
+	}{
+		{
+			name:       "sum of integers",
+			valueFrom:  v1alpha1.ValueFromProjection{FieldPath: "status.restarts"},
+			wantReason: v1alpha1.ReasonMonitoringActive,
+			wantValue:  "7",
+		},
+		{
+			name:       "max of quantities",
+			valueFrom:  v1alpha1.ValueFromProjection{FieldPath: "status.memory", Type: v1alpha1.ValueTypeQuantity, Aggregation: v1alpha1.AggregationMax},
+			wantReason: v1alpha1.ReasonMonitoringActive,
+			wantValue:  "1073741824",
+		},
+		{
+			name:       "field not found",
+			valueFrom:  v1alpha1.ValueFromProjection{FieldPath: "status.missing"},
+			wantReason: "ValueNotFound",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			metric := v1alpha1.Metric{Spec: v1alpha1.MetricSpec{
+				Target:    v1alpha1.MetricTarget{GroupVersionKind: v1alpha1.GroupVersionKind{Version: "v1", Kind: "Pod"}},
+				ValueFrom: &tt.valueFrom,
+			}}
+			h := newFakeMetricHandler(metric, objects...)
+			metricClient, err := clientoptl.NewMetricClient(ctx, nil)
+			require.NoError(t, err)
+			metricClient.SetMeter("metric", nil)
+			h.gaugeMetric, err = metricClient.NewMetric("test", "", "")
+			require.NoError(t, err)
+
+			result, err := h.Monitor(ctx)
+			require.NoError(t, err)
+			require.Equal(t, tt.wantReason, result.Reason)
+			require.Equal(t, tt.wantValue, result.Observation.GetValue())
+		})
+	}
+}
+
+func TestMetricHandler_listTarget_name(t *testing.T) {
+	h := newFakeMetricHandler(v1alpha1.Metric{})
+	var fieldSelectors []string
+	h.dCli.(*dynamicfake.FakeDynamicClient).PrependReactor("list", "pods", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		fieldSelectors = append(fieldSelectors, action.(clienttesting.ListAction).GetListRestrictions().Fields.String())
+		return false, nil, nil
+	})
+
+	target := v1alpha1.MetricTarget{GroupVersionKind: v1alpha1.GroupVersionKind{Version: "v1", Kind: "Pod"}, Name: "web", Namespaces: []string{"team-a"}}
+	_, err := h.listTarget(context.Background(), target, "", "")
+	require.NoError(t, err)
+	_, err = h.listTarget(context.Background(), target, "", "status.phase=Running")
+	require.NoError(t, err)
+	require.Equal(t, []string{"metadata.name=web", "metadata.name=web,status.phase=Running"}, fieldSelectors)
+}
+
+func TestMetricHandler_listTarget_clientSideFieldSelector(t *testing.T) {
+	pod := func(name, phase string) runtime.Object {
+		obj := fakePod("team-a", name)
+		obj.Object["status"] = map[string]interface{}{"phase": phase}
+		return obj
+	}
+	objects := []runtime.Object{pod("web", "Running"), pod("db", "Running"), pod("job", "Pending")}
+
+	tests := []struct {
+		name          string
+		listErr       error
+		fieldSelector string
+		wantNames     []string
+		wantErr       bool
+	}{
+		{
+			name:          "unsupported field filtered by the operator",
+			listErr:       apierrors.NewBadRequest(`field label not supported: status.phase`),
+			fieldSelector: "status.phase=Running",
+			wantNames:     []string{"db", "web"},
+		},
+		{
+			name:          "unsupported field combined with the target name",
+			listErr:       apierrors.NewBadRequest(`field label not supported: status.phase`),
+			fieldSelector: "status.phase!=Pending,metadata.name=web",
+			wantNames:     []string{"web"},
+		},
+		{
+			name:          "other errors are returned",
+			listErr:       apierrors.NewForbidden(schema.GroupResource{Resource: "pods"}, "", nil),
+			fieldSelector: "status.phase=Running",
+			wantErr:       true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newFakeMetricHandler(v1alpha1.Metric{}, objects...)
+			// the fake client ignores field selectors, the server rejects them instead
+			h.dCli.(*dynamicfake.FakeDynamicClient).PrependReactor("list", "pods", func(action clienttesting.Action) (bool, runtime.Object, error) {
+				if action.(clienttesting.ListAction).GetListRestrictions().Fields.Empty() {
+					return false, nil, nil
+				}
+				return true, nil, tt.listErr
+			})
+
+			target := v1alpha1.MetricTarget{GroupVersionKind: v1alpha1.GroupVersionKind{Version: "v1", Kind: "Pod"}, Namespaces: []string{"team-a"}}
+			list, err := h.listTarget(context.Background(), target, "", tt.fieldSelector)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			var names []string
+			for _, item := range list.Items {
+				names = append(names, item.GetName())
+			}
+			require.ElementsMatch(t, tt.wantNames, names)
+		})
+	}
+}
+
+func newFakeMetricHandler(metric v1alpha1.Metric, objects ...runtime.Object) *MetricHandler {
+	dCli := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		{Version: "v1", Resource: "pods"}: "PodList",
+		namespaceGVR:                      "NamespaceList",
+	}, objects...)
+	disco := &fakediscovery.FakeDiscovery{Fake: &clienttesting.Fake{Resources: []*metav1.APIResourceList{{
+		GroupVersion: "v1",
+		APIResources: []metav1.APIResource{{Name: "pods", Kind: "Pod", Namespaced: true}, {Name: "namespaces", Kind: "Namespace"}},
+	}}}}
+
+	return &MetricHandler{
+		dCli:        dCli,
+		discoClient: disco,
+		metric:      metric,
+	}
+}
+
+func fakeNamespace(name string, labels map[string]string) *unstructured.Unstructured {
+	ns := &unstructured.Unstructured{}
+	ns.SetAPIVersion("v1")
+	ns.SetKind("Namespace")
+	ns.SetName(name)
+	ns.SetLabels(labels)
+	return ns
+}
+
+func fakePod(namespace, name string) *unstructured.Unstructured {
+	pod := &unstructured.Unstructured{}
+	pod.SetAPIVersion("v1")
+	pod.SetKind("Pod")
+	pod.SetNamespace(namespace)
+	pod.SetName(name)
+	return pod
+}
+
+func TestMetricHandler_Monitor_groupByNamespace(t *testing.T) {
+	podGVK := v1alpha1.GroupVersionKind{Version: "v1", Kind: "Pod"}
+	objects := []runtime.Object{
+		fakePod("team-a", "pod-a1"),
+		fakePod("team-a", "pod-a2"),
+		fakePod("team-b", "pod-b1"),
+	}
+
+	tests := []struct {
+		name        string
+		projections []v1alpha1.Projection
+		want        map[string]int64
+	}{
+		{
+			name: "one data point per namespace",
+			want: map[string]int64{"team-a": 2, "team-b": 1},
+		},
+		{
+			name:        "combined with projections",
+			projections: []v1alpha1.Projection{{Name: "kind", FieldPath: "kind", Type: v1alpha1.TypePrimitive}},
+			want:        map[string]int64{"team-a/Pod": 2, "team-b/Pod": 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			metric := v1alpha1.Metric{Spec: v1alpha1.MetricSpec{
+				Target:           v1alpha1.MetricTarget{GroupVersionKind: podGVK},
+				Projections:      tt.projections,
+				GroupByNamespace: true,
+			}}
+			h := newFakeMetricHandler(metric, objects...)
+
+			metricClient, err := clientoptl.NewMetricClient(ctx, nil)
+			require.NoError(t, err)
+			metricClient.SetMeter("metric", nil)
+			h.gaugeMetric, err = metricClient.NewMetric("test", "", "")
+			require.NoError(t, err)
+
+			recorded := map[string]int64{}
+			h.gaugeMetric.SetPrometheusFunc(func(dims map[string]string, value int64) {
+				key := dims[NAMESPACE]
+				if kind, ok := dims["kind"]; ok {
+					key += "/" + kind
+				}
+				recorded[key] = value
+			})
+
+			result, err := h.Monitor(ctx)
+			require.NoError(t, err)
+			require.NoError(t, result.Error)
+			require.Equal(t, v1alpha1.ReasonMonitoringActive, result.Reason)
+			require.Equal(t, tt.want, recorded)
+
+			// the observation breaks the total count down by the exported series
+			observation := result.Observation.(*v1alpha1.MetricObservation)
+			require.Equal(t, "3", observation.LatestValue)
+			require.Len(t, observation.Series, len(tt.want))
+			require.Equal(t, "team-a", observation.Series[0].Dimensions[NAMESPACE])
+			require.Equal(t, int64(2), observation.Series[0].Value)
+		})
+	}
+}
+
+func TestMetricHandler_Monitor_enrichments(t *testing.T) {
+	podGVK := v1alpha1.GroupVersionKind{Version: "v1", Kind: "Pod"}
+	objects := []runtime.Object{
+		fakeNamespace("team-a", map[string]string{"team": "a"}),
+		fakeNamespace("team-b", map[string]string{"team": "b"}),
+		fakeNamespace("other", nil),
+		fakePod("team-a", "pod-a1"),
+		fakePod("team-a", "pod-a2"),
+		fakePod("team-b", "pod-b1"),
+		fakePod("other", "pod-o1"),
+		fakePod("deleted", "pod-d1"),
+	}
+
+	ctx := context.Background()
+	metric := v1alpha1.Metric{Spec: v1alpha1.MetricSpec{
+		Target: v1alpha1.MetricTarget{GroupVersionKind: podGVK},
+		Enrichments: []v1alpha1.Enrichment{{
+			Target:   v1alpha1.GroupVersionKind{Version: "v1", Kind: "Namespace"},
+			NameFrom: "metadata.namespace",
+			Projections: []v1alpha1.Projection{
+				{Name: "team", FieldPath: "metadata.labels.team", Type: v1alpha1.TypePrimitive, Default: v1alpha1.NewProjectionDefaultValue("unknown")},
+			},
+		}},
+	}}
+	h := newFakeMetricHandler(metric, objects...)
+
+	metricClient, err := clientoptl.NewMetricClient(ctx, nil)
+	require.NoError(t, err)
+	metricClient.SetMeter("metric", nil)
+	h.gaugeMetric, err = metricClient.NewMetric("test", "", "")
+	require.NoError(t, err)
+
+	recorded := map[string]int64{}
+	h.gaugeMetric.SetPrometheusFunc(func(dims map[string]string, value int64) {
+		recorded[dims["team"]] = value
+	})
+
+	result, err := h.Monitor(ctx)
+	require.NoError(t, err)
+	require.NoError(t, result.Error)
+	require.Equal(t, v1alpha1.ReasonMonitoringActive, result.Reason)
+	require.Equal(t, map[string]int64{"a": 2, "b": 1, "unknown": 2}, recorded)
+}
+
+func TestMetricHandler_getResources_exclude(t *testing.T) {
+	debugPod := fakePod("team-a", "pod-debug")
+	debugPod.SetLabels(map[string]string{"app": "debug"})
+	objects := []runtime.Object{
+		fakePod("team-a", "pod-a1"),
+		debugPod,
+		fakePod("kube-system", "pod-k1"),
+	}
+
+	tests := []struct {
+		name                 string
+		excludeLabelSelector string
+		excludeFieldSelector string
+		wantPods             []string
+		wantErr              bool
+	}{
+		{
+			name:     "nothing excluded",
+			wantPods: []string{"pod-a1", "pod-debug", "pod-k1"},
+		},
+		{
+			name:                 "excluded by label",
+			excludeLabelSelector: "app in (debug,test)",
+			wantPods:             []string{"pod-a1", "pod-k1"},
+		},
+		{
+			name:                 "excluded by field",
+			excludeFieldSelector: "metadata.namespace=kube-system",
+			wantPods:             []string{"pod-a1", "pod-debug"},
+		},
+		{
+			name:                 "excluded by label or field",
+			excludeLabelSelector: "app=debug",
+			excludeFieldSelector: "metadata.namespace=kube-system",
+			wantPods:             []string{"pod-a1"},
+		},
+		{
+			name:                 "invalid selector",
+			excludeLabelSelector: "app in (",
+			wantErr:              true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newFakeMetricHandler(v1alpha1.Metric{Spec: v1alpha1.MetricSpec{
+				Target:               v1alpha1.MetricTarget{GroupVersionKind: v1alpha1.GroupVersionKind{Version: "v1", Kind: "Pod"}},
+				ExcludeLabelSelector: tt.excludeLabelSelector,
+				ExcludeFieldSelector: tt.excludeFieldSelector,
+			}}, objects...)
+
+			list, err := h.getResources(context.Background())
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			names := make([]string, 0, len(list.Items))
+			for _, item := range list.Items {
+				names = append(names, item.GetName())
+			}
+			require.ElementsMatch(t, tt.wantPods, names)
+		})
+	}
+}
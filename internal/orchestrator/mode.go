@@ -0,0 +1,139 @@
+package orchestrator
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/openmcp-project/metrics-operator/api/v1alpha1"
+	"github.com/openmcp-project/metrics-operator/internal/clientoptl"
+)
+
+// ReasonTooManySeries is the reason of observations with more series than the status of the metric can remember
+const ReasonTooManySeries = "TooManySeries"
+
+// TooManySeriesError reports an observation with more series than the status of the metric can remember
+type TooManySeriesError struct {
+	// Field is the status field the series would be remembered in
+	Field  string
+	Series int
+	Limit  int
+}
+
+func (e *TooManySeriesError) Error() string {
+	return fmt.Sprintf("the observation has %d series, more than the %d series that can be remembered in %s", e.Series, e.Limit, e.Field)
+}
+
+// recordFailureReason returns the reason of a failure to record the data points of an observation
+func recordFailureReason(err error) string {
+	if errors.As(err, new(*TooManySeriesError)) {
+		return ReasonTooManySeries
+	}
+	return "RecordMetricFailed"
+}
+
+// applyMode converts the absolute values of the data points into the values exported for the mode of the spec.
+// It returns the converted data points and the baseline the next observation is computed against,
+// which is nil for the Absolute mode. A partial observation, e.g. one that timed out, keeps the baseline
+// of the data points it did not reach, instead of reporting them as disappeared.
+func applyMode(spec *v1alpha1.MetricSpec, baseline *v1alpha1.MetricBaseline, dataPoints []*clientoptl.DataPoint, partial bool, now time.Time) ([]*clientoptl.DataPoint, *v1alpha1.MetricBaseline, error) {
+	if spec.Mode != v1alpha1.MetricModeDelta && spec.Mode != v1alpha1.MetricModeRate {
+		return dataPoints, nil, nil
+	}
+
+	next := &v1alpha1.MetricBaseline{
+		Timestamp:  metav1.NewTime(now),
+		Values:     make(map[string]int64, len(dataPoints)),
+		Dimensions: make(map[string]v1alpha1.DataPointDimensions, len(dataPoints)),
+	}
+	for _, dp := range dataPoints {
+		key := dimensionsKey(dp.Dimensions)
+		next.Values[key] = dp.Value
+		// an empty map rather than nil, the status schema does not accept null values
+		dimensions := make(v1alpha1.DataPointDimensions, len(dp.Dimensions))
+		maps.Copy(dimensions, dp.Dimensions)
+		next.Dimensions[key] = dimensions
+	}
+
+	if partial && baseline != nil {
+		for key, value := range baseline.Values {
+			if _, observed := next.Values[key]; !observed {
+				next.Values[key] = value
+				if dimensions, ok := baseline.Dimensions[key]; ok {
+					next.Dimensions[key] = dimensions
+				}
+			}
+		}
+	}
+	if len(next.Values) > v1alpha1.MaxBaselineSeries {
+		return nil, nil, &TooManySeriesError{Field: "status.baseline", Series: len(next.Values), Limit: v1alpha1.MaxBaselineSeries}
+	}
+
+	// the first observation only establishes the baseline
+	if baseline == nil || baseline.Timestamp.IsZero() {
+		return nil, next, nil
+	}
+	elapsed := now.Sub(baseline.Timestamp.Time).Seconds()
+	if spec.Mode == v1alpha1.MetricModeRate && elapsed <= 0 {
+		return nil, next, nil
+	}
+	convert := func(delta int64) int64 {
+		if spec.Mode != v1alpha1.MetricModeRate {
+			return delta
+		}
+		return int64(math.Round(float64(delta) * rateUnit(spec.RateUnit).Seconds() / elapsed))
+	}
+
+	converted := make([]*clientoptl.DataPoint, 0, len(dataPoints))
+	for _, dp := range dataPoints {
+		// data points without a previous value, e.g. a new projection group, start from zero
+		delta := dp.Value - baseline.Values[dimensionsKey(dp.Dimensions)]
+		converted = append(converted, &clientoptl.DataPoint{Dimensions: dp.Dimensions, Value: convert(delta)})
+	}
+	if partial {
+		return converted, next, nil
+	}
+	// data points that disappeared drop to zero, baselines recorded without dimensions can't report them
+	for _, key := range slices.Sorted(maps.Keys(baseline.Values)) {
+		dimensions, ok := baseline.Dimensions[key]
+		if _, observed := next.Values[key]; observed || !ok {
+			continue
+		}
+		converted = append(converted, &clientoptl.DataPoint{Dimensions: dimensions, Value: convert(-baseline.Values[key])})
+	}
+	return converted, next, nil
+}
+
+// rateUnit returns the duration of the given rate unit, defaulting to a second
+func rateUnit(unit string) time.Duration {
+	switch unit {
+	case v1alpha1.RateUnitMinute:
+		return time.Minute
+	case v1alpha1.RateUnitHour:
+		return time.Hour
+	default:
+		return time.Second
+	}
+}
+
+// dimensionsKey returns a stable string representation of the dimensions of a data point
+func dimensionsKey(dimensions map[string]string) string {
+	keys := slices.Sorted(maps.Keys(dimensions))
+
+	var sb strings.Builder
+	for i, k := range keys {
+		if i > 0 {
+			sb.WriteString(",")
+		}
+		sb.WriteString(k)
+		sb.WriteString("=")
+		sb.WriteString(dimensions[k])
+	}
+	return sb.String()
+}
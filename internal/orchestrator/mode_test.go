@@ -0,0 +1,205 @@
+package orchestrator
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/openmcp-project/metrics-operator/api/v1alpha1"
+	"github.com/openmcp-project/metrics-operator/internal/clientoptl"
+)
+
+func TestApplyMode(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	dataPoints := func() []*clientoptl.DataPoint {
+		return []*clientoptl.DataPoint{
+			clientoptl.NewDataPoint().AddDimension("phase", "Running").SetValue(130),
+			clientoptl.NewDataPoint().AddDimension("phase", "Pending").SetValue(4),
+		}
+	}
+	baseline := &v1alpha1.MetricBaseline{
+		Timestamp: metav1.NewTime(now.Add(-time.Minute)),
+		Values:    map[string]int64{"phase=Running": 10},
+	}
+
+	tests := []struct {
+		name         string
+		mode         string
+		baseline     *v1alpha1.MetricBaseline
+		wantValues   []int64
+		wantBaseline bool
+	}{
+		{name: "default", wantValues: []int64{130, 4}},
+		{name: "absolute", mode: v1alpha1.MetricModeAbsolute, baseline: baseline, wantValues: []int64{130, 4}},
+		{name: "delta first observation", mode: v1alpha1.MetricModeDelta, wantValues: []int64{}, wantBaseline: true},
+		{name: "delta", mode: v1alpha1.MetricModeDelta, baseline: baseline, wantValues: []int64{120, 4}, wantBaseline: true},
+		{name: "rate first observation", mode: v1alpha1.MetricModeRate, wantValues: []int64{}, wantBaseline: true},
+		{name: "rate", mode: v1alpha1.MetricModeRate, baseline: baseline, wantValues: []int64{2, 0}, wantBaseline: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := dataPoints()
+			got, next, err := applyMode(&v1alpha1.MetricSpec{Mode: tt.mode}, tt.baseline, input, false, now)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(got) != len(tt.wantValues) {
+				t.Fatalf("unexpected number of data points: wanted=%v, got=%v", len(tt.wantValues), len(got))
+			}
+			for i, dp := range got {
+				if dp.Value != tt.wantValues[i] {
+					t.Errorf("unexpected value of data point %d: wanted=%v, got=%v", i, tt.wantValues[i], dp.Value)
+				}
+			}
+			// the recorded data points must not be modified, they may be recorded again
+			if input[0].Value != 130 || input[1].Value != 4 {
+				t.Errorf("input data points were modified")
+			}
+
+			if !tt.wantBaseline {
+				if next != nil {
+					t.Errorf("unexpected baseline: %v", next)
+				}
+				return
+			}
+			if next == nil {
+				t.Fatal("expected a baseline")
+			}
+			if !next.Timestamp.Time.Equal(now) {
+				t.Errorf("unexpected baseline timestamp: %v", next.Timestamp)
+			}
+			if next.Values["phase=Running"] != 130 || next.Values["phase=Pending"] != 4 {
+				t.Errorf("unexpected baseline values: %v", next.Values)
+			}
+		})
+	}
+}
+
+func TestApplyMode_rateUnit(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	baseline := &v1alpha1.MetricBaseline{
+		Timestamp: metav1.NewTime(now.Add(-10 * time.Minute)),
+		Values:    map[string]int64{"phase=Running": 10},
+	}
+
+	tests := []struct {
+		unit string
+		want int64
+	}{
+		// 3 new values in 10 minutes round to 0 per second
+		{unit: "", want: 0},
+		{unit: v1alpha1.RateUnitSecond, want: 0},
+		{unit: v1alpha1.RateUnitMinute, want: 0},
+		{unit: v1alpha1.RateUnitHour, want: 18},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.unit, func(t *testing.T) {
+			spec := &v1alpha1.MetricSpec{Mode: v1alpha1.MetricModeRate, RateUnit: tt.unit}
+			got, _, _ := applyMode(spec, baseline, []*clientoptl.DataPoint{
+				clientoptl.NewDataPoint().AddDimension("phase", "Running").SetValue(13),
+			}, false, now)
+			if len(got) != 1 || got[0].Value != tt.want {
+				t.Errorf("unexpected data points: wanted value %v, got=%v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestApplyMode_disappearedDataPoints(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	baseline := &v1alpha1.MetricBaseline{
+		Timestamp: metav1.NewTime(now.Add(-time.Minute)),
+		Values:    map[string]int64{"phase=Running": 10, "phase=Pending": 4, "phase=Failed": 2},
+		// baselines recorded before the dimensions were stored lack some of them
+		Dimensions: map[string]v1alpha1.DataPointDimensions{
+			"phase=Running": {"phase": "Running"},
+			"phase=Pending": {"phase": "Pending"},
+		},
+	}
+	input := []*clientoptl.DataPoint{clientoptl.NewDataPoint().AddDimension("phase", "Running").SetValue(70)}
+
+	tests := []struct {
+		mode string
+		unit string
+		want []int64
+	}{
+		{mode: v1alpha1.MetricModeDelta, want: []int64{60, -4}},
+		{mode: v1alpha1.MetricModeRate, unit: v1alpha1.RateUnitMinute, want: []int64{60, -4}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			got, next, _ := applyMode(&v1alpha1.MetricSpec{Mode: tt.mode, RateUnit: tt.unit}, baseline, input, false, now)
+			if len(got) != len(tt.want) {
+				t.Fatalf("unexpected number of data points: wanted=%v, got=%v", len(tt.want), len(got))
+			}
+			for i, dp := range got {
+				if dp.Value != tt.want[i] {
+					t.Errorf("unexpected value of data point %d: wanted=%v, got=%v", i, tt.want[i], dp.Value)
+				}
+			}
+			if phase := got[1].Dimensions["phase"]; phase != "Pending" {
+				t.Errorf("unexpected dimensions of the disappeared data point: %v", got[1].Dimensions)
+			}
+			// the disappeared data points are reported once
+			if len(next.Values) != 1 || len(next.Dimensions) != 1 {
+				t.Errorf("unexpected baseline: %v", next)
+			}
+		})
+	}
+}
+
+func TestApplyMode_partial(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	baseline := &v1alpha1.MetricBaseline{
+		Timestamp:  metav1.NewTime(now.Add(-time.Minute)),
+		Values:     map[string]int64{"phase=Running": 10, "phase=Pending": 4},
+		Dimensions: map[string]v1alpha1.DataPointDimensions{"phase=Running": {"phase": "Running"}, "phase=Pending": {"phase": "Pending"}},
+	}
+	input := []*clientoptl.DataPoint{clientoptl.NewDataPoint().AddDimension("phase", "Running").SetValue(70)}
+
+	got, next, err := applyMode(&v1alpha1.MetricSpec{Mode: v1alpha1.MetricModeDelta}, baseline, input, true, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the data points the observation did not reach are not reported as disappeared, but kept in the baseline
+	if len(got) != 1 || got[0].Value != 60 {
+		t.Errorf("unexpected data points: %v", got)
+	}
+	if next.Values["phase=Running"] != 70 || next.Values["phase=Pending"] != 4 || next.Dimensions["phase=Pending"]["phase"] != "Pending" {
+		t.Errorf("unexpected baseline: %v", next)
+	}
+}
+
+func TestApplyMode_tooManySeries(t *testing.T) {
+	dataPoints := make([]*clientoptl.DataPoint, 0, v1alpha1.MaxBaselineSeries+1)
+	for i := range v1alpha1.MaxBaselineSeries + 1 {
+		dataPoints = append(dataPoints, clientoptl.NewDataPoint().AddDimension("name", fmt.Sprintf("pod-%d", i)).SetValue(1))
+	}
+
+	_, next, err := applyMode(&v1alpha1.MetricSpec{Mode: v1alpha1.MetricModeDelta}, nil, dataPoints, false, time.Now())
+	var tooMany *TooManySeriesError
+	if !errors.As(err, &tooMany) || tooMany.Series != v1alpha1.MaxBaselineSeries+1 || next != nil {
+		t.Errorf("unexpected result: %v, %v", next, err)
+	}
+	if reason := recordFailureReason(fmt.Errorf("failed: %w", err)); reason != ReasonTooManySeries {
+		t.Errorf("unexpected reason: %v", reason)
+	}
+	// the Absolute mode does not remember the series
+	if _, _, err := applyMode(&v1alpha1.MetricSpec{}, nil, dataPoints, false, time.Now()); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestDimensionsKey(t *testing.T) {
+	got := dimensionsKey(map[string]string{"version": "v1", "kind": "Pod", "cluster": "a"})
+	if want := "cluster=a,kind=Pod,version=v1"; got != want {
+		t.Errorf("dimensionsKey() = %v, want %v", got, want)
+	}
+}
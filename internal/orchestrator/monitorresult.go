@@ -13,4 +13,27 @@ type MonitorResult struct {
 	Error   error
 
 	Observation extensions.Observation
+
+	// Baseline is the baseline for the next observation of metrics exported in Delta or Rate mode
+	Baseline *insight.MetricBaseline
+
+	// LastExport is the last export the next observation of metrics with an OnChange export policy is compared against
+	LastExport *insight.MetricLastExport
+
+	// SamplingWindow holds the samples of metrics with sampling taken since their last export
+	SamplingWindow *insight.MetricSamplingWindow
+	// SampleOnly is true if the observation was only added to the sampling window and nothing was recorded for export
+	SampleOnly bool
+
+	// RecordedSeries are the series of the observation, as requested with spec.debug.recordDimensions
+	RecordedSeries *insight.RecordedSeries
+
+	// Snapshot holds the series of the observation, as requested with spec.snapshot
+	Snapshot *insight.MetricSnapshotSpec
+
+	// TimedOut lists the collection phases that timed out, the result only covers the data collected until then
+	TimedOut []string
+
+	// Samples are names of matched resources, as many as requested with spec.debug.emitSamples
+	Samples []string
 }
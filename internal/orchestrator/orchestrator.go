@@ -2,7 +2,10 @@ package orchestrator
 
 import (
 	"context"
+	"time"
 
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/rest"
 	rcli "sigs.k8s.io/controller-runtime/pkg/client"
 
@@ -24,11 +27,17 @@ const (
 	// CLUSTER Constant for k8s resource fields
 	CLUSTER string = "cluster"
 
+	// NAMESPACE Constant for the dimension of metrics grouped by namespace
+	NAMESPACE string = "namespace"
+
 	// RESOURCE Constant for k8s resource fields
 	RESOURCE string = "resource"
 
 	// APIVERSION Constant for k8s resource fields
 	APIVERSION string = "apiVersion"
+
+	// STATISTIC Constant for the dimension of aggregated values
+	STATISTIC string = "statistic"
 )
 
 // GenericHandler is used to monitor the metric
@@ -36,6 +45,10 @@ type GenericHandler interface {
 	Monitor(ctx context.Context) (MonitorResult, error)
 }
 
+// HandlerFactory creates the handler monitoring a Metric, ManagedMetric, FederatedMetric or FederatedManagedMetric
+// on the cluster of the query config. Tests inject it to monitor without querying a cluster.
+type HandlerFactory func(metric rcli.Object, qConfig QueryConfig, gaugeMetric *clientoptl.Metric) (GenericHandler, error)
+
 // Orchestrator is used to create a new handler
 type Orchestrator struct {
 	Handler GenericHandler
@@ -43,6 +56,8 @@ type Orchestrator struct {
 	credentials common.DataSinkCredentials
 
 	queryConfig QueryConfig
+
+	handlers HandlerFactory
 }
 
 // QueryConfig holds the configuration for the query client to query resources in a K8S cluster, may be internal or external cluster.
@@ -50,6 +65,21 @@ type QueryConfig struct {
 	Client      rcli.Client
 	RestConfig  rest.Config
 	ClusterName *string
+	// ClusterLabels are added as dimensions to every data point of the cluster
+	ClusterLabels map[string]string
+	// DynamicClient and DiscoveryClient are created from the RestConfig if they are not set
+	DynamicClient   dynamic.Interface
+	DiscoveryClient discovery.DiscoveryInterface
+	// Identity distinguishes credentials that are not part of the RestConfig, e.g. of a wrapped transport
+	Identity string
+	// CredentialsExpiry is the time the credentials of a kubeconfig or token stored for the cluster expire,
+	// zero if they do not expire or are requested by the operator itself and renewed before they expire
+	CredentialsExpiry time.Time
+}
+
+// credentialsKey identifies the cluster and the credentials it is queried with
+func (qc *QueryConfig) credentialsKey() string {
+	return credentialsKey(&qc.RestConfig, qc.Identity)
 }
 
 // NewOrchestrator creates a new Orchestrator
@@ -57,8 +87,24 @@ func NewOrchestrator(creds common.DataSinkCredentials, qConfig QueryConfig) *Orc
 	return &Orchestrator{credentials: creds, queryConfig: qConfig}
 }
 
+// WithHandlers makes the orchestrator create its handlers with the factory, a nil factory creates the handlers of this package
+func (o *Orchestrator) WithHandlers(handlers HandlerFactory) *Orchestrator {
+	o.handlers = handlers
+	return o
+}
+
+// withInjected creates the handler with the injected factory
+func (o *Orchestrator) withInjected(metric rcli.Object, gaugeMetric *clientoptl.Metric) (*Orchestrator, error) {
+	var err error
+	o.Handler, err = o.handlers(metric, o.queryConfig, gaugeMetric)
+	return o, err
+}
+
 // WithManaged creates a new Orchestrator with a ManagedMetric handler
 func (o *Orchestrator) WithManaged(managed v1alpha1.ManagedMetric, gaugeMetric *clientoptl.Metric) (*Orchestrator, error) {
+	if o.handlers != nil {
+		return o.withInjected(&managed, gaugeMetric)
+	}
 	var err error
 	o.Handler, err = NewManagedHandler(managed, o.queryConfig, gaugeMetric)
 	return o, err
@@ -66,6 +112,9 @@ func (o *Orchestrator) WithManaged(managed v1alpha1.ManagedMetric, gaugeMetric *
 
 // WithMetric creates a new Orchestrator with a Metric handler
 func (o *Orchestrator) WithMetric(metric v1alpha1.Metric, gaugeMetric *clientoptl.Metric) (*Orchestrator, error) { // Added gaugeMetric parameter
+	if o.handlers != nil {
+		return o.withInjected(&metric, gaugeMetric)
+	}
 	// dtClient creation removed, as it's handled by the controller
 
 	var err error
@@ -76,6 +125,9 @@ func (o *Orchestrator) WithMetric(metric v1alpha1.Metric, gaugeMetric *clientopt
 
 // WithFederated creates a new Orchestrator with a FederatedMetric handler
 func (o *Orchestrator) WithFederated(metric v1alpha1.FederatedMetric, gaugeMetric *clientoptl.Metric) (*Orchestrator, error) {
+	if o.handlers != nil {
+		return o.withInjected(&metric, gaugeMetric)
+	}
 	var err error
 	o.Handler, err = NewFederatedHandler(metric, o.queryConfig, gaugeMetric)
 	return o, err
@@ -83,7 +135,22 @@ func (o *Orchestrator) WithFederated(metric v1alpha1.FederatedMetric, gaugeMetri
 
 // WithFederatedManaged creates a new Orchestrator with a FederatedManagedMetric handler
 func (o *Orchestrator) WithFederatedManaged(metric v1alpha1.FederatedManagedMetric, gaugeMetric *clientoptl.Metric) (*Orchestrator, error) {
+	if o.handlers != nil {
+		return o.withInjected(&metric, gaugeMetric)
+	}
 	var err error
 	o.Handler, err = NewFederatedManagedHandler(metric, o.queryConfig, gaugeMetric)
 	return o, err
 }
+
+// addClusterLabels adds the labels of the cluster as dimensions to the data points,
+// dimensions that are already set are not overridden
+func addClusterLabels(labels map[string]string, dataPoints ...*clientoptl.DataPoint) {
+	for _, dp := range dataPoints {
+		for key, value := range labels {
+			if _, exists := dp.Dimensions[key]; !exists {
+				dp.AddDimension(key, value)
+			}
+		}
+	}
+}
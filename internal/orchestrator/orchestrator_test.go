@@ -0,0 +1,61 @@
+package orchestrator
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/openmcp-project/metrics-operator/api/v1alpha1"
+	"github.com/openmcp-project/metrics-operator/internal/clientoptl"
+	"github.com/openmcp-project/metrics-operator/internal/common"
+)
+
+func TestAddClusterLabels(t *testing.T) {
+	dp := clientoptl.NewDataPoint().
+		AddDimension(CLUSTER, "prod-eu").
+		AddDimension("region", "from-metric")
+
+	addClusterLabels(map[string]string{"region": "eu-west-1", "landscape": "live"}, dp)
+
+	want := map[string]string{CLUSTER: "prod-eu", "region": "from-metric", "landscape": "live"}
+	if !reflect.DeepEqual(dp.Dimensions, want) {
+		t.Errorf("unexpected dimensions: wanted=%v, got=%v", want, dp.Dimensions)
+	}
+}
+
+func TestOrchestrator_WithHandlers(t *testing.T) {
+	handler := NewFakeHandler(MonitorResult{Phase: v1alpha1.PhaseActive})
+	meta := metav1.ObjectMeta{Name: "injected"}
+
+	create := map[string]func(o *Orchestrator) (*Orchestrator, error){
+		"Metric": func(o *Orchestrator) (*Orchestrator, error) {
+			return o.WithMetric(v1alpha1.Metric{ObjectMeta: meta}, nil)
+		},
+		"ManagedMetric": func(o *Orchestrator) (*Orchestrator, error) {
+			return o.WithManaged(v1alpha1.ManagedMetric{ObjectMeta: meta}, nil)
+		},
+		"FederatedMetric": func(o *Orchestrator) (*Orchestrator, error) {
+			return o.WithFederated(v1alpha1.FederatedMetric{ObjectMeta: meta}, nil)
+		},
+		"FederatedManagedMetric": func(o *Orchestrator) (*Orchestrator, error) {
+			return o.WithFederatedManaged(v1alpha1.FederatedManagedMetric{ObjectMeta: meta}, nil)
+		},
+	}
+	for kind, with := range create {
+		o, err := with(NewOrchestrator(common.DataSinkCredentials{}, QueryConfig{}).WithHandlers(handler.Factory()))
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", kind, err)
+		}
+		result, err := o.Handler.Monitor(context.Background())
+		if err != nil || result.Phase != v1alpha1.PhaseActive {
+			t.Errorf("%s: unexpected result of the injected handler: %v, %v", kind, result, err)
+		}
+	}
+
+	want := []string{"injected", "injected", "injected", "injected"}
+	if got := handler.Monitors(); !reflect.DeepEqual(got, want) {
+		t.Errorf("unexpected monitors: wanted=%v, got=%v", want, got)
+	}
+}
@@ -0,0 +1,226 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/openmcp-project/metrics-operator/api/v1alpha1"
+	"github.com/openmcp-project/metrics-operator/internal/clientoptl"
+)
+
+// Dimensions of the data points of the presets
+const (
+	// PresetDimensionResourceName is the name of the resource quantity, e.g. "cpu" or "requests.memory"
+	PresetDimensionResourceName = "resourceName"
+	// PresetDimensionResourceQuota is the name of the ResourceQuota
+	PresetDimensionResourceQuota = "resourceQuota"
+	// PresetDimensionRole are the roles of the Nodes, taken from their node-role.kubernetes.io/<role> labels
+	PresetDimensionRole = "role"
+	// PresetDimensionType is "allocatable" or "capacity"
+	PresetDimensionType = "type"
+	// PresetDimensionStorageClass is the storage class of the PersistentVolumeClaims
+	PresetDimensionStorageClass = "storageClass"
+)
+
+// nodeRoleLabelPrefix is the prefix of the labels holding the roles of a Node
+const nodeRoleLabelPrefix = "node-role.kubernetes.io/"
+
+// PresetKind returns the kind a preset is computed from, the presets only read core resources of version v1
+func PresetKind(preset string) (string, bool) {
+	switch preset {
+	case v1alpha1.PresetResourceQuotaUsage:
+		return "ResourceQuota", true
+	case v1alpha1.PresetNodeCapacity:
+		return "Node", true
+	case v1alpha1.PresetPVCRequestedBytes:
+		return "PersistentVolumeClaim", true
+	}
+	return "", false
+}
+
+// presetMonitor records the data points of the metric's preset, computed from the matched resources
+func (h *MetricHandler) presetMonitor(ctx context.Context, list *unstructured.UnstructuredList) (MonitorResult, error) {
+	result := MonitorResult{Observation: &v1alpha1.MetricObservation{Timestamp: metav1.Now()}}
+
+	preset := h.metric.Spec.Preset
+	target := h.metric.Spec.Target
+	if kind, ok := PresetKind(preset); !ok || !strings.EqualFold(target.Kind, kind) || target.Group != "" {
+		result.Error = fmt.Errorf("preset '%s' cannot be computed from kind %s", preset, target.GVK().GroupKind())
+		result.Phase = v1alpha1.PhaseFailed
+		result.Reason = "InvalidPreset"
+		result.Message = result.Error.Error()
+		return result, nil
+	}
+
+	dataPoints, err := presetDataPoints(preset, list.Items)
+	if err != nil {
+		result.Error = err
+		result.Phase = v1alpha1.PhaseFailed
+		result.Reason = "PresetFailed"
+		result.Message = fmt.Sprintf("failed to compute preset '%s': %s", preset, err.Error())
+		return result, nil
+	}
+	for _, dataPoint := range dataPoints {
+		h.setDataPointBaseDimensions(dataPoint)
+	}
+
+	if len(dataPoints) > 0 {
+		if err := h.recordMetrics(ctx, dataPoints...); err != nil {
+			result.Error = err
+			result.Phase = v1alpha1.PhaseFailed
+			result.Reason = recordFailureReason(err)
+			result.Message = fmt.Sprintf("failed to record metric value(s): %s", err.Error())
+			return result, nil
+		}
+	}
+
+	result.Observation = &v1alpha1.MetricObservation{Timestamp: metav1.Now(), LatestValue: strconv.Itoa(len(dataPoints))}
+	result.Phase = v1alpha1.PhaseActive
+	result.Reason = v1alpha1.ReasonMonitoringActive
+	result.Message = fmt.Sprintf("%d value(s) of preset '%s' recorded for resource '%s'", len(dataPoints), preset, h.metric.GvkToString())
+	return result, nil
+}
+
+// presetDataPoints computes the data points of a preset from the resources of its kind
+func presetDataPoints(preset string, items []unstructured.Unstructured) ([]*clientoptl.DataPoint, error) {
+	switch preset {
+	case v1alpha1.PresetResourceQuotaUsage:
+		return resourceQuotaUsage(items)
+	case v1alpha1.PresetNodeCapacity:
+		return nodeCapacity(items)
+	case v1alpha1.PresetPVCRequestedBytes:
+		return pvcRequestedBytes(items)
+	}
+	return nil, fmt.Errorf("unknown preset '%s'", preset)
+}
+
+// resourceQuotaUsage exports the used share of each hard limit of the ResourceQuotas in percent,
+// limits of zero are skipped
+func resourceQuotaUsage(items []unstructured.Unstructured) ([]*clientoptl.DataPoint, error) {
+	var dataPoints []*clientoptl.DataPoint
+	for _, item := range items {
+		quota := &corev1.ResourceQuota{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(item.UnstructuredContent(), quota); err != nil {
+			return nil, fmt.Errorf("failed to read ResourceQuota %s: %w", objectName(item.GetNamespace(), item.GetName()), err)
+		}
+		for _, name := range slices.Sorted(maps.Keys(quota.Status.Hard)) {
+			hard := quota.Status.Hard[name]
+			if hard.IsZero() {
+				continue
+			}
+			used := quota.Status.Used[name]
+			percent := math.Round(100 * used.AsApproximateFloat64() / hard.AsApproximateFloat64())
+			dataPoints = append(dataPoints, clientoptl.NewDataPoint().
+				AddDimension(NAMESPACE, quota.Namespace).
+				AddDimension(PresetDimensionResourceQuota, quota.Name).
+				AddDimension(PresetDimensionResourceName, string(name)).
+				SetValue(int64(percent)))
+		}
+	}
+	return dataPoints, nil
+}
+
+// nodeCapacity exports the allocatable and the capacity resources summed up by the roles of the Nodes,
+// cpu in millicores and the other resources in their base unit, e.g. bytes
+func nodeCapacity(items []unstructured.Unstructured) ([]*clientoptl.DataPoint, error) {
+	type key struct{ role, resourceName, valueType string }
+	sums := map[key]int64{}
+	for _, item := range items {
+		node := &corev1.Node{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(item.UnstructuredContent(), node); err != nil {
+			return nil, fmt.Errorf("failed to read Node %s: %w", item.GetName(), err)
+		}
+		role := nodeRoles(node.Labels)
+		for valueType, resources := range map[string]corev1.ResourceList{"allocatable": node.Status.Allocatable, "capacity": node.Status.Capacity} {
+			for name, quantity := range resources {
+				sums[key{role: role, resourceName: string(name), valueType: valueType}] += presetQuantityValue(name, quantity)
+			}
+		}
+	}
+
+	keys := make([]key, 0, len(sums))
+	for k := range sums {
+		keys = append(keys, k)
+	}
+	slices.SortFunc(keys, func(a, b key) int {
+		return strings.Compare(a.role+"/"+a.resourceName+"/"+a.valueType, b.role+"/"+b.resourceName+"/"+b.valueType)
+	})
+	dataPoints := make([]*clientoptl.DataPoint, 0, len(keys))
+	for _, k := range keys {
+		dataPoint := clientoptl.NewDataPoint().
+			AddDimension(PresetDimensionResourceName, k.resourceName).
+			AddDimension(PresetDimensionType, k.valueType).
+			SetValue(sums[k])
+		if k.role != "" {
+			dataPoint.AddDimension(PresetDimensionRole, k.role)
+		}
+		dataPoints = append(dataPoints, dataPoint)
+	}
+	return dataPoints, nil
+}
+
+// pvcRequestedBytes exports the storage requested by the PersistentVolumeClaims summed up by namespace and storage class
+func pvcRequestedBytes(items []unstructured.Unstructured) ([]*clientoptl.DataPoint, error) {
+	type key struct{ namespace, storageClass string }
+	sums := map[key]int64{}
+	for _, item := range items {
+		pvc := &corev1.PersistentVolumeClaim{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(item.UnstructuredContent(), pvc); err != nil {
+			return nil, fmt.Errorf("failed to read PersistentVolumeClaim %s: %w", objectName(item.GetNamespace(), item.GetName()), err)
+		}
+		k := key{namespace: pvc.Namespace}
+		if pvc.Spec.StorageClassName != nil {
+			k.storageClass = *pvc.Spec.StorageClassName
+		}
+		sums[k] += pvc.Spec.Resources.Requests.Storage().Value()
+	}
+
+	keys := make([]key, 0, len(sums))
+	for k := range sums {
+		keys = append(keys, k)
+	}
+	slices.SortFunc(keys, func(a, b key) int {
+		return strings.Compare(a.namespace+"/"+a.storageClass, b.namespace+"/"+b.storageClass)
+	})
+	dataPoints := make([]*clientoptl.DataPoint, 0, len(keys))
+	for _, k := range keys {
+		dataPoint := clientoptl.NewDataPoint().AddDimension(NAMESPACE, k.namespace).SetValue(sums[k])
+		if k.storageClass != "" {
+			dataPoint.AddDimension(PresetDimensionStorageClass, k.storageClass)
+		}
+		dataPoints = append(dataPoints, dataPoint)
+	}
+	return dataPoints, nil
+}
+
+// nodeRoles returns the sorted roles of the node-role.kubernetes.io/<role> labels joined by commas,
+// or an empty string if the Node has no role
+func nodeRoles(labels map[string]string) string {
+	var roles []string
+	for label := range labels {
+		if role, ok := strings.CutPrefix(label, nodeRoleLabelPrefix); ok && role != "" {
+			roles = append(roles, role)
+		}
+	}
+	slices.Sort(roles)
+	return strings.Join(roles, ",")
+}
+
+// presetQuantityValue returns cpu in millicores, and the other resources rounded up to their base unit
+func presetQuantityValue(name corev1.ResourceName, quantity resource.Quantity) int64 {
+	if name == corev1.ResourceCPU {
+		return quantity.MilliValue()
+	}
+	return quantity.Value()
+}
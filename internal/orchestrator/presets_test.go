@@ -0,0 +1,130 @@
+package orchestrator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/openmcp-project/metrics-operator/api/v1alpha1"
+	"github.com/openmcp-project/metrics-operator/internal/clientoptl"
+)
+
+func TestPresetDataPoints(t *testing.T) {
+	object := func(kind, namespace, name string, labels map[string]string, fields map[string]interface{}) unstructured.Unstructured {
+		obj := unstructured.Unstructured{Object: fields}
+		obj.SetAPIVersion("v1")
+		obj.SetKind(kind)
+		obj.SetNamespace(namespace)
+		obj.SetName(name)
+		obj.SetLabels(labels)
+		return obj
+	}
+	dataPoint := func(value int64, dimensions ...string) *clientoptl.DataPoint {
+		dp := clientoptl.NewDataPoint().SetValue(value)
+		for i := 0; i < len(dimensions); i += 2 {
+			dp.AddDimension(dimensions[i], dimensions[i+1])
+		}
+		return dp
+	}
+
+	tests := []struct {
+		name    string
+		preset  string
+		items   []unstructured.Unstructured
+		want    []*clientoptl.DataPoint
+		wantErr bool
+	}{
+		{
+			name:   "resource quota usage",
+			preset: v1alpha1.PresetResourceQuotaUsage,
+			items: []unstructured.Unstructured{
+				object("ResourceQuota", "team-a", "compute", nil, map[string]interface{}{"status": map[string]interface{}{
+					"hard": map[string]interface{}{"requests.cpu": "2", "requests.memory": "4Gi", "pods": "0"},
+					"used": map[string]interface{}{"requests.cpu": "500m", "requests.memory": "3Gi"},
+				}}),
+			},
+			want: []*clientoptl.DataPoint{
+				dataPoint(25, NAMESPACE, "team-a", PresetDimensionResourceQuota, "compute", PresetDimensionResourceName, "requests.cpu"),
+				dataPoint(75, NAMESPACE, "team-a", PresetDimensionResourceQuota, "compute", PresetDimensionResourceName, "requests.memory"),
+			},
+		},
+		{
+			name:   "node capacity by role",
+			preset: v1alpha1.PresetNodeCapacity,
+			items: []unstructured.Unstructured{
+				object("Node", "", "worker-1", map[string]string{"node-role.kubernetes.io/worker": ""}, map[string]interface{}{"status": map[string]interface{}{
+					"allocatable": map[string]interface{}{"cpu": "3500m"},
+					"capacity":    map[string]interface{}{"cpu": "4"},
+				}}),
+				object("Node", "", "worker-2", map[string]string{"node-role.kubernetes.io/worker": ""}, map[string]interface{}{"status": map[string]interface{}{
+					"allocatable": map[string]interface{}{"cpu": "3500m"},
+					"capacity":    map[string]interface{}{"cpu": "4"},
+				}}),
+				object("Node", "", "plain", nil, map[string]interface{}{"status": map[string]interface{}{
+					"capacity": map[string]interface{}{"memory": "1Ki"},
+				}}),
+			},
+			want: []*clientoptl.DataPoint{
+				dataPoint(1024, PresetDimensionResourceName, "memory", PresetDimensionType, "capacity"),
+				dataPoint(7000, PresetDimensionResourceName, "cpu", PresetDimensionType, "allocatable", PresetDimensionRole, "worker"),
+				dataPoint(8000, PresetDimensionResourceName, "cpu", PresetDimensionType, "capacity", PresetDimensionRole, "worker"),
+			},
+		},
+		{
+			name:   "pvc requested bytes",
+			preset: v1alpha1.PresetPVCRequestedBytes,
+			items: []unstructured.Unstructured{
+				object("PersistentVolumeClaim", "team-a", "data-1", nil, map[string]interface{}{"spec": map[string]interface{}{
+					"storageClassName": "fast",
+					"resources":        map[string]interface{}{"requests": map[string]interface{}{"storage": "1Gi"}},
+				}}),
+				object("PersistentVolumeClaim", "team-a", "data-2", nil, map[string]interface{}{"spec": map[string]interface{}{
+					"storageClassName": "fast",
+					"resources":        map[string]interface{}{"requests": map[string]interface{}{"storage": "1Gi"}},
+				}}),
+				object("PersistentVolumeClaim", "team-a", "scratch", nil, map[string]interface{}{"spec": map[string]interface{}{
+					"resources": map[string]interface{}{"requests": map[string]interface{}{"storage": "100Mi"}},
+				}}),
+			},
+			want: []*clientoptl.DataPoint{
+				dataPoint(100*1024*1024, NAMESPACE, "team-a"),
+				dataPoint(2*1024*1024*1024, NAMESPACE, "team-a", PresetDimensionStorageClass, "fast"),
+			},
+		},
+		{
+			name:   "invalid quantity",
+			preset: v1alpha1.PresetResourceQuotaUsage,
+			items: []unstructured.Unstructured{
+				object("ResourceQuota", "team-a", "compute", nil, map[string]interface{}{"status": map[string]interface{}{
+					"hard": map[string]interface{}{"pods": "many"},
+				}}),
+			},
+			wantErr: true,
+		},
+		{
+			name:    "unknown preset",
+			preset:  "Unknown",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dataPoints, err := presetDataPoints(tt.preset, tt.items)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, dataPoints)
+		})
+	}
+}
+
+func TestNodeRoles(t *testing.T) {
+	require.Equal(t, "", nodeRoles(map[string]string{"kubernetes.io/os": "linux"}))
+	require.Equal(t, "control-plane,worker", nodeRoles(map[string]string{
+		"node-role.kubernetes.io/worker":        "",
+		"node-role.kubernetes.io/control-plane": "",
+	}))
+}
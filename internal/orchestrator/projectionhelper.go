@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"regexp"
 	"strconv"
 	"strings"
 	"time"
@@ -13,10 +14,62 @@ import (
 
 	"github.com/openmcp-project/metrics-operator/api/v1alpha1"
 
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/client-go/util/jsonpath"
 )
 
+// projectionValue extracts the value of a projection from an unstructured Kubernetes object,
+// from its built-in source if set and otherwise from the field at its path, see nestedFieldValue.
+// Projections without a type are primitives, as defaulted by the API server.
+func projectionValue(obj unstructured.Unstructured, projection v1alpha1.Projection) (string, bool, error) {
+	if projection.Source == "" {
+		valueType := projection.Type
+		if valueType == "" {
+			valueType = v1alpha1.TypePrimitive
+		}
+		return nestedFieldValue(obj, projection.FieldPath, valueType, projection.Default)
+	}
+
+	var value string
+	var found bool
+	switch projection.Source {
+	case v1alpha1.ProjectionSourceOwnerKind:
+		value, _, found = controllerOwner(obj)
+	case v1alpha1.ProjectionSourceOwnerName:
+		_, value, found = controllerOwner(obj)
+	default:
+		return "", false, fmt.Errorf("unsupported projection source: %s", projection.Source)
+	}
+	if !found && projection.Default != nil {
+		defaultAsString, err := projection.Default.AsString(v1alpha1.TypePrimitive)
+		if err != nil {
+			return "", false, fmt.Errorf("failed to parse default value: %v", err)
+		}
+		return defaultAsString, true, nil
+	}
+	return value, found, nil
+}
+
+// controllerOwner returns the kind and name of the controller owner of the object.
+// Pods of a ReplicaSet created by a Deployment are attributed to the Deployment: the ReplicaSet is named
+// after the Deployment and the pod-template-hash label of its Pods, so the Deployment is derived without a lookup.
+func controllerOwner(obj unstructured.Unstructured) (string, string, bool) {
+	owner := metav1.GetControllerOf(&obj)
+	if owner == nil {
+		return "", "", false
+	}
+	if owner.Kind == "ReplicaSet" && obj.GetKind() == "Pod" {
+		if hash := obj.GetLabels()["pod-template-hash"]; hash != "" {
+			if deployment, ok := strings.CutSuffix(owner.Name, "-"+hash); ok && deployment != "" {
+				return "Deployment", deployment, true
+			}
+		}
+	}
+	return owner.Kind, owner.Name, true
+}
+
 // nestedFieldValue extracts a value from an unstructured Kubernetes object using JSONPath.
 //
 // Returns:
@@ -35,6 +88,7 @@ import (
 //
 // Path format:
 //   - Use dot-notation without brackets or leading dot (e.g., "metadata.name")
+//   - Address list items by a field with [field=value] (e.g., "status.conditions[type=Ready].status")
 //   - Use "." to export the entire object as JSON (requires TypeMap)
 func nestedFieldValue(obj unstructured.Unstructured, path string, valueType v1alpha1.DimensionType, defaultValue *v1alpha1.ProjectionDefaultValue) (string, bool, error) {
 	if path == "." {
@@ -49,9 +103,9 @@ func nestedFieldValue(obj unstructured.Unstructured, path string, valueType v1al
 	}
 
 	// Parse and execute JSONPath
-	jp := jsonpath.New("projection").AllowMissingKeys(true)
-	if err := jp.Parse(fmt.Sprintf("{.%s}", path)); err != nil {
-		return "", false, fmt.Errorf("failed to parse path: %v", err)
+	jp, err := parseFieldPath(path)
+	if err != nil {
+		return "", false, err
 	}
 
 	results, err := jp.FindResults(obj.UnstructuredContent())
@@ -80,6 +134,41 @@ func nestedFieldValue(obj unstructured.Unstructured, path string, valueType v1al
 	return s, true, err
 }
 
+// ValidateFieldPath checks that a projection path parses, the root path "." is only valid for the map type
+func ValidateFieldPath(path string, valueType v1alpha1.DimensionType) error {
+	if path == "." {
+		if valueType != v1alpha1.TypeMap {
+			return fmt.Errorf("type %s cannot be used with root path '.', only 'map' is supported", valueType)
+		}
+		return nil
+	}
+	_, err := parseFieldPath(path)
+	return err
+}
+
+// parseFieldPath parses a projection path to a JSONPath that ignores missing keys
+func parseFieldPath(path string) (*jsonpath.JSONPath, error) {
+	jp := jsonpath.New("projection").AllowMissingKeys(true)
+	if err := jp.Parse(fmt.Sprintf("{.%s}", expandSelectors(path))); err != nil {
+		return nil, fmt.Errorf("failed to parse path: %v", err)
+	}
+	return jp, nil
+}
+
+// itemSelector matches the [field=value] shorthand addressing a list item by a field, the value may be quoted
+var itemSelector = regexp.MustCompile(`\[([A-Za-z_][A-Za-z0-9_.-]*)=("[^"'\]]*"|'[^"'\]]*'|[^"'\]=]*)\]`)
+
+// expandSelectors rewrites the [field=value] selectors of a path to JSONPath filters,
+// e.g. "status.conditions[type=Ready].status" to "status.conditions[?(@.type=='Ready')].status".
+// Other brackets, like indexes and filters, are left unchanged.
+func expandSelectors(path string) string {
+	return itemSelector.ReplaceAllStringFunc(path, func(selector string) string {
+		match := itemSelector.FindStringSubmatch(selector)
+		value := strings.Trim(match[2], `"'`)
+		return fmt.Sprintf("[?(@.%s=='%s')]", match[1], value)
+	})
+}
+
 // extractTypedValue converts JSONPath results to a string according to valueType.
 func extractTypedValue(results [][]reflect.Value, valueType v1alpha1.DimensionType) (string, error) {
 	switch valueType {
@@ -198,6 +287,15 @@ func parseProjectionValue(s string) (int64, error) {
 	return 0, fmt.Errorf("cannot parse %q as integer or RFC3339 timestamp", s)
 }
 
+// parseQuantityValue parses a resource quantity like "10Gi" or "500m", rounded up to an integer
+func parseQuantityValue(s string) (int64, error) {
+	q, err := resource.ParseQuantity(s)
+	if err != nil {
+		return 0, fmt.Errorf("cannot parse %q as quantity: %w", s, err)
+	}
+	return q.Value(), nil
+}
+
 // resolveValueFrom resolves the valueFrom projection for each object in the list,
 // returning a map from object UID to the resolved int64 gauge value.
 // Objects where valueFrom cannot be resolved are omitted from the map.
@@ -211,14 +309,19 @@ func resolveValueFrom(list *unstructured.UnstructuredList, vf *v1alpha1.ValueFro
 		valueType = v1alpha1.ValueTypeInteger
 	}
 	// ValueType maps to DimensionType for nestedFieldValue; both integer and timestamp
-	// store their default as a JSON-encoded primitive string.
+	// store their default as a JSON-encoded primitive string, quantities are read as primitives.
 	dimType := v1alpha1.DimensionType(valueType)
+	parse := parseProjectionValue
+	if valueType == v1alpha1.ValueTypeQuantity {
+		dimType = v1alpha1.TypePrimitive
+		parse = parseQuantityValue
+	}
 	for _, obj := range list.Items {
 		raw, found, err := nestedFieldValue(obj, vf.FieldPath, dimType, vf.Default)
 		if err != nil || !found || raw == "" {
 			continue
 		}
-		v, err := parseProjectionValue(raw)
+		v, err := parse(raw)
 		if err != nil {
 			continue
 		}
@@ -280,19 +383,23 @@ func aggregateGroupValue(uids []string, valueByUID map[string]int64, vf *v1alpha
 }
 
 // It returns a map where the key is a unique combination of projected values and the value is a list of groups of projected fields that share that combination.
-func extractProjectionGroupsFrom(list *unstructured.UnstructuredList, projections []v1alpha1.Projection) projectionGroups {
+// The projections of the related resources of each object are added to its own projections.
+func extractProjectionGroupsFrom(list *unstructured.UnstructuredList, projections []v1alpha1.Projection, related ...*relatedResources) projectionGroups {
 	collection := make([][]projectedField, 0, len(list.Items))
 
 	for _, obj := range list.Items {
 		uid := string(obj.GetUID())
 		var fields []projectedField
 		for _, projection := range projections {
-			if projection.Name != "" && projection.FieldPath != "" {
+			if projection.Name != "" && (projection.FieldPath != "" || projection.Source != "") {
 				name := projection.Name
-				value, found, err := nestedFieldValue(obj, projection.FieldPath, projection.Type, projection.Default)
+				value, found, err := projectionValue(obj, projection)
 				fields = append(fields, projectedField{uid: uid, name: name, value: value, found: found, error: err})
 			}
 		}
+		for _, r := range related {
+			fields = append(fields, r.fields(obj)...)
+		}
 		if fields != nil {
 			collection = append(collection, fields)
 		}
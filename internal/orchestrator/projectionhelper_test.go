@@ -174,6 +174,36 @@ func TestNestedFieldValue_primitive(t *testing.T) {
 			wantFound:    true,
 			wantError:    false,
 		},
+		{
+			name:         "nested value retrieval with item selector",
+			resourceYaml: subaccountCR,
+			path:         "status.conditions[type=Ready].reason",
+			valueType:    v1alpha1.TypePrimitive,
+			defaultValue: nil,
+			wantValue:    "Available",
+			wantFound:    true,
+			wantError:    false,
+		},
+		{
+			name:         "nested value retrieval with quoted item selector",
+			resourceYaml: subaccountCR,
+			path:         `status.conditions[type="Synced"].reason`,
+			valueType:    v1alpha1.TypePrimitive,
+			defaultValue: nil,
+			wantValue:    "ReconcileSuccess",
+			wantFound:    true,
+			wantError:    false,
+		},
+		{
+			name:         "item selector without matching item uses default",
+			resourceYaml: subaccountCR,
+			path:         "status.conditions[type=Healthy].status",
+			valueType:    v1alpha1.TypePrimitive,
+			defaultValue: v1alpha1.NewProjectionDefaultValue("Unknown"),
+			wantValue:    "Unknown",
+			wantFound:    true,
+			wantError:    false,
+		},
 		{
 			name:         "nested value retrieval with array slice selector",
 			resourceYaml: subaccountCR,
@@ -1097,6 +1127,138 @@ func TestNestedFieldValue_timestamp(t *testing.T) {
 	})
 }
 
+const deploymentPod = `
+apiVersion: v1
+kind: Pod
+metadata:
+  name: web-7d4b9c8f6d-x2k9p
+  namespace: default
+  labels:
+    pod-template-hash: 7d4b9c8f6d
+  ownerReferences:
+  - apiVersion: apps/v1
+    kind: ReplicaSet
+    name: web-7d4b9c8f6d
+    uid: 1f0c1a52-7e0e-4a8b-9a3c-2d7c1c0e4d11
+    controller: true
+`
+
+const statefulSetPod = `
+apiVersion: v1
+kind: Pod
+metadata:
+  name: db-0
+  namespace: default
+  ownerReferences:
+  - apiVersion: v1
+    kind: ConfigMap
+    name: db-config
+    uid: 6a5b3c2d-1e0f-4a9b-8c7d-6e5f4a3b2c1d
+  - apiVersion: apps/v1
+    kind: StatefulSet
+    name: db
+    uid: 9e8d7c6b-5a4f-4e3d-2c1b-0a9f8e7d6c5b
+    controller: true
+`
+
+func TestProjectionValue_source(t *testing.T) {
+	tests := []struct {
+		name         string
+		resourceYaml string
+		projection   v1alpha1.Projection
+		wantValue    string
+		wantFound    bool
+	}{
+		{
+			name:         "owner kind of a deployment pod",
+			resourceYaml: deploymentPod,
+			projection:   v1alpha1.Projection{Name: "owner", Source: v1alpha1.ProjectionSourceOwnerKind},
+			wantValue:    "Deployment",
+			wantFound:    true,
+		},
+		{
+			name:         "owner name of a deployment pod",
+			resourceYaml: deploymentPod,
+			projection:   v1alpha1.Projection{Name: "owner", Source: v1alpha1.ProjectionSourceOwnerName},
+			wantValue:    "web",
+			wantFound:    true,
+		},
+		{
+			name:         "controller owner among several owners",
+			resourceYaml: statefulSetPod,
+			projection:   v1alpha1.Projection{Name: "owner", Source: v1alpha1.ProjectionSourceOwnerKind},
+			wantValue:    "StatefulSet",
+			wantFound:    true,
+		},
+		{
+			name:         "no owner",
+			resourceYaml: subaccountCR,
+			projection:   v1alpha1.Projection{Name: "owner", Source: v1alpha1.ProjectionSourceOwnerName},
+			wantValue:    "",
+			wantFound:    false,
+		},
+		{
+			name:         "no owner with default",
+			resourceYaml: subaccountCR,
+			projection:   v1alpha1.Projection{Name: "owner", Source: v1alpha1.ProjectionSourceOwnerKind, Default: v1alpha1.NewProjectionDefaultValue("none")},
+			wantValue:    "none",
+			wantFound:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, found, err := projectionValue(toUnstructured(t, tt.resourceYaml), tt.projection)
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if found != tt.wantFound {
+				t.Errorf("unexpected found result: got %v, want %v", found, tt.wantFound)
+			}
+			if value != tt.wantValue {
+				t.Errorf("unexpected value: got %q, want %q", value, tt.wantValue)
+			}
+		})
+	}
+}
+
+func TestExpandSelectors(t *testing.T) {
+	tests := []struct {
+		name string
+		path string
+		want string
+	}{
+		{
+			name: "no selector",
+			path: "metadata.name",
+			want: "metadata.name",
+		},
+		{
+			name: "item selector",
+			path: "status.conditions[type=Ready].status",
+			want: "status.conditions[?(@.type=='Ready')].status",
+		},
+		{
+			name: "quoted item selectors",
+			path: `spec.containers[name="app"].ports[name='http'].containerPort`,
+			want: "spec.containers[?(@.name=='app')].ports[?(@.name=='http')].containerPort",
+		},
+		{
+			name: "index and filter are unchanged",
+			path: "status.conditions[0].status,status.conditions[?(@.type=='Ready')].status",
+			want: "status.conditions[0].status,status.conditions[?(@.type=='Ready')].status",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := expandSelectors(tt.path); got != tt.want {
+				t.Errorf("expandSelectors(%q) = %q, want %q", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
 func TestParseProjectionValue(t *testing.T) {
 	tests := []struct {
 		name      string
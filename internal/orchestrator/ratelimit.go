@@ -0,0 +1,59 @@
+package orchestrator
+
+import (
+	"context"
+	"time"
+
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/util/flowcontrol"
+
+	internalmetrics "github.com/openmcp-project/metrics-operator/internal/metrics"
+)
+
+const (
+	// DefaultClientQPS is the number of requests per second the operator sends to each cluster it queries
+	DefaultClientQPS = 20
+	// DefaultClientBurst is the number of requests the operator may send to a cluster at once
+	DefaultClientBurst = 30
+)
+
+// ClientRateLimit is used for the clients of clusters whose rest config does not set a rate limit
+var ClientRateLimit = RateLimit{QPS: DefaultClientQPS, Burst: DefaultClientBurst}
+
+// RateLimit limits the requests to the API server of a cluster
+type RateLimit struct {
+	QPS   float32
+	Burst int
+}
+
+// observedRateLimiter records how long the requests to a cluster waited for the rate limiter
+type observedRateLimiter struct {
+	flowcontrol.RateLimiter
+	host string
+}
+
+func (l *observedRateLimiter) Wait(ctx context.Context) error {
+	start := time.Now()
+	err := l.RateLimiter.Wait(ctx)
+	internalmetrics.ClientRateLimiterWait.WithLabelValues(l.host).Observe(time.Since(start).Seconds())
+	return err
+}
+
+// withRateLimiter returns a copy of the rest config with a rate limiter shared by all clients created from it.
+// The QPS and burst of the rest config are used if set, otherwise ClientRateLimit applies.
+// A negative QPS disables the rate limiter.
+func withRateLimiter(restConfig *rest.Config) *rest.Config {
+	cfg := rest.CopyConfig(restConfig)
+	if cfg.RateLimiter != nil || cfg.QPS < 0 {
+		return cfg
+	}
+	if cfg.QPS == 0 {
+		cfg.QPS, cfg.Burst = ClientRateLimit.QPS, ClientRateLimit.Burst
+	}
+	burst := max(cfg.Burst, 1)
+	cfg.RateLimiter = &observedRateLimiter{
+		RateLimiter: flowcontrol.NewTokenBucketRateLimiter(cfg.QPS, burst),
+		host:        cfg.Host,
+	}
+	return cfg
+}
@@ -0,0 +1,74 @@
+package orchestrator
+
+import (
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/openmcp-project/metrics-operator/api/v1alpha1"
+	"github.com/openmcp-project/metrics-operator/internal/clientoptl"
+)
+
+// samples returns the names of the first items, as many as requested by the debug options
+func samples[T any](items []T, debug *v1alpha1.DebugOptions, name func(T) string) []string {
+	if debug == nil || debug.EmitSamples <= 0 {
+		return nil
+	}
+	n := min(debug.EmitSamples, len(items))
+	names := make([]string, 0, n)
+	for _, item := range items[:n] {
+		names = append(names, name(item))
+	}
+	return names
+}
+
+// objectName returns the namespaced name of an object, or its name if it is cluster scoped
+func objectName(namespace, name string) string {
+	if namespace == "" {
+		return name
+	}
+	return namespace + "/" + name
+}
+
+// recordedSeries returns the dimensions and values of the data points sorted by their dimensions,
+// at most v1alpha1.MaxRecordedSeries of them, if requested by the debug options
+func recordedSeries(debug *v1alpha1.DebugOptions, dataPoints []*clientoptl.DataPoint, now time.Time) *v1alpha1.RecordedSeries {
+	if debug == nil || !debug.RecordDimensions {
+		return nil
+	}
+	recorded := &v1alpha1.RecordedSeries{Timestamp: metav1.NewTime(now)}
+	recorded.Series, recorded.Omitted = seriesValues(dataPoints, v1alpha1.MaxRecordedSeries)
+	return recorded
+}
+
+// snapshot returns the snapshot of the data points of a collection, if requested by the metric
+func snapshot(spec *v1alpha1.MetricSpec, dataPoints []*clientoptl.DataPoint, now time.Time) *v1alpha1.MetricSnapshotSpec {
+	if !spec.Snapshot {
+		return nil
+	}
+	snapshot := &v1alpha1.MetricSnapshotSpec{Timestamp: metav1.NewTime(now)}
+	for _, dp := range dataPoints {
+		snapshot.Total += dp.Value
+	}
+	snapshot.Series, snapshot.Omitted = seriesValues(dataPoints, v1alpha1.MaxSnapshotSeries)
+	return snapshot
+}
+
+// seriesValues returns the dimensions and values of the data points sorted by their dimensions,
+// at most limit of them, and the number of omitted series
+func seriesValues(dataPoints []*clientoptl.DataPoint, limit int) ([]v1alpha1.RecordedSeriesValue, int32) {
+	series := make([]v1alpha1.RecordedSeriesValue, 0, len(dataPoints))
+	for _, dp := range dataPoints {
+		series = append(series, v1alpha1.RecordedSeriesValue{Dimensions: maps.Clone(dp.Dimensions), Value: dp.Value})
+	}
+	slices.SortFunc(series, func(a, b v1alpha1.RecordedSeriesValue) int {
+		return strings.Compare(dimensionsKey(a.Dimensions), dimensionsKey(b.Dimensions))
+	})
+	if len(series) > limit {
+		return series[:limit], int32(len(series) - limit)
+	}
+	return series, 0
+}
@@ -0,0 +1,126 @@
+package orchestrator
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/openmcp-project/metrics-operator/api/v1alpha1"
+	"github.com/openmcp-project/metrics-operator/internal/clientoptl"
+)
+
+func TestSamples(t *testing.T) {
+	items := []string{"a", "b", "c"}
+	mark := func(s string) string { return s + "!" }
+
+	tests := []struct {
+		name  string
+		debug *v1alpha1.DebugOptions
+		want  []string
+	}{
+		{name: "no debug options"},
+		{name: "disabled", debug: &v1alpha1.DebugOptions{}},
+		{name: "fewer than items", debug: &v1alpha1.DebugOptions{EmitSamples: 2}, want: []string{"a!", "b!"}},
+		{name: "more than items", debug: &v1alpha1.DebugOptions{EmitSamples: 5}, want: []string{"a!", "b!", "c!"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := samples(items, tt.debug, mark); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("samples() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestObjectName(t *testing.T) {
+	if got := objectName("team-a", "pod"); got != "team-a/pod" {
+		t.Errorf("objectName() = %v, want team-a/pod", got)
+	}
+	if got := objectName("", "node"); got != "node" {
+		t.Errorf("objectName() = %v, want node", got)
+	}
+}
+
+func TestRecordedSeries(t *testing.T) {
+	now := time.Now()
+	dataPoints := []*clientoptl.DataPoint{
+		clientoptl.NewDataPoint().AddDimension("phase", "Running").SetValue(3),
+		clientoptl.NewDataPoint().AddDimension("phase", "Pending").SetValue(1),
+	}
+
+	if got := recordedSeries(nil, dataPoints, now); got != nil {
+		t.Errorf("recordedSeries() without debug options = %v, want nil", got)
+	}
+	if got := recordedSeries(&v1alpha1.DebugOptions{EmitSamples: 1}, dataPoints, now); got != nil {
+		t.Errorf("recordedSeries() without recordDimensions = %v, want nil", got)
+	}
+
+	got := recordedSeries(&v1alpha1.DebugOptions{RecordDimensions: true}, dataPoints, now)
+	want := []v1alpha1.RecordedSeriesValue{
+		{Dimensions: map[string]string{"phase": "Pending"}, Value: 1},
+		{Dimensions: map[string]string{"phase": "Running"}, Value: 3},
+	}
+	if !reflect.DeepEqual(got.Series, want) || got.Omitted != 0 || !got.Timestamp.Time.Equal(now) {
+		t.Errorf("recordedSeries() = %v, want series %v", got, want)
+	}
+
+	many := make([]*clientoptl.DataPoint, 0, v1alpha1.MaxRecordedSeries+5)
+	for i := range v1alpha1.MaxRecordedSeries + 5 {
+		many = append(many, clientoptl.NewDataPoint().AddDimension("name", fmt.Sprintf("pod-%03d", i)).SetValue(1))
+	}
+	got = recordedSeries(&v1alpha1.DebugOptions{RecordDimensions: true}, many, now)
+	if len(got.Series) != v1alpha1.MaxRecordedSeries || got.Omitted != 5 {
+		t.Errorf("recordedSeries() listed %d series and omitted %d, want %d and 5", len(got.Series), got.Omitted, v1alpha1.MaxRecordedSeries)
+	}
+}
+
+func TestSeriesValues(t *testing.T) {
+	dataPoints := []*clientoptl.DataPoint{
+		clientoptl.NewDataPoint().AddDimension("phase", "Running").SetValue(3),
+		clientoptl.NewDataPoint().AddDimension("phase", "Pending").SetValue(1),
+	}
+
+	got, omitted := seriesValues(dataPoints, v1alpha1.MaxObservedSeries)
+	want := []v1alpha1.RecordedSeriesValue{
+		{Dimensions: map[string]string{"phase": "Pending"}, Value: 1},
+		{Dimensions: map[string]string{"phase": "Running"}, Value: 3},
+	}
+	if !reflect.DeepEqual(got, want) || omitted != 0 {
+		t.Errorf("seriesValues() = %v, %d, want %v, 0", got, omitted, want)
+	}
+
+	many := make([]*clientoptl.DataPoint, 0, v1alpha1.MaxObservedSeries+5)
+	for i := range v1alpha1.MaxObservedSeries + 5 {
+		many = append(many, clientoptl.NewDataPoint().AddDimension("name", fmt.Sprintf("pod-%03d", i)).SetValue(1))
+	}
+	got, omitted = seriesValues(many, v1alpha1.MaxObservedSeries)
+	if len(got) != v1alpha1.MaxObservedSeries || omitted != 5 {
+		t.Errorf("seriesValues() listed %d series and omitted %d, want %d and 5", len(got), omitted, v1alpha1.MaxObservedSeries)
+	}
+	if got[0].Dimensions["name"] != "pod-000" {
+		t.Errorf("seriesValues() starts with %v, want pod-000", got[0].Dimensions)
+	}
+}
+
+func TestSnapshot(t *testing.T) {
+	now := time.Now()
+	dataPoints := []*clientoptl.DataPoint{
+		clientoptl.NewDataPoint().AddDimension("phase", "Running").SetValue(3),
+		clientoptl.NewDataPoint().AddDimension("phase", "Pending").SetValue(1),
+	}
+
+	if got := snapshot(&v1alpha1.MetricSpec{}, dataPoints, now); got != nil {
+		t.Errorf("snapshot() without spec.snapshot = %v, want nil", got)
+	}
+
+	got := snapshot(&v1alpha1.MetricSpec{Snapshot: true}, dataPoints, now)
+	want := []v1alpha1.RecordedSeriesValue{
+		{Dimensions: map[string]string{"phase": "Pending"}, Value: 1},
+		{Dimensions: map[string]string{"phase": "Running"}, Value: 3},
+	}
+	if !reflect.DeepEqual(got.Series, want) || got.Total != 4 || got.Omitted != 0 || !got.Timestamp.Time.Equal(now) {
+		t.Errorf("snapshot() = %v, want series %v with total 4", got, want)
+	}
+}
@@ -0,0 +1,112 @@
+package orchestrator
+
+import (
+	"maps"
+	"math"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/openmcp-project/metrics-operator/api/v1alpha1"
+	"github.com/openmcp-project/metrics-operator/internal/clientoptl"
+)
+
+// defaultSamplingAggregations are exported if the sampling of a metric does not list any
+var defaultSamplingAggregations = []string{
+	v1alpha1.SamplingAggregationMin,
+	v1alpha1.SamplingAggregationMax,
+	v1alpha1.SamplingAggregationAvg,
+	v1alpha1.SamplingAggregationLast,
+}
+
+// applySampling adds the data points as a sample to the sampling window of the metric.
+// Once the interval has passed since the start of the window, it returns the aggregated data points of the window
+// and starts a new one, before that it returns no data points. Metrics without sampling are returned as is.
+// The boolean is true if the data points are only sampled and not exported.
+func applySampling(spec *v1alpha1.MetricSpec, window *v1alpha1.MetricSamplingWindow, dataPoints []*clientoptl.DataPoint, now time.Time) ([]*clientoptl.DataPoint, *v1alpha1.MetricSamplingWindow, bool, error) {
+	if spec.Sampling == nil {
+		return dataPoints, nil, false, nil
+	}
+
+	// the first sample starts the window
+	if window == nil {
+		window = &v1alpha1.MetricSamplingWindow{Start: metav1.NewTime(now)}
+	} else {
+		window = window.DeepCopy()
+	}
+	addSample(window, dataPoints)
+	if len(window.Series) > v1alpha1.MaxSamplingWindowSeries {
+		return nil, nil, false, &TooManySeriesError{Field: "status.samplingWindow", Series: len(window.Series), Limit: v1alpha1.MaxSamplingWindowSeries}
+	}
+	if now.Before(window.Start.Add(spec.Interval.Duration)) {
+		return nil, window, true, nil
+	}
+	return aggregateSamples(window, spec.Sampling.Aggregations), &v1alpha1.MetricSamplingWindow{Start: metav1.NewTime(now)}, false, nil
+}
+
+// addSample adds the values of the data points to the series of the window,
+// series that are not part of the sample count as 0, like series that were not part of the previous samples
+func addSample(window *v1alpha1.MetricSamplingWindow, dataPoints []*clientoptl.DataPoint) {
+	window.Samples++
+	sampled := make(map[string]int64, len(dataPoints))
+	for _, dp := range dataPoints {
+		sampled[dimensionsKey(dp.Dimensions)] = dp.Value
+	}
+
+	known := make(map[string]bool, len(window.Series))
+	for i := range window.Series {
+		s := &window.Series[i]
+		key := dimensionsKey(s.Dimensions)
+		known[key] = true
+		value := sampled[key]
+		s.Min = min(s.Min, value)
+		s.Max = max(s.Max, value)
+		s.Sum += value
+		s.Last = value
+	}
+	for _, dp := range dataPoints {
+		key := dimensionsKey(dp.Dimensions)
+		if known[key] {
+			continue
+		}
+		known[key] = true
+		s := v1alpha1.SampledSeries{Dimensions: maps.Clone(dp.Dimensions), Min: dp.Value, Max: dp.Value, Sum: dp.Value, Last: dp.Value}
+		if window.Samples > 1 {
+			s.Min = min(s.Min, 0)
+			s.Max = max(s.Max, 0)
+		}
+		window.Series = append(window.Series, s)
+	}
+}
+
+// aggregateSamples returns a data point for each aggregation of each series of the window
+func aggregateSamples(window *v1alpha1.MetricSamplingWindow, aggregations []string) []*clientoptl.DataPoint {
+	if len(aggregations) == 0 {
+		aggregations = defaultSamplingAggregations
+	}
+	dataPoints := make([]*clientoptl.DataPoint, 0, len(window.Series)*len(aggregations))
+	for _, s := range window.Series {
+		for _, aggregation := range aggregations {
+			var value int64
+			switch aggregation {
+			case v1alpha1.SamplingAggregationMin:
+				value = s.Min
+			case v1alpha1.SamplingAggregationMax:
+				value = s.Max
+			case v1alpha1.SamplingAggregationAvg:
+				value = int64(math.Round(float64(s.Sum) / float64(window.Samples)))
+			case v1alpha1.SamplingAggregationLast:
+				value = s.Last
+			default:
+				continue
+			}
+			dp := clientoptl.NewDataPoint().SetValue(value)
+			for k, v := range s.Dimensions {
+				dp.AddDimension(k, v)
+			}
+			dp.AddDimension(v1alpha1.SamplingAggregationDimension, aggregation)
+			dataPoints = append(dataPoints, dp)
+		}
+	}
+	return dataPoints
+}
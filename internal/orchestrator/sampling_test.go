@@ -0,0 +1,136 @@
+package orchestrator
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/openmcp-project/metrics-operator/api/v1alpha1"
+	"github.com/openmcp-project/metrics-operator/internal/clientoptl"
+)
+
+func TestApplySampling(t *testing.T) {
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	spec := &v1alpha1.MetricSpec{
+		Interval: metav1.Duration{Duration: 5 * time.Minute},
+		Sampling: &v1alpha1.Sampling{SampleInterval: metav1.Duration{Duration: time.Minute}},
+	}
+	pending := func(value int64) *clientoptl.DataPoint {
+		return clientoptl.NewDataPoint().AddDimension("phase", "Pending").SetValue(value)
+	}
+	running := func(value int64) *clientoptl.DataPoint {
+		return clientoptl.NewDataPoint().AddDimension("phase", "Running").SetValue(value)
+	}
+	samples := [][]*clientoptl.DataPoint{
+		{running(10)},
+		{running(10), pending(6)},
+		{running(12), pending(2)},
+		{running(12)},
+		{running(11)},
+	}
+
+	var window *v1alpha1.MetricSamplingWindow
+	for i, sample := range samples {
+		got, next, sampleOnly, _ := applySampling(spec, window, sample, start.Add(time.Duration(i)*time.Minute))
+		if !sampleOnly || len(got) != 0 {
+			t.Fatalf("sample %d: unexpected export of %d data points", i, len(got))
+		}
+		if next == nil || !next.Start.Time.Equal(start) || next.Samples != int32(i+1) {
+			t.Fatalf("sample %d: unexpected window: %v", i, next)
+		}
+		window = next
+	}
+
+	end := start.Add(5 * time.Minute)
+	got, next, sampleOnly, _ := applySampling(spec, window, []*clientoptl.DataPoint{running(11)}, end)
+	if sampleOnly {
+		t.Fatal("expected the window to be exported")
+	}
+	if next == nil || !next.Start.Time.Equal(end) || next.Samples != 0 || len(next.Series) != 0 {
+		t.Errorf("expected a new window, got %v", next)
+	}
+	if window.Samples != 5 {
+		t.Errorf("the previous window was modified: %v", window)
+	}
+
+	want := map[string]int64{
+		"aggregation=min,phase=Running":  10,
+		"aggregation=max,phase=Running":  12,
+		"aggregation=avg,phase=Running":  11,
+		"aggregation=last,phase=Running": 11,
+		"aggregation=min,phase=Pending":  0,
+		"aggregation=max,phase=Pending":  6,
+		"aggregation=avg,phase=Pending":  1,
+		"aggregation=last,phase=Pending": 0,
+	}
+	if len(got) != len(want) {
+		t.Fatalf("unexpected number of data points: wanted=%v, got=%v", len(want), len(got))
+	}
+	for _, dp := range got {
+		key := dimensionsKey(dp.Dimensions)
+		if value, ok := want[key]; !ok || value != dp.Value {
+			t.Errorf("unexpected data point %s=%v", key, dp.Value)
+		}
+	}
+}
+
+func TestApplySampling_aggregations(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	spec := &v1alpha1.MetricSpec{
+		Interval: metav1.Duration{Duration: 5 * time.Minute},
+		Sampling: &v1alpha1.Sampling{
+			SampleInterval: metav1.Duration{Duration: time.Minute},
+			Aggregations:   []string{v1alpha1.SamplingAggregationMax},
+		},
+	}
+	window := &v1alpha1.MetricSamplingWindow{
+		Start:   metav1.NewTime(now.Add(-5 * time.Minute)),
+		Samples: 1,
+		Series:  []v1alpha1.SampledSeries{{Min: 3, Max: 3, Sum: 3, Last: 3}},
+	}
+
+	got, _, _, _ := applySampling(spec, window, []*clientoptl.DataPoint{clientoptl.NewDataPoint().SetValue(7)}, now)
+	if len(got) != 1 {
+		t.Fatalf("unexpected number of data points: wanted=1, got=%v", len(got))
+	}
+	if got[0].Value != 7 || got[0].Dimensions[v1alpha1.SamplingAggregationDimension] != v1alpha1.SamplingAggregationMax {
+		t.Errorf("unexpected data point: %v", got[0])
+	}
+}
+
+func TestApplySampling_disabled(t *testing.T) {
+	dataPoints := []*clientoptl.DataPoint{clientoptl.NewDataPoint().SetValue(1)}
+	got, window, sampleOnly, _ := applySampling(&v1alpha1.MetricSpec{}, nil, dataPoints, time.Now())
+	if len(got) != 1 || window != nil || sampleOnly {
+		t.Errorf("unexpected sampling of a metric without sampling: %v, %v, %v", got, window, sampleOnly)
+	}
+}
+
+func TestApplySampling_tooManySeries(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	spec := &v1alpha1.MetricSpec{
+		Interval: metav1.Duration{Duration: 10 * time.Minute},
+		Sampling: &v1alpha1.Sampling{SampleInterval: metav1.Duration{Duration: time.Minute}},
+	}
+	// the series of the samples add up in the window
+	sample := func(offset int) []*clientoptl.DataPoint {
+		dataPoints := make([]*clientoptl.DataPoint, 0, v1alpha1.MaxSamplingWindowSeries/2+1)
+		for i := range v1alpha1.MaxSamplingWindowSeries/2 + 1 {
+			dataPoints = append(dataPoints, clientoptl.NewDataPoint().AddDimension("name", fmt.Sprintf("pod-%d", offset+i)).SetValue(1))
+		}
+		return dataPoints
+	}
+
+	_, window, _, err := applySampling(spec, nil, sample(0), now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, next, _, err := applySampling(spec, window, sample(v1alpha1.MaxSamplingWindowSeries), now.Add(time.Minute))
+	var tooMany *TooManySeriesError
+	if !errors.As(err, &tooMany) || tooMany.Field != "status.samplingWindow" || next != nil {
+		t.Errorf("unexpected result: %v, %v", next, err)
+	}
+}
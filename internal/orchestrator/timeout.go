@@ -0,0 +1,38 @@
+package orchestrator
+
+import (
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// DefaultPhaseTimeout is the timeout of each collection phase of metrics that do not set a timeout
+var DefaultPhaseTimeout = time.Minute
+
+// phaseTimeoutMu guards DefaultPhaseTimeout, which is changed at runtime by the MetricsOperatorConfig
+var phaseTimeoutMu sync.RWMutex
+
+// Collection phases bounded by the timeout of a metric
+const (
+	CollectionPhaseList       = "list"
+	CollectionPhaseProjection = "projection"
+	CollectionPhaseExport     = "export"
+)
+
+// SetDefaultPhaseTimeout changes the timeout of each collection phase of metrics that do not set a timeout
+func SetDefaultPhaseTimeout(timeout time.Duration) {
+	phaseTimeoutMu.Lock()
+	defer phaseTimeoutMu.Unlock()
+	DefaultPhaseTimeout = timeout
+}
+
+// PhaseTimeout returns the timeout of each collection phase for the timeout of a metric
+func PhaseTimeout(timeout *metav1.Duration) time.Duration {
+	if timeout == nil || timeout.Duration <= 0 {
+		phaseTimeoutMu.RLock()
+		defer phaseTimeoutMu.RUnlock()
+		return DefaultPhaseTimeout
+	}
+	return timeout.Duration
+}
@@ -0,0 +1,303 @@
+// Package lint checks the specs of Metrics and ManagedMetrics for mistakes the API server accepts,
+// but that make the metric fail or export nothing, e.g. selectors that do not parse
+// or kinds that do not exist in the cluster. It is used by the validate command of the operator
+// and can be used by other tools checking manifests before they are applied.
+package lint
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/discovery"
+
+	"github.com/openmcp-project/metrics-operator/api/v1alpha1"
+	"github.com/openmcp-project/metrics-operator/internal/cron"
+	"github.com/openmcp-project/metrics-operator/internal/expression"
+	"github.com/openmcp-project/metrics-operator/internal/orchestrator"
+)
+
+const (
+	// DefaultMinInterval is the shortest interval of a metric that passes the checks
+	DefaultMinInterval = time.Minute
+	// DefaultMaxInterval is the longest interval of a metric that passes the checks
+	DefaultMaxInterval = 24 * time.Hour
+)
+
+// Severity tells whether a finding makes the metric fail or is likely a mistake
+type Severity string
+
+const (
+	// SeverityError is a finding that makes the metric fail or be rejected
+	SeverityError Severity = "error"
+	// SeverityWarning is a finding that is likely a mistake, but does not make the metric fail
+	SeverityWarning Severity = "warning"
+)
+
+// Finding is a problem of a field of a metric spec
+type Finding struct {
+	Severity Severity
+	// Field is the path of the field, e.g. "spec.projections[0].fieldPath"
+	Field   string
+	Message string
+}
+
+func (f Finding) String() string {
+	return fmt.Sprintf("%s: %s: %s", f.Severity, f.Field, f.Message)
+}
+
+// Options configure the checks
+type Options struct {
+	// Discovery is used to check that the targeted kinds exist, they are not checked if it is nil
+	Discovery discovery.DiscoveryInterface
+	// MinInterval and MaxInterval bound the interval of the metrics, zero uses DefaultMinInterval and DefaultMaxInterval
+	MinInterval time.Duration
+	MaxInterval time.Duration
+}
+
+// HasErrors returns true if one of the findings is an error
+func HasErrors(findings []Finding) bool {
+	return slices.ContainsFunc(findings, func(f Finding) bool { return f.Severity == SeverityError })
+}
+
+// linter collects the findings of a spec
+type linter struct {
+	opts     Options
+	findings []Finding
+}
+
+func (l *linter) errorf(field, format string, args ...any) {
+	l.findings = append(l.findings, Finding{Severity: SeverityError, Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+func (l *linter) warnf(field, format string, args ...any) {
+	l.findings = append(l.findings, Finding{Severity: SeverityWarning, Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+// MetricSpec checks the spec of a Metric, the fields of the findings are prefixed with path, e.g. "spec"
+func MetricSpec(spec *v1alpha1.MetricSpec, path string, opts Options) []Finding {
+	l := &linter{opts: opts}
+	l.schedule(spec, path)
+	l.sampling(spec, path)
+	l.target(spec.Target, path+".target")
+	l.labelSelector(spec.LabelSelector, path+".labelSelector")
+	l.fieldSelector(spec.FieldSelector, path+".fieldSelector")
+	l.labelSelector(spec.ExcludeLabelSelector, path+".excludeLabelSelector")
+	l.fieldSelector(spec.ExcludeFieldSelector, path+".excludeFieldSelector")
+	l.projections(spec.Projections, path+".projections")
+
+	if spec.ValueFrom != nil {
+		l.fieldPath(spec.ValueFrom.FieldPath, v1alpha1.TypePrimitive, path+".valueFrom.fieldPath")
+	}
+	for i, enrichment := range spec.Enrichments {
+		field := fmt.Sprintf("%s.enrichments[%d]", path, i)
+		l.gvk(enrichment.Target, field+".target")
+		l.fieldPath(enrichment.NameFrom, v1alpha1.TypePrimitive, field+".nameFrom")
+		l.projections(enrichment.Projections, field+".projections")
+	}
+	for i, target := range spec.Targets {
+		field := fmt.Sprintf("%s.targets[%d]", path, i)
+		l.target(target.Target, field+".target")
+		l.labelSelector(target.LabelSelector, field+".labelSelector")
+		l.fieldSelector(target.FieldSelector, field+".fieldSelector")
+	}
+	l.combine(spec, path+".combine")
+	l.preset(spec, path)
+	return l.findings
+}
+
+// ManagedMetricSpec checks the spec of a ManagedMetric, the fields of the findings are prefixed with path, e.g. "spec"
+func ManagedMetricSpec(spec *v1alpha1.ManagedMetricSpec, path string, opts Options) []Finding {
+	l := &linter{opts: opts}
+	l.interval(spec.Interval.Duration, path+".interval")
+	// the target of managed metrics may leave out the group or version to match all of them
+	if target := spec.Target; target != nil && target.Kind != "" && target.Version != "" {
+		l.gvk(*target, path+".target")
+	}
+	l.labelSelector(spec.LabelSelector, path+".labelSelector")
+	l.fieldSelector(spec.FieldSelector, path+".fieldSelector")
+	l.projections(spec.Dimensions, path+".dimensions")
+	return l.findings
+}
+
+// schedule checks the cron schedule, or the interval bounds of metrics without a schedule
+func (l *linter) schedule(spec *v1alpha1.MetricSpec, path string) {
+	if spec.Schedule != "" {
+		schedule, err := cron.Parse(spec.Schedule)
+		if err != nil {
+			l.errorf(path+".schedule", "%v", err)
+		} else if schedule.Next(time.Now().UTC()).IsZero() {
+			l.errorf(path+".schedule", "cron expression '%s' never fires", spec.Schedule)
+		}
+		return
+	}
+
+	l.interval(spec.Interval.Duration, path+".interval")
+}
+
+// interval checks that the interval is within the bounds of the options
+func (l *linter) interval(interval time.Duration, path string) {
+	// an unset interval is defaulted by the API server
+	if interval == 0 {
+		return
+	}
+	minInterval, maxInterval := l.opts.MinInterval, l.opts.MaxInterval
+	if minInterval == 0 {
+		minInterval = DefaultMinInterval
+	}
+	if maxInterval == 0 {
+		maxInterval = DefaultMaxInterval
+	}
+	switch {
+	case interval < 0:
+		l.errorf(path, "interval %s is negative", interval)
+	case interval < minInterval:
+		l.errorf(path, "interval %s is shorter than %s", interval, minInterval)
+	case interval > maxInterval:
+		l.errorf(path, "interval %s is longer than %s", interval, maxInterval)
+	}
+}
+
+// sampling checks that samples are taken within the interval, sampling more often than the minimum interval is a warning
+func (l *linter) sampling(spec *v1alpha1.MetricSpec, path string) {
+	if spec.Sampling == nil {
+		return
+	}
+	sampleInterval := spec.Sampling.SampleInterval.Duration
+	minInterval := l.opts.MinInterval
+	if minInterval == 0 {
+		minInterval = DefaultMinInterval
+	}
+	switch {
+	case spec.Schedule != "":
+		l.errorf(path+".sampling", "sampling cannot be used together with schedule")
+	case sampleInterval <= 0:
+		l.errorf(path+".sampling.sampleInterval", "sample interval %s is not positive", sampleInterval)
+	case spec.Interval.Duration != 0 && sampleInterval >= spec.Interval.Duration:
+		l.errorf(path+".sampling.sampleInterval", "sample interval %s is not shorter than the interval %s", sampleInterval, spec.Interval.Duration)
+	case sampleInterval < minInterval:
+		l.warnf(path+".sampling.sampleInterval", "sample interval %s is shorter than %s, the target is listed for every sample", sampleInterval, minInterval)
+	}
+}
+
+func (l *linter) target(target v1alpha1.MetricTarget, path string) {
+	l.gvk(target.GroupVersionKind, path)
+	if target.NamespaceSelector != nil {
+		if _, err := metav1.LabelSelectorAsSelector(target.NamespaceSelector); err != nil {
+			l.errorf(path+".namespaceSelector", "invalid namespace selector: %v", err)
+		}
+	}
+}
+
+// gvk checks that the kind is set and, with a discovery client, that it is served by the cluster
+func (l *linter) gvk(gvk v1alpha1.GroupVersionKind, path string) {
+	if gvk.Kind == "" || gvk.Version == "" {
+		l.errorf(path, "kind and version are required")
+		return
+	}
+	if l.opts.Discovery == nil {
+		return
+	}
+	gvr, err := orchestrator.GetGVRfromGVK(gvk.GVK(), l.opts.Discovery)
+	if err != nil {
+		l.errorf(path, "group version %s is not served by the cluster: %v", gvk.GVK().GroupVersion(), err)
+		return
+	}
+	if gvr.Resource == "" {
+		l.errorf(path, "kind %s does not exist in group version %s", gvk.Kind, gvk.GVK().GroupVersion())
+	}
+}
+
+func (l *linter) labelSelector(selector, path string) {
+	if selector == "" {
+		return
+	}
+	if _, err := labels.Parse(selector); err != nil {
+		l.errorf(path, "invalid label selector '%s': %v", selector, err)
+	}
+}
+
+func (l *linter) fieldSelector(selector, path string) {
+	if selector == "" {
+		return
+	}
+	if _, err := fields.ParseSelector(selector); err != nil {
+		l.errorf(path, "invalid field selector '%s': %v", selector, err)
+	}
+}
+
+func (l *linter) projections(projections []v1alpha1.Projection, path string) {
+	names := map[string]bool{}
+	for i, projection := range projections {
+		field := fmt.Sprintf("%s[%d]", path, i)
+		switch {
+		case projection.Name == "":
+			l.errorf(field+".name", "name is required")
+		case names[projection.Name]:
+			l.errorf(field+".name", "duplicate projection name '%s'", projection.Name)
+		}
+		names[projection.Name] = true
+
+		if projection.Source != "" {
+			continue
+		}
+		valueType := projection.Type
+		if valueType == "" {
+			valueType = v1alpha1.TypePrimitive
+		}
+		l.fieldPath(projection.FieldPath, valueType, field+".fieldPath")
+	}
+}
+
+// fieldPath checks that a path parses and warns about a leading dot, which JSONPath reads as recursive descent
+func (l *linter) fieldPath(fieldPath string, valueType v1alpha1.DimensionType, path string) {
+	if fieldPath == "" {
+		l.errorf(path, "field path is required")
+		return
+	}
+	if err := orchestrator.ValidateFieldPath(fieldPath, valueType); err != nil {
+		l.errorf(path, "invalid field path '%s': %v", fieldPath, err)
+		return
+	}
+	if fieldPath != "." && strings.HasPrefix(fieldPath, ".") {
+		l.warnf(path, "field path '%s' starts with a dot and matches the field at any depth, paths use dot-notation without a leading dot, e.g. 'metadata.name'", fieldPath)
+	}
+}
+
+// combine checks that the expression parses and only references the target and the named targets
+func (l *linter) combine(spec *v1alpha1.MetricSpec, path string) {
+	if spec.Combine == "" {
+		return
+	}
+	expr, err := expression.Parse(spec.Combine)
+	if err != nil {
+		l.errorf(path, "invalid combine expression: %v", err)
+		return
+	}
+	for _, variable := range expr.Variables() {
+		if variable == v1alpha1.CombinePrimaryTarget {
+			continue
+		}
+		if !slices.ContainsFunc(spec.Targets, func(t v1alpha1.NamedTarget) bool { return t.Name == variable }) {
+			l.errorf(path, "combine expression references '%s', which is neither '%s' nor the name of a target", variable, v1alpha1.CombinePrimaryTarget)
+		}
+	}
+}
+
+// preset checks that the target is of the kind the preset is computed from
+func (l *linter) preset(spec *v1alpha1.MetricSpec, path string) {
+	if spec.Preset == "" {
+		return
+	}
+	kind, ok := orchestrator.PresetKind(spec.Preset)
+	switch {
+	case !ok:
+		l.errorf(path+".preset", "unknown preset '%s'", spec.Preset)
+	case spec.Target.Kind != kind || spec.Target.Group != "":
+		l.errorf(path+".target", "preset '%s' requires a target of kind %s", spec.Preset, kind)
+	}
+}
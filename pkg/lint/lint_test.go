@@ -0,0 +1,315 @@
+package lint
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	clienttesting "k8s.io/client-go/testing"
+
+	"github.com/openmcp-project/metrics-operator/api/v1alpha1"
+)
+
+func podSpec() v1alpha1.MetricSpec {
+	return v1alpha1.MetricSpec{
+		Name:     "pods",
+		Target:   v1alpha1.MetricTarget{GroupVersionKind: v1alpha1.GroupVersionKind{Kind: "Pod", Version: "v1"}},
+		Interval: metav1.Duration{Duration: 10 * time.Minute},
+	}
+}
+
+func TestMetricSpec(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(spec *v1alpha1.MetricSpec)
+		opts   Options
+		want   []Finding
+	}{
+		{
+			name:   "valid spec",
+			modify: func(*v1alpha1.MetricSpec) {},
+		},
+		{
+			name: "interval below the minimum",
+			modify: func(spec *v1alpha1.MetricSpec) {
+				spec.Interval = metav1.Duration{Duration: 10 * time.Second}
+			},
+			want: []Finding{{Severity: SeverityError, Field: "spec.interval", Message: "interval 10s is shorter than 1m0s"}},
+		},
+		{
+			name: "interval above the configured maximum",
+			modify: func(spec *v1alpha1.MetricSpec) {
+				spec.Interval = metav1.Duration{Duration: 2 * time.Hour}
+			},
+			opts: Options{MaxInterval: time.Hour},
+			want: []Finding{{Severity: SeverityError, Field: "spec.interval", Message: "interval 2h0m0s is longer than 1h0m0s"}},
+		},
+		{
+			name: "schedule takes precedence over the interval",
+			modify: func(spec *v1alpha1.MetricSpec) {
+				spec.Interval = metav1.Duration{Duration: time.Second}
+				spec.Schedule = "@hourly"
+			},
+		},
+		{
+			name: "sampling",
+			modify: func(spec *v1alpha1.MetricSpec) {
+				spec.Sampling = &v1alpha1.Sampling{SampleInterval: metav1.Duration{Duration: 30 * time.Second}}
+			},
+			want: []Finding{{Severity: SeverityWarning, Field: "spec.sampling.sampleInterval", Message: "sample interval 30s is shorter than 1m0s, the target is listed for every sample"}},
+		},
+		{
+			name: "sample interval not shorter than the interval",
+			modify: func(spec *v1alpha1.MetricSpec) {
+				spec.Sampling = &v1alpha1.Sampling{SampleInterval: metav1.Duration{Duration: 10 * time.Minute}}
+			},
+			want: []Finding{{Severity: SeverityError, Field: "spec.sampling.sampleInterval", Message: "sample interval 10m0s is not shorter than the interval 10m0s"}},
+		},
+		{
+			name: "invalid schedule",
+			modify: func(spec *v1alpha1.MetricSpec) {
+				spec.Schedule = "0 * *"
+			},
+			want: []Finding{{Severity: SeverityError, Field: "spec.schedule", Message: "invalid cron expression '0 * *': expected 5 fields, got 3"}},
+		},
+		{
+			name: "missing kind",
+			modify: func(spec *v1alpha1.MetricSpec) {
+				spec.Target.Kind = ""
+			},
+			want: []Finding{{Severity: SeverityError, Field: "spec.target", Message: "kind and version are required"}},
+		},
+		{
+			name: "invalid selectors",
+			modify: func(spec *v1alpha1.MetricSpec) {
+				spec.LabelSelector = "app in (a"
+				spec.ExcludeFieldSelector = "status.phase"
+				spec.Target.NamespaceSelector = &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "team", Operator: "Like"}}}
+			},
+			want: []Finding{
+				{Severity: SeverityError, Field: "spec.target.namespaceSelector"},
+				{Severity: SeverityError, Field: "spec.labelSelector"},
+				{Severity: SeverityError, Field: "spec.excludeFieldSelector"},
+			},
+		},
+		{
+			name: "projection paths",
+			modify: func(spec *v1alpha1.MetricSpec) {
+				spec.Projections = []v1alpha1.Projection{
+					{Name: "ready", FieldPath: "status.conditions[type=Ready].status"},
+					{Name: "owner", Source: v1alpha1.ProjectionSourceOwnerKind},
+					{Name: "phase", FieldPath: ".status.phase"},
+					{Name: "phase", FieldPath: "status.containerStatuses[0"},
+					{Name: "object", FieldPath: "."},
+					{FieldPath: ""},
+				}
+			},
+			want: []Finding{
+				{Severity: SeverityWarning, Field: "spec.projections[2].fieldPath"},
+				{Severity: SeverityError, Field: "spec.projections[3].name", Message: "duplicate projection name 'phase'"},
+				{Severity: SeverityError, Field: "spec.projections[3].fieldPath"},
+				{Severity: SeverityError, Field: "spec.projections[4].fieldPath"},
+				{Severity: SeverityError, Field: "spec.projections[5].name", Message: "name is required"},
+				{Severity: SeverityError, Field: "spec.projections[5].fieldPath", Message: "field path is required"},
+			},
+		},
+		{
+			name: "enrichments and valueFrom",
+			modify: func(spec *v1alpha1.MetricSpec) {
+				spec.ValueFrom = &v1alpha1.ValueFromProjection{FieldPath: "status.restarts["}
+				spec.Enrichments = []v1alpha1.Enrichment{{
+					Target:      v1alpha1.GroupVersionKind{Kind: "Namespace", Version: "v1"},
+					NameFrom:    "metadata.namespace",
+					Projections: []v1alpha1.Projection{{Name: "team", FieldPath: "metadata.labels.team"}},
+				}}
+			},
+			want: []Finding{{Severity: SeverityError, Field: "spec.valueFrom.fieldPath"}},
+		},
+		{
+			name: "combine references unknown targets",
+			modify: func(spec *v1alpha1.MetricSpec) {
+				spec.Targets = []v1alpha1.NamedTarget{{Name: "ready", Target: spec.Target, LabelSelector: "ready=true"}}
+				spec.Combine = "100 * ready / target + missing"
+			},
+			want: []Finding{{Severity: SeverityError, Field: "spec.combine", Message: "combine expression references 'missing', which is neither 'target' nor the name of a target"}},
+		},
+		{
+			name: "invalid combine expression",
+			modify: func(spec *v1alpha1.MetricSpec) {
+				spec.Combine = "100 * (target"
+			},
+			want: []Finding{{Severity: SeverityError, Field: "spec.combine"}},
+		},
+		{
+			name: "preset of another kind",
+			modify: func(spec *v1alpha1.MetricSpec) {
+				spec.Preset = v1alpha1.PresetNodeCapacity
+			},
+			want: []Finding{{Severity: SeverityError, Field: "spec.target", Message: "preset 'NodeCapacity' requires a target of kind Node"}},
+		},
+		{
+			name: "kinds are checked against the cluster",
+			modify: func(spec *v1alpha1.MetricSpec) {
+				spec.Targets = []v1alpha1.NamedTarget{{Name: "buckets", Target: v1alpha1.MetricTarget{GroupVersionKind: v1alpha1.GroupVersionKind{Kind: "Bucket", Group: "s3.example.com", Version: "v1"}}}}
+				spec.Enrichments = []v1alpha1.Enrichment{{
+					Target:      v1alpha1.GroupVersionKind{Kind: "Node", Version: "v1"},
+					NameFrom:    "spec.nodeName",
+					Projections: []v1alpha1.Projection{{Name: "zone", FieldPath: "metadata.labels.zone"}},
+				}}
+				spec.Combine = "buckets"
+			},
+			opts: Options{Discovery: &fakediscovery.FakeDiscovery{Fake: &clienttesting.Fake{Resources: []*metav1.APIResourceList{{
+				GroupVersion: "v1",
+				APIResources: []metav1.APIResource{{Name: "pods", Kind: "Pod", Namespaced: true}},
+			}}}}},
+			want: []Finding{
+				{Severity: SeverityError, Field: "spec.enrichments[0].target", Message: "kind Node does not exist in group version v1"},
+				{Severity: SeverityError, Field: "spec.targets[0].target"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec := podSpec()
+			tt.modify(&spec)
+
+			got := MetricSpec(&spec, "spec", tt.opts)
+			require.Len(t, got, len(tt.want), "findings: %v", got)
+			for i, want := range tt.want {
+				require.Equal(t, want.Severity, got[i].Severity, got[i].String())
+				require.Equal(t, want.Field, got[i].Field, got[i].String())
+				if want.Message != "" {
+					require.Equal(t, want.Message, got[i].Message)
+				}
+			}
+			require.Equal(t, HasErrors(tt.want), HasErrors(got))
+		})
+	}
+}
+
+func TestManagedMetricSpec(t *testing.T) {
+	tests := []struct {
+		name string
+		spec v1alpha1.ManagedMetricSpec
+		want []Finding
+	}{
+		{
+			name: "valid spec with a partial target",
+			spec: v1alpha1.ManagedMetricSpec{
+				Target:     &v1alpha1.GroupVersionKind{Kind: "Bucket"},
+				Interval:   metav1.Duration{Duration: 5 * time.Minute},
+				Dimensions: []v1alpha1.Projection{{Name: "region", FieldPath: "spec.forProvider.region"}},
+			},
+		},
+		{
+			name: "invalid dimensions",
+			spec: v1alpha1.ManagedMetricSpec{
+				Dimensions: []v1alpha1.Projection{
+					{Name: "region", FieldPath: "spec.forProvider.region"},
+					{Name: "region", FieldPath: "status.atProvider.region"},
+					{Name: "tags", FieldPath: "spec.forProvider.tags[", Type: v1alpha1.TypeMap},
+				},
+			},
+			want: []Finding{
+				{Severity: SeverityError, Field: "spec.dimensions[1].name", Message: "duplicate projection name 'region'"},
+				{Severity: SeverityError, Field: "spec.dimensions[2].fieldPath"},
+			},
+		},
+		{
+			name: "selectors and interval",
+			spec: v1alpha1.ManagedMetricSpec{
+				Interval:      metav1.Duration{Duration: 10 * time.Second},
+				LabelSelector: "app in (",
+			},
+			want: []Finding{
+				{Severity: SeverityError, Field: "spec.interval", Message: "interval 10s is shorter than 1m0s"},
+				{Severity: SeverityError, Field: "spec.labelSelector"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ManagedMetricSpec(&tt.spec, "spec", Options{})
+			require.Len(t, got, len(tt.want), "findings: %v", got)
+			for i, want := range tt.want {
+				require.Equal(t, want.Field, got[i].Field, got[i].String())
+				if want.Message != "" {
+					require.Equal(t, want.Message, got[i].Message)
+				}
+			}
+		})
+	}
+}
+
+func TestManifests(t *testing.T) {
+	manifests := `
+apiVersion: metrics.openmcp.cloud/v1alpha1
+kind: Metric
+metadata:
+  name: pods
+  namespace: team-a
+spec:
+  name: pods
+  target:
+    kind: Pod
+    version: v1
+  interval: 5m
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+---
+apiVersion: metrics.openmcp.cloud/v1alpha1
+kind: Metric
+metadata:
+  name: typo
+spec:
+  name: typo
+  target:
+    kind: Pod
+    version: v1
+  labelSelectr: app=web
+---
+apiVersion: metrics.openmcp.cloud/v1alpha1
+kind: MetricSet
+metadata:
+  name: workloads
+spec:
+  template:
+    spec:
+      name: workloads
+      target:
+        kind: Deployment
+        group: apps
+        version: v1
+      labelSelector: "app in ("
+  targets:
+    - name: statefulsets
+      kind: StatefulSet
+      group: apps
+      version: v1
+`
+	results, err := Manifests(strings.NewReader(manifests), Options{})
+	require.NoError(t, err)
+	require.Len(t, results, 3)
+
+	require.Equal(t, "Metric team-a/pods", results[0].String())
+	require.Empty(t, results[0].Findings)
+
+	require.Equal(t, "Metric typo", results[1].String())
+	require.Len(t, results[1].Findings, 1)
+	require.Contains(t, results[1].Findings[0].Message, `unknown field "labelSelectr"`)
+
+	require.Equal(t, "MetricSet workloads", results[2].String())
+	require.Len(t, results[2].Findings, 1)
+	require.Equal(t, "spec.template.spec.labelSelector", results[2].Findings[0].Field)
+}
+
+func TestManifests_invalidYAML(t *testing.T) {
+	_, err := Manifests(strings.NewReader("kind: [Metric"), Options{})
+	require.Error(t, err)
+}
@@ -0,0 +1,108 @@
+package lint
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/yaml"
+
+	"github.com/openmcp-project/metrics-operator/api/v1alpha1"
+)
+
+// Result holds the findings of an object of a manifest
+type Result struct {
+	Kind      string
+	Namespace string
+	Name      string
+	Findings  []Finding
+}
+
+func (r Result) String() string {
+	if r.Namespace == "" {
+		return r.Kind + " " + r.Name
+	}
+	return r.Kind + " " + r.Namespace + "/" + r.Name
+}
+
+// Manifests decodes the YAML or JSON documents read from r and checks the Metrics, MetricSets and ManagedMetrics among them.
+// Other objects are skipped. Unknown fields of the checked objects are reported as errors, as the API server drops them.
+func Manifests(r io.Reader, opts Options) ([]Result, error) {
+	var results []Result
+	decoder := yaml.NewYAMLOrJSONDecoder(r, 4096)
+	for {
+		raw := json.RawMessage{}
+		if err := decoder.Decode(&raw); err != nil {
+			if errors.Is(err, io.EOF) {
+				return results, nil
+			}
+			return results, err
+		}
+		if len(bytes.TrimSpace(raw)) == 0 || bytes.Equal(bytes.TrimSpace(raw), []byte("null")) {
+			continue
+		}
+
+		typeMeta := metav1.TypeMeta{}
+		if err := json.Unmarshal(raw, &typeMeta); err != nil {
+			return results, fmt.Errorf("failed to decode object: %w", err)
+		}
+		if typeMeta.APIVersion != v1alpha1.GroupVersion.String() {
+			continue
+		}
+
+		switch typeMeta.Kind {
+		case "Metric":
+			metric := v1alpha1.Metric{}
+			result := decodeStrict(raw, &metric, typeMeta.Kind)
+			result.Namespace, result.Name = metric.Namespace, metric.Name
+			if !HasErrors(result.Findings) {
+				result.Findings = append(result.Findings, MetricSpec(&metric.Spec, "spec", opts)...)
+			}
+			results = append(results, result)
+		case "ManagedMetric":
+			metric := v1alpha1.ManagedMetric{}
+			result := decodeStrict(raw, &metric, typeMeta.Kind)
+			result.Namespace, result.Name = metric.Namespace, metric.Name
+			if !HasErrors(result.Findings) {
+				result.Findings = append(result.Findings, ManagedMetricSpec(&metric.Spec, "spec", opts)...)
+			}
+			results = append(results, result)
+		case "MetricSet":
+			set := v1alpha1.MetricSet{}
+			result := decodeStrict(raw, &set, typeMeta.Kind)
+			result.Namespace, result.Name = set.Namespace, set.Name
+			if !HasErrors(result.Findings) {
+				result.Findings = append(result.Findings, metricSet(&set, opts)...)
+			}
+			results = append(results, result)
+		}
+	}
+}
+
+// decodeStrict decodes the object and reports a field that is not part of the API as error
+func decodeStrict(raw []byte, obj any, kind string) Result {
+	result := Result{Kind: kind}
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(obj); err != nil {
+		// decode the known fields, so the result is named
+		_ = json.Unmarshal(raw, obj)
+		result.Findings = append(result.Findings, Finding{Severity: SeverityError, Field: kind, Message: err.Error()})
+	}
+	return result
+}
+
+// metricSet checks the template of a MetricSet with the kind of each of its targets
+func metricSet(set *v1alpha1.MetricSet, opts Options) []Finding {
+	findings := MetricSpec(&set.Spec.Template.Spec, "spec.template.spec", opts)
+	l := &linter{opts: opts}
+	for i, target := range set.Spec.Targets {
+		if target.Kind != "" {
+			l.gvk(target.GroupVersionKind, fmt.Sprintf("spec.targets[%d]", i))
+		}
+	}
+	return append(findings, l.findings...)
+}
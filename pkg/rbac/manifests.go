@@ -0,0 +1,57 @@
+package rbac
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/yaml"
+
+	"github.com/openmcp-project/metrics-operator/api/v1alpha1"
+)
+
+// ManifestKinds decodes the YAML or JSON documents read from r and returns the kinds listed by the Metrics and MetricSets among them.
+// Other objects are skipped.
+func ManifestKinds(r io.Reader) ([]schema.GroupVersionKind, error) {
+	var kinds []schema.GroupVersionKind
+	decoder := yaml.NewYAMLOrJSONDecoder(r, 4096)
+	for {
+		raw := json.RawMessage{}
+		if err := decoder.Decode(&raw); err != nil {
+			if errors.Is(err, io.EOF) {
+				return kinds, nil
+			}
+			return kinds, err
+		}
+		if len(bytes.TrimSpace(raw)) == 0 || bytes.Equal(bytes.TrimSpace(raw), []byte("null")) {
+			continue
+		}
+
+		typeMeta := metav1.TypeMeta{}
+		if err := json.Unmarshal(raw, &typeMeta); err != nil {
+			return kinds, fmt.Errorf("failed to decode object: %w", err)
+		}
+		if typeMeta.APIVersion != v1alpha1.GroupVersion.String() {
+			continue
+		}
+
+		switch typeMeta.Kind {
+		case "Metric":
+			metric := v1alpha1.Metric{}
+			if err := json.Unmarshal(raw, &metric); err != nil {
+				return kinds, fmt.Errorf("failed to decode Metric: %w", err)
+			}
+			kinds = append(kinds, MetricKinds(&metric.Spec)...)
+		case "MetricSet":
+			set := v1alpha1.MetricSet{}
+			if err := json.Unmarshal(raw, &set); err != nil {
+				return kinds, fmt.Errorf("failed to decode MetricSet: %w", err)
+			}
+			kinds = append(kinds, MetricSetKinds(&set)...)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+// Package rbac derives the RBAC rules the operator needs to collect Metrics from the resources they target,
+// so the operator can be granted access to exactly those resources instead of all resources of the cluster.
+// It is used by the render-rbac command of the operator.
+package rbac
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+
+	"github.com/openmcp-project/metrics-operator/api/v1alpha1"
+	"github.com/openmcp-project/metrics-operator/internal/orchestrator"
+)
+
+// Verbs are granted on the target resources
+var Verbs = []string{"list", "watch"}
+
+// namespaceKind is listed by metrics with a namespace selector
+var namespaceKind = schema.GroupVersionKind{Version: "v1", Kind: "Namespace"}
+
+// Resolver returns the resource of a kind
+type Resolver func(gvk schema.GroupVersionKind) (schema.GroupVersionResource, error)
+
+// GuessResolver derives the resource from the kind without a cluster, e.g. "NetworkPolicy" becomes "networkpolicies".
+// Resources that are not named after the plural of their kind need a DiscoveryResolver.
+func GuessResolver(gvk schema.GroupVersionKind) (schema.GroupVersionResource, error) {
+	gvr, _ := meta.UnsafeGuessKindToResource(gvk)
+	return gvr, nil
+}
+
+// DiscoveryResolver looks up the resources of the kinds in the cluster
+func DiscoveryResolver(disco discovery.DiscoveryInterface) Resolver {
+	return func(gvk schema.GroupVersionKind) (schema.GroupVersionResource, error) {
+		gvr, err := orchestrator.GetGVRfromGVK(gvk, disco)
+		if err != nil {
+			return gvr, fmt.Errorf("group version %s is not served by the cluster: %w", gvk.GroupVersion(), err)
+		}
+		if gvr.Resource == "" {
+			return gvr, fmt.Errorf("kind %s does not exist in group version %s", gvk.Kind, gvk.GroupVersion())
+		}
+		return gvr, nil
+	}
+}
+
+// MetricKinds returns the kinds a Metric with the spec lists: its targets, the targets of its enrichments,
+// and namespaces if it selects the namespaces of its target. Metrics of remote clusters list none in the local cluster.
+func MetricKinds(spec *v1alpha1.MetricSpec) []schema.GroupVersionKind {
+	if spec.RemoteClusterAccessRef != nil {
+		return nil
+	}
+	kinds := []schema.GroupVersionKind{spec.Target.GVK()}
+	if spec.Target.NamespaceSelector != nil {
+		kinds = append(kinds, namespaceKind)
+	}
+	for _, target := range spec.Targets {
+		kinds = append(kinds, target.Target.GVK())
+		if target.Target.NamespaceSelector != nil {
+			kinds = append(kinds, namespaceKind)
+		}
+	}
+	for _, enrichment := range spec.Enrichments {
+		kinds = append(kinds, enrichment.Target.GVK())
+	}
+	return kinds
+}
+
+// MetricSetKinds returns the kinds the Metrics generated by the MetricSet list
+func MetricSetKinds(set *v1alpha1.MetricSet) []schema.GroupVersionKind {
+	template := set.Spec.Template.Spec
+	if template.RemoteClusterAccessRef != nil {
+		return nil
+	}
+	// the target of the template is only used by targets that do not override the kind
+	kinds := MetricKinds(&template)[1:]
+	for _, target := range set.Spec.Targets {
+		if target.Kind != "" {
+			kinds = append(kinds, target.GVK())
+		} else {
+			kinds = append(kinds, template.Target.GVK())
+		}
+	}
+	return kinds
+}
+
+// Rules returns one rule per API group that grants the verbs on the resources of the kinds.
+// Kinds without a kind or version are skipped, the rules and their resources are sorted.
+func Rules(kinds []schema.GroupVersionKind, resolve Resolver) ([]rbacv1.PolicyRule, error) {
+	resources := map[string]map[string]bool{}
+	for _, gvk := range kinds {
+		if gvk.Kind == "" || gvk.Version == "" {
+			continue
+		}
+		gvr, err := resolve(gvk)
+		if err != nil {
+			return nil, err
+		}
+		if resources[gvr.Group] == nil {
+			resources[gvr.Group] = map[string]bool{}
+		}
+		resources[gvr.Group][gvr.Resource] = true
+	}
+
+	rules := make([]rbacv1.PolicyRule, 0, len(resources))
+	for _, group := range slices.Sorted(maps.Keys(resources)) {
+		rules = append(rules, rbacv1.PolicyRule{
+			APIGroups: []string{group},
+			Resources: slices.Sorted(maps.Keys(resources[group])),
+			Verbs:     slices.Clone(Verbs),
+		})
+	}
+	return rules, nil
+}
+
+// ClusterRole returns a ClusterRole with the name and rules
+func ClusterRole(name string, rules []rbacv1.PolicyRule) *rbacv1.ClusterRole {
+	return &rbacv1.ClusterRole{
+		TypeMeta: metav1.TypeMeta{APIVersion: rbacv1.SchemeGroupVersion.String(), Kind: "ClusterRole"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: map[string]string{"app.kubernetes.io/managed-by": "metrics-operator"},
+		},
+		Rules: rules,
+	}
+}
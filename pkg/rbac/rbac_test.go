@@ -0,0 +1,134 @@
+package rbac
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	clienttesting "k8s.io/client-go/testing"
+
+	"github.com/openmcp-project/metrics-operator/api/v1alpha1"
+)
+
+func TestMetricKinds(t *testing.T) {
+	deployments := v1alpha1.GroupVersionKind{Kind: "Deployment", Group: "apps", Version: "v1"}
+	tests := []struct {
+		name string
+		spec v1alpha1.MetricSpec
+		want []schema.GroupVersionKind
+	}{
+		{
+			name: "target",
+			spec: v1alpha1.MetricSpec{Target: v1alpha1.MetricTarget{GroupVersionKind: deployments}},
+			want: []schema.GroupVersionKind{deployments.GVK()},
+		},
+		{
+			name: "namespace selector, targets and enrichments",
+			spec: v1alpha1.MetricSpec{
+				Target:      v1alpha1.MetricTarget{GroupVersionKind: deployments, NamespaceSelector: &metav1.LabelSelector{}},
+				Targets:     []v1alpha1.NamedTarget{{Name: "pods", Target: v1alpha1.MetricTarget{GroupVersionKind: v1alpha1.GroupVersionKind{Kind: "Pod", Version: "v1"}}}},
+				Enrichments: []v1alpha1.Enrichment{{Target: v1alpha1.GroupVersionKind{Kind: "Node", Version: "v1"}}},
+			},
+			want: []schema.GroupVersionKind{
+				deployments.GVK(),
+				{Version: "v1", Kind: "Namespace"},
+				{Version: "v1", Kind: "Pod"},
+				{Version: "v1", Kind: "Node"},
+			},
+		},
+		{
+			name: "remote cluster",
+			spec: v1alpha1.MetricSpec{
+				Target:                 v1alpha1.MetricTarget{GroupVersionKind: deployments},
+				RemoteClusterAccessRef: &v1alpha1.RemoteClusterAccessRef{Name: "remote"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, MetricKinds(&tt.spec))
+		})
+	}
+}
+
+func TestRules(t *testing.T) {
+	kinds := []schema.GroupVersionKind{
+		{Group: "apps", Version: "v1", Kind: "StatefulSet"},
+		{Version: "v1", Kind: "Pod"},
+		{Group: "apps", Version: "v1", Kind: "Deployment"},
+		{Version: "v1", Kind: "Pod"},
+		{Kind: "Incomplete"},
+	}
+	want := []rbacv1.PolicyRule{
+		{APIGroups: []string{""}, Resources: []string{"pods"}, Verbs: []string{"list", "watch"}},
+		{APIGroups: []string{"apps"}, Resources: []string{"deployments", "statefulsets"}, Verbs: []string{"list", "watch"}},
+	}
+
+	rules, err := Rules(kinds, GuessResolver)
+	require.NoError(t, err)
+	require.Equal(t, want, rules)
+}
+
+func TestRules_discovery(t *testing.T) {
+	disco := &fakediscovery.FakeDiscovery{Fake: &clienttesting.Fake{Resources: []*metav1.APIResourceList{{
+		GroupVersion: "example.com/v1",
+		APIResources: []metav1.APIResource{{Name: "buckets-v1", Kind: "Bucket"}},
+	}}}}
+
+	rules, err := Rules([]schema.GroupVersionKind{{Group: "example.com", Version: "v1", Kind: "Bucket"}}, DiscoveryResolver(disco))
+	require.NoError(t, err)
+	require.Equal(t, []string{"buckets-v1"}, rules[0].Resources)
+
+	_, err = Rules([]schema.GroupVersionKind{{Group: "example.com", Version: "v1", Kind: "Queue"}}, DiscoveryResolver(disco))
+	require.ErrorContains(t, err, "kind Queue does not exist in group version example.com/v1")
+}
+
+func TestManifestKinds(t *testing.T) {
+	manifests := `
+apiVersion: metrics.openmcp.cloud/v1alpha1
+kind: Metric
+metadata:
+  name: pods
+spec:
+  name: pods
+  target:
+    kind: Pod
+    version: v1
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+---
+apiVersion: metrics.openmcp.cloud/v1alpha1
+kind: MetricSet
+metadata:
+  name: workloads
+spec:
+  template:
+    spec:
+      name: workloads
+      target:
+        kind: Deployment
+        group: apps
+        version: v1
+  targets:
+    - name: statefulsets
+      kind: StatefulSet
+      group: apps
+      version: v1
+    - name: team-a
+      namespaces: [team-a]
+`
+	kinds, err := ManifestKinds(strings.NewReader(manifests))
+	require.NoError(t, err)
+	require.Equal(t, []schema.GroupVersionKind{
+		{Version: "v1", Kind: "Pod"},
+		{Group: "apps", Version: "v1", Kind: "StatefulSet"},
+		{Group: "apps", Version: "v1", Kind: "Deployment"},
+	}, kinds)
+}
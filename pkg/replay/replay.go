@@ -0,0 +1,136 @@
+// Package replay requests the immediate export of metrics regardless of their interval or schedule,
+// e.g. to fill the gaps an outage of a data sink left. It is used by the replay command of the operator.
+package replay
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/openmcp-project/metrics-operator/api/v1alpha1"
+)
+
+// Kinds are the kinds of metrics that can be replayed
+var Kinds = []string{"Metric", "ManagedMetric", "FederatedMetric", "FederatedManagedMetric", "CompositeMetric"}
+
+// newList returns an empty list of the metrics of a kind
+func newList(kind string) client.ObjectList {
+	switch kind {
+	case "Metric":
+		return &v1alpha1.MetricList{}
+	case "ManagedMetric":
+		return &v1alpha1.ManagedMetricList{}
+	case "FederatedMetric":
+		return &v1alpha1.FederatedMetricList{}
+	case "FederatedManagedMetric":
+		return &v1alpha1.FederatedManagedMetricList{}
+	case "CompositeMetric":
+		return &v1alpha1.CompositeMetricList{}
+	}
+	return nil
+}
+
+// lastExport returns the time a metric was last collected, or the zero time if it never was
+func lastExport(obj client.Object) time.Time {
+	switch metric := obj.(type) {
+	case *v1alpha1.Metric:
+		return metric.Status.Observation.Timestamp.Time
+	case *v1alpha1.ManagedMetric:
+		return metric.Status.Observation.Timestamp.Time
+	case *v1alpha1.CompositeMetric:
+		return metric.Status.Observation.Timestamp.Time
+	case *v1alpha1.FederatedMetric:
+		if metric.Status.LastReconcileTime != nil {
+			return metric.Status.LastReconcileTime.Time
+		}
+	case *v1alpha1.FederatedManagedMetric:
+		if metric.Status.LastReconcileTime != nil {
+			return metric.Status.LastReconcileTime.Time
+		}
+	}
+	return time.Time{}
+}
+
+// Options select the metrics to replay
+type Options struct {
+	// Kinds of the metrics, all Kinds if empty
+	Kinds []string
+	// Namespace of the metrics, all namespaces if empty
+	Namespace string
+	// Selector on the labels of the metrics, all metrics if nil
+	Selector labels.Selector
+	// Since selects the metrics collected within this duration before now, whose exports may have been lost.
+	// All metrics are selected if it is zero.
+	Since time.Duration
+	// DryRun returns the selected metrics without requesting the replay
+	DryRun bool
+}
+
+// Metric identifies a replayed metric
+type Metric struct {
+	Kind      string
+	Namespace string
+	Name      string
+}
+
+// String returns the kind, namespace and name of the metric
+func (m Metric) String() string {
+	return fmt.Sprintf("%s %s/%s", m.Kind, m.Namespace, m.Name)
+}
+
+// Request sets the replay annotation to now on the selected metrics, the operator exports them right away.
+// It returns the selected metrics.
+func Request(ctx context.Context, cli client.Client, opts Options, now time.Time) ([]Metric, error) {
+	kinds := opts.Kinds
+	if len(kinds) == 0 {
+		kinds = Kinds
+	}
+	for _, kind := range kinds {
+		if !slices.Contains(Kinds, kind) {
+			return nil, fmt.Errorf("unknown kind %q, expected one of %v", kind, Kinds)
+		}
+	}
+	listOpts := []client.ListOption{client.InNamespace(opts.Namespace)}
+	if opts.Selector != nil {
+		listOpts = append(listOpts, client.MatchingLabelsSelector{Selector: opts.Selector})
+	}
+	replay := now.UTC().Format(time.RFC3339)
+
+	var replayed []Metric
+	for _, kind := range kinds {
+		list := newList(kind)
+		if err := cli.List(ctx, list, listOpts...); err != nil {
+			return replayed, fmt.Errorf("failed to list %ss: %w", kind, err)
+		}
+		items, err := meta.ExtractList(list)
+		if err != nil {
+			return replayed, err
+		}
+		for _, item := range items {
+			obj := item.(client.Object)
+			if opts.Since > 0 && lastExport(obj).Before(now.Add(-opts.Since)) {
+				continue
+			}
+			metric := Metric{Kind: kind, Namespace: obj.GetNamespace(), Name: obj.GetName()}
+			if !opts.DryRun {
+				patch := client.MergeFrom(obj.DeepCopyObject().(client.Object))
+				annotations := obj.GetAnnotations()
+				if annotations == nil {
+					annotations = map[string]string{}
+				}
+				annotations[v1alpha1.ReplayAnnotation] = replay
+				obj.SetAnnotations(annotations)
+				if err := cli.Patch(ctx, obj, patch); err != nil {
+					return replayed, fmt.Errorf("failed to request the replay of %s: %w", metric, err)
+				}
+			}
+			replayed = append(replayed, metric)
+		}
+	}
+	return replayed, nil
+}
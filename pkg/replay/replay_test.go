@@ -0,0 +1,107 @@
+package replay
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/openmcp-project/metrics-operator/api/v1alpha1"
+)
+
+func TestRequest(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, v1alpha1.AddToScheme(scheme))
+	now := time.Date(2024, time.May, 15, 12, 0, 0, 0, time.UTC)
+
+	metric := func(namespace, name string, exported time.Time, labels map[string]string) *v1alpha1.Metric {
+		return &v1alpha1.Metric{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name, Labels: labels},
+			Status:     v1alpha1.MetricStatus{Observation: v1alpha1.MetricObservation{Timestamp: metav1.NewTime(exported)}},
+		}
+	}
+	federated := &v1alpha1.FederatedMetric{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "fleet"},
+		Status:     v1alpha1.FederatedMetricStatus{LastReconcileTime: &metav1.Time{Time: now.Add(-time.Hour)}},
+	}
+	objects := []client.Object{
+		metric("default", "pods", now.Add(-time.Hour), map[string]string{"team": "a"}),
+		metric("default", "daily", now.Add(-20*time.Hour), map[string]string{"team": "a"}),
+		metric("default", "never", time.Time{}, map[string]string{"team": "a"}),
+		metric("other", "pods", now.Add(-time.Hour), map[string]string{"team": "b"}),
+		federated,
+	}
+
+	testCases := []struct {
+		name     string
+		opts     Options
+		expected []Metric
+	}{
+		{
+			name: "All",
+			expected: []Metric{
+				{Kind: "Metric", Namespace: "default", Name: "daily"},
+				{Kind: "Metric", Namespace: "default", Name: "never"},
+				{Kind: "Metric", Namespace: "default", Name: "pods"},
+				{Kind: "Metric", Namespace: "other", Name: "pods"},
+				{Kind: "FederatedMetric", Namespace: "default", Name: "fleet"},
+			},
+		},
+		{
+			name: "Since",
+			opts: Options{Since: 2 * time.Hour},
+			expected: []Metric{
+				{Kind: "Metric", Namespace: "default", Name: "pods"},
+				{Kind: "Metric", Namespace: "other", Name: "pods"},
+				{Kind: "FederatedMetric", Namespace: "default", Name: "fleet"},
+			},
+		},
+		{
+			name:     "KindNamespaceAndSelector",
+			opts:     Options{Kinds: []string{"Metric"}, Namespace: "default", Selector: labels.SelectorFromSet(labels.Set{"team": "a"}), Since: 2 * time.Hour},
+			expected: []Metric{{Kind: "Metric", Namespace: "default", Name: "pods"}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cli := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build()
+
+			replayed, err := Request(context.Background(), cli, tc.opts, now)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, replayed)
+
+			updated := &v1alpha1.Metric{}
+			require.NoError(t, cli.Get(context.Background(), client.ObjectKey{Namespace: "default", Name: "pods"}, updated))
+			require.Equal(t, "2024-05-15T12:00:00Z", updated.Annotations[v1alpha1.ReplayAnnotation])
+		})
+	}
+}
+
+func TestRequestDryRun(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, v1alpha1.AddToScheme(scheme))
+	cli := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&v1alpha1.ManagedMetric{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "managed"}},
+	).Build()
+
+	replayed, err := Request(context.Background(), cli, Options{DryRun: true}, time.Now())
+	require.NoError(t, err)
+	require.Equal(t, []Metric{{Kind: "ManagedMetric", Namespace: "default", Name: "managed"}}, replayed)
+
+	managed := &v1alpha1.ManagedMetric{}
+	require.NoError(t, cli.Get(context.Background(), client.ObjectKey{Namespace: "default", Name: "managed"}, managed))
+	require.Empty(t, managed.Annotations)
+}
+
+func TestRequestUnknownKind(t *testing.T) {
+	cli := fake.NewClientBuilder().Build()
+	_, err := Request(context.Background(), cli, Options{Kinds: []string{"Pod"}}, time.Now())
+	require.ErrorContains(t, err, `unknown kind "Pod"`)
+}
@@ -0,0 +1,137 @@
+//go:build e2e
+
+package e2e
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	collectorNamespace = "e2e-collector"
+	collectorSelector  = "app=otel-collector"
+	// collectorOutput is the container printing the metrics written by the file exporter of the collector
+	collectorOutput = "output"
+)
+
+// exportedPoint is a data point of a gauge received by the collector
+type exportedPoint struct {
+	Metric     string
+	Dimensions map[string]string
+	Value      int64
+	Time       time.Time
+}
+
+// otlpRequest is the OTLP JSON encoding of the export requests written by the file exporter,
+// reduced to the fields of the gauges exported by the operator
+type otlpRequest struct {
+	ResourceMetrics []struct {
+		ScopeMetrics []struct {
+			Metrics []struct {
+				Name  string `json:"name"`
+				Gauge *struct {
+					DataPoints []struct {
+						Attributes []struct {
+							Key   string `json:"key"`
+							Value struct {
+								StringValue string `json:"stringValue"`
+							} `json:"value"`
+						} `json:"attributes"`
+						TimeUnixNano string `json:"timeUnixNano"`
+						AsInt        string `json:"asInt"`
+					} `json:"dataPoints"`
+				} `json:"gauge"`
+			} `json:"metrics"`
+		} `json:"scopeMetrics"`
+	} `json:"resourceMetrics"`
+}
+
+// exportedPoints returns the data points received by the collector so far, in the order they were received
+func exportedPoints(ctx context.Context, clientset kubernetes.Interface) ([]exportedPoint, error) {
+	pods, err := clientset.CoreV1().Pods(collectorNamespace).List(ctx, metav1.ListOptions{LabelSelector: collectorSelector})
+	if err != nil {
+		return nil, err
+	}
+	if len(pods.Items) != 1 {
+		return nil, fmt.Errorf("expected one collector pod, found %d", len(pods.Items))
+	}
+	logs, err := clientset.CoreV1().Pods(collectorNamespace).GetLogs(pods.Items[0].Name, &corev1.PodLogOptions{Container: collectorOutput}).Stream(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = logs.Close() }()
+
+	var points []exportedPoint
+	scanner := bufio.NewScanner(logs)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		// tail reports the file missing until the first export, those lines are skipped
+		if !strings.HasPrefix(scanner.Text(), "{") {
+			continue
+		}
+		var request otlpRequest
+		if err := json.Unmarshal(scanner.Bytes(), &request); err != nil {
+			return nil, fmt.Errorf("failed to parse the output of the collector: %w", err)
+		}
+		points = append(points, request.points()...)
+	}
+	return points, scanner.Err()
+}
+
+// points returns the data points of the gauges of the request
+func (r *otlpRequest) points() []exportedPoint {
+	var points []exportedPoint
+	for _, rm := range r.ResourceMetrics {
+		for _, sm := range rm.ScopeMetrics {
+			for _, m := range sm.Metrics {
+				if m.Gauge == nil {
+					continue
+				}
+				for _, dp := range m.Gauge.DataPoints {
+					point := exportedPoint{Metric: m.Name, Dimensions: map[string]string{}}
+					for _, attribute := range dp.Attributes {
+						point.Dimensions[attribute.Key] = attribute.Value.StringValue
+					}
+					point.Value, _ = strconv.ParseInt(dp.AsInt, 10, 64)
+					if nanos, err := strconv.ParseInt(dp.TimeUnixNano, 10, 64); err == nil {
+						point.Time = time.Unix(0, nanos)
+					}
+					points = append(points, point)
+				}
+			}
+		}
+	}
+	return points
+}
+
+// latest returns the last data point of every series of the metric received after since, by their dimensions
+func latest(points []exportedPoint, metric string, since time.Time) map[string]exportedPoint {
+	series := map[string]exportedPoint{}
+	for _, point := range points {
+		if point.Metric != metric || point.Time.Before(since) {
+			continue
+		}
+		series[seriesKey(point.Dimensions)] = point
+	}
+	return series
+}
+
+// seriesKey identifies a series by its dimensions, e.g. "app=web,cluster=member"
+func seriesKey(dimensions map[string]string) string {
+	keys := make([]string, 0, len(dimensions))
+	for key, value := range dimensions {
+		keys = append(keys, key+"="+value)
+	}
+	slices.Sort(keys)
+	return strings.Join(keys, ",")
+}
@@ -0,0 +1,337 @@
+//go:build e2e
+
+// Package e2e tests the operator deployed into a kind cluster end to end, by asserting on the series received
+// by an OpenTelemetry collector. The cluster is set up by `task e2e`, see test/e2e/manifests for the collector.
+package e2e
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/openmcp-project/metrics-operator/api/v1alpha1"
+)
+
+const (
+	operatorNamespace = "metrics-operator-system"
+	operatorSelector  = "app.kubernetes.io/name=metrics-operator"
+	targetNamespace   = "e2e-targets"
+	targetLabel       = "e2e.metrics.openmcp.cloud/target"
+	memberLabel       = "e2e.metrics.openmcp.cloud/member"
+	collectorToken    = "e2e-token"
+
+	// exportTimeout is how long a series may take to arrive at the collector, more than the interval of the metrics
+	exportTimeout = 3 * time.Minute
+	pollInterval  = 5 * time.Second
+)
+
+// suite holds the clients of the kind cluster the operator is deployed into
+type suite struct {
+	client    client.Client
+	clientset kubernetes.Interface
+	start     time.Time
+}
+
+func TestE2E(t *testing.T) {
+	ctx := context.Background()
+	s := newSuite(t)
+	s.setupDataSink(ctx, t)
+	s.setupTargets(ctx, t)
+
+	// the scenarios build on each other and run in order
+	t.Run("Metric", s.testMetric)
+	t.Run("ManagedMetric", s.testManagedMetric)
+	t.Run("FederatedMetric", s.testFederatedMetric)
+	t.Run("OperatorRestart", s.testOperatorRestart)
+	t.Run("SecretRotation", s.testSecretRotation)
+}
+
+func newSuite(t *testing.T) *suite {
+	cfg, err := ctrl.GetConfig()
+	require.NoError(t, err, "the e2e tests need the kubeconfig of the kind cluster, run them with `task e2e`")
+
+	scheme := runtime.NewScheme()
+	require.NoError(t, clientgoscheme.AddToScheme(scheme))
+	require.NoError(t, v1alpha1.AddToScheme(scheme))
+	c, err := client.New(cfg, client.Options{Scheme: scheme})
+	require.NoError(t, err)
+	clientset, err := kubernetes.NewForConfig(cfg)
+	require.NoError(t, err)
+
+	return &suite{client: c, clientset: clientset, start: time.Now()}
+}
+
+// setupDataSink creates the default DataSink exporting to the collector with its API token
+func (s *suite) setupDataSink(ctx context.Context, t *testing.T) {
+	s.create(ctx, t, &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "collector-token", Namespace: operatorNamespace},
+		StringData: map[string]string{"api-token": collectorToken},
+	})
+	s.create(ctx, t, &v1alpha1.DataSink{
+		ObjectMeta: metav1.ObjectMeta{Name: "default", Namespace: operatorNamespace},
+		Spec: v1alpha1.DataSinkSpec{
+			Connection: v1alpha1.Connection{Endpoint: "http://otel-collector.e2e-collector.svc:4318/v1/metrics"},
+			Authentication: &v1alpha1.Authentication{APIKey: &v1alpha1.APIKeyAuthentication{
+				SecretKeyRef: corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "collector-token"}, Key: "api-token"},
+			}},
+		},
+	})
+	s.requireDataSinkReady(ctx, t, metav1.ConditionTrue, "")
+}
+
+// setupTargets creates the ConfigMaps counted by the metrics and the Widgets observed by the ManagedMetric
+func (s *suite) setupTargets(ctx context.Context, t *testing.T) {
+	s.create(ctx, t, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: targetNamespace}})
+	for name, app := range map[string]string{"web-a": "web", "web-b": "web", "db": "db"} {
+		s.create(ctx, t, &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: targetNamespace,
+			Labels:    map[string]string{targetLabel: "true", "app": app},
+		}})
+	}
+	for name, ready := range map[string]string{"ready": "True", "failing": "False"} {
+		widget := &unstructured.Unstructured{Object: map[string]any{
+			"apiVersion": "e2e.metrics.openmcp.cloud/v1alpha1",
+			"kind":       "Widget",
+			"metadata":   map[string]any{"name": name},
+			"status": map[string]any{"conditions": []any{
+				map[string]any{"type": "Ready", "status": ready},
+				map[string]any{"type": "Synced", "status": "True"},
+			}},
+		}}
+		s.create(ctx, t, widget)
+	}
+}
+
+func (s *suite) testMetric(t *testing.T) {
+	ctx := context.Background()
+	s.create(ctx, t, &v1alpha1.Metric{
+		ObjectMeta: metav1.ObjectMeta{Name: "e2e-configmaps", Namespace: targetNamespace},
+		Spec: v1alpha1.MetricSpec{
+			Name:          "e2e_configmaps",
+			Target:        v1alpha1.MetricTarget{GroupVersionKind: v1alpha1.GroupVersionKind{Kind: "ConfigMap", Version: "v1"}, Namespaces: []string{targetNamespace}},
+			LabelSelector: targetLabel + "=true",
+			Interval:      metav1.Duration{Duration: time.Minute},
+			Projections:   []v1alpha1.Projection{{Name: "app", FieldPath: "metadata.labels.app"}},
+		},
+	})
+
+	s.requireSeries(t, "e2e_configmaps", s.start, map[string]string{"resource": "ConfigMap", "version": "v1", "app": "web"}, 2)
+	s.requireSeries(t, "e2e_configmaps", s.start, map[string]string{"resource": "ConfigMap", "version": "v1", "app": "db"}, 1)
+}
+
+func (s *suite) testManagedMetric(t *testing.T) {
+	ctx := context.Background()
+	s.create(ctx, t, &v1alpha1.ManagedMetric{
+		ObjectMeta: metav1.ObjectMeta{Name: "e2e-widgets", Namespace: targetNamespace},
+		Spec: v1alpha1.ManagedMetricSpec{
+			Name:     "e2e_widgets",
+			Target:   &v1alpha1.GroupVersionKind{Kind: "Widget", Group: "e2e.metrics.openmcp.cloud", Version: "v1alpha1"},
+			Interval: metav1.Duration{Duration: time.Minute},
+		},
+	})
+
+	widget := map[string]string{"kind": "Widget", "group": "e2e.metrics.openmcp.cloud", "version": "v1alpha1", "synced": "true"}
+	s.requireSeries(t, "e2e_widgets", s.start, with(widget, "ready", "true"), 1)
+	s.requireSeries(t, "e2e_widgets", s.start, with(widget, "ready", "false"), 1)
+}
+
+func (s *suite) testFederatedMetric(t *testing.T) {
+	ctx := context.Background()
+	s.createMember(ctx, t, "member-a")
+	s.create(ctx, t, &v1alpha1.FederatedClusterAccess{
+		ObjectMeta: metav1.ObjectMeta{Name: "e2e-members", Namespace: targetNamespace},
+		Spec: v1alpha1.FederatedClusterAccessSpec{
+			SecretSelector: &v1alpha1.KubeConfigSecretSelector{
+				Selector: metav1.LabelSelector{MatchLabels: map[string]string{memberLabel: "true"}},
+			},
+			ClusterNameFrom: v1alpha1.ClusterNameFromMember,
+		},
+	})
+	s.create(ctx, t, &v1alpha1.FederatedMetric{
+		ObjectMeta: metav1.ObjectMeta{Name: "e2e-federated-configmaps", Namespace: targetNamespace},
+		Spec: v1alpha1.FederatedMetricSpec{
+			Name:                      "e2e_federated_configmaps",
+			Target:                    v1alpha1.GroupVersionKind{Kind: "ConfigMap", Version: "v1"},
+			LabelSelector:             targetLabel + "=true",
+			Interval:                  metav1.Duration{Duration: time.Minute},
+			Projections:               []v1alpha1.Projection{{Name: "app", FieldPath: "metadata.labels.app"}},
+			FederatedClusterAccessRef: v1alpha1.FederateClusterAccessRef{Name: "e2e-members", Namespace: targetNamespace},
+		},
+	})
+
+	s.requireSeries(t, "e2e_federated_configmaps", s.start, map[string]string{"cluster": "member-a", "resource": "ConfigMap", "app": "web"}, 2)
+	s.requireSeries(t, "e2e_federated_configmaps", s.start, map[string]string{"cluster": "member-a", "resource": "ConfigMap", "app": "db"}, 1)
+}
+
+// testOperatorRestart deletes the pod of the operator and expects the series to be exported again by its replacement
+func (s *suite) testOperatorRestart(t *testing.T) {
+	ctx := context.Background()
+	pods, err := s.clientset.CoreV1().Pods(operatorNamespace).List(ctx, metav1.ListOptions{LabelSelector: operatorSelector})
+	require.NoError(t, err)
+	require.NotEmpty(t, pods.Items, "the operator is not deployed")
+	restart := time.Now()
+	for _, pod := range pods.Items {
+		require.NoError(t, s.clientset.CoreV1().Pods(operatorNamespace).Delete(ctx, pod.Name, metav1.DeleteOptions{}))
+	}
+
+	require.EventuallyWithT(t, func(c *assert.CollectT) {
+		pods, err := s.clientset.CoreV1().Pods(operatorNamespace).List(ctx, metav1.ListOptions{LabelSelector: operatorSelector})
+		require.NoError(c, err)
+		ready := 0
+		for _, pod := range pods.Items {
+			if pod.CreationTimestamp.After(restart.Add(-time.Second)) && podReady(&pod) {
+				ready++
+			}
+		}
+		assert.Equal(c, 1, ready, "the replacement of the operator pod is not ready")
+	}, exportTimeout, pollInterval)
+
+	s.requireSeries(t, "e2e_configmaps", restart, map[string]string{"app": "web"}, 2)
+	s.requireSeries(t, "e2e_widgets", restart, map[string]string{"ready": "true"}, 1)
+	s.requireSeries(t, "e2e_federated_configmaps", restart, map[string]string{"cluster": "member-a", "app": "web"}, 2)
+}
+
+// testSecretRotation replaces the API token of the DataSink with one the collector rejects and restores it,
+// the operator has to pick up both without a restart
+func (s *suite) testSecretRotation(t *testing.T) {
+	ctx := context.Background()
+	s.rotateToken(ctx, t, "revoked-token")
+	s.requireDataSinkReady(ctx, t, metav1.ConditionFalse, "Unauthorized")
+
+	rotation := time.Now()
+	s.rotateToken(ctx, t, collectorToken)
+	s.requireDataSinkReady(ctx, t, metav1.ConditionTrue, "")
+	s.requireSeries(t, "e2e_configmaps", rotation, map[string]string{"app": "db"}, 1)
+}
+
+// createMember creates a ServiceAccount that may read the cluster and a Secret labeled as member cluster with its kubeconfig,
+// so the FederatedMetric collects from the kind cluster itself as member cluster
+func (s *suite) createMember(ctx context.Context, t *testing.T, name string) {
+	s.create(ctx, t, &corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: targetNamespace}})
+	s.create(ctx, t, &rbacv1.ClusterRoleBinding{
+		ObjectMeta: metav1.ObjectMeta{Name: "e2e-" + name},
+		RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: "view"},
+		Subjects:   []rbacv1.Subject{{Kind: rbacv1.ServiceAccountKind, Name: name, Namespace: targetNamespace}},
+	})
+
+	expiration := int64(time.Hour.Seconds())
+	token, err := s.clientset.CoreV1().ServiceAccounts(targetNamespace).CreateToken(ctx, name, &authenticationv1.TokenRequest{
+		Spec: authenticationv1.TokenRequestSpec{ExpirationSeconds: &expiration},
+	}, metav1.CreateOptions{})
+	require.NoError(t, err)
+	ca, err := s.clientset.CoreV1().ConfigMaps(targetNamespace).Get(ctx, "kube-root-ca.crt", metav1.GetOptions{})
+	require.NoError(t, err)
+
+	kubeconfig, err := clientcmd.Write(clientcmdapi.Config{
+		Clusters:       map[string]*clientcmdapi.Cluster{name: {Server: "https://kubernetes.default.svc", CertificateAuthorityData: []byte(ca.Data["ca.crt"])}},
+		AuthInfos:      map[string]*clientcmdapi.AuthInfo{name: {Token: token.Status.Token}},
+		Contexts:       map[string]*clientcmdapi.Context{name: {Cluster: name, AuthInfo: name}},
+		CurrentContext: name,
+	})
+	require.NoError(t, err)
+	s.create(ctx, t, &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: targetNamespace, Labels: map[string]string{memberLabel: "true"}},
+		Data:       map[string][]byte{v1alpha1.DefaultKubeConfigSecretKey: kubeconfig},
+	})
+}
+
+// rotateToken replaces the API token in the Secret of the DataSink
+func (s *suite) rotateToken(ctx context.Context, t *testing.T, token string) {
+	secret := &corev1.Secret{}
+	require.NoError(t, s.client.Get(ctx, client.ObjectKey{Namespace: operatorNamespace, Name: "collector-token"}, secret))
+	secret.Data = map[string][]byte{"api-token": []byte(token)}
+	require.NoError(t, s.client.Update(ctx, secret))
+}
+
+// create creates the object and deletes it when the test and all its subtests have completed
+func (s *suite) create(ctx context.Context, t *testing.T, obj client.Object) {
+	t.Helper()
+	require.NoError(t, s.client.Create(ctx, obj), "failed to create %T %s", obj, client.ObjectKeyFromObject(obj))
+	t.Cleanup(func() {
+		if err := s.client.Delete(context.Background(), obj); err != nil && !apierrors.IsNotFound(err) {
+			t.Logf("failed to delete %T %s: %v", obj, client.ObjectKeyFromObject(obj), err)
+		}
+	})
+}
+
+// requireSeries waits until the collector received a data point of the metric after since,
+// whose dimensions include the given ones and whose value is the expected one
+func (s *suite) requireSeries(t *testing.T, metric string, since time.Time, dimensions map[string]string, value int64) {
+	t.Helper()
+	require.EventuallyWithT(t, func(c *assert.CollectT) {
+		points, err := exportedPoints(context.Background(), s.clientset)
+		require.NoError(c, err)
+		var found []string
+		for key, point := range latest(points, metric, since) {
+			if includes(point.Dimensions, dimensions) {
+				assert.Equal(c, value, point.Value, "value of the series %s", key)
+				return
+			}
+			found = append(found, key)
+		}
+		c.Errorf("no series of %s with the dimensions %v received since %s, found %v", metric, dimensions, since.Format(time.RFC3339), found)
+	}, exportTimeout, pollInterval)
+}
+
+// requireDataSinkReady waits until the Ready condition of the default DataSink has the status and, if not empty, the reason
+func (s *suite) requireDataSinkReady(ctx context.Context, t *testing.T, status metav1.ConditionStatus, reason string) {
+	t.Helper()
+	require.EventuallyWithT(t, func(c *assert.CollectT) {
+		dataSink := &v1alpha1.DataSink{}
+		require.NoError(c, s.client.Get(ctx, client.ObjectKey{Namespace: operatorNamespace, Name: "default"}, dataSink))
+		ready := meta.FindStatusCondition(dataSink.Status.Conditions, v1alpha1.TypeReady)
+		require.NotNil(c, ready, "the DataSink has not been probed yet")
+		assert.Equal(c, status, ready.Status, ready.Message)
+		if reason != "" {
+			assert.Equal(c, reason, ready.Reason)
+		}
+	}, exportTimeout, pollInterval)
+}
+
+// includes reports whether dimensions contains all the expected dimensions
+func includes(dimensions, expected map[string]string) bool {
+	for key, value := range expected {
+		if dimensions[key] != value {
+			return false
+		}
+	}
+	return true
+}
+
+// with returns a copy of the dimensions with the key set to value
+func with(dimensions map[string]string, key, value string) map[string]string {
+	result := map[string]string{key: value}
+	for k, v := range dimensions {
+		if k != key {
+			result[k] = v
+		}
+	}
+	return result
+}
+
+func podReady(pod *corev1.Pod) bool {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}